@@ -12,6 +12,8 @@ var newControllerMap = map[string](func(*Server) ControllerInterface){
 	"_referentials": NewReferentialController,
 	"_time":         NewTimeController,
 	"_status":       NewStatusController,
+	"_metrics":      NewMetricsController,
+	"_health":       NewReferentialsHealthController,
 }
 
 var newWithReferentialControllerMap = map[string](func(*core.Referential) ControllerInterface){
@@ -24,6 +26,7 @@ var newWithReferentialControllerMap = map[string](func(*core.Referential) Contro
 	"operators":        NewOperatorController,
 	"vehicles":         NewVehicleController,
 	"import":           NewImportController,
+	"_health":          NewHealthController,
 }
 
 type RestfulResource interface {
@@ -35,6 +35,12 @@ func (handler *GtfsHandler) serve(response http.ResponseWriter, request *http.Re
 		return
 	}
 
+	limiter, ok := acquirePartnerRequest(handler.referential, partner, "gtfs", response, request)
+	if !ok {
+		return
+	}
+	defer limiter.Release()
+
 	startTime := handler.referential.Clock().Now()
 
 	logStashEvent := partner.NewLogStashEvent()
@@ -58,6 +64,11 @@ func (handler *GtfsHandler) serve(response http.ResponseWriter, request *http.Re
 		if ok {
 			gc = []core.GtfsConnector{c.(core.GtfsConnector)}
 		}
+	} else if resource == "service-alerts" {
+		c, ok = partner.Connector(core.GTFS_RT_SERVICE_ALERTS_BROADCASTER)
+		if ok {
+			gc = []core.GtfsConnector{c.(core.GtfsConnector)}
+		}
 	} else {
 		messageType = "trip-updates,vehicle-position"
 		gc, ok = partner.GtfsConnectors()
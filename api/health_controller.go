@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"bitbucket.org/enroute-mobi/ara/core"
+)
+
+// Returns the HealthReport of a Referential
+type HealthController struct {
+	referential *core.Referential
+}
+
+// Returns the HealthReports of all the Referentials
+type ReferentialsHealthController struct {
+	server *Server
+}
+
+func NewHealthController(referential *core.Referential) ControllerInterface {
+	return &HealthController{
+		referential: referential,
+	}
+}
+
+func NewReferentialsHealthController(server *Server) ControllerInterface {
+	return &ReferentialsHealthController{
+		server: server,
+	}
+}
+
+func (controller *HealthController) serve(response http.ResponseWriter, request *http.Request, requestData *RequestData) {
+	if request.Method != "GET" || requestData.Id != "" {
+		http.Error(response, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	jsonBytes, _ := json.Marshal(core.NewHealthReport(controller.referential))
+	response.Write(jsonBytes)
+}
+
+func (controller *ReferentialsHealthController) serve(response http.ResponseWriter, request *http.Request, requestData *RequestData) {
+	if request.Method != "GET" || requestData.Resource != "" {
+		http.Error(response, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	referentials := controller.server.CurrentReferentials().FindAll()
+	sort.Slice(referentials, func(i, j int) bool { return referentials[i].Slug() < referentials[j].Slug() })
+
+	reports := []*core.HealthReport{}
+	for _, referential := range referentials {
+		reports = append(reports, core.NewHealthReport(referential))
+	}
+
+	jsonBytes, _ := json.Marshal(reports)
+	response.Write(jsonBytes)
+}
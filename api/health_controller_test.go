@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bitbucket.org/enroute-mobi/ara/core"
+)
+
+func Test_HealthController(t *testing.T) {
+	referentials := core.NewMemoryReferentials()
+	referential := referentials.New("referential")
+	referential.Tokens = []string{"testToken"}
+	referentials.Save(referential)
+
+	stopArea := referential.Model().StopAreas().New()
+	stopArea.Save()
+
+	server := &Server{apiKey: "admin"}
+	server.SetReferentials(referentials)
+
+	var conditions = []struct {
+		url    string
+		token  string
+		status int
+	}{
+		{"/referential/_health", "testToken", http.StatusOK},
+		{"/referential/_health", "wrong", http.StatusUnauthorized},
+		{"/_health", "admin", http.StatusOK},
+		{"/_health", "testToken", http.StatusUnauthorized},
+	}
+
+	for _, condition := range conditions {
+		request, _ := http.NewRequest("GET", condition.url, nil)
+		request.Header.Set("Authorization", "Token token="+condition.token)
+		responseRecorder := httptest.NewRecorder()
+		server.HandleFlow(responseRecorder, request)
+
+		if responseRecorder.Code != condition.status {
+			t.Errorf("Wrong status for %v with token %v:\n got: %v\n want: %v", condition.url, condition.token, responseRecorder.Code, condition.status)
+			continue
+		}
+		if condition.status != http.StatusOK {
+			continue
+		}
+
+		var reports []*core.HealthReport
+		if condition.url == "/_health" {
+			if err := json.Unmarshal(responseRecorder.Body.Bytes(), &reports); err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			report := &core.HealthReport{}
+			if err := json.Unmarshal(responseRecorder.Body.Bytes(), report); err != nil {
+				t.Fatal(err)
+			}
+			reports = append(reports, report)
+		}
+
+		if len(reports) != 1 || reports[0].Referential != "referential" || reports[0].StopAreas.Total != 1 {
+			t.Errorf("Wrong health reports for %v: %v", condition.url, responseRecorder.Body.String())
+		}
+	}
+}
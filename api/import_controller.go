@@ -3,6 +3,7 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"mime"
 	"mime/multipart"
@@ -22,6 +23,11 @@ type ImportController struct {
 
 type ImportRequest struct {
 	Force bool
+	// csv (default), netex or gtfs
+	Format string
+	// NeTEx/GTFS model date (YYYY-MM-DD) and ObjectID kind
+	ModelName    string
+	ObjectIDKind string
 }
 
 func NewImportController(referential *core.Referential) ControllerInterface {
@@ -63,7 +69,41 @@ func (controller *ImportController) serve(response http.ResponseWriter, request
 
 	stime := controller.referential.Clock().Now()
 
-	result := model.NewLoader(string(controller.referential.Slug()), controller.importRequest.Force, false).Load(controller.csvReader)
+	var result model.Result
+	switch controller.importRequest.Format {
+	case "", "csv":
+		result = model.NewLoader(string(controller.referential.Slug()), controller.importRequest.Force, false).Load(controller.csvReader)
+	case model.NETEX_FORMAT, model.GTFS_FORMAT:
+		modelName := controller.importRequest.ModelName
+		if modelName == "" {
+			modelName = controller.referential.Clock().Now().Format("2006-01-02")
+		}
+		objectidKind := controller.importRequest.ObjectIDKind
+		if objectidKind == "" {
+			objectidKind = "internal"
+		}
+		slug := string(controller.referential.Slug())
+
+		if controller.importRequest.Format == model.GTFS_FORMAT {
+			gtfsLoader, err := model.NewGtfsLoader(slug, modelName, objectidKind, controller.importRequest.Force, false)
+			if err != nil {
+				http.Error(response, fmt.Sprintf("Invalid GTFS import request: %v", err), http.StatusBadRequest)
+				return
+			}
+			result = gtfsLoader.Load(bytes.NewReader(controller.csvReader.Bytes()), int64(controller.csvReader.Len()))
+			break
+		}
+
+		netexLoader, err := model.NewNeTExLoader(slug, modelName, objectidKind, controller.importRequest.Force, false)
+		if err != nil {
+			http.Error(response, fmt.Sprintf("Invalid NeTEx import request: %v", err), http.StatusBadRequest)
+			return
+		}
+		result = netexLoader.Load(controller.csvReader)
+	default:
+		http.Error(response, fmt.Sprintf("Unknown import format %v", controller.importRequest.Format), http.StatusBadRequest)
+		return
+	}
 	logger.Log.Debugf("ImportController Load time : %v", controller.referential.Clock().Since(stime))
 
 	jsonBytes, _ := json.Marshal(result)
@@ -0,0 +1,454 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/model"
+)
+
+// Filters supported by the Index requests
+const (
+	INDEX_FILTER_OBJECTID      = "ObjectId"
+	INDEX_FILTER_OBJECTID_KIND = "ObjectIdKind"
+	INDEX_FILTER_LINE          = "LineId"
+	INDEX_FILTER_STOP_AREA     = "StopAreaId"
+	INDEX_FILTER_AFTER         = "After"
+	INDEX_FILTER_BEFORE        = "Before"
+	INDEX_FILTER_COLLECTED     = "Collected"
+	INDEX_FILTER_MONITORED     = "Monitored"
+
+	INDEX_LIMIT  = "limit"
+	INDEX_CURSOR = "cursor"
+	INDEX_FIELDS = "fields"
+	INDEX_SORT   = "sort"
+)
+
+var indexFilters = []string{
+	INDEX_FILTER_OBJECTID,
+	INDEX_FILTER_OBJECTID_KIND,
+	INDEX_FILTER_LINE,
+	INDEX_FILTER_STOP_AREA,
+	INDEX_FILTER_AFTER,
+	INDEX_FILTER_BEFORE,
+	INDEX_FILTER_COLLECTED,
+	INDEX_FILTER_MONITORED,
+}
+
+var objectIdIdentifierRegexp = regexp.MustCompile("([0-9a-zA-Z-]+):([0-9a-zA-Z-:]+)")
+
+// Model object returned by an Index request with the attributes used by
+// the filters
+type indexItem struct {
+	id    string
+	value interface{}
+
+	objectids   model.ObjectIDs
+	lineIds     []model.LineId
+	stopAreaIds []model.StopAreaId
+	time        time.Time
+	collected   bool
+	monitored   bool
+
+	// Sort values followed by the id, used as pagination cursor
+	key []interface{}
+}
+
+// Typed accessors of the attributes which can be selected with fields and
+// used as sort keys. The Id is always available.
+type indexAttributes map[string]func(value interface{}) interface{}
+
+func indexObjectIDs(value interface{}) interface{} {
+	return value.(interface{ ObjectIDs() model.ObjectIDs }).ObjectIDs()
+}
+
+type indexSortKey struct {
+	attribute  string
+	descending bool
+}
+
+// Filters, sorts and paginates the Index responses.
+//
+// Empty queries return all the model objects, in a JSON array like
+// before. The total number of results is returned in the X-Total-Count
+// header and the cursor of the next page in the X-Next-Cursor header.
+//
+// The cursor contains the sort values and the id of the last returned
+// object, the next page starts after this object even if objects have been
+// added or removed meanwhile.
+type indexQuery struct {
+	objectid      *model.ObjectID
+	objectidKind  string
+	lineIds       map[model.LineId]struct{}
+	stopAreaIds   map[model.StopAreaId]struct{}
+	after, before time.Time
+	collected     *bool
+	monitored     *bool
+
+	limit    int
+	cursor   []interface{}
+	fields   []string
+	sortKeys []indexSortKey
+}
+
+// Returns an error when a filter isn't supported by the resource
+func newIndexQuery(tx *model.Transaction, filters url.Values, supportedFilters ...string) (*indexQuery, error) {
+	query := &indexQuery{}
+
+	supported := make(map[string]struct{})
+	for _, filter := range supportedFilters {
+		supported[filter] = struct{}{}
+	}
+	for _, filter := range indexFilters {
+		if _, ok := supported[filter]; !ok && filters.Get(filter) != "" {
+			return nil, fmt.Errorf("unsupported filter %v", filter)
+		}
+	}
+
+	if value := filters.Get(INDEX_FILTER_OBJECTID); value != "" {
+		foundStrings := objectIdIdentifierRegexp.FindStringSubmatch(value)
+		if foundStrings == nil {
+			return nil, fmt.Errorf("invalid %v %v", INDEX_FILTER_OBJECTID, value)
+		}
+		objectid := model.NewObjectID(foundStrings[1], foundStrings[2])
+		query.objectid = &objectid
+	}
+	query.objectidKind = filters.Get(INDEX_FILTER_OBJECTID_KIND)
+
+	lineIdentifiers := splitFilterValues(filters[INDEX_FILTER_LINE])
+	if len(lineIdentifiers) != 0 {
+		query.lineIds = make(map[model.LineId]struct{})
+	}
+	for _, identifier := range lineIdentifiers {
+		line, ok := findLineByIdentifier(tx, identifier)
+		if !ok {
+			return nil, fmt.Errorf("line not found: %v", identifier)
+		}
+		query.lineIds[line.Id()] = struct{}{}
+	}
+
+	stopAreaIdentifiers := splitFilterValues(filters[INDEX_FILTER_STOP_AREA])
+	if len(stopAreaIdentifiers) != 0 {
+		query.stopAreaIds = make(map[model.StopAreaId]struct{})
+	}
+	for _, identifier := range stopAreaIdentifiers {
+		stopArea, ok := findStopAreaByIdentifier(tx, identifier)
+		if !ok {
+			return nil, fmt.Errorf("stop area not found: %v", identifier)
+		}
+		query.stopAreaIds[stopArea.Id()] = struct{}{}
+	}
+
+	var err error
+	if value := filters.Get(INDEX_FILTER_AFTER); value != "" {
+		if query.after, err = parseHistoryTime(value); err != nil {
+			return nil, fmt.Errorf("can't parse %v: %v", INDEX_FILTER_AFTER, err)
+		}
+	}
+	if value := filters.Get(INDEX_FILTER_BEFORE); value != "" {
+		if query.before, err = parseHistoryTime(value); err != nil {
+			return nil, fmt.Errorf("can't parse %v: %v", INDEX_FILTER_BEFORE, err)
+		}
+	}
+	if query.collected, err = parseBoolFilter(filters, INDEX_FILTER_COLLECTED); err != nil {
+		return nil, err
+	}
+	if query.monitored, err = parseBoolFilter(filters, INDEX_FILTER_MONITORED); err != nil {
+		return nil, err
+	}
+
+	if value := filters.Get(INDEX_LIMIT); value != "" {
+		if query.limit, err = strconv.Atoi(value); err != nil || query.limit <= 0 {
+			return nil, fmt.Errorf("invalid %v %v", INDEX_LIMIT, value)
+		}
+	}
+
+	query.fields = splitFilterValues(filters[INDEX_FIELDS])
+	for _, attribute := range splitFilterValues(filters[INDEX_SORT]) {
+		key := indexSortKey{attribute: attribute}
+		if strings.HasPrefix(attribute, "-") {
+			key.attribute = attribute[1:]
+			key.descending = true
+		}
+		query.sortKeys = append(query.sortKeys, key)
+	}
+
+	if value := filters.Get(INDEX_CURSOR); value != "" {
+		if query.cursor, err = parseIndexCursor(value, len(query.sortKeys)); err != nil {
+			return nil, fmt.Errorf("invalid %v %v", INDEX_CURSOR, value)
+		}
+	}
+
+	return query, nil
+}
+
+func parseIndexCursor(value string, sortKeyCount int) ([]interface{}, error) {
+	jsonBytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	var cursor []interface{}
+	if err := json.Unmarshal(jsonBytes, &cursor); err != nil {
+		return nil, err
+	}
+	if len(cursor) != sortKeyCount+1 {
+		return nil, fmt.Errorf("wrong cursor length")
+	}
+	if _, ok := cursor[sortKeyCount].(string); !ok {
+		return nil, fmt.Errorf("wrong cursor id")
+	}
+	return cursor, nil
+}
+
+func formatIndexCursor(key []interface{}) string {
+	jsonBytes, _ := json.Marshal(key)
+	return base64.RawURLEncoding.EncodeToString(jsonBytes)
+}
+
+func parseBoolFilter(filters url.Values, filter string) (*bool, error) {
+	value := filters.Get(filter)
+	if value == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %v %v", filter, value)
+	}
+	return &b, nil
+}
+
+func (query *indexQuery) match(item *indexItem) bool {
+	if query.objectid != nil {
+		objectid, ok := item.objectids[query.objectid.Kind()]
+		if !ok || objectid.Value() != query.objectid.Value() {
+			return false
+		}
+	}
+	if query.objectidKind != "" {
+		if _, ok := item.objectids[query.objectidKind]; !ok {
+			return false
+		}
+	}
+	if query.lineIds != nil && !matchLineIds(query.lineIds, item.lineIds) {
+		return false
+	}
+	if query.stopAreaIds != nil && !matchStopAreaIds(query.stopAreaIds, item.stopAreaIds) {
+		return false
+	}
+	if !query.after.IsZero() && (item.time.IsZero() || item.time.Before(query.after)) {
+		return false
+	}
+	if !query.before.IsZero() && (item.time.IsZero() || item.time.After(query.before)) {
+		return false
+	}
+	if query.collected != nil && item.collected != *query.collected {
+		return false
+	}
+	if query.monitored != nil && item.monitored != *query.monitored {
+		return false
+	}
+	return true
+}
+
+func matchLineIds(lineIds map[model.LineId]struct{}, itemLineIds []model.LineId) bool {
+	for _, lineId := range itemLineIds {
+		if _, ok := lineIds[lineId]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func matchStopAreaIds(stopAreaIds map[model.StopAreaId]struct{}, itemStopAreaIds []model.StopAreaId) bool {
+	for _, stopAreaId := range itemStopAreaIds {
+		if _, ok := stopAreaIds[stopAreaId]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (query *indexQuery) write(response http.ResponseWriter, items []*indexItem, attributes indexAttributes) {
+	for _, field := range query.fields {
+		if _, ok := attributes[field]; !ok && field != "Id" {
+			http.Error(response, fmt.Sprintf("Invalid request: unsupported field %v", field), http.StatusBadRequest)
+			return
+		}
+	}
+	for _, key := range query.sortKeys {
+		if _, ok := attributes[key.attribute]; !ok && key.attribute != "Id" {
+			http.Error(response, fmt.Sprintf("Invalid request: unsupported sort attribute %v", key.attribute), http.StatusBadRequest)
+			return
+		}
+	}
+
+	selected := []*indexItem{}
+	for _, item := range items {
+		if query.match(item) {
+			selected = append(selected, item)
+		}
+	}
+
+	query.sort(selected, attributes)
+
+	response.Header().Set("X-Total-Count", strconv.Itoa(len(selected)))
+
+	if query.cursor != nil {
+		first := sort.Search(len(selected), func(i int) bool {
+			return query.compareKeys(selected[i].key, query.cursor) > 0
+		})
+		selected = selected[first:]
+	}
+	if query.limit != 0 && len(selected) > query.limit {
+		selected = selected[:query.limit]
+		response.Header().Set("X-Next-Cursor", formatIndexCursor(selected[query.limit-1].key))
+	}
+
+	values := make([]interface{}, 0, len(selected))
+	for _, item := range selected {
+		if len(query.fields) == 0 {
+			values = append(values, item.value)
+			continue
+		}
+		value := make(map[string]interface{})
+		for _, field := range query.fields {
+			if field == "Id" {
+				value[field] = item.id
+				continue
+			}
+			value[field] = attributes[field](item.value)
+		}
+		values = append(values, value)
+	}
+
+	jsonBytes, _ := json.Marshal(values)
+	response.Write(jsonBytes)
+}
+
+// Sorts by the given attributes, by Id when the results are paginated
+func (query *indexQuery) sort(items []*indexItem, attributes indexAttributes) {
+	if len(query.sortKeys) == 0 && query.limit == 0 && query.cursor == nil {
+		return
+	}
+
+	for _, item := range items {
+		item.key = make([]interface{}, 0, len(query.sortKeys)+1)
+		for _, key := range query.sortKeys {
+			if key.attribute == "Id" {
+				item.key = append(item.key, item.id)
+				continue
+			}
+			item.key = append(item.key, sortValue(attributes[key.attribute](item.value)))
+		}
+		item.key = append(item.key, item.id)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return query.compareKeys(items[i].key, items[j].key) < 0
+	})
+}
+
+func (query *indexQuery) compareKeys(a, b []interface{}) int {
+	for i, key := range query.sortKeys {
+		c := compareAttributes(a[i], b[i])
+		if c == 0 {
+			continue
+		}
+		if key.descending {
+			return -c
+		}
+		return c
+	}
+	return strings.Compare(a[len(a)-1].(string), b[len(b)-1].(string))
+}
+
+// Returns the value in a JSON compatible form, to be compared with the
+// cursor values. Times are sorted with their UTC representation.
+func sortValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, bool, float64, string:
+		return v
+	case int:
+		return float64(v)
+	case time.Time:
+		if v.IsZero() {
+			return nil
+		}
+		return v.UTC().Format("2006-01-02T15:04:05.000000000Z")
+	}
+	return fmt.Sprint(value)
+}
+
+// Undefined attributes are sorted first
+func compareAttributes(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	switch av := a.(type) {
+	case float64:
+		if bv, ok := b.(float64); ok {
+			switch {
+			case av < bv:
+				return -1
+			case av > bv:
+				return 1
+			}
+			return 0
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			switch {
+			case av == bv:
+				return 0
+			case !av:
+				return -1
+			}
+			return 1
+		}
+	case string:
+		if bv, ok := b.(string); ok {
+			return strings.Compare(av, bv)
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func findStopAreaByIdentifier(tx *model.Transaction, identifier string) (model.StopArea, bool) {
+	if foundStrings := objectIdIdentifierRegexp.FindStringSubmatch(identifier); foundStrings != nil {
+		return tx.Model().StopAreas().FindByObjectId(model.NewObjectID(foundStrings[1], foundStrings[2]))
+	}
+	return tx.Model().StopAreas().Find(model.StopAreaId(identifier))
+}
+
+func findLineByIdentifier(tx *model.Transaction, identifier string) (model.Line, bool) {
+	if foundStrings := objectIdIdentifierRegexp.FindStringSubmatch(identifier); foundStrings != nil {
+		return tx.Model().Lines().FindByObjectId(model.NewObjectID(foundStrings[1], foundStrings[2]))
+	}
+	return tx.Model().Lines().Find(model.LineId(identifier))
+}
+
+// Accepts repeated and comma separated values
+func splitFilterValues(values []string) (result []string) {
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				result = append(result, v)
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/core"
+	"bitbucket.org/enroute-mobi/ara/model"
+)
+
+func prepareIndexQueryServer() (*Server, *core.Referential) {
+	referentials := core.NewMemoryReferentials()
+	server := &Server{}
+	server.SetReferentials(referentials)
+	referential := referentials.New("default")
+	referential.Tokens = []string{"testToken"}
+	referential.Save()
+	return server, referential
+}
+
+func sendIndexQueryRequest(server *Server, url string) *httptest.ResponseRecorder {
+	request, _ := http.NewRequest("GET", url, nil)
+	request.Header.Set("Authorization", "Token token=testToken")
+	responseRecorder := httptest.NewRecorder()
+	server.HandleFlow(responseRecorder, request)
+	return responseRecorder
+}
+
+func Test_IndexQuery_Vehicles(t *testing.T) {
+	server, referential := prepareIndexQueryServer()
+
+	line := referential.Model().Lines().New()
+	line.SetObjectID(model.NewObjectID("kind", "line"))
+	line.Save()
+
+	recordedAt := time.Date(2017, time.January, 1, 12, 0, 0, 0, time.UTC)
+	for i, value := range []string{"v1", "v2", "v3"} {
+		vehicle := referential.Model().Vehicles().New()
+		vehicle.SetObjectID(model.NewObjectID("kind", value))
+		if value != "v3" {
+			vehicle.LineId = line.Id()
+		}
+		vehicle.RecordedAtTime = recordedAt.Add(time.Duration(i) * time.Minute)
+		vehicle.Save()
+	}
+
+	var conditions = []struct {
+		url        string
+		status     int
+		values     []string
+		total      string
+		nextCursor bool
+	}{
+		{"/default/vehicles?ObjectId=kind:v2", http.StatusOK, []string{"v2"}, "1", false},
+		{"/default/vehicles?LineId=kind:line&sort=RecordedAtTime", http.StatusOK, []string{"v1", "v2"}, "2", false},
+		{"/default/vehicles?After=2017-01-01T12:01:00Z&sort=RecordedAtTime", http.StatusOK, []string{"v2", "v3"}, "2", false},
+		{"/default/vehicles?sort=-RecordedAtTime&limit=2", http.StatusOK, []string{"v3", "v2"}, "3", true},
+		{"/default/vehicles?ObjectIdKind=other", http.StatusOK, []string{}, "0", false},
+		{"/default/vehicles?Collected=true", http.StatusBadRequest, nil, "", false},
+		{"/default/vehicles?limit=0", http.StatusBadRequest, nil, "", false},
+		{"/default/vehicles?cursor=2", http.StatusBadRequest, nil, "", false},
+		{"/default/vehicles?sort=Unknown", http.StatusBadRequest, nil, "", false},
+		{"/default/vehicles?fields=Unknown", http.StatusBadRequest, nil, "", false},
+		{"/default/vehicles?LineId=unknown", http.StatusBadRequest, nil, "", false},
+	}
+
+	for _, condition := range conditions {
+		responseRecorder := sendIndexQueryRequest(server, condition.url)
+
+		if responseRecorder.Code != condition.status {
+			t.Errorf("Wrong status for %v:\n got: %v\n want: %v", condition.url, responseRecorder.Code, condition.status)
+			continue
+		}
+		if condition.status != http.StatusOK {
+			continue
+		}
+
+		var vehicles []struct {
+			ObjectIDs map[string]string
+		}
+		if err := json.Unmarshal(responseRecorder.Body.Bytes(), &vehicles); err != nil {
+			t.Fatalf("Invalid response for %v: %v", condition.url, err)
+		}
+		values := []string{}
+		for _, vehicle := range vehicles {
+			values = append(values, vehicle.ObjectIDs["kind"])
+		}
+		if !reflect.DeepEqual(values, condition.values) {
+			t.Errorf("Wrong vehicles for %v:\n got: %v\n want: %v", condition.url, values, condition.values)
+		}
+		if total := responseRecorder.Header().Get("X-Total-Count"); total != condition.total {
+			t.Errorf("Wrong X-Total-Count for %v:\n got: %v\n want: %v", condition.url, total, condition.total)
+		}
+		if nextCursor := responseRecorder.Header().Get("X-Next-Cursor"); (nextCursor != "") != condition.nextCursor {
+			t.Errorf("Wrong X-Next-Cursor for %v: %v", condition.url, nextCursor)
+		}
+	}
+}
+
+func Test_IndexQuery_Cursor(t *testing.T) {
+	server, referential := prepareIndexQueryServer()
+
+	recordedAt := time.Date(2017, time.January, 1, 12, 0, 0, 0, time.UTC)
+	newVehicle := func(value string, minutes int) {
+		vehicle := referential.Model().Vehicles().New()
+		vehicle.SetObjectID(model.NewObjectID("kind", value))
+		vehicle.RecordedAtTime = recordedAt.Add(time.Duration(minutes) * time.Minute)
+		vehicle.Save()
+	}
+	newVehicle("v1", 1)
+	newVehicle("v3", 3)
+	newVehicle("v4", 4)
+
+	values := func(responseRecorder *httptest.ResponseRecorder) []string {
+		var vehicles []struct {
+			ObjectIDs map[string]string
+		}
+		json.Unmarshal(responseRecorder.Body.Bytes(), &vehicles)
+		values := []string{}
+		for _, vehicle := range vehicles {
+			values = append(values, vehicle.ObjectIDs["kind"])
+		}
+		return values
+	}
+
+	responseRecorder := sendIndexQueryRequest(server, "/default/vehicles?sort=RecordedAtTime&limit=1")
+	if got := values(responseRecorder); !reflect.DeepEqual(got, []string{"v1"}) {
+		t.Fatalf("Wrong first page: %v", got)
+	}
+
+	// The next page starts after the last returned vehicle
+	newVehicle("v0", 0)
+	newVehicle("v2", 2)
+
+	cursor := responseRecorder.Header().Get("X-Next-Cursor")
+	responseRecorder = sendIndexQueryRequest(server, "/default/vehicles?sort=RecordedAtTime&limit=2&cursor="+cursor)
+	if got := values(responseRecorder); !reflect.DeepEqual(got, []string{"v2", "v3"}) {
+		t.Errorf("Wrong second page: %v", got)
+	}
+
+	cursor = responseRecorder.Header().Get("X-Next-Cursor")
+	responseRecorder = sendIndexQueryRequest(server, "/default/vehicles?sort=RecordedAtTime&limit=2&cursor="+cursor)
+	if got := values(responseRecorder); !reflect.DeepEqual(got, []string{"v4"}) {
+		t.Errorf("Wrong last page: %v", got)
+	}
+	if nextCursor := responseRecorder.Header().Get("X-Next-Cursor"); nextCursor != "" {
+		t.Errorf("Last page shouldn't have a next cursor, got %v", nextCursor)
+	}
+
+	responseRecorder = sendIndexQueryRequest(server, "/default/vehicles?sort=-RecordedAtTime&cursor="+cursor)
+	if responseRecorder.Code != http.StatusOK {
+		t.Fatalf("Wrong status:\n got: %v\n want: %v", responseRecorder.Code, http.StatusOK)
+	}
+	if got := values(responseRecorder); !reflect.DeepEqual(got, []string{"v2", "v1", "v0"}) {
+		t.Errorf("Wrong page in descending order: %v", got)
+	}
+}
+
+func Test_IndexQuery_Fields(t *testing.T) {
+	server, referential := prepareIndexQueryServer()
+
+	stopArea := referential.Model().StopAreas().New()
+	stopArea.Name = "Stop Area"
+	stopArea.SetObjectID(model.NewObjectID("kind", "value"))
+	stopArea.Save()
+
+	responseRecorder := sendIndexQueryRequest(server, "/default/stop_areas?fields=Id,Name")
+	if responseRecorder.Code != http.StatusOK {
+		t.Fatalf("Wrong status:\n got: %v\n want: %v", responseRecorder.Code, http.StatusOK)
+	}
+
+	var stopAreas []map[string]interface{}
+	if err := json.Unmarshal(responseRecorder.Body.Bytes(), &stopAreas); err != nil {
+		t.Fatal(err)
+	}
+	expected := []map[string]interface{}{{"Id": string(stopArea.Id()), "Name": "Stop Area"}}
+	if !reflect.DeepEqual(stopAreas, expected) {
+		t.Errorf("Wrong selected fields:\n got: %v\n want: %v", stopAreas, expected)
+	}
+}
+
+func Test_IndexQuery_StopVisits(t *testing.T) {
+	server, referential := prepareIndexQueryServer()
+
+	stopArea := referential.Model().StopAreas().New()
+	stopArea.SetObjectID(model.NewObjectID("kind", "stopArea"))
+	stopArea.Save()
+
+	vehicleJourney := referential.Model().VehicleJourneys().New()
+	vehicleJourney.Monitored = true
+	vehicleJourney.Save()
+
+	collected := referential.Model().StopVisits().New()
+	collected.StopAreaId = stopArea.Id()
+	collected.VehicleJourneyId = vehicleJourney.Id()
+	collected.Collected(time.Date(2017, time.January, 1, 12, 0, 0, 0, time.UTC))
+	collected.Save()
+
+	notCollected := referential.Model().StopVisits().New()
+	notCollected.StopAreaId = stopArea.Id()
+	notCollected.Save()
+
+	var conditions = []struct {
+		url string
+		ids []string
+	}{
+		{"/default/stop_visits?StopAreaId=kind:stopArea&Collected=true", []string{string(collected.Id())}},
+		{"/default/stop_visits?StopArea=" + string(stopArea.Id()) + "&Collected=false", []string{string(notCollected.Id())}},
+		{"/default/stop_visits?Monitored=true", []string{string(collected.Id())}},
+	}
+
+	for _, condition := range conditions {
+		responseRecorder := sendIndexQueryRequest(server, condition.url)
+		if responseRecorder.Code != http.StatusOK {
+			t.Errorf("Wrong status for %v:\n got: %v\n want: %v", condition.url, responseRecorder.Code, http.StatusOK)
+			continue
+		}
+
+		var stopVisits []struct{ Id string }
+		if err := json.Unmarshal(responseRecorder.Body.Bytes(), &stopVisits); err != nil {
+			t.Fatal(err)
+		}
+		ids := []string{}
+		for _, stopVisit := range stopVisits {
+			ids = append(ids, stopVisit.Id)
+		}
+		if !reflect.DeepEqual(ids, condition.ids) {
+			t.Errorf("Wrong stop visits for %v:\n got: %v\n want: %v", condition.url, ids, condition.ids)
+		}
+	}
+}
@@ -16,6 +16,14 @@ type LineController struct {
 	referential *core.Referential
 }
 
+// Attributes which can be selected and sorted in the Index requests
+var lineIndexAttributes = indexAttributes{
+	"ObjectIDs":   indexObjectIDs,
+	"Name":        func(v interface{}) interface{} { return v.(*model.Line).Name },
+	"ReferentId":  func(v interface{}) interface{} { return string(v.(*model.Line).ReferentId) },
+	"CollectedAt": func(v interface{}) interface{} { return v.(*model.Line).CollectedAt() },
+}
+
 func NewLineController(referential *core.Referential) ControllerInterface {
 	return &Controller{
 		restfulResource: &LineController{
@@ -41,8 +49,25 @@ func (controller *LineController) Index(response http.ResponseWriter, filters ur
 
 	logger.Log.Debugf("Lines Index")
 
-	jsonBytes, _ := json.Marshal(tx.Model().Lines().FindAll())
-	response.Write(jsonBytes)
+	query, err := newIndexQuery(tx, filters, INDEX_FILTER_OBJECTID, INDEX_FILTER_OBJECTID_KIND, INDEX_FILTER_LINE, INDEX_FILTER_COLLECTED)
+	if err != nil {
+		http.Error(response, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	lines := tx.Model().Lines().FindAll()
+	items := make([]*indexItem, 0, len(lines))
+	for i := range lines {
+		line := &lines[i]
+		items = append(items, &indexItem{
+			id:        string(line.Id()),
+			value:     line,
+			objectids: line.ObjectIDs(),
+			lineIds:   []model.LineId{line.Id()},
+			collected: !line.CollectedAt().IsZero(),
+		})
+	}
+	query.write(response, items, lineIndexAttributes)
 }
 
 func (controller *LineController) Show(response http.ResponseWriter, identifier string) {
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"bitbucket.org/enroute-mobi/ara/core"
+)
+
+// Serves the Prometheus text exposition format
+type MetricsController struct {
+	server *Server
+}
+
+type metricFamily struct {
+	name       string
+	help       string
+	metricType string
+	samples    []metricSample
+}
+
+type metricSample struct {
+	labels []string // label names and values
+	value  float64
+}
+
+func NewMetricsController(server *Server) ControllerInterface {
+	return &MetricsController{
+		server: server,
+	}
+}
+
+func (controller *MetricsController) serve(response http.ResponseWriter, request *http.Request, requestData *RequestData) {
+	if request.Method != "GET" || requestData.Resource != "" {
+		http.Error(response, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	response.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	response.Write(controller.metrics())
+}
+
+func (controller *MetricsController) metrics() []byte {
+	modelObjects := &metricFamily{name: "ara_model_objects", help: "Number of objects in the referential model", metricType: "gauge"}
+	updateEvents := &metricFamily{name: "ara_update_events_total", help: "Number of update events broadcasted by the referential CollectManager", metricType: "counter"}
+	partnerStatus := &metricFamily{name: "ara_partner_operational_status", help: "Operational status of the partner", metricType: "gauge"}
+	soapRequests := &metricFamily{name: "ara_soap_requests_total", help: "Number of SOAP requests sent to the partner", metricType: "counter"}
+	soapErrors := &metricFamily{name: "ara_soap_request_errors_total", help: "Number of SOAP requests sent to the partner which returned an error", metricType: "counter"}
+	soapDuration := &metricFamily{name: "ara_soap_request_duration_seconds_total", help: "Total duration of the SOAP requests sent to the partner", metricType: "counter"}
+	subscriptions := &metricFamily{name: "ara_subscriptions", help: "Number of partner subscriptions", metricType: "gauge"}
+	cacheHits := &metricFamily{name: "ara_gtfs_cache_hits_total", help: "Number of GTFS-RT feeds served from the partner cache", metricType: "counter"}
+	cacheMisses := &metricFamily{name: "ara_gtfs_cache_misses_total", help: "Number of GTFS-RT feeds built for the partner cache", metricType: "counter"}
+
+	for _, referential := range controller.referentials() {
+		slug := string(referential.Slug())
+
+		model := referential.Model()
+		modelObjects.add(float64(model.StopAreas().Size()), "referential", slug, "type", "stop_area")
+		modelObjects.add(float64(model.StopVisits().Size()), "referential", slug, "type", "stop_visit")
+		modelObjects.add(float64(model.VehicleJourneys().Size()), "referential", slug, "type", "vehicle_journey")
+		modelObjects.add(float64(model.Vehicles().Size()), "referential", slug, "type", "vehicle")
+		modelObjects.add(float64(model.Situations().Size()), "referential", slug, "type", "situation")
+
+		if collectManager := referential.CollectManager(); collectManager != nil {
+			counts := collectManager.UpdateEventCounts()
+			for _, kind := range sortedKeys(counts) {
+				updateEvents.add(float64(counts[kind]), "referential", slug, "kind", kind)
+			}
+		}
+
+		partners := referential.Partners().FindAll()
+		sort.Slice(partners, func(i, j int) bool { return partners[i].Slug() < partners[j].Slug() })
+		for _, partner := range partners {
+			partnerSlug := string(partner.Slug())
+
+			for _, status := range []core.OperationnalStatus{core.OPERATIONNAL_STATUS_UNKNOWN, core.OPERATIONNAL_STATUS_UP, core.OPERATIONNAL_STATUS_DOWN} {
+				var value float64
+				if partner.OperationnalStatus() == status {
+					value = 1
+				}
+				partnerStatus.add(value, "referential", slug, "partner", partnerSlug, "status", string(status))
+			}
+
+			if soapMetrics := partner.SOAPMetrics(); soapMetrics != nil {
+				for _, request := range soapMetrics.Requests() {
+					soapRequests.add(float64(request.Count), "referential", slug, "partner", partnerSlug, "request", request.Request)
+					soapErrors.add(float64(request.Errors), "referential", slug, "partner", partnerSlug, "request", request.Request)
+					soapDuration.add(request.Duration.Seconds(), "referential", slug, "partner", partnerSlug, "request", request.Request)
+				}
+			}
+
+			kinds := make(map[string]uint64)
+			for _, subscription := range partner.Subscriptions().FindAll() {
+				kinds[subscription.Kind()]++
+			}
+			for _, kind := range sortedKeys(kinds) {
+				subscriptions.add(float64(kinds[kind]), "referential", slug, "partner", partnerSlug, "kind", kind)
+			}
+
+			if gtfsCache := partner.GtfsCache(); gtfsCache != nil {
+				hits, misses := gtfsCache.Stats()
+				cacheHits.add(float64(hits), "referential", slug, "partner", partnerSlug)
+				cacheMisses.add(float64(misses), "referential", slug, "partner", partnerSlug)
+			}
+		}
+	}
+
+	var buffer bytes.Buffer
+	for _, family := range []*metricFamily{modelObjects, updateEvents, partnerStatus, soapRequests, soapErrors, soapDuration, subscriptions, cacheHits, cacheMisses} {
+		family.write(&buffer)
+	}
+	return buffer.Bytes()
+}
+
+func (controller *MetricsController) referentials() []*core.Referential {
+	referentials := controller.server.CurrentReferentials().FindAll()
+	sort.Slice(referentials, func(i, j int) bool { return referentials[i].Slug() < referentials[j].Slug() })
+	return referentials
+}
+
+func (family *metricFamily) add(value float64, labels ...string) {
+	family.samples = append(family.samples, metricSample{labels: labels, value: value})
+}
+
+func (family *metricFamily) write(buffer *bytes.Buffer) {
+	if len(family.samples) == 0 {
+		return
+	}
+
+	fmt.Fprintf(buffer, "# HELP %s %s\n", family.name, family.help)
+	fmt.Fprintf(buffer, "# TYPE %s %s\n", family.name, family.metricType)
+	for _, sample := range family.samples {
+		buffer.WriteString(family.name)
+		if len(sample.labels) != 0 {
+			buffer.WriteByte('{')
+			for i := 0; i+1 < len(sample.labels); i += 2 {
+				if i != 0 {
+					buffer.WriteByte(',')
+				}
+				fmt.Fprintf(buffer, "%s=\"%s\"", sample.labels[i], escapeLabelValue(sample.labels[i+1]))
+			}
+			buffer.WriteByte('}')
+		}
+		buffer.WriteByte(' ')
+		buffer.WriteString(strconv.FormatFloat(sample.value, 'g', -1, 64))
+		buffer.WriteByte('\n')
+	}
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
+
+func sortedKeys(m map[string]uint64) (keys []string) {
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return
+}
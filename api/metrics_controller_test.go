@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bitbucket.org/enroute-mobi/ara/core"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+func Test_MetricsController(t *testing.T) {
+	referentials := core.NewMemoryReferentials()
+	referentials.SetUUIDGenerator(uuid.NewFakeUUIDGenerator())
+	referential := referentials.New("referential")
+	referentials.Save(referential)
+
+	var stopAreaId model.StopAreaId
+	for i := 0; i < 2; i++ {
+		stopArea := referential.Model().StopAreas().New()
+		stopArea.Save()
+		stopAreaId = stopArea.Id()
+	}
+	referential.CollectManager().BroadcastUpdateEvent(model.NewStatusUpdateEvent(stopAreaId, "partner", true))
+
+	partner := referential.Partners().New("partner")
+	partner.PartnerStatus.OperationnalStatus = core.OPERATIONNAL_STATUS_UP
+	referential.Partners().Save(partner)
+	subscription := partner.Subscriptions().New("StopArea")
+	subscription.Save()
+
+	server := &Server{apiKey: "admin"}
+	server.SetReferentials(referentials)
+
+	request, err := http.NewRequest("GET", "/_metrics", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set("Authorization", "Token token=admin")
+	responseRecorder := httptest.NewRecorder()
+	server.HandleFlow(responseRecorder, request)
+
+	if status := responseRecorder.Code; status != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code:\n got %v\n want %v", status, http.StatusOK)
+	}
+	if contentType := responseRecorder.Header().Get("Content-Type"); contentType != "text/plain; version=0.0.4" {
+		t.Errorf("Handler returned wrong Content-Type: %v", contentType)
+	}
+
+	body := responseRecorder.Body.String()
+	for _, expected := range []string{
+		"# TYPE ara_model_objects gauge\n",
+		`ara_model_objects{referential="referential",type="stop_area"} 2`,
+		`ara_model_objects{referential="referential",type="stop_visit"} 0`,
+		`ara_update_events_total{referential="referential",kind="Status"} 1`,
+		`ara_partner_operational_status{referential="referential",partner="partner",status="up"} 1`,
+		`ara_partner_operational_status{referential="referential",partner="partner",status="down"} 0`,
+		`ara_subscriptions{referential="referential",partner="partner",kind="StopArea"} 1`,
+		`ara_gtfs_cache_hits_total{referential="referential",partner="partner"} 0`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Metrics should contain %v, got:\n%v", expected, body)
+		}
+	}
+}
+
+func Test_MetricsController_Unauthorized(t *testing.T) {
+	server := &Server{apiKey: "admin"}
+	server.SetReferentials(core.NewMemoryReferentials())
+
+	request, err := http.NewRequest("GET", "/_metrics", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	responseRecorder := httptest.NewRecorder()
+	server.HandleFlow(responseRecorder, request)
+
+	if status := responseRecorder.Code; status != http.StatusUnauthorized {
+		t.Errorf("Handler returned wrong status code:\n got %v\n want %v", status, http.StatusUnauthorized)
+	}
+}
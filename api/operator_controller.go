@@ -16,6 +16,12 @@ type OperatorController struct {
 	referential *core.Referential
 }
 
+// Attributes which can be selected and sorted in the Index requests
+var operatorIndexAttributes = indexAttributes{
+	"ObjectIDs": indexObjectIDs,
+	"Name":      func(v interface{}) interface{} { return v.(*model.Operator).Name },
+}
+
 func NewOperatorController(referential *core.Referential) ControllerInterface {
 	return &Controller{
 		restfulResource: &OperatorController{
@@ -41,8 +47,23 @@ func (controller *OperatorController) Index(response http.ResponseWriter, filter
 
 	logger.Log.Debugf("Operators Index")
 
-	jsonBytes, _ := json.Marshal(tx.Model().Operators().FindAll())
-	response.Write(jsonBytes)
+	query, err := newIndexQuery(tx, filters, INDEX_FILTER_OBJECTID, INDEX_FILTER_OBJECTID_KIND)
+	if err != nil {
+		http.Error(response, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	operators := tx.Model().Operators().FindAll()
+	items := make([]*indexItem, 0, len(operators))
+	for i := range operators {
+		operator := &operators[i]
+		items = append(items, &indexItem{
+			id:        string(operator.Id()),
+			value:     operator,
+			objectids: operator.ObjectIDs(),
+		})
+	}
+	query.write(response, items, operatorIndexAttributes)
 }
 
 func (controller *OperatorController) Show(response http.ResponseWriter, identifier string) {
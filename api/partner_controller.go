@@ -16,6 +16,13 @@ type PartnerController struct {
 	referential *core.Referential
 }
 
+// Attributes which can be selected and sorted in the Index requests
+var partnerIndexAttributes = indexAttributes{
+	"Slug":               func(v interface{}) interface{} { return string(v.(*core.Partner).Slug()) },
+	"Name":               func(v interface{}) interface{} { return v.(*core.Partner).Name },
+	"OperationnalStatus": func(v interface{}) interface{} { return string(v.(*core.Partner).OperationnalStatus()) },
+}
+
 func NewPartnerController(referential *core.Referential) ControllerInterface {
 	return &Controller{
 		restfulResource: &PartnerController{
@@ -122,8 +129,24 @@ func (controller *PartnerController) findPartner(identifier string) *core.Partne
 func (controller *PartnerController) Index(response http.ResponseWriter, filters url.Values) {
 	logger.Log.Debugf("Partners Index")
 
-	jsonBytes, _ := json.Marshal(controller.referential.Partners().FindAll())
-	response.Write(jsonBytes)
+	tx := controller.referential.NewTransaction()
+	defer tx.Close()
+
+	query, err := newIndexQuery(tx, filters)
+	if err != nil {
+		http.Error(response, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	partners := controller.referential.Partners().FindAll()
+	items := make([]*indexItem, 0, len(partners))
+	for _, partner := range partners {
+		items = append(items, &indexItem{
+			id:    string(partner.Id()),
+			value: partner,
+		})
+	}
+	query.write(response, items, partnerIndexAttributes)
 }
 
 func (controller *PartnerController) Show(response http.ResponseWriter, identifier string) {
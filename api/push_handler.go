@@ -45,6 +45,12 @@ func (handler *PushHandler) serve(response http.ResponseWriter, request *http.Re
 		return
 	}
 
+	limiter, ok := acquirePartnerRequest(handler.referential, partner, "push", response, request)
+	if !ok {
+		return
+	}
+	defer limiter.Release()
+
 	// Find Push connector
 	connector, ok := partner.Connector(core.PUSH_COLLECTOR)
 	if !ok {
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/core"
+)
+
+// Responds with a 429 status when the partner exceeds its rate limits.
+// When the request is accepted, the returned RateLimiter must be released.
+func acquirePartnerRequest(referential *core.Referential, partner *core.Partner, protocol string, response http.ResponseWriter, request *http.Request) (*core.RateLimiter, bool) {
+	limiter := partner.RateLimiter()
+	err := limiter.Acquire()
+	if err == nil {
+		return limiter, true
+	}
+
+	message := &audit.BigQueryMessage{
+		Protocol:     protocol,
+		Direction:    "received",
+		Partner:      string(partner.Slug()),
+		IPAddress:    request.RemoteAddr,
+		Status:       "RateLimited",
+		ErrorDetails: err.Error(),
+	}
+	audit.CurrentBigQuery(string(referential.Slug())).WriteEvent(message)
+
+	setRetryAfter(response, limiter)
+	http.Error(response, fmt.Sprintf("Partner %v: %v", partner.Slug(), err), http.StatusTooManyRequests)
+	return nil, false
+}
+
+// Defines the Retry-After header in seconds (at least one)
+func setRetryAfter(response http.ResponseWriter, limiter *core.RateLimiter) {
+	seconds := int(math.Ceil(limiter.RetryAfter().Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	response.Header().Set("Retry-After", strconv.Itoa(seconds))
+}
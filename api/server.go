@@ -1,6 +1,7 @@
 package api
 
 import (
+	"crypto/tls"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -105,6 +106,21 @@ func (server *Server) ListenAndServe() error {
 	return server.srv.ListenAndServe()
 }
 
+// Client certificates are requested but not verified, partners check their
+// fingerprint
+func (server *Server) ListenAndServeTLS(certFile, keyFile string) error {
+	server.srv = &http.Server{
+		Addr:         server.bind,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 60 * time.Second,
+		TLSConfig:    &tls.Config{ClientAuth: tls.RequestClientCert},
+	}
+	http.HandleFunc("/", server.HandleFlow)
+
+	logger.Log.Debugf("Starting TLS server on %s", server.bind)
+	return server.srv.ListenAndServeTLS(certFile, keyFile)
+}
+
 func (server *Server) handleControllers(response http.ResponseWriter, request *http.Request, requestData *RequestData) {
 	newController, ok := newControllerMap[requestData.Referential]
 	if !ok {
@@ -135,8 +151,10 @@ func (server *Server) isAdmin(r *http.Request) bool {
 }
 
 func (server *Server) isAuth(referential *core.Referential, request *http.Request) bool {
-	authToken := server.getToken(request)
+	return server.isValidToken(referential, server.getToken(request))
+}
 
+func (server *Server) isValidToken(referential *core.Referential, authToken string) bool {
 	if authToken == "" {
 		return false
 	}
@@ -184,6 +202,11 @@ func (server *Server) HandleFlow(response http.ResponseWriter, request *http.Req
 		return
 	}
 
+	if foundStrings[2] == "stream" {
+		server.handleStream(response, request, foundStrings[1])
+		return
+	}
+
 	requestData := NewRequestDataFromContent(foundStrings)
 	requestData.Method = request.Method
 	requestData.Url = request.URL.Path
@@ -271,3 +294,21 @@ func (server *Server) handleGtfs(response http.ResponseWriter, request *http.Req
 	gtfsHandler := NewGtfsHandler(foundReferential, server.getToken(request))
 	gtfsHandler.serve(response, request, resource)
 }
+
+func (server *Server) handleStream(response http.ResponseWriter, request *http.Request, referential string) {
+	foundReferential := server.CurrentReferentials().FindBySlug(core.ReferentialSlug(referential))
+	if foundReferential == nil {
+		http.Error(response, "Referential not found", http.StatusNotFound)
+		return
+	}
+	// EventSource clients can't define the Authorization header
+	if !server.isAuth(foundReferential, request) && !server.isValidToken(foundReferential, request.URL.Query().Get("token")) {
+		http.Error(response, "Unauthorized request", http.StatusUnauthorized)
+		return
+	}
+
+	logger.Log.Debugf("Stream request: %v", request)
+
+	streamHandler := NewStreamHandler(foundReferential)
+	streamHandler.serve(response, request)
+}
@@ -14,6 +14,9 @@ type SiriErrorResponse struct {
 	errCode        string
 	errDescription string
 	request        string
+	partner        string
+	status         string
+	httpStatus     int
 }
 
 func siriError(errCode, errDescription, referentialSlug string, response http.ResponseWriter) {
@@ -29,6 +32,18 @@ func siriErrorWithRequest(errCode, errDescription, referentialSlug, request stri
 	}.sendSiriError(referentialSlug)
 }
 
+func siriRateLimitedError(partner, errDescription, referentialSlug, request string, response http.ResponseWriter) {
+	SiriErrorResponse{
+		response:       response,
+		errCode:        "OtherError",
+		errDescription: errDescription,
+		request:        request,
+		partner:        partner,
+		status:         "RateLimited",
+		httpStatus:     http.StatusTooManyRequests,
+	}.sendSiriError(referentialSlug)
+}
+
 func (siriError SiriErrorResponse) sendSiriError(referentialSlug string) {
 	logger.Log.Debugf("Send SIRI error %v : %v", siriError.errCode, siriError.errDescription)
 
@@ -48,17 +63,25 @@ func (siriError SiriErrorResponse) sendSiriError(referentialSlug string) {
 	message := &audit.BigQueryMessage{
 		Protocol:  "siri",
 		Direction: "received",
+		Partner:   siriError.partner,
 		Status:    "Error",
 		// Type:         "siri-error",
 		ErrorDetails: fmt.Sprintf("%v: %v", siriError.errCode, siriError.errDescription),
 		// ResponseRawMessage: soapEnvelope.String(),
 	}
 
+	if siriError.status != "" {
+		message.Status = siriError.status
+	}
+
 	if siriError.request != "" {
 		logStashEvent["requestXML"] = siriError.request
 		// message.RequestRawMessage = siriError.request
 	}
 
+	if siriError.httpStatus != 0 {
+		siriError.response.WriteHeader(siriError.httpStatus)
+	}
 	soapEnvelope.WriteTo(siriError.response)
 	message.ResponseSize = soapEnvelope.Length()
 
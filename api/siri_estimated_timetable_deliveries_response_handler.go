@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/core"
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/siri"
+)
+
+type SIRIEstimatedTimetableRequestDeliveriesResponseHandler struct {
+	xmlRequest  *siri.XMLNotifyEstimatedTimetable
+	referential *core.Referential
+}
+
+func (handler *SIRIEstimatedTimetableRequestDeliveriesResponseHandler) RequestorRef() string {
+	return handler.xmlRequest.ProducerRef()
+}
+
+func (handler *SIRIEstimatedTimetableRequestDeliveriesResponseHandler) ConnectorType() string {
+	return core.SIRI_ESTIMATED_TIMETABLE_SUBSCRIPTION_COLLECTOR
+}
+
+func (handler *SIRIEstimatedTimetableRequestDeliveriesResponseHandler) Respond(connector core.Connector, rw http.ResponseWriter, message *audit.BigQueryMessage) {
+	logger.Log.Debugf("NotifyEstimatedTimetable: %s", handler.xmlRequest.ResponseMessageIdentifier())
+
+	t := clock.DefaultClock().Now()
+
+	connector.(core.EstimatedTimetableSubscriptionCollector).HandleNotifyEstimatedTimetable(handler.xmlRequest)
+
+	rw.WriteHeader(http.StatusOK)
+
+	message.Type = "NotifyEstimatedTimetable"
+	message.RequestRawMessage = handler.xmlRequest.RawXML()
+	message.ProcessingTime = clock.DefaultClock().Since(t).Seconds()
+	message.RequestIdentifier = handler.xmlRequest.RequestMessageRef()
+	message.ResponseIdentifier = handler.xmlRequest.ResponseMessageIdentifier()
+
+	subIds := make(map[string]struct{})
+	for _, delivery := range handler.xmlRequest.EstimatedTimetableDeliveries() {
+		subIds[delivery.SubscriptionRef()] = struct{}{}
+		if !delivery.Status() {
+			message.Status = "Error"
+		}
+	}
+	subs := make([]string, 0, len(subIds))
+	for k := range subIds {
+		subs = append(subs, k)
+	}
+	message.SubscriptionIdentifiers = subs
+	audit.CurrentBigQuery(string(handler.referential.Slug())).WriteEvent(message)
+}
@@ -57,6 +57,21 @@ func (handler *SIRIHandler) requestHandler(envelope *siri.SOAPEnvelope) SIRIRequ
 			xmlRequest:  siri.NewXMLNotifyGeneralMessage(envelope.Body()),
 			referential: handler.referential,
 		}
+	case "NotifySituationExchange":
+		return &SIRISituationExchangeRequestDeliveriesResponseHandler{
+			xmlRequest:  siri.NewXMLNotifySituationExchange(envelope.Body()),
+			referential: handler.referential,
+		}
+	case "NotifyVehicleMonitoring":
+		return &SIRIVehicleMonitoringRequestDeliveriesResponseHandler{
+			xmlRequest:  siri.NewXMLNotifyVehicleMonitoring(envelope.Body()),
+			referential: handler.referential,
+		}
+	case "NotifyEstimatedTimetable":
+		return &SIRIEstimatedTimetableRequestDeliveriesResponseHandler{
+			xmlRequest:  siri.NewXMLNotifyEstimatedTimetable(envelope.Body()),
+			referential: handler.referential,
+		}
 	case "NotifySubscriptionTerminated":
 		return &SIRINotifySubscriptionTerminatedHandler{
 			xmlRequest:  siri.NewXMLNotifySubscriptionTerminated(envelope.Body()),
@@ -87,11 +102,26 @@ func (handler *SIRIHandler) requestHandler(envelope *siri.SOAPEnvelope) SIRIRequ
 			xmlRequest:  siri.NewXMLGetGeneralMessage(envelope.Body()),
 			referential: handler.referential,
 		}
+	case "GetProductionTimetable":
+		return &SIRIProductionTimetableRequestHandler{
+			xmlRequest:  siri.NewXMLGetProductionTimetable(envelope.Body()),
+			referential: handler.referential,
+		}
+	case "GetSituationExchange":
+		return &SIRISituationExchangeRequestHandler{
+			xmlRequest:  siri.NewXMLGetSituationExchange(envelope.Body()),
+			referential: handler.referential,
+		}
 	case "GetEstimatedTimetable":
 		return &SIRIEstimatedTimetableRequestHandler{
 			xmlRequest:  siri.NewXMLGetEstimatedTimetable(envelope.Body()),
 			referential: handler.referential,
 		}
+	case "GetVehicleMonitoring":
+		return &SIRIVehicleMonitoringRequestHandler{
+			xmlRequest:  siri.NewXMLGetVehicleMonitoring(envelope.Body()),
+			referential: handler.referential,
+		}
 	}
 	return nil
 }
@@ -139,6 +169,18 @@ func (handler *SIRIHandler) serve(response http.ResponseWriter, request *http.Re
 		siriErrorWithRequest("UnknownCredential", fmt.Sprintf("RequestorRef Unknown '%s'", requestHandler.RequestorRef()), string(handler.referential.Slug()), envelope.Body().String(), response)
 		return
 	}
+	if !partner.Authenticate(request) {
+		siriErrorWithRequest("UnknownCredential", fmt.Sprintf("Authentication failed for RequestorRef '%s'", requestHandler.RequestorRef()), string(handler.referential.Slug()), envelope.Body().String(), response)
+		return
+	}
+	limiter := partner.RateLimiter()
+	if err := limiter.Acquire(); err != nil {
+		setRetryAfter(response, limiter)
+		siriRateLimitedError(string(partner.Slug()), fmt.Sprintf("Partner %v: %v", partner.Slug(), err), string(handler.referential.Slug()), envelope.Body().String(), response)
+		return
+	}
+	defer limiter.Release()
+
 	connector, ok := partner.Connector(requestHandler.ConnectorType())
 	if !ok {
 		siriErrorWithRequest("NotFound", fmt.Sprintf("No Connectors for %v", envelope.BodyType()), string(handler.referential.Slug()), envelope.Body().String(), response)
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -772,3 +773,68 @@ func Test_SIRIHandler_LinesDiscovery(t *testing.T) {
 		t.Errorf("Unexpected response body:\n expected: %v\n got: %v", expectedResponseBody, responseBody)
 	}
 }
+
+func Test_SIRIHandler_Authentication(t *testing.T) {
+	server, referential := siriHandler_PrepareServer()
+	partner, _ := referential.Partners().FindBySlug("partner")
+	partner.SetSetting("local_authentication.mode", "bearer")
+	partner.SetSetting("local_authentication.token", "secret")
+
+	for _, authorization := range []string{"", "Bearer wrong", "Bearer secret"} {
+		soapEnvelope := siri.NewSOAPEnvelopeBuffer()
+		xml, err := siri.NewSIRICheckStatusRequest("Ara",
+			clock.DefaultClock().Now(),
+			"Ara:Message::6ba7b814-9dad-11d1-0-00c04fd430c8:LOC").BuildXML()
+		if err != nil {
+			t.Fatal(err)
+		}
+		soapEnvelope.WriteXML(xml)
+
+		request, err := http.NewRequest("POST", "/default/siri", soapEnvelope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+		responseRecorder := httptest.NewRecorder()
+		server.HandleFlow(responseRecorder, request)
+
+		authenticated := !strings.Contains(responseRecorder.Body.String(), "UnknownCredential")
+		if expected := authorization == "Bearer secret"; authenticated != expected {
+			t.Errorf("Wrong authentication with Authorization '%v':\n got: %v\n want: %v", authorization, authenticated, expected)
+		}
+	}
+}
+
+func Test_SIRIHandler_RateLimit(t *testing.T) {
+	server, referential := siriHandler_PrepareServer()
+	partner, _ := referential.Partners().FindBySlug("partner")
+	partner.SetSetting("rate_limit.requests_per_minute", "1")
+
+	for i, expectedStatus := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		soapEnvelope := siri.NewSOAPEnvelopeBuffer()
+		xml, err := siri.NewSIRICheckStatusRequest("Ara",
+			clock.DefaultClock().Now(),
+			"Ara:Message::6ba7b814-9dad-11d1-0-00c04fd430c8:LOC").BuildXML()
+		if err != nil {
+			t.Fatal(err)
+		}
+		soapEnvelope.WriteXML(xml)
+
+		request, _ := http.NewRequest("POST", "/default/siri", soapEnvelope)
+		responseRecorder := httptest.NewRecorder()
+		server.HandleFlow(responseRecorder, request)
+
+		if responseRecorder.Code != expectedStatus {
+			t.Errorf("Wrong status for request %d:\n got: %v\n want: %v", i, responseRecorder.Code, expectedStatus)
+		}
+		limited := expectedStatus == http.StatusTooManyRequests
+		if strings.Contains(responseRecorder.Body.String(), "S:OtherError") != limited {
+			t.Errorf("Wrong SOAP fault for request %d: %v", i, responseRecorder.Body.String())
+		}
+		if (responseRecorder.Header().Get("Retry-After") != "") != limited {
+			t.Errorf("Wrong Retry-After header for request %d: %q", i, responseRecorder.Header().Get("Retry-After"))
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/core"
+	"bitbucket.org/enroute-mobi/ara/logger"
+)
+
+type SIRILiteEstimatedTimetableRequestHandler struct {
+	requestUrl  string
+	filters     url.Values
+	referential *core.Referential
+}
+
+func (handler *SIRILiteEstimatedTimetableRequestHandler) ConnectorType() string {
+	return core.SIRI_LITE_ESTIMATED_TIMETABLE_REQUEST_BROADCASTER
+}
+
+func (handler *SIRILiteEstimatedTimetableRequestHandler) Respond(connector core.Connector, rw http.ResponseWriter, message *audit.BigQueryMessage) {
+	logger.Log.Debugf("Siri Lite EstimatedTimetable %s", handler.requestUrl)
+
+	t := clock.DefaultClock().Now()
+
+	response := connector.(core.LiteEstimatedTimetableRequestBroadcaster).RequestLine(handler.requestUrl, handler.filters, message)
+
+	jsonBytes, err := json.Marshal(response)
+	if err != nil {
+		http.Error(rw, "Internal Server Error", http.StatusInternalServerError)
+		logger.Log.Debugf("Internal error while Marshaling a SiriLite response in estimated timetable handler: %v", err)
+		return
+	}
+	n, err := rw.Write(jsonBytes)
+	if err != nil {
+		logger.Log.Debugf("Internal error while writing a SiriLite response in estimated timetable handler: %v", err)
+		http.Error(rw, "Internal Server Error", http.StatusInternalServerError)
+	}
+
+	message.Type = "EstimatedTimetableRequest"
+	message.ResponseRawMessage = string(jsonBytes)
+	message.ResponseSize = int64(n)
+	message.ProcessingTime = clock.DefaultClock().Since(t).Seconds()
+	audit.CurrentBigQuery(string(handler.referential.Slug())).WriteEvent(message)
+}
@@ -32,20 +32,37 @@ func (handler *SIRILiteHandler) requestHandler(requestData *SIRIRequestData) SIR
 			filters:     requestData.Filters,
 			referential: handler.referential,
 		}
+	case "stop-monitoring":
+		return &SIRILiteStopMonitoringRequestHandler{
+			requestUrl:  requestData.Url,
+			filters:     requestData.Filters,
+			referential: handler.referential,
+		}
+	case "estimated-timetable":
+		return &SIRILiteEstimatedTimetableRequestHandler{
+			requestUrl:  requestData.Url,
+			filters:     requestData.Filters,
+			referential: handler.referential,
+		}
+	case "general-message":
+		return &SIRILiteGeneralMessageRequestHandler{
+			requestUrl:  requestData.Url,
+			filters:     requestData.Filters,
+			referential: handler.referential,
+		}
+	case "stoppoints-discovery":
+		return &SIRILiteStopPointsDiscoveryRequestHandler{
+			requestUrl:  requestData.Url,
+			filters:     requestData.Filters,
+			referential: handler.referential,
+		}
+	case "lines-discovery":
+		return &SIRILiteLinesDiscoveryRequestHandler{
+			requestUrl:  requestData.Url,
+			filters:     requestData.Filters,
+			referential: handler.referential,
+		}
 	}
-	// case "CheckStatus":
-	// case "GetStopMonitoring":
-	// case "DeleteSubscription":
-	// case "Subscribe":
-	// case "NotifyStopMonitoring":
-	// case "NotifyGeneralMessage":
-	// case "SubscriptionTerminatedNotification":
-	// case "StopPointsDiscovery":
-	// case "LinesDiscovery":
-	// case "GetSiriService":
-	// case "GetGeneralMessage":
-	// case "GetEstimatedTimetable":
-	// }
 	return nil
 }
 
@@ -67,6 +84,12 @@ func (handler *SIRILiteHandler) serve(response http.ResponseWriter, request *htt
 		return
 	}
 
+	limiter, ok := acquirePartnerRequest(handler.referential, partner, "siri-lite", response, request)
+	if !ok {
+		return
+	}
+	defer limiter.Release()
+
 	requestHandler := handler.requestHandler(requestData)
 	if requestHandler == nil {
 		http.Error(response, "The SIRI Lite request doesn’t match a defined broadcast", http.StatusNotFound)
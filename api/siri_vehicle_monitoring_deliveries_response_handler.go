@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/core"
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/siri"
+)
+
+type SIRIVehicleMonitoringRequestDeliveriesResponseHandler struct {
+	xmlRequest  *siri.XMLNotifyVehicleMonitoring
+	referential *core.Referential
+}
+
+func (handler *SIRIVehicleMonitoringRequestDeliveriesResponseHandler) RequestorRef() string {
+	return handler.xmlRequest.ProducerRef()
+}
+
+func (handler *SIRIVehicleMonitoringRequestDeliveriesResponseHandler) ConnectorType() string {
+	return core.SIRI_VEHICLE_MONITORING_SUBSCRIPTION_COLLECTOR
+}
+
+func (handler *SIRIVehicleMonitoringRequestDeliveriesResponseHandler) Respond(connector core.Connector, rw http.ResponseWriter, message *audit.BigQueryMessage) {
+	logger.Log.Debugf("NotifyVehicleMonitoring: %s", handler.xmlRequest.ResponseMessageIdentifier())
+
+	t := clock.DefaultClock().Now()
+
+	connector.(core.VehicleMonitoringSubscriptionCollector).HandleNotifyVehicleMonitoring(handler.xmlRequest)
+
+	rw.WriteHeader(http.StatusOK)
+
+	message.Type = "NotifyVehicleMonitoring"
+	message.RequestRawMessage = handler.xmlRequest.RawXML()
+	message.ProcessingTime = clock.DefaultClock().Since(t).Seconds()
+	message.RequestIdentifier = handler.xmlRequest.RequestMessageRef()
+	message.ResponseIdentifier = handler.xmlRequest.ResponseMessageIdentifier()
+
+	subIds := make(map[string]struct{})
+	for _, delivery := range handler.xmlRequest.VehicleMonitoringDeliveries() {
+		subIds[delivery.SubscriptionRef()] = struct{}{}
+		if !delivery.Status() {
+			message.Status = "Error"
+		}
+	}
+	subs := make([]string, 0, len(subIds))
+	for k := range subIds {
+		subs = append(subs, k)
+	}
+	message.SubscriptionIdentifiers = subs
+	audit.CurrentBigQuery(string(handler.referential.Slug())).WriteEvent(message)
+}
@@ -16,6 +16,17 @@ type SituationController struct {
 	referential *core.Referential
 }
 
+// Attributes which can be selected and sorted in the Index requests
+var situationIndexAttributes = indexAttributes{
+	"ObjectIDs":   indexObjectIDs,
+	"RecordedAt":  func(v interface{}) interface{} { return v.(*model.Situation).RecordedAt },
+	"ValidUntil":  func(v interface{}) interface{} { return v.(*model.Situation).ValidUntil },
+	"Version":     func(v interface{}) interface{} { return v.(*model.Situation).Version },
+	"ProducerRef": func(v interface{}) interface{} { return v.(*model.Situation).ProducerRef },
+	"Severity":    func(v interface{}) interface{} { return v.(*model.Situation).Severity },
+	"Summary":     func(v interface{}) interface{} { return v.(*model.Situation).Summary },
+}
+
 func NewSituationController(referential *core.Referential) ControllerInterface {
 	return &Controller{
 		restfulResource: &SituationController{
@@ -41,8 +52,40 @@ func (controller *SituationController) Index(response http.ResponseWriter, filte
 
 	logger.Log.Debugf("Situations Index")
 
-	jsonBytes, _ := json.Marshal(tx.Model().Situations().FindAll())
-	response.Write(jsonBytes)
+	query, err := newIndexQuery(tx, filters, INDEX_FILTER_OBJECTID, INDEX_FILTER_OBJECTID_KIND, INDEX_FILTER_LINE, INDEX_FILTER_STOP_AREA, INDEX_FILTER_AFTER, INDEX_FILTER_BEFORE)
+	if err != nil {
+		http.Error(response, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	situations := tx.Model().Situations().FindAll()
+	items := make([]*indexItem, 0, len(situations))
+	for i := range situations {
+		situation := &situations[i]
+		item := &indexItem{
+			id:        string(situation.Id()),
+			value:     situation,
+			objectids: situation.ObjectIDs(),
+			time:      situation.RecordedAt,
+		}
+		if query.lineIds != nil || query.stopAreaIds != nil {
+			references := append([]*model.Reference{}, situation.References...)
+			references = append(references, situation.Affects...)
+			for _, reference := range references {
+				if reference.ObjectId == nil {
+					continue
+				}
+				if line, ok := tx.Model().Lines().FindByObjectId(*reference.ObjectId); ok {
+					item.lineIds = append(item.lineIds, line.Id())
+				}
+				if stopArea, ok := tx.Model().StopAreas().FindByObjectId(*reference.ObjectId); ok {
+					item.stopAreaIds = append(item.stopAreaIds, stopArea.Id())
+				}
+			}
+		}
+		items = append(items, item)
+	}
+	query.write(response, items, situationIndexAttributes)
 }
 
 func (controller *SituationController) Show(response http.ResponseWriter, identifier string) {
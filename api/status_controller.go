@@ -3,18 +3,33 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"bitbucket.org/enroute-mobi/ara/version"
 )
 
-type StatusController struct{}
+type StatusController struct {
+	server *Server
+}
+
 type Status struct {
-	Status  string `json:"status"`
-	Version string `json:"version"`
+	Status              string               `json:"status"`
+	Version             string               `json:"version"`
+	OpenCircuitBreakers []OpenCircuitBreaker `json:"open_circuit_breakers,omitempty"`
+}
+
+// Partner which doesn't receive requests anymore after too many failures
+type OpenCircuitBreaker struct {
+	Referential string     `json:"referential"`
+	Partner     string     `json:"partner"`
+	Failures    int        `json:"failures"`
+	OpenUntil   *time.Time `json:"open_until,omitempty"`
 }
 
 func NewStatusController(server *Server) ControllerInterface {
-	return &StatusController{}
+	return &StatusController{
+		server: server,
+	}
 }
 
 func (controller *StatusController) serve(response http.ResponseWriter, request *http.Request, requestData *RequestData) {
@@ -23,6 +38,25 @@ func (controller *StatusController) serve(response http.ResponseWriter, request
 		Version: version.Value(),
 	}
 
+	for _, referential := range controller.server.CurrentReferentials().FindAll() {
+		for _, partner := range referential.Partners().FindAll() {
+			circuitBreaker := partner.CircuitBreaker()
+			if circuitBreaker == nil {
+				continue
+			}
+			state := circuitBreaker.State()
+			if !state.Open {
+				continue
+			}
+			status.OpenCircuitBreakers = append(status.OpenCircuitBreakers, OpenCircuitBreaker{
+				Referential: string(referential.Slug()),
+				Partner:     string(partner.Slug()),
+				Failures:    state.Failures,
+				OpenUntil:   state.OpenUntil,
+			})
+		}
+	}
+
 	jsonBytes, _ := json.Marshal(status)
 	response.Write(jsonBytes)
 }
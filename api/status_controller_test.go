@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"bitbucket.org/enroute-mobi/ara/core"
 )
 
 func statusCheckResponseStatus(responseRecorder *httptest.ResponseRecorder, t *testing.T) {
@@ -53,3 +55,30 @@ func Test_status_check_version(t *testing.T) {
 	}
 
 }
+
+func Test_status_check_openCircuitBreakers(t *testing.T) {
+	server, request, responseRecorder := statusPrepareRequest("GET", t)
+
+	referentials := core.NewMemoryReferentials()
+	referential := referentials.New("referential")
+	referentials.Save(referential)
+	partner := referential.Partners().New("partner")
+	partner.SetSetting(core.REMOTE_CIRCUIT_BREAKER_THRESHOLD, "1")
+	referential.Partners().Save(partner)
+	partner.CircuitBreaker().Failure()
+	server.SetReferentials(referentials)
+
+	server.HandleFlow(responseRecorder, request)
+	statusCheckResponseStatus(responseRecorder, t)
+
+	status := &Status{}
+	if err := json.Unmarshal(responseRecorder.Body.Bytes(), status); err != nil {
+		t.Fatal(err)
+	}
+	if len(status.OpenCircuitBreakers) != 1 {
+		t.Fatalf("Status should have an open circuit breaker, got %v", status.OpenCircuitBreakers)
+	}
+	if circuitBreaker := status.OpenCircuitBreakers[0]; circuitBreaker.Referential != "referential" || circuitBreaker.Partner != "partner" || circuitBreaker.Failures != 1 {
+		t.Errorf("Wrong open circuit breaker: %v", circuitBreaker)
+	}
+}
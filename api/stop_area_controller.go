@@ -16,6 +16,19 @@ type StopAreaController struct {
 	referential *core.Referential
 }
 
+// Attributes which can be selected and sorted in the Index requests
+var stopAreaIndexAttributes = indexAttributes{
+	"ObjectIDs":       indexObjectIDs,
+	"Name":            func(v interface{}) interface{} { return v.(*model.StopArea).Name },
+	"ParentId":        func(v interface{}) interface{} { return string(v.(*model.StopArea).ParentId) },
+	"ReferentId":      func(v interface{}) interface{} { return string(v.(*model.StopArea).ReferentId) },
+	"Monitored":       func(v interface{}) interface{} { return v.(*model.StopArea).Monitored },
+	"CollectedAlways": func(v interface{}) interface{} { return v.(*model.StopArea).CollectedAlways },
+	"CollectedAt":     func(v interface{}) interface{} { return v.(*model.StopArea).CollectedAt() },
+	"Longitude":       func(v interface{}) interface{} { return v.(*model.StopArea).Longitude },
+	"Latitude":        func(v interface{}) interface{} { return v.(*model.StopArea).Latitude },
+}
+
 func NewStopAreaController(referential *core.Referential) ControllerInterface {
 	return &Controller{
 		restfulResource: &StopAreaController{
@@ -41,13 +54,33 @@ func (controller *StopAreaController) Index(response http.ResponseWriter, filter
 
 	logger.Log.Debugf("StopAreas Index")
 
+	query, err := newIndexQuery(tx, filters, INDEX_FILTER_OBJECTID, INDEX_FILTER_OBJECTID_KIND, INDEX_FILTER_LINE, INDEX_FILTER_STOP_AREA, INDEX_FILTER_COLLECTED, INDEX_FILTER_MONITORED)
+	if err != nil {
+		http.Error(response, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
 	stime := controller.referential.Clock().Now()
 	sas := tx.Model().StopAreas().FindAll()
 	logger.Log.Debugf("StopAreaController FindAll time : %v", controller.referential.Clock().Since(stime))
+
+	items := make([]*indexItem, 0, len(sas))
+	for i := range sas {
+		stopArea := &sas[i]
+		items = append(items, &indexItem{
+			id:          string(stopArea.Id()),
+			value:       stopArea,
+			objectids:   stopArea.ObjectIDs(),
+			lineIds:     stopArea.LineIds,
+			stopAreaIds: []model.StopAreaId{stopArea.Id()},
+			collected:   !stopArea.CollectedAt().IsZero(),
+			monitored:   stopArea.Monitored,
+		})
+	}
+
 	stime = controller.referential.Clock().Now()
-	jsonBytes, _ := json.Marshal(sas)
+	query.write(response, items, stopAreaIndexAttributes)
 	logger.Log.Debugf("StopAreaController Json Marshal time : %v ", controller.referential.Clock().Since(stime))
-	response.Write(jsonBytes)
 }
 
 func (controller *StopAreaController) Show(response http.ResponseWriter, identifier string) {
@@ -65,6 +98,36 @@ func (controller *StopAreaController) Show(response http.ResponseWriter, identif
 	response.Write(jsonBytes)
 }
 
+func (controller *StopAreaController) Action(response http.ResponseWriter, requestData *RequestData) {
+	if requestData.Action == "group" && requestData.Method == "GET" {
+		controller.group(response, requestData.Id)
+		return
+	}
+	http.Error(response, fmt.Sprintf("Action not supported: %s", requestData.Action), http.StatusBadRequest)
+}
+
+// Returns the StopAreas merged with the given one in the broadcasts
+func (controller *StopAreaController) group(response http.ResponseWriter, identifier string) {
+	tx := controller.referential.NewTransaction()
+	defer tx.Close()
+
+	stopArea, ok := controller.findStopArea(tx, identifier)
+	if !ok {
+		http.Error(response, fmt.Sprintf("Stop area not found: %s", identifier), http.StatusNotFound)
+		return
+	}
+	logger.Log.Debugf("Get stopArea %s group", identifier)
+
+	group := []*model.StopArea{}
+	for _, id := range core.NewMerger(controller.referential, tx).StopAreaGroup(stopArea.Id()) {
+		groupStopArea, _ := tx.Model().StopAreas().Find(id)
+		group = append(group, &groupStopArea)
+	}
+
+	jsonBytes, _ := json.Marshal(group)
+	response.Write(jsonBytes)
+}
+
 func (controller *StopAreaController) Delete(response http.ResponseWriter, identifier string) {
 	// New transaction
 	tx := controller.referential.NewTransaction()
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"bitbucket.org/enroute-mobi/ara/core"
@@ -171,3 +172,57 @@ func Test_StopAreaController_FindStopArea(t *testing.T) {
 		t.Error("Can't find StopArea by Id")
 	}
 }
+
+func Test_StopAreaController_Group(t *testing.T) {
+	referentials := core.NewMemoryReferentials()
+	server := &Server{}
+	server.SetReferentials(referentials)
+	referential := referentials.New("default")
+	referential.Tokens = []string{"testToken"}
+	referential.Settings[core.REFERENTIAL_SETTING_STOP_AREA_EQUIVALENCES] = "kind1:referent,kind2:equivalent"
+	referential.Save()
+
+	referent := referential.Model().StopAreas().New()
+	referent.Name = "Referent"
+	referent.SetObjectID(model.NewObjectID("kind1", "referent"))
+	referent.Save()
+
+	stopArea := referential.Model().StopAreas().New()
+	stopArea.Name = "StopArea"
+	stopArea.ReferentId = referent.Id()
+	stopArea.SetObjectID(model.NewObjectID("kind1", "value"))
+	stopArea.Save()
+
+	equivalent := referential.Model().StopAreas().New()
+	equivalent.Name = "Equivalent"
+	equivalent.SetObjectID(model.NewObjectID("kind2", "equivalent"))
+	equivalent.Save()
+
+	other := referential.Model().StopAreas().New()
+	other.Name = "Other"
+	other.Save()
+
+	request, _ := http.NewRequest("GET", "/default/stop_areas/kind1:value/group", nil)
+	request.Header.Set("Authorization", "Token token=testToken")
+	responseRecorder := httptest.NewRecorder()
+	server.HandleFlow(responseRecorder, request)
+
+	checkStopAreaResponseStatus(responseRecorder, t)
+	body := responseRecorder.Body.String()
+	for _, name := range []string{"Referent", "StopArea", "Equivalent"} {
+		if !strings.Contains(body, fmt.Sprintf(`"Name":"%s"`, name)) {
+			t.Errorf("Group should contain %v, got %v", name, body)
+		}
+	}
+	if strings.Contains(body, `"Name":"Other"`) {
+		t.Errorf("Group should not contain other StopAreas, got %v", body)
+	}
+
+	request, _ = http.NewRequest("GET", "/default/stop_areas/unknown/group", nil)
+	request.Header.Set("Authorization", "Token token=testToken")
+	responseRecorder = httptest.NewRecorder()
+	server.HandleFlow(responseRecorder, request)
+	if responseRecorder.Code != http.StatusNotFound {
+		t.Errorf("Wrong status for unknown StopArea: %v", responseRecorder.Code)
+	}
+}
@@ -17,6 +17,19 @@ type StopVisitController struct {
 	referential *core.Referential
 }
 
+// Attributes which can be selected and sorted in the Index requests
+var stopVisitIndexAttributes = indexAttributes{
+	"ObjectIDs":        indexObjectIDs,
+	"StopAreaId":       func(v interface{}) interface{} { return string(v.(*model.StopVisit).StopAreaId) },
+	"VehicleJourneyId": func(v interface{}) interface{} { return string(v.(*model.StopVisit).VehicleJourneyId) },
+	"PassageOrder":     func(v interface{}) interface{} { return v.(*model.StopVisit).PassageOrder },
+	"ArrivalStatus":    func(v interface{}) interface{} { return string(v.(*model.StopVisit).ArrivalStatus) },
+	"DepartureStatus":  func(v interface{}) interface{} { return string(v.(*model.StopVisit).DepartureStatus) },
+	"VehicleAtStop":    func(v interface{}) interface{} { return v.(*model.StopVisit).VehicleAtStop },
+	"RecordedAt":       func(v interface{}) interface{} { return v.(*model.StopVisit).RecordedAt },
+	"ReferenceTime":    func(v interface{}) interface{} { return v.(*model.StopVisit).ReferenceTime() },
+}
+
 func NewStopVisitController(referential *core.Referential) ControllerInterface {
 	return &Controller{
 		restfulResource: &StopVisitController{
@@ -36,51 +49,82 @@ func (controller *StopVisitController) findStopVisit(tx *model.Transaction, iden
 	return tx.Model().StopVisits().Find(model.StopVisitId(identifier))
 }
 
-func (controller *StopVisitController) filterStopVisits(stopVisits []model.StopVisit, filters url.Values) []model.StopVisit {
-	selectors := []model.StopVisitSelector{}
-	filteredStopVisits := []model.StopVisit{}
-
-	for key, value := range filters {
-		switch key {
-		case "After":
-			layout := "2006/01/02-15:04:05"
-			startTime, err := time.Parse(layout, value[0])
-			if err != nil {
-				continue
-			}
-			selectors = append(selectors, model.StopVisitSelectorAfterTime(startTime))
-		case "Before":
-			layout := "2006/01/02-15:04:05"
-			endTime, err := time.Parse(layout, value[0])
-			if err != nil {
-				continue
-			}
-			selectors = append(selectors, model.StopVisitSelectorAfterTime(endTime))
-		case "StopArea":
-			selectors = append(selectors, model.StopVisitSelectByStopAreaId(model.StopAreaId(value[0])))
+func (controller *StopVisitController) Index(response http.ResponseWriter, filters url.Values) {
+	tx := controller.referential.NewTransaction()
+	defer tx.Close()
+
+	// Former StopArea filter
+	if stopAreaId := filters.Get("StopArea"); stopAreaId != "" && filters.Get(INDEX_FILTER_STOP_AREA) == "" {
+		filters.Set(INDEX_FILTER_STOP_AREA, stopAreaId)
+	}
+	query, err := newIndexQuery(tx, filters, INDEX_FILTER_OBJECTID, INDEX_FILTER_OBJECTID_KIND, INDEX_FILTER_LINE, INDEX_FILTER_STOP_AREA, INDEX_FILTER_AFTER, INDEX_FILTER_BEFORE, INDEX_FILTER_COLLECTED, INDEX_FILTER_MONITORED)
+	if err != nil {
+		http.Error(response, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var stopVisits []model.StopVisit
+	if at := filters.Get("at"); at != "" {
+		t, err := parseHistoryTime(at)
+		if err != nil {
+			http.Error(response, fmt.Sprintf("Invalid request: can't parse at: %v", err), http.StatusBadRequest)
+			return
 		}
+		stopVisits = tx.Model().History().StopVisitsAt(t, tx.Model().StopVisits().FindAll())
+	} else {
+		stopVisits = tx.Model().StopVisits().FindAll()
 	}
 
-	selector := model.CompositeStopVisitSelector(selectors)
-	for _, sv := range stopVisits {
-		if !selector(sv) {
-			continue
+	logger.Log.Debugf("StopVisits Index")
+
+	items := make([]*indexItem, 0, len(stopVisits))
+	for i := range stopVisits {
+		stopVisit := &stopVisits[i]
+		item := &indexItem{
+			id:          string(stopVisit.Id()),
+			value:       stopVisit,
+			objectids:   stopVisit.ObjectIDs(),
+			stopAreaIds: []model.StopAreaId{stopVisit.StopAreaId},
+			time:        stopVisit.ReferenceTime(),
+			collected:   stopVisit.IsCollected(),
+		}
+		if query.lineIds != nil || query.monitored != nil {
+			if vehicleJourney, ok := tx.Model().VehicleJourneys().Find(stopVisit.VehicleJourneyId); ok {
+				item.lineIds = []model.LineId{vehicleJourney.LineId}
+				item.monitored = vehicleJourney.Monitored
+			}
 		}
-		filteredStopVisits = append(filteredStopVisits, sv)
+		items = append(items, item)
 	}
+	query.write(response, items, stopVisitIndexAttributes)
+}
 
-	return filteredStopVisits
+func (controller *StopVisitController) Action(response http.ResponseWriter, requestData *RequestData) {
+	if requestData.Action == "history" && requestData.Method == "GET" {
+		controller.history(response, requestData.Id)
+		return
+	}
+	http.Error(response, fmt.Sprintf("Action not supported: %s", requestData.Action), http.StatusBadRequest)
 }
 
-func (controller *StopVisitController) Index(response http.ResponseWriter, filters url.Values) {
+func (controller *StopVisitController) history(response http.ResponseWriter, identifier string) {
 	tx := controller.referential.NewTransaction()
 	defer tx.Close()
 
-	stopVisits := tx.Model().StopVisits().FindAll()
-	filteredStopVisits := controller.filterStopVisits(stopVisits, filters)
+	// A StopVisit removed from the model can still have a history
+	id := model.StopVisitId(identifier)
+	if stopVisit, ok := controller.findStopVisit(tx, identifier); ok {
+		id = stopVisit.Id()
+	}
 
-	logger.Log.Debugf("StopVisits Index")
-	jsonBytes, _ := json.Marshal(filteredStopVisits)
+	entries := tx.Model().History().StopVisit(id)
+	if len(entries) == 0 {
+		http.Error(response, fmt.Sprintf("Stop visit history not found: %s", identifier), http.StatusNotFound)
+		return
+	}
+	logger.Log.Debugf("Get stopVisit %s history", identifier)
+
+	jsonBytes, _ := json.Marshal(entries)
 	response.Write(jsonBytes)
 }
 
@@ -200,3 +244,12 @@ func (controller *StopVisitController) Create(response http.ResponseWriter, body
 	jsonBytes, _ := stopVisit.MarshalJSON()
 	response.Write(jsonBytes)
 }
+
+// Accepts the After/Before layout or RFC3339
+func parseHistoryTime(value string) (time.Time, error) {
+	t, err := time.Parse("2006/01/02-15:04:05", value)
+	if err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -180,6 +181,60 @@ func Test_StopVisitController_FindStopVisit(t *testing.T) {
 	}
 }
 
+func Test_StopVisitController_History(t *testing.T) {
+	referentials := core.NewMemoryReferentials()
+	server := &Server{}
+	server.SetReferentials(referentials)
+	referential := referentials.New("default")
+	referential.Tokens = []string{"testToken"}
+	referential.Save()
+
+	stopVisit := referential.Model().StopVisits().New()
+	stopVisit.SetObjectID(model.NewObjectID("kind", "value"))
+	stopVisit.Save()
+
+	recordedAt := time.Date(2017, time.January, 1, 12, 0, 0, 0, time.UTC)
+	stopVisit.ArrivalStatus = model.STOP_VISIT_ARRIVAL_ONTIME
+	referential.Model().History().RecordStopVisit(&stopVisit, "partner", recordedAt)
+	stopVisit.ArrivalStatus = model.STOP_VISIT_ARRIVAL_DELAYED
+	referential.Model().History().RecordStopVisit(&stopVisit, "partner", recordedAt.Add(time.Minute))
+
+	// StopVisit unchanged since the Model was loaded
+	unchanged := referential.Model().StopVisits().New()
+	unchanged.SetObjectID(model.NewObjectID("kind", "unchanged"))
+	unchanged.Save()
+
+	var conditions = []struct {
+		url      string
+		status   int
+		contains []string
+	}{
+		{"/default/stop_visits/kind:value/history", http.StatusOK, []string{`"Origin":"partner"`, `"ArrivalStatus":"onTime"`, `"ArrivalStatus":"delayed"`}},
+		{"/default/stop_visits/unknown/history", http.StatusNotFound, nil},
+		{"/default/stop_visits?at=2017/01/01-12:00:30", http.StatusOK, []string{`"ArrivalStatus":"onTime"`, `"kind":"unchanged"`}},
+		{"/default/stop_visits?at=2017-01-01T12:01:00Z", http.StatusOK, []string{`"ArrivalStatus":"delayed"`, `"kind":"unchanged"`}},
+		{"/default/stop_visits?at=2017/01/01-11:00:00", http.StatusOK, []string{`"kind":"unchanged"`}},
+		{"/default/stop_visits?at=yesterday", http.StatusBadRequest, nil},
+	}
+
+	for _, condition := range conditions {
+		request, _ := http.NewRequest("GET", condition.url, nil)
+		request.Header.Set("Authorization", "Token token=testToken")
+		responseRecorder := httptest.NewRecorder()
+		server.HandleFlow(responseRecorder, request)
+
+		if responseRecorder.Code != condition.status {
+			t.Errorf("Wrong status for %v:\n got: %v\n want: %v", condition.url, responseRecorder.Code, condition.status)
+			continue
+		}
+		for _, expected := range condition.contains {
+			if !strings.Contains(responseRecorder.Body.String(), expected) {
+				t.Errorf("Response for %v should contain %v:\n%v", condition.url, expected, responseRecorder.Body.String())
+			}
+		}
+	}
+}
+
 func benchmarkStopVisitsIndex(sv int, b *testing.B) {
 	referentials := core.NewMemoryReferentials()
 	server := &Server{}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/core"
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/model"
+)
+
+const (
+	STREAM_KEEP_ALIVE = 15 * time.Second
+	// Streams are closed before the server write timeout. Clients reconnect
+	// after the retry delay
+	STREAM_MAX_DURATION = 50 * time.Second
+	STREAM_RETRY        = 1 * time.Second
+)
+
+// Sends the model changes as Server-Sent Events
+type StreamHandler struct {
+	referential *core.Referential
+}
+
+func NewStreamHandler(referential *core.Referential) *StreamHandler {
+	return &StreamHandler{
+		referential: referential,
+	}
+}
+
+func (handler *StreamHandler) serve(response http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		http.Error(response, "Invalid request: only GET requests are supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := response.(http.Flusher)
+	if !ok {
+		http.Error(response, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	filter, err := handler.filter(request.URL.Query())
+	if err != nil {
+		http.Error(response, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	streams := handler.referential.ModelStreams()
+	stream := streams.New(filter)
+	defer streams.Close(stream)
+
+	logger.Log.Debugf("Open model stream: %v", request)
+
+	response.Header().Set("Content-Type", "text/event-stream")
+	response.Header().Set("Cache-Control", "no-cache")
+	response.Header().Set("Connection", "keep-alive")
+	response.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(response, "retry: %d\n\n", STREAM_RETRY/time.Millisecond)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(STREAM_KEEP_ALIVE)
+	defer keepAlive.Stop()
+	timeout := time.NewTimer(STREAM_MAX_DURATION)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case event := <-stream.Events():
+			data, err := json.Marshal(event)
+			if err != nil {
+				logger.Log.Debugf("Unable to marshal model stream event: %v", err)
+				continue
+			}
+			fmt.Fprintf(response, "event: %s\ndata: %s\n\n", event.Kind, data)
+			flusher.Flush()
+		case <-keepAlive.C:
+			fmt.Fprint(response, ": keep-alive\n\n")
+			flusher.Flush()
+		case <-timeout.C:
+			return
+		case <-request.Context().Done():
+			logger.Log.Debugf("Model stream closed by client")
+			return
+		}
+	}
+}
+
+func (handler *StreamHandler) filter(values url.Values) (*core.ModelStreamFilter, error) {
+	filter := &core.ModelStreamFilter{
+		StopAreaIds: make(map[model.StopAreaId]struct{}),
+		LineIds:     make(map[model.LineId]struct{}),
+		Kinds:       make(map[string]struct{}),
+	}
+
+	tx := handler.referential.NewTransaction()
+	defer tx.Close()
+
+	for _, identifier := range splitFilterValues(values["StopAreaId"]) {
+		stopArea, ok := findStopAreaByIdentifier(tx, identifier)
+		if !ok {
+			return nil, fmt.Errorf("stop area not found: %s", identifier)
+		}
+		filter.StopAreaIds[stopArea.Id()] = struct{}{}
+	}
+
+	for _, identifier := range splitFilterValues(values["LineId"]) {
+		line, ok := findLineByIdentifier(tx, identifier)
+		if !ok {
+			return nil, fmt.Errorf("line not found: %s", identifier)
+		}
+		filter.LineIds[line.Id()] = struct{}{}
+	}
+
+	for _, kind := range splitFilterValues(values["Kind"]) {
+		switch kind {
+		case "StopVisit", "VehicleJourney", "Vehicle", "Situation":
+			filter.Kinds[kind] = struct{}{}
+		default:
+			return nil, fmt.Errorf("unknown kind: %s", kind)
+		}
+	}
+
+	return filter, nil
+}
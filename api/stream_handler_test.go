@@ -0,0 +1,136 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bitbucket.org/enroute-mobi/ara/core"
+	"bitbucket.org/enroute-mobi/ara/model"
+)
+
+func Test_StreamHandler_Unauthorized(t *testing.T) {
+	server := NewTestServer()
+	referential := server.CurrentReferentials().New("default")
+	referential.Tokens = []string{"token"}
+	server.CurrentReferentials().Save(referential)
+
+	httpServer := httptest.NewServer(http.HandlerFunc(server.HandleFlow))
+	defer httpServer.Close()
+
+	response, err := http.Get(httpServer.URL + "/default/stream?token=wrong")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Wrong status code:\n got: %v\n want: %v", response.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func Test_StreamHandler_InvalidFilter(t *testing.T) {
+	server := NewTestServer()
+	referential := server.CurrentReferentials().New("default")
+	referential.Tokens = []string{"token"}
+	server.CurrentReferentials().Save(referential)
+
+	httpServer := httptest.NewServer(http.HandlerFunc(server.HandleFlow))
+	defer httpServer.Close()
+
+	response, err := http.Get(httpServer.URL + "/default/stream?token=token&Kind=StopArea")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("Wrong status code:\n got: %v\n want: %v", response.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func Test_StreamHandler(t *testing.T) {
+	server := NewTestServer()
+	referential := server.CurrentReferentials().New("default")
+	referential.Tokens = []string{"token"}
+	server.CurrentReferentials().Save(referential)
+
+	stopArea := referential.Model().StopAreas().New()
+	stopArea.SetObjectID(model.NewObjectID("kind", "stopArea"))
+	stopArea.Save()
+
+	otherStopArea := referential.Model().StopAreas().New()
+	otherStopArea.Save()
+
+	vehicleJourney := referential.Model().VehicleJourneys().New()
+	vehicleJourney.Save()
+
+	httpServer := httptest.NewServer(http.HandlerFunc(server.HandleFlow))
+	defer httpServer.Close()
+
+	request, _ := http.NewRequest("GET", httpServer.URL+"/default/stream?Kind=StopVisit,VehicleJourney&StopAreaId=kind:stopArea", nil)
+	request.Header.Set("Authorization", "Token token=token")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Wrong status code:\n got: %v\n want: %v", response.StatusCode, http.StatusOK)
+	}
+	if contentType := response.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("Wrong Content-Type:\n got: %v\n want: text/event-stream", contentType)
+	}
+
+	reader := bufio.NewReader(response.Body)
+	// Wait for the stream opening
+	if line, _ := reader.ReadString('\n'); !strings.HasPrefix(line, "retry:") {
+		t.Fatalf("Stream should start with the retry delay, got %q", line)
+	}
+
+	otherStopVisit := referential.Model().StopVisits().New()
+	otherStopVisit.StopAreaId = otherStopArea.Id()
+	otherStopVisit.Save()
+
+	stopVisit := referential.Model().StopVisits().New()
+	stopVisit.StopAreaId = stopArea.Id()
+	stopVisit.VehicleJourneyId = vehicleJourney.Id()
+	stopVisit.Save()
+
+	streams := referential.ModelStreams()
+	for _, id := range []string{string(otherStopVisit.Id()), string(stopVisit.Id())} {
+		streams.HandleStopMonitoringBroadcastEvent(&model.StopMonitoringBroadcastEvent{ModelId: id, ModelType: "StopVisit"})
+	}
+	streams.HandleStopMonitoringBroadcastEvent(&model.StopMonitoringBroadcastEvent{ModelId: string(vehicleJourney.Id()), ModelType: "VehicleJourney"})
+
+	for _, expected := range []struct{ kind, id string }{{"StopVisit", string(stopVisit.Id())}, {"VehicleJourney", string(vehicleJourney.Id())}} {
+		var eventLine, dataLine string
+		for eventLine == "" || dataLine == "" {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				eventLine = strings.TrimSpace(strings.TrimPrefix(line, "event: "))
+			case strings.HasPrefix(line, "data: "):
+				dataLine = strings.TrimPrefix(line, "data: ")
+			}
+		}
+
+		if eventLine != expected.kind {
+			t.Errorf("Wrong event:\n got: %v\n want: %v", eventLine, expected.kind)
+		}
+		event := &core.ModelStreamEvent{}
+		if err := json.Unmarshal([]byte(dataLine), event); err != nil {
+			t.Fatal(err)
+		}
+		if event.Id != expected.id {
+			t.Errorf("Wrong event Id:\n got: %v\n want: %v", event.Id, expected.id)
+		}
+	}
+}
@@ -16,6 +16,17 @@ type VehicleController struct {
 	referential *core.Referential
 }
 
+// Attributes which can be selected and sorted in the Index requests
+var vehicleIndexAttributes = indexAttributes{
+	"ObjectIDs":        indexObjectIDs,
+	"LineId":           func(v interface{}) interface{} { return string(v.(*model.Vehicle).LineId) },
+	"VehicleJourneyId": func(v interface{}) interface{} { return string(v.(*model.Vehicle).VehicleJourneyId) },
+	"Longitude":        func(v interface{}) interface{} { return v.(*model.Vehicle).Longitude },
+	"Latitude":         func(v interface{}) interface{} { return v.(*model.Vehicle).Latitude },
+	"Bearing":          func(v interface{}) interface{} { return v.(*model.Vehicle).Bearing },
+	"RecordedAtTime":   func(v interface{}) interface{} { return v.(*model.Vehicle).RecordedAtTime },
+}
+
 func NewVehicleController(referential *core.Referential) ControllerInterface {
 	return &Controller{
 		restfulResource: &VehicleController{
@@ -41,13 +52,31 @@ func (controller *VehicleController) Index(response http.ResponseWriter, filters
 
 	logger.Log.Debugf("Vehicles Index")
 
+	query, err := newIndexQuery(tx, filters, INDEX_FILTER_OBJECTID, INDEX_FILTER_OBJECTID_KIND, INDEX_FILTER_LINE, INDEX_FILTER_AFTER, INDEX_FILTER_BEFORE)
+	if err != nil {
+		http.Error(response, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
 	stime := controller.referential.Clock().Now()
 	vehicles := tx.Model().Vehicles().FindAll()
 	logger.Log.Debugf("VehicleController FindAll time : %v", controller.referential.Clock().Since(stime))
+
+	items := make([]*indexItem, 0, len(vehicles))
+	for i := range vehicles {
+		vehicle := &vehicles[i]
+		items = append(items, &indexItem{
+			id:        string(vehicle.Id()),
+			value:     vehicle,
+			objectids: vehicle.ObjectIDs(),
+			lineIds:   []model.LineId{vehicle.LineId},
+			time:      vehicle.RecordedAtTime,
+		})
+	}
+
 	stime = controller.referential.Clock().Now()
-	jsonBytes, _ := json.Marshal(vehicles)
+	query.write(response, items, vehicleIndexAttributes)
 	logger.Log.Debugf("VehicleController Json Marshal time : %v ", controller.referential.Clock().Since(stime))
-	response.Write(jsonBytes)
 }
 
 func (controller *VehicleController) Show(response http.ResponseWriter, identifier string) {
@@ -16,6 +16,18 @@ type VehicleJourneyController struct {
 	referential *core.Referential
 }
 
+// Attributes which can be selected and sorted in the Index requests
+var vehicleJourneyIndexAttributes = indexAttributes{
+	"ObjectIDs":       indexObjectIDs,
+	"LineId":          func(v interface{}) interface{} { return string(v.(*model.VehicleJourney).LineId) },
+	"Name":            func(v interface{}) interface{} { return v.(*model.VehicleJourney).Name },
+	"OriginName":      func(v interface{}) interface{} { return v.(*model.VehicleJourney).OriginName },
+	"DestinationName": func(v interface{}) interface{} { return v.(*model.VehicleJourney).DestinationName },
+	"Monitored":       func(v interface{}) interface{} { return v.(*model.VehicleJourney).Monitored },
+	"Cancellation":    func(v interface{}) interface{} { return v.(*model.VehicleJourney).Cancellation },
+	"ExtraJourney":    func(v interface{}) interface{} { return v.(*model.VehicleJourney).ExtraJourney },
+}
+
 func NewVehicleJourneyController(referential *core.Referential) ControllerInterface {
 	return &Controller{
 		restfulResource: &VehicleJourneyController{
@@ -41,8 +53,32 @@ func (controller *VehicleJourneyController) Index(response http.ResponseWriter,
 
 	logger.Log.Debugf("VehicleJourneys Index")
 
-	jsonBytes, _ := json.Marshal(tx.Model().VehicleJourneys().FindAll())
-	response.Write(jsonBytes)
+	query, err := newIndexQuery(tx, filters, INDEX_FILTER_OBJECTID, INDEX_FILTER_OBJECTID_KIND, INDEX_FILTER_LINE, INDEX_FILTER_STOP_AREA, INDEX_FILTER_MONITORED)
+	if err != nil {
+		http.Error(response, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	vehicleJourneys := tx.Model().VehicleJourneys().FindAll()
+	items := make([]*indexItem, 0, len(vehicleJourneys))
+	for i := range vehicleJourneys {
+		vehicleJourney := &vehicleJourneys[i]
+		item := &indexItem{
+			id:        string(vehicleJourney.Id()),
+			value:     vehicleJourney,
+			objectids: vehicleJourney.ObjectIDs(),
+			lineIds:   []model.LineId{vehicleJourney.LineId},
+			monitored: vehicleJourney.Monitored,
+		}
+		if query.stopAreaIds != nil {
+			stopVisits := tx.Model().StopVisits().FindByVehicleJourneyId(vehicleJourney.Id())
+			for j := range stopVisits {
+				item.stopAreaIds = append(item.stopAreaIds, stopVisits[j].StopAreaId)
+			}
+		}
+		items = append(items, item)
+	}
+	query.write(response, items, vehicleJourneyIndexAttributes)
 }
 
 func (controller *VehicleJourneyController) Show(response http.ResponseWriter, identifier string) {
@@ -45,7 +45,9 @@ func main() {
 		fmt.Println("\tcheck [-requestor-ref=<requestorRef>] <url>")
 		fmt.Println("\tapi [-listen=<url>]")
 		fmt.Println("\tmigrate [-path=<path>] <up|down>")
-		fmt.Println("\tload <file path> <referential_slug>")
+		fmt.Println("\tload [-force] [-format=<csv|netex|gtfs>] <file path> <referential_slug>")
+		fmt.Println("\tconfig apply [-dry-run] <path>")
+		fmt.Println("\tconfig export [<directory>]")
 		os.Exit(1)
 	}
 
@@ -134,6 +136,8 @@ func main() {
 	case "api":
 		apiFlags := flag.NewFlagSet("api", flag.ExitOnError)
 		serverAddressPtr := apiFlags.String("listen", "localhost:8080", "Specify server port")
+		tlsCertificatePtr := apiFlags.String("tls-certificate", "", "Specify the server certificate file to serve HTTPS")
+		tlsKeyPtr := apiFlags.String("tls-key", "", "Specify the server key file to serve HTTPS")
 		apiFlags.Parse(flag.Args()[1:])
 
 		// Init Database
@@ -148,7 +152,12 @@ func main() {
 
 		core.CurrentReferentials().Start()
 
-		err = api.NewServer(*serverAddressPtr).ListenAndServe()
+		server := api.NewServer(*serverAddressPtr)
+		if *tlsCertificatePtr != "" {
+			err = server.ListenAndServeTLS(*tlsCertificatePtr, *tlsKeyPtr)
+		} else {
+			err = server.ListenAndServe()
+		}
 	case "purge":
 		logger.Log.Debug = true
 
@@ -172,14 +181,44 @@ func main() {
 		database := model.InitDB(config.Config.DB)
 		defer model.CloseDB(database)
 		err = model.ApplyMigrations(migrateFlags.Arg(0), *migrationFilesPtr, database.Db)
+	case "config":
+		if len(flag.Args()) < 2 {
+			logger.Log.Printf("Incorrect use of command config: missing subcommand")
+			logger.Log.Printf("usage: ara config apply [-dry-run] <path> | ara config export [<directory>]")
+			os.Exit(2)
+		}
+		configFlags := flag.NewFlagSet("config", flag.ExitOnError)
+		dryRunPtr := configFlags.Bool("dry-run", false, "Only display the changes")
+		configFlags.Parse(flag.Args()[2:])
+
+		// Init Database
+		model.Database = model.InitDB(config.Config.DB)
+		defer model.CloseDB(model.Database)
+
+		switch flag.Args()[1] {
+		case "apply":
+			if configFlags.NArg() < 1 {
+				logger.Log.Printf("Incorrect use of command config apply: missing path")
+				os.Exit(2)
+			}
+			err = applyConfiguration(configFlags.Arg(0), *dryRunPtr)
+		case "export":
+			err = exportConfiguration(configFlags.Arg(0))
+		default:
+			logger.Log.Printf("Incorrect use of command config: unknown subcommand %v", flag.Args()[1])
+			os.Exit(2)
+		}
 	case "load":
 		loadFlags := flag.NewFlagSet("load", flag.ExitOnError)
 		forcePtr := loadFlags.Bool("force", false, "Overwrite records in Database")
+		formatPtr := loadFlags.String("format", "csv", "File format: csv, netex or gtfs")
+		modelNamePtr := loadFlags.String("model-name", clock.DefaultClock().Now().Format("2006-01-02"), "Model date of the NeTEx or GTFS data")
+		objectidKindPtr := loadFlags.String("objectid-kind", "internal", "ObjectID kind of the NeTEx or GTFS identifiers")
 		loadFlags.Parse(flag.Args()[1:])
 
 		if loadFlags.NArg() < 2 {
 			logger.Log.Printf("Incorrect use of command load: not enough aguments")
-			logger.Log.Printf("usage: ara load [-force] <path> <referential slug>")
+			logger.Log.Printf("usage: ara load [-force] [-format=<csv|netex|gtfs>] [-model-name=<date>] [-objectid-kind=<kind>] <path> <referential slug>")
 			os.Exit(2)
 		}
 
@@ -187,7 +226,17 @@ func main() {
 		model.Database = model.InitDB(config.Config.DB)
 		defer model.CloseDB(model.Database)
 
-		err = model.LoadFromCSVFile(loadFlags.Arg(0), loadFlags.Arg(1), *forcePtr)
+		switch *formatPtr {
+		case "csv":
+			err = model.LoadFromCSVFile(loadFlags.Arg(0), loadFlags.Arg(1), *forcePtr)
+		case model.NETEX_FORMAT:
+			err = model.LoadFromNeTExFile(loadFlags.Arg(0), loadFlags.Arg(1), *modelNamePtr, *objectidKindPtr, *forcePtr)
+		case model.GTFS_FORMAT:
+			err = model.LoadFromGtfsFile(loadFlags.Arg(0), loadFlags.Arg(1), *modelNamePtr, *objectidKindPtr, *forcePtr)
+		default:
+			logger.Log.Printf("Incorrect use of command load: unknown format %v", *formatPtr)
+			os.Exit(2)
+		}
 	}
 
 	if err != nil {
@@ -253,6 +302,53 @@ func checkStatus(url string, requestorRef string) error {
 	return nil
 }
 
+func applyConfiguration(path string, dryRun bool) error {
+	definitions, err := core.LoadReferentialDefinitions(path)
+	if err != nil {
+		return err
+	}
+
+	current := core.NewMemoryReferentials()
+	if err = current.Load(); err != nil {
+		return err
+	}
+
+	plan, err := core.NewConfigurationPlan(current, definitions)
+	if err != nil {
+		return err
+	}
+
+	if len(plan.Changes) == 0 {
+		logger.Log.Printf("Configuration is up to date")
+		return nil
+	}
+	for _, change := range plan.Changes {
+		logger.Log.Printf("%v", change)
+	}
+	if dryRun {
+		return nil
+	}
+
+	if err = plan.Apply(); err != nil {
+		return err
+	}
+	logger.Log.Printf("%d changes applied", len(plan.Changes))
+	return nil
+}
+
+func exportConfiguration(directory string) error {
+	current := core.NewMemoryReferentials()
+	if err := current.Load(); err != nil {
+		return err
+	}
+
+	definitions := core.NewReferentialDefinitions(current)
+	if directory == "" {
+		return core.WriteReferentialDefinitions(os.Stdout, definitions)
+	}
+	return core.WriteReferentialDefinitionFiles(directory, definitions)
+}
+
 func enableCpuProfile(file string) error {
 	f, err := os.Create(file)
 	if err != nil {
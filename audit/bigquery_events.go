@@ -25,7 +25,7 @@ type BigQueryMessage struct {
 	Type                    string    `bigquery:"type"`      // "siri-checkstatus", "gtfs-trip-update", …
 	Direction               string    `bigquery:"direction"` // "sent" (by Ara), "received" (by Ara)
 	Partner                 string    `bigquery:"partner"`   // partner slug
-	Status                  string    `bigquery:"status"`    // "OK", "Error"
+	Status                  string    `bigquery:"status"`    // "OK", "Error", "RateLimited"
 	ErrorDetails            string    `bigquery:"error_details"`
 	RequestRawMessage       string    `bigquery:"request_raw_message"`  // XML or JSON for GTFS-RT
 	ResponseRawMessage      string    `bigquery:"response_raw_message"` // XML or JSON for GTFS-RT
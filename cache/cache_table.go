@@ -77,3 +77,15 @@ func (table *CacheTable) Fetch(key string, f func() (interface{}, error)) (inter
 	}
 	return r.Fetch(f)
 }
+
+// Returns the sum of the hits and misses of all cached items
+func (table *CacheTable) Stats() (hits, misses uint64) {
+	table.RLock()
+	for _, item := range table.items {
+		h, m := item.Stats()
+		hits += h
+		misses += m
+	}
+	table.RUnlock()
+	return
+}
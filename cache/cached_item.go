@@ -2,6 +2,7 @@ package cache
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"bitbucket.org/enroute-mobi/ara/logger"
@@ -25,6 +26,9 @@ type CachedItem struct {
 	cleanupTimer *time.Timer
 
 	loadData func(...interface{}) (interface{}, error)
+
+	hits   uint64
+	misses uint64
 }
 
 func NewCachedItem(key string, lifeSpan time.Duration, data interface{}, loader func(...interface{}) (interface{}, error)) *CachedItem {
@@ -62,6 +66,7 @@ func (item *CachedItem) Fetch(f func() (interface{}, error)) (interface{}, error
 	item.RUnlock()
 
 	if d != nil {
+		atomic.AddUint64(&item.hits, 1)
 		return d, nil
 	}
 
@@ -70,6 +75,7 @@ func (item *CachedItem) Fetch(f func() (interface{}, error)) (interface{}, error
 	var err error
 	// Double check
 	if item.data == nil && f != nil {
+		atomic.AddUint64(&item.misses, 1)
 		// Ensure we never have 2 AfterFunc simustaniously
 		if item.cleanupTimer != nil {
 			item.cleanupTimer.Stop()
@@ -80,6 +86,8 @@ func (item *CachedItem) Fetch(f func() (interface{}, error)) (interface{}, error
 			return nil, err
 		}
 		item.cleanupTimer = time.AfterFunc(item.lifeSpan, func() { item.expire() })
+	} else if item.data != nil {
+		atomic.AddUint64(&item.hits, 1)
 	}
 
 	return item.data, nil
@@ -111,3 +119,8 @@ func (item *CachedItem) SetData(data interface{}) {
 	item.data = data
 	item.Unlock()
 }
+
+// Returns the number of Fetch calls which used the cached data and which loaded it
+func (item *CachedItem) Stats() (hits, misses uint64) {
+	return atomic.LoadUint64(&item.hits), atomic.LoadUint64(&item.misses)
+}
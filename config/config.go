@@ -34,6 +34,7 @@ type config struct {
 	ColorizeLog           bool
 	LoadMaxInsert         int
 	FakeUUIDRealFormat    bool
+	SnapshotDirectory     string
 }
 
 var Config = config{}
@@ -12,6 +12,7 @@ type BroadcastManagerInterface interface {
 
 	GetStopMonitoringBroadcastEventChan() chan model.StopMonitoringBroadcastEvent
 	GetGeneralMessageBroadcastEventChan() chan model.GeneralMessageBroadcastEvent
+	GetModelStreamEventChan() chan model.StopMonitoringBroadcastEvent
 }
 
 type BroadcastManager struct {
@@ -19,14 +20,17 @@ type BroadcastManager struct {
 
 	smbEventChan chan model.StopMonitoringBroadcastEvent
 	gmbEventChan chan model.GeneralMessageBroadcastEvent
-	stop         chan struct{}
+	// Events only sent to the model streams
+	streamEventChan chan model.StopMonitoringBroadcastEvent
+	stop            chan struct{}
 }
 
 func NewBroadcastManager(referential *Referential) *BroadcastManager {
 	return &BroadcastManager{
-		Referential:  referential,
-		smbEventChan: make(chan model.StopMonitoringBroadcastEvent, 2000),
-		gmbEventChan: make(chan model.GeneralMessageBroadcastEvent, 2000),
+		Referential:     referential,
+		smbEventChan:    make(chan model.StopMonitoringBroadcastEvent, 2000),
+		gmbEventChan:    make(chan model.GeneralMessageBroadcastEvent, 2000),
+		streamEventChan: make(chan model.StopMonitoringBroadcastEvent, 2000),
 	}
 }
 
@@ -38,6 +42,10 @@ func (manager *BroadcastManager) GetGeneralMessageBroadcastEventChan() chan mode
 	return manager.gmbEventChan
 }
 
+func (manager *BroadcastManager) GetModelStreamEventChan() chan model.StopMonitoringBroadcastEvent {
+	return manager.streamEventChan
+}
+
 func (manager *BroadcastManager) GetPartnersWithConnector(connectorTypes []string) []*Partner {
 	partners := []*Partner{}
 
@@ -71,8 +79,14 @@ func (manager *BroadcastManager) run() {
 		case event := <-manager.smbEventChan:
 			manager.smsbEvent_handler(event)
 			manager.ettsbEvent_handler(event)
+			manager.vmsbEvent_handler(event)
+			manager.streamSMEvent_handler(event)
 		case event := <-manager.gmbEventChan:
 			manager.gmsbEvent_handler(event)
+			manager.sxsbEvent_handler(event)
+			manager.streamGMEvent_handler(event)
+		case event := <-manager.streamEventChan:
+			manager.streamSMEvent_handler(event)
 		case <-manager.stop:
 			logger.Log.Debugf("BroadcastManager Stop")
 			return
@@ -115,6 +129,23 @@ func (manager *BroadcastManager) ettsbEvent_handler(event model.StopMonitoringBr
 	}
 }
 
+func (manager *BroadcastManager) vmsbEvent_handler(event model.StopMonitoringBroadcastEvent) {
+	connectorTypes := []string{SIRI_VEHICLE_MONITORING_SUBSCRIPTION_BROADCASTER, TEST_VEHICLE_MONITORING_SUBSCRIPTION_BROADCASTER}
+	for _, partner := range manager.GetPartnersWithConnector(connectorTypes) {
+		connector, ok := partner.Connector(SIRI_VEHICLE_MONITORING_SUBSCRIPTION_BROADCASTER)
+		if ok {
+			connector.(*SIRIVehicleMonitoringSubscriptionBroadcaster).HandleBroadcastEvent(&event)
+			continue
+		}
+
+		connector, ok = partner.Connector(TEST_VEHICLE_MONITORING_SUBSCRIPTION_BROADCASTER)
+		if ok {
+			connector.(*TestVMSubscriptionBroadcaster).HandleBroadcastEvent(&event)
+			continue
+		}
+	}
+}
+
 func (manager *BroadcastManager) gmsbEvent_handler(event model.GeneralMessageBroadcastEvent) {
 	connectorTypes := []string{SIRI_GENERAL_MESSAGE_SUBSCRIPTION_BROADCASTER, TEST_GENERAL_MESSAGE_SUBSCRIPTION_BROADCASTER}
 	for _, partner := range manager.GetPartnersWithConnector(connectorTypes) {
@@ -133,6 +164,36 @@ func (manager *BroadcastManager) gmsbEvent_handler(event model.GeneralMessageBro
 	}
 }
 
+func (manager *BroadcastManager) sxsbEvent_handler(event model.GeneralMessageBroadcastEvent) {
+	connectorTypes := []string{SIRI_SITUATION_EXCHANGE_SUBSCRIPTION_BROADCASTER, TEST_SITUATION_EXCHANGE_SUBSCRIPTION_BROADCASTER}
+	for _, partner := range manager.GetPartnersWithConnector(connectorTypes) {
+		connector, ok := partner.Connector(SIRI_SITUATION_EXCHANGE_SUBSCRIPTION_BROADCASTER)
+		if ok {
+			connector.(*SIRISituationExchangeSubscriptionBroadcaster).HandleGeneralMessageBroadcastEvent(&event)
+			continue
+		}
+
+		// TEST
+		connector, ok = partner.Connector(TEST_SITUATION_EXCHANGE_SUBSCRIPTION_BROADCASTER)
+		if ok {
+			connector.(*TestSituationExchangeSubscriptionBroadcaster).HandleGeneralMessageBroadcastEvent(&event)
+			continue
+		}
+	}
+}
+
+func (manager *BroadcastManager) streamSMEvent_handler(event model.StopMonitoringBroadcastEvent) {
+	if streams := manager.Referential.ModelStreams(); streams != nil {
+		streams.HandleStopMonitoringBroadcastEvent(&event)
+	}
+}
+
+func (manager *BroadcastManager) streamGMEvent_handler(event model.GeneralMessageBroadcastEvent) {
+	if streams := manager.Referential.ModelStreams(); streams != nil {
+		streams.HandleGeneralMessageBroadcastEvent(&event)
+	}
+}
+
 func (manager *BroadcastManager) Stop() {
 	if manager.stop != nil {
 		close(manager.stop)
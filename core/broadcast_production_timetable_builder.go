@@ -0,0 +1,158 @@
+package core
+
+import (
+	"sort"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+)
+
+type BroadcastProductionTimetableBuilder struct {
+	clock.ClockConsumer
+
+	tx                 *model.Transaction
+	partner            *Partner
+	referenceGenerator *IdentifierGenerator
+	remoteObjectidKind string
+
+	startTime time.Time
+	endTime   time.Time
+}
+
+func NewBroadcastProductionTimetableBuilder(tx *model.Transaction, partner *Partner, connector string) *BroadcastProductionTimetableBuilder {
+	return &BroadcastProductionTimetableBuilder{
+		tx:                 tx,
+		partner:            partner,
+		referenceGenerator: partner.IdentifierGenerator(REFERENCE_IDENTIFIER),
+		remoteObjectidKind: partner.RemoteObjectIDKind(connector),
+	}
+}
+
+// Only the VehicleJourneys with at least one aimed time in the period are
+// broadcasted. A zero time leaves the period open.
+func (builder *BroadcastProductionTimetableBuilder) SetValidityPeriod(startTime, endTime time.Time) {
+	builder.startTime = startTime
+	builder.endTime = endTime
+}
+
+func (builder *BroadcastProductionTimetableBuilder) BuildDatedTimetableVersionFrame(line *model.Line) *siri.SIRIDatedTimetableVersionFrame {
+	lineObjectId, ok := line.ObjectID(builder.remoteObjectidKind)
+	if !ok {
+		return nil
+	}
+
+	frame := &siri.SIRIDatedTimetableVersionFrame{
+		LineRef:        lineObjectId.Value(),
+		RecordedAtTime: builder.Clock().Now(),
+	}
+
+	for _, vehicleJourney := range builder.tx.Model().VehicleJourneys().FindByLineId(line.Id()) {
+		datedVehicleJourney := builder.buildDatedVehicleJourney(&vehicleJourney)
+		if datedVehicleJourney == nil {
+			continue
+		}
+		frame.DatedVehicleJourneys = append(frame.DatedVehicleJourneys, datedVehicleJourney)
+	}
+
+	if len(frame.DatedVehicleJourneys) == 0 {
+		return nil
+	}
+	return frame
+}
+
+func (builder *BroadcastProductionTimetableBuilder) buildDatedVehicleJourney(vehicleJourney *model.VehicleJourney) *siri.SIRIDatedVehicleJourney {
+	var datedVehicleJourneyRef string
+	vehicleJourneyId, ok := vehicleJourney.ObjectID(builder.remoteObjectidKind)
+	if ok {
+		datedVehicleJourneyRef = vehicleJourneyId.Value()
+	} else {
+		defaultObjectID, ok := vehicleJourney.ObjectID("_default")
+		if !ok {
+			logger.Log.Debugf("Vehicle journey with id %v does not have a proper objectid", vehicleJourney.Id())
+			return nil
+		}
+		datedVehicleJourneyRef = builder.referenceGenerator.NewIdentifier(IdentifierAttributes{Type: "VehicleJourney", Id: defaultObjectID.Value()})
+	}
+
+	datedVehicleJourney := &siri.SIRIDatedVehicleJourney{
+		DatedVehicleJourneyRef: datedVehicleJourneyRef,
+		Attributes:             vehicleJourney.Attributes,
+		References:             builder.references(vehicleJourney),
+	}
+
+	stopVisits := builder.tx.Model().StopVisits().FindByVehicleJourneyId(vehicleJourney.Id())
+	sort.Slice(stopVisits, func(i, j int) bool { return stopVisits[i].PassageOrder < stopVisits[j].PassageOrder })
+
+	var inPeriod bool
+	for i := range stopVisits {
+		stopVisit := &stopVisits[i]
+
+		stopArea, ok := builder.tx.Model().StopAreas().Find(stopVisit.StopAreaId)
+		if !ok {
+			continue
+		}
+		stopAreaObjectId, ok := stopArea.ReferentOrSelfObjectId(builder.remoteObjectidKind)
+		if !ok {
+			logger.Log.Debugf("Ignore StopVisit %v with StopArea without correct ObjectID", stopVisit.Id())
+			continue
+		}
+
+		aimed := stopVisit.Schedules.Schedule("aimed")
+		datedCall := &siri.SIRIDatedCall{
+			StopPointRef:       stopAreaObjectId.Value(),
+			StopPointName:      stopArea.Name,
+			DestinationDisplay: stopVisit.Attributes["DestinationDisplay"],
+			Order:              stopVisit.PassageOrder,
+			AimedArrivalTime:   aimed.ArrivalTime(),
+			AimedDepartureTime: aimed.DepartureTime(),
+		}
+		if builder.inPeriod(datedCall.AimedArrivalTime) || builder.inPeriod(datedCall.AimedDepartureTime) {
+			inPeriod = true
+		}
+
+		datedVehicleJourney.DatedCalls = append(datedVehicleJourney.DatedCalls, datedCall)
+	}
+
+	if !inPeriod {
+		return nil
+	}
+	return datedVehicleJourney
+}
+
+func (builder *BroadcastProductionTimetableBuilder) inPeriod(t time.Time) bool {
+	if t.IsZero() {
+		return false
+	}
+	if !builder.startTime.IsZero() && t.Before(builder.startTime) {
+		return false
+	}
+	if !builder.endTime.IsZero() && t.After(builder.endTime) {
+		return false
+	}
+	return true
+}
+
+func (builder *BroadcastProductionTimetableBuilder) references(vehicleJourney *model.VehicleJourney) map[string]string {
+	references := make(map[string]string)
+
+	for _, refType := range []string{"OriginRef", "DestinationRef"} {
+		ref, ok := vehicleJourney.Reference(refType)
+		if !ok || ref.ObjectId == nil {
+			continue
+		}
+		stopArea, ok := builder.tx.Model().StopAreas().FindByObjectId(*ref.ObjectId)
+		if !ok {
+			continue
+		}
+		obj, ok := stopArea.ReferentOrSelfObjectId(builder.remoteObjectidKind)
+		if !ok {
+			continue
+		}
+		references[refType] = obj.Value()
+	}
+
+	return references
+}
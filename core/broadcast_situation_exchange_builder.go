@@ -0,0 +1,265 @@
+package core
+
+import (
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+type BroadcastSituationExchangeBuilder struct {
+	clock.ClockConsumer
+	uuid.UUIDConsumer
+
+	tx                 *model.Transaction
+	partner            *Partner
+	referenceGenerator *IdentifierGenerator
+	remoteObjectidKind string
+	lineRef            map[string]struct{}
+	stopPointRef       map[string]struct{}
+}
+
+func NewBroadcastSituationExchangeBuilder(tx *model.Transaction, partner *Partner, connector string) *BroadcastSituationExchangeBuilder {
+	return &BroadcastSituationExchangeBuilder{
+		tx:                 tx,
+		partner:            partner,
+		referenceGenerator: partner.IdentifierGenerator(REFERENCE_IDENTIFIER),
+		remoteObjectidKind: partner.RemoteObjectIDKind(connector),
+		lineRef:            make(map[string]struct{}),
+		stopPointRef:       make(map[string]struct{}),
+	}
+}
+
+func (builder *BroadcastSituationExchangeBuilder) SetLineRef(lineRef []string) {
+	for i := range lineRef {
+		if lineRef[i] == "" {
+			continue
+		}
+		builder.lineRef[lineRef[i]] = struct{}{}
+	}
+}
+
+func (builder *BroadcastSituationExchangeBuilder) SetStopPointRef(stopPointRef []string) {
+	for i := range stopPointRef {
+		if stopPointRef[i] == "" {
+			continue
+		}
+		builder.stopPointRef[stopPointRef[i]] = struct{}{}
+	}
+}
+
+func (builder *BroadcastSituationExchangeBuilder) BuildSituationExchange(situation model.Situation) *siri.SIRIPtSituationElement {
+	if situation.Origin == string(builder.partner.Slug()) {
+		return nil
+	}
+
+	now := builder.Clock().Now()
+
+	validityPeriods := builder.validityPeriods(&situation)
+	if !builder.isValid(validityPeriods, now) {
+		return nil
+	}
+	if len(situation.PublicationWindows) != 0 && !builder.isPublished(situation.PublicationWindows, now) {
+		return nil
+	}
+
+	var situationNumber string
+	objectid, present := situation.ObjectID(builder.remoteObjectidKind)
+	if present {
+		situationNumber = objectid.Value()
+	} else {
+		objectid, present = situation.ObjectID("_default")
+		if !present {
+			return nil
+		}
+		situationNumber = builder.referenceGenerator.NewIdentifier(IdentifierAttributes{Type: "Situation", Id: objectid.Value()})
+	}
+
+	siriSituation := &siri.SIRIPtSituationElement{
+		CreationTime:    situation.RecordedAt,
+		SituationNumber: situationNumber,
+		Version:         situation.Version,
+		ParticipantRef:  situation.ProducerRef,
+		AlertCause:      situation.Reason,
+		Severity:        situation.Severity,
+		Summary:         situation.Summary,
+		Description:     situation.Description,
+	}
+	if siriSituation.Version == 0 {
+		siriSituation.Version = 1
+	}
+	if siriSituation.ParticipantRef == "" {
+		siriSituation.ParticipantRef = builder.partner.ProducerRef()
+	}
+	if siriSituation.Summary == "" {
+		siriSituation.Summary, siriSituation.Description = builder.messages(situation.Messages)
+	}
+
+	for _, period := range validityPeriods {
+		siriSituation.ValidityPeriods = append(siriSituation.ValidityPeriods, &siri.SIRITimeRange{StartTime: period.StartTime, EndTime: period.EndTime})
+	}
+	for _, window := range situation.PublicationWindows {
+		siriSituation.PublicationWindows = append(siriSituation.PublicationWindows, &siri.SIRITimeRange{StartTime: window.StartTime, EndTime: window.EndTime})
+	}
+
+	for _, affect := range builder.affects(&situation) {
+		id, ok := builder.resolveReference(affect)
+		if !ok {
+			continue
+		}
+		switch affect.Type {
+		case "NetworkRef":
+			siriSituation.AffectedNetworks = append(siriSituation.AffectedNetworks, id)
+		case "LineRef":
+			siriSituation.AffectedLines = append(siriSituation.AffectedLines, id)
+		case "StopPointRef":
+			siriSituation.AffectedStopPoints = append(siriSituation.AffectedStopPoints, id)
+		case "DatedVehicleJourneyRef":
+			siriSituation.AffectedVehicleJourneys = append(siriSituation.AffectedVehicleJourneys, id)
+		}
+	}
+	if !siriSituation.HasAffects() || !builder.checkFilter(siriSituation) {
+		return nil
+	}
+
+	return siriSituation
+}
+
+// Situations collected with GeneralMessage only have a ValidUntil
+func (builder *BroadcastSituationExchangeBuilder) validityPeriods(situation *model.Situation) []*model.TimeRange {
+	if len(situation.ValidityPeriods) != 0 || situation.ValidUntil.IsZero() {
+		return situation.ValidityPeriods
+	}
+	return []*model.TimeRange{{StartTime: situation.RecordedAt, EndTime: situation.ValidUntil}}
+}
+
+func (builder *BroadcastSituationExchangeBuilder) isValid(periods []*model.TimeRange, now time.Time) bool {
+	if len(periods) == 0 {
+		return true
+	}
+	for _, period := range periods {
+		if period.EndTime.IsZero() || period.EndTime.After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+func (builder *BroadcastSituationExchangeBuilder) isPublished(windows []*model.TimeRange, now time.Time) bool {
+	for _, window := range windows {
+		if window.StartTime.After(now) {
+			continue
+		}
+		if window.EndTime.IsZero() || window.EndTime.After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// Use the GeneralMessage messages when the situation has no Summary
+func (builder *BroadcastSituationExchangeBuilder) messages(messages []*model.Message) (summary, description string) {
+	for _, message := range messages {
+		switch message.Type {
+		case "shortMessage":
+			summary = message.Content
+		case "longMessage":
+			description = message.Content
+		}
+	}
+	if summary == "" && len(messages) != 0 {
+		summary = messages[0].Content
+	}
+	if description == summary {
+		description = ""
+	}
+	return
+}
+
+// Use the GeneralMessage references when the situation has no Affects
+func (builder *BroadcastSituationExchangeBuilder) affects(situation *model.Situation) []*model.Reference {
+	if len(situation.Affects) != 0 {
+		return situation.Affects
+	}
+
+	var affects []*model.Reference
+	for _, reference := range situation.References {
+		if reference.Type == "LineRef" || reference.Type == "StopPointRef" {
+			affects = append(affects, reference)
+		}
+	}
+	for _, lineSection := range situation.LineSections {
+		reference, ok := lineSection.Get("LineRef")
+		if !ok {
+			continue
+		}
+		affects = append(affects, &model.Reference{ObjectId: reference.ObjectId, Type: "LineRef"})
+	}
+	return affects
+}
+
+func (builder *BroadcastSituationExchangeBuilder) resolveReference(reference *model.Reference) (string, bool) {
+	if reference.ObjectId == nil {
+		return "", false
+	}
+
+	switch reference.Type {
+	case "LineRef":
+		line, ok := builder.tx.Model().Lines().FindByObjectId(*reference.ObjectId)
+		if !ok {
+			return "", false
+		}
+		lineObjectId, ok := line.ObjectID(builder.remoteObjectidKind)
+		if !ok {
+			return "", false
+		}
+		return lineObjectId.Value(), true
+	case "StopPointRef":
+		stopArea, ok := builder.tx.Model().StopAreas().FindByObjectId(*reference.ObjectId)
+		if !ok {
+			return "", false
+		}
+		stopAreaObjectId, ok := stopArea.ReferentOrSelfObjectId(builder.remoteObjectidKind)
+		if !ok {
+			return "", false
+		}
+		return stopAreaObjectId.Value(), true
+	case "DatedVehicleJourneyRef":
+		vehicleJourney, ok := builder.tx.Model().VehicleJourneys().FindByObjectId(*reference.ObjectId)
+		if !ok {
+			return "", false
+		}
+		vehicleJourneyObjectId, ok := vehicleJourney.ObjectID(builder.remoteObjectidKind)
+		if !ok {
+			return "", false
+		}
+		return vehicleJourneyObjectId.Value(), true
+	default:
+		if reference.ObjectId.Kind() == builder.remoteObjectidKind {
+			return reference.ObjectId.Value(), true
+		}
+		kind := reference.Type
+		return builder.referenceGenerator.NewIdentifier(IdentifierAttributes{Type: kind[:len(kind)-3], Id: reference.GetSha1()}), true
+	}
+}
+
+func (builder *BroadcastSituationExchangeBuilder) checkFilter(situation *siri.SIRIPtSituationElement) bool {
+	if len(builder.lineRef) == 0 && len(builder.stopPointRef) == 0 {
+		return true
+	}
+
+	for _, lineRef := range situation.AffectedLines {
+		if _, ok := builder.lineRef[lineRef]; ok {
+			return true
+		}
+	}
+	for _, stopPointRef := range situation.AffectedStopPoints {
+		if _, ok := builder.stopPointRef[stopPointRef]; ok {
+			return true
+		}
+	}
+
+	return false
+}
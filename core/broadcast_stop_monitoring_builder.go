@@ -25,6 +25,8 @@ type BroadcastStopMonitoringBuilder struct {
 	noDestinationRefRewritingFrom []string
 	noDataFrameRefRewritingFrom   []string
 	rewriteJourneyPatternRef      bool
+
+	merger *Merger
 }
 
 func NewBroadcastStopMonitoringBuilder(tx *model.Transaction, partner *Partner, connector string) *BroadcastStopMonitoringBuilder {
@@ -92,7 +94,11 @@ func (builder *BroadcastStopMonitoringBuilder) BuildMonitoredStopVisit(stopVisit
 		logger.Log.Printf("Ignore StopVisit %s without Vehiclejourney", stopVisit.Id())
 		return nil
 	}
-	line, ok := builder.tx.Model().Lines().Find(vehicleJourney.LineId)
+	lineId := vehicleJourney.LineId
+	if builder.merger != nil {
+		lineId = builder.merger.LineWithObjectIDKind(lineId, builder.remoteObjectidKind)
+	}
+	line, ok := builder.tx.Model().Lines().Find(lineId)
 	if !ok {
 		logger.Log.Printf("Ignore StopVisit %s without Line", stopVisit.Id())
 		return nil
@@ -137,18 +143,23 @@ func (builder *BroadcastStopMonitoringBuilder) BuildMonitoredStopVisit(stopVisit
 	if !stopPointRef.Monitored {
 		monitoredStopVisit.Monitored = false
 	}
+	// All the StopVisits of a cancelled VehicleJourney are cancelled
+	if vehicleJourney.Cancellation {
+		monitoredStopVisit.ArrivalStatus = string(model.STOP_VISIT_ARRIVAL_CANCELLED)
+		monitoredStopVisit.DepartureStatus = string(model.STOP_VISIT_DEPARTURE_CANCELLED)
+	}
 
-	if stopVisit.ArrivalStatus != model.STOP_VISIT_ARRIVAL_CANCELLED && builder.StopVisitTypes != "departures" {
+	if monitoredStopVisit.ArrivalStatus != string(model.STOP_VISIT_ARRIVAL_CANCELLED) && builder.StopVisitTypes != "departures" {
 		monitoredStopVisit.AimedArrivalTime = stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_AIMED).ArrivalTime()
-		monitoredStopVisit.ExpectedArrivalTime = stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_EXPECTED).ArrivalTime()
+		monitoredStopVisit.ExpectedArrivalTime = stopVisit.ExpectedOrPredictedArrivalTime()
 		if monitoredStopVisit.Monitored {
 			monitoredStopVisit.ActualArrivalTime = stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_ACTUAL).ArrivalTime()
 		}
 	}
 
-	if stopVisit.DepartureStatus != model.STOP_VISIT_DEPARTURE_CANCELLED && builder.StopVisitTypes != "arrivals" {
+	if monitoredStopVisit.DepartureStatus != string(model.STOP_VISIT_DEPARTURE_CANCELLED) && builder.StopVisitTypes != "arrivals" {
 		monitoredStopVisit.AimedDepartureTime = stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_AIMED).DepartureTime()
-		monitoredStopVisit.ExpectedDepartureTime = stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_EXPECTED).DepartureTime()
+		monitoredStopVisit.ExpectedDepartureTime = stopVisit.ExpectedOrPredictedDepartureTime()
 		if monitoredStopVisit.Monitored {
 			monitoredStopVisit.ActualDepartureTime = stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_ACTUAL).DepartureTime()
 		}
@@ -171,6 +182,9 @@ func (builder *BroadcastStopMonitoringBuilder) BuildMonitoredStopVisit(stopVisit
 }
 
 func (builder *BroadcastStopMonitoringBuilder) stopPointRef(stopAreaId model.StopAreaId) (model.StopArea, string, bool) {
+	if builder.merger != nil {
+		stopAreaId = builder.merger.StopAreaWithObjectIDKind(stopAreaId, builder.remoteObjectidKind)
+	}
 	stopPointRef, ok := builder.tx.Model().StopAreas().Find(stopAreaId)
 	if !ok {
 		return model.StopArea{}, "", false
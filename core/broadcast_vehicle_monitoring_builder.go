@@ -0,0 +1,120 @@
+package core
+
+import (
+	"strings"
+
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+)
+
+type BroadcastVehicleMonitoringBuilder struct {
+	tx                            *model.Transaction
+	referenceGenerator            *IdentifierGenerator
+	stopAreareferenceGenerator    *IdentifierGenerator
+	dataFrameGenerator            *IdentifierGenerator
+	remoteObjectidKind            string
+	vehicleRemoteObjectidKind     string
+	noDestinationRefRewritingFrom []string
+}
+
+func NewBroadcastVehicleMonitoringBuilder(tx *model.Transaction, partner *Partner, connector string) *BroadcastVehicleMonitoringBuilder {
+	return &BroadcastVehicleMonitoringBuilder{
+		tx:                            tx,
+		referenceGenerator:            partner.IdentifierGenerator(REFERENCE_IDENTIFIER),
+		stopAreareferenceGenerator:    partner.IdentifierGenerator(REFERENCE_STOP_AREA_IDENTIFIER),
+		dataFrameGenerator:            partner.IdentifierGenerator(DATA_FRAME_IDENTIFIER),
+		remoteObjectidKind:            partner.RemoteObjectIDKind(connector),
+		vehicleRemoteObjectidKind:     partner.VehicleRemoteObjectIDKind(connector),
+		noDestinationRefRewritingFrom: partner.NoDestinationRefRewritingFrom(),
+	}
+}
+
+func (builder *BroadcastVehicleMonitoringBuilder) BuildVehicleActivity(vehicle model.Vehicle) *siri.SIRIVehicleActivity {
+	vehicleId, ok := vehicle.ObjectID(builder.vehicleRemoteObjectidKind)
+	if !ok {
+		return nil
+	}
+
+	vehicleJourney, ok := builder.tx.Model().VehicleJourneys().Find(vehicle.VehicleJourneyId)
+	if !ok {
+		logger.Log.Debugf("Ignore Vehicle %s without VehicleJourney", vehicle.Id())
+		return nil
+	}
+	line, ok := builder.tx.Model().Lines().Find(vehicleJourney.LineId)
+	if !ok {
+		logger.Log.Debugf("Ignore Vehicle %s without Line", vehicle.Id())
+		return nil
+	}
+	lineObjectId, ok := line.ObjectID(builder.remoteObjectidKind)
+	if !ok {
+		logger.Log.Debugf("Ignore Vehicle %s with Line without correct ObjectID", vehicle.Id())
+		return nil
+	}
+
+	datedVehicleJourneyRef, ok := builder.datedVehicleJourneyRef(vehicleJourney)
+	if !ok {
+		return nil
+	}
+
+	references := vehicleJourney.References.Copy()
+	modelDate := builder.tx.Model().Date()
+
+	return &siri.SIRIVehicleActivity{
+		RecordedAtTime:         vehicle.RecordedAtTime,
+		ValidUntilTime:         vehicle.RecordedAtTime,
+		VehicleMonitoringRef:   vehicleId.Value(),
+		LineRef:                lineObjectId.Value(),
+		DataFrameRef:           builder.dataFrameGenerator.NewIdentifier(IdentifierAttributes{Id: modelDate.String()}),
+		DatedVehicleJourneyRef: datedVehicleJourneyRef,
+		PublishedLineName:      line.Name,
+		DirectionName:          vehicleJourney.Attributes["DirectionName"],
+		OriginRef:              builder.handleRef("OriginRef", vehicleJourney.Origin, references),
+		OriginName:             vehicleJourney.OriginName,
+		DestinationRef:         builder.handleRef("DestinationRef", vehicleJourney.Origin, references),
+		DestinationName:        vehicleJourney.DestinationName,
+		Monitored:              vehicleJourney.Monitored,
+		Bearing:                vehicle.Bearing,
+		Longitude:              vehicle.Longitude,
+		Latitude:               vehicle.Latitude,
+	}
+}
+
+func (builder *BroadcastVehicleMonitoringBuilder) datedVehicleJourneyRef(vehicleJourney model.VehicleJourney) (string, bool) {
+	vehicleJourneyId, ok := vehicleJourney.ObjectID(builder.remoteObjectidKind)
+	if ok {
+		return vehicleJourneyId.Value(), true
+	}
+	defaultObjectID, ok := vehicleJourney.ObjectID("_default")
+	if !ok {
+		return "", false
+	}
+	return builder.referenceGenerator.NewIdentifier(IdentifierAttributes{Type: "VehicleJourney", Id: defaultObjectID.Value()}), true
+}
+
+func (builder *BroadcastVehicleMonitoringBuilder) handleRef(refType, origin string, references model.References) string {
+	reference, ok := references.Get(refType)
+	if !ok || reference.ObjectId == nil {
+		return ""
+	}
+	if refType == "DestinationRef" && builder.noDestinationRefRewrite(origin) {
+		return reference.ObjectId.Value()
+	}
+	stopArea, ok := builder.tx.Model().StopAreas().FindByObjectId(*reference.ObjectId)
+	if ok {
+		obj, ok := stopArea.ReferentOrSelfObjectId(builder.remoteObjectidKind)
+		if ok {
+			return obj.Value()
+		}
+	}
+	return builder.stopAreareferenceGenerator.NewIdentifier(IdentifierAttributes{Id: reference.GetSha1()})
+}
+
+func (builder *BroadcastVehicleMonitoringBuilder) noDestinationRefRewrite(origin string) bool {
+	for _, o := range builder.noDestinationRefRewritingFrom {
+		if origin == strings.TrimSpace(o) {
+			return true
+		}
+	}
+	return false
+}
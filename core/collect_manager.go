@@ -2,6 +2,8 @@ package core
 
 import (
 	"strconv"
+	"sync"
+	"time"
 
 	"bitbucket.org/enroute-mobi/ara/logger"
 	"bitbucket.org/enroute-mobi/ara/model"
@@ -14,6 +16,8 @@ type UpdateSubscriber func(model.UpdateEvent)
 type CollectManagerInterface interface {
 	HandlePartnerStatusChange(partner string, status bool)
 	UpdateStopArea(request *StopAreaUpdateRequest)
+	UpdateVehicle(request *VehicleUpdateRequest)
+	UpdateLine(request *LineUpdateRequest)
 
 	HandleUpdateEvent(UpdateSubscriber UpdateSubscriber)
 	BroadcastUpdateEvent(event model.UpdateEvent)
@@ -21,6 +25,9 @@ type CollectManagerInterface interface {
 	UpdateSituation(request *SituationUpdateRequest)
 	HandleSituationUpdateEvent(SituationUpdateSubscriber)
 	BroadcastSituationUpdateEvent(event []*model.SituationUpdateEvent)
+
+	UpdateEventCounts() map[string]uint64
+	LastCollects() map[string]time.Time
 }
 
 type CollectManager struct {
@@ -29,6 +36,10 @@ type CollectManager struct {
 	SituationUpdateSubscribers []SituationUpdateSubscriber
 	UpdateSubscribers          []UpdateSubscriber
 	referential                *Referential
+
+	countMutex   *sync.Mutex
+	updateEvents map[string]uint64
+	lastCollects map[string]time.Time
 }
 
 // TestCollectManager has a test StopAreaUpdateSubscriber method
@@ -50,6 +61,10 @@ func (manager *TestCollectManager) UpdateStopArea(request *StopAreaUpdateRequest
 	manager.Done <- true
 }
 
+func (manager *TestCollectManager) UpdateVehicle(request *VehicleUpdateRequest) {}
+
+func (manager *TestCollectManager) UpdateLine(request *LineUpdateRequest) {}
+
 func (manager *TestCollectManager) TestUpdateSubscriber(event model.UpdateEvent) {
 	manager.UpdateEvents = append(manager.UpdateEvents, event)
 }
@@ -66,6 +81,8 @@ func (manager *TestCollectManager) UpdateSituation(*SituationUpdateRequest)
 func (manager *TestCollectManager) HandleSituationUpdateEvent(SituationUpdateSubscriber) {}
 func (manager *TestCollectManager) BroadcastSituationUpdateEvent(event []*model.SituationUpdateEvent) {
 }
+func (manager *TestCollectManager) UpdateEventCounts() map[string]uint64 { return nil }
+func (manager *TestCollectManager) LastCollects() map[string]time.Time   { return nil }
 
 // TEST END
 
@@ -74,7 +91,66 @@ func NewCollectManager(referential *Referential) CollectManagerInterface {
 		referential:                referential,
 		SituationUpdateSubscribers: make([]SituationUpdateSubscriber, 0),
 		UpdateSubscribers:          make([]UpdateSubscriber, 0),
+		countMutex:                 &sync.Mutex{},
+		updateEvents:               make(map[string]uint64),
+		lastCollects:               make(map[string]time.Time),
+	}
+}
+
+// Returns the number of broadcasted update events by kind
+func (manager *CollectManager) UpdateEventCounts() map[string]uint64 {
+	manager.countMutex.Lock()
+	defer manager.countMutex.Unlock()
+
+	counts := make(map[string]uint64, len(manager.updateEvents))
+	for kind, count := range manager.updateEvents {
+		counts[kind] = count
 	}
+	return counts
+}
+
+func (manager *CollectManager) countUpdateEvents(kind string, count int) {
+	manager.countMutex.Lock()
+	manager.updateEvents[kind] += uint64(count)
+	manager.countMutex.Unlock()
+}
+
+// Returns the time of the last update event broadcasted by partner
+func (manager *CollectManager) LastCollects() map[string]time.Time {
+	manager.countMutex.Lock()
+	defer manager.countMutex.Unlock()
+
+	lastCollects := make(map[string]time.Time, len(manager.lastCollects))
+	for partner, lastCollect := range manager.lastCollects {
+		lastCollects[partner] = lastCollect
+	}
+	return lastCollects
+}
+
+func (manager *CollectManager) collected(origin string) {
+	if origin == "" {
+		return
+	}
+	now := manager.referential.Clock().Now()
+	manager.countMutex.Lock()
+	manager.lastCollects[origin] = now
+	manager.countMutex.Unlock()
+}
+
+func updateEventOrigin(event model.UpdateEvent) string {
+	switch e := event.(type) {
+	case *model.StopAreaUpdateEvent:
+		return e.Origin
+	case *model.StopVisitUpdateEvent:
+		return e.Origin
+	case *model.VehicleJourneyUpdateEvent:
+		return e.Origin
+	case *model.LineUpdateEvent:
+		return e.Origin
+	case *model.VehicleUpdateEvent:
+		return e.Origin
+	}
+	return ""
 }
 
 func (manager *CollectManager) HandleUpdateEvent(UpdateSubscriber UpdateSubscriber) {
@@ -82,6 +158,8 @@ func (manager *CollectManager) HandleUpdateEvent(UpdateSubscriber UpdateSubscrib
 }
 
 func (manager *CollectManager) BroadcastUpdateEvent(event model.UpdateEvent) {
+	manager.countUpdateEvents(event.EventKind().String(), 1)
+	manager.collected(updateEventOrigin(event))
 	for _, UpdateSubscriber := range manager.UpdateSubscribers {
 		UpdateSubscriber(event)
 	}
@@ -148,11 +226,97 @@ func (manager *CollectManager) UpdateStopArea(request *StopAreaUpdateRequest) {
 	}
 }
 
+func (manager *CollectManager) UpdateVehicle(request *VehicleUpdateRequest) {
+	line, ok := manager.referential.Model().Lines().Find(request.LineId())
+	if !ok {
+		logger.Log.Debugf("Can't find Line %v in Collect Manager", request.LineId())
+		return
+	}
+
+	for _, partner := range manager.referential.Partners().FindAllByCollectPriority() {
+		subscriptionCollector := partner.VehicleMonitoringSubscriptionCollector()
+		requestCollector := partner.VehicleMonitoringRequestCollector()
+
+		if subscriptionCollector == nil && requestCollector == nil {
+			continue
+		}
+
+		if partner.PartnerStatus.OperationnalStatus != OPERATIONNAL_STATUS_UP {
+			if b, _ := strconv.ParseBool(partner.Setting(COLLECT_SUBSCRIPTIONS_PERSISTENT)); !b || subscriptionCollector == nil {
+				continue
+			}
+		}
+
+		lineObjectID, ok := line.ObjectID(partner.Setting(REMOTE_OBJECTID_KIND))
+		if !ok {
+			continue
+		}
+
+		// Vehicles are only collected on the lines defined by collect.include_lines
+		if !partner.CanCollectLine(lineObjectID.Value()) {
+			continue
+		}
+
+		logger.Log.Debugf("RequestVehicleUpdate %v with Partner %v", lineObjectID.Value(), partner.Slug())
+		if subscriptionCollector != nil {
+			subscriptionCollector.RequestVehicleUpdate(request)
+			return
+		}
+		requestCollector.RequestVehicleUpdate(request)
+		return
+	}
+}
+
+func (manager *CollectManager) UpdateLine(request *LineUpdateRequest) {
+	line, ok := manager.referential.Model().Lines().Find(request.LineId())
+	if !ok {
+		logger.Log.Debugf("Can't find Line %v in Collect Manager", request.LineId())
+		return
+	}
+
+	for _, partner := range manager.referential.Partners().FindAllByCollectPriority() {
+		subscriptionCollector := partner.EstimatedTimetableSubscriptionCollector()
+		requestCollector := partner.EstimatedTimetableRequestCollector()
+
+		if subscriptionCollector == nil && requestCollector == nil {
+			continue
+		}
+
+		if partner.PartnerStatus.OperationnalStatus != OPERATIONNAL_STATUS_UP {
+			if b, _ := strconv.ParseBool(partner.Setting(COLLECT_SUBSCRIPTIONS_PERSISTENT)); !b || subscriptionCollector == nil {
+				continue
+			}
+		}
+
+		lineObjectID, ok := line.ObjectID(partner.Setting(REMOTE_OBJECTID_KIND))
+		if !ok {
+			continue
+		}
+
+		// EstimatedTimetables are only collected on the lines defined by collect.include_lines
+		if !partner.CanCollectLine(lineObjectID.Value()) {
+			continue
+		}
+
+		logger.Log.Debugf("RequestLineUpdate %v with Partner %v", lineObjectID.Value(), partner.Slug())
+		if subscriptionCollector != nil {
+			subscriptionCollector.RequestLineUpdate(request)
+			return
+		}
+		requestCollector.RequestLineUpdate(request)
+		return
+	}
+}
+
 func (manager *CollectManager) HandleSituationUpdateEvent(SituationUpdateSubscriber SituationUpdateSubscriber) {
 	manager.SituationUpdateSubscribers = append(manager.SituationUpdateSubscribers, SituationUpdateSubscriber)
 }
 
 func (manager *CollectManager) BroadcastSituationUpdateEvent(event []*model.SituationUpdateEvent) {
+	manager.countUpdateEvents("Situation", len(event))
+	for i := range event {
+		manager.collected(event[i].Origin)
+	}
 	for _, SituationUpdateSubscriber := range manager.SituationUpdateSubscribers {
 		SituationUpdateSubscriber(event)
 	}
@@ -171,6 +335,28 @@ func (manager *CollectManager) UpdateSituation(request *SituationUpdateRequest)
 	}
 }
 
+type situationSubscriptionCollector interface {
+	RequestAllSituationsUpdate()
+	RequestSituationUpdate(kind string, requestedId model.ObjectID)
+}
+
+// SituationExchange collectors are used when present, GeneralMessage ones otherwise
+func (manager *CollectManager) situationCollectors(partner *Partner) (requestConnector GeneralMessageRequestCollector, subscriptionConnector situationSubscriptionCollector) {
+	if c := partner.SituationExchangeRequestCollector(); c != nil {
+		requestConnector = c
+	} else if c := partner.GeneralMessageRequestCollector(); c != nil {
+		requestConnector = c
+	}
+
+	if c := partner.SituationExchangeSubscriptionCollector(); c != nil {
+		subscriptionConnector = c
+	} else if c := partner.GeneralMessageSubscriptionCollector(); c != nil {
+		subscriptionConnector = c
+	}
+
+	return
+}
+
 func (manager *CollectManager) requestAllSituations() {
 	for _, partner := range manager.referential.Partners().FindAllByCollectPriority() {
 		if partner.PartnerStatus.OperationnalStatus != OPERATIONNAL_STATUS_UP {
@@ -180,8 +366,7 @@ func (manager *CollectManager) requestAllSituations() {
 			continue
 		}
 
-		requestConnector := partner.GeneralMessageRequestCollector()
-		subscriptionConnector := partner.GeneralMessageSubscriptionCollector()
+		requestConnector, subscriptionConnector := manager.situationCollectors(partner)
 		if requestConnector == nil && subscriptionConnector == nil {
 			continue
 		}
@@ -210,8 +395,7 @@ func (manager *CollectManager) requestLineFilteredSituation(requestedId string)
 			continue
 		}
 
-		requestConnector := partner.GeneralMessageRequestCollector()
-		subscriptionConnector := partner.GeneralMessageSubscriptionCollector()
+		requestConnector, subscriptionConnector := manager.situationCollectors(partner)
 
 		if requestConnector == nil && subscriptionConnector == nil {
 			continue
@@ -254,8 +438,7 @@ func (manager *CollectManager) requestStopAreaFilteredSituation(requestedId stri
 			continue
 		}
 
-		requestConnector := partner.GeneralMessageRequestCollector()
-		subscriptionConnector := partner.GeneralMessageSubscriptionCollector()
+		requestConnector, subscriptionConnector := manager.situationCollectors(partner)
 
 		if requestConnector == nil && subscriptionConnector == nil {
 			continue
@@ -160,3 +160,15 @@ func Test_CollectManager_StopAreaMonitoredWithReferent(t *testing.T) {
 		t.Errorf("StopArea should have an Origin partner:false, got: %v", updatedStopArea.Origins.AllOrigin())
 	}
 }
+
+func Test_CollectManager_LastCollects(t *testing.T) {
+	referentials := NewMemoryReferentials()
+	referential := referentials.New("referential")
+	referential.Save()
+
+	referential.CollectManager().BroadcastUpdateEvent(&model.VehicleUpdateEvent{Origin: "partner"})
+
+	if _, ok := referential.CollectManager().LastCollects()["partner"]; !ok {
+		t.Errorf("VehicleUpdateEvent Origin should be counted in LastCollects, got: %v", referential.CollectManager().LastCollects())
+	}
+}
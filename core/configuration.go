@@ -0,0 +1,450 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"bitbucket.org/enroute-mobi/ara/model"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	CONFIGURATION_CREATE = "create"
+	CONFIGURATION_UPDATE = "update"
+	CONFIGURATION_DELETE = "delete"
+)
+
+// YAML definition of a Referential and its Partners, used by the config
+// apply and export commands
+type ReferentialDefinition struct {
+	Slug           ReferentialSlug      `yaml:"slug"`
+	Name           string               `yaml:"name,omitempty"`
+	OrganisationId string               `yaml:"organisation_id,omitempty"`
+	Settings       map[string]string    `yaml:"settings,omitempty"`
+	Tokens         []string             `yaml:"tokens,omitempty"`
+	Partners       []*PartnerDefinition `yaml:"partners,omitempty"`
+}
+
+type PartnerDefinition struct {
+	Slug           PartnerSlug       `yaml:"slug"`
+	Name           string            `yaml:"name,omitempty"`
+	ConnectorTypes []string          `yaml:"connectors,omitempty"`
+	Settings       map[string]string `yaml:"settings,omitempty"`
+}
+
+// Reads the YAML documents of the given file, or of all the .yml and .yaml
+// files of the given directory
+func LoadReferentialDefinitions(path string) ([]*ReferentialDefinition, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files = nil
+		for _, pattern := range []string{"*.yml", "*.yaml"} {
+			matches, _ := filepath.Glob(filepath.Join(path, pattern))
+			files = append(files, matches...)
+		}
+		sort.Strings(files)
+	}
+
+	definitions := []*ReferentialDefinition{}
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		fileDefinitions, err := ReadReferentialDefinitions(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", file, err)
+		}
+		definitions = append(definitions, fileDefinitions...)
+	}
+	return definitions, nil
+}
+
+func ReadReferentialDefinitions(reader io.Reader) ([]*ReferentialDefinition, error) {
+	definitions := []*ReferentialDefinition{}
+	decoder := yaml.NewDecoder(reader)
+	decoder.SetStrict(true)
+	for {
+		definition := &ReferentialDefinition{}
+		err := decoder.Decode(definition)
+		if err == io.EOF {
+			return definitions, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, definition)
+	}
+}
+
+func WriteReferentialDefinitions(writer io.Writer, definitions []*ReferentialDefinition) error {
+	encoder := yaml.NewEncoder(writer)
+	for _, definition := range definitions {
+		if err := encoder.Encode(definition); err != nil {
+			return err
+		}
+	}
+	return encoder.Close()
+}
+
+// Returns the definitions of the given Referentials and their Partners,
+// ordered by slug
+func NewReferentialDefinitions(referentials Referentials) []*ReferentialDefinition {
+	definitions := []*ReferentialDefinition{}
+	for _, referential := range referentials.FindAll() {
+		definition := &ReferentialDefinition{
+			Slug:           referential.slug,
+			Name:           referential.Name,
+			OrganisationId: referential.OrganisationId,
+			Settings:       referential.Settings,
+			Tokens:         referential.Tokens,
+		}
+		for _, partner := range referential.Partners().FindAll() {
+			definition.Partners = append(definition.Partners, &PartnerDefinition{
+				Slug:           partner.slug,
+				Name:           partner.Name,
+				ConnectorTypes: partner.ConnectorTypes,
+				Settings:       partner.SettingsDefinition(),
+			})
+		}
+		sort.Slice(definition.Partners, func(i, j int) bool { return definition.Partners[i].Slug < definition.Partners[j].Slug })
+		definitions = append(definitions, definition)
+	}
+	sort.Slice(definitions, func(i, j int) bool { return definitions[i].Slug < definitions[j].Slug })
+	return definitions
+}
+
+type ConfigurationChange struct {
+	Action      string
+	Referential ReferentialSlug
+	Partner     PartnerSlug
+	Details     []string
+}
+
+func (change *ConfigurationChange) String() string {
+	s := fmt.Sprintf("%v referential %v", change.Action, change.Referential)
+	if change.Partner != "" {
+		s = fmt.Sprintf("%v partner %v/%v", change.Action, change.Referential, change.Partner)
+	}
+	for _, detail := range change.Details {
+		s += "\n    " + detail
+	}
+	return s
+}
+
+// Changes required to replace the current Referentials and Partners by the
+// definitions. The Referentials and Partners keep their identifiers when
+// their slug is unchanged.
+type ConfigurationPlan struct {
+	Changes []*ConfigurationChange
+
+	desired *MemoryReferentials
+}
+
+func NewConfigurationPlan(current Referentials, definitions []*ReferentialDefinition) (*ConfigurationPlan, error) {
+	plan := &ConfigurationPlan{
+		desired: NewMemoryReferentials(),
+	}
+
+	for _, definition := range definitions {
+		if err := plan.addReferential(current, definition); err != nil {
+			return nil, err
+		}
+	}
+	plan.computeChanges(current)
+
+	return plan, nil
+}
+
+func (plan *ConfigurationPlan) addReferential(current Referentials, definition *ReferentialDefinition) error {
+	existing := current.FindBySlug(definition.Slug)
+
+	apiReferential := &APIReferential{
+		Slug:           definition.Slug,
+		Name:           definition.Name,
+		OrganisationId: definition.OrganisationId,
+		Settings:       definition.Settings,
+		Tokens:         definition.Tokens,
+		manager:        plan.desired,
+	}
+	if apiReferential.Settings == nil {
+		apiReferential.Settings = make(map[string]string)
+	}
+	if existing != nil {
+		apiReferential.id = existing.id
+	}
+	if !apiReferential.Validate() {
+		return fmt.Errorf("invalid referential %v: %v", definition.Slug, formatErrors(apiReferential.Errors))
+	}
+
+	referential := plan.desired.New(definition.Slug)
+	referential.id = apiReferential.id
+	referential.SetDefinition(apiReferential)
+	plan.desired.Save(referential)
+
+	for _, partnerDefinition := range definition.Partners {
+		apiPartner := &APIPartner{
+			Slug:           partnerDefinition.Slug,
+			Name:           partnerDefinition.Name,
+			ConnectorTypes: partnerDefinition.ConnectorTypes,
+			Settings:       partnerDefinition.Settings,
+			factories:      make(map[string]ConnectorFactory),
+			manager:        referential.Partners(),
+		}
+		if apiPartner.Settings == nil {
+			apiPartner.Settings = make(map[string]string)
+		}
+		if apiPartner.ConnectorTypes == nil {
+			apiPartner.ConnectorTypes = []string{}
+		}
+		if existing != nil {
+			if existingPartner, ok := existing.Partners().FindBySlug(partnerDefinition.Slug); ok {
+				apiPartner.Id = existingPartner.id
+			}
+		}
+		if !apiPartner.Validate() {
+			return fmt.Errorf("invalid partner %v/%v: %v", definition.Slug, partnerDefinition.Slug, formatErrors(apiPartner.Errors))
+		}
+
+		partner := referential.Partners().New(partnerDefinition.Slug)
+		partner.SetDefinition(apiPartner)
+		referential.Partners().Save(partner)
+	}
+
+	return nil
+}
+
+func (plan *ConfigurationPlan) computeChanges(current Referentials) {
+	for _, referential := range plan.desired.FindAll() {
+		existing := current.FindBySlug(referential.slug)
+		if existing == nil {
+			plan.Changes = append(plan.Changes, &ConfigurationChange{
+				Action:      CONFIGURATION_CREATE,
+				Referential: referential.slug,
+			})
+			for _, partner := range referential.Partners().FindAll() {
+				plan.Changes = append(plan.Changes, &ConfigurationChange{
+					Action:      CONFIGURATION_CREATE,
+					Referential: referential.slug,
+					Partner:     partner.slug,
+				})
+			}
+			continue
+		}
+
+		details := compareReferentials(existing, referential)
+		if len(details) != 0 {
+			plan.Changes = append(plan.Changes, &ConfigurationChange{
+				Action:      CONFIGURATION_UPDATE,
+				Referential: referential.slug,
+				Details:     details,
+			})
+		}
+		plan.computePartnerChanges(existing, referential)
+	}
+
+	for _, existing := range current.FindAll() {
+		if plan.desired.FindBySlug(existing.slug) == nil {
+			plan.Changes = append(plan.Changes, &ConfigurationChange{
+				Action:      CONFIGURATION_DELETE,
+				Referential: existing.slug,
+			})
+		}
+	}
+
+	sort.SliceStable(plan.Changes, func(i, j int) bool {
+		if plan.Changes[i].Referential != plan.Changes[j].Referential {
+			return plan.Changes[i].Referential < plan.Changes[j].Referential
+		}
+		return plan.Changes[i].Partner < plan.Changes[j].Partner
+	})
+}
+
+func (plan *ConfigurationPlan) computePartnerChanges(existing, referential *Referential) {
+	for _, partner := range referential.Partners().FindAll() {
+		existingPartner, ok := existing.Partners().FindBySlug(partner.slug)
+		if !ok {
+			plan.Changes = append(plan.Changes, &ConfigurationChange{
+				Action:      CONFIGURATION_CREATE,
+				Referential: referential.slug,
+				Partner:     partner.slug,
+			})
+			continue
+		}
+
+		details := comparePartners(existingPartner, partner)
+		if len(details) != 0 {
+			plan.Changes = append(plan.Changes, &ConfigurationChange{
+				Action:      CONFIGURATION_UPDATE,
+				Referential: referential.slug,
+				Partner:     partner.slug,
+				Details:     details,
+			})
+		}
+	}
+
+	for _, existingPartner := range existing.Partners().FindAll() {
+		if _, ok := referential.Partners().FindBySlug(existingPartner.slug); !ok {
+			plan.Changes = append(plan.Changes, &ConfigurationChange{
+				Action:      CONFIGURATION_DELETE,
+				Referential: referential.slug,
+				Partner:     existingPartner.slug,
+			})
+		}
+	}
+}
+
+// Replaces the Referentials and Partners in a single database transaction.
+// The subscriptions of the removed Partners are deleted.
+func (plan *ConfigurationPlan) Apply() error {
+	tx, err := model.Database.Begin()
+	if err != nil {
+		return fmt.Errorf("database error: %v", err)
+	}
+
+	for _, query := range []string{"delete from partners;", "delete from referentials;"} {
+		if _, err = tx.Exec(query); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("database error: %v", err)
+		}
+	}
+
+	for _, referential := range plan.desired.FindAll() {
+		dbReferential, err := plan.desired.newDbReferential(referential)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("internal error: %v", err)
+		}
+		if err = tx.Insert(dbReferential); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("database error: %v", err)
+		}
+
+		manager := referential.partners.(*PartnerManager)
+		for _, partner := range manager.FindAll() {
+			dbPartner, err := manager.newDbPartner(partner)
+			if err != nil {
+				tx.Rollback()
+				return fmt.Errorf("internal error: %v", err)
+			}
+			if err = tx.Insert(dbPartner); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("database error: %v", err)
+			}
+		}
+	}
+
+	_, err = tx.Exec("delete from subscriptions where partner_id not in (select id from partners);")
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("database error: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("database error: %v", err)
+	}
+	return nil
+}
+
+func compareReferentials(existing, referential *Referential) (details []string) {
+	details = compareValues(details, "name", existing.Name, referential.Name)
+	details = compareValues(details, "organisation_id", existing.OrganisationId, referential.OrganisationId)
+	details = compareSettings(details, existing.Settings, referential.Settings)
+	if !equalStrings(existing.Tokens, referential.Tokens) {
+		details = append(details, "tokens: changed")
+	}
+	return
+}
+
+func comparePartners(existing, partner *Partner) (details []string) {
+	details = compareValues(details, "name", existing.Name, partner.Name)
+	if !equalStrings(existing.ConnectorTypes, partner.ConnectorTypes) {
+		details = append(details, fmt.Sprintf("connectors: %v -> %v", existing.ConnectorTypes, partner.ConnectorTypes))
+	}
+	details = compareSettings(details, existing.SettingsDefinition(), partner.SettingsDefinition())
+	return
+}
+
+func compareValues(details []string, attribute, existing, value string) []string {
+	if existing == value {
+		return details
+	}
+	return append(details, fmt.Sprintf("%v: %q -> %q", attribute, existing, value))
+}
+
+func compareSettings(details []string, existing, settings map[string]string) []string {
+	keys := []string{}
+	for key := range existing {
+		keys = append(keys, key)
+	}
+	for key := range settings {
+		if _, ok := existing[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		existingValue, wasDefined := existing[key]
+		value, isDefined := settings[key]
+		switch {
+		case !wasDefined:
+			details = append(details, fmt.Sprintf("settings.%v: added %q", key, displayedSetting(key, value)))
+		case !isDefined:
+			details = append(details, fmt.Sprintf("settings.%v: removed", key))
+		case existingValue != value:
+			details = append(details, fmt.Sprintf("settings.%v: %q -> %q", key, displayedSetting(key, existingValue), displayedSetting(key, value)))
+		}
+	}
+	return details
+}
+
+// Hides the secrets in the displayed changes
+func displayedSetting(key, value string) string {
+	if strings.Contains(key, "password") || strings.Contains(key, "token") || strings.Contains(key, "credential") {
+		return "***"
+	}
+	return value
+}
+
+// Ignores the order
+func equalStrings(a, b []string) bool {
+	sortedA := append([]string{}, a...)
+	sortedB := append([]string{}, b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	return reflect.DeepEqual(sortedA, sortedB)
+}
+
+func formatErrors(errors Errors) string {
+	b, _ := json.Marshal(errors)
+	return string(b)
+}
+
+// Writes the definitions in the directory, one file by Referential
+func WriteReferentialDefinitionFiles(directory string, definitions []*ReferentialDefinition) error {
+	for _, definition := range definitions {
+		var b strings.Builder
+		if err := WriteReferentialDefinitions(&b, []*ReferentialDefinition{definition}); err != nil {
+			return err
+		}
+		path := filepath.Join(directory, fmt.Sprintf("%v.yml", definition.Slug))
+		if err := ioutil.WriteFile(path, []byte(b.String()), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
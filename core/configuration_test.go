@@ -0,0 +1,155 @@
+package core
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_LoadReferentialDefinitions(t *testing.T) {
+	definitions, err := LoadReferentialDefinitions("testdata/configuration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(definitions) != 2 {
+		t.Fatalf("Wrong number of definitions: %v", len(definitions))
+	}
+
+	definition := definitions[0]
+	if definition.Slug != "test" || definition.Name != "Test" || !reflect.DeepEqual(definition.Tokens, []string{"secret"}) {
+		t.Errorf("Wrong referential definition: %v", definition)
+	}
+	if len(definition.Partners) != 2 {
+		t.Fatalf("Wrong number of partners: %v", len(definition.Partners))
+	}
+	partner := definition.Partners[0]
+	if partner.Slug != "broadcaster" || partner.ConnectorTypes[0] != SIRI_STOP_MONITORING_REQUEST_BROADCASTER || partner.Settings["local_credential"] != "broadcaster" {
+		t.Errorf("Wrong partner definition: %v", partner)
+	}
+}
+
+func Test_ReadReferentialDefinitions_UnknownAttribute(t *testing.T) {
+	if _, err := ReadReferentialDefinitions(strings.NewReader("slug: test\nwrong: true\n")); err == nil {
+		t.Errorf("Unknown attributes should be rejected")
+	}
+}
+
+func Test_NewConfigurationPlan(t *testing.T) {
+	current := NewMemoryReferentials()
+
+	referential := current.New("test")
+	referential.Name = "Old name"
+	referential.Tokens = []string{"secret"}
+	current.Save(referential)
+
+	collector := referential.Partners().New("collector")
+	collector.Name = "Collector"
+	collector.SetSetting("remote_url", "http://localhost/old")
+	collector.SetSetting("remote_credential", "password")
+	referential.Partners().Save(collector)
+
+	removedPartner := referential.Partners().New("removed")
+	referential.Partners().Save(removedPartner)
+
+	removedReferential := current.New("removed")
+	current.Save(removedReferential)
+
+	definitions, err := LoadReferentialDefinitions("testdata/configuration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := NewConfigurationPlan(current, definitions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"create referential other",
+		"delete referential removed",
+		`update referential test
+    name: "Old name" -> "Test"
+    settings.model.reload_at: added "03:00"`,
+		"create partner test/broadcaster",
+		`update partner test/collector
+    settings.remote_credential: removed
+    settings.remote_url: "http://localhost/old" -> "http://localhost/siri"`,
+		"delete partner test/removed",
+	}
+	changes := []string{}
+	for _, change := range plan.Changes {
+		changes = append(changes, change.String())
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Wrong changes:\n got: %v\n want: %v", changes, expected)
+	}
+
+	desired := plan.desired.FindBySlug("test")
+	if desired.Id() != referential.Id() {
+		t.Errorf("Referential should keep its id")
+	}
+	if desiredCollector, _ := desired.Partners().FindBySlug("collector"); desiredCollector.Id() != collector.Id() {
+		t.Errorf("Partner should keep its id")
+	}
+	if _, ok := desired.Partners().FindBySlug("broadcaster"); !ok {
+		t.Errorf("Partner should be created")
+	}
+
+	plan, err = NewConfigurationPlan(plan.desired, definitions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) != 0 {
+		t.Errorf("Applied configuration should have no change, got %v", plan.Changes)
+	}
+}
+
+func Test_NewConfigurationPlan_Invalid(t *testing.T) {
+	var conditions = []struct {
+		yaml     string
+		expected string
+	}{
+		{"slug: Wrong Slug\n", "invalid referential Wrong Slug"},
+		{"slug: test\n---\nslug: test\n", "invalid referential test"},
+		{"slug: test\npartners:\n  - slug: partner\n    connectors: [siri-stop-monitoring-request-broadcaster]\n", "invalid partner test/partner"},
+	}
+
+	for _, condition := range conditions {
+		definitions, err := ReadReferentialDefinitions(strings.NewReader(condition.yaml))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = NewConfigurationPlan(NewMemoryReferentials(), definitions)
+		if err == nil || !strings.Contains(err.Error(), condition.expected) {
+			t.Errorf("Wrong error for %q:\n got: %v\n want: %v", condition.yaml, err, condition.expected)
+		}
+	}
+}
+
+func Test_NewReferentialDefinitions(t *testing.T) {
+	definitions, err := LoadReferentialDefinitions("testdata/configuration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := NewConfigurationPlan(NewMemoryReferentials(), definitions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buffer bytes.Buffer
+	if err = WriteReferentialDefinitions(&buffer, NewReferentialDefinitions(plan.desired)); err != nil {
+		t.Fatal(err)
+	}
+	exported, err := ReadReferentialDefinitions(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err = NewConfigurationPlan(plan.desired, exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) != 0 {
+		t.Errorf("Exported configuration should have no change, got %v", plan.Changes)
+	}
+}
@@ -9,34 +9,55 @@ const (
 	SIRI_PARTNER = "siri-partner"
 
 	// Connectors
-	PUSH_COLLECTOR                                    = "push-collector"
-	SIRI_STOP_POINTS_DISCOVERY_REQUEST_COLLECTOR      = "siri-stop-points-discovery-request-collector"
-	SIRI_STOP_POINTS_DISCOVERY_REQUEST_BROADCASTER    = "siri-stop-points-discovery-request-broadcaster"
-	SIRI_LINES_DISCOVERY_REQUEST_BROADCASTER          = "siri-lines-discovery-request-broadcaster"
-	SIRI_SERVICE_REQUEST_BROADCASTER                  = "siri-service-request-broadcaster"
-	SIRI_STOP_MONITORING_REQUEST_COLLECTOR            = "siri-stop-monitoring-request-collector"
-	TEST_STOP_MONITORING_REQUEST_COLLECTOR            = "test-stop-monitoring-request-collector"
-	SIRI_STOP_MONITORING_REQUEST_BROADCASTER          = "siri-stop-monitoring-request-broadcaster"
-	SIRI_STOP_MONITORING_SUBSCRIPTION_COLLECTOR       = "siri-stop-monitoring-subscription-collector"
-	SIRI_STOP_MONITORING_SUBSCRIPTION_BROADCASTER     = "siri-stop-monitoring-subscription-broadcaster"
-	TEST_STOP_MONITORING_SUBSCRIPTION_BROADCASTER     = "siri-stop-monitoring-subscription-broadcaster-test"
-	SIRI_GENERAL_MESSAGE_REQUEST_COLLECTOR            = "siri-general-message-request-collector"
-	SIRI_GENERAL_MESSAGE_REQUEST_BROADCASTER          = "siri-general-message-request-broadcaster"
-	SIRI_GENERAL_MESSAGE_SUBSCRIPTION_COLLECTOR       = "siri-general-message-subscription-collector"
-	SIRI_GENERAL_MESSAGE_SUBSCRIPTION_BROADCASTER     = "siri-general-message-subscription-broadcaster"
-	TEST_GENERAL_MESSAGE_SUBSCRIPTION_BROADCASTER     = "siri-general-message-subscription-broadcaster-test"
-	SIRI_ESTIMATED_TIMETABLE_REQUEST_BROADCASTER      = "siri-estimated-timetable-request-broadcaster"
-	SIRI_ESTIMATED_TIMETABLE_SUBSCRIPTION_BROADCASTER = "siri-estimated-timetable-subscription-broadcaster"
-	TEST_ESTIMATED_TIMETABLE_SUBSCRIPTION_BROADCASTER = "siri-estimated-timetable-subscription-broadcaster-test"
-	SIRI_SUBSCRIPTION_REQUEST_DISPATCHER              = "siri-subscription-request-dispatcher"
-	SIRI_CHECK_STATUS_CLIENT_TYPE                     = "siri-check-status-client"
-	TEST_CHECK_STATUS_CLIENT_TYPE                     = "test-check-status-client"
-	SIRI_CHECK_STATUS_SERVER_TYPE                     = "siri-check-status-server"
-	SIRI_LITE_VEHICLE_MONITORING_REQUEST_BROADCASTER  = "siri-lite-vehicle-monitoring-request-broadcaster"
-	TEST_VALIDATION_CONNECTOR                         = "test-validation-connector"
-	TEST_STARTABLE_CONNECTOR                          = "test-startable-connector-connector"
-	GTFS_RT_TRIP_UPDATES_BROADCASTER                  = "gtfs-rt-trip-updates-broadcaster"
-	GTFS_RT_VEHICLE_POSITIONS_BROADCASTER             = "gtfs-rt-vehicle-positions-broadcaster"
+	PUSH_COLLECTOR                                      = "push-collector"
+	SIRI_STOP_POINTS_DISCOVERY_REQUEST_COLLECTOR        = "siri-stop-points-discovery-request-collector"
+	SIRI_STOP_POINTS_DISCOVERY_REQUEST_BROADCASTER      = "siri-stop-points-discovery-request-broadcaster"
+	SIRI_LINES_DISCOVERY_REQUEST_BROADCASTER            = "siri-lines-discovery-request-broadcaster"
+	SIRI_SERVICE_REQUEST_BROADCASTER                    = "siri-service-request-broadcaster"
+	SIRI_STOP_MONITORING_REQUEST_COLLECTOR              = "siri-stop-monitoring-request-collector"
+	TEST_STOP_MONITORING_REQUEST_COLLECTOR              = "test-stop-monitoring-request-collector"
+	SIRI_STOP_MONITORING_REQUEST_BROADCASTER            = "siri-stop-monitoring-request-broadcaster"
+	SIRI_STOP_MONITORING_SUBSCRIPTION_COLLECTOR         = "siri-stop-monitoring-subscription-collector"
+	SIRI_STOP_MONITORING_SUBSCRIPTION_BROADCASTER       = "siri-stop-monitoring-subscription-broadcaster"
+	TEST_STOP_MONITORING_SUBSCRIPTION_BROADCASTER       = "siri-stop-monitoring-subscription-broadcaster-test"
+	SIRI_GENERAL_MESSAGE_REQUEST_COLLECTOR              = "siri-general-message-request-collector"
+	SIRI_GENERAL_MESSAGE_REQUEST_BROADCASTER            = "siri-general-message-request-broadcaster"
+	SIRI_GENERAL_MESSAGE_SUBSCRIPTION_COLLECTOR         = "siri-general-message-subscription-collector"
+	SIRI_GENERAL_MESSAGE_SUBSCRIPTION_BROADCASTER       = "siri-general-message-subscription-broadcaster"
+	TEST_GENERAL_MESSAGE_SUBSCRIPTION_BROADCASTER       = "siri-general-message-subscription-broadcaster-test"
+	SIRI_ESTIMATED_TIMETABLE_REQUEST_COLLECTOR          = "siri-estimated-timetable-request-collector"
+	SIRI_ESTIMATED_TIMETABLE_SUBSCRIPTION_COLLECTOR     = "siri-estimated-timetable-subscription-collector"
+	SIRI_ESTIMATED_TIMETABLE_REQUEST_BROADCASTER        = "siri-estimated-timetable-request-broadcaster"
+	SIRI_ESTIMATED_TIMETABLE_SUBSCRIPTION_BROADCASTER   = "siri-estimated-timetable-subscription-broadcaster"
+	TEST_ESTIMATED_TIMETABLE_SUBSCRIPTION_BROADCASTER   = "siri-estimated-timetable-subscription-broadcaster-test"
+	SIRI_VEHICLE_MONITORING_REQUEST_COLLECTOR           = "siri-vehicle-monitoring-request-collector"
+	SIRI_VEHICLE_MONITORING_REQUEST_BROADCASTER         = "siri-vehicle-monitoring-request-broadcaster"
+	SIRI_VEHICLE_MONITORING_SUBSCRIPTION_COLLECTOR      = "siri-vehicle-monitoring-subscription-collector"
+	SIRI_VEHICLE_MONITORING_SUBSCRIPTION_BROADCASTER    = "siri-vehicle-monitoring-subscription-broadcaster"
+	TEST_VEHICLE_MONITORING_SUBSCRIPTION_BROADCASTER    = "siri-vehicle-monitoring-subscription-broadcaster-test"
+	SIRI_SITUATION_EXCHANGE_REQUEST_COLLECTOR           = "siri-situation-exchange-request-collector"
+	SIRI_SITUATION_EXCHANGE_REQUEST_BROADCASTER         = "siri-situation-exchange-request-broadcaster"
+	SIRI_SITUATION_EXCHANGE_SUBSCRIPTION_COLLECTOR      = "siri-situation-exchange-subscription-collector"
+	SIRI_SITUATION_EXCHANGE_SUBSCRIPTION_BROADCASTER    = "siri-situation-exchange-subscription-broadcaster"
+	TEST_SITUATION_EXCHANGE_SUBSCRIPTION_BROADCASTER    = "siri-situation-exchange-subscription-broadcaster-test"
+	SIRI_PRODUCTION_TIMETABLE_REQUEST_BROADCASTER       = "siri-production-timetable-request-broadcaster"
+	SIRI_PRODUCTION_TIMETABLE_SUBSCRIPTION_BROADCASTER  = "siri-production-timetable-subscription-broadcaster"
+	SIRI_SUBSCRIPTION_REQUEST_DISPATCHER                = "siri-subscription-request-dispatcher"
+	SIRI_CHECK_STATUS_CLIENT_TYPE                       = "siri-check-status-client"
+	TEST_CHECK_STATUS_CLIENT_TYPE                       = "test-check-status-client"
+	SIRI_CHECK_STATUS_SERVER_TYPE                       = "siri-check-status-server"
+	SIRI_LITE_VEHICLE_MONITORING_REQUEST_BROADCASTER    = "siri-lite-vehicle-monitoring-request-broadcaster"
+	SIRI_LITE_STOP_MONITORING_REQUEST_BROADCASTER       = "siri-lite-stop-monitoring-request-broadcaster"
+	SIRI_LITE_ESTIMATED_TIMETABLE_REQUEST_BROADCASTER   = "siri-lite-estimated-timetable-request-broadcaster"
+	SIRI_LITE_GENERAL_MESSAGE_REQUEST_BROADCASTER       = "siri-lite-general-message-request-broadcaster"
+	SIRI_LITE_STOP_POINTS_DISCOVERY_REQUEST_BROADCASTER = "siri-lite-stop-points-discovery-request-broadcaster"
+	SIRI_LITE_LINES_DISCOVERY_REQUEST_BROADCASTER       = "siri-lite-lines-discovery-request-broadcaster"
+	TEST_VALIDATION_CONNECTOR                           = "test-validation-connector"
+	TEST_STARTABLE_CONNECTOR                            = "test-startable-connector-connector"
+	GTFS_RT_TRIP_UPDATES_BROADCASTER                    = "gtfs-rt-trip-updates-broadcaster"
+	GTFS_RT_VEHICLE_POSITIONS_BROADCASTER               = "gtfs-rt-vehicle-positions-broadcaster"
+	GTFS_RT_SERVICE_ALERTS_BROADCASTER                  = "gtfs-rt-service-alerts-broadcaster"
+	GTFS_RT_REQUEST_COLLECTOR                           = "gtfs-rt-request-collector"
 )
 
 type Connector interface{}
@@ -103,12 +124,40 @@ func NewConnectorFactory(connectorType string) ConnectorFactory {
 		return &SIRIGeneralMessageSubscriptionBroadcasterFactory{}
 	case TEST_GENERAL_MESSAGE_SUBSCRIPTION_BROADCASTER:
 		return &TestSIRIGeneralMessageSubscriptionBroadcasterFactory{}
+	case SIRI_ESTIMATED_TIMETABLE_REQUEST_COLLECTOR:
+		return &SIRIEstimatedTimetableRequestCollectorFactory{}
 	case SIRI_ESTIMATED_TIMETABLE_REQUEST_BROADCASTER:
 		return &SIRIEstimatedTimetableBroadcasterFactory{}
+	case SIRI_ESTIMATED_TIMETABLE_SUBSCRIPTION_COLLECTOR:
+		return &SIRIEstimatedTimetableSubscriptionCollectorFactory{}
 	case SIRI_ESTIMATED_TIMETABLE_SUBSCRIPTION_BROADCASTER:
 		return &SIRIEstimatedTimetableSubscriptionBroadcasterFactory{}
 	case TEST_ESTIMATED_TIMETABLE_SUBSCRIPTION_BROADCASTER:
 		return &TestSIRIETTSubscriptionBroadcasterFactory{}
+	case SIRI_VEHICLE_MONITORING_REQUEST_COLLECTOR:
+		return &SIRIVehicleMonitoringRequestCollectorFactory{}
+	case SIRI_VEHICLE_MONITORING_REQUEST_BROADCASTER:
+		return &SIRIVehicleMonitoringRequestBroadcasterFactory{}
+	case SIRI_VEHICLE_MONITORING_SUBSCRIPTION_COLLECTOR:
+		return &SIRIVehicleMonitoringSubscriptionCollectorFactory{}
+	case SIRI_VEHICLE_MONITORING_SUBSCRIPTION_BROADCASTER:
+		return &SIRIVehicleMonitoringSubscriptionBroadcasterFactory{}
+	case TEST_VEHICLE_MONITORING_SUBSCRIPTION_BROADCASTER:
+		return &TestSIRIVMSubscriptionBroadcasterFactory{}
+	case SIRI_SITUATION_EXCHANGE_REQUEST_COLLECTOR:
+		return &SIRISituationExchangeRequestCollectorFactory{}
+	case SIRI_SITUATION_EXCHANGE_REQUEST_BROADCASTER:
+		return &SIRISituationExchangeRequestBroadcasterFactory{}
+	case SIRI_SITUATION_EXCHANGE_SUBSCRIPTION_COLLECTOR:
+		return &SIRISituationExchangeSubscriptionCollectorFactory{}
+	case SIRI_SITUATION_EXCHANGE_SUBSCRIPTION_BROADCASTER:
+		return &SIRISituationExchangeSubscriptionBroadcasterFactory{}
+	case TEST_SITUATION_EXCHANGE_SUBSCRIPTION_BROADCASTER:
+		return &TestSIRISituationExchangeSubscriptionBroadcasterFactory{}
+	case SIRI_PRODUCTION_TIMETABLE_REQUEST_BROADCASTER:
+		return &SIRIProductionTimetableRequestBroadcasterFactory{}
+	case SIRI_PRODUCTION_TIMETABLE_SUBSCRIPTION_BROADCASTER:
+		return &SIRIProductionTimetableSubscriptionBroadcasterFactory{}
 	case SIRI_CHECK_STATUS_CLIENT_TYPE:
 		return &SIRICheckStatusClientFactory{}
 	case SIRI_SUBSCRIPTION_REQUEST_DISPATCHER:
@@ -119,10 +168,24 @@ func NewConnectorFactory(connectorType string) ConnectorFactory {
 		return &SIRICheckStatusServerFactory{}
 	case SIRI_LITE_VEHICLE_MONITORING_REQUEST_BROADCASTER:
 		return &SIRILiteVehicleMonitoringRequestBroadcasterFactory{}
+	case SIRI_LITE_STOP_MONITORING_REQUEST_BROADCASTER:
+		return &SIRILiteStopMonitoringRequestBroadcasterFactory{}
+	case SIRI_LITE_ESTIMATED_TIMETABLE_REQUEST_BROADCASTER:
+		return &SIRILiteEstimatedTimetableRequestBroadcasterFactory{}
+	case SIRI_LITE_GENERAL_MESSAGE_REQUEST_BROADCASTER:
+		return &SIRILiteGeneralMessageRequestBroadcasterFactory{}
+	case SIRI_LITE_STOP_POINTS_DISCOVERY_REQUEST_BROADCASTER:
+		return &SIRILiteStopPointsDiscoveryRequestBroadcasterFactory{}
+	case SIRI_LITE_LINES_DISCOVERY_REQUEST_BROADCASTER:
+		return &SIRILiteLinesDiscoveryRequestBroadcasterFactory{}
 	case GTFS_RT_TRIP_UPDATES_BROADCASTER:
 		return &TripUpdatesBroadcasterFactory{}
 	case GTFS_RT_VEHICLE_POSITIONS_BROADCASTER:
 		return &VehiclePositionBroadcasterFactory{}
+	case GTFS_RT_SERVICE_ALERTS_BROADCASTER:
+		return &ServiceAlertsBroadcasterFactory{}
+	case GTFS_RT_REQUEST_COLLECTOR:
+		return &GtfsRequestCollectorFactory{}
 	case TEST_VALIDATION_CONNECTOR:
 		return &TestValidationFactory{}
 	case TEST_STARTABLE_CONNECTOR:
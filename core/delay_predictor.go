@@ -0,0 +1,132 @@
+package core
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/model"
+)
+
+const (
+	REFERENTIAL_SETTING_PREDICTION_ENABLED            = "prediction.enabled"
+	REFERENTIAL_SETTING_PREDICTION_DELAY_DECAY        = "prediction.delay_decay"
+	REFERENTIAL_SETTING_PREDICTION_MINIMUM_DWELL_TIME = "prediction.minimum_dwell_time"
+)
+
+// Propagates the delay observed on a StopVisit to the following StopVisits of
+// the same VehicleJourney which have no collected expected or actual time.
+//
+// At each following StopVisit, the delay is multiplied by the decay (between
+// 0 and 1, 1 by default) and reduced by the aimed dwell time exceeding the
+// minimum dwell time (no absorption when the minimum dwell time isn't
+// defined).
+//
+// The predicted times are saved in the "predicted" schedules.
+type DelayPredictor struct {
+	decay            float64
+	minimumDwellTime time.Duration
+}
+
+func NewDelayPredictor(referential *Referential) *DelayPredictor {
+	predictor := &DelayPredictor{
+		decay:            1,
+		minimumDwellTime: -1,
+	}
+
+	if decay, err := strconv.ParseFloat(referential.Setting(REFERENTIAL_SETTING_PREDICTION_DELAY_DECAY), 64); err == nil && decay >= 0 && decay <= 1 {
+		predictor.decay = decay
+	}
+	if minimumDwellTime, err := time.ParseDuration(referential.Setting(REFERENTIAL_SETTING_PREDICTION_MINIMUM_DWELL_TIME)); err == nil && minimumDwellTime >= 0 {
+		predictor.minimumDwellTime = minimumDwellTime
+	}
+
+	return predictor
+}
+
+func PredictionEnabled(referential *Referential) bool {
+	enabled, _ := strconv.ParseBool(referential.Setting(REFERENTIAL_SETTING_PREDICTION_ENABLED))
+	return enabled
+}
+
+// Updates the predicted schedules of the given StopVisits of a single
+// VehicleJourney. Returns the indexes of the modified StopVisits.
+func (predictor *DelayPredictor) Predict(stopVisits []model.StopVisit) (modified []int) {
+	indexes := make([]int, len(stopVisits))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return stopVisits[indexes[i]].PassageOrder < stopVisits[indexes[j]].PassageOrder
+	})
+
+	var delay time.Duration
+	var known bool
+
+	for _, i := range indexes {
+		stopVisit := &stopVisits[i]
+		aimed := stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_AIMED)
+		predicted := stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_PREDICTED)
+
+		if observedDelay, ok := predictor.observedDelay(stopVisit); ok {
+			delay, known = observedDelay, true
+			if !predicted.ArrivalTime().IsZero() || !predicted.DepartureTime().IsZero() {
+				stopVisit.Schedules.DeleteSchedule(model.STOP_VISIT_SCHEDULE_PREDICTED)
+				modified = append(modified, i)
+			}
+			continue
+		}
+		if !known || (aimed.ArrivalTime().IsZero() && aimed.DepartureTime().IsZero()) {
+			continue
+		}
+
+		delay = time.Duration(float64(delay) * predictor.decay)
+
+		var arrivalTime, departureTime time.Time
+		if !aimed.ArrivalTime().IsZero() {
+			arrivalTime = aimed.ArrivalTime().Add(delay)
+		}
+		if predictor.minimumDwellTime >= 0 && delay > 0 && !aimed.ArrivalTime().IsZero() && !aimed.DepartureTime().IsZero() {
+			if slack := aimed.DepartureTime().Sub(aimed.ArrivalTime()) - predictor.minimumDwellTime; slack > 0 {
+				if slack > delay {
+					slack = delay
+				}
+				delay -= slack
+			}
+		}
+		if !aimed.DepartureTime().IsZero() {
+			departureTime = aimed.DepartureTime().Add(delay)
+		}
+
+		if delay == 0 && arrivalTime.Equal(aimed.ArrivalTime()) {
+			// No delay anymore, the aimed times are used
+			if !predicted.ArrivalTime().IsZero() || !predicted.DepartureTime().IsZero() {
+				stopVisit.Schedules.DeleteSchedule(model.STOP_VISIT_SCHEDULE_PREDICTED)
+				modified = append(modified, i)
+			}
+			continue
+		}
+		if predicted.ArrivalTime().Equal(arrivalTime) && predicted.DepartureTime().Equal(departureTime) {
+			continue
+		}
+		stopVisit.Schedules.SetSchedule(model.STOP_VISIT_SCHEDULE_PREDICTED, departureTime, arrivalTime)
+		modified = append(modified, i)
+	}
+
+	return
+}
+
+// Returns the delay of the collected actual or expected times
+func (predictor *DelayPredictor) observedDelay(stopVisit *model.StopVisit) (time.Duration, bool) {
+	aimed := stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_AIMED)
+	for _, kind := range []model.StopVisitScheduleType{model.STOP_VISIT_SCHEDULE_ACTUAL, model.STOP_VISIT_SCHEDULE_EXPECTED} {
+		schedule := stopVisit.Schedules.Schedule(kind)
+		if !schedule.DepartureTime().IsZero() && !aimed.DepartureTime().IsZero() {
+			return schedule.DepartureTime().Sub(aimed.DepartureTime()), true
+		}
+		if !schedule.ArrivalTime().IsZero() && !aimed.ArrivalTime().IsZero() {
+			return schedule.ArrivalTime().Sub(aimed.ArrivalTime()), true
+		}
+	}
+	return 0, false
+}
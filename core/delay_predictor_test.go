@@ -0,0 +1,162 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/model"
+)
+
+func newPredictionStopVisits(referential *Referential, aimedTime time.Time, count int) model.VehicleJourneyId {
+	vehicleJourney := referential.Model().VehicleJourneys().New()
+	vehicleJourney.Save()
+
+	for i := 0; i < count; i++ {
+		stopVisit := referential.Model().StopVisits().New()
+		stopVisit.VehicleJourneyId = vehicleJourney.Id()
+		stopVisit.PassageOrder = count - i
+		arrivalTime := aimedTime.Add(time.Duration(count-i) * 10 * time.Minute)
+		stopVisit.Schedules.SetSchedule(model.STOP_VISIT_SCHEDULE_AIMED, arrivalTime.Add(2*time.Minute), arrivalTime)
+		stopVisit.Save()
+	}
+
+	return vehicleJourney.Id()
+}
+
+func predictionStopVisit(referential *Referential, vehicleJourneyId model.VehicleJourneyId, passageOrder int) *model.StopVisit {
+	tx := referential.NewTransaction()
+	defer tx.Close()
+
+	stopVisits := tx.Model().StopVisits().FindByVehicleJourneyId(vehicleJourneyId)
+	for i := range stopVisits {
+		if stopVisits[i].PassageOrder == passageOrder {
+			return &stopVisits[i]
+		}
+	}
+	return nil
+}
+
+func Test_DelayPredictor_Predict(t *testing.T) {
+	aimedTime := time.Date(2017, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	var tests = []struct {
+		settings          map[string]string
+		expectedArrival   []time.Duration
+		expectedDeparture []time.Duration
+	}{
+		{
+			settings:          map[string]string{},
+			expectedArrival:   []time.Duration{5 * time.Minute, 5 * time.Minute},
+			expectedDeparture: []time.Duration{5 * time.Minute, 5 * time.Minute},
+		},
+		{
+			settings:          map[string]string{REFERENTIAL_SETTING_PREDICTION_DELAY_DECAY: "0.5"},
+			expectedArrival:   []time.Duration{150 * time.Second, 75 * time.Second},
+			expectedDeparture: []time.Duration{150 * time.Second, 75 * time.Second},
+		},
+		{
+			settings:          map[string]string{REFERENTIAL_SETTING_PREDICTION_MINIMUM_DWELL_TIME: "30s"},
+			expectedArrival:   []time.Duration{5 * time.Minute, 210 * time.Second},
+			expectedDeparture: []time.Duration{210 * time.Second, 120 * time.Second},
+		},
+	}
+
+	for i, tt := range tests {
+		referential := NewMemoryReferentials().New("test")
+		for key, value := range tt.settings {
+			referential.Settings[key] = value
+		}
+		vehicleJourneyId := newPredictionStopVisits(referential, aimedTime, 3)
+
+		observed := predictionStopVisit(referential, vehicleJourneyId, 1)
+		aimed := observed.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_AIMED)
+		observed.Schedules.SetDepartureTime(model.STOP_VISIT_SCHEDULE_EXPECTED, aimed.DepartureTime().Add(5*time.Minute))
+		observed.Save()
+
+		tx := referential.NewTransaction()
+		stopVisits := tx.Model().StopVisits().FindByVehicleJourneyId(vehicleJourneyId)
+		modified := NewDelayPredictor(referential).Predict(stopVisits)
+		for _, j := range modified {
+			tx.Model().StopVisits().Save(&stopVisits[j])
+		}
+		tx.Commit()
+		tx.Close()
+
+		if len(modified) != 2 {
+			t.Errorf("Test %d: Wrong number of modified StopVisits:\n got: %v\n want: 2", i, len(modified))
+		}
+		if predicted := predictionStopVisit(referential, vehicleJourneyId, 1).Schedules.Schedule(model.STOP_VISIT_SCHEDULE_PREDICTED); !predicted.ArrivalTime().IsZero() {
+			t.Errorf("Test %d: Observed StopVisit shouldn't have a predicted schedule", i)
+		}
+
+		for j := range tt.expectedArrival {
+			stopVisit := predictionStopVisit(referential, vehicleJourneyId, j+2)
+			aimed := stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_AIMED)
+			predicted := stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_PREDICTED)
+
+			if delay := predicted.ArrivalTime().Sub(aimed.ArrivalTime()); delay != tt.expectedArrival[j] {
+				t.Errorf("Test %d: Wrong predicted arrival delay for StopVisit %d:\n got: %v\n want: %v", i, j+2, delay, tt.expectedArrival[j])
+			}
+			if delay := predicted.DepartureTime().Sub(aimed.DepartureTime()); delay != tt.expectedDeparture[j] {
+				t.Errorf("Test %d: Wrong predicted departure delay for StopVisit %d:\n got: %v\n want: %v", i, j+2, delay, tt.expectedDeparture[j])
+			}
+		}
+	}
+}
+
+func Test_DelayPredictor_Predict_ObservedStopVisit(t *testing.T) {
+	referential := NewMemoryReferentials().New("test")
+	aimedTime := time.Date(2017, time.January, 1, 12, 0, 0, 0, time.UTC)
+	vehicleJourneyId := newPredictionStopVisits(referential, aimedTime, 2)
+
+	stopVisit := predictionStopVisit(referential, vehicleJourneyId, 1)
+	stopVisit.Schedules.SetArrivalTime(model.STOP_VISIT_SCHEDULE_EXPECTED, stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_AIMED).ArrivalTime().Add(time.Minute))
+	stopVisit.Save()
+
+	// Previously predicted StopVisit which is now collected
+	stopVisit = predictionStopVisit(referential, vehicleJourneyId, 2)
+	aimed := stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_AIMED)
+	stopVisit.Schedules.SetSchedule(model.STOP_VISIT_SCHEDULE_PREDICTED, aimed.DepartureTime().Add(time.Minute), aimed.ArrivalTime().Add(time.Minute))
+	stopVisit.Schedules.SetArrivalTime(model.STOP_VISIT_SCHEDULE_EXPECTED, aimed.ArrivalTime().Add(3*time.Minute))
+	stopVisit.Save()
+
+	tx := referential.NewTransaction()
+	defer tx.Close()
+	stopVisits := tx.Model().StopVisits().FindByVehicleJourneyId(vehicleJourneyId)
+	if modified := NewDelayPredictor(referential).Predict(stopVisits); len(modified) != 1 {
+		t.Fatalf("Wrong number of modified StopVisits:\n got: %v\n want: 1", len(modified))
+	}
+
+	for i := range stopVisits {
+		if predicted := stopVisits[i].Schedules.Schedule(model.STOP_VISIT_SCHEDULE_PREDICTED); !predicted.ArrivalTime().IsZero() || !predicted.DepartureTime().IsZero() {
+			t.Errorf("StopVisit %d shouldn't have a predicted schedule", stopVisits[i].PassageOrder)
+		}
+	}
+	if arrivalTime := stopVisits[0].ExpectedOrPredictedArrivalTime(); arrivalTime.IsZero() {
+		t.Errorf("ExpectedOrPredictedArrivalTime should return the expected arrival time")
+	}
+}
+
+func Test_ModelGuardian_PredictDelays(t *testing.T) {
+	referential := NewMemoryReferentials().New("test")
+	aimedTime := time.Date(2017, time.January, 1, 12, 0, 0, 0, time.UTC)
+	vehicleJourneyId := newPredictionStopVisits(referential, aimedTime, 2)
+
+	stopVisit := predictionStopVisit(referential, vehicleJourneyId, 1)
+	stopVisit.Schedules.SetArrivalTime(model.STOP_VISIT_SCHEDULE_ACTUAL, stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_AIMED).ArrivalTime().Add(time.Minute))
+	stopVisit.Save()
+
+	referential.ModelGuardian().predictDelays()
+	if predicted := predictionStopVisit(referential, vehicleJourneyId, 2).Schedules.Schedule(model.STOP_VISIT_SCHEDULE_PREDICTED); !predicted.ArrivalTime().IsZero() {
+		t.Errorf("Delays shouldn't be predicted when prediction isn't enabled")
+	}
+
+	referential.Settings[REFERENTIAL_SETTING_PREDICTION_ENABLED] = "true"
+	referential.ModelGuardian().predictDelays()
+
+	stopVisit = predictionStopVisit(referential, vehicleJourneyId, 2)
+	aimed := stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_AIMED)
+	if arrivalTime := stopVisit.ExpectedOrPredictedArrivalTime(); !arrivalTime.Equal(aimed.ArrivalTime().Add(time.Minute)) {
+		t.Errorf("Wrong predicted arrival time:\n got: %v\n want: %v", arrivalTime, aimed.ArrivalTime().Add(time.Minute))
+	}
+}
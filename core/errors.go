@@ -16,6 +16,7 @@ const (
 	ERROR_SLUG_FORMAT = "Invalid format: only lowercase alphanumeric characters and _"
 	ERROR_ZERO        = "Can't be zero"
 	ERROR_UNIQUE      = "Is already in use"
+	ERROR_INVALID     = "Is invalid"
 )
 
 func (errors Errors) Get(attribute string) []string {
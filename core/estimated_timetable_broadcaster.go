@@ -135,6 +135,7 @@ func (ett *ETTBroadcaster) prepareSIRIEstimatedTimeTable() {
 	defer tx.Close()
 
 	currentTime := ett.Clock().Now()
+	merger := ett.connector.merger(tx)
 
 	for subId, stopVisits := range events {
 		sub, ok := ett.connector.Partner().Subscriptions().Find(subId)
@@ -165,13 +166,23 @@ func (ett *ETTBroadcaster) prepareSIRIEstimatedTimeTable() {
 			}
 
 			// Find the StopVisit
-			stopVisit, ok := tx.Model().StopVisits().Find(stopVisitId)
+			modelStopVisit, ok := tx.Model().StopVisits().Find(stopVisitId)
 			if !ok {
 				continue
 			}
+			stopVisit := &modelStopVisit
+			// With merging, the StopVisit it is merged into is sent instead
+			stopPointRefId := stopVisit.StopAreaId
+			if merger != nil {
+				stopVisit = merger.MergedStopVisit(stopVisit)
+				if _, ok := processedStopVisits[stopVisit.Id()]; ok {
+					continue
+				}
+				stopPointRefId = merger.StopAreaWithObjectIDKind(stopVisit.StopAreaId, ett.connector.Partner().RemoteObjectIDKind(SIRI_ESTIMATED_TIMETABLE_SUBSCRIPTION_BROADCASTER))
+			}
 
 			// Handle StopPointRef
-			stopArea, stopAreaId, ok := ett.connector.stopPointRef(stopVisit.StopAreaId, tx)
+			stopArea, stopAreaId, ok := ett.connector.stopPointRef(stopPointRefId, tx)
 			if !ok {
 				logger.Log.Printf("Ignore StopVisit %v without StopArea or with StopArea without correct ObjectID", stopVisit.Id())
 				continue
@@ -184,7 +195,11 @@ func (ett *ETTBroadcaster) prepareSIRIEstimatedTimeTable() {
 			}
 
 			// Find the Line
-			line, ok := tx.Model().Lines().Find(vehicleJourney.LineId)
+			lineId := vehicleJourney.LineId
+			if merger != nil {
+				lineId = merger.LineWithObjectIDKind(lineId, ett.connector.Partner().RemoteObjectIDKind(SIRI_ESTIMATED_TIMETABLE_SUBSCRIPTION_BROADCASTER))
+			}
+			line, ok := tx.Model().Lines().Find(lineId)
 			if !ok {
 				continue
 			}
@@ -230,10 +245,12 @@ func (ett *ETTBroadcaster) prepareSIRIEstimatedTimeTable() {
 				estimatedVehicleJourney = &siri.SIRIEstimatedVehicleJourney{
 					LineRef:                lineObjectId.Value(),
 					DatedVehicleJourneyRef: datedVehicleJourneyRef,
+					ExtraJourney:           vehicleJourney.ExtraJourney,
+					Cancellation:           vehicleJourney.Cancellation,
 					Attributes:             make(map[string]string),
 					References:             make(map[string]string),
 				}
-				estimatedVehicleJourney.References = ett.connector.getEstimatedVehicleJourneyReferences(&vehicleJourney, &stopVisit, tx)
+				estimatedVehicleJourney.References = ett.connector.getEstimatedVehicleJourneyReferences(&vehicleJourney, stopVisit, tx)
 				estimatedVehicleJourney.Attributes = vehicleJourney.Attributes
 
 				journeyFrame.EstimatedVehicleJourneys = append(journeyFrame.EstimatedVehicleJourneys, estimatedVehicleJourney)
@@ -245,9 +262,9 @@ func (ett *ETTBroadcaster) prepareSIRIEstimatedTimeTable() {
 				ArrivalStatus:         string(stopVisit.ArrivalStatus),
 				DepartureStatus:       string(stopVisit.DepartureStatus),
 				AimedArrivalTime:      stopVisit.Schedules.Schedule("aimed").ArrivalTime(),
-				ExpectedArrivalTime:   stopVisit.Schedules.Schedule("expected").ArrivalTime(),
+				ExpectedArrivalTime:   stopVisit.ExpectedOrPredictedArrivalTime(),
 				AimedDepartureTime:    stopVisit.Schedules.Schedule("aimed").DepartureTime(),
-				ExpectedDepartureTime: stopVisit.Schedules.Schedule("expected").DepartureTime(),
+				ExpectedDepartureTime: stopVisit.ExpectedOrPredictedDepartureTime(),
 				Order:                 stopVisit.PassageOrder,
 				StopPointRef:          stopAreaId,
 				StopPointName:         stopArea.Name,
@@ -258,15 +275,16 @@ func (ett *ETTBroadcaster) prepareSIRIEstimatedTimeTable() {
 			estimatedVehicleJourney.EstimatedCalls = append(estimatedVehicleJourney.EstimatedCalls, estimatedCall)
 
 			processedStopVisits[stopVisitId] = struct{}{}
+			processedStopVisits[stopVisit.Id()] = struct{}{}
 
 			lastStateInterface, ok := resource.LastState(string(stopVisit.Id()))
 			if !ok {
 				ettlc := &estimatedTimeTableLastChange{}
-				ettlc.InitState(&stopVisit, sub)
+				ettlc.InitState(stopVisit, sub)
 				resource.SetLastState(string(stopVisit.Id()), ettlc)
 			} else {
 				lastState := lastStateInterface.(*estimatedTimeTableLastChange)
-				lastState.UpdateState(&stopVisit)
+				lastState.UpdateState(stopVisit)
 			}
 		}
 		ett.sendDelivery(delivery)
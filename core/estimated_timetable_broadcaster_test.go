@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -155,3 +156,63 @@ func Test_EstimatedTimeTableBroadcaster_Receive_Notify(t *testing.T) {
 		t.Errorf("Got diffrent xml than expected, got: %v\nwant :%v", string(response), expected)
 	}
 }
+
+func Test_EstimatedTimeTableBroadcaster_MergeStopVisits(t *testing.T) {
+	fakeClock := clock.NewFakeClock()
+	clock.SetDefaultClock(fakeClock)
+
+	response := []byte{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, _ = ioutil.ReadAll(r.Body)
+		w.Header().Add("Content-Type", "text/xml")
+	}))
+	defer ts.Close()
+
+	referential := NewMemoryReferentials().New("referential")
+	referential.SetClock(fakeClock)
+
+	partner := referential.Partners().New("subscriber")
+	partner.SetSetting("remote_objectid_kind", "internal")
+	partner.SetSetting("remote_credential", "external")
+	partner.SetSetting("local_credential", "local")
+	partner.SetSetting("remote_url", ts.URL)
+	partner.SetSetting(BROADCAST_MERGE_STOP_VISITS, "true")
+	partner.ConnectorTypes = []string{SIRI_ESTIMATED_TIMETABLE_SUBSCRIPTION_BROADCASTER}
+	partner.RefreshConnectors()
+	referential.Partners().Save(partner)
+
+	c, _ := partner.Connector(SIRI_ESTIMATED_TIMETABLE_SUBSCRIPTION_BROADCASTER)
+	connector := c.(*SIRIEstimatedTimeTableSubscriptionBroadcaster)
+	connector.SetClock(fakeClock)
+
+	aimedTime := fakeClock.Now().Add(10 * time.Minute)
+	high, low := mergerTest_PrepareModel(referential, aimedTime)
+
+	objectid := model.NewObjectID("internal", "highLine")
+	subscription := partner.Subscriptions().New("EstimatedTimeTableBroadcast")
+	subscription.SetExternalId("externalId")
+	subscription.CreateAddNewResource(model.Reference{ObjectId: &objectid, Type: "Line"})
+	subscription.Save()
+
+	// The StopVisit of "low" is on a Line without "internal" ObjectID
+	connector.HandleBroadcastEvent(&model.StopMonitoringBroadcastEvent{ModelId: string(low.Id()), ModelType: "StopVisit"})
+	if l := len(connector.toBroadcast[subscription.Id()]); l != 1 {
+		t.Fatalf("StopVisit of the Line group should be broadcasted, got %v StopVisits", l)
+	}
+	connector.HandleBroadcastEvent(&model.StopMonitoringBroadcastEvent{ModelId: string(high.Id()), ModelType: "StopVisit"})
+
+	NewFakeEstimatedTimeTableBroadcaster(connector).Start()
+
+	if n := strings.Count(string(response), "<siri:EstimatedCall>"); n != 1 {
+		t.Fatalf("Merged StopVisits should be sent once, got %v EstimatedCalls:\n%s", n, response)
+	}
+	for _, expected := range []string{
+		"<siri:DatedVehicleJourneyRef>highVehicleJourney</siri:DatedVehicleJourneyRef>",
+		"<siri:StopPointRef>highStopArea</siri:StopPointRef>",
+		"<siri:ExpectedDepartureTime>" + aimedTime.Add(2*time.Minute).Format("2006-01-02T15:04:05.000Z07:00") + "</siri:ExpectedDepartureTime>",
+	} {
+		if !strings.Contains(string(response), expected) {
+			t.Errorf("Notification should contain %v:\n%s", expected, response)
+		}
+	}
+}
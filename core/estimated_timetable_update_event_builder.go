@@ -0,0 +1,174 @@
+package core
+
+import (
+	"fmt"
+
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+)
+
+type EstimatedTimetableUpdateEventBuilder struct {
+	partner            *Partner
+	remoteObjectidKind string
+
+	estimatedTimetableUpdateEvents *EstimatedTimetableUpdateEvents
+}
+
+type EstimatedTimetableUpdateEvents struct {
+	StopAreas          map[string]*model.StopAreaUpdateEvent
+	Lines              map[string]*model.LineUpdateEvent
+	VehicleJourneys    map[string]*model.VehicleJourneyUpdateEvent
+	StopVisits         map[string]*model.StopVisitUpdateEvent
+	LineRefs           map[string]struct{}
+	VehicleJourneyRefs map[string]struct{}
+}
+
+func NewEstimatedTimetableUpdateEventBuilder(partner *Partner) EstimatedTimetableUpdateEventBuilder {
+	return EstimatedTimetableUpdateEventBuilder{
+		partner:                        partner,
+		remoteObjectidKind:             partner.Setting(REMOTE_OBJECTID_KIND),
+		estimatedTimetableUpdateEvents: newEstimatedTimetableUpdateEvents(),
+	}
+}
+
+func newEstimatedTimetableUpdateEvents() *EstimatedTimetableUpdateEvents {
+	return &EstimatedTimetableUpdateEvents{
+		StopAreas:          make(map[string]*model.StopAreaUpdateEvent),
+		Lines:              make(map[string]*model.LineUpdateEvent),
+		VehicleJourneys:    make(map[string]*model.VehicleJourneyUpdateEvent),
+		StopVisits:         make(map[string]*model.StopVisitUpdateEvent),
+		LineRefs:           make(map[string]struct{}),
+		VehicleJourneyRefs: make(map[string]struct{}),
+	}
+}
+
+func (builder *EstimatedTimetableUpdateEventBuilder) buildUpdateEvents(xmlVehicleJourney *siri.XMLEstimatedVehicleJourney) {
+	// EstimatedTimetables are only collected on the Lines defined by collect.include_lines
+	if !builder.partner.CanCollectLine(xmlVehicleJourney.LineRef()) {
+		return
+	}
+
+	if xmlVehicleJourney.DatedVehicleJourneyRef() == "" {
+		return
+	}
+
+	origin := string(builder.partner.Slug())
+
+	// Lines
+	lineObjectId := model.NewObjectID(builder.remoteObjectidKind, xmlVehicleJourney.LineRef())
+
+	_, ok := builder.estimatedTimetableUpdateEvents.Lines[xmlVehicleJourney.LineRef()]
+	if !ok {
+		// CollectedAlways is false by default
+		lineEvent := &model.LineUpdateEvent{
+			Origin:   origin,
+			ObjectId: lineObjectId,
+			Name:     xmlVehicleJourney.PublishedLineName(),
+		}
+
+		builder.estimatedTimetableUpdateEvents.Lines[xmlVehicleJourney.LineRef()] = lineEvent
+		builder.estimatedTimetableUpdateEvents.LineRefs[xmlVehicleJourney.LineRef()] = struct{}{}
+	}
+
+	// VehicleJourneys
+	vjObjectId := model.NewObjectID(builder.remoteObjectidKind, xmlVehicleJourney.DatedVehicleJourneyRef())
+
+	_, ok = builder.estimatedTimetableUpdateEvents.VehicleJourneys[xmlVehicleJourney.DatedVehicleJourneyRef()]
+	if !ok {
+		vjEvent := &model.VehicleJourneyUpdateEvent{
+			Origin:          origin,
+			ObjectId:        vjObjectId,
+			LineObjectId:    lineObjectId,
+			OriginRef:       xmlVehicleJourney.OriginRef(),
+			OriginName:      xmlVehicleJourney.OriginName(),
+			DestinationRef:  xmlVehicleJourney.DestinationRef(),
+			DestinationName: xmlVehicleJourney.DestinationName(),
+			Monitored:       xmlVehicleJourney.Monitored(),
+			FlagsDefined:    true,
+			Cancellation:    xmlVehicleJourney.Cancellation(),
+			ExtraJourney:    xmlVehicleJourney.ExtraJourney(),
+
+			ObjectidKind: builder.remoteObjectidKind,
+		}
+
+		builder.estimatedTimetableUpdateEvents.VehicleJourneys[xmlVehicleJourney.DatedVehicleJourneyRef()] = vjEvent
+		builder.estimatedTimetableUpdateEvents.VehicleJourneyRefs[xmlVehicleJourney.DatedVehicleJourneyRef()] = struct{}{}
+	}
+
+	// StopVisits
+	for _, call := range xmlVehicleJourney.RecordedCalls() {
+		builder.buildCallUpdateEvents(xmlVehicleJourney, call, vjObjectId)
+	}
+	for _, call := range xmlVehicleJourney.EstimatedCalls() {
+		builder.buildCallUpdateEvents(xmlVehicleJourney, call, vjObjectId)
+	}
+}
+
+func (builder *EstimatedTimetableUpdateEventBuilder) buildCallUpdateEvents(xmlVehicleJourney *siri.XMLEstimatedVehicleJourney, call *siri.XMLCall, vjObjectId model.ObjectID) {
+	if call.StopPointRef() == "" {
+		return
+	}
+
+	origin := string(builder.partner.Slug())
+
+	// StopAreas
+	stopAreaObjectId := model.NewObjectID(builder.remoteObjectidKind, call.StopPointRef())
+
+	_, ok := builder.estimatedTimetableUpdateEvents.StopAreas[call.StopPointRef()]
+	if !ok {
+		// CollectedAlways is false by default
+		event := &model.StopAreaUpdateEvent{
+			Origin:   origin,
+			ObjectId: stopAreaObjectId,
+			Name:     call.StopPointName(),
+		}
+
+		builder.estimatedTimetableUpdateEvents.StopAreas[call.StopPointRef()] = event
+	}
+
+	// EstimatedCalls don't have ItemIdentifier, the StopVisit is identified by its VehicleJourney and its Order
+	stopVisitId := fmt.Sprintf("%v-%v", xmlVehicleJourney.DatedVehicleJourneyRef(), call.Order())
+
+	svEvent := &model.StopVisitUpdateEvent{
+		Origin:                 origin,
+		ObjectId:               model.NewObjectID(builder.remoteObjectidKind, stopVisitId),
+		StopAreaObjectId:       stopAreaObjectId,
+		VehicleJourneyObjectId: vjObjectId,
+		DataFrameRef:           xmlVehicleJourney.DataFrameRef(),
+		PassageOrder:           call.Order(),
+		Monitored:              xmlVehicleJourney.Monitored(),
+		ArrivalStatus:          model.StopVisitArrivalStatus(call.ArrivalStatus()),
+		DepartureStatus:        model.StopVisitDepartureStatus(call.DepartureStatus()),
+		Schedules:              model.NewStopVisitSchedules(),
+
+		ObjectidKind: builder.remoteObjectidKind,
+	}
+
+	if !call.AimedDepartureTime().IsZero() || !call.AimedArrivalTime().IsZero() {
+		svEvent.Schedules.SetSchedule(model.STOP_VISIT_SCHEDULE_AIMED, call.AimedDepartureTime(), call.AimedArrivalTime())
+	}
+	if !call.ExpectedDepartureTime().IsZero() || !call.ExpectedArrivalTime().IsZero() {
+		svEvent.Schedules.SetSchedule(model.STOP_VISIT_SCHEDULE_EXPECTED, call.ExpectedDepartureTime(), call.ExpectedArrivalTime())
+	}
+	if !call.ActualDepartureTime().IsZero() || !call.ActualArrivalTime().IsZero() {
+		svEvent.Schedules.SetSchedule(model.STOP_VISIT_SCHEDULE_ACTUAL, call.ActualDepartureTime(), call.ActualArrivalTime())
+	}
+
+	// A cancelled VehicleJourney cancels all its StopVisits
+	if xmlVehicleJourney.Cancellation() || call.Cancellation() {
+		svEvent.ArrivalStatus = model.STOP_VISIT_ARRIVAL_CANCELLED
+		svEvent.DepartureStatus = model.STOP_VISIT_DEPARTURE_CANCELLED
+	}
+
+	builder.estimatedTimetableUpdateEvents.StopVisits[stopVisitId] = svEvent
+}
+
+func (builder *EstimatedTimetableUpdateEventBuilder) SetUpdateEvents(vehicleJourneys []*siri.XMLEstimatedVehicleJourney) {
+	for _, xmlVehicleJourney := range vehicleJourneys {
+		builder.buildUpdateEvents(xmlVehicleJourney)
+	}
+}
+
+func (builder *EstimatedTimetableUpdateEventBuilder) UpdateEvents() EstimatedTimetableUpdateEvents {
+	return *builder.estimatedTimetableUpdateEvents
+}
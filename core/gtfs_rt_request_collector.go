@@ -0,0 +1,362 @@
+package core
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+	"bitbucket.org/enroute-mobi/ara/version"
+	"github.com/MobilityData/gtfs-realtime-bindings/golang/gtfs"
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	GTFS_RT_COLLECT_DEFAULT_TTL = 30 * time.Second
+	GTFS_RT_COLLECT_MIN_TTL     = 5 * time.Second
+)
+
+type GtfsRequestCollector struct {
+	clock.ClockConsumer
+	uuid.UUIDConsumer
+
+	BaseConnector
+
+	subscriber UpdateSubscriber
+	httpClient *http.Client
+	stop       chan struct{}
+}
+
+type GtfsRequestCollectorFactory struct{}
+
+func (factory *GtfsRequestCollectorFactory) CreateConnector(partner *Partner) Connector {
+	return NewGtfsRequestCollector(partner)
+}
+
+func (factory *GtfsRequestCollectorFactory) Validate(apiPartner *APIPartner) {
+	apiPartner.ValidatePresenceOfSetting(REMOTE_OBJECTID_KIND)
+	apiPartner.ValidatePresenceOfSetting(REMOTE_URL)
+}
+
+func NewGtfsRequestCollector(partner *Partner) *GtfsRequestCollector {
+	connector := &GtfsRequestCollector{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	connector.partner = partner
+	manager := partner.Referential().CollectManager()
+	connector.subscriber = manager.BroadcastUpdateEvent
+
+	return connector
+}
+
+func (connector *GtfsRequestCollector) SetSubscriber(subscriber UpdateSubscriber) {
+	connector.subscriber = subscriber
+}
+
+func (connector *GtfsRequestCollector) broadcastUpdateEvent(event model.UpdateEvent) {
+	if connector.subscriber != nil {
+		connector.subscriber(event)
+	}
+}
+
+func (connector *GtfsRequestCollector) Start() {
+	logger.Log.Debugf("Start GtfsRequestCollector for partner %v", connector.partner.Slug())
+
+	connector.stop = make(chan struct{})
+	go connector.run()
+}
+
+func (connector *GtfsRequestCollector) Stop() {
+	if connector.stop != nil {
+		close(connector.stop)
+	}
+}
+
+// Requests the GTFS-RT feed when the connector starts and then after each TTL
+func (connector *GtfsRequestCollector) run() {
+	connector.RequestGtfs()
+	c := connector.Clock().After(connector.partner.GtfsCollectTTL())
+
+	for {
+		select {
+		case <-connector.stop:
+			logger.Log.Debugf("Stop GtfsRequestCollector for partner %v", connector.partner.Slug())
+			return
+		case <-c:
+			connector.RequestGtfs()
+			c = connector.Clock().After(connector.partner.GtfsCollectTTL())
+		}
+	}
+}
+
+func (connector *GtfsRequestCollector) RequestGtfs() {
+	logStashEvent := connector.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+
+	message := connector.newBQEvent()
+	defer audit.CurrentBigQuery(string(connector.Partner().Referential().Slug())).WriteEvent(message)
+
+	startTime := connector.Clock().Now()
+
+	feed, size, err := connector.fetchFeed()
+	logStashEvent["responseTime"] = connector.Clock().Since(startTime).String()
+	message.ProcessingTime = connector.Clock().Since(startTime).Seconds()
+	message.ResponseSize = int64(size)
+	if err != nil {
+		e := fmt.Sprintf("Error during Gtfs request: %v", err)
+		logStashEvent["status"] = "false"
+		logStashEvent["errorDescription"] = e
+
+		message.Status = "Error"
+		message.ErrorDetails = e
+		return
+	}
+
+	logStashEvent["status"] = "true"
+	logStashEvent["entities"] = strconv.Itoa(len(feed.GetEntity()))
+
+	connector.handleFeed(feed, message)
+}
+
+func (connector *GtfsRequestCollector) fetchFeed() (*gtfs.FeedMessage, int, error) {
+	httpRequest, err := http.NewRequest(http.MethodGet, connector.partner.Setting(REMOTE_URL), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	httpRequest.Header.Set("Accept-Encoding", "gzip")
+	httpRequest.Header.Set("User-Agent", version.ApplicationName())
+	if credential := connector.partner.Setting(REMOTE_CREDENTIAL); credential != "" {
+		httpRequest.Header.Set("Authorization", fmt.Sprintf("Token token=%v", credential))
+	}
+
+	response, err := connector.httpClient.Do(httpRequest)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		io.Copy(ioutil.Discard, response.Body)
+		response.Body.Close()
+	}()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("HTTP status %v", response.StatusCode)
+	}
+
+	var reader io.Reader = response.Body
+	if response.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(response.Body)
+		if err != nil {
+			return nil, 0, err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	feed := &gtfs.FeedMessage{}
+	if err = proto.Unmarshal(content, feed); err != nil {
+		return nil, len(content), err
+	}
+	return feed, len(content), nil
+}
+
+func (connector *GtfsRequestCollector) handleFeed(feed *gtfs.FeedMessage, message *audit.BigQueryMessage) {
+	partner := string(connector.partner.Slug())
+	objectidKind := connector.partner.RemoteObjectIDKind(GTFS_RT_REQUEST_COLLECTOR)
+	vehicleObjectidKind := connector.partner.VehicleRemoteObjectIDKind(GTFS_RT_REQUEST_COLLECTOR)
+
+	feedTimestamp := time.Unix(int64(feed.GetHeader().GetTimestamp()), 0)
+
+	lines := make(map[string]struct{})
+	stopAreas := make(map[string]struct{})
+	vehicleJourneys := make(map[string]struct{})
+	vehicles := make(map[string]struct{})
+
+	tx := connector.partner.Referential().NewTransaction()
+	defer tx.Close()
+
+	for _, entity := range feed.GetEntity() {
+		if tripUpdate := entity.GetTripUpdate(); tripUpdate != nil {
+			trip := tripUpdate.GetTrip()
+			if trip.GetTripId() == "" {
+				continue
+			}
+
+			connector.handleTrip(trip, true, lines, vehicleJourneys)
+
+			recordedAt := feedTimestamp
+			if tripUpdate.GetTimestamp() != 0 {
+				recordedAt = time.Unix(int64(tripUpdate.GetTimestamp()), 0)
+			}
+
+			for i, stopTimeUpdate := range tripUpdate.GetStopTimeUpdate() {
+				stopId := stopTimeUpdate.GetStopId()
+				if stopId == "" {
+					continue
+				}
+
+				if _, ok := stopAreas[stopId]; !ok {
+					stopAreaEvent := model.NewStopAreaUpdateEvent()
+					stopAreaEvent.Origin = partner
+					stopAreaEvent.ObjectId = model.NewObjectID(objectidKind, stopId)
+					stopAreaEvent.Name = stopId
+
+					stopAreas[stopId] = struct{}{}
+					connector.broadcastUpdateEvent(stopAreaEvent)
+				}
+
+				// The stop_sequence is optional, the StopTimeUpdates are ordered
+				stopVisitCode, passageOrder := stopId, i+1
+				if stopTimeUpdate.StopSequence != nil {
+					stopVisitCode, passageOrder = strconv.Itoa(int(stopTimeUpdate.GetStopSequence())), int(stopTimeUpdate.GetStopSequence())
+				}
+
+				stopVisitEvent := model.NewStopVisitUpdateEvent()
+				stopVisitEvent.Origin = partner
+				stopVisitEvent.ObjectId = model.NewObjectID(objectidKind, fmt.Sprintf("%v-%v", trip.GetTripId(), stopVisitCode))
+				stopVisitEvent.StopAreaObjectId = model.NewObjectID(objectidKind, stopId)
+				stopVisitEvent.VehicleJourneyObjectId = model.NewObjectID(objectidKind, trip.GetTripId())
+				stopVisitEvent.PassageOrder = passageOrder
+				stopVisitEvent.RecordedAt = recordedAt
+
+				aimed := &model.StopVisitSchedule{}
+				if stopVisit, ok := tx.Model().StopVisits().FindByObjectId(stopVisitEvent.ObjectId); ok {
+					aimed = stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_AIMED)
+				}
+				arrival := gtfsExpectedTime(stopTimeUpdate.GetArrival(), aimed.ArrivalTime())
+				departure := gtfsExpectedTime(stopTimeUpdate.GetDeparture(), aimed.DepartureTime())
+				stopVisitEvent.Schedules.SetSchedule(model.STOP_VISIT_SCHEDULE_EXPECTED, departure, arrival)
+				stopVisitEvent.Monitored = !arrival.IsZero() || !departure.IsZero()
+
+				if stopTimeUpdate.GetScheduleRelationship() == gtfs.TripUpdate_StopTimeUpdate_SKIPPED {
+					stopVisitEvent.DepartureStatus = model.STOP_VISIT_DEPARTURE_CANCELLED
+					stopVisitEvent.ArrivalStatus = model.STOP_VISIT_ARRIVAL_CANCELLED
+				}
+
+				connector.broadcastUpdateEvent(stopVisitEvent)
+			}
+		}
+
+		if vehiclePosition := entity.GetVehicle(); vehiclePosition != nil {
+			vehicleId := vehiclePosition.GetVehicle().GetId()
+			if vehicleId == "" {
+				continue
+			}
+			trip := vehiclePosition.GetTrip()
+			if trip.GetTripId() != "" {
+				// The ScheduleRelationship is often omitted in vehicle positions
+				connector.handleTrip(trip, trip.ScheduleRelationship != nil, lines, vehicleJourneys)
+			}
+
+			vehicleEvent := model.NewVehicleUpdateEvent()
+			vehicleEvent.Origin = partner
+			vehicleEvent.ObjectId = model.NewObjectID(vehicleObjectidKind, vehicleId)
+			if trip.GetTripId() != "" {
+				vehicleEvent.VehicleJourneyObjectId = model.NewObjectID(objectidKind, trip.GetTripId())
+			}
+			vehicleEvent.Longitude = float64(vehiclePosition.GetPosition().GetLongitude())
+			vehicleEvent.Latitude = float64(vehiclePosition.GetPosition().GetLatitude())
+			vehicleEvent.Bearing = float64(vehiclePosition.GetPosition().GetBearing())
+			vehicleEvent.RecordedAt = feedTimestamp
+			if vehiclePosition.GetTimestamp() != 0 {
+				vehicleEvent.RecordedAt = time.Unix(int64(vehiclePosition.GetTimestamp()), 0)
+			}
+
+			vehicles[vehicleId] = struct{}{}
+			connector.broadcastUpdateEvent(vehicleEvent)
+		}
+	}
+
+	message.Lines = refSlice(lines)
+	message.StopAreas = refSlice(stopAreas)
+	message.Vehicles = refSlice(vehicles)
+}
+
+// Returns the time of the StopTimeEvent or applies its delay to the aimed
+// time. Returns a zero time when the StopTimeEvent defines neither.
+func gtfsExpectedTime(event *gtfs.TripUpdate_StopTimeEvent, aimed time.Time) time.Time {
+	if event.GetTime() != 0 {
+		return time.Unix(event.GetTime(), 0)
+	}
+	if event != nil && event.Delay != nil && !aimed.IsZero() {
+		return aimed.Add(time.Duration(event.GetDelay()) * time.Second)
+	}
+	return time.Time{}
+}
+
+// Broadcast the Line and VehicleJourney events of a trip, only once by feed
+func (connector *GtfsRequestCollector) handleTrip(trip *gtfs.TripDescriptor, flagsDefined bool, lines, vehicleJourneys map[string]struct{}) {
+	partner := string(connector.partner.Slug())
+	objectidKind := connector.partner.RemoteObjectIDKind(GTFS_RT_REQUEST_COLLECTOR)
+
+	tripId := trip.GetTripId()
+	if _, ok := vehicleJourneys[tripId]; ok {
+		return
+	}
+	vehicleJourneys[tripId] = struct{}{}
+
+	routeId := trip.GetRouteId()
+	if _, ok := lines[routeId]; !ok && routeId != "" {
+		lineEvent := model.NewLineUpdateEvent()
+		lineEvent.Origin = partner
+		lineEvent.ObjectId = model.NewObjectID(objectidKind, routeId)
+		lineEvent.Name = routeId
+
+		lines[routeId] = struct{}{}
+		connector.broadcastUpdateEvent(lineEvent)
+	}
+
+	vehicleJourneyEvent := model.NewVehicleJourneyUpdateEvent()
+	vehicleJourneyEvent.Origin = partner
+	vehicleJourneyEvent.ObjectId = model.NewObjectID(objectidKind, tripId)
+	vehicleJourneyEvent.LineObjectId = model.NewObjectID(objectidKind, routeId)
+	vehicleJourneyEvent.Monitored = true
+	vehicleJourneyEvent.FlagsDefined = flagsDefined
+	switch trip.GetScheduleRelationship() {
+	case gtfs.TripDescriptor_CANCELED:
+		vehicleJourneyEvent.Cancellation = true
+	case gtfs.TripDescriptor_ADDED:
+		vehicleJourneyEvent.ExtraJourney = true
+	}
+	if trip.DirectionId != nil {
+		vehicleJourneyEvent.Direction = strconv.Itoa(int(trip.GetDirectionId()))
+	}
+
+	connector.broadcastUpdateEvent(vehicleJourneyEvent)
+}
+
+func refSlice(refs map[string]struct{}) []string {
+	slice := make([]string, 0, len(refs))
+	for ref := range refs {
+		slice = append(slice, ref)
+	}
+	return slice
+}
+
+func (connector *GtfsRequestCollector) newBQEvent() *audit.BigQueryMessage {
+	return &audit.BigQueryMessage{
+		Type:      "GtfsRequest",
+		Protocol:  "gtfs",
+		Direction: "sent",
+		Partner:   string(connector.partner.Slug()),
+		Status:    "OK",
+	}
+}
+
+func (connector *GtfsRequestCollector) newLogStashEvent() audit.LogStashEvent {
+	event := connector.partner.NewLogStashEvent()
+	event["connector"] = "GtfsRequestCollector"
+	return event
+}
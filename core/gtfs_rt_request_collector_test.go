@@ -0,0 +1,308 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"github.com/MobilityData/gtfs-realtime-bindings/golang/gtfs"
+	"github.com/golang/protobuf/proto"
+)
+
+func Test_GtfsRequestCollectorFactory_Validate(t *testing.T) {
+	partner := &Partner{
+		slug:           "partner",
+		ConnectorTypes: []string{GTFS_RT_REQUEST_COLLECTOR},
+		connectors:     make(map[string]Connector),
+		manager:        NewPartnerManager(nil),
+	}
+	partner.PartnerSettings = NewPartnerSettings(partner)
+	apiPartner := partner.Definition()
+	apiPartner.Validate()
+	if apiPartner.Errors.Empty() {
+		t.Errorf("apiPartner should have errors when remote_url and remote_objectid_kind aren't set, got: %v", apiPartner.Errors)
+	}
+
+	apiPartner.Settings = map[string]string{
+		"remote_url":           "remote_url",
+		"remote_objectid_kind": "remote_objectid_kind",
+	}
+	apiPartner.Validate()
+	if !apiPartner.Errors.Empty() {
+		t.Errorf("apiPartner shouldn't have any error when remote_url and remote_objectid_kind are set, got: %v", apiPartner.Errors)
+	}
+}
+
+func Test_GtfsRequestCollector_RequestGtfs(t *testing.T) {
+	audit.SetCurrentLogstash(audit.NewFakeLogStash())
+
+	version := "2.0"
+	timestamp := uint64(clock.FAKE_CLOCK_INITIAL_DATE.Unix())
+	tripId, routeId, stopId, vehicleId, otherVehicleId := "trip", "route", "stop", "vehicle", "otherVehicle"
+	vehicleTimestamp := timestamp - 30
+	stopSequence := uint32(3)
+	arrival := clock.FAKE_CLOCK_INITIAL_DATE.Add(5 * time.Minute).Unix()
+	lat, lon, bearing := float32(48.8), float32(2.3), float32(90)
+
+	feed := &gtfs.FeedMessage{
+		Header: &gtfs.FeedHeader{
+			GtfsRealtimeVersion: &version,
+			Timestamp:           &timestamp,
+		},
+		Entity: []*gtfs.FeedEntity{
+			{
+				Id: &tripId,
+				TripUpdate: &gtfs.TripUpdate{
+					Trip: &gtfs.TripDescriptor{TripId: &tripId, RouteId: &routeId},
+					StopTimeUpdate: []*gtfs.TripUpdate_StopTimeUpdate{
+						{
+							StopSequence: &stopSequence,
+							StopId:       &stopId,
+							Arrival:      &gtfs.TripUpdate_StopTimeEvent{Time: &arrival},
+						},
+					},
+				},
+			},
+			{
+				Id: &vehicleId,
+				Vehicle: &gtfs.VehiclePosition{
+					Trip:      &gtfs.TripDescriptor{TripId: &tripId, RouteId: &routeId},
+					Vehicle:   &gtfs.VehicleDescriptor{Id: &vehicleId},
+					Position:  &gtfs.Position{Latitude: &lat, Longitude: &lon, Bearing: &bearing},
+					Timestamp: &vehicleTimestamp,
+				},
+			},
+			{
+				Id: &otherVehicleId,
+				Vehicle: &gtfs.VehiclePosition{
+					Vehicle:  &gtfs.VehicleDescriptor{Id: &otherVehicleId},
+					Position: &gtfs.Position{Latitude: &lat, Longitude: &lon},
+				},
+			},
+		},
+	}
+	data, err := proto.Marshal(feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Token token=secret" {
+			t.Errorf("Wrong Authorization header: %v", r.Header.Get("Authorization"))
+		}
+		w.Write(data)
+	}))
+	defer ts.Close()
+
+	referentials := NewMemoryReferentials()
+	referential := referentials.New("referential")
+	partner := referential.Partners().New("partner")
+	partner.SetSettingsDefinition(map[string]string{
+		"remote_url":           ts.URL,
+		"remote_credential":    "secret",
+		"remote_objectid_kind": "test",
+	})
+	referential.Partners().Save(partner)
+
+	connector := NewGtfsRequestCollector(partner)
+	connector.SetClock(clock.NewFakeClock())
+	collectManager := NewTestCollectManager().(*TestCollectManager)
+	connector.SetSubscriber(collectManager.TestUpdateSubscriber)
+
+	connector.RequestGtfs()
+
+	var stopVisitEvents []*model.StopVisitUpdateEvent
+	var vehicleJourneyEvents []*model.VehicleJourneyUpdateEvent
+	var vehicleEvents []*model.VehicleUpdateEvent
+	var lineEvents, stopAreaEvents int
+	for _, event := range collectManager.UpdateEvents {
+		switch e := event.(type) {
+		case *model.StopVisitUpdateEvent:
+			stopVisitEvents = append(stopVisitEvents, e)
+		case *model.VehicleJourneyUpdateEvent:
+			vehicleJourneyEvents = append(vehicleJourneyEvents, e)
+		case *model.VehicleUpdateEvent:
+			vehicleEvents = append(vehicleEvents, e)
+		case *model.LineUpdateEvent:
+			lineEvents++
+		case *model.StopAreaUpdateEvent:
+			stopAreaEvents++
+		}
+	}
+
+	if lineEvents != 1 || stopAreaEvents != 1 || len(vehicleJourneyEvents) != 1 {
+		t.Fatalf("Wrong number of events: %v lines, %v stop areas, %v vehicle journeys", lineEvents, stopAreaEvents, len(vehicleJourneyEvents))
+	}
+	if expected := model.NewObjectID("test", "route"); vehicleJourneyEvents[0].LineObjectId != expected {
+		t.Errorf("Wrong VehicleJourney LineObjectId:\n got: %v\n want: %v", vehicleJourneyEvents[0].LineObjectId, expected)
+	}
+
+	if len(stopVisitEvents) != 1 {
+		t.Fatalf("Wrong number of StopVisitUpdateEvents:\n got: %v\n want: 1", len(stopVisitEvents))
+	}
+	svEvent := stopVisitEvents[0]
+	if expected := model.NewObjectID("test", "trip-3"); svEvent.ObjectId != expected {
+		t.Errorf("Wrong StopVisit ObjectId:\n got: %v\n want: %v", svEvent.ObjectId, expected)
+	}
+	if expected := model.NewObjectID("test", "stop"); svEvent.StopAreaObjectId != expected {
+		t.Errorf("Wrong StopVisit StopAreaObjectId:\n got: %v\n want: %v", svEvent.StopAreaObjectId, expected)
+	}
+	if svEvent.PassageOrder != 3 {
+		t.Errorf("Wrong StopVisit PassageOrder:\n got: %v\n want: 3", svEvent.PassageOrder)
+	}
+	if expected := time.Unix(arrival, 0); !svEvent.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_EXPECTED).ArrivalTime().Equal(expected) {
+		t.Errorf("Wrong StopVisit expected arrival time:\n got: %v\n want: %v", svEvent.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_EXPECTED).ArrivalTime(), expected)
+	}
+
+	if len(vehicleEvents) != 2 {
+		t.Fatalf("Wrong number of VehicleUpdateEvents:\n got: %v\n want: 2", len(vehicleEvents))
+	}
+	vEvent := vehicleEvents[0]
+	if vEvent.Origin != "partner" {
+		t.Errorf("Wrong Vehicle Origin:\n got: %v\n want: partner", vEvent.Origin)
+	}
+	if expected := model.NewObjectID("test", "vehicle"); vEvent.ObjectId != expected {
+		t.Errorf("Wrong Vehicle ObjectId:\n got: %v\n want: %v", vEvent.ObjectId, expected)
+	}
+	if expected := model.NewObjectID("test", "trip"); vEvent.VehicleJourneyObjectId != expected {
+		t.Errorf("Wrong Vehicle VehicleJourneyObjectId:\n got: %v\n want: %v", vEvent.VehicleJourneyObjectId, expected)
+	}
+	if vEvent.Latitude != float64(lat) || vEvent.Longitude != float64(lon) || vEvent.Bearing != float64(bearing) {
+		t.Errorf("Wrong Vehicle position: %v %v %v", vEvent.Latitude, vEvent.Longitude, vEvent.Bearing)
+	}
+	if expected := time.Unix(int64(vehicleTimestamp), 0); !vEvent.RecordedAt.Equal(expected) {
+		t.Errorf("Wrong Vehicle RecordedAt:\n got: %v\n want: %v", vEvent.RecordedAt, expected)
+	}
+
+	otherEvent := vehicleEvents[1]
+	if otherEvent.VehicleJourneyObjectId != (model.ObjectID{}) {
+		t.Errorf("Vehicle without trip shouldn't have a VehicleJourneyObjectId, got %v", otherEvent.VehicleJourneyObjectId)
+	}
+	if expected := time.Unix(int64(timestamp), 0); !otherEvent.RecordedAt.Equal(expected) {
+		t.Errorf("Wrong Vehicle RecordedAt without timestamp:\n got: %v\n want: %v", otherEvent.RecordedAt, expected)
+	}
+}
+
+func Test_GtfsRequestCollector_RequestGtfs_WithoutStopSequence(t *testing.T) {
+	audit.SetCurrentLogstash(audit.NewFakeLogStash())
+
+	version := "2.0"
+	timestamp := uint64(clock.FAKE_CLOCK_INITIAL_DATE.Unix())
+	tripId := "trip"
+	stopIds := []string{"stop1", "stop2", "stop3"}
+	delay := int32(120)
+	arrival := clock.FAKE_CLOCK_INITIAL_DATE.Add(10 * time.Minute).Unix()
+	aimedArrival := clock.FAKE_CLOCK_INITIAL_DATE.Add(5 * time.Minute)
+
+	feed := &gtfs.FeedMessage{
+		Header: &gtfs.FeedHeader{
+			GtfsRealtimeVersion: &version,
+			Timestamp:           &timestamp,
+		},
+		Entity: []*gtfs.FeedEntity{
+			{
+				Id: &tripId,
+				TripUpdate: &gtfs.TripUpdate{
+					Trip: &gtfs.TripDescriptor{TripId: &tripId},
+					StopTimeUpdate: []*gtfs.TripUpdate_StopTimeUpdate{
+						{StopId: &stopIds[0], Arrival: &gtfs.TripUpdate_StopTimeEvent{Delay: &delay}},
+						{StopId: &stopIds[1], Arrival: &gtfs.TripUpdate_StopTimeEvent{Time: &arrival}},
+						{StopId: &stopIds[2]},
+					},
+				},
+			},
+		},
+	}
+	data, err := proto.Marshal(feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer ts.Close()
+
+	referential := NewMemoryReferentials().New("referential")
+	partner := referential.Partners().New("partner")
+	partner.SetSettingsDefinition(map[string]string{
+		"remote_url":           ts.URL,
+		"remote_objectid_kind": "test",
+	})
+
+	stopVisit := referential.Model().StopVisits().New()
+	stopVisit.SetObjectID(model.NewObjectID("test", "trip-stop1"))
+	stopVisit.Schedules.SetArrivalTime(model.STOP_VISIT_SCHEDULE_AIMED, aimedArrival)
+	stopVisit.Save()
+
+	connector := NewGtfsRequestCollector(partner)
+	connector.SetClock(clock.NewFakeClock())
+	collectManager := NewTestCollectManager().(*TestCollectManager)
+	connector.SetSubscriber(collectManager.TestUpdateSubscriber)
+
+	connector.RequestGtfs()
+
+	var stopVisitEvents []*model.StopVisitUpdateEvent
+	for _, event := range collectManager.UpdateEvents {
+		if e, ok := event.(*model.StopVisitUpdateEvent); ok {
+			stopVisitEvents = append(stopVisitEvents, e)
+		}
+	}
+	if len(stopVisitEvents) != 3 {
+		t.Fatalf("Wrong number of StopVisitUpdateEvents:\n got: %v\n want: 3", len(stopVisitEvents))
+	}
+
+	expectedArrivals := []time.Time{aimedArrival.Add(2 * time.Minute), time.Unix(arrival, 0), {}}
+	for i, event := range stopVisitEvents {
+		if expected := model.NewObjectID("test", "trip-"+stopIds[i]); event.ObjectId != expected {
+			t.Errorf("Wrong ObjectId for event %d:\n got: %v\n want: %v", i, event.ObjectId, expected)
+		}
+		if event.PassageOrder != i+1 {
+			t.Errorf("Wrong PassageOrder for event %d:\n got: %v\n want: %v", i, event.PassageOrder, i+1)
+		}
+		expectedArrival := event.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_EXPECTED).ArrivalTime()
+		if !expectedArrival.Equal(expectedArrivals[i]) {
+			t.Errorf("Wrong expected arrival time for event %d:\n got: %v\n want: %v", i, expectedArrival, expectedArrivals[i])
+		}
+		if monitored := !expectedArrivals[i].IsZero(); event.Monitored != monitored {
+			t.Errorf("Wrong Monitored for event %d:\n got: %v\n want: %v", i, event.Monitored, monitored)
+		}
+	}
+}
+
+func Test_GtfsRequestCollector_Start(t *testing.T) {
+	audit.SetCurrentLogstash(audit.NewFakeLogStash())
+
+	requested := make(chan struct{}, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case requested <- struct{}{}:
+		default:
+		}
+	}))
+	defer ts.Close()
+
+	referential := NewMemoryReferentials().New("referential")
+	partner := referential.Partners().New("partner")
+	partner.SetSettingsDefinition(map[string]string{
+		"remote_url":           ts.URL,
+		"remote_objectid_kind": "test",
+	})
+
+	connector := NewGtfsRequestCollector(partner)
+	connector.SetClock(clock.NewFakeClock())
+	connector.SetSubscriber(nil)
+
+	connector.Start()
+	defer connector.Stop()
+
+	select {
+	case <-requested:
+	case <-time.After(time.Second):
+		t.Errorf("GTFS-RT feed should be requested when the connector starts")
+	}
+}
@@ -0,0 +1,194 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/cache"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"github.com/MobilityData/gtfs-realtime-bindings/golang/gtfs"
+)
+
+type ServiceAlertsBroadcaster struct {
+	clock.ClockConsumer
+
+	BaseConnector
+
+	cache *cache.CachedItem
+}
+
+type ServiceAlertsBroadcasterFactory struct{}
+
+func (factory *ServiceAlertsBroadcasterFactory) CreateConnector(partner *Partner) Connector {
+	return NewServiceAlertsBroadcaster(partner)
+}
+
+func (factory *ServiceAlertsBroadcasterFactory) Validate(apiPartner *APIPartner) {
+	apiPartner.ValidatePresenceOfSetting(REMOTE_OBJECTID_KIND)
+}
+
+func NewServiceAlertsBroadcaster(partner *Partner) *ServiceAlertsBroadcaster {
+	connector := &ServiceAlertsBroadcaster{}
+	connector.partner = partner
+	connector.cache = cache.NewCachedItem("ServiceAlerts", partner.CacheTimeout(GTFS_RT_SERVICE_ALERTS_BROADCASTER), nil, func(...interface{}) (interface{}, error) { return connector.handleGtfs() })
+
+	return connector
+}
+
+func (connector *ServiceAlertsBroadcaster) HandleGtfs(feed *gtfs.FeedMessage, logStashEvent audit.LogStashEvent) {
+	entities, _ := connector.cache.Value()
+	feedEntities := entities.([]*gtfs.FeedEntity)
+
+	for i := range feedEntities {
+		feed.Entity = append(feed.Entity, feedEntities[i])
+	}
+	logStashEvent["service_alert_quantity"] = strconv.Itoa(len(feedEntities))
+}
+
+func (connector *ServiceAlertsBroadcaster) handleGtfs() (entities []*gtfs.FeedEntity, err error) {
+	tx := connector.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	objectidKind := connector.partner.RemoteObjectIDKind(GTFS_RT_SERVICE_ALERTS_BROADCASTER)
+	referenceGenerator := connector.Partner().IdentifierGenerator(REFERENCE_IDENTIFIER)
+	now := connector.Clock().Now()
+
+	for _, situation := range tx.Model().Situations().FindAll() {
+		if situation.Origin == string(connector.Partner().Slug()) || situation.Channel == "Commercial" {
+			continue
+		}
+		if !situation.ValidUntil.IsZero() && situation.ValidUntil.Before(now) {
+			continue
+		}
+
+		var entityId string
+		objectid, ok := situation.ObjectID(objectidKind)
+		if ok {
+			entityId = objectid.Value()
+		} else {
+			objectid, ok = situation.ObjectID("_default")
+			if !ok {
+				continue
+			}
+			entityId = referenceGenerator.NewIdentifier(IdentifierAttributes{Type: "InfoMessage", Id: objectid.Value()})
+		}
+
+		alert := &gtfs.Alert{}
+
+		// Informed entities
+		references := append([]*model.Reference{}, situation.References...)
+		references = append(references, situation.Affects...)
+		for _, lineSection := range situation.LineSections {
+			for _, reference := range lineSection.GetReferences() {
+				r := reference
+				references = append(references, &r)
+			}
+		}
+		informedEntities := make(map[string]struct{})
+		for _, reference := range references {
+			entitySelector, ok := connector.entitySelector(tx, reference, objectidKind)
+			if !ok {
+				continue
+			}
+			key := fmt.Sprintf("%v/%v", entitySelector.GetRouteId(), entitySelector.GetStopId())
+			if _, ok := informedEntities[key]; ok {
+				continue
+			}
+			informedEntities[key] = struct{}{}
+			alert.InformedEntity = append(alert.InformedEntity, entitySelector)
+		}
+		// An Alert must have at least one informed entity
+		if len(alert.InformedEntity) == 0 {
+			continue
+		}
+
+		// Active periods
+		for _, period := range situation.ValidityPeriods {
+			alert.ActivePeriod = append(alert.ActivePeriod, gtfsTimeRange(period.StartTime, period.EndTime))
+		}
+		if len(alert.ActivePeriod) == 0 && !situation.ValidUntil.IsZero() {
+			alert.ActivePeriod = append(alert.ActivePeriod, gtfsTimeRange(situation.RecordedAt, situation.ValidUntil))
+		}
+
+		// Texts
+		header, description := situation.Summary, situation.Description
+		for _, message := range situation.Messages {
+			switch {
+			case header == "" && message.Type != "longMessage":
+				header = message.Content
+			case description == "" && message.Type == "longMessage":
+				description = message.Content
+			}
+		}
+		if header == "" && len(situation.Messages) != 0 {
+			header = situation.Messages[0].Content
+		}
+		alert.HeaderText = gtfsTranslatedString(header)
+		alert.DescriptionText = gtfsTranslatedString(description)
+
+		entities = append(entities, &gtfs.FeedEntity{
+			Id:    &entityId,
+			Alert: alert,
+		})
+	}
+
+	return
+}
+
+func (connector *ServiceAlertsBroadcaster) entitySelector(tx *model.Transaction, reference *model.Reference, objectidKind string) (*gtfs.EntitySelector, bool) {
+	if reference.ObjectId == nil {
+		return nil, false
+	}
+
+	switch reference.Type {
+	case "LineRef":
+		line, ok := tx.Model().Lines().FindByObjectId(*reference.ObjectId)
+		if !ok {
+			return nil, false
+		}
+		lineObjectId, ok := line.ObjectID(objectidKind)
+		if !ok {
+			return nil, false
+		}
+		routeId := lineObjectId.Value()
+		return &gtfs.EntitySelector{RouteId: &routeId}, true
+	case "StopPointRef", "DestinationRef", "FirstStop", "LastStop":
+		stopArea, ok := tx.Model().StopAreas().FindByObjectId(*reference.ObjectId)
+		if !ok {
+			return nil, false
+		}
+		stopAreaObjectId, ok := stopArea.ReferentOrSelfObjectId(objectidKind)
+		if !ok {
+			return nil, false
+		}
+		stopId := stopAreaObjectId.Value()
+		return &gtfs.EntitySelector{StopId: &stopId}, true
+	}
+	return nil, false
+}
+
+// Zero times are left undefined
+func gtfsTimeRange(start, end time.Time) *gtfs.TimeRange {
+	timeRange := &gtfs.TimeRange{}
+	if !start.IsZero() {
+		s := uint64(start.Unix())
+		timeRange.Start = &s
+	}
+	if !end.IsZero() {
+		e := uint64(end.Unix())
+		timeRange.End = &e
+	}
+	return timeRange
+}
+
+func gtfsTranslatedString(text string) *gtfs.TranslatedString {
+	if text == "" {
+		return nil
+	}
+	return &gtfs.TranslatedString{
+		Translation: []*gtfs.TranslatedString_Translation{{Text: &text}},
+	}
+}
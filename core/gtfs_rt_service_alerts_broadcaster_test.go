@@ -0,0 +1,100 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"github.com/MobilityData/gtfs-realtime-bindings/golang/gtfs"
+)
+
+func Test_ServiceAlertsBroadcaster_HandleGtfs(t *testing.T) {
+	referentials := NewMemoryReferentials()
+	referential := referentials.New("referential")
+	partner := referential.Partners().New("partner")
+	partner.SetSetting("remote_objectid_kind", "objectidKind")
+	connector := NewServiceAlertsBroadcaster(partner)
+	connector.SetClock(clock.NewFakeClock())
+
+	lineObjectId := model.NewObjectID("objectidKind", "lId")
+	line := referential.Model().Lines().New()
+	line.SetObjectID(lineObjectId)
+	line.Save()
+
+	stopAreaObjectId := model.NewObjectID("objectidKind", "saId")
+	stopArea := referential.Model().StopAreas().New()
+	stopArea.SetObjectID(stopAreaObjectId)
+	stopArea.Save()
+
+	otherObjectId := model.NewObjectID("other", "unknown")
+
+	startTime := connector.Clock().Now()
+	endTime := startTime.Add(time.Hour)
+
+	situation := referential.Model().Situations().New()
+	situation.SetObjectID(model.NewObjectID("objectidKind", "situation"))
+	situation.ValidUntil = endTime
+	situation.ValidityPeriods = []*model.TimeRange{{StartTime: startTime, EndTime: endTime}}
+	situation.References = []*model.Reference{
+		{ObjectId: &lineObjectId, Type: "LineRef"},
+		{ObjectId: &otherObjectId, Type: "LineRef"},
+	}
+	situation.Affects = []*model.Reference{{ObjectId: &stopAreaObjectId, Type: "StopPointRef"}}
+	situation.Messages = []*model.Message{
+		{Content: "Short", Type: "shortMessage"},
+		{Content: "Long", Type: "longMessage"},
+	}
+	situation.Save()
+
+	expired := referential.Model().Situations().New()
+	expired.SetObjectID(model.NewObjectID("objectidKind", "expired"))
+	expired.ValidUntil = startTime.Add(-time.Minute)
+	expired.References = []*model.Reference{{ObjectId: &lineObjectId, Type: "LineRef"}}
+	expired.Save()
+
+	withoutEntity := referential.Model().Situations().New()
+	withoutEntity.SetObjectID(model.NewObjectID("objectidKind", "withoutEntity"))
+	withoutEntity.ValidUntil = endTime
+	withoutEntity.References = []*model.Reference{{ObjectId: &otherObjectId, Type: "LineRef"}}
+	withoutEntity.Save()
+
+	gtfsFeed := &gtfs.FeedMessage{}
+	connector.HandleGtfs(gtfsFeed, partner.NewLogStashEvent())
+
+	if l := len(gtfsFeed.Entity); l != 1 {
+		t.Fatalf("Response have incorrect number of entities:\n got: %v\n want: 1", l)
+	}
+	entity := gtfsFeed.Entity[0]
+	if r := "situation"; entity.GetId() != r {
+		t.Errorf("Incorrect Feed entity Id:\n got: %v\n want: %v", entity.GetId(), r)
+	}
+
+	alert := entity.Alert
+	if l := len(alert.InformedEntity); l != 2 {
+		t.Fatalf("Incorrect number of informed entities:\n got: %v\n want: 2", l)
+	}
+	if r := "lId"; alert.InformedEntity[0].GetRouteId() != r {
+		t.Errorf("Incorrect informed entity RouteId:\n got: %v\n want: %v", alert.InformedEntity[0].GetRouteId(), r)
+	}
+	if r := "saId"; alert.InformedEntity[1].GetStopId() != r {
+		t.Errorf("Incorrect informed entity StopId:\n got: %v\n want: %v", alert.InformedEntity[1].GetStopId(), r)
+	}
+
+	if l := len(alert.ActivePeriod); l != 1 {
+		t.Fatalf("Incorrect number of active periods:\n got: %v\n want: 1", l)
+	}
+	if r := uint64(startTime.Unix()); alert.ActivePeriod[0].GetStart() != r {
+		t.Errorf("Incorrect active period Start:\n got: %v\n want: %v", alert.ActivePeriod[0].GetStart(), r)
+	}
+	if r := uint64(endTime.Unix()); alert.ActivePeriod[0].GetEnd() != r {
+		t.Errorf("Incorrect active period End:\n got: %v\n want: %v", alert.ActivePeriod[0].GetEnd(), r)
+	}
+
+	if r := "Short"; alert.HeaderText.Translation[0].GetText() != r {
+		t.Errorf("Incorrect HeaderText:\n got: %v\n want: %v", alert.HeaderText.Translation[0].GetText(), r)
+	}
+	if r := "Long"; alert.DescriptionText.Translation[0].GetText() != r {
+		t.Errorf("Incorrect DescriptionText:\n got: %v\n want: %v", alert.DescriptionText.Translation[0].GetText(), r)
+	}
+}
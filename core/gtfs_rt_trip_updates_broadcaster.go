@@ -105,8 +105,9 @@ func (connector *TripUpdatesBroadcaster) handleGtfs() (entities []*gtfs.FeedEnti
 			tripId := vjId.Value()
 			// Fill the tripDescriptor
 			tripDescriptor := &gtfs.TripDescriptor{
-				TripId:  &tripId,
-				RouteId: &routeId,
+				TripId:               &tripId,
+				RouteId:              &routeId,
+				ScheduleRelationship: tripScheduleRelationship(&vj),
 			}
 
 			// Fill the FeedEntity
@@ -159,3 +160,14 @@ func (connector *TripUpdatesBroadcaster) handleGtfs() (entities []*gtfs.FeedEnti
 	}
 	return
 }
+
+// Returns nil for a scheduled trip, the default ScheduleRelationship
+func tripScheduleRelationship(vehicleJourney *model.VehicleJourney) *gtfs.TripDescriptor_ScheduleRelationship {
+	switch {
+	case vehicleJourney.Cancellation:
+		return gtfs.TripDescriptor_CANCELED.Enum()
+	case vehicleJourney.ExtraJourney:
+		return gtfs.TripDescriptor_ADDED.Enum()
+	}
+	return nil
+}
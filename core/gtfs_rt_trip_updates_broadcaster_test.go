@@ -355,3 +355,54 @@ func Test_TripUpdatesBroadcaster_HandleGtfs_Generators(t *testing.T) {
 		t.Errorf("Incorrect StopId in StopTimeUpdate:\n got: %v\n want: %v", stopTimeUpdate.GetStopId(), r)
 	}
 }
+
+func Test_TripUpdatesBroadcaster_HandleGtfs_ScheduleRelationship(t *testing.T) {
+	referentials := NewMemoryReferentials()
+	referential := referentials.New("referential")
+	partner := referential.Partners().New("partner")
+	partner.SetSetting("remote_objectid_kind", "objectidKind")
+	connector := NewTripUpdatesBroadcaster(partner)
+	connector.SetClock(clock.NewFakeClock())
+
+	stopArea := referential.Model().StopAreas().New()
+	stopArea.SetObjectID(model.NewObjectID("objectidKind", "saId"))
+	stopArea.Save()
+
+	line := referential.model.Lines().New()
+	line.SetObjectID(model.NewObjectID("objectidKind", "lId"))
+	line.Save()
+
+	for _, vjId := range []string{"scheduled", "cancelled", "added"} {
+		vehicleJourney := referential.model.VehicleJourneys().New()
+		vehicleJourney.SetObjectID(model.NewObjectID("objectidKind", vjId))
+		vehicleJourney.LineId = line.Id()
+		vehicleJourney.Cancellation = vjId == "cancelled"
+		vehicleJourney.ExtraJourney = vjId == "added"
+		vehicleJourney.Save()
+
+		stopVisit := referential.model.StopVisits().New()
+		stopVisit.SetObjectID(model.NewObjectID("objectidKind", vjId))
+		stopVisit.StopAreaId = stopArea.Id()
+		stopVisit.VehicleJourneyId = vehicleJourney.Id()
+		stopVisit.Schedules.SetDepartureTime("actual", connector.Clock().Now().Add(10*time.Minute))
+		stopVisit.Save()
+	}
+
+	gtfsFeed := &gtfs.FeedMessage{}
+	connector.HandleGtfs(gtfsFeed, partner.NewLogStashEvent())
+
+	expected := map[string]gtfs.TripDescriptor_ScheduleRelationship{
+		"scheduled": gtfs.TripDescriptor_SCHEDULED,
+		"cancelled": gtfs.TripDescriptor_CANCELED,
+		"added":     gtfs.TripDescriptor_ADDED,
+	}
+	if l := len(gtfsFeed.Entity); l != len(expected) {
+		t.Fatalf("Response have incorrect number of entities:\n got: %v\n want: %v", l, len(expected))
+	}
+	for _, entity := range gtfsFeed.Entity {
+		trip := entity.TripUpdate.Trip
+		if r := expected[trip.GetTripId()]; trip.GetScheduleRelationship() != r {
+			t.Errorf("Incorrect ScheduleRelationship for trip %v:\n got: %v\n want: %v", trip.GetTripId(), trip.GetScheduleRelationship(), r)
+		}
+	}
+}
@@ -95,8 +95,9 @@ func (connector *VehiclePositionBroadcaster) handleGtfs() (entities []*gtfs.Feed
 			// Fill the tripDescriptor
 			tripId := vjId.Value()
 			trip = &gtfs.TripDescriptor{
-				TripId:  &tripId,
-				RouteId: &routeId,
+				TripId:               &tripId,
+				RouteId:              &routeId,
+				ScheduleRelationship: tripScheduleRelationship(&vj),
 			}
 
 			// ARA-874
@@ -0,0 +1,187 @@
+package core
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/model"
+)
+
+const (
+	REFERENTIAL_SETTING_HEALTH_STALE_DELAY = "health.stale_delay"
+
+	DEFAULT_HEALTH_STALE_DELAY = 10 * time.Minute
+
+	HEALTH_STATUS_OK       = "ok"
+	HEALTH_STATUS_DEGRADED = "degraded"
+)
+
+// Describes the freshness and the quality of the data of a Referential.
+//
+// The Referential is degraded when a collecting partner is down or hasn't
+// collected any data since the stale delay.
+type HealthReport struct {
+	Referential   ReferentialSlug
+	Status        string
+	GeneratedAt   time.Time
+	StaleDelay    string
+	ModelLoadedAt *time.Time `json:",omitempty"`
+	NextReloadAt  *time.Time `json:",omitempty"`
+	StopAreas     StopAreasHealth
+	StopVisits    StopVisitsHealth
+	Partners      []*PartnerHealth
+}
+
+type StopAreasHealth struct {
+	Total       int
+	Monitored   int
+	Unmonitored int
+}
+
+// Only the StopVisits which aren't passed are considered
+type StopVisitsHealth struct {
+	Upcoming                int
+	WithExpectedTimes       int
+	ExpectedTimesPercentage float64
+	// Collected StopVisits which haven't been updated since the stale delay
+	Stale int
+}
+
+type PartnerHealth struct {
+	Slug                 PartnerSlug
+	OperationnalStatus   OperationnalStatus
+	Collector            bool
+	LastCollect          *time.Time `json:",omitempty"`
+	Stale                bool
+	MonitoredStopAreas   int
+	UnmonitoredStopAreas int
+	Subscriptions        map[string]int `json:",omitempty"`
+}
+
+func (referential *Referential) HealthStaleDelay() time.Duration {
+	if delay, err := time.ParseDuration(referential.Setting(REFERENTIAL_SETTING_HEALTH_STALE_DELAY)); err == nil && delay > 0 {
+		return delay
+	}
+	return DEFAULT_HEALTH_STALE_DELAY
+}
+
+func NewHealthReport(referential *Referential) *HealthReport {
+	now := referential.Clock().Now()
+	staleDelay := referential.HealthStaleDelay()
+	staleLimit := now.Add(-staleDelay)
+
+	report := &HealthReport{
+		Referential: referential.Slug(),
+		Status:      HEALTH_STATUS_OK,
+		GeneratedAt: now,
+		StaleDelay:  staleDelay.String(),
+	}
+	if startedAt := referential.StartedAt(); !startedAt.IsZero() {
+		report.ModelLoadedAt = &startedAt
+	}
+	if nextReloadAt := referential.NextReloadAt(); !nextReloadAt.IsZero() {
+		report.NextReloadAt = &nextReloadAt
+	}
+
+	partners := referential.Partners().FindAll()
+	sort.Slice(partners, func(i, j int) bool { return partners[i].Slug() < partners[j].Slug() })
+
+	var lastCollects map[string]time.Time
+	if collectManager := referential.CollectManager(); collectManager != nil {
+		lastCollects = collectManager.LastCollects()
+	}
+
+	partnerHealths := make(map[string]*PartnerHealth)
+	for _, partner := range partners {
+		partnerHealth := &PartnerHealth{
+			Slug:               partner.Slug(),
+			OperationnalStatus: partner.OperationnalStatus(),
+			Collector:          isCollector(partner),
+		}
+
+		if lastCollect, ok := lastCollects[string(partner.Slug())]; ok {
+			partnerHealth.LastCollect = &lastCollect
+		}
+		if partnerHealth.Collector {
+			partnerHealth.Stale = partnerHealth.LastCollect == nil || partnerHealth.LastCollect.Before(staleLimit)
+			if partnerHealth.Stale || partnerHealth.OperationnalStatus == OPERATIONNAL_STATUS_DOWN {
+				report.Status = HEALTH_STATUS_DEGRADED
+			}
+		}
+
+		for _, subscription := range partner.Subscriptions().FindAll() {
+			if partnerHealth.Subscriptions == nil {
+				partnerHealth.Subscriptions = make(map[string]int)
+			}
+			partnerHealth.Subscriptions[subscription.Kind()]++
+		}
+
+		report.Partners = append(report.Partners, partnerHealth)
+		partnerHealths[string(partner.Slug())] = partnerHealth
+	}
+
+	tx := referential.NewTransaction()
+	defer tx.Close()
+
+	stopAreas := tx.Model().StopAreas().FindAll()
+	for i := range stopAreas {
+		report.StopAreas.Total++
+
+		partnersKO := stopAreas[i].Origins.PartnersKO()
+		if len(partnersKO) == 0 {
+			report.StopAreas.Monitored++
+		} else {
+			report.StopAreas.Unmonitored++
+		}
+
+		for partner, status := range stopAreas[i].Origins.AllOrigin() {
+			partnerHealth, ok := partnerHealths[partner]
+			if !ok {
+				continue
+			}
+			if status {
+				partnerHealth.MonitoredStopAreas++
+			} else {
+				partnerHealth.UnmonitoredStopAreas++
+			}
+		}
+	}
+
+	report.StopVisits = stopVisitsHealth(tx.Model().StopVisits().FindAll(), now, staleLimit)
+
+	return report
+}
+
+func stopVisitsHealth(stopVisits []model.StopVisit, now, staleLimit time.Time) (health StopVisitsHealth) {
+	for i := range stopVisits {
+		stopVisit := &stopVisits[i]
+		if stopVisit.ReferenceTime().Before(now) {
+			continue
+		}
+		health.Upcoming++
+
+		expected := stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_EXPECTED)
+		if !expected.ArrivalTime().IsZero() || !expected.DepartureTime().IsZero() {
+			health.WithExpectedTimes++
+		}
+
+		if stopVisit.IsCollected() && stopVisit.CollectedAt().Before(staleLimit) {
+			health.Stale++
+		}
+	}
+
+	if health.Upcoming != 0 {
+		health.ExpectedTimesPercentage = 100 * float64(health.WithExpectedTimes) / float64(health.Upcoming)
+	}
+	return
+}
+
+func isCollector(partner *Partner) bool {
+	for _, connectorType := range partner.ConnectorTypes {
+		if strings.HasSuffix(connectorType, "-collector") {
+			return true
+		}
+	}
+	return false
+}
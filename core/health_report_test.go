@@ -0,0 +1,110 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
+)
+
+func Test_HealthReport(t *testing.T) {
+	fakeClock := clock.NewFakeClock()
+	referentials := NewMemoryReferentials()
+	referential := referentials.New("referential")
+	referential.SetClock(fakeClock)
+	referential.Settings[REFERENTIAL_SETTING_HEALTH_STALE_DELAY] = "5m"
+	referentials.Save(referential)
+
+	collector := referential.Partners().New("collector")
+	collector.ConnectorTypes = []string{SIRI_STOP_MONITORING_REQUEST_COLLECTOR}
+	collector.PartnerStatus.OperationnalStatus = OPERATIONNAL_STATUS_UP
+	referential.Partners().Save(collector)
+	collector.Subscriptions().New("StopMonitoringCollect").Save()
+
+	broadcaster := referential.Partners().New("broadcaster")
+	broadcaster.ConnectorTypes = []string{SIRI_STOP_MONITORING_REQUEST_BROADCASTER}
+	referential.Partners().Save(broadcaster)
+
+	monitored := referential.Model().StopAreas().New()
+	monitored.Origins.SetPartnerStatus("collector", true)
+	monitored.Save()
+
+	unmonitored := referential.Model().StopAreas().New()
+	unmonitored.Origins.SetPartnerStatus("collector", false)
+	unmonitored.Save()
+
+	now := fakeClock.Now()
+	for i, collectedAt := range []time.Time{now, now.Add(-10 * time.Minute), {}} {
+		stopVisit := referential.Model().StopVisits().New()
+		stopVisit.Schedules.SetArrivalTime(model.STOP_VISIT_SCHEDULE_AIMED, now.Add(time.Duration(i+1)*time.Minute))
+		if !collectedAt.IsZero() {
+			stopVisit.Schedules.SetArrivalTime(model.STOP_VISIT_SCHEDULE_EXPECTED, now.Add(time.Duration(i+2)*time.Minute))
+			stopVisit.Collected(collectedAt)
+		}
+		stopVisit.Save()
+	}
+	passed := referential.Model().StopVisits().New()
+	passed.Schedules.SetArrivalTime(model.STOP_VISIT_SCHEDULE_AIMED, now.Add(-time.Hour))
+	passed.Save()
+
+	report := NewHealthReport(referential)
+	if report.Status != HEALTH_STATUS_DEGRADED {
+		t.Errorf("Referential without collect should be degraded, got %v", report.Status)
+	}
+
+	referential.CollectManager().BroadcastUpdateEvent(&model.LineUpdateEvent{Origin: "collector"})
+	fakeClock.Advance(time.Minute)
+
+	report = NewHealthReport(referential)
+	if report.Status != HEALTH_STATUS_OK {
+		t.Errorf("Wrong Status:\n got: %v\n want: %v", report.Status, HEALTH_STATUS_OK)
+	}
+	if report.StaleDelay != "5m0s" {
+		t.Errorf("Wrong StaleDelay:\n got: %v\n want: 5m0s", report.StaleDelay)
+	}
+
+	expectedStopAreas := StopAreasHealth{Total: 2, Monitored: 1, Unmonitored: 1}
+	if report.StopAreas != expectedStopAreas {
+		t.Errorf("Wrong StopAreas:\n got: %+v\n want: %+v", report.StopAreas, expectedStopAreas)
+	}
+
+	if report.StopVisits.Upcoming != 3 || report.StopVisits.WithExpectedTimes != 2 || report.StopVisits.ExpectedTimesPercentage != 200.0/3 || report.StopVisits.Stale != 1 {
+		t.Errorf("Wrong StopVisits: %+v", report.StopVisits)
+	}
+
+	if len(report.Partners) != 2 {
+		t.Fatalf("Wrong Partners count:\n got: %v\n want: 2", len(report.Partners))
+	}
+	broadcasterHealth, collectorHealth := report.Partners[0], report.Partners[1]
+	if broadcasterHealth.Collector || broadcasterHealth.Stale || broadcasterHealth.LastCollect != nil {
+		t.Errorf("Wrong broadcaster health: %+v", broadcasterHealth)
+	}
+	if !collectorHealth.Collector || collectorHealth.Stale || collectorHealth.LastCollect == nil || !collectorHealth.LastCollect.Equal(now) {
+		t.Errorf("Wrong collector health: %+v", collectorHealth)
+	}
+	if collectorHealth.MonitoredStopAreas != 1 || collectorHealth.UnmonitoredStopAreas != 1 {
+		t.Errorf("Wrong collector stop areas: %+v", collectorHealth)
+	}
+	if collectorHealth.Subscriptions["StopMonitoringCollect"] != 1 {
+		t.Errorf("Wrong collector subscriptions: %v", collectorHealth.Subscriptions)
+	}
+
+	fakeClock.Advance(5 * time.Minute)
+	report = NewHealthReport(referential)
+	if report.Status != HEALTH_STATUS_DEGRADED || !report.Partners[1].Stale {
+		t.Errorf("Collector without collect since the stale delay should be stale")
+	}
+}
+
+func Test_Referential_HealthStaleDelay(t *testing.T) {
+	referential := &Referential{Settings: map[string]string{}}
+	if delay := referential.HealthStaleDelay(); delay != DEFAULT_HEALTH_STALE_DELAY {
+		t.Errorf("Wrong default HealthStaleDelay: %v", delay)
+	}
+
+	referential.Settings[REFERENTIAL_SETTING_HEALTH_STALE_DELAY] = "5m"
+	if delay := referential.HealthStaleDelay(); delay != 5*time.Minute {
+		t.Errorf("Wrong HealthStaleDelay: %v", delay)
+	}
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+type LineUpdateRequestId string
+
+type LineUpdateRequest struct {
+	id        LineUpdateRequestId
+	lineId    model.LineId
+	createdAt time.Time
+}
+
+func NewLineUpdateRequest(lineId model.LineId) *LineUpdateRequest {
+	return &LineUpdateRequest{
+		id:        LineUpdateRequestId(uuid.DefaultUUIDGenerator().NewUUID()),
+		lineId:    lineId,
+		createdAt: clock.DefaultClock().Now(),
+	}
+}
+
+func (lineUpdateRequest *LineUpdateRequest) Id() LineUpdateRequestId {
+	return lineUpdateRequest.id
+}
+
+func (lineUpdateRequest *LineUpdateRequest) LineId() model.LineId {
+	return lineUpdateRequest.lineId
+}
+
+func (lineUpdateRequest *LineUpdateRequest) CreatedAt() time.Time {
+	return lineUpdateRequest.createdAt
+}
@@ -0,0 +1,411 @@
+package core
+
+import (
+	"sort"
+	"strings"
+
+	"bitbucket.org/enroute-mobi/ara/model"
+)
+
+const (
+	REFERENTIAL_SETTING_STOP_AREA_EQUIVALENCES = "merging.stop_area_equivalences"
+	REFERENTIAL_SETTING_LINE_EQUIVALENCES      = "merging.line_equivalences"
+)
+
+// Groups the StopAreas and the Lines describing the same physical object:
+// an object, its referent, the objects sharing the same referent and the
+// objects declared equivalent in the referential settings.
+//
+// The equivalences are defined by groups of ObjectIDs separated by ';',
+// each group containing ObjectIDs separated by ',':
+//
+//	kind1:value1,kind2:value2;kind1:value3,kind2:value4
+//
+// The StopVisits of the same passage collected from several partners are
+// merged field by field according to the partners collect.priority.
+type Merger struct {
+	tx *model.Transaction
+
+	partners             Partners
+	stopAreaEquivalences map[model.ObjectID][]model.ObjectID
+	lineEquivalences     map[model.ObjectID][]model.ObjectID
+	excludedOrigin       string
+
+	stopAreaGroupKeys map[model.StopAreaId]model.StopAreaId
+	lineGroupKeys     map[model.LineId]model.LineId
+	priorities        map[string]int
+	mergedStopVisits  map[model.StopVisitId]*model.StopVisit
+}
+
+func NewMerger(referential *Referential, tx *model.Transaction) *Merger {
+	return &Merger{
+		tx:                   tx,
+		partners:             referential.Partners(),
+		stopAreaEquivalences: parseEquivalences(referential.Setting(REFERENTIAL_SETTING_STOP_AREA_EQUIVALENCES)),
+		lineEquivalences:     parseEquivalences(referential.Setting(REFERENTIAL_SETTING_LINE_EQUIVALENCES)),
+		stopAreaGroupKeys:    make(map[model.StopAreaId]model.StopAreaId),
+		lineGroupKeys:        make(map[model.LineId]model.LineId),
+		priorities:           make(map[string]int),
+		mergedStopVisits:     make(map[model.StopVisitId]*model.StopVisit),
+	}
+}
+
+// The StopVisits of this origin are not merged
+func (merger *Merger) ExcludeOrigin(origin string) {
+	merger.excludedOrigin = origin
+}
+
+func parseEquivalences(setting string) map[model.ObjectID][]model.ObjectID {
+	equivalences := make(map[model.ObjectID][]model.ObjectID)
+	for _, group := range strings.Split(setting, ";") {
+		objectids := []model.ObjectID{}
+		for _, s := range strings.Split(group, ",") {
+			kindValue := strings.SplitN(strings.TrimSpace(s), ":", 2)
+			if len(kindValue) != 2 || kindValue[0] == "" || kindValue[1] == "" {
+				continue
+			}
+			objectids = append(objectids, model.NewObjectID(kindValue[0], kindValue[1]))
+		}
+		for _, objectid := range objectids {
+			equivalences[objectid] = append(equivalences[objectid], objectids...)
+		}
+	}
+	return equivalences
+}
+
+func (merger *Merger) StopAreaGroup(stopAreaId model.StopAreaId) []model.StopAreaId {
+	group := []model.StopAreaId{}
+	visited := make(map[model.StopAreaId]struct{})
+	queue := []model.StopAreaId{stopAreaId}
+
+	for len(queue) != 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if _, ok := visited[id]; ok || id == "" {
+			continue
+		}
+		stopArea, ok := merger.tx.Model().StopAreas().Find(id)
+		if !ok {
+			continue
+		}
+		visited[id] = struct{}{}
+		group = append(group, id)
+
+		queue = append(queue, stopArea.ReferentId)
+		referringStopAreas := merger.tx.Model().StopAreas().FindByReferentId(id)
+		for i := range referringStopAreas {
+			queue = append(queue, referringStopAreas[i].Id())
+		}
+		for _, objectid := range stopArea.ObjectIDs() {
+			for _, equivalent := range merger.stopAreaEquivalences[objectid] {
+				if equivalentStopArea, ok := merger.tx.Model().StopAreas().FindByObjectId(equivalent); ok {
+					queue = append(queue, equivalentStopArea.Id())
+				}
+			}
+		}
+	}
+	return group
+}
+
+func (merger *Merger) LineGroup(lineId model.LineId) []model.LineId {
+	group := []model.LineId{}
+	visited := make(map[model.LineId]struct{})
+	queue := []model.LineId{lineId}
+
+	for len(queue) != 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if _, ok := visited[id]; ok || id == "" {
+			continue
+		}
+		line, ok := merger.tx.Model().Lines().Find(id)
+		if !ok {
+			continue
+		}
+		visited[id] = struct{}{}
+		group = append(group, id)
+
+		queue = append(queue, line.ReferentId)
+		for _, referringLine := range merger.tx.Model().Lines().FindByReferentId(id) {
+			queue = append(queue, referringLine.Id())
+		}
+		for _, objectid := range line.ObjectIDs() {
+			for _, equivalent := range merger.lineEquivalences[objectid] {
+				if equivalentLine, ok := merger.tx.Model().Lines().FindByObjectId(equivalent); ok {
+					queue = append(queue, equivalentLine.Id())
+				}
+			}
+		}
+	}
+	return group
+}
+
+// Returns the family of all the StopAreas of the group
+func (merger *Merger) StopAreaFamily(stopAreaId model.StopAreaId) (stopAreaIds []model.StopAreaId) {
+	known := make(map[model.StopAreaId]struct{})
+	for _, groupStopAreaId := range merger.StopAreaGroup(stopAreaId) {
+		for _, id := range merger.tx.Model().StopAreas().FindFamily(groupStopAreaId) {
+			if _, ok := known[id]; ok {
+				continue
+			}
+			known[id] = struct{}{}
+			stopAreaIds = append(stopAreaIds, id)
+		}
+	}
+	return
+}
+
+// Returns the first StopArea of the group with an ObjectID of the given kind,
+// or the given StopArea when the group has no such ObjectID
+func (merger *Merger) StopAreaWithObjectIDKind(stopAreaId model.StopAreaId, kind string) model.StopAreaId {
+	for _, id := range merger.StopAreaGroup(stopAreaId) {
+		stopArea, _ := merger.tx.Model().StopAreas().Find(id)
+		if _, ok := stopArea.ObjectID(kind); ok {
+			return id
+		}
+	}
+	return stopAreaId
+}
+
+// Returns the first Line of the group with an ObjectID of the given kind, or
+// the given Line when the group has no such ObjectID
+func (merger *Merger) LineWithObjectIDKind(lineId model.LineId, kind string) model.LineId {
+	for _, id := range merger.LineGroup(lineId) {
+		line, _ := merger.tx.Model().Lines().Find(id)
+		if _, ok := line.ObjectID(kind); ok {
+			return id
+		}
+	}
+	return lineId
+}
+
+func (merger *Merger) LineVehicleJourneys(lineId model.LineId) (vehicleJourneys []model.VehicleJourney) {
+	for _, id := range merger.LineGroup(lineId) {
+		vehicleJourneys = append(vehicleJourneys, merger.tx.Model().VehicleJourneys().FindByLineId(id)...)
+	}
+	return
+}
+
+func (merger *Merger) stopAreaGroupKey(stopAreaId model.StopAreaId) model.StopAreaId {
+	if key, ok := merger.stopAreaGroupKeys[stopAreaId]; ok {
+		return key
+	}
+	group := merger.StopAreaGroup(stopAreaId)
+	key := stopAreaId
+	for _, id := range group {
+		if id < key {
+			key = id
+		}
+	}
+	for _, id := range group {
+		merger.stopAreaGroupKeys[id] = key
+	}
+	merger.stopAreaGroupKeys[stopAreaId] = key
+	return key
+}
+
+func (merger *Merger) lineGroupKey(lineId model.LineId) model.LineId {
+	if key, ok := merger.lineGroupKeys[lineId]; ok {
+		return key
+	}
+	group := merger.LineGroup(lineId)
+	key := lineId
+	for _, id := range group {
+		if id < key {
+			key = id
+		}
+	}
+	for _, id := range group {
+		merger.lineGroupKeys[id] = key
+	}
+	merger.lineGroupKeys[lineId] = key
+	return key
+}
+
+func (merger *Merger) priority(origin string) int {
+	priority, ok := merger.priorities[origin]
+	if ok {
+		return priority
+	}
+	if partner, ok := merger.partners.FindBySlug(PartnerSlug(origin)); ok {
+		priority = partner.CollectPriority()
+	}
+	merger.priorities[origin] = priority
+	return priority
+}
+
+type passageKey struct {
+	stopAreaGroup model.StopAreaId
+	lineGroup     model.LineId
+	aimedTime     int64
+}
+
+// Merges in place the StopVisits describing the same passage: same StopArea
+// group, same Line group and same aimed time. The StopVisit of the partner
+// with the highest collect.priority is completed with the fields missing in
+// it. Returns the merged StopVisits which must be ignored, associated to the
+// VehicleJourney of the StopVisit they were merged into.
+func (merger *Merger) MergeStopVisits(stopVisitLists ...[]model.StopVisit) map[model.StopVisitId]model.VehicleJourneyId {
+	merged := make(map[model.StopVisitId]model.VehicleJourneyId)
+	for stopVisitId, base := range merger.mergeStopVisits(stopVisitLists...) {
+		merged[stopVisitId] = base.VehicleJourneyId
+	}
+	return merged
+}
+
+// Returns the StopVisit to broadcast instead of the given one: the StopVisit
+// it is merged into or itself, completed with the merged StopVisits. The
+// StopVisits of the same StopArea group are merged only once by Merger.
+func (merger *Merger) MergedStopVisit(stopVisit *model.StopVisit) *model.StopVisit {
+	if mergedStopVisit, ok := merger.mergedStopVisits[stopVisit.Id()]; ok {
+		return mergedStopVisit
+	}
+
+	stopVisits := merger.tx.Model().StopVisits().FindFollowingByStopAreaIds(merger.StopAreaFamily(stopVisit.StopAreaId))
+	merged := merger.mergeStopVisits(stopVisits)
+	for i := range stopVisits {
+		id := stopVisits[i].Id()
+		if _, ok := merger.mergedStopVisits[id]; ok {
+			continue
+		}
+		if base, ok := merged[id]; ok {
+			merger.mergedStopVisits[id] = base
+		} else {
+			merger.mergedStopVisits[id] = &stopVisits[i]
+		}
+	}
+
+	if mergedStopVisit, ok := merger.mergedStopVisits[stopVisit.Id()]; ok {
+		return mergedStopVisit
+	}
+	merger.mergedStopVisits[stopVisit.Id()] = stopVisit
+	return stopVisit
+}
+
+// Returns the merged StopVisits associated to the StopVisit they were merged
+// into
+func (merger *Merger) mergeStopVisits(stopVisitLists ...[]model.StopVisit) map[model.StopVisitId]*model.StopVisit {
+	merged := make(map[model.StopVisitId]*model.StopVisit)
+	passages := make(map[passageKey][]*model.StopVisit)
+	keys := []passageKey{}
+	lineIds := make(map[model.VehicleJourneyId]model.LineId)
+
+	for _, stopVisits := range stopVisitLists {
+		for i := range stopVisits {
+			stopVisit := &stopVisits[i]
+			if stopVisit.Origin == merger.excludedOrigin && stopVisit.Origin != "" {
+				continue
+			}
+			aimedTime := stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_AIMED).DepartureTime()
+			if aimedTime.IsZero() {
+				aimedTime = stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_AIMED).ArrivalTime()
+			}
+			if aimedTime.IsZero() {
+				continue
+			}
+			lineId, ok := lineIds[stopVisit.VehicleJourneyId]
+			if !ok {
+				vehicleJourney, _ := merger.tx.Model().VehicleJourneys().Find(stopVisit.VehicleJourneyId)
+				lineId = vehicleJourney.LineId
+				lineIds[stopVisit.VehicleJourneyId] = lineId
+			}
+
+			key := passageKey{
+				stopAreaGroup: merger.stopAreaGroupKey(stopVisit.StopAreaId),
+				lineGroup:     merger.lineGroupKey(lineId),
+				aimedTime:     aimedTime.Unix(),
+			}
+			if _, ok := passages[key]; !ok {
+				keys = append(keys, key)
+			}
+			passages[key] = append(passages[key], stopVisit)
+		}
+	}
+
+	for _, key := range keys {
+		passage := passages[key]
+		if len(passage) < 2 {
+			continue
+		}
+		sort.Slice(passage, func(i, j int) bool {
+			pi, pj := merger.priority(passage[i].Origin), merger.priority(passage[j].Origin)
+			if pi != pj {
+				return pi > pj
+			}
+			if passage[i].Origin != passage[j].Origin {
+				return passage[i].Origin < passage[j].Origin
+			}
+			return passage[i].Id() < passage[j].Id()
+		})
+
+		base := passage[0]
+		origins := map[string]struct{}{base.Origin: {}}
+		for _, stopVisit := range passage[1:] {
+			// Several StopVisits of the same partner are distinct passages
+			if _, ok := origins[stopVisit.Origin]; ok {
+				continue
+			}
+			origins[stopVisit.Origin] = struct{}{}
+
+			mergeStopVisit(base, stopVisit)
+			merged[stopVisit.Id()] = base
+		}
+	}
+
+	return merged
+}
+
+// Merges the StopVisits of the VehicleJourneys. Returns the VehicleJourneys
+// merged into another one, which must be ignored.
+func (merger *Merger) MergeVehicleJourneys(stopVisits map[model.VehicleJourneyId][]model.StopVisit) map[model.VehicleJourneyId]struct{} {
+	stopVisitLists := [][]model.StopVisit{}
+	vehicleJourneyIds := make(map[model.StopVisitId]model.VehicleJourneyId)
+	for vehicleJourneyId, list := range stopVisits {
+		stopVisitLists = append(stopVisitLists, list)
+		for i := range list {
+			vehicleJourneyIds[list[i].Id()] = vehicleJourneyId
+		}
+	}
+
+	merged := make(map[model.VehicleJourneyId]struct{})
+	for stopVisitId, baseVehicleJourneyId := range merger.MergeStopVisits(stopVisitLists...) {
+		if vehicleJourneyId := vehicleJourneyIds[stopVisitId]; vehicleJourneyId != baseVehicleJourneyId {
+			merged[vehicleJourneyId] = struct{}{}
+		}
+	}
+	return merged
+}
+
+func mergeStopVisit(base, stopVisit *model.StopVisit) {
+	for _, kind := range model.SCHEDULE_ORDER_MAP {
+		schedule := base.Schedules.Schedule(kind)
+		other := stopVisit.Schedules.Schedule(kind)
+		if schedule.ArrivalTime().IsZero() && !other.ArrivalTime().IsZero() {
+			base.Schedules.SetArrivalTime(kind, other.ArrivalTime())
+		}
+		if schedule.DepartureTime().IsZero() && !other.DepartureTime().IsZero() {
+			base.Schedules.SetDepartureTime(kind, other.DepartureTime())
+		}
+	}
+
+	if base.ArrivalStatus == "" {
+		base.ArrivalStatus = stopVisit.ArrivalStatus
+	}
+	if base.DepartureStatus == "" {
+		base.DepartureStatus = stopVisit.DepartureStatus
+	}
+	if base.RecordedAt.IsZero() {
+		base.RecordedAt = stopVisit.RecordedAt
+	}
+
+	for key, value := range stopVisit.Attributes {
+		if _, ok := base.Attributes[key]; !ok {
+			base.Attributes.Set(key, value)
+		}
+	}
+	for key, reference := range stopVisit.References.GetReferences() {
+		if _, ok := base.References.Get(key); !ok {
+			base.References.SetReference(key, reference)
+		}
+	}
+}
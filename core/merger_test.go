@@ -0,0 +1,195 @@
+package core
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/model"
+)
+
+func Test_Merger_StopAreaGroup(t *testing.T) {
+	referential := NewMemoryReferentials().New("test")
+	referential.Settings[REFERENTIAL_SETTING_STOP_AREA_EQUIVALENCES] = "kind1:a,kind2:b ; kind1:wrong"
+
+	referent := referential.Model().StopAreas().New()
+	referent.Save()
+
+	stopArea := referential.Model().StopAreas().New()
+	stopArea.ReferentId = referent.Id()
+	stopArea.SetObjectID(model.NewObjectID("kind1", "a"))
+	stopArea.Save()
+
+	otherStopArea := referential.Model().StopAreas().New()
+	otherStopArea.ReferentId = referent.Id()
+	otherStopArea.Save()
+
+	equivalent := referential.Model().StopAreas().New()
+	equivalent.SetObjectID(model.NewObjectID("kind2", "b"))
+	equivalent.Save()
+
+	child := referential.Model().StopAreas().New()
+	child.ParentId = equivalent.Id()
+	child.Save()
+
+	alone := referential.Model().StopAreas().New()
+	alone.Save()
+
+	tx := referential.NewTransaction()
+	defer tx.Close()
+	merger := NewMerger(referential, tx)
+
+	expected := []model.StopAreaId{referent.Id(), stopArea.Id(), otherStopArea.Id(), equivalent.Id()}
+	sortStopAreaIds(expected)
+	for _, id := range expected {
+		group := merger.StopAreaGroup(id)
+		sortStopAreaIds(group)
+		if !reflect.DeepEqual(group, expected) {
+			t.Errorf("Wrong group for %v:\n got: %v\n want: %v", id, group, expected)
+		}
+	}
+
+	if group := merger.StopAreaGroup(alone.Id()); !reflect.DeepEqual(group, []model.StopAreaId{alone.Id()}) {
+		t.Errorf("StopArea without referent nor equivalence should be alone in its group, got %v", group)
+	}
+	if family := merger.StopAreaFamily(stopArea.Id()); len(family) != 5 {
+		t.Errorf("Family should contain the group and its children, got %v", family)
+	}
+	if id := merger.StopAreaWithObjectIDKind(referent.Id(), "kind2"); id != equivalent.Id() {
+		t.Errorf("Wrong StopArea with kind2 ObjectID: %v", id)
+	}
+	if id := merger.StopAreaWithObjectIDKind(referent.Id(), "kind3"); id != referent.Id() {
+		t.Errorf("Wrong StopArea without kind3 ObjectID: %v", id)
+	}
+}
+
+func sortStopAreaIds(ids []model.StopAreaId) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+}
+
+func Test_Merger_MergeStopVisits(t *testing.T) {
+	referential := NewMemoryReferentials().New("test")
+	referential.Settings[REFERENTIAL_SETTING_LINE_EQUIVALENCES] = "kind1:line,kind2:line"
+
+	low := referential.Partners().New("low")
+	low.SetSetting(COLLECT_PRIORITY, "1")
+	referential.Partners().Save(low)
+	high := referential.Partners().New("high")
+	high.SetSetting(COLLECT_PRIORITY, "2")
+	referential.Partners().Save(high)
+
+	referent := referential.Model().StopAreas().New()
+	referent.Save()
+
+	aimedTime := time.Date(2017, time.January, 1, 12, 0, 0, 0, time.UTC)
+	for _, partner := range []struct{ origin, lineKind string }{{"low", "kind1"}, {"high", "kind2"}} {
+		stopArea := referential.Model().StopAreas().New()
+		stopArea.ReferentId = referent.Id()
+		stopArea.Save()
+
+		line := referential.Model().Lines().New()
+		line.SetObjectID(model.NewObjectID(partner.lineKind, "line"))
+		line.Save()
+
+		vehicleJourney := referential.Model().VehicleJourneys().New()
+		vehicleJourney.LineId = line.Id()
+		vehicleJourney.Origin = partner.origin
+		vehicleJourney.Save()
+
+		stopVisit := referential.Model().StopVisits().New()
+		stopVisit.StopAreaId = stopArea.Id()
+		stopVisit.VehicleJourneyId = vehicleJourney.Id()
+		stopVisit.Origin = partner.origin
+		stopVisit.Schedules.SetDepartureTime(model.STOP_VISIT_SCHEDULE_AIMED, aimedTime)
+		stopVisit.Save()
+	}
+
+	tx := referential.NewTransaction()
+	defer tx.Close()
+	stopVisits := tx.Model().StopVisits().FindAll()
+
+	var lowIndex, highIndex int
+	for i := range stopVisits {
+		if stopVisits[i].Origin == "low" {
+			lowIndex = i
+			stopVisits[i].Schedules.SetArrivalTime(model.STOP_VISIT_SCHEDULE_EXPECTED, aimedTime.Add(time.Minute))
+			stopVisits[i].DepartureStatus = model.STOP_VISIT_DEPARTURE_DELAYED
+		} else {
+			highIndex = i
+			stopVisits[i].DepartureStatus = model.STOP_VISIT_DEPARTURE_ONTIME
+		}
+	}
+
+	merged := NewMerger(referential, tx).MergeStopVisits(stopVisits)
+	if len(merged) != 1 {
+		t.Fatalf("One StopVisit should be merged, got %v", merged)
+	}
+	if _, ok := merged[stopVisits[lowIndex].Id()]; !ok {
+		t.Errorf("StopVisit with the lowest priority should be merged, got %v", merged)
+	}
+
+	base := &stopVisits[highIndex]
+	if base.DepartureStatus != model.STOP_VISIT_DEPARTURE_ONTIME {
+		t.Errorf("Fields of the highest priority StopVisit should be kept, got %v", base.DepartureStatus)
+	}
+	if arrivalTime := base.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_EXPECTED).ArrivalTime(); !arrivalTime.Equal(aimedTime.Add(time.Minute)) {
+		t.Errorf("Missing fields should be completed, got %v", arrivalTime)
+	}
+}
+
+// Creates the same passage collected by the partners "high" and "low" at two
+// StopAreas and on two Lines of the same groups. Only the StopArea, the Line,
+// the VehicleJourney and the StopVisit of "high" have "internal" ObjectIDs,
+// only the StopVisit of "low" has an expected departure time.
+func mergerTest_PrepareModel(referential *Referential, aimedTime time.Time) (high, low *model.StopVisit) {
+	for slug, priority := range map[string]string{"high": "2", "low": "1"} {
+		partner := referential.Partners().New(PartnerSlug(slug))
+		partner.SetSetting(COLLECT_PRIORITY, priority)
+		referential.Partners().Save(partner)
+	}
+
+	var referentStopAreaId model.StopAreaId
+	var referentLineId model.LineId
+	for _, origin := range []string{"high", "low"} {
+		kind := "internal"
+		if origin == "low" {
+			kind = "other"
+		}
+
+		stopArea := referential.Model().StopAreas().New()
+		stopArea.SetObjectID(model.NewObjectID(kind, origin+"StopArea"))
+		stopArea.ReferentId = referentStopAreaId
+		stopArea.SetPartnerStatus(origin, true)
+		stopArea.Save()
+		referentStopAreaId = stopArea.Id()
+
+		line := referential.Model().Lines().New()
+		line.SetObjectID(model.NewObjectID(kind, origin+"Line"))
+		line.ReferentId = referentLineId
+		line.Save()
+		referentLineId = line.Id()
+
+		vehicleJourney := referential.Model().VehicleJourneys().New()
+		vehicleJourney.SetObjectID(model.NewObjectID(kind, origin+"VehicleJourney"))
+		vehicleJourney.LineId = line.Id()
+		vehicleJourney.Origin = origin
+		vehicleJourney.Monitored = true
+		vehicleJourney.Save()
+
+		stopVisit := referential.Model().StopVisits().New()
+		stopVisit.SetObjectID(model.NewObjectID(kind, origin+"StopVisit"))
+		stopVisit.StopAreaId = stopArea.Id()
+		stopVisit.VehicleJourneyId = vehicleJourney.Id()
+		stopVisit.Origin = origin
+		stopVisit.Schedules.SetDepartureTime(model.STOP_VISIT_SCHEDULE_AIMED, aimedTime)
+		if origin == "low" {
+			stopVisit.Schedules.SetDepartureTime(model.STOP_VISIT_SCHEDULE_EXPECTED, aimedTime.Add(2*time.Minute))
+			low = &stopVisit
+		} else {
+			high = &stopVisit
+		}
+		stopVisit.Save()
+	}
+	return
+}
@@ -16,6 +16,9 @@ type ModelGuardian struct {
 	uuid.UUIDConsumer
 
 	gmTimer     time.Time
+	vmTimer     time.Time
+	etTimer     time.Time
+	snTimer     time.Time
 	stop        chan struct{}
 	referential *Referential
 }
@@ -41,6 +44,9 @@ func (guardian *ModelGuardian) Stop() {
 func (guardian *ModelGuardian) Run() {
 	c := guardian.Clock().After(10 * time.Second)
 	guardian.gmTimer = guardian.Clock().Now()
+	guardian.vmTimer = guardian.Clock().Now()
+	guardian.etTimer = guardian.Clock().Now()
+	guardian.snTimer = guardian.Clock().Now()
 
 	for {
 		select {
@@ -57,7 +63,11 @@ func (guardian *ModelGuardian) Run() {
 			guardian.refreshStopAreas()
 			guardian.refreshLines()
 			guardian.simulateActualAttributes()
+			guardian.predictDelays()
 			guardian.requestSituations()
+			guardian.requestVehicles()
+			guardian.requestEstimatedTimetables()
+			guardian.saveSnapshot()
 
 			c = guardian.Clock().After(10 * time.Second)
 		}
@@ -170,6 +180,50 @@ func (guardian *ModelGuardian) requestSituations() {
 	guardian.referential.CollectManager().UpdateSituation(situationUpdateRequest)
 }
 
+func (guardian *ModelGuardian) requestVehicles() {
+	defer monitoring.HandlePanic()
+
+	if guardian.Clock().Now().Before(guardian.vmTimer.Add(30 * time.Second)) {
+		return
+	}
+
+	guardian.vmTimer = guardian.vmTimer.Add(30 * time.Second)
+
+	tx := guardian.referential.NewTransaction()
+	defer tx.Close()
+
+	for _, line := range tx.Model().Lines().FindAll() {
+		vehicleUpdateRequest := &VehicleUpdateRequest{
+			id:        VehicleUpdateRequestId(guardian.NewUUID()),
+			lineId:    line.Id(),
+			createdAt: guardian.Clock().Now(),
+		}
+		guardian.referential.CollectManager().UpdateVehicle(vehicleUpdateRequest)
+	}
+}
+
+func (guardian *ModelGuardian) requestEstimatedTimetables() {
+	defer monitoring.HandlePanic()
+
+	if guardian.Clock().Now().Before(guardian.etTimer.Add(30 * time.Second)) {
+		return
+	}
+
+	guardian.etTimer = guardian.etTimer.Add(30 * time.Second)
+
+	tx := guardian.referential.NewTransaction()
+	defer tx.Close()
+
+	for _, line := range tx.Model().Lines().FindAll() {
+		lineUpdateRequest := &LineUpdateRequest{
+			id:        LineUpdateRequestId(guardian.NewUUID()),
+			lineId:    line.Id(),
+			createdAt: guardian.Clock().Now(),
+		}
+		guardian.referential.CollectManager().UpdateLine(lineUpdateRequest)
+	}
+}
+
 func (guardian *ModelGuardian) simulateActualAttributes() {
 	defer monitoring.HandlePanic()
 
@@ -287,3 +341,45 @@ func (simulator *ActualAttributesSimulator) simulateDeparture() bool {
 	}
 	return false
 }
+
+func (guardian *ModelGuardian) predictDelays() {
+	defer monitoring.HandlePanic()
+
+	if !PredictionEnabled(guardian.referential) {
+		return
+	}
+
+	predictor := NewDelayPredictor(guardian.referential)
+
+	tx := guardian.referential.NewTransaction()
+	defer tx.Close()
+
+	for _, vehicleJourney := range tx.Model().VehicleJourneys().FindAll() {
+		vehicleJourneyTx := guardian.referential.NewTransaction()
+
+		stopVisits := vehicleJourneyTx.Model().StopVisits().FindByVehicleJourneyId(vehicleJourney.Id())
+		modified := predictor.Predict(stopVisits)
+		for _, i := range modified {
+			vehicleJourneyTx.Model().StopVisits().Save(&stopVisits[i])
+		}
+		if len(modified) != 0 {
+			logger.Log.Debugf("Update predicted schedules of %d StopVisits of VehicleJourney %s", len(modified), vehicleJourney.Id())
+			vehicleJourneyTx.Commit()
+		}
+		vehicleJourneyTx.Close()
+	}
+}
+
+func (guardian *ModelGuardian) saveSnapshot() {
+	defer monitoring.HandlePanic()
+
+	if guardian.Clock().Now().Before(guardian.snTimer.Add(1 * time.Minute)) {
+		return
+	}
+
+	guardian.snTimer = guardian.Clock().Now()
+
+	if err := guardian.referential.SaveSnapshot(); err != nil {
+		logger.Log.Printf("Can't save model snapshot of referential %v: %v", guardian.referential.Slug(), err)
+	}
+}
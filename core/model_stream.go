@@ -0,0 +1,236 @@
+package core
+
+import (
+	"encoding/json"
+	"sync"
+
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/model"
+)
+
+const MODEL_STREAM_QUEUE_SIZE = 500
+
+// Filters the events sent to a ModelStream. Empty filters accept all the
+// events.
+type ModelStreamFilter struct {
+	StopAreaIds map[model.StopAreaId]struct{}
+	LineIds     map[model.LineId]struct{}
+	Kinds       map[string]struct{}
+}
+
+type ModelStreamEvent struct {
+	Kind  string          `json:"Kind"`
+	Id    string          `json:"Id"`
+	Model json.RawMessage `json:"Model"`
+
+	stopAreaIds []model.StopAreaId
+	lineIds     []model.LineId
+}
+
+// Receives the changes of the model which match its filter
+type ModelStream struct {
+	filter *ModelStreamFilter
+	events chan *ModelStreamEvent
+}
+
+func (stream *ModelStream) Events() <-chan *ModelStreamEvent {
+	return stream.events
+}
+
+// Sends the changes of the Referential model to the opened ModelStreams
+type ModelStreams struct {
+	referential *Referential
+
+	mutex   *sync.RWMutex
+	streams map[*ModelStream]struct{}
+}
+
+func NewModelStreams(referential *Referential) *ModelStreams {
+	return &ModelStreams{
+		referential: referential,
+		mutex:       &sync.RWMutex{},
+		streams:     make(map[*ModelStream]struct{}),
+	}
+}
+
+func (streams *ModelStreams) New(filter *ModelStreamFilter) *ModelStream {
+	stream := &ModelStream{
+		filter: filter,
+		events: make(chan *ModelStreamEvent, MODEL_STREAM_QUEUE_SIZE),
+	}
+
+	streams.mutex.Lock()
+	streams.streams[stream] = struct{}{}
+	streams.mutex.Unlock()
+
+	return stream
+}
+
+func (streams *ModelStreams) Close(stream *ModelStream) {
+	streams.mutex.Lock()
+	delete(streams.streams, stream)
+	streams.mutex.Unlock()
+}
+
+func (streams *ModelStreams) Size() int {
+	streams.mutex.RLock()
+	defer streams.mutex.RUnlock()
+	return len(streams.streams)
+}
+
+func (streams *ModelStreams) HandleStopMonitoringBroadcastEvent(event *model.StopMonitoringBroadcastEvent) {
+	if streams.Size() == 0 {
+		return
+	}
+
+	tx := streams.referential.NewTransaction()
+	defer tx.Close()
+
+	var streamEvent *ModelStreamEvent
+	switch event.ModelType {
+	case "StopVisit":
+		stopVisit, ok := tx.Model().StopVisits().Find(model.StopVisitId(event.ModelId))
+		if !ok {
+			return
+		}
+		streamEvent = newModelStreamEvent(event.ModelType, event.ModelId, &stopVisit)
+		streamEvent.stopAreaIds = []model.StopAreaId{stopVisit.StopAreaId}
+		if vehicleJourney, ok := tx.Model().VehicleJourneys().Find(stopVisit.VehicleJourneyId); ok {
+			streamEvent.lineIds = []model.LineId{vehicleJourney.LineId}
+		}
+	case "VehicleJourney":
+		vehicleJourney, ok := tx.Model().VehicleJourneys().Find(model.VehicleJourneyId(event.ModelId))
+		if !ok {
+			return
+		}
+		streamEvent = newModelStreamEvent(event.ModelType, event.ModelId, &vehicleJourney)
+		streamEvent.stopAreaIds = vehicleJourneyStopAreaIds(tx, vehicleJourney.Id())
+		streamEvent.lineIds = []model.LineId{vehicleJourney.LineId}
+	case "Vehicle":
+		vehicle, ok := tx.Model().Vehicles().Find(model.VehicleId(event.ModelId))
+		if !ok {
+			return
+		}
+		streamEvent = newModelStreamEvent(event.ModelType, event.ModelId, &vehicle)
+		streamEvent.stopAreaIds = vehicleJourneyStopAreaIds(tx, vehicle.VehicleJourneyId)
+		streamEvent.lineIds = []model.LineId{vehicle.LineId}
+	default:
+		return
+	}
+
+	streams.send(streamEvent)
+}
+
+func (streams *ModelStreams) HandleGeneralMessageBroadcastEvent(event *model.GeneralMessageBroadcastEvent) {
+	if streams.Size() == 0 {
+		return
+	}
+
+	tx := streams.referential.NewTransaction()
+	defer tx.Close()
+
+	situation, ok := tx.Model().Situations().Find(event.SituationId)
+	if !ok {
+		return
+	}
+
+	streamEvent := newModelStreamEvent("Situation", string(situation.Id()), &situation)
+	references := append([]*model.Reference{}, situation.References...)
+	references = append(references, situation.Affects...)
+	for _, reference := range references {
+		if reference.ObjectId == nil {
+			continue
+		}
+		if line, ok := tx.Model().Lines().FindByObjectId(*reference.ObjectId); ok {
+			streamEvent.lineIds = append(streamEvent.lineIds, line.Id())
+		}
+		if stopArea, ok := tx.Model().StopAreas().FindByObjectId(*reference.ObjectId); ok {
+			streamEvent.stopAreaIds = append(streamEvent.stopAreaIds, stopArea.Id())
+		}
+	}
+
+	streams.send(streamEvent)
+}
+
+func (streams *ModelStreams) send(event *ModelStreamEvent) {
+	if event.Model == nil {
+		return
+	}
+
+	streams.mutex.RLock()
+	defer streams.mutex.RUnlock()
+
+	for stream := range streams.streams {
+		if !stream.filter.Match(event) {
+			continue
+		}
+		select {
+		case stream.events <- event:
+		default:
+			logger.Log.Debugf("ModelStream queue is full")
+		}
+	}
+}
+
+func newModelStreamEvent(kind, id string, m json.Marshaler) *ModelStreamEvent {
+	event := &ModelStreamEvent{
+		Kind: kind,
+		Id:   id,
+	}
+
+	content, err := m.MarshalJSON()
+	if err != nil {
+		logger.Log.Debugf("Unable to marshal %v %v: %v", kind, id, err)
+		return event
+	}
+	event.Model = content
+	return event
+}
+
+func vehicleJourneyStopAreaIds(tx *model.Transaction, vehicleJourneyId model.VehicleJourneyId) (stopAreaIds []model.StopAreaId) {
+	stopVisits := tx.Model().StopVisits().FindByVehicleJourneyId(vehicleJourneyId)
+	for i := range stopVisits {
+		stopAreaIds = append(stopAreaIds, stopVisits[i].StopAreaId)
+	}
+	return
+}
+
+func (filter *ModelStreamFilter) Match(event *ModelStreamEvent) bool {
+	if filter == nil {
+		return true
+	}
+
+	if len(filter.Kinds) != 0 {
+		if _, ok := filter.Kinds[event.Kind]; !ok {
+			return false
+		}
+	}
+
+	if len(filter.StopAreaIds) != 0 {
+		found := false
+		for _, stopAreaId := range event.stopAreaIds {
+			if _, ok := filter.StopAreaIds[stopAreaId]; ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(filter.LineIds) != 0 {
+		found := false
+		for _, lineId := range event.lineIds {
+			if _, ok := filter.LineIds[lineId]; ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -13,6 +13,7 @@ import (
 	"bitbucket.org/enroute-mobi/ara/cache"
 	"bitbucket.org/enroute-mobi/ara/logger"
 	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
 	"bitbucket.org/enroute-mobi/ara/state"
 	"bitbucket.org/enroute-mobi/ara/uuid"
 )
@@ -54,6 +55,7 @@ type Partners interface {
 type PartnerStatus struct {
 	OperationnalStatus OperationnalStatus
 	ServiceStartedAt   time.Time
+	CircuitBreaker     *siri.CircuitBreakerState `json:",omitempty"`
 }
 
 type Partner struct {
@@ -79,7 +81,8 @@ type Partner struct {
 	subscriptionManager Subscriptions
 	manager             Partners
 
-	gtfsCache *cache.CacheTable
+	gtfsCache   *cache.CacheTable
+	soapMetrics *siri.SOAPClientMetrics
 }
 
 type ByPriority []*Partner
@@ -129,6 +132,8 @@ func (partner *APIPartner) Validate() bool {
 		factory.Validate(partner)
 	}
 
+	partner.validateAuthentication()
+
 	// Check Slug uniqueness
 	for _, existingPartner := range partner.manager.FindAll() {
 		if existingPartner.id != partner.Id && existingPartner.slug == partner.Slug {
@@ -224,7 +229,8 @@ func NewPartner() *Partner {
 		PartnerStatus: PartnerStatus{
 			OperationnalStatus: OPERATIONNAL_STATUS_UNKNOWN,
 		},
-		gtfsCache: cache.NewCacheTable(),
+		gtfsCache:   cache.NewCacheTable(),
+		soapMetrics: siri.NewSOAPClientMetrics(),
 	}
 	partner.PartnerSettings = NewPartnerSettings(partner)
 	partner.subscriptionManager = NewMemorySubscriptions(partner)
@@ -260,6 +266,11 @@ func (partner *Partner) GtfsCache() *cache.CacheTable {
 	return partner.gtfsCache
 }
 
+// Returns the metrics shared by all the SOAPClients of the Partner connectors
+func (partner *Partner) SOAPMetrics() *siri.SOAPClientMetrics {
+	return partner.soapMetrics
+}
+
 func (partner *Partner) OperationnalStatus() OperationnalStatus {
 	return partner.PartnerStatus.OperationnalStatus
 }
@@ -326,6 +337,7 @@ func (partner *Partner) Start() {
 	to := partner.GtfsCacheTimeout()
 	partner.gtfsCache.Add("trip-updates", to, nil)
 	partner.gtfsCache.Add("vehicle-positions", to, nil)
+	partner.gtfsCache.Add("service-alerts", to, nil)
 	partner.gtfsCache.Add("trip-updates,vehicle-position", to, nil)
 }
 
@@ -407,6 +419,14 @@ func (partner *Partner) hasSubscribers() bool {
 		return true
 	}
 	_, ok = partner.connectors[SIRI_ESTIMATED_TIMETABLE_SUBSCRIPTION_BROADCASTER]
+	if ok {
+		return true
+	}
+	_, ok = partner.connectors[SIRI_VEHICLE_MONITORING_SUBSCRIPTION_BROADCASTER]
+	if ok {
+		return true
+	}
+	_, ok = partner.connectors[SIRI_SITUATION_EXCHANGE_SUBSCRIPTION_BROADCASTER]
 	return ok
 }
 
@@ -441,7 +461,11 @@ func (partner *Partner) GtfsConnectors() (connectors []GtfsConnector, ok bool) {
 	if ok2 {
 		connectors = append(connectors, c.(GtfsConnector))
 	}
-	ok = ok1 || ok2
+	c, ok3 := partner.connectors[GTFS_RT_SERVICE_ALERTS_BROADCASTER]
+	if ok3 {
+		connectors = append(connectors, c.(GtfsConnector))
+	}
+	ok = ok1 || ok2 || ok3
 
 	return
 }
@@ -464,6 +488,22 @@ func (partner *Partner) GeneralMessageSubscriptionCollector() GeneralMessageSubs
 	return nil
 }
 
+func (partner *Partner) SituationExchangeRequestCollector() SituationExchangeRequestCollector {
+	client, ok := partner.connectors[SIRI_SITUATION_EXCHANGE_REQUEST_COLLECTOR]
+	if ok {
+		return client.(SituationExchangeRequestCollector)
+	}
+	return nil
+}
+
+func (partner *Partner) SituationExchangeSubscriptionCollector() SituationExchangeSubscriptionCollector {
+	client, ok := partner.connectors[SIRI_SITUATION_EXCHANGE_SUBSCRIPTION_COLLECTOR]
+	if ok {
+		return client.(SituationExchangeSubscriptionCollector)
+	}
+	return nil
+}
+
 func (partner *Partner) StopMonitoringSubscriptionCollector() StopMonitoringSubscriptionCollector {
 	// WIP
 	client, ok := partner.connectors[SIRI_STOP_MONITORING_SUBSCRIPTION_COLLECTOR]
@@ -486,6 +526,38 @@ func (partner *Partner) StopMonitoringRequestCollector() StopMonitoringRequestCo
 	return nil
 }
 
+func (partner *Partner) VehicleMonitoringRequestCollector() VehicleMonitoringRequestCollector {
+	client, ok := partner.connectors[SIRI_VEHICLE_MONITORING_REQUEST_COLLECTOR]
+	if ok {
+		return client.(VehicleMonitoringRequestCollector)
+	}
+	return nil
+}
+
+func (partner *Partner) VehicleMonitoringSubscriptionCollector() VehicleMonitoringSubscriptionCollector {
+	client, ok := partner.connectors[SIRI_VEHICLE_MONITORING_SUBSCRIPTION_COLLECTOR]
+	if ok {
+		return client.(VehicleMonitoringSubscriptionCollector)
+	}
+	return nil
+}
+
+func (partner *Partner) EstimatedTimetableRequestCollector() EstimatedTimetableRequestCollector {
+	client, ok := partner.connectors[SIRI_ESTIMATED_TIMETABLE_REQUEST_COLLECTOR]
+	if ok {
+		return client.(EstimatedTimetableRequestCollector)
+	}
+	return nil
+}
+
+func (partner *Partner) EstimatedTimetableSubscriptionCollector() EstimatedTimetableSubscriptionCollector {
+	client, ok := partner.connectors[SIRI_ESTIMATED_TIMETABLE_SUBSCRIPTION_COLLECTOR]
+	if ok {
+		return client.(EstimatedTimetableSubscriptionCollector)
+	}
+	return nil
+}
+
 func (partner *Partner) hasPushCollector() (ok bool) {
 	_, ok = partner.connectors[PUSH_COLLECTOR]
 	return ok
@@ -508,6 +580,10 @@ func (partner *Partner) CheckStatus() (PartnerStatus, error) {
 	if err != nil {
 		logger.Log.Printf("Error while checking status: %v", err)
 	}
+	if circuitBreaker := partner.CircuitBreaker(); circuitBreaker != nil {
+		state := circuitBreaker.State()
+		partnerStatus.CircuitBreaker = &state
+	}
 	logger.Log.Debugf("Partner %v status is %v", partner.slug, partnerStatus.OperationnalStatus)
 	return partnerStatus, nil
 }
@@ -629,6 +705,7 @@ func (manager *PartnerManager) New(slug PartnerSlug) *Partner {
 		},
 		ConnectorTypes: []string{},
 		gtfsCache:      cache.NewCacheTable(),
+		soapMetrics:    siri.NewSOAPClientMetrics(),
 	}
 	partner.PartnerSettings = NewPartnerSettings(partner)
 	partner.subscriptionManager = NewMemorySubscriptions(partner)
@@ -776,6 +853,10 @@ func (manager *PartnerManager) Load() error {
 
 		partner.RefreshConnectors()
 		manager.Save(partner)
+
+		if err = partner.Subscriptions().Load(); err != nil {
+			logger.Log.Printf("Unable to load subscriptions of partner %v: %v", partner.Slug(), err)
+		}
 	}
 	return nil
 }
@@ -822,6 +903,14 @@ func (manager *PartnerManager) SaveToDatabase() (int, error) {
 		}
 	}
 
+	// Delete subscriptions of removed partners
+	sqlQuery = fmt.Sprintf("delete from subscriptions where referential_id = '%s' and partner_id not in (select id from partners where referential_id = '%s');", manager.referential.Id(), manager.referential.Id())
+	_, err = tx.Exec(sqlQuery)
+	if err != nil {
+		tx.Rollback()
+		return http.StatusInternalServerError, fmt.Errorf("database error: %v", err)
+	}
+
 	// Commit transaction
 	err = tx.Commit()
 	if err != nil {
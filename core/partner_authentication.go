@@ -0,0 +1,107 @@
+package core
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"bitbucket.org/enroute-mobi/ara/siri"
+)
+
+// Checks the request against the local_authentication settings. Without
+// local_authentication.mode, the partner is only identified by its
+// credentials.
+func (s *PartnerSettings) Authenticate(request *http.Request) bool {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	switch s.s[LOCAL_AUTHENTICATION_MODE] {
+	case "":
+		return true
+	case siri.AUTHENTICATION_BASIC:
+		username, password, ok := request.BasicAuth()
+		return ok && secureCompare(username, s.s[LOCAL_AUTHENTICATION_USERNAME]) && secureCompare(password, s.s[LOCAL_AUTHENTICATION_PASSWORD])
+	case siri.AUTHENTICATION_BEARER:
+		const prefix = "Bearer "
+		authorization := request.Header.Get("Authorization")
+		return strings.HasPrefix(authorization, prefix) && secureCompare(authorization[len(prefix):], s.s[LOCAL_AUTHENTICATION_TOKEN])
+	case siri.AUTHENTICATION_CLIENT_CERTIFICATE:
+		if request.TLS == nil || len(request.TLS.PeerCertificates) == 0 {
+			return false
+		}
+		fingerprint := sha256.Sum256(request.TLS.PeerCertificates[0].Raw)
+		for _, expected := range trimedSlice(s.s[LOCAL_AUTHENTICATION_CERTIFICATE_FINGERPRINTS]) {
+			if secureCompare(hex.EncodeToString(fingerprint[:]), normalizeFingerprint(expected)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *PartnerSettings) SOAPClientAuthentication() siri.SOAPClientAuthentication {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return siri.SOAPClientAuthentication{
+		Mode:            s.s[REMOTE_AUTHENTICATION_MODE],
+		Username:        s.s[REMOTE_AUTHENTICATION_USERNAME],
+		Password:        s.s[REMOTE_AUTHENTICATION_PASSWORD],
+		Token:           s.s[REMOTE_AUTHENTICATION_TOKEN],
+		CertificateFile: s.s[REMOTE_AUTHENTICATION_CERTIFICATE_FILE],
+		KeyFile:         s.s[REMOTE_AUTHENTICATION_KEY_FILE],
+	}
+}
+
+func (partner *APIPartner) validateAuthentication() {
+	switch partner.Settings[LOCAL_AUTHENTICATION_MODE] {
+	case "":
+	case siri.AUTHENTICATION_BASIC:
+		partner.ValidatePresenceOfSetting(LOCAL_AUTHENTICATION_USERNAME)
+		partner.ValidatePresenceOfSetting(LOCAL_AUTHENTICATION_PASSWORD)
+	case siri.AUTHENTICATION_BEARER:
+		partner.ValidatePresenceOfSetting(LOCAL_AUTHENTICATION_TOKEN)
+	case siri.AUTHENTICATION_CLIENT_CERTIFICATE:
+		partner.ValidatePresenceOfSetting(LOCAL_AUTHENTICATION_CERTIFICATE_FINGERPRINTS)
+	default:
+		partner.Errors.AddSettingError(LOCAL_AUTHENTICATION_MODE, ERROR_INVALID)
+	}
+
+	switch partner.Settings[REMOTE_AUTHENTICATION_MODE] {
+	case "":
+	case siri.AUTHENTICATION_BASIC:
+		partner.ValidatePresenceOfSetting(REMOTE_AUTHENTICATION_USERNAME)
+		partner.ValidatePresenceOfSetting(REMOTE_AUTHENTICATION_PASSWORD)
+	case siri.AUTHENTICATION_BEARER:
+		partner.ValidatePresenceOfSetting(REMOTE_AUTHENTICATION_TOKEN)
+	case siri.AUTHENTICATION_CLIENT_CERTIFICATE:
+		partner.ValidatePresenceOfSetting(REMOTE_AUTHENTICATION_CERTIFICATE_FILE)
+		partner.ValidatePresenceOfSetting(REMOTE_AUTHENTICATION_KEY_FILE)
+		partner.validateClientCertificate()
+	default:
+		partner.Errors.AddSettingError(REMOTE_AUTHENTICATION_MODE, ERROR_INVALID)
+	}
+}
+
+// The SOAPClient can't be authenticated without a loadable client certificate
+func (partner *APIPartner) validateClientCertificate() {
+	certificateFile, keyFile := partner.Settings[REMOTE_AUTHENTICATION_CERTIFICATE_FILE], partner.Settings[REMOTE_AUTHENTICATION_KEY_FILE]
+	if certificateFile == "" || keyFile == "" {
+		return
+	}
+	if _, err := tls.LoadX509KeyPair(certificateFile, keyFile); err != nil {
+		partner.Errors.AddSettingError(REMOTE_AUTHENTICATION_CERTIFICATE_FILE, ERROR_INVALID)
+	}
+}
+
+func secureCompare(given, expected string) bool {
+	return expected != "" && subtle.ConstantTimeCompare([]byte(given), []byte(expected)) == 1
+}
+
+// Fingerprints can be written "AB:CD:..." like in openssl output
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.Replace(fingerprint, ":", "", -1))
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func Test_PartnerSettings_Authenticate(t *testing.T) {
+	certificate := &x509.Certificate{Raw: []byte("certificate")}
+	fingerprint := sha256.Sum256(certificate.Raw)
+	upperFingerprint := strings.ToUpper(hex.EncodeToString(fingerprint[:]))
+
+	var conditions = []struct {
+		settings map[string]string
+		prepare  func(*http.Request)
+		expected bool
+	}{
+		{map[string]string{}, func(*http.Request) {}, true},
+		{
+			map[string]string{"local_authentication.mode": "basic", "local_authentication.username": "user", "local_authentication.password": "secret"},
+			func(r *http.Request) { r.SetBasicAuth("user", "secret") },
+			true,
+		},
+		{
+			map[string]string{"local_authentication.mode": "basic", "local_authentication.username": "user", "local_authentication.password": "secret"},
+			func(r *http.Request) { r.SetBasicAuth("user", "wrong") },
+			false,
+		},
+		{
+			map[string]string{"local_authentication.mode": "basic", "local_authentication.username": "user", "local_authentication.password": "secret"},
+			func(*http.Request) {},
+			false,
+		},
+		{
+			map[string]string{"local_authentication.mode": "bearer", "local_authentication.token": "token"},
+			func(r *http.Request) { r.Header.Set("Authorization", "Bearer token") },
+			true,
+		},
+		{
+			map[string]string{"local_authentication.mode": "bearer", "local_authentication.token": "token"},
+			func(r *http.Request) { r.Header.Set("Authorization", "Bearer other") },
+			false,
+		},
+		{
+			map[string]string{"local_authentication.mode": "client_certificate", "local_authentication.certificate_fingerprints": "other, " + upperFingerprint},
+			func(r *http.Request) {
+				r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{certificate}}
+			},
+			true,
+		},
+		{
+			map[string]string{"local_authentication.mode": "client_certificate", "local_authentication.certificate_fingerprints": "other"},
+			func(r *http.Request) {
+				r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{certificate}}
+			},
+			false,
+		},
+		{
+			map[string]string{"local_authentication.mode": "client_certificate", "local_authentication.certificate_fingerprints": upperFingerprint},
+			func(*http.Request) {},
+			false,
+		},
+		{map[string]string{"local_authentication.mode": "unknown"}, func(*http.Request) {}, false},
+	}
+
+	for i, condition := range conditions {
+		partner := NewPartner()
+		partner.SetSettingsDefinition(condition.settings)
+
+		request, _ := http.NewRequest("POST", "/default/siri", nil)
+		condition.prepare(request)
+
+		if authenticated := partner.Authenticate(request); authenticated != condition.expected {
+			t.Errorf("Wrong Authenticate result for condition %d:\n got: %v\n want: %v", i, authenticated, condition.expected)
+		}
+	}
+}
+
+func Test_PartnerSettings_SOAPClientAuthentication(t *testing.T) {
+	partner := NewPartner()
+	partner.SetSettingsDefinition(map[string]string{
+		"remote_authentication.mode":     "basic",
+		"remote_authentication.username": "user",
+		"remote_authentication.password": "secret",
+	})
+
+	authentication := partner.SOAPClientAuthentication()
+	if authentication.Mode != "basic" || authentication.Username != "user" || authentication.Password != "secret" {
+		t.Errorf("Wrong SOAPClientAuthentication: %v", authentication)
+	}
+}
+
+func Test_APIPartner_Validate_Authentication(t *testing.T) {
+	partners := createTestPartnerManager()
+
+	apiPartner := &APIPartner{
+		Slug: "slug",
+		Settings: map[string]string{
+			"local_authentication.mode":  "basic",
+			"remote_authentication.mode": "digest",
+		},
+		manager: partners,
+	}
+	if apiPartner.Validate() {
+		t.Errorf("Validate should return false")
+	}
+	for _, setting := range []string{LOCAL_AUTHENTICATION_USERNAME, LOCAL_AUTHENTICATION_PASSWORD} {
+		if errors := apiPartner.Errors.GetSettingError(setting); len(errors) != 1 || errors[0] != ERROR_BLANK {
+			t.Errorf("apiPartner should have Error for %v, got %v", setting, apiPartner.Errors)
+		}
+	}
+	if errors := apiPartner.Errors.GetSettingError(REMOTE_AUTHENTICATION_MODE); len(errors) != 1 || errors[0] != ERROR_INVALID {
+		t.Errorf("apiPartner should have Error for %v, got %v", REMOTE_AUTHENTICATION_MODE, apiPartner.Errors)
+	}
+
+	apiPartner.Settings = map[string]string{
+		"remote_authentication.mode":             "client_certificate",
+		"remote_authentication.certificate_file": "testdata/missing.crt",
+		"remote_authentication.key_file":         "testdata/missing.key",
+	}
+	if apiPartner.Validate() {
+		t.Errorf("Validate should return false")
+	}
+	if errors := apiPartner.Errors.GetSettingError(REMOTE_AUTHENTICATION_CERTIFICATE_FILE); len(errors) != 1 || errors[0] != ERROR_INVALID {
+		t.Errorf("apiPartner should have Error for %v, got %v", REMOTE_AUTHENTICATION_CERTIFICATE_FILE, apiPartner.Errors)
+	}
+
+	apiPartner.Settings = map[string]string{
+		"local_authentication.mode":  "bearer",
+		"local_authentication.token": "token",
+	}
+	if !apiPartner.Validate() {
+		t.Errorf("Validate should return true, got errors: %v", apiPartner.Errors)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"bitbucket.org/enroute-mobi/ara/audit"
 	"bitbucket.org/enroute-mobi/ara/clock"
 	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/model"
 	"bitbucket.org/enroute-mobi/ara/monitoring"
 	"cloud.google.com/go/civil"
 )
@@ -70,6 +71,7 @@ func (guardian *PartnersGuardian) routineWork(partner *Partner) {
 	}
 
 	guardian.checkPartnerDiscovery(partner)
+	guardian.saveSubscriptions(partner)
 }
 
 func (guardian *PartnersGuardian) checkPartnerStatus(partner *Partner) bool {
@@ -97,7 +99,15 @@ func (guardian *PartnersGuardian) checkPartnerStatus(partner *Partner) bool {
 	}
 
 	if partnerStatus.OperationnalStatus == OPERATIONNAL_STATUS_UP && partnerStatus.ServiceStartedAt != partner.PartnerStatus.ServiceStartedAt {
+		previousServiceStartedAt := partner.PartnerStatus.ServiceStartedAt
+		if previousServiceStartedAt.IsZero() {
+			previousServiceStartedAt = partner.Subscriptions().ServiceStartedAt()
+		}
 		partner.PartnerStatus = partnerStatus
+		// Subscriptions loaded from Database are kept if the remote partner hasn't restarted since they were saved
+		if !previousServiceStartedAt.IsZero() && previousServiceStartedAt.Equal(partnerStatus.ServiceStartedAt) {
+			return true
+		}
 		partner.Subscriptions().CancelSubscriptions()
 		partner.lastDiscovery = time.Time{} // Reset discoveries if distant partner reloaded
 		return false
@@ -105,6 +115,7 @@ func (guardian *PartnersGuardian) checkPartnerStatus(partner *Partner) bool {
 
 	if partnerStatus.OperationnalStatus == OPERATIONNAL_STATUS_UNKNOWN || partnerStatus.OperationnalStatus == OPERATIONNAL_STATUS_DOWN {
 		partner.PartnerStatus.OperationnalStatus = partnerStatus.OperationnalStatus
+		partner.PartnerStatus.CircuitBreaker = partnerStatus.CircuitBreaker
 		partner.lastDiscovery = time.Time{} // Reset discoveries if distant partner is down
 
 		collectPersistent, _ := strconv.ParseBool(partner.Setting(COLLECT_SUBSCRIPTIONS_PERSISTENT))
@@ -152,3 +163,14 @@ func (guardian *PartnersGuardian) checkPartnerDiscovery(partner *Partner) {
 		partner.Discover()
 	}
 }
+
+// Persist the partner subscriptions to be reloaded after a restart
+func (guardian *PartnersGuardian) saveSubscriptions(partner *Partner) {
+	if model.Database == nil || partner.Subscriptions() == nil {
+		return
+	}
+
+	if err := partner.Subscriptions().SaveToDatabase(); err != nil {
+		logger.Log.Printf("Unable to save subscriptions of partner %v: %v", partner.Slug(), err)
+	}
+}
@@ -48,3 +48,50 @@ func Test_PartnerGuardian_Run(t *testing.T) {
 		t.Errorf("Guardian CheckPartnerStatus with TestCheckStatusClient timed out")
 	}
 }
+
+func Test_PartnerGuardian_checkPartnerStatus_ServiceStartedAt(t *testing.T) {
+	serviceStartedAt := time.Date(2017, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	var conditions = []struct {
+		description      string
+		previous         time.Time
+		saved            time.Time
+		expectedKept     bool
+		expectedResponse bool
+	}{
+		{"unknown remote service", time.Time{}, time.Time{}, false, false},
+		{"same remote service as the saved subscriptions", time.Time{}, serviceStartedAt, true, true},
+		{"remote service restarted since the subscriptions were saved", time.Time{}, serviceStartedAt.Add(-time.Hour), false, false},
+		{"remote service restarted", serviceStartedAt.Add(-time.Hour), time.Time{}, false, false},
+	}
+
+	for _, condition := range conditions {
+		partners := createTestPartnerManager()
+		partner := partners.New("slug")
+		partner.ConnectorTypes = []string{"test-check-status-client"}
+		subscriptions := NewMemorySubscriptions(partner)
+		subscriptions.serviceStartedAt = condition.saved
+		partner.subscriptionManager = subscriptions
+		partner.RefreshConnectors()
+		partners.Save(partner)
+
+		partner.PartnerStatus.ServiceStartedAt = condition.previous
+		checkStatusClient := partner.CheckStatusClient().(*TestCheckStatusClient)
+		checkStatusClient.partnerStatus.ServiceStartedAt = serviceStartedAt
+
+		subscriptions.New("kind")
+
+		response := partners.Guardian().checkPartnerStatus(partner)
+		<-checkStatusClient.Done
+
+		if response != condition.expectedResponse {
+			t.Errorf("Wrong checkPartnerStatus response with %v:\n got: %v\n want: %v", condition.description, response, condition.expectedResponse)
+		}
+		if kept := len(subscriptions.FindAll()) == 1; kept != condition.expectedKept {
+			t.Errorf("Subscriptions should be kept (%v) with %v", condition.expectedKept, condition.description)
+		}
+		if !partner.PartnerStatus.ServiceStartedAt.Equal(serviceStartedAt) {
+			t.Errorf("Wrong PartnerStatus ServiceStartedAt with %v: %v", condition.description, partner.PartnerStatus.ServiceStartedAt)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"bitbucket.org/enroute-mobi/ara/cache"
+	"bitbucket.org/enroute-mobi/ara/siri"
 )
 
 const (
@@ -16,6 +17,12 @@ const (
 	LOCAL_CREDENTIALS = "local_credentials"
 	LOCAL_URL         = "local_url"
 
+	LOCAL_AUTHENTICATION_MODE                     = "local_authentication.mode"
+	LOCAL_AUTHENTICATION_USERNAME                 = "local_authentication.username"
+	LOCAL_AUTHENTICATION_PASSWORD                 = "local_authentication.password"
+	LOCAL_AUTHENTICATION_TOKEN                    = "local_authentication.token"
+	LOCAL_AUTHENTICATION_CERTIFICATE_FINGERPRINTS = "local_authentication.certificate_fingerprints"
+
 	REMOTE_CREDENTIAL            = "remote_credential"
 	REMOTE_OBJECTID_KIND         = "remote_objectid_kind"
 	VEHICLE_REMOTE_OBJECTID_KIND = "vehicle_remote_objectid_kind"
@@ -23,18 +30,34 @@ const (
 	NOTIFICATIONS_REMOTE_URL     = "notifications.remote_url"
 	SUBSCRIPTIONS_REMOTE_URL     = "subscriptions.remote_url"
 
-	COLLECT_PRIORITY                 = "collect.priority"
-	COLLECT_INCLUDE_LINES            = "collect.include_lines"
-	COLLECT_INCLUDE_STOP_AREAS       = "collect.include_stop_areas"
-	COLLECT_EXCLUDE_STOP_AREAS       = "collect.exclude_stop_areas"
-	COLLECT_USE_DISCOVERED_SA        = "collect.use_discovered_stop_areas"
-	COLLECT_SUBSCRIPTIONS_PERSISTENT = "collect.subscriptions.persistent"
-	COLLECT_FILTER_GENERAL_MESSAGES  = "collect.filter_general_messages"
+	REMOTE_AUTHENTICATION_MODE             = "remote_authentication.mode"
+	REMOTE_AUTHENTICATION_USERNAME         = "remote_authentication.username"
+	REMOTE_AUTHENTICATION_PASSWORD         = "remote_authentication.password"
+	REMOTE_AUTHENTICATION_TOKEN            = "remote_authentication.token"
+	REMOTE_AUTHENTICATION_CERTIFICATE_FILE = "remote_authentication.certificate_file"
+	REMOTE_AUTHENTICATION_KEY_FILE         = "remote_authentication.key_file"
+
+	REMOTE_RETRY_ATTEMPTS            = "remote_retry.attempts"
+	REMOTE_RETRY_BACKOFF             = "remote_retry.backoff"
+	REMOTE_RETRY_MAX_DURATION        = "remote_retry.max_duration"
+	REMOTE_CIRCUIT_BREAKER_THRESHOLD = "remote_circuit_breaker.threshold"
+	REMOTE_CIRCUIT_BREAKER_COOL_OFF  = "remote_circuit_breaker.cool_off"
+
+	COLLECT_PRIORITY                     = "collect.priority"
+	COLLECT_INCLUDE_LINES                = "collect.include_lines"
+	COLLECT_INCLUDE_STOP_AREAS           = "collect.include_stop_areas"
+	COLLECT_EXCLUDE_STOP_AREAS           = "collect.exclude_stop_areas"
+	COLLECT_USE_DISCOVERED_SA            = "collect.use_discovered_stop_areas"
+	COLLECT_SUBSCRIPTIONS_PERSISTENT     = "collect.subscriptions.persistent"
+	COLLECT_FILTER_GENERAL_MESSAGES      = "collect.filter_general_messages"
+	COLLECT_GTFS_TTL                     = "collect.gtfs.ttl"
+	COLLECT_VEHICLE_POSITIONS_ESTIMATION = "collect.vehicle_positions_estimation"
 
 	DISCOVERY_INTERVAL = "discovery_interval"
 
 	BROADCAST_SUBSCRIPTIONS_PERSISTENT         = "broadcast.subscriptions.persistent"
 	BROADCAST_REWRITE_JOURNEY_PATTERN_REF      = "broadcast.rewrite_journey_pattern_ref"
+	BROADCAST_MERGE_STOP_VISITS                = "broadcast.merge_stop_visits"
 	BROADCAST_NO_DESTINATIONREF_REWRITING_FROM = "broadcast.no_destinationref_rewriting_from"
 	BROADCAST_NO_DATAFRAMEREF_REWRITING_FROM   = "broadcast.no_dataframeref_rewriting_from"
 	BROADCAST_GZIP_GTFS                        = "broadcast.gzip_gtfs"
@@ -48,6 +71,15 @@ const (
 	LOGSTASH_LOG_DELIVERIES_IN_SM_COLLECT_REQUESTS      = "logstash.log_deliveries_in_sm_collect_requests"
 
 	CACHE_TIMEOUT = "cache_timeout"
+
+	RATE_LIMIT_REQUESTS_PER_MINUTE     = "rate_limit.requests_per_minute"
+	RATE_LIMIT_MAX_CONCURRENT_REQUESTS = "rate_limit.max_concurrent_requests"
+)
+
+const (
+	DEFAULT_REMOTE_RETRY_BACKOFF            = 500 * time.Millisecond
+	DEFAULT_REMOTE_RETRY_MAX_DURATION       = 5 * time.Second
+	DEFAULT_REMOTE_CIRCUIT_BREAKER_COOL_OFF = 2 * time.Minute
 )
 
 type PartnerSettings struct {
@@ -58,6 +90,8 @@ type PartnerSettings struct {
 	s  map[string]string
 	cs *CollectSettings
 	g  map[string]*IdentifierGenerator
+	rl *RateLimiter
+	cb *siri.CircuitBreaker
 }
 
 func NewPartnerSettings(p *Partner) PartnerSettings {
@@ -156,6 +190,19 @@ func (s *PartnerSettings) GtfsCacheTimeout() (t time.Duration) {
 	return
 }
 
+func (s *PartnerSettings) GtfsCollectTTL() (t time.Duration) {
+	s.m.RLock()
+	t, _ = time.ParseDuration(s.s[COLLECT_GTFS_TTL])
+	s.m.RUnlock()
+
+	if t == 0 {
+		t = GTFS_RT_COLLECT_DEFAULT_TTL
+	} else if t < GTFS_RT_COLLECT_MIN_TTL {
+		t = GTFS_RT_COLLECT_MIN_TTL
+	}
+	return
+}
+
 func (s *PartnerSettings) CacheTimeout(connectorName string) (t time.Duration) {
 	s.m.RLock()
 	t, _ = time.ParseDuration(s.s[fmt.Sprintf("%s.%s", connectorName, CACHE_TIMEOUT)])
@@ -214,6 +261,20 @@ func (s *PartnerSettings) RewriteJourneyPatternRef() (r bool) {
 	return
 }
 
+func (s *PartnerSettings) MergeStopVisits() (r bool) {
+	s.m.RLock()
+	r, _ = strconv.ParseBool(s.s[BROADCAST_MERGE_STOP_VISITS])
+	s.m.RUnlock()
+	return
+}
+
+func (s *PartnerSettings) VehiclePositionsEstimation() (r bool) {
+	s.m.RLock()
+	r, _ = strconv.ParseBool(s.s[COLLECT_VEHICLE_POSITIONS_ESTIMATION])
+	s.m.RUnlock()
+	return
+}
+
 func (s *PartnerSettings) LogSubscriptionStopMonitoringDeliveries() (l bool) {
 	s.m.RLock()
 	l, _ = strconv.ParseBool(s.s[LOGSTASH_LOG_DELIVERIES_IN_SM_COLLECT_NOTIFICATIONS])
@@ -245,6 +306,58 @@ func (s *PartnerSettings) DiscoveryInterval() (d time.Duration) {
 	return -d
 }
 
+// The RateLimiter is created again when the settings are modified
+func (s *PartnerSettings) RateLimiter() *RateLimiter {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.rl == nil {
+		requestsPerMinute, _ := strconv.Atoi(s.s[RATE_LIMIT_REQUESTS_PER_MINUTE])
+		maxConcurrentRequests, _ := strconv.Atoi(s.s[RATE_LIMIT_MAX_CONCURRENT_REQUESTS])
+		s.rl = NewRateLimiter(requestsPerMinute, maxConcurrentRequests)
+	}
+	return s.rl
+}
+
+func (s *PartnerSettings) SOAPClientRetry() (retry siri.SOAPClientRetry) {
+	s.m.RLock()
+	retry.Attempts, _ = strconv.Atoi(s.s[REMOTE_RETRY_ATTEMPTS])
+	retry.Backoff, _ = time.ParseDuration(s.s[REMOTE_RETRY_BACKOFF])
+	retry.MaxDuration, _ = time.ParseDuration(s.s[REMOTE_RETRY_MAX_DURATION])
+	s.m.RUnlock()
+
+	if retry.Attempts < 1 {
+		retry.Attempts = 1
+	}
+	if retry.Backoff <= 0 {
+		retry.Backoff = DEFAULT_REMOTE_RETRY_BACKOFF
+	}
+	if retry.MaxDuration <= 0 {
+		retry.MaxDuration = DEFAULT_REMOTE_RETRY_MAX_DURATION
+	}
+	return
+}
+
+// Returns nil when remote_circuit_breaker.threshold isn't defined. The
+// CircuitBreaker is created again when the settings are modified.
+func (s *PartnerSettings) CircuitBreaker() *siri.CircuitBreaker {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.cb == nil {
+		threshold, _ := strconv.Atoi(s.s[REMOTE_CIRCUIT_BREAKER_THRESHOLD])
+		if threshold <= 0 {
+			return nil
+		}
+		coolOff, _ := time.ParseDuration(s.s[REMOTE_CIRCUIT_BREAKER_COOL_OFF])
+		if coolOff <= 0 {
+			coolOff = DEFAULT_REMOTE_CIRCUIT_BREAKER_COOL_OFF
+		}
+		s.cb = siri.NewCircuitBreaker(threshold, coolOff)
+	}
+	return s.cb
+}
+
 func (s *PartnerSettings) CollectSettings() *CollectSettings {
 	if s.cs == nil {
 		s.m.RLock()
@@ -305,4 +418,6 @@ func (s *PartnerSettings) refreshGenerators() {
 func (s *PartnerSettings) reloadSettings() {
 	s.setCollectSettings()
 	s.refreshGenerators()
+	s.rl = nil
+	s.cb = nil
 }
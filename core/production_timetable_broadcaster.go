@@ -0,0 +1,228 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/state"
+)
+
+type SIRIProductionTimetableBroadcaster interface {
+	state.Stopable
+	state.Startable
+}
+
+type PTBroadcaster struct {
+	clock.ClockConsumer
+
+	connector *SIRIProductionTimetableSubscriptionBroadcaster
+}
+
+type ProductionTimetableBroadcaster struct {
+	PTBroadcaster
+
+	stop chan struct{}
+}
+
+type FakeProductionTimetableBroadcaster struct {
+	PTBroadcaster
+
+	clock.ClockConsumer
+}
+
+func NewFakeProductionTimetableBroadcaster(connector *SIRIProductionTimetableSubscriptionBroadcaster) SIRIProductionTimetableBroadcaster {
+	broadcaster := &FakeProductionTimetableBroadcaster{}
+	broadcaster.connector = connector
+	return broadcaster
+}
+
+func (broadcaster *FakeProductionTimetableBroadcaster) Start() {
+	broadcaster.prepareSIRIProductionTimetable()
+}
+
+func (broadcaster *FakeProductionTimetableBroadcaster) Stop() {}
+
+func NewSIRIProductionTimetableBroadcaster(connector *SIRIProductionTimetableSubscriptionBroadcaster) SIRIProductionTimetableBroadcaster {
+	broadcaster := &ProductionTimetableBroadcaster{}
+	broadcaster.connector = connector
+
+	return broadcaster
+}
+
+func (ptb *ProductionTimetableBroadcaster) Start() {
+	logger.Log.Debugf("Start ProductionTimetableBroadcaster")
+
+	ptb.stop = make(chan struct{})
+	go ptb.run()
+}
+
+func (ptb *ProductionTimetableBroadcaster) run() {
+	c := ptb.Clock().After(5 * time.Second)
+
+	for {
+		select {
+		case <-ptb.stop:
+			logger.Log.Debugf("production timetable broadcaster routine stop")
+
+			return
+		case <-c:
+			logger.Log.Debugf("SIRIProductionTimetableBroadcaster visit")
+
+			ptb.prepareSIRIProductionTimetable()
+
+			c = ptb.Clock().After(5 * time.Second)
+		}
+	}
+}
+
+func (ptb *ProductionTimetableBroadcaster) Stop() {
+	if ptb.stop != nil {
+		close(ptb.stop)
+	}
+}
+
+func (ptb *PTBroadcaster) prepareSIRIProductionTimetable() {
+	ptb.connector.mutex.Lock()
+
+	events := ptb.connector.toBroadcast
+	ptb.connector.toBroadcast = make(map[SubscriptionId][]model.ObjectID)
+
+	ptb.connector.mutex.Unlock()
+
+	tx := ptb.connector.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	for subId, lineObjectIds := range events {
+		sub, ok := ptb.connector.Partner().Subscriptions().Find(subId)
+		if !ok {
+			continue
+		}
+
+		notify := siri.SIRINotifyProductionTimetable{
+			Address:                   ptb.connector.Partner().Address(),
+			ProducerRef:               ptb.connector.Partner().ProducerRef(),
+			ResponseMessageIdentifier: ptb.connector.Partner().IdentifierGenerator(RESPONSE_MESSAGE_IDENTIFIER).NewMessageIdentifier(),
+			SubscriberRef:             ptb.connector.SIRIPartner().SubscriberRef(),
+			SubscriptionIdentifier:    sub.ExternalId(),
+			RequestMessageRef:         sub.SubscriptionOption("MessageIdentifier"),
+			Status:                    true,
+			ResponseTimestamp:         ptb.Clock().Now(),
+		}
+
+		builder := NewBroadcastProductionTimetableBuilder(tx, ptb.connector.Partner(), SIRI_PRODUCTION_TIMETABLE_SUBSCRIPTION_BROADCASTER)
+		builder.SetValidityPeriod(subscriptionTimeOption(sub, "StartTime"), subscriptionTimeOption(sub, "EndTime"))
+
+		sentLines := make(map[model.ObjectID]struct{})
+		for _, lineObjectId := range lineObjectIds {
+			if _, ok := sentLines[lineObjectId]; ok {
+				continue
+			}
+			sentLines[lineObjectId] = struct{}{}
+
+			line, ok := tx.Model().Lines().FindByObjectId(lineObjectId)
+			if !ok {
+				logger.Log.Debugf("Could not find line : %v in production timetable broadcaster", lineObjectId.String())
+				continue
+			}
+
+			frame := builder.BuildDatedTimetableVersionFrame(&line)
+			if frame == nil {
+				continue
+			}
+			notify.DatedTimetableVersionFrames = append(notify.DatedTimetableVersionFrames, frame)
+		}
+		if len(notify.DatedTimetableVersionFrames) == 0 {
+			continue
+		}
+
+		logStashEvent := ptb.newLogStashEvent()
+		message := ptb.newBQEvent()
+
+		logSIRIProductionTimetableNotify(logStashEvent, message, &notify)
+		audit.CurrentLogStash().WriteEvent(logStashEvent)
+		t := ptb.Clock().Now()
+
+		err := ptb.connector.SIRIPartner().SOAPClient().NotifyProductionTimetable(&notify)
+		message.ProcessingTime = ptb.Clock().Since(t).Seconds()
+		if err != nil {
+			event := ptb.newLogStashEvent()
+			logSIRINotifyError(err.Error(), notify.ResponseMessageIdentifier, event)
+			audit.CurrentLogStash().WriteEvent(event)
+		}
+
+		audit.CurrentBigQuery(string(ptb.connector.Partner().Referential().Slug())).WriteEvent(message)
+	}
+}
+
+func subscriptionTimeOption(sub *Subscription, option string) time.Time {
+	value := sub.SubscriptionOption(option)
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		logger.Log.Debugf("Invalid %v option in subscription %v: %v", option, sub.Id(), err)
+		return time.Time{}
+	}
+	return t
+}
+
+func (ptb *PTBroadcaster) newBQEvent() *audit.BigQueryMessage {
+	return &audit.BigQueryMessage{
+		Type:      "NotifyProductionTimetable",
+		Protocol:  "siri",
+		Direction: "sent",
+		Partner:   string(ptb.connector.partner.Slug()),
+		Status:    "OK",
+	}
+}
+
+func (ptb *PTBroadcaster) newLogStashEvent() audit.LogStashEvent {
+	event := ptb.connector.partner.NewLogStashEvent()
+	event["connector"] = "ProductionTimetableSubscriptionBroadcaster"
+	return event
+}
+
+func logSIRIProductionTimetableNotify(logStashEvent audit.LogStashEvent, message *audit.BigQueryMessage, response *siri.SIRINotifyProductionTimetable) {
+	var lineRefs []string
+	for _, frame := range response.DatedTimetableVersionFrames {
+		lineRefs = append(lineRefs, frame.LineRef)
+	}
+
+	message.RequestIdentifier = response.RequestMessageRef
+	message.ResponseIdentifier = response.ResponseMessageIdentifier
+	message.SubscriptionIdentifiers = []string{response.SubscriptionIdentifier}
+	message.Lines = lineRefs
+
+	logStashEvent["siriType"] = "NotifyProductionTimetable"
+	logStashEvent["producerRef"] = response.ProducerRef
+	logStashEvent["requestMessageRef"] = response.RequestMessageRef
+	logStashEvent["responseMessageIdentifier"] = response.ResponseMessageIdentifier
+	logStashEvent["responseTimestamp"] = response.ResponseTimestamp.String()
+	logStashEvent["subscriberRef"] = response.SubscriberRef
+	logStashEvent["subscriptionIdentifier"] = response.SubscriptionIdentifier
+	logStashEvent["status"] = strconv.FormatBool(response.Status)
+	if !response.Status {
+		message.Status = "Error"
+		logStashEvent["errorType"] = response.ErrorType
+		if response.ErrorType == "OtherError" {
+			logStashEvent["errorNumber"] = strconv.Itoa(response.ErrorNumber)
+		}
+		logStashEvent["errorText"] = response.ErrorText
+		message.ErrorDetails = response.ErrorString()
+	}
+	xml, err := response.BuildXML()
+	if err != nil {
+		logStashEvent["responseXML"] = fmt.Sprintf("%v", err)
+		return
+	}
+	logStashEvent["responseXML"] = xml
+	message.ResponseRawMessage = xml
+	message.ResponseSize = int64(len(xml))
+}
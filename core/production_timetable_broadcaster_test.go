@@ -0,0 +1,110 @@
+package core
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+func Test_ProductionTimetableBroadcaster_HandleModelReload(t *testing.T) {
+	fakeClock := clock.NewFakeClock()
+	clock.SetDefaultClock(fakeClock)
+	uuidGenerator := uuid.NewFakeUUIDGenerator()
+
+	response := []byte{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, _ = ioutil.ReadAll(r.Body)
+		w.Header().Add("Content-Type", "text/xml")
+	}))
+	defer ts.Close()
+
+	referentials := NewMemoryReferentials()
+	referential := referentials.New("referential")
+	referential.SetClock(fakeClock)
+
+	partner := referential.Partners().New("partner")
+	partner.SetSetting("remote_objectid_kind", "internal")
+	partner.SetSetting("remote_credential", "external")
+	partner.SetSetting("local_credential", "local")
+	partner.SetSetting("remote_url", ts.URL)
+
+	partner.ConnectorTypes = []string{SIRI_PRODUCTION_TIMETABLE_SUBSCRIPTION_BROADCASTER}
+	partner.RefreshConnectors()
+	referential.Partners().Save(partner)
+
+	c, _ := partner.Connector(SIRI_PRODUCTION_TIMETABLE_SUBSCRIPTION_BROADCASTER)
+	connector := c.(*SIRIProductionTimetableSubscriptionBroadcaster)
+	connector.Partner().SetUUIDGenerator(uuidGenerator)
+	connector.SetClock(fakeClock)
+	connector.productionTimetableBroadcaster = NewFakeProductionTimetableBroadcaster(connector)
+	partner.Subscriptions().SetUUIDGenerator(uuidGenerator)
+
+	line := referential.Model().Lines().New()
+	lineObjectId := model.NewObjectID("internal", "Line:1")
+	line.SetObjectID(lineObjectId)
+	line.Save()
+
+	stopArea := referential.Model().StopAreas().New()
+	stopArea.SetObjectID(model.NewObjectID("internal", "StopArea:1"))
+	stopArea.Save()
+
+	vehicleJourney := referential.Model().VehicleJourneys().New()
+	vehicleJourney.SetObjectID(model.NewObjectID("internal", "VehicleJourney:1"))
+	vehicleJourney.LineId = line.Id()
+	vehicleJourney.Save()
+
+	stopVisit := referential.Model().StopVisits().New()
+	stopVisit.VehicleJourneyId = vehicleJourney.Id()
+	stopVisit.StopAreaId = stopArea.Id()
+	stopVisit.PassageOrder = 1
+	stopVisit.Schedules.SetDepartureTime("aimed", fakeClock.Now().Add(1*time.Hour))
+	stopVisit.Save()
+
+	subscription := partner.Subscriptions().New("ProductionTimetableBroadcast")
+	subscription.SetExternalId("externalId")
+	subscription.SetSubscriptionOption("MessageIdentifier", "MessageIdentifier")
+	resource := subscription.CreateAddNewResource(model.Reference{ObjectId: &lineObjectId, Type: "Line"})
+	resource.SubscribedUntil = fakeClock.Now().Add(24 * time.Hour)
+	subscription.Save()
+
+	connector.PrepareModelReload()
+	partner.Stop()
+
+	if _, ok := partner.Subscriptions().FindByExternalId("externalId"); ok {
+		t.Fatalf("Subscription should be cancelled when the partner is stopped")
+	}
+
+	partner.Start()
+	connector.HandleModelReload()
+
+	if _, ok := partner.Subscriptions().FindByExternalId("externalId"); !ok {
+		t.Fatalf("Subscription should be restored after the model reload")
+	}
+	if l := len(connector.toBroadcast); l != 1 {
+		t.Fatalf("should have 1 subscription to broadcast got : %v", l)
+	}
+
+	connector.productionTimetableBroadcaster.Start()
+
+	if !strings.Contains(string(response), "<sw:NotifyProductionTimetable") {
+		t.Fatalf("Should send a NotifyProductionTimetable, got: %v", string(response))
+	}
+	for _, expected := range []string{
+		"<siri:SubscriptionRef>externalId</siri:SubscriptionRef>",
+		"<siri:LineRef>Line:1</siri:LineRef>",
+		"<siri:DatedVehicleJourneyCode>VehicleJourney:1</siri:DatedVehicleJourneyCode>",
+		"<siri:StopPointRef>StopArea:1</siri:StopPointRef>",
+		"<siri:AimedDepartureTime>1984-04-04T01:00:00.000Z</siri:AimedDepartureTime>",
+	} {
+		if !strings.Contains(string(response), expected) {
+			t.Errorf("Notify should contain %v, got: %v", expected, string(response))
+		}
+	}
+}
@@ -60,7 +60,8 @@ func (pc *PushCollector) HandlePushNotification(model *external_models.ExternalC
 	pc.handleLines(model.GetLines())
 	pc.handleVehicleJourneys(model.GetVehicleJourneys())
 	pc.handleStopVisits(model.GetStopVisits())
-	pc.handleVehicles(model.GetVehicles())
+	vehicles := pc.handleVehicles(model.GetVehicles())
+	pc.estimateFromVehicles(vehicles)
 
 	processingTime := clock.DefaultClock().Since(t)
 
@@ -133,6 +134,8 @@ func (pc *PushCollector) handleVehicleJourneys(vjs []*external_models.ExternalVe
 		event.DestinationRef = vj.GetDestinationRef()
 		event.DestinationName = vj.GetDestinationName()
 		event.Direction = vj.GetDirection()
+		// ExternalVehicleJourney doesn't define Cancellation and ExtraJourney
+		// yet: FlagsDefined is false to keep the collected values
 
 		pc.broadcastUpdateEvent(event)
 	}
@@ -160,12 +163,15 @@ func (pc *PushCollector) handleStopVisits(svs []*external_models.ExternalStopVis
 }
 
 func (pc *PushCollector) handleVehicles(vs []*external_models.ExternalVehicle) (vehicles []string) {
+	partner := string(pc.Partner().Slug())
 	id_kind := pc.Partner().Setting(REMOTE_OBJECTID_KIND)
 
 	for i := range vs {
 		v := vs[i]
 		event := model.NewVehicleUpdateEvent()
 
+		event.Origin = partner
+
 		event.ObjectId = model.NewObjectID(id_kind, v.GetObjectid())
 		event.VehicleJourneyObjectId = model.NewObjectID(id_kind, v.GetVehicleJourneyRef())
 		event.Longitude = v.GetLongitude()
@@ -179,6 +185,26 @@ func (pc *PushCollector) handleVehicles(vs []*external_models.ExternalVehicle) (
 	return
 }
 
+func (pc *PushCollector) estimateFromVehicles(vehicles []string) {
+	if len(vehicles) == 0 || !pc.Partner().VehiclePositionsEstimation() {
+		return
+	}
+
+	id_kind := pc.Partner().Setting(REMOTE_OBJECTID_KIND)
+	estimator := NewVehiclePositionEstimator(pc.Partner().Referential(), string(pc.Partner().Slug()))
+
+	tx := pc.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	for _, v := range vehicles {
+		vehicle, ok := tx.Model().Vehicles().FindByObjectId(model.NewObjectID(id_kind, v))
+		if !ok {
+			continue
+		}
+		estimator.Estimate(vehicle.Id())
+	}
+}
+
 func handleSchedules(sc *model.StopVisitSchedules, protoDeparture, protoArrival *external_models.ExternalStopVisit_Times) {
 	sc.SetSchedule(model.STOP_VISIT_SCHEDULE_AIMED, convertProtoTimes(protoDeparture.GetAimed()), convertProtoTimes(protoArrival.GetAimed()))
 	sc.SetSchedule(model.STOP_VISIT_SCHEDULE_ACTUAL, convertProtoTimes(protoDeparture.GetActual()), convertProtoTimes(protoArrival.GetActual()))
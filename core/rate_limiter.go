@@ -0,0 +1,96 @@
+package core
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/clock"
+)
+
+var (
+	ErrRateLimitExceeded         = errors.New("request rate limit exceeded")
+	ErrTooManyConcurrentRequests = errors.New("too many concurrent requests")
+)
+
+// Limits the requests received from a partner. The request rate is limited
+// with a token bucket refilled with requestsPerMinute tokens each minute.
+// A zero value disables the associated limit.
+type RateLimiter struct {
+	clock.ClockConsumer
+
+	mutex *sync.Mutex
+
+	requestsPerMinute     int
+	maxConcurrentRequests int
+
+	tokens             float64
+	refilledAt         time.Time
+	concurrentRequests int
+}
+
+func NewRateLimiter(requestsPerMinute, maxConcurrentRequests int) *RateLimiter {
+	return &RateLimiter{
+		mutex:                 &sync.Mutex{},
+		requestsPerMinute:     requestsPerMinute,
+		maxConcurrentRequests: maxConcurrentRequests,
+		tokens:                float64(requestsPerMinute),
+	}
+}
+
+// Release must be called at the end of each acquired request
+func (limiter *RateLimiter) Acquire() error {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	if limiter.maxConcurrentRequests != 0 && limiter.concurrentRequests >= limiter.maxConcurrentRequests {
+		return ErrTooManyConcurrentRequests
+	}
+
+	if limiter.requestsPerMinute != 0 {
+		limiter.refill()
+		if limiter.tokens < 1 {
+			return ErrRateLimitExceeded
+		}
+		limiter.tokens--
+	}
+
+	limiter.concurrentRequests++
+	return nil
+}
+
+func (limiter *RateLimiter) Release() {
+	limiter.mutex.Lock()
+	if limiter.concurrentRequests > 0 {
+		limiter.concurrentRequests--
+	}
+	limiter.mutex.Unlock()
+}
+
+// Returns the delay before a new request can be accepted by the rate limit.
+// A default delay of one second is returned for the concurrent requests,
+// which can't be predicted.
+func (limiter *RateLimiter) RetryAfter() time.Duration {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	if limiter.requestsPerMinute == 0 {
+		return time.Second
+	}
+	limiter.refill()
+	if limiter.tokens >= 1 {
+		return time.Second
+	}
+	return time.Duration((1 - limiter.tokens) / float64(limiter.requestsPerMinute) * float64(time.Minute))
+}
+
+func (limiter *RateLimiter) refill() {
+	now := limiter.Clock().Now()
+	if !limiter.refilledAt.IsZero() {
+		limiter.tokens += now.Sub(limiter.refilledAt).Minutes() * float64(limiter.requestsPerMinute)
+		if limiter.tokens > float64(limiter.requestsPerMinute) {
+			limiter.tokens = float64(limiter.requestsPerMinute)
+		}
+	}
+	limiter.refilledAt = now
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/clock"
+)
+
+func Test_RateLimiter_RequestsPerMinute(t *testing.T) {
+	fakeClock := clock.NewFakeClock()
+	limiter := NewRateLimiter(2, 0)
+	limiter.SetClock(fakeClock)
+
+	for i := 0; i < 2; i++ {
+		if err := limiter.Acquire(); err != nil {
+			t.Fatalf("Request %d should be accepted, got %v", i, err)
+		}
+		limiter.Release()
+	}
+	if err := limiter.Acquire(); err != ErrRateLimitExceeded {
+		t.Errorf("Third request should exceed the rate limit, got %v", err)
+	}
+
+	if retryAfter := limiter.RetryAfter(); retryAfter != 30*time.Second {
+		t.Errorf("Wrong RetryAfter:\n got: %v\n want: 30s", retryAfter)
+	}
+
+	fakeClock.Advance(30 * time.Second)
+	if err := limiter.Acquire(); err != nil {
+		t.Errorf("Request should be accepted after 30 seconds, got %v", err)
+	}
+	limiter.Release()
+	if err := limiter.Acquire(); err != ErrRateLimitExceeded {
+		t.Errorf("Request should exceed the rate limit, got %v", err)
+	}
+}
+
+func Test_RateLimiter_MaxConcurrentRequests(t *testing.T) {
+	limiter := NewRateLimiter(0, 1)
+
+	if err := limiter.Acquire(); err != nil {
+		t.Fatalf("First request should be accepted, got %v", err)
+	}
+	if err := limiter.Acquire(); err != ErrTooManyConcurrentRequests {
+		t.Errorf("Second concurrent request should be rejected, got %v", err)
+	}
+
+	limiter.Release()
+	if err := limiter.Acquire(); err != nil {
+		t.Errorf("Request should be accepted once the first one is released, got %v", err)
+	}
+}
+
+func Test_PartnerSettings_RateLimiter(t *testing.T) {
+	partner := NewPartner()
+	partner.SetSetting(RATE_LIMIT_MAX_CONCURRENT_REQUESTS, "1")
+
+	limiter := partner.RateLimiter()
+	if limiter != partner.RateLimiter() {
+		t.Errorf("RateLimiter should be kept between requests")
+	}
+	if limiter.maxConcurrentRequests != 1 {
+		t.Errorf("Wrong maxConcurrentRequests: %v", limiter.maxConcurrentRequests)
+	}
+
+	partner.SetSetting(RATE_LIMIT_MAX_CONCURRENT_REQUESTS, "2")
+	if partner.RateLimiter().maxConcurrentRequests != 2 {
+		t.Errorf("RateLimiter should be created again when the settings change")
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"time"
@@ -22,7 +25,9 @@ type ReferentialId string
 type ReferentialSlug string
 
 const (
-	REFERENTIAL_SETTING_MODEL_RELOAD_AT = "model.reload_at"
+	REFERENTIAL_SETTING_MODEL_RELOAD_AT               = "model.reload_at"
+	REFERENTIAL_SETTING_HISTORY_MAX_ENTRIES           = "history.max_entries"
+	REFERENTIAL_SETTING_HISTORY_MAX_ENTRIES_BY_OBJECT = "history.max_entries_by_object"
 )
 
 // Validation
@@ -43,6 +48,7 @@ type Referential struct {
 	manager           Referentials
 	model             *model.MemoryModel
 	modelGuardian     *ModelGuardian
+	modelStreams      *ModelStreams
 	partners          Partners
 	startedAt         time.Time
 	nextReloadAt      time.Time
@@ -133,6 +139,10 @@ func (referential *Referential) ModelGuardian() *ModelGuardian {
 	return referential.modelGuardian
 }
 
+func (referential *Referential) ModelStreams() *ModelStreams {
+	return referential.modelStreams
+}
+
 func (referential *Referential) Partners() Partners {
 	return referential.partners
 }
@@ -148,6 +158,9 @@ func (referential *Referential) DatabaseOrganisationId() sql.NullString {
 }
 
 func (referential *Referential) Start() {
+	if referential.startedAt.IsZero() {
+		referential.loadSnapshot()
+	}
 	referential.startedAt = referential.Clock().Now()
 
 	// Configure BigQuery
@@ -157,17 +170,33 @@ func (referential *Referential) Start() {
 		audit.CurrentBigQuery(string(referential.slug)).Start()
 	}
 
+	referential.model.History().SetLimits(referential.HistoryLimits())
+
 	referential.partners.Start()
 	referential.modelGuardian.Start()
 
 	referential.broacasterManager = NewBroadcastManager(referential)
 	referential.model.SetBroadcastSMChan(referential.broacasterManager.GetStopMonitoringBroadcastEventChan())
 	referential.model.SetBroadcastGMChan(referential.broacasterManager.GetGeneralMessageBroadcastEventChan())
+	referential.model.SetBroadcastStreamChan(referential.broacasterManager.GetModelStreamEventChan())
 
 	referential.broacasterManager.Start()
 
 }
 
+// Returns the maximum number of History entries, in total and by model
+// object. The History is reset when the Model is reloaded.
+func (referential *Referential) HistoryLimits() (maxEntries, maxEntriesByObject int) {
+	maxEntries, maxEntriesByObject = model.DEFAULT_HISTORY_MAX_ENTRIES, model.DEFAULT_HISTORY_MAX_ENTRIES_BY_OBJECT
+	if limit, err := strconv.Atoi(referential.Setting(REFERENTIAL_SETTING_HISTORY_MAX_ENTRIES)); err == nil && limit > 0 {
+		maxEntries = limit
+	}
+	if limit, err := strconv.Atoi(referential.Setting(REFERENTIAL_SETTING_HISTORY_MAX_ENTRIES_BY_OBJECT)); err == nil && limit > 0 {
+		maxEntriesByObject = limit
+	}
+	return
+}
+
 func (referential *Referential) Stop() {
 	referential.partners.Stop()
 	referential.modelGuardian.Stop()
@@ -175,6 +204,64 @@ func (referential *Referential) Stop() {
 	audit.CurrentBigQuery(string(referential.slug)).Stop()
 }
 
+func (referential *Referential) snapshotPath() string {
+	if config.Config.SnapshotDirectory == "" {
+		return ""
+	}
+	return filepath.Join(config.Config.SnapshotDirectory, fmt.Sprintf("%v.json", referential.slug))
+}
+
+// Writes the live model in the snapshot directory
+func (referential *Referential) SaveSnapshot() error {
+	path := referential.snapshotPath()
+	if path == "" {
+		return nil
+	}
+
+	// Write in a temporary file to never leave a partial snapshot
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if err = referential.model.WriteSnapshot(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// Restores the live model saved before a restart if it is for the current
+// model Date
+func (referential *Referential) loadSnapshot() {
+	path := referential.snapshotPath()
+	if path == "" {
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Log.Printf("Can't read model snapshot of referential %v: %v", referential.slug, err)
+		}
+		return
+	}
+	defer file.Close()
+
+	restored, err := referential.model.ReadSnapshot(file)
+	if err != nil {
+		logger.Log.Printf("Can't restore model snapshot of referential %v: %v", referential.slug, err)
+		return
+	}
+	if restored {
+		logger.Log.Printf("Model of referential %v restored from snapshot", referential.slug)
+	}
+}
+
 func (referential *Referential) Save() (ok bool) {
 	ok = referential.manager.Save(referential)
 	return
@@ -246,10 +333,38 @@ func (referential *Referential) NextReloadAt() time.Time {
 
 func (referential *Referential) ReloadModel() {
 	logger.Log.Printf("Reset Model for referential %v", referential.slug)
+	ptConnectors := referential.productionTimetableConnectors()
+	for _, connector := range ptConnectors {
+		connector.PrepareModelReload()
+	}
+	// Subscriptions are cancelled when the partners are stopped
+	subscriptions := make(map[*Partner][]*Subscription)
+	for _, partner := range referential.partners.FindAll() {
+		subscriptions[partner] = partner.Subscriptions().FindAll()
+	}
 	referential.Stop()
 	referential.model = referential.model.Reload(string(referential.Slug()))
 	referential.setNextReloadAt()
 	referential.Start()
+	for partner, partnerSubscriptions := range subscriptions {
+		for _, subscription := range partnerSubscriptions {
+			partner.Subscriptions().Save(subscription)
+		}
+	}
+	for _, connector := range ptConnectors {
+		connector.HandleModelReload()
+	}
+}
+
+func (referential *Referential) productionTimetableConnectors() (connectors []*SIRIProductionTimetableSubscriptionBroadcaster) {
+	for _, partner := range referential.partners.FindAll() {
+		connector, ok := partner.Connector(SIRI_PRODUCTION_TIMETABLE_SUBSCRIPTION_BROADCASTER)
+		if !ok {
+			continue
+		}
+		connectors = append(connectors, connector.(*SIRIProductionTimetableSubscriptionBroadcaster))
+	}
+	return connectors
 }
 
 func (referential *Referential) setNextReloadAt() {
@@ -309,8 +424,10 @@ func (manager *MemoryReferentials) New(slug ReferentialSlug) *Referential {
 
 	referential.model.SetBroadcastSMChan(referential.broacasterManager.GetStopMonitoringBroadcastEventChan())
 	referential.model.SetBroadcastGMChan(referential.broacasterManager.GetGeneralMessageBroadcastEventChan())
+	referential.model.SetBroadcastStreamChan(referential.broacasterManager.GetModelStreamEventChan())
 
 	referential.modelGuardian = NewModelGuardian(referential)
+	referential.modelStreams = NewModelStreams(referential)
 	referential.setNextReloadAt()
 
 	return referential
@@ -432,6 +549,13 @@ func (manager *MemoryReferentials) SaveToDatabase() (int, error) {
 		return http.StatusInternalServerError, fmt.Errorf("database error: %v", err)
 	}
 
+	// Delete subscriptions
+	_, err = tx.Exec("delete from subscriptions where referential_id not in (select referential_id from referentials);")
+	if err != nil {
+		tx.Rollback()
+		return http.StatusInternalServerError, fmt.Errorf("database error: %v", err)
+	}
+
 	// Commit transaction
 	err = tx.Commit()
 	if err != nil {
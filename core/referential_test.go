@@ -2,11 +2,14 @@ package core
 
 import (
 	"database/sql"
+	"io/ioutil"
+	"os"
 	"reflect"
 	"testing"
 	"time"
 
 	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/config"
 	"bitbucket.org/enroute-mobi/ara/model"
 )
 
@@ -112,6 +115,34 @@ func Test_Referential_Save(t *testing.T) {
 	}
 }
 
+func Test_Referential_Snapshot(t *testing.T) {
+	directory, err := ioutil.TempDir("", "snapshots")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	config.Config.SnapshotDirectory = directory
+	defer func() { config.Config.SnapshotDirectory = "" }()
+
+	referential := NewMemoryReferentials().New("referential")
+	stopArea := referential.Model().StopAreas().New()
+	stopArea.SetObjectID(model.NewObjectID("kind", "value"))
+	stopArea.Save()
+
+	if err := referential.SaveSnapshot(); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted := NewMemoryReferentials().New("referential")
+	restarted.Start()
+	restarted.Stop()
+
+	if _, ok := restarted.Model().StopAreas().FindByObjectId(model.NewObjectID("kind", "value")); !ok {
+		t.Error("StopArea should be restored from the snapshot when the referential starts")
+	}
+}
+
 func Test_Referential_setNextReloadAt(t *testing.T) {
 	var conditions = []struct {
 		setting        string
@@ -142,6 +173,19 @@ func Test_Referential_setNextReloadAt(t *testing.T) {
 	}
 }
 
+func Test_Referential_HistoryLimits(t *testing.T) {
+	referential := &Referential{Settings: map[string]string{}}
+	if maxEntries, maxEntriesByObject := referential.HistoryLimits(); maxEntries != model.DEFAULT_HISTORY_MAX_ENTRIES || maxEntriesByObject != model.DEFAULT_HISTORY_MAX_ENTRIES_BY_OBJECT {
+		t.Errorf("Wrong default HistoryLimits: %v, %v", maxEntries, maxEntriesByObject)
+	}
+
+	referential.Settings[REFERENTIAL_SETTING_HISTORY_MAX_ENTRIES] = "1000"
+	referential.Settings[REFERENTIAL_SETTING_HISTORY_MAX_ENTRIES_BY_OBJECT] = "10"
+	if maxEntries, maxEntriesByObject := referential.HistoryLimits(); maxEntries != 1000 || maxEntriesByObject != 10 {
+		t.Errorf("Wrong HistoryLimits: %v, %v", maxEntries, maxEntriesByObject)
+	}
+}
+
 func Test_APIReferential_Validate(t *testing.T) {
 	referentials := NewMemoryReferentials()
 	// Check empty Slug
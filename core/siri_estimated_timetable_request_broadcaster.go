@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"bitbucket.org/enroute-mobi/ara/audit"
 	"bitbucket.org/enroute-mobi/ara/clock"
@@ -16,16 +17,28 @@ type EstimatedTimetableBroadcaster interface {
 	RequestLine(*siri.XMLGetEstimatedTimetable, *audit.BigQueryMessage) *siri.SIRIEstimatedTimeTableResponse
 }
 
+// Implemented by the SOAP and SIRI Lite requests
+type estimatedTimetableRequest interface {
+	MessageIdentifier() string
+	Lines() []string
+	PreviewInterval() time.Duration
+	StartTime() time.Time
+}
+
 type SIRIEstimatedTimetableBroadcaster struct {
 	clock.ClockConsumer
 
 	siriConnector
+
+	connectorType string
 }
 
 type SIRIEstimatedTimetableBroadcasterFactory struct{}
 
 func NewSIRIEstimatedTimetableBroadcaster(partner *Partner) *SIRIEstimatedTimetableBroadcaster {
-	broadcaster := &SIRIEstimatedTimetableBroadcaster{}
+	broadcaster := &SIRIEstimatedTimetableBroadcaster{
+		connectorType: SIRI_ESTIMATED_TIMETABLE_REQUEST_BROADCASTER,
+	}
 	broadcaster.partner = partner
 	return broadcaster
 }
@@ -61,7 +74,7 @@ func (connector *SIRIEstimatedTimetableBroadcaster) RequestLine(request *siri.XM
 	return response
 }
 
-func (connector *SIRIEstimatedTimetableBroadcaster) getEstimatedTimetableDelivery(tx *model.Transaction, request *siri.XMLEstimatedTimetableRequest, logStashEvent audit.LogStashEvent) siri.SIRIEstimatedTimetableDelivery {
+func (connector *SIRIEstimatedTimetableBroadcaster) getEstimatedTimetableDelivery(tx *model.Transaction, request estimatedTimetableRequest, logStashEvent audit.LogStashEvent) siri.SIRIEstimatedTimetableDelivery {
 	currentTime := connector.Clock().Now()
 	monitoringRefs := []string{}
 	lineRefs := []string{}
@@ -84,9 +97,14 @@ func (connector *SIRIEstimatedTimetableBroadcaster) getEstimatedTimetableDeliver
 	}
 	selector := model.CompositeStopVisitSelector(selectors)
 
+	var merger *Merger
+	if connector.Partner().MergeStopVisits() {
+		merger = NewMerger(connector.Partner().Referential(), tx)
+	}
+
 	// SIRIEstimatedJourneyVersionFrame
 	for _, lineId := range request.Lines() {
-		lineObjectId := model.NewObjectID(connector.partner.RemoteObjectIDKind(SIRI_ESTIMATED_TIMETABLE_REQUEST_BROADCASTER), lineId)
+		lineObjectId := model.NewObjectID(connector.partner.RemoteObjectIDKind(connector.connectorType), lineId)
 		line, ok := tx.Model().Lines().FindByObjectId(lineObjectId)
 		if !ok {
 			logger.Log.Debugf("Cannot find requested line Estimated Time Table with id %v at %v", lineObjectId.String(), connector.Clock().Now())
@@ -97,10 +115,29 @@ func (connector *SIRIEstimatedTimetableBroadcaster) getEstimatedTimetableDeliver
 			RecordedAtTime: currentTime,
 		}
 
+		var vehicleJourneys []model.VehicleJourney
+		if merger != nil {
+			vehicleJourneys = merger.LineVehicleJourneys(line.Id())
+		} else {
+			vehicleJourneys = tx.Model().VehicleJourneys().FindByLineId(line.Id())
+		}
+		stopVisits := make(map[model.VehicleJourneyId][]model.StopVisit)
+		for _, vehicleJourney := range vehicleJourneys {
+			stopVisits[vehicleJourney.Id()] = tx.Model().StopVisits().FindFollowingByVehicleJourneyId(vehicleJourney.Id())
+		}
+		var merged map[model.VehicleJourneyId]struct{}
+		if merger != nil {
+			merged = merger.MergeVehicleJourneys(stopVisits)
+		}
+
 		// SIRIEstimatedVehicleJourney
-		for _, vehicleJourney := range tx.Model().VehicleJourneys().FindByLineId(line.Id()) {
+		for _, vehicleJourney := range vehicleJourneys {
+			if _, ok := merged[vehicleJourney.Id()]; ok {
+				continue
+			}
+
 			// Handle vehicleJourney Objectid
-			vehicleJourneyId, ok := vehicleJourney.ObjectID(connector.partner.RemoteObjectIDKind(SIRI_ESTIMATED_TIMETABLE_REQUEST_BROADCASTER))
+			vehicleJourneyId, ok := vehicleJourney.ObjectID(connector.partner.RemoteObjectIDKind(connector.connectorType))
 			var datedVehicleJourneyRef string
 			if ok {
 				datedVehicleJourneyRef = vehicleJourneyId.Value()
@@ -117,6 +154,8 @@ func (connector *SIRIEstimatedTimetableBroadcaster) getEstimatedTimetableDeliver
 			estimatedVehicleJourney := &siri.SIRIEstimatedVehicleJourney{
 				LineRef:                lineObjectId.Value(),
 				DatedVehicleJourneyRef: datedVehicleJourneyRef,
+				ExtraJourney:           vehicleJourney.ExtraJourney,
+				Cancellation:           vehicleJourney.Cancellation,
 				Attributes:             make(map[string]string),
 				References:             make(map[string]string),
 			}
@@ -125,13 +164,17 @@ func (connector *SIRIEstimatedTimetableBroadcaster) getEstimatedTimetableDeliver
 			estimatedVehicleJourney.Attributes = vehicleJourney.Attributes
 
 			// SIRIEstimatedCall
-			for _, stopVisit := range tx.Model().StopVisits().FindFollowingByVehicleJourneyId(vehicleJourney.Id()) {
+			for _, stopVisit := range stopVisits[vehicleJourney.Id()] {
 				if !selector(stopVisit) {
 					continue
 				}
 
 				// Handle StopPointRef
-				stopArea, stopAreaId, ok := connector.stopPointRef(stopVisit.StopAreaId, tx)
+				stopPointRefId := stopVisit.StopAreaId
+				if merger != nil {
+					stopPointRefId = merger.StopAreaWithObjectIDKind(stopPointRefId, connector.partner.RemoteObjectIDKind(connector.connectorType))
+				}
+				stopArea, stopAreaId, ok := connector.stopPointRef(stopPointRefId, tx)
 				if !ok {
 					logger.Log.Printf("Ignore StopVisit %v without StopArea or with StopArea without correct ObjectID", stopVisit.Id())
 					continue
@@ -153,8 +196,8 @@ func (connector *SIRIEstimatedTimetableBroadcaster) getEstimatedTimetableDeliver
 				}
 
 				if stopArea.Monitored {
-					estimatedCall.ExpectedArrivalTime = stopVisit.Schedules.Schedule("expected").ArrivalTime()
-					estimatedCall.ExpectedDepartureTime = stopVisit.Schedules.Schedule("expected").DepartureTime()
+					estimatedCall.ExpectedArrivalTime = stopVisit.ExpectedOrPredictedArrivalTime()
+					estimatedCall.ExpectedDepartureTime = stopVisit.ExpectedOrPredictedDepartureTime()
 				} else {
 					delivery.Status = false
 					delivery.ErrorType = "OtherError"
@@ -183,13 +226,13 @@ func (connector *SIRIEstimatedTimetableBroadcaster) stopPointRef(stopAreaId mode
 	if !ok {
 		return model.StopArea{}, "", false
 	}
-	stopPointRefObjectId, ok := stopPointRef.ObjectID(connector.partner.RemoteObjectIDKind(SIRI_ESTIMATED_TIMETABLE_REQUEST_BROADCASTER))
+	stopPointRefObjectId, ok := stopPointRef.ObjectID(connector.partner.RemoteObjectIDKind(connector.connectorType))
 	if ok {
 		return stopPointRef, stopPointRefObjectId.Value(), true
 	}
 	referent, ok := stopPointRef.Referent()
 	if ok {
-		referentObjectId, ok := referent.ObjectID(connector.partner.RemoteObjectIDKind(SIRI_ESTIMATED_TIMETABLE_REQUEST_BROADCASTER))
+		referentObjectId, ok := referent.ObjectID(connector.partner.RemoteObjectIDKind(connector.connectorType))
 		if ok {
 			return referent, referentObjectId.Value(), true
 		}
@@ -210,14 +253,14 @@ func (connector *SIRIEstimatedTimetableBroadcaster) getEstimatedVehicleJourneyRe
 			continue
 		}
 		if foundStopArea, ok := tx.Model().StopAreas().FindByObjectId(*ref.ObjectId); ok {
-			obj, ok := foundStopArea.ReferentOrSelfObjectId(connector.partner.RemoteObjectIDKind(SIRI_ESTIMATED_TIMETABLE_REQUEST_BROADCASTER))
+			obj, ok := foundStopArea.ReferentOrSelfObjectId(connector.partner.RemoteObjectIDKind(connector.connectorType))
 			if ok {
 				references[refType] = obj.Value()
 				continue
 			}
 		}
 		generator := connector.Partner().IdentifierGenerator(REFERENCE_STOP_AREA_IDENTIFIER)
-		defaultObjectID := model.NewObjectID(connector.partner.RemoteObjectIDKind(SIRI_ESTIMATED_TIMETABLE_REQUEST_BROADCASTER), generator.NewIdentifier(IdentifierAttributes{Id: ref.GetSha1()}))
+		defaultObjectID := model.NewObjectID(connector.partner.RemoteObjectIDKind(connector.connectorType), generator.NewIdentifier(IdentifierAttributes{Id: ref.GetSha1()}))
 		references[refType] = defaultObjectID.Value()
 	}
 
@@ -247,7 +290,7 @@ func (connector *SIRIEstimatedTimetableBroadcaster) resolveOperatorRef(refs map[
 		refs["OperatorRef"] = operatorRef.ObjectId.Value()
 		return
 	}
-	obj, ok := operator.ObjectID(connector.partner.RemoteObjectIDKind(SIRI_ESTIMATED_TIMETABLE_REQUEST_BROADCASTER))
+	obj, ok := operator.ObjectID(connector.partner.RemoteObjectIDKind(connector.connectorType))
 	if !ok {
 		refs["OperatorRef"] = operatorRef.ObjectId.Value()
 		return
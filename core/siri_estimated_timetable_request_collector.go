@@ -0,0 +1,207 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+type EstimatedTimetableRequestCollector interface {
+	RequestLineUpdate(request *LineUpdateRequest)
+}
+
+type SIRIEstimatedTimetableRequestCollector struct {
+	clock.ClockConsumer
+	uuid.UUIDConsumer
+
+	siriConnector
+
+	updateSubscriber UpdateSubscriber
+}
+
+type SIRIEstimatedTimetableRequestCollectorFactory struct{}
+
+func NewSIRIEstimatedTimetableRequestCollector(partner *Partner) *SIRIEstimatedTimetableRequestCollector {
+	connector := &SIRIEstimatedTimetableRequestCollector{}
+	connector.partner = partner
+	manager := partner.Referential().CollectManager()
+	connector.updateSubscriber = manager.BroadcastUpdateEvent
+
+	return connector
+}
+
+func (connector *SIRIEstimatedTimetableRequestCollector) RequestLineUpdate(request *LineUpdateRequest) {
+	tx := connector.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	line, ok := tx.Model().Lines().Find(request.LineId())
+	if !ok {
+		logger.Log.Debugf("LineUpdateRequest in EstimatedTimetableRequestCollector for unknown Line %v", request.LineId())
+		return
+	}
+
+	objectidKind := connector.partner.Setting(REMOTE_OBJECTID_KIND)
+	objectid, ok := line.ObjectID(objectidKind)
+	if !ok {
+		logger.Log.Debugf("Requested line %v doesn't have and objectId of kind %v", request.LineId(), objectidKind)
+		return
+	}
+
+	logStashEvent := connector.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+
+	message := connector.newBQEvent()
+	defer audit.CurrentBigQuery(string(connector.Partner().Referential().Slug())).WriteEvent(message)
+
+	startTime := connector.Clock().Now()
+
+	siriEstimatedTimetableRequest := siri.NewSIRIGetEstimatedTimetableRequest(
+		connector.Partner().IdentifierGenerator(MESSAGE_IDENTIFIER).NewMessageIdentifier(),
+		[]string{objectid.Value()},
+		connector.SIRIPartner().RequestorRef(),
+		connector.Clock().Now(),
+	)
+
+	logSIRIEstimatedTimetableRequest(logStashEvent, message, siriEstimatedTimetableRequest)
+
+	xmlEstimatedTimetableResponse, err := connector.SIRIPartner().SOAPClient().EstimatedTimetable(siriEstimatedTimetableRequest)
+	logStashEvent["responseTime"] = connector.Clock().Since(startTime).String()
+	message.ProcessingTime = connector.Clock().Since(startTime).Seconds()
+	if err != nil {
+		e := fmt.Sprintf("Error during EstimatedTimetable request: %v", err)
+		logStashEvent["status"] = "false"
+		logStashEvent["errorDescription"] = e
+		message.Status = "Error"
+		message.ErrorDetails = e
+		return
+	}
+
+	logXMLEstimatedTimetableResponse(logStashEvent, message, xmlEstimatedTimetableResponse)
+
+	builder := NewEstimatedTimetableUpdateEventBuilder(connector.partner)
+
+	for _, delivery := range xmlEstimatedTimetableResponse.EstimatedTimetableDeliveries() {
+		if !delivery.Status() {
+			continue
+		}
+		builder.SetUpdateEvents(delivery.EstimatedVehicleJourneys())
+	}
+
+	updateEvents := builder.UpdateEvents()
+
+	logEstimatedTimetableRefs(logStashEvent, updateEvents.VehicleJourneyRefs)
+
+	connector.broadcastUpdateEvents(&updateEvents)
+}
+
+func (connector *SIRIEstimatedTimetableRequestCollector) broadcastUpdateEvents(events *EstimatedTimetableUpdateEvents) {
+	broadcastEstimatedTimetableUpdateEvents(connector.updateSubscriber, events)
+}
+
+func (connector *SIRIEstimatedTimetableRequestCollector) SetUpdateSubscriber(updateSubscriber UpdateSubscriber) {
+	connector.updateSubscriber = updateSubscriber
+}
+
+func (connector *SIRIEstimatedTimetableRequestCollector) newBQEvent() *audit.BigQueryMessage {
+	return &audit.BigQueryMessage{
+		Type:      "EstimatedTimetableRequest",
+		Protocol:  "siri",
+		Direction: "sent",
+		Partner:   string(connector.partner.Slug()),
+		Status:    "OK",
+	}
+}
+
+func (connector *SIRIEstimatedTimetableRequestCollector) newLogStashEvent() audit.LogStashEvent {
+	event := connector.partner.NewLogStashEvent()
+	event["connector"] = "EstimatedTimetableRequestCollector"
+	return event
+}
+
+func (factory *SIRIEstimatedTimetableRequestCollectorFactory) Validate(apiPartner *APIPartner) {
+	apiPartner.ValidatePresenceOfSetting(REMOTE_OBJECTID_KIND)
+	apiPartner.ValidatePresenceOfSetting(REMOTE_URL)
+	apiPartner.ValidatePresenceOfSetting(REMOTE_CREDENTIAL)
+}
+
+func (factory *SIRIEstimatedTimetableRequestCollectorFactory) CreateConnector(partner *Partner) Connector {
+	return NewSIRIEstimatedTimetableRequestCollector(partner)
+}
+
+// The UpdateManager needs the StopAreas, Lines and VehicleJourneys before the StopVisits
+func broadcastEstimatedTimetableUpdateEvents(updateSubscriber UpdateSubscriber, events *EstimatedTimetableUpdateEvents) {
+	if updateSubscriber == nil {
+		return
+	}
+	for _, e := range events.StopAreas {
+		updateSubscriber(e)
+	}
+	for _, e := range events.Lines {
+		updateSubscriber(e)
+	}
+	for _, e := range events.VehicleJourneys {
+		updateSubscriber(e)
+	}
+	for _, e := range events.StopVisits {
+		updateSubscriber(e)
+	}
+}
+
+func logSIRIEstimatedTimetableRequest(logStashEvent audit.LogStashEvent, message *audit.BigQueryMessage, request *siri.SIRIGetEstimatedTimetableRequest) {
+	logStashEvent["siriType"] = "EstimatedTimetableRequest"
+	logStashEvent["messageIdentifier"] = request.MessageIdentifier
+	logStashEvent["lineRefs"] = strings.Join(request.Lines, ", ")
+	logStashEvent["requestorRef"] = request.RequestorRef
+	logStashEvent["requestTimestamp"] = request.RequestTimestamp.String()
+	xml, err := request.BuildXML()
+	if err != nil {
+		logStashEvent["requestXML"] = fmt.Sprintf("%v", err)
+		return
+	}
+	logStashEvent["requestXML"] = xml
+
+	message.RequestIdentifier = request.MessageIdentifier
+	message.RequestRawMessage = xml
+	message.RequestSize = int64(len(xml))
+	message.Lines = request.Lines
+}
+
+func logXMLEstimatedTimetableResponse(logStashEvent audit.LogStashEvent, message *audit.BigQueryMessage, response *siri.XMLEstimatedTimetableResponse) {
+	logStashEvent["address"] = response.Address()
+	logStashEvent["producerRef"] = response.ProducerRef()
+	logStashEvent["requestMessageRef"] = response.RequestMessageRef()
+	logStashEvent["responseMessageIdentifier"] = response.ResponseMessageIdentifier()
+	logStashEvent["responseTimestamp"] = response.ResponseTimestamp().String()
+	logStashEvent["responseXML"] = response.RawXML()
+	status := "true"
+	errorCount := 0
+	for _, delivery := range response.EstimatedTimetableDeliveries() {
+		if !delivery.Status() {
+			message.Status = "Error"
+			status = "false"
+			errorCount++
+		}
+	}
+	logStashEvent["status"] = status
+	logStashEvent["errorCount"] = strconv.Itoa(errorCount)
+
+	message.ResponseIdentifier = response.ResponseMessageIdentifier()
+	message.ResponseRawMessage = response.RawXML()
+	message.ResponseSize = int64(len(message.ResponseRawMessage))
+}
+
+func logEstimatedTimetableRefs(logStashEvent audit.LogStashEvent, refs map[string]struct{}) {
+	refSlice := make([]string, len(refs))
+	i := 0
+	for vehicleJourneyRef := range refs {
+		refSlice[i] = vehicleJourneyRef
+		i++
+	}
+	logStashEvent["vehicleJourneyRefs"] = strings.Join(refSlice, ", ")
+}
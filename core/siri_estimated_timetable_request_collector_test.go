@@ -0,0 +1,140 @@
+package core
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
+)
+
+func prepare_SIRIEstimatedTimetableRequestCollector(t *testing.T, settings map[string]string) []model.UpdateEvent {
+	audit.SetCurrentLogstash(audit.NewFakeLogStash())
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, err := os.Open("testdata/estimatedtimetable-response-soap.xml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		io.Copy(w, file)
+	}))
+	defer ts.Close()
+
+	settings["remote_url"] = ts.URL
+	settings["remote_objectid_kind"] = "test"
+
+	partners := createTestPartnerManager()
+	partner := partners.New("slug")
+	partner.SetSettingsDefinition(settings)
+	partners.Save(partner)
+
+	line := partners.Model().Lines().New()
+	line.SetObjectID(model.NewObjectID("test", "RLA:Line:1:LOC"))
+	partners.Model().Lines().Save(&line)
+
+	connector := NewSIRIEstimatedTimetableRequestCollector(partner)
+	connector.SetClock(clock.NewFakeClock())
+	collectManager := NewTestCollectManager().(*TestCollectManager)
+	connector.SetUpdateSubscriber(collectManager.TestUpdateSubscriber)
+
+	connector.RequestLineUpdate(NewLineUpdateRequest(line.Id()))
+
+	return collectManager.UpdateEvents
+}
+
+func Test_SIRIEstimatedTimetableRequestCollectorFactory_Validate(t *testing.T) {
+	partner := &Partner{
+		slug:           "partner",
+		ConnectorTypes: []string{SIRI_ESTIMATED_TIMETABLE_REQUEST_COLLECTOR},
+		connectors:     make(map[string]Connector),
+		manager:        NewPartnerManager(nil),
+	}
+	partner.PartnerSettings = NewPartnerSettings(partner)
+	apiPartner := partner.Definition()
+	apiPartner.Validate()
+	if apiPartner.Errors.Empty() {
+		t.Errorf("apiPartner should have errors when remote_url, remote_credential and remote_objectid_kind aren't set, got: %v", apiPartner.Errors)
+	}
+
+	apiPartner.Settings = map[string]string{
+		"remote_url":           "remote_url",
+		"remote_credential":    "remote_credential",
+		"remote_objectid_kind": "remote_objectid_kind",
+	}
+	apiPartner.Validate()
+	if !apiPartner.Errors.Empty() {
+		t.Errorf("apiPartner shouldn't have any error when remote_url, remote_credential and remote_objectid_kind are set, got: %v", apiPartner.Errors)
+	}
+}
+
+func Test_SIRIEstimatedTimetableRequestCollector_RequestLineUpdate(t *testing.T) {
+	events := prepare_SIRIEstimatedTimetableRequestCollector(t, map[string]string{
+		"collect.include_lines": "RLA:Line:1:LOC",
+	})
+
+	var stopVisitEvents = make(map[string]*model.StopVisitUpdateEvent)
+	var vehicleJourneyEvents []*model.VehicleJourneyUpdateEvent
+	var stopAreaEvents, lineEvents int
+	for i, event := range events {
+		switch e := event.(type) {
+		case *model.StopAreaUpdateEvent:
+			stopAreaEvents++
+		case *model.LineUpdateEvent:
+			lineEvents++
+		case *model.VehicleJourneyUpdateEvent:
+			vehicleJourneyEvents = append(vehicleJourneyEvents, e)
+		case *model.StopVisitUpdateEvent:
+			if len(stopVisitEvents) == 0 && i < stopAreaEvents+lineEvents+len(vehicleJourneyEvents) {
+				t.Errorf("StopVisit events should be broadcasted after the other events")
+			}
+			stopVisitEvents[e.ObjectId.Value()] = e
+		}
+	}
+
+	if stopAreaEvents != 3 || lineEvents != 1 || len(vehicleJourneyEvents) != 2 || len(stopVisitEvents) != 4 {
+		t.Fatalf("Wrong number of events: %v stop areas, %v lines, %v vehicle journeys, %v stop visits", stopAreaEvents, lineEvents, len(vehicleJourneyEvents), len(stopVisitEvents))
+	}
+
+	recorded, ok := stopVisitEvents["RLA:VehicleJourney:1:LOC-1"]
+	if !ok {
+		t.Fatalf("Missing StopVisit event for the RecordedCall, got: %v", stopVisitEvents)
+	}
+	if expected := time.Date(2017, time.January, 1, 11, 52, 0, 0, time.UTC); !recorded.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_ACTUAL).DepartureTime().Equal(expected) {
+		t.Errorf("Wrong actual departure time:\n got: %v\n want: %v", recorded.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_ACTUAL).DepartureTime(), expected)
+	}
+	if expected := model.NewObjectID("test", "RLA:StopPoint:q:1:LOC"); recorded.StopAreaObjectId != expected {
+		t.Errorf("Wrong StopAreaObjectId:\n got: %v\n want: %v", recorded.StopAreaObjectId, expected)
+	}
+
+	estimated := stopVisitEvents["RLA:VehicleJourney:1:LOC-3"]
+	if expected := time.Date(2017, time.January, 1, 12, 12, 0, 0, time.UTC); !estimated.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_EXPECTED).ArrivalTime().Equal(expected) {
+		t.Errorf("Wrong expected arrival time:\n got: %v\n want: %v", estimated.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_EXPECTED).ArrivalTime(), expected)
+	}
+	if estimated.ArrivalStatus != model.STOP_VISIT_ARRIVAL_DELAYED {
+		t.Errorf("Wrong ArrivalStatus:\n got: %v\n want: %v", estimated.ArrivalStatus, model.STOP_VISIT_ARRIVAL_DELAYED)
+	}
+
+	if cancelled := stopVisitEvents["RLA:VehicleJourney:1:LOC-2"]; cancelled.ArrivalStatus != model.STOP_VISIT_ARRIVAL_CANCELLED {
+		t.Errorf("Cancelled EstimatedCall should have a cancelled ArrivalStatus, got: %v", cancelled.ArrivalStatus)
+	}
+	// The whole VehicleJourney is cancelled
+	if cancelled := stopVisitEvents["RLA:VehicleJourney:2:LOC-1"]; cancelled.DepartureStatus != model.STOP_VISIT_DEPARTURE_CANCELLED {
+		t.Errorf("StopVisit of a cancelled VehicleJourney should have a cancelled DepartureStatus, got: %v", cancelled.DepartureStatus)
+	}
+}
+
+func Test_SIRIEstimatedTimetableRequestCollector_RequestLineUpdate_ExcludedLine(t *testing.T) {
+	events := prepare_SIRIEstimatedTimetableRequestCollector(t, map[string]string{
+		"collect.include_lines": "RLA:Line:2:LOC",
+	})
+
+	if len(events) != 0 {
+		t.Errorf("No event should be broadcasted for a Line not in collect.include_lines, got: %v", events)
+	}
+}
@@ -0,0 +1,247 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/state"
+)
+
+type SIRIEstimatedTimetableSubscriber interface {
+	state.Stopable
+	state.Startable
+}
+
+type ETSubscriber struct {
+	clock.ClockConsumer
+
+	connector *SIRIEstimatedTimetableSubscriptionCollector
+}
+
+type EstimatedTimetableSubscriber struct {
+	ETSubscriber
+
+	stop chan struct{}
+}
+
+type FakeEstimatedTimetableSubscriber struct {
+	ETSubscriber
+}
+
+func NewFakeEstimatedTimetableSubscriber(connector *SIRIEstimatedTimetableSubscriptionCollector) SIRIEstimatedTimetableSubscriber {
+	subscriber := &FakeEstimatedTimetableSubscriber{}
+	subscriber.connector = connector
+	return subscriber
+}
+
+func (subscriber *FakeEstimatedTimetableSubscriber) Start() {
+	subscriber.prepareSIRIEstimatedTimetableSubscriptionRequest()
+}
+
+func (subscriber *FakeEstimatedTimetableSubscriber) Stop() {}
+
+func NewSIRIEstimatedTimetableSubscriber(connector *SIRIEstimatedTimetableSubscriptionCollector) SIRIEstimatedTimetableSubscriber {
+	subscriber := &EstimatedTimetableSubscriber{}
+	subscriber.connector = connector
+	return subscriber
+}
+
+func (subscriber *EstimatedTimetableSubscriber) Start() {
+	logger.Log.Debugf("Start EstimatedTimetableSubscriber")
+
+	subscriber.stop = make(chan struct{})
+	go subscriber.run()
+}
+
+func (subscriber *EstimatedTimetableSubscriber) run() {
+	c := subscriber.Clock().After(5 * time.Second)
+
+	for {
+		select {
+		case <-subscriber.stop:
+			return
+		case <-c:
+			logger.Log.Debugf("SIRIEstimatedTimetableSubscriber visit")
+
+			subscriber.prepareSIRIEstimatedTimetableSubscriptionRequest()
+
+			c = subscriber.Clock().After(5 * time.Second)
+		}
+	}
+}
+
+func (subscriber *EstimatedTimetableSubscriber) Stop() {
+	if subscriber.stop != nil {
+		close(subscriber.stop)
+	}
+}
+
+func (subscriber *ETSubscriber) prepareSIRIEstimatedTimetableSubscriptionRequest() {
+	subscriptions := subscriber.connector.partner.Subscriptions().FindSubscriptionsByKind("EstimatedTimetableCollect")
+	if len(subscriptions) == 0 {
+		logger.Log.Debugf("EstimatedTimetableSubscriber visit without EstimatedTimetableCollect subscriptions")
+		return
+	}
+
+	// LineRef for Logstash
+	lineRefList := []string{}
+
+	linesToRequest := make(map[string]*lineToRequest)
+	for _, subscription := range subscriptions {
+		for _, resource := range subscription.ResourcesByObjectIDCopy() {
+			if resource.SubscribedAt.IsZero() && resource.RetryCount <= 10 {
+				messageIdentifier := subscriber.connector.Partner().IdentifierGenerator(MESSAGE_IDENTIFIER).NewMessageIdentifier()
+				linesToRequest[messageIdentifier] = &lineToRequest{
+					subId:  subscription.id,
+					lineId: *(resource.Reference.ObjectId),
+				}
+			}
+		}
+	}
+
+	if len(linesToRequest) == 0 {
+		return
+	}
+
+	logStashEvent := subscriber.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+
+	message := subscriber.newBQEvent()
+	defer audit.CurrentBigQuery(string(subscriber.connector.Partner().Referential().Slug())).WriteEvent(message)
+
+	siriEstimatedTimetableSubscriptionRequest := &siri.SIRIEstimatedTimetableSubscriptionRequest{
+		ConsumerAddress:   subscriber.connector.Partner().Address(),
+		MessageIdentifier: subscriber.connector.Partner().IdentifierGenerator(MESSAGE_IDENTIFIER).NewMessageIdentifier(),
+		RequestorRef:      subscriber.connector.SIRIPartner().RequestorRef(),
+		RequestTimestamp:  subscriber.Clock().Now(),
+	}
+
+	var subIds []string
+	for messageIdentifier, requestedLine := range linesToRequest {
+		entry := &siri.SIRIEstimatedTimetableSubscriptionRequestEntry{
+			SubscriberRef:          subscriber.connector.SIRIPartner().RequestorRef(),
+			SubscriptionIdentifier: string(requestedLine.subId),
+			InitialTerminationTime: subscriber.Clock().Now().Add(48 * time.Hour),
+		}
+		entry.MessageIdentifier = messageIdentifier
+		entry.RequestTimestamp = subscriber.Clock().Now()
+		entry.Lines = []string{requestedLine.lineId.Value()}
+
+		lineRefList = append(lineRefList, requestedLine.lineId.Value())
+		subIds = append(subIds, entry.SubscriptionIdentifier)
+		siriEstimatedTimetableSubscriptionRequest.Entries = append(siriEstimatedTimetableSubscriptionRequest.Entries, entry)
+	}
+
+	message.RequestIdentifier = siriEstimatedTimetableSubscriptionRequest.MessageIdentifier
+	message.RequestRawMessage, _ = siriEstimatedTimetableSubscriptionRequest.BuildXML()
+	message.RequestSize = int64(len(message.RequestRawMessage))
+	message.Lines = lineRefList
+	message.SubscriptionIdentifiers = subIds
+
+	logStashEvent["lineRefs"] = strings.Join(lineRefList, ", ")
+	logSIRIEstimatedTimetableSubscriptionRequest(logStashEvent, siriEstimatedTimetableSubscriptionRequest)
+
+	startTime := subscriber.Clock().Now()
+	response, err := subscriber.connector.SIRIPartner().SOAPClient().EstimatedTimetableSubscription(siriEstimatedTimetableSubscriptionRequest)
+	logStashEvent["responseTime"] = subscriber.Clock().Since(startTime).String()
+	message.ProcessingTime = subscriber.Clock().Since(startTime).Seconds()
+	if err != nil {
+		logger.Log.Debugf("Error while subscribing: %v", err)
+		e := fmt.Sprintf("Error during EstimatedTimetableSubscriptionRequest: %v", err)
+		logStashEvent["status"] = "false"
+		logStashEvent["errorDescription"] = e
+		subscriber.incrementRetryCountFromMap(linesToRequest)
+
+		message.Status = "Error"
+		message.ErrorDetails = e
+		return
+	}
+
+	logStashEvent["responseXML"] = response.RawXML()
+	message.ResponseRawMessage = response.RawXML()
+	message.ResponseSize = int64(len(message.ResponseRawMessage))
+	message.ResponseIdentifier = response.ResponseMessageIdentifier()
+
+	for _, responseStatus := range response.ResponseStatus() {
+		requestedLine, ok := linesToRequest[responseStatus.RequestMessageRef()]
+		if !ok {
+			logger.Log.Debugf("ResponseStatus RequestMessageRef unknown: %v", responseStatus.RequestMessageRef())
+			continue
+		}
+		delete(linesToRequest, responseStatus.RequestMessageRef())
+
+		subscription, ok := subscriber.connector.partner.Subscriptions().Find(requestedLine.subId)
+		if !ok { // Should never happen
+			logger.Log.Debugf("Response for unknown subscription %v", requestedLine.subId)
+			continue
+		}
+		resource := subscription.Resource(requestedLine.lineId)
+		if resource == nil { // Should never happen
+			logger.Log.Debugf("Response for unknown subscription resource %v", requestedLine.lineId.String())
+			continue
+		}
+
+		if !responseStatus.Status() {
+			logger.Log.Debugf("Subscription status false for line %v: %v %v ", requestedLine.lineId.Value(), responseStatus.ErrorType(), responseStatus.ErrorText())
+			resource.RetryCount++
+			message.Status = "Error"
+			continue
+		}
+		resource.SubscribedAt = subscriber.Clock().Now()
+		resource.RetryCount = 0
+	}
+
+	if len(linesToRequest) == 0 {
+		return
+	}
+	subscriber.incrementRetryCountFromMap(linesToRequest)
+}
+
+func (subscriber *ETSubscriber) incrementRetryCountFromMap(linesToRequest map[string]*lineToRequest) {
+	for _, requestedLine := range linesToRequest {
+		subscription, ok := subscriber.connector.partner.Subscriptions().Find(requestedLine.subId)
+		if !ok { // Should never happen
+			continue
+		}
+		resource := subscription.Resource(requestedLine.lineId)
+		if resource == nil { // Should never happen
+			continue
+		}
+		resource.RetryCount++
+	}
+}
+
+func (subscriber *ETSubscriber) newBQEvent() *audit.BigQueryMessage {
+	return &audit.BigQueryMessage{
+		Type:      "EstimatedTimetableSubscriptionRequest",
+		Protocol:  "siri",
+		Direction: "sent",
+		Partner:   string(subscriber.connector.partner.Slug()),
+		Status:    "OK",
+	}
+}
+
+func (subscriber *ETSubscriber) newLogStashEvent() audit.LogStashEvent {
+	event := subscriber.connector.partner.NewLogStashEvent()
+	event["connector"] = "EstimatedTimetableSubscriptionCollector"
+	return event
+}
+
+func logSIRIEstimatedTimetableSubscriptionRequest(logStashEvent audit.LogStashEvent, request *siri.SIRIEstimatedTimetableSubscriptionRequest) {
+	logStashEvent["siriType"] = "EstimatedTimetableSubscriptionRequest"
+	logStashEvent["consumerAddress"] = request.ConsumerAddress
+	logStashEvent["messageIdentifier"] = request.MessageIdentifier
+	logStashEvent["requestorRef"] = request.RequestorRef
+	logStashEvent["requestTimestamp"] = request.RequestTimestamp.String()
+	xml, err := request.BuildXML()
+	if err != nil {
+		logStashEvent["requestXML"] = fmt.Sprintf("%v", err)
+		return
+	}
+	logStashEvent["requestXML"] = xml
+}
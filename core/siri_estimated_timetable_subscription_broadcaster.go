@@ -124,7 +124,17 @@ func (connector *SIRIEstimatedTimeTableSubscriptionBroadcaster) addLineStopVisit
 	tx := connector.Partner().Referential().NewTransaction()
 	defer tx.Close()
 
-	for _, sa := range tx.Model().StopAreas().FindByLineId(lineId) {
+	lineIds := []model.LineId{lineId}
+	if merger := connector.merger(tx); merger != nil {
+		lineIds = merger.LineGroup(lineId)
+	}
+
+	var stopAreas []model.StopArea
+	for _, id := range lineIds {
+		stopAreas = append(stopAreas, tx.Model().StopAreas().FindByLineId(id)...)
+	}
+
+	for _, sa := range stopAreas {
 		// Init SA LastChange
 		salc := &stopAreaLastChange{}
 		salc.InitState(&sa, sub)
@@ -203,7 +213,11 @@ func (connector *SIRIEstimatedTimeTableSubscriptionBroadcaster) checkEvent(svId
 		return
 	}
 
-	line, ok := connector.Partner().Model().Lines().Find(vj.LineId)
+	lineId := vj.LineId
+	if merger := connector.merger(tx); merger != nil {
+		lineId = merger.LineWithObjectIDKind(lineId, connector.Partner().RemoteObjectIDKind(SIRI_ESTIMATED_TIMETABLE_SUBSCRIPTION_BROADCASTER))
+	}
+	line, ok := connector.Partner().Model().Lines().Find(lineId)
 	if !ok {
 		return
 	}
@@ -236,6 +250,14 @@ func (connector *SIRIEstimatedTimeTableSubscriptionBroadcaster) checkEvent(svId
 	}
 }
 
+// Returns a Merger when the partner merges the StopVisits
+func (connector *SIRIEstimatedTimeTableSubscriptionBroadcaster) merger(tx *model.Transaction) *Merger {
+	if !connector.Partner().MergeStopVisits() {
+		return nil
+	}
+	return NewMerger(connector.Partner().Referential(), tx)
+}
+
 func (connector *SIRIEstimatedTimeTableSubscriptionBroadcaster) addStopVisit(subId SubscriptionId, svId model.StopVisitId) {
 	connector.mutex.Lock()
 	connector.toBroadcast[SubscriptionId(subId)] = append(connector.toBroadcast[SubscriptionId(subId)], svId)
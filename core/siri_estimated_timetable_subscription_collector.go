@@ -0,0 +1,234 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/state"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+type EstimatedTimetableSubscriptionCollector interface {
+	state.Stopable
+	state.Startable
+
+	RequestLineUpdate(request *LineUpdateRequest)
+	HandleNotifyEstimatedTimetable(notify *siri.XMLNotifyEstimatedTimetable)
+}
+
+type SIRIEstimatedTimetableSubscriptionCollector struct {
+	clock.ClockConsumer
+	uuid.UUIDConsumer
+
+	siriConnector
+
+	estimatedTimetableSubscriber SIRIEstimatedTimetableSubscriber
+	updateSubscriber             UpdateSubscriber
+}
+
+type SIRIEstimatedTimetableSubscriptionCollectorFactory struct{}
+
+func (factory *SIRIEstimatedTimetableSubscriptionCollectorFactory) CreateConnector(partner *Partner) Connector {
+	return NewSIRIEstimatedTimetableSubscriptionCollector(partner)
+}
+
+func (factory *SIRIEstimatedTimetableSubscriptionCollectorFactory) Validate(apiPartner *APIPartner) {
+	apiPartner.ValidatePresenceOfSetting(REMOTE_OBJECTID_KIND)
+	apiPartner.ValidatePresenceOfSetting(REMOTE_URL)
+	apiPartner.ValidatePresenceOfSetting(REMOTE_CREDENTIAL)
+	apiPartner.ValidatePresenceOfLocalCredentials()
+}
+
+func NewSIRIEstimatedTimetableSubscriptionCollector(partner *Partner) *SIRIEstimatedTimetableSubscriptionCollector {
+	connector := &SIRIEstimatedTimetableSubscriptionCollector{}
+	connector.partner = partner
+	manager := partner.Referential().CollectManager()
+	connector.updateSubscriber = manager.BroadcastUpdateEvent
+	connector.estimatedTimetableSubscriber = NewSIRIEstimatedTimetableSubscriber(connector)
+
+	return connector
+}
+
+func (connector *SIRIEstimatedTimetableSubscriptionCollector) Stop() {
+	connector.estimatedTimetableSubscriber.Stop()
+}
+
+func (connector *SIRIEstimatedTimetableSubscriptionCollector) Start() {
+	connector.estimatedTimetableSubscriber.Start()
+}
+
+func (connector *SIRIEstimatedTimetableSubscriptionCollector) RequestLineUpdate(request *LineUpdateRequest) {
+	tx := connector.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	line, ok := tx.Model().Lines().Find(request.LineId())
+	if !ok {
+		logger.Log.Debugf("LineUpdateRequest in EstimatedTimetable SubscriptionCollector for unknown Line %v", request.LineId())
+		return
+	}
+
+	objectidKind := connector.Partner().Setting(REMOTE_OBJECTID_KIND)
+	lineObjectid, ok := line.ObjectID(objectidKind)
+	if !ok {
+		logger.Log.Debugf("Requested line %v doesn't have and objectId of kind %v", request.LineId(), objectidKind)
+		return
+	}
+
+	// Try to find a Subscription with the resource
+	subscriptions := connector.partner.Subscriptions().FindByResourceId(lineObjectid.String(), "EstimatedTimetableCollect")
+	if len(subscriptions) > 0 {
+		for _, subscription := range subscriptions {
+			resource := subscription.Resource(lineObjectid)
+			if resource == nil { // Should never happen
+				logger.Log.Debugf("Can't find resource in subscription after Subscriptions#FindByResourceId")
+				return
+			}
+			if !resource.SubscribedAt.IsZero() {
+				resource.SubscribedUntil = connector.Clock().Now().Add(2 * time.Minute)
+			}
+		}
+		return
+	}
+
+	// Else we find or create a subscription to add the resource
+	newSubscription := connector.partner.Subscriptions().FindOrCreateByKind("EstimatedTimetableCollect")
+	ref := model.Reference{
+		ObjectId: &lineObjectid,
+		Type:     "Line",
+	}
+
+	newSubscription.CreateAddNewResource(ref)
+}
+
+func (connector *SIRIEstimatedTimetableSubscriptionCollector) SetEstimatedTimetableSubscriber(estimatedTimetableSubscriber SIRIEstimatedTimetableSubscriber) {
+	connector.estimatedTimetableSubscriber = estimatedTimetableSubscriber
+}
+
+func (connector *SIRIEstimatedTimetableSubscriptionCollector) SetUpdateSubscriber(updateSubscriber UpdateSubscriber) {
+	connector.updateSubscriber = updateSubscriber
+}
+
+func (connector *SIRIEstimatedTimetableSubscriptionCollector) HandleNotifyEstimatedTimetable(notify *siri.XMLNotifyEstimatedTimetable) {
+	logStashEvent := connector.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+	vehicleJourneyRefMap := make(map[string]struct{})
+	subscriptionErrors := make(map[string]string)
+	subToDelete := make(map[string]struct{})
+
+	logXMLNotifyEstimatedTimetable(logStashEvent, notify)
+
+	for _, delivery := range notify.EstimatedTimetableDeliveries() {
+		subscriptionId := delivery.SubscriptionRef()
+
+		subscription, ok := connector.Partner().Subscriptions().Find(SubscriptionId(subscriptionId))
+		if !ok {
+			logger.Log.Debugf("Partner %s sent a NotifyEstimatedTimetable to a non existant subscription of id: %s\n", connector.Partner().Slug(), subscriptionId)
+			subscriptionErrors[subscriptionId] = "Non existant subscription of id %s"
+			subToDelete[delivery.SubscriptionRef()] = struct{}{}
+			continue
+		}
+		if subscription.Kind() != "EstimatedTimetableCollect" {
+			logger.Log.Debugf("Partner %s sent a NotifyEstimatedTimetable to a subscription with kind: %s\n", connector.Partner().Slug(), subscription.Kind())
+			subscriptionErrors[subscriptionId] = "Subscription of id %s is not a subscription of kind EstimatedTimetableCollect"
+			continue
+		}
+
+		builder := NewEstimatedTimetableUpdateEventBuilder(connector.partner)
+		builder.SetUpdateEvents(delivery.EstimatedVehicleJourneys())
+		updateEvents := builder.UpdateEvents()
+
+		// Copy VehicleJourneyRefs for global log
+		for k := range updateEvents.VehicleJourneyRefs {
+			vehicleJourneyRefMap[k] = struct{}{}
+		}
+
+		connector.broadcastUpdateEvents(&updateEvents)
+	}
+
+	logEstimatedTimetableRefs(logStashEvent, vehicleJourneyRefMap)
+	if len(subscriptionErrors) != 0 {
+		logSubscriptionErrorsFromMap(logStashEvent, subscriptionErrors)
+	}
+
+	for subId := range subToDelete {
+		connector.cancelSubscription(subId)
+	}
+}
+
+func (connector *SIRIEstimatedTimetableSubscriptionCollector) cancelSubscription(subId string) {
+	logStashEvent := connector.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+	message := connector.newBQEvent()
+	defer audit.CurrentBigQuery(string(connector.Partner().Referential().Slug())).WriteEvent(message)
+
+	request := &siri.SIRIDeleteSubscriptionRequest{
+		RequestTimestamp:  connector.Clock().Now(),
+		SubscriptionRef:   subId,
+		RequestorRef:      connector.partner.ProducerRef(),
+		MessageIdentifier: connector.Partner().IdentifierGenerator(MESSAGE_IDENTIFIER).NewMessageIdentifier(),
+	}
+	logSIRIDeleteSubscriptionRequest(logStashEvent, message, request, "EstimatedTimetableSubscriptionCollector")
+
+	startTime := connector.Clock().Now()
+	response, err := connector.SIRIPartner().SOAPClient().DeleteSubscription(request)
+
+	responseTime := connector.Clock().Since(startTime)
+	logStashEvent["responseTime"] = responseTime.String()
+	message.ProcessingTime = responseTime.Seconds()
+
+	if err != nil {
+		logger.Log.Debugf("Error while terminating subcription with id : %v error : %v", subId, err.Error())
+		e := fmt.Sprintf("Error during DeleteSubscription: %v", err)
+		logStashEvent["status"] = "false"
+		logStashEvent["errorDescription"] = e
+		message.Status = "Error"
+		message.ErrorDetails = e
+		return
+	}
+	logXMLDeleteSubscriptionResponse(logStashEvent, message, response)
+}
+
+func (connector *SIRIEstimatedTimetableSubscriptionCollector) broadcastUpdateEvents(events *EstimatedTimetableUpdateEvents) {
+	broadcastEstimatedTimetableUpdateEvents(connector.updateSubscriber, events)
+}
+
+func (connector *SIRIEstimatedTimetableSubscriptionCollector) newLogStashEvent() audit.LogStashEvent {
+	event := connector.partner.NewLogStashEvent()
+	event["connector"] = "EstimatedTimetableSubscriptionCollector"
+	return event
+}
+
+func (connector *SIRIEstimatedTimetableSubscriptionCollector) newBQEvent() *audit.BigQueryMessage {
+	return &audit.BigQueryMessage{
+		Protocol:  "siri",
+		Direction: "sent",
+		Partner:   string(connector.partner.Slug()),
+		Status:    "OK",
+	}
+}
+
+func logXMLNotifyEstimatedTimetable(logStashEvent audit.LogStashEvent, notify *siri.XMLNotifyEstimatedTimetable) {
+	logStashEvent["siriType"] = "CollectedNotifyEstimatedTimetable"
+	logStashEvent["producerRef"] = notify.ProducerRef()
+	logStashEvent["requestMessageRef"] = notify.RequestMessageRef()
+	logStashEvent["responseMessageIdentifier"] = notify.ResponseMessageIdentifier()
+	logStashEvent["responseTimestamp"] = notify.ResponseTimestamp().String()
+	logStashEvent["responseXML"] = notify.RawXML()
+
+	status := "true"
+	errorCount := 0
+	for _, delivery := range notify.EstimatedTimetableDeliveries() {
+		if !delivery.Status() {
+			status = "false"
+			errorCount++
+		}
+	}
+	logStashEvent["status"] = status
+	logStashEvent["errorCount"] = strconv.Itoa(errorCount)
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+func Test_SIRIEstimatedTimetableSubscriptionCollector_HandleNotifyEstimatedTimetable(t *testing.T) {
+	audit.SetCurrentLogstash(audit.NewFakeLogStash())
+
+	collectManager := NewTestCollectManager()
+	referential := &Referential{
+		collectManager: collectManager,
+		model:          model.NewMemoryModel(),
+	}
+
+	partners := NewPartnerManager(referential)
+	partner := partners.New("slug")
+	partner.SetSetting("remote_objectid_kind", "_internal")
+	partner.SetSetting("generators.subscription_identifier", "Subscription::%{id}::LOC")
+	partner.SetSetting("collect.include_lines", "RLA:Line:1:LOC")
+
+	connector := NewSIRIEstimatedTimetableSubscriptionCollector(partner)
+
+	file, err := os.Open("testdata/notify-estimated-timetable.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notify, err := siri.NewXMLNotifyEstimatedTimetableFromContent(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	partner.Subscriptions().SetUUIDGenerator(uuid.NewFakeUUIDGenerator())
+	subscription := connector.partner.Subscriptions().FindOrCreateByKind("EstimatedTimetableCollect")
+	subscription.Save()
+
+	connector.HandleNotifyEstimatedTimetable(notify)
+
+	// 1 StopArea 1 Line 1 VehicleJourney 1 StopVisit, the second Line isn't collected
+	if len(collectManager.(*TestCollectManager).UpdateEvents) != 4 {
+		t.Errorf("Wrong number of events in collectManager, expected 4 got %v", len(collectManager.(*TestCollectManager).UpdateEvents))
+	}
+}
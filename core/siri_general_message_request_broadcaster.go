@@ -16,16 +16,28 @@ type GeneralMessageRequestBroadcaster interface {
 	Situations(*siri.XMLGetGeneralMessage, *audit.BigQueryMessage) (*siri.SIRIGeneralMessageResponse, error)
 }
 
+// Implemented by the SOAP and SIRI Lite requests
+type generalMessageRequest interface {
+	MessageIdentifier() string
+	InfoChannelRef() []string
+	LineRef() []string
+	StopPointRef() []string
+}
+
 type SIRIGeneralMessageRequestBroadcaster struct {
 	clock.ClockConsumer
 	uuid.UUIDConsumer
 	siriConnector
+
+	connectorType string
 }
 
 type SIRIGeneralMessageRequestBroadcasterFactory struct{}
 
 func NewSIRIGeneralMessageRequestBroadcaster(partner *Partner) *SIRIGeneralMessageRequestBroadcaster {
-	siriGeneralMessageRequestBroadcaster := &SIRIGeneralMessageRequestBroadcaster{}
+	siriGeneralMessageRequestBroadcaster := &SIRIGeneralMessageRequestBroadcaster{
+		connectorType: SIRI_GENERAL_MESSAGE_REQUEST_BROADCASTER,
+	}
 	siriGeneralMessageRequestBroadcaster.partner = partner
 	return siriGeneralMessageRequestBroadcaster
 }
@@ -61,7 +73,7 @@ func (connector *SIRIGeneralMessageRequestBroadcaster) Situations(request *siri.
 	return response, nil
 }
 
-func (connector *SIRIGeneralMessageRequestBroadcaster) getGeneralMessageDelivery(tx *model.Transaction, logStashEvent audit.LogStashEvent, request *siri.XMLGeneralMessageRequest) siri.SIRIGeneralMessageDelivery {
+func (connector *SIRIGeneralMessageRequestBroadcaster) getGeneralMessageDelivery(tx *model.Transaction, logStashEvent audit.LogStashEvent, request generalMessageRequest) siri.SIRIGeneralMessageDelivery {
 	delivery := siri.SIRIGeneralMessageDelivery{
 		RequestMessageRef: request.MessageIdentifier(),
 		Status:            true,
@@ -71,7 +83,7 @@ func (connector *SIRIGeneralMessageRequestBroadcaster) getGeneralMessageDelivery
 	// Prepare Id Array
 	var messageArray []string
 
-	builder := NewBroadcastGeneralMessageBuilder(tx, connector.Partner(), SIRI_GENERAL_MESSAGE_REQUEST_BROADCASTER)
+	builder := NewBroadcastGeneralMessageBuilder(tx, connector.Partner(), connector.connectorType)
 	builder.InfoChannelRef = request.InfoChannelRef()
 	builder.SetLineRef(request.LineRef())
 	builder.SetStopPointRef(request.StopPointRef())
@@ -8,6 +8,7 @@ import (
 
 	"bitbucket.org/enroute-mobi/ara/audit"
 	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
 	"bitbucket.org/enroute-mobi/ara/siri"
 )
 
@@ -19,12 +20,16 @@ type SIRILinesDiscoveryRequestBroadcaster struct {
 	clock.ClockConsumer
 
 	siriConnector
+
+	connectorType string
 }
 
 type SIRILinesDiscoveryRequestBroadcasterFactory struct{}
 
 func NewSIRILinesDiscoveryRequestBroadcaster(partner *Partner) *SIRILinesDiscoveryRequestBroadcaster {
-	siriLinesDiscoveryRequestBroadcaster := &SIRILinesDiscoveryRequestBroadcaster{}
+	siriLinesDiscoveryRequestBroadcaster := &SIRILinesDiscoveryRequestBroadcaster{
+		connectorType: SIRI_LINES_DISCOVERY_REQUEST_BROADCASTER,
+	}
 	siriLinesDiscoveryRequestBroadcaster.partner = partner
 	return siriLinesDiscoveryRequestBroadcaster
 }
@@ -38,6 +43,18 @@ func (connector *SIRILinesDiscoveryRequestBroadcaster) Lines(request *siri.XMLLi
 
 	logXMLLineDiscoveryRequest(logStashEvent, request)
 
+	response, annotedLineArray := connector.getLinesDiscoveryResponse(tx)
+
+	message.RequestIdentifier = request.MessageIdentifier()
+	message.Lines = annotedLineArray
+
+	logStashEvent["annotedLines"] = strings.Join(annotedLineArray, ", ")
+	logSIRILineDiscoveryResponse(logStashEvent, response)
+
+	return response, nil
+}
+
+func (connector *SIRILinesDiscoveryRequestBroadcaster) getLinesDiscoveryResponse(tx *model.Transaction) (*siri.SIRILinesDiscoveryResponse, []string) {
 	response := &siri.SIRILinesDiscoveryResponse{
 		Status:            true,
 		ResponseTimestamp: connector.Clock().Now(),
@@ -45,7 +62,7 @@ func (connector *SIRILinesDiscoveryRequestBroadcaster) Lines(request *siri.XMLLi
 
 	var annotedLineArray []string
 
-	objectIDKind := connector.partner.RemoteObjectIDKind(SIRI_LINES_DISCOVERY_REQUEST_BROADCASTER)
+	objectIDKind := connector.partner.RemoteObjectIDKind(connector.connectorType)
 	for _, line := range tx.Model().Lines().FindAll() {
 		if line.Name == "" {
 			continue
@@ -67,13 +84,7 @@ func (connector *SIRILinesDiscoveryRequestBroadcaster) Lines(request *siri.XMLLi
 
 	sort.Sort(siri.SIRIAnnotatedLineByLineRef(response.AnnotatedLines))
 
-	message.RequestIdentifier = request.MessageIdentifier()
-	message.Lines = annotedLineArray
-
-	logStashEvent["annotedLines"] = strings.Join(annotedLineArray, ", ")
-	logSIRILineDiscoveryResponse(logStashEvent, response)
-
-	return response, nil
+	return response, annotedLineArray
 }
 
 func (connector *SIRILinesDiscoveryRequestBroadcaster) newLogStashEvent() audit.LogStashEvent {
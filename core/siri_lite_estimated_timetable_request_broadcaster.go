@@ -0,0 +1,94 @@
+package core
+
+import (
+	"net/url"
+	"strings"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/siri"
+)
+
+type LiteEstimatedTimetableRequestBroadcaster interface {
+	RequestLine(string, url.Values, *audit.BigQueryMessage) *siri.SiriLiteResponse
+}
+
+type SIRILiteEstimatedTimetableRequestBroadcaster struct {
+	clock.ClockConsumer
+
+	BaseConnector
+
+	// Uses the SOAP broadcaster to select the VehicleJourneys
+	estimatedTimetableBroadcaster *SIRIEstimatedTimetableBroadcaster
+}
+
+type SIRILiteEstimatedTimetableRequestBroadcasterFactory struct{}
+
+func NewSIRILiteEstimatedTimetableRequestBroadcaster(partner *Partner) *SIRILiteEstimatedTimetableRequestBroadcaster {
+	siriLiteEstimatedTimetableRequestBroadcaster := &SIRILiteEstimatedTimetableRequestBroadcaster{
+		estimatedTimetableBroadcaster: &SIRIEstimatedTimetableBroadcaster{
+			connectorType: SIRI_LITE_ESTIMATED_TIMETABLE_REQUEST_BROADCASTER,
+		},
+	}
+	siriLiteEstimatedTimetableRequestBroadcaster.partner = partner
+	siriLiteEstimatedTimetableRequestBroadcaster.estimatedTimetableBroadcaster.partner = partner
+	return siriLiteEstimatedTimetableRequestBroadcaster
+}
+
+func (connector *SIRILiteEstimatedTimetableRequestBroadcaster) RequestLine(url string, filters url.Values, message *audit.BigQueryMessage) *siri.SiriLiteResponse {
+	tx := connector.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	logStashEvent := connector.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+
+	request := siri.NewSIRILiteRequest(url, filters)
+	logSIRILiteEstimatedTimetableRequest(logStashEvent, request)
+
+	siriLiteResponse := siri.NewSiriLiteResponse()
+	siriLiteResponse.Siri.ServiceDelivery.ResponseTimestamp = connector.Clock().Now()
+	siriLiteResponse.Siri.ServiceDelivery.ProducerRef = connector.Partner().ProducerRef()
+	siriLiteResponse.Siri.ServiceDelivery.ResponseMessageIdentifier = connector.Partner().IdentifierGenerator(RESPONSE_MESSAGE_IDENTIFIER).NewMessageIdentifier()
+	siriLiteResponse.Siri.ServiceDelivery.RequestMessageRef = request.MessageIdentifier()
+
+	connector.estimatedTimetableBroadcaster.SetClock(connector.Clock())
+	delivery := connector.estimatedTimetableBroadcaster.getEstimatedTimetableDelivery(tx, request, logStashEvent)
+	siriLiteResponse.Siri.ServiceDelivery.EstimatedTimetableDelivery = siri.NewSiriLiteEstimatedTimetableDelivery(&delivery)
+
+	if !delivery.Status {
+		message.Status = "Error"
+		message.ErrorDetails = delivery.ErrorString()
+	}
+	message.Lines = request.Lines()
+	message.RequestIdentifier = request.MessageIdentifier()
+	message.ResponseIdentifier = siriLiteResponse.Siri.ServiceDelivery.ResponseMessageIdentifier
+
+	logStashEvent["responseMessageIdentifier"] = siriLiteResponse.Siri.ServiceDelivery.ResponseMessageIdentifier
+
+	return siriLiteResponse
+}
+
+func (connector *SIRILiteEstimatedTimetableRequestBroadcaster) newLogStashEvent() audit.LogStashEvent {
+	event := connector.partner.NewLogStashEvent()
+	event["connector"] = "SIRILiteEstimatedTimetableRequestBroadcaster"
+	return event
+}
+
+func (factory *SIRILiteEstimatedTimetableRequestBroadcasterFactory) Validate(apiPartner *APIPartner) {
+	apiPartner.ValidatePresenceOfSetting(REMOTE_OBJECTID_KIND)
+	apiPartner.ValidatePresenceOfLocalCredentials()
+}
+
+func (factory *SIRILiteEstimatedTimetableRequestBroadcasterFactory) CreateConnector(partner *Partner) Connector {
+	return NewSIRILiteEstimatedTimetableRequestBroadcaster(partner)
+}
+
+func logSIRILiteEstimatedTimetableRequest(logStashEvent audit.LogStashEvent, request *siri.SIRILiteRequest) {
+	logStashEvent["siriType"] = "EstimatedTimetableResponse"
+	logStashEvent["requestURL"] = request.Url()
+	logStashEvent["requestorRef"] = request.RequestorRef()
+	logStashEvent["messageIdentifier"] = request.MessageIdentifier()
+	logStashEvent["requestedLines"] = strings.Join(request.Lines(), ",")
+	logStashEvent["startTime"] = request.StartTime().String()
+	logStashEvent["previewInterval"] = request.PreviewInterval().String()
+}
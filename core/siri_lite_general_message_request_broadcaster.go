@@ -0,0 +1,96 @@
+package core
+
+import (
+	"net/url"
+	"strings"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/siri"
+)
+
+type LiteGeneralMessageRequestBroadcaster interface {
+	Situations(string, url.Values, *audit.BigQueryMessage) *siri.SiriLiteResponse
+}
+
+type SIRILiteGeneralMessageRequestBroadcaster struct {
+	clock.ClockConsumer
+
+	BaseConnector
+
+	// Uses the SOAP broadcaster to select the Situations
+	generalMessageBroadcaster *SIRIGeneralMessageRequestBroadcaster
+}
+
+type SIRILiteGeneralMessageRequestBroadcasterFactory struct{}
+
+func NewSIRILiteGeneralMessageRequestBroadcaster(partner *Partner) *SIRILiteGeneralMessageRequestBroadcaster {
+	siriLiteGeneralMessageRequestBroadcaster := &SIRILiteGeneralMessageRequestBroadcaster{
+		generalMessageBroadcaster: &SIRIGeneralMessageRequestBroadcaster{
+			connectorType: SIRI_LITE_GENERAL_MESSAGE_REQUEST_BROADCASTER,
+		},
+	}
+	siriLiteGeneralMessageRequestBroadcaster.partner = partner
+	siriLiteGeneralMessageRequestBroadcaster.generalMessageBroadcaster.partner = partner
+	return siriLiteGeneralMessageRequestBroadcaster
+}
+
+func (connector *SIRILiteGeneralMessageRequestBroadcaster) Situations(url string, filters url.Values, message *audit.BigQueryMessage) *siri.SiriLiteResponse {
+	tx := connector.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	logStashEvent := connector.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+
+	request := siri.NewSIRILiteGeneralMessageRequest(url, filters)
+	logSIRILiteGeneralMessageRequest(logStashEvent, request)
+
+	siriLiteResponse := siri.NewSiriLiteResponse()
+	siriLiteResponse.Siri.ServiceDelivery.ResponseTimestamp = connector.Clock().Now()
+	siriLiteResponse.Siri.ServiceDelivery.ProducerRef = connector.Partner().ProducerRef()
+	siriLiteResponse.Siri.ServiceDelivery.ResponseMessageIdentifier = connector.Partner().IdentifierGenerator(RESPONSE_MESSAGE_IDENTIFIER).NewMessageIdentifier()
+	siriLiteResponse.Siri.ServiceDelivery.RequestMessageRef = request.MessageIdentifier()
+
+	connector.generalMessageBroadcaster.SetClock(connector.Clock())
+	delivery := connector.generalMessageBroadcaster.getGeneralMessageDelivery(tx, logStashEvent, request)
+	siriLiteResponse.Siri.ServiceDelivery.GeneralMessageDelivery = siri.NewSiriLiteGeneralMessageDelivery(&delivery)
+
+	if !delivery.Status {
+		message.Status = "Error"
+		message.ErrorDetails = delivery.ErrorString()
+	}
+	message.Lines = request.LineRef()
+	message.StopAreas = request.StopPointRef()
+	message.RequestIdentifier = request.MessageIdentifier()
+	message.ResponseIdentifier = siriLiteResponse.Siri.ServiceDelivery.ResponseMessageIdentifier
+
+	logSIRIGeneralMessageDelivery(logStashEvent, delivery)
+	logStashEvent["responseMessageIdentifier"] = siriLiteResponse.Siri.ServiceDelivery.ResponseMessageIdentifier
+
+	return siriLiteResponse
+}
+
+func (connector *SIRILiteGeneralMessageRequestBroadcaster) newLogStashEvent() audit.LogStashEvent {
+	event := connector.partner.NewLogStashEvent()
+	event["connector"] = "SIRILiteGeneralMessageRequestBroadcaster"
+	return event
+}
+
+func (factory *SIRILiteGeneralMessageRequestBroadcasterFactory) Validate(apiPartner *APIPartner) {
+	apiPartner.ValidatePresenceOfSetting(REMOTE_OBJECTID_KIND)
+	apiPartner.ValidatePresenceOfLocalCredentials()
+}
+
+func (factory *SIRILiteGeneralMessageRequestBroadcasterFactory) CreateConnector(partner *Partner) Connector {
+	return NewSIRILiteGeneralMessageRequestBroadcaster(partner)
+}
+
+func logSIRILiteGeneralMessageRequest(logStashEvent audit.LogStashEvent, request *siri.SIRILiteGeneralMessageRequest) {
+	logStashEvent["siriType"] = "GeneralMessageResponse"
+	logStashEvent["requestURL"] = request.Url()
+	logStashEvent["requestorRef"] = request.RequestorRef()
+	logStashEvent["messageIdentifier"] = request.MessageIdentifier()
+	logStashEvent["infoChannelRefs"] = strings.Join(request.InfoChannelRef(), ",")
+	logStashEvent["lineRefs"] = strings.Join(request.LineRef(), ",")
+	logStashEvent["stopPointRefs"] = strings.Join(request.StopPointRef(), ",")
+}
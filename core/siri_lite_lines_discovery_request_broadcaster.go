@@ -0,0 +1,77 @@
+package core
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/siri"
+)
+
+type LiteLinesDiscoveryRequestBroadcaster interface {
+	Lines(string, url.Values, *audit.BigQueryMessage) *siri.SiriLiteResponse
+}
+
+type SIRILiteLinesDiscoveryRequestBroadcaster struct {
+	clock.ClockConsumer
+
+	BaseConnector
+
+	// Uses the SOAP broadcaster to select the Lines
+	linesDiscoveryBroadcaster *SIRILinesDiscoveryRequestBroadcaster
+}
+
+type SIRILiteLinesDiscoveryRequestBroadcasterFactory struct{}
+
+func NewSIRILiteLinesDiscoveryRequestBroadcaster(partner *Partner) *SIRILiteLinesDiscoveryRequestBroadcaster {
+	siriLiteLinesDiscoveryRequestBroadcaster := &SIRILiteLinesDiscoveryRequestBroadcaster{
+		linesDiscoveryBroadcaster: &SIRILinesDiscoveryRequestBroadcaster{
+			connectorType: SIRI_LITE_LINES_DISCOVERY_REQUEST_BROADCASTER,
+		},
+	}
+	siriLiteLinesDiscoveryRequestBroadcaster.partner = partner
+	siriLiteLinesDiscoveryRequestBroadcaster.linesDiscoveryBroadcaster.partner = partner
+	return siriLiteLinesDiscoveryRequestBroadcaster
+}
+
+func (connector *SIRILiteLinesDiscoveryRequestBroadcaster) Lines(url string, filters url.Values, message *audit.BigQueryMessage) *siri.SiriLiteResponse {
+	tx := connector.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	logStashEvent := connector.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+
+	request := siri.NewSIRILiteRequest(url, filters)
+	logSIRILiteDiscoveryRequest(logStashEvent, "LinesDiscoveryResponse", request)
+
+	connector.linesDiscoveryBroadcaster.SetClock(connector.Clock())
+	response, annotedLineArray := connector.linesDiscoveryBroadcaster.getLinesDiscoveryResponse(tx)
+
+	siriLiteResponse := siri.NewSiriLiteDiscoveryResponse()
+	siriLiteResponse.Siri.LinesDelivery = siri.NewSiriLiteLinesDelivery(response)
+
+	message.RequestIdentifier = request.MessageIdentifier()
+	message.Lines = annotedLineArray
+
+	logStashEvent["annotedLines"] = strings.Join(annotedLineArray, ", ")
+	logStashEvent["status"] = strconv.FormatBool(response.Status)
+
+	return siriLiteResponse
+}
+
+func (connector *SIRILiteLinesDiscoveryRequestBroadcaster) newLogStashEvent() audit.LogStashEvent {
+	event := connector.partner.NewLogStashEvent()
+	event["connector"] = "SIRILiteLinesDiscoveryRequestBroadcaster"
+	return event
+}
+
+func (factory *SIRILiteLinesDiscoveryRequestBroadcasterFactory) Validate(apiPartner *APIPartner) {
+	apiPartner.ValidatePresenceOfSetting(REMOTE_OBJECTID_KIND)
+	apiPartner.ValidatePresenceOfLocalCredentials()
+}
+
+func (factory *SIRILiteLinesDiscoveryRequestBroadcasterFactory) CreateConnector(partner *Partner) Connector {
+	return NewSIRILiteLinesDiscoveryRequestBroadcaster(partner)
+}
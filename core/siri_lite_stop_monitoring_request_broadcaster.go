@@ -0,0 +1,101 @@
+package core
+
+import (
+	"net/url"
+	"strconv"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/siri"
+)
+
+type LiteStopMonitoringRequestBroadcaster interface {
+	RequestStopArea(string, url.Values, *audit.BigQueryMessage) *siri.SiriLiteResponse
+}
+
+type SIRILiteStopMonitoringRequestBroadcaster struct {
+	clock.ClockConsumer
+
+	BaseConnector
+
+	// Uses the SOAP broadcaster to select the StopVisits
+	stopMonitoringBroadcaster *SIRIStopMonitoringRequestBroadcaster
+}
+
+type SIRILiteStopMonitoringRequestBroadcasterFactory struct{}
+
+func NewSIRILiteStopMonitoringRequestBroadcaster(partner *Partner) *SIRILiteStopMonitoringRequestBroadcaster {
+	siriLiteStopMonitoringRequestBroadcaster := &SIRILiteStopMonitoringRequestBroadcaster{
+		stopMonitoringBroadcaster: &SIRIStopMonitoringRequestBroadcaster{
+			connectorType: SIRI_LITE_STOP_MONITORING_REQUEST_BROADCASTER,
+		},
+	}
+	siriLiteStopMonitoringRequestBroadcaster.partner = partner
+	siriLiteStopMonitoringRequestBroadcaster.stopMonitoringBroadcaster.partner = partner
+	return siriLiteStopMonitoringRequestBroadcaster
+}
+
+func (connector *SIRILiteStopMonitoringRequestBroadcaster) RequestStopArea(url string, filters url.Values, message *audit.BigQueryMessage) *siri.SiriLiteResponse {
+	tx := connector.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	logStashEvent := connector.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+
+	request := siri.NewSIRILiteRequest(url, filters)
+	logSIRILiteStopMonitoringRequest(logStashEvent, request)
+
+	siriLiteResponse := siri.NewSiriLiteResponse()
+	siriLiteResponse.Siri.ServiceDelivery.ResponseTimestamp = connector.Clock().Now()
+	siriLiteResponse.Siri.ServiceDelivery.ProducerRef = connector.Partner().ProducerRef()
+	siriLiteResponse.Siri.ServiceDelivery.ResponseMessageIdentifier = connector.Partner().IdentifierGenerator(RESPONSE_MESSAGE_IDENTIFIER).NewMessageIdentifier()
+	siriLiteResponse.Siri.ServiceDelivery.RequestMessageRef = request.MessageIdentifier()
+
+	connector.stopMonitoringBroadcaster.SetClock(connector.Clock())
+	delivery := connector.stopMonitoringBroadcaster.getStopMonitoringDelivery(tx, logStashEvent, request)
+	siriLiteResponse.Siri.ServiceDelivery.StopMonitoringDelivery = siri.NewSiriLiteStopMonitoringDelivery(&delivery)
+
+	if !delivery.Status {
+		message.Status = "Error"
+		message.ErrorDetails = delivery.ErrorString()
+	}
+	if request.LineRef() != "" {
+		message.Lines = []string{request.LineRef()}
+	}
+	message.StopAreas = []string{request.MonitoringRef()}
+	message.RequestIdentifier = request.MessageIdentifier()
+	message.ResponseIdentifier = siriLiteResponse.Siri.ServiceDelivery.ResponseMessageIdentifier
+
+	logSIRIStopMonitoringDelivery(logStashEvent, delivery)
+	logStashEvent["responseMessageIdentifier"] = siriLiteResponse.Siri.ServiceDelivery.ResponseMessageIdentifier
+
+	return siriLiteResponse
+}
+
+func (connector *SIRILiteStopMonitoringRequestBroadcaster) newLogStashEvent() audit.LogStashEvent {
+	event := connector.partner.NewLogStashEvent()
+	event["connector"] = "SIRILiteStopMonitoringRequestBroadcaster"
+	return event
+}
+
+func (factory *SIRILiteStopMonitoringRequestBroadcasterFactory) Validate(apiPartner *APIPartner) {
+	apiPartner.ValidatePresenceOfSetting(REMOTE_OBJECTID_KIND)
+	apiPartner.ValidatePresenceOfLocalCredentials()
+}
+
+func (factory *SIRILiteStopMonitoringRequestBroadcasterFactory) CreateConnector(partner *Partner) Connector {
+	return NewSIRILiteStopMonitoringRequestBroadcaster(partner)
+}
+
+func logSIRILiteStopMonitoringRequest(logStashEvent audit.LogStashEvent, request *siri.SIRILiteRequest) {
+	logStashEvent["siriType"] = "StopMonitoringResponse"
+	logStashEvent["requestURL"] = request.Url()
+	logStashEvent["requestorRef"] = request.RequestorRef()
+	logStashEvent["messageIdentifier"] = request.MessageIdentifier()
+	logStashEvent["monitoringRef"] = request.MonitoringRef()
+	logStashEvent["stopVisitTypes"] = request.StopVisitTypes()
+	logStashEvent["lineRef"] = request.LineRef()
+	logStashEvent["maximumStopVisits"] = strconv.Itoa(request.MaximumStopVisits())
+	logStashEvent["startTime"] = request.StartTime().String()
+	logStashEvent["previewInterval"] = request.PreviewInterval().String()
+}
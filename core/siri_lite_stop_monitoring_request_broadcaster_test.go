@@ -0,0 +1,131 @@
+package core
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+func prepareSIRILiteStopMonitoringRequestBroadcaster() (*SIRILiteStopMonitoringRequestBroadcaster, *Referential) {
+	referentials := NewMemoryReferentials()
+	referential := referentials.New("referential")
+	partner := referential.Partners().New("partner")
+	partner.SetSetting("remote_objectid_kind", "objectidKind")
+	partner.SetSetting("generators.response_message_identifier", "Ara:ResponseMessage::%{uuid}:LOC")
+	connector := NewSIRILiteStopMonitoringRequestBroadcaster(partner)
+	connector.Partner().SetUUIDGenerator(uuid.NewFakeUUIDGenerator())
+
+	stopArea := referential.Model().StopAreas().New()
+	stopArea.SetObjectID(model.NewObjectID("objectidKind", "NINOXE:StopPoint:SP:24:LOC"))
+	stopArea.Monitored = true
+	stopArea.Save()
+
+	for i, lineRef := range []string{"NINOXE:Line:1:LOC", "NINOXE:Line:2:LOC"} {
+		line := referential.Model().Lines().New()
+		line.SetObjectID(model.NewObjectID("objectidKind", lineRef))
+		line.Save()
+
+		vehicleJourney := referential.Model().VehicleJourneys().New()
+		vehicleJourney.SetObjectID(model.NewObjectID("objectidKind", "NINOXE:VehicleJourney:"+lineRef))
+		vehicleJourney.LineId = line.Id()
+		vehicleJourney.Save()
+
+		stopVisit := referential.Model().StopVisits().New()
+		stopVisit.SetObjectID(model.NewObjectID("objectidKind", "NINOXE:StopVisit:"+lineRef))
+		stopVisit.StopAreaId = stopArea.Id()
+		stopVisit.VehicleJourneyId = vehicleJourney.Id()
+		stopVisit.Schedules.SetArrivalTime("aimed", connector.Clock().Now().Add(time.Duration(i+1)*10*time.Minute))
+		stopVisit.Save()
+	}
+
+	return connector, referential
+}
+
+func Test_SIRILiteStopMonitoringRequestBroadcaster_RequestStopArea(t *testing.T) {
+	connector, _ := prepareSIRILiteStopMonitoringRequestBroadcaster()
+
+	filters := url.Values{}
+	filters.Set("MessageIdentifier", "StopMonitoring:Test:0")
+	filters.Set("MonitoringRef", "NINOXE:StopPoint:SP:24:LOC")
+
+	message := &audit.BigQueryMessage{}
+	response := connector.RequestStopArea("/referential/siri/v2.0/stop-monitoring.json", filters, message)
+
+	serviceDelivery := response.Siri.ServiceDelivery
+	if serviceDelivery.RequestMessageRef != "StopMonitoring:Test:0" {
+		t.Errorf("Wrong RequestMessageRef:\n got: %v\n want: StopMonitoring:Test:0", serviceDelivery.RequestMessageRef)
+	}
+	if serviceDelivery.ResponseMessageIdentifier != "Ara:ResponseMessage::6ba7b814-9dad-11d1-0-00c04fd430c8:LOC" {
+		t.Errorf("Wrong ResponseMessageIdentifier:\n got: %v\n want: Ara:ResponseMessage::6ba7b814-9dad-11d1-0-00c04fd430c8:LOC", serviceDelivery.ResponseMessageIdentifier)
+	}
+
+	delivery := serviceDelivery.StopMonitoringDelivery
+	if delivery == nil {
+		t.Fatal("Response should have a StopMonitoringDelivery")
+	}
+	if !delivery.Status {
+		t.Errorf("Delivery Status should be true")
+	}
+	if len(delivery.MonitoredStopVisit) != 2 {
+		t.Fatalf("Delivery should have 2 MonitoredStopVisits, got: %v", len(delivery.MonitoredStopVisit))
+	}
+	if message.Status != "" {
+		t.Errorf("BigQuery message Status should not be changed, got: %v", message.Status)
+	}
+	if len(message.StopAreas) != 1 || message.StopAreas[0] != "NINOXE:StopPoint:SP:24:LOC" {
+		t.Errorf("Wrong BigQuery message StopAreas: %v", message.StopAreas)
+	}
+
+	filters.Set("LineRef", "NINOXE:Line:2:LOC")
+	response = connector.RequestStopArea("/referential/siri/v2.0/stop-monitoring.json", filters, &audit.BigQueryMessage{})
+
+	delivery = response.Siri.ServiceDelivery.StopMonitoringDelivery
+	if len(delivery.MonitoredStopVisit) != 1 {
+		t.Fatalf("Delivery should have 1 MonitoredStopVisit with LineRef, got: %v", len(delivery.MonitoredStopVisit))
+	}
+	if lineRef := delivery.MonitoredStopVisit[0].MonitoredVehicleJourney.LineRef; lineRef != "NINOXE:Line:2:LOC" {
+		t.Errorf("Wrong LineRef:\n got: %v\n want: NINOXE:Line:2:LOC", lineRef)
+	}
+
+	filters.Del("LineRef")
+	filters.Set("MaximumStopVisits", "1")
+	response = connector.RequestStopArea("/referential/siri/v2.0/stop-monitoring.json", filters, &audit.BigQueryMessage{})
+
+	delivery = response.Siri.ServiceDelivery.StopMonitoringDelivery
+	if len(delivery.MonitoredStopVisit) != 1 {
+		t.Errorf("Delivery should have 1 MonitoredStopVisit with MaximumStopVisits, got: %v", len(delivery.MonitoredStopVisit))
+	}
+}
+
+func Test_SIRILiteStopMonitoringRequestBroadcaster_RequestStopArea_UnknownStopArea(t *testing.T) {
+	connector, _ := prepareSIRILiteStopMonitoringRequestBroadcaster()
+
+	filters := url.Values{}
+	filters.Set("MonitoringRef", "NINOXE:StopPoint:SP:99:LOC")
+
+	message := &audit.BigQueryMessage{}
+	response := connector.RequestStopArea("/referential/siri/v2.0/stop-monitoring.json", filters, message)
+
+	delivery := response.Siri.ServiceDelivery.StopMonitoringDelivery
+	if delivery.Status {
+		t.Errorf("Delivery Status should be false")
+	}
+	if message.Status != "Error" {
+		t.Errorf("BigQuery message Status should be Error, got: %v", message.Status)
+	}
+
+	jsonBytes, err := json.Marshal(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `"ErrorCondition":{"InvalidDataReferencesError":{"ErrorText":"StopArea not found: 'NINOXE:StopPoint:SP:99:LOC'"}}`
+	if !strings.Contains(string(jsonBytes), expected) {
+		t.Errorf("JSON response should contain %v, got:\n%v", expected, string(jsonBytes))
+	}
+}
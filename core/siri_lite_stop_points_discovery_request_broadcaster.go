@@ -0,0 +1,82 @@
+package core
+
+import (
+	"net/url"
+	"strconv"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/siri"
+)
+
+type LiteStopPointsDiscoveryRequestBroadcaster interface {
+	StopAreas(string, url.Values, *audit.BigQueryMessage) *siri.SiriLiteResponse
+}
+
+type SIRILiteStopPointsDiscoveryRequestBroadcaster struct {
+	clock.ClockConsumer
+
+	BaseConnector
+
+	// Uses the SOAP broadcaster to select the StopAreas
+	stopPointsDiscoveryBroadcaster *SIRIStopPointsDiscoveryRequestBroadcaster
+}
+
+type SIRILiteStopPointsDiscoveryRequestBroadcasterFactory struct{}
+
+func NewSIRILiteStopPointsDiscoveryRequestBroadcaster(partner *Partner) *SIRILiteStopPointsDiscoveryRequestBroadcaster {
+	siriLiteStopPointsDiscoveryRequestBroadcaster := &SIRILiteStopPointsDiscoveryRequestBroadcaster{
+		stopPointsDiscoveryBroadcaster: &SIRIStopPointsDiscoveryRequestBroadcaster{
+			connectorType: SIRI_LITE_STOP_POINTS_DISCOVERY_REQUEST_BROADCASTER,
+		},
+	}
+	siriLiteStopPointsDiscoveryRequestBroadcaster.partner = partner
+	siriLiteStopPointsDiscoveryRequestBroadcaster.stopPointsDiscoveryBroadcaster.partner = partner
+	return siriLiteStopPointsDiscoveryRequestBroadcaster
+}
+
+func (connector *SIRILiteStopPointsDiscoveryRequestBroadcaster) StopAreas(url string, filters url.Values, message *audit.BigQueryMessage) *siri.SiriLiteResponse {
+	tx := connector.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	logStashEvent := connector.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+
+	request := siri.NewSIRILiteRequest(url, filters)
+	logSIRILiteDiscoveryRequest(logStashEvent, "StopPointsDiscoveryResponse", request)
+
+	connector.stopPointsDiscoveryBroadcaster.SetClock(connector.Clock())
+	response, annotedStopPointMap := connector.stopPointsDiscoveryBroadcaster.getStopPointsDiscoveryResponse(tx)
+
+	siriLiteResponse := siri.NewSiriLiteDiscoveryResponse()
+	siriLiteResponse.Siri.StopPointsDelivery = siri.NewSiriLiteStopPointsDelivery(response)
+
+	message.RequestIdentifier = request.MessageIdentifier()
+
+	logAnnotatedStopPoints(annotedStopPointMap, logStashEvent, message)
+	logStashEvent["status"] = strconv.FormatBool(response.Status)
+
+	return siriLiteResponse
+}
+
+func (connector *SIRILiteStopPointsDiscoveryRequestBroadcaster) newLogStashEvent() audit.LogStashEvent {
+	event := connector.partner.NewLogStashEvent()
+	event["connector"] = "SIRILiteStopPointsDiscoveryRequestBroadcaster"
+	return event
+}
+
+func (factory *SIRILiteStopPointsDiscoveryRequestBroadcasterFactory) Validate(apiPartner *APIPartner) {
+	apiPartner.ValidatePresenceOfSetting(REMOTE_OBJECTID_KIND)
+	apiPartner.ValidatePresenceOfLocalCredentials()
+}
+
+func (factory *SIRILiteStopPointsDiscoveryRequestBroadcasterFactory) CreateConnector(partner *Partner) Connector {
+	return NewSIRILiteStopPointsDiscoveryRequestBroadcaster(partner)
+}
+
+func logSIRILiteDiscoveryRequest(logStashEvent audit.LogStashEvent, siriType string, request *siri.SIRILiteRequest) {
+	logStashEvent["siriType"] = siriType
+	logStashEvent["requestURL"] = request.Url()
+	logStashEvent["requestorRef"] = request.RequestorRef()
+	logStashEvent["messageIdentifier"] = request.MessageIdentifier()
+}
@@ -195,41 +195,3 @@ func (factory *SIRILiteVehicleMonitoringRequestBroadcasterFactory) CreateConnect
 func logSIRILiteVehicleMonitoringResponse(logStashEvent audit.LogStashEvent, siriLiteResponse *siri.SiriLiteResponse) {
 
 }
-
-// func logXMLVehicleMonitoringRequest(logStashEvent audit.LogStashEvent, request *siri.XMLVehicleMonitoringRequest) {
-// 	logStashEvent["siriType"] = "VehicleMonitoringResponse"
-// 	logStashEvent["messageIdentifier"] = request.MessageIdentifier()
-// 	logStashEvent["monitoringRef"] = request.MonitoringRef()
-// 	logStashEvent["stopVisitTypes"] = request.StopVisitTypes()
-// 	logStashEvent["lineRef"] = request.LineRef()
-// 	logStashEvent["maximumStopVisits"] = strconv.Itoa(request.MaximumStopVisits())
-// 	logStashEvent["requestTimestamp"] = request.RequestTimestamp().String()
-// 	logStashEvent["startTime"] = request.StartTime().String()
-// 	logStashEvent["previewInterval"] = request.PreviewInterval().String()
-// 	logStashEvent["requestXML"] = request.RawXML()
-// }
-
-// func logSIRIVehicleMonitoringDelivery(logStashEvent audit.LogStashEvent, delivery siri.SIRIVehicleMonitoringDelivery) {
-// 	logStashEvent["requestMessageRef"] = delivery.RequestMessageRef
-// 	logStashEvent["responseTimestamp"] = delivery.ResponseTimestamp.String()
-// 	logStashEvent["status"] = strconv.FormatBool(delivery.Status)
-// 	if !delivery.Status {
-// 		logStashEvent["errorType"] = delivery.ErrorType
-// 		if delivery.ErrorType == "OtherError" {
-// 			logStashEvent["errorNumber"] = strconv.Itoa(delivery.ErrorNumber)
-// 		}
-// 		logStashEvent["errorText"] = delivery.ErrorText
-// 	}
-// }
-
-// func logSIRIVehicleMonitoringResponse(logStashEvent audit.LogStashEvent, response *siri.SIRIVehicleMonitoringResponse) {
-// 	logStashEvent["address"] = response.Address
-// 	logStashEvent["producerRef"] = response.ProducerRef
-// 	logStashEvent["responseMessageIdentifier"] = response.ResponseMessageIdentifier
-// 	xml, err := response.BuildXML()
-// 	if err != nil {
-// 		logStashEvent["responseXML"] = fmt.Sprintf("%v", err)
-// 		return
-// 	}
-// 	logStashEvent["responseXML"] = xml
-// }
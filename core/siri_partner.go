@@ -21,9 +21,24 @@ func (siriPartner *SIRIPartner) SOAPClient() *siri.SOAPClient {
 		SubscriptionsUrl: siriPartner.partner.Setting(SUBSCRIPTIONS_REMOTE_URL),
 		NotificationsUrl: siriPartner.partner.Setting(NOTIFICATIONS_REMOTE_URL),
 	}
-	if siriPartner.soapClient == nil || siriPartner.soapClient.SOAPClientUrls != urls {
+	authentication := siriPartner.partner.SOAPClientAuthentication()
+	retry := siriPartner.partner.SOAPClientRetry()
+	circuitBreaker := siriPartner.partner.CircuitBreaker()
+	if siriPartner.soapClient == nil ||
+		siriPartner.soapClient.SOAPClientUrls != urls ||
+		siriPartner.soapClient.Authentication() != authentication ||
+		siriPartner.soapClient.Retry() != retry ||
+		siriPartner.soapClient.CircuitBreaker() != circuitBreaker {
 		logger.Log.Debugf("Create SIRI SOAPClient to %s", urls.Url)
 		siriPartner.soapClient = siri.NewSOAPClient(urls)
+		if metrics := siriPartner.partner.SOAPMetrics(); metrics != nil {
+			siriPartner.soapClient.SetMetrics(metrics)
+		}
+		siriPartner.soapClient.SetRetry(retry)
+		siriPartner.soapClient.SetCircuitBreaker(circuitBreaker)
+		if err := siriPartner.soapClient.SetAuthentication(authentication); err != nil {
+			logger.Log.Printf("Can't set SOAPClient authentication of partner %v: %v", siriPartner.partner.Slug(), err)
+		}
 	}
 	return siriPartner.soapClient
 }
@@ -1,5 +1,10 @@
 package core
 
+import (
+	"testing"
+	"time"
+)
+
 // func Test_SIRIPartner_SOAPClient(t *testing.T) {
 // 	partner := &Partner{
 // 		slug:     "partner",
@@ -54,3 +59,32 @@ package core
 // 		t.Errorf("Wrong MessageIdentifier:\n got: %s\n want: %s", mid, expected)
 // 	}
 // }
+
+func Test_SIRIPartner_SOAPClient_RetryAndCircuitBreaker(t *testing.T) {
+	partner := NewPartner()
+	siriPartner := NewSIRIPartner(partner)
+
+	soapClient := siriPartner.SOAPClient()
+	if retry := soapClient.Retry(); retry.Attempts != 1 || retry.Backoff != DEFAULT_REMOTE_RETRY_BACKOFF || retry.MaxDuration != DEFAULT_REMOTE_RETRY_MAX_DURATION {
+		t.Errorf("Wrong default SOAPClient retry: %v", retry)
+	}
+	if soapClient.CircuitBreaker() != nil {
+		t.Errorf("SOAPClient shouldn't have a CircuitBreaker without setting")
+	}
+
+	partner.SetSetting(REMOTE_RETRY_ATTEMPTS, "3")
+	partner.SetSetting(REMOTE_RETRY_BACKOFF, "1s")
+	partner.SetSetting(REMOTE_RETRY_MAX_DURATION, "10s")
+	partner.SetSetting(REMOTE_CIRCUIT_BREAKER_THRESHOLD, "5")
+
+	soapClient = siriPartner.SOAPClient()
+	if retry := soapClient.Retry(); retry.Attempts != 3 || retry.Backoff != time.Second || retry.MaxDuration != 10*time.Second {
+		t.Errorf("Wrong SOAPClient retry: %v", retry)
+	}
+	if soapClient.CircuitBreaker() == nil || soapClient.CircuitBreaker() != partner.CircuitBreaker() {
+		t.Errorf("SOAPClient should use the partner CircuitBreaker")
+	}
+	if siriPartner.SOAPClient() != soapClient {
+		t.Errorf("SOAPClient should be kept while the settings don't change")
+	}
+}
@@ -0,0 +1,152 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+)
+
+type ProductionTimetableRequestBroadcaster interface {
+	RequestLine(*siri.XMLGetProductionTimetable, *audit.BigQueryMessage) *siri.SIRIProductionTimetableResponse
+}
+
+type SIRIProductionTimetableRequestBroadcaster struct {
+	clock.ClockConsumer
+
+	siriConnector
+}
+
+type SIRIProductionTimetableRequestBroadcasterFactory struct{}
+
+func NewSIRIProductionTimetableRequestBroadcaster(partner *Partner) *SIRIProductionTimetableRequestBroadcaster {
+	broadcaster := &SIRIProductionTimetableRequestBroadcaster{}
+	broadcaster.partner = partner
+	return broadcaster
+}
+
+func (connector *SIRIProductionTimetableRequestBroadcaster) RequestLine(request *siri.XMLGetProductionTimetable, message *audit.BigQueryMessage) *siri.SIRIProductionTimetableResponse {
+	tx := connector.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	logStashEvent := connector.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+
+	logXMLProductionTimetableRequest(logStashEvent, &request.XMLProductionTimetableRequest)
+	logStashEvent["requestorRef"] = request.RequestorRef()
+
+	response := &siri.SIRIProductionTimetableResponse{
+		Address:                   connector.Partner().Address(),
+		ProducerRef:               connector.Partner().ProducerRef(),
+		ResponseMessageIdentifier: connector.Partner().IdentifierGenerator(RESPONSE_MESSAGE_IDENTIFIER).NewMessageIdentifier(),
+	}
+
+	response.SIRIProductionTimetableDelivery = connector.getProductionTimetableDelivery(tx, &request.XMLProductionTimetableRequest, logStashEvent)
+
+	if !response.SIRIProductionTimetableDelivery.Status {
+		message.Status = "Error"
+		message.ErrorDetails = response.SIRIProductionTimetableDelivery.ErrorString()
+	}
+	message.Lines = request.Lines()
+	message.RequestIdentifier = request.MessageIdentifier()
+	message.ResponseIdentifier = response.ResponseMessageIdentifier
+
+	logSIRIProductionTimetableResponse(logStashEvent, response)
+
+	return response
+}
+
+func (connector *SIRIProductionTimetableRequestBroadcaster) getProductionTimetableDelivery(tx *model.Transaction, request *siri.XMLProductionTimetableRequest, logStashEvent audit.LogStashEvent) siri.SIRIProductionTimetableDelivery {
+	delivery := siri.SIRIProductionTimetableDelivery{
+		RequestMessageRef: request.MessageIdentifier(),
+		ResponseTimestamp: connector.Clock().Now(),
+		Status:            true,
+	}
+
+	builder := NewBroadcastProductionTimetableBuilder(tx, connector.Partner(), SIRI_PRODUCTION_TIMETABLE_REQUEST_BROADCASTER)
+	builder.SetValidityPeriod(request.StartTime(), request.EndTime())
+
+	var lines []model.Line
+	if len(request.Lines()) == 0 {
+		lines = tx.Model().Lines().FindAll()
+	}
+	for _, lineId := range request.Lines() {
+		lineObjectId := model.NewObjectID(connector.partner.RemoteObjectIDKind(SIRI_PRODUCTION_TIMETABLE_REQUEST_BROADCASTER), lineId)
+		line, ok := tx.Model().Lines().FindByObjectId(lineObjectId)
+		if !ok {
+			logger.Log.Debugf("Cannot find requested line Production Timetable with id %v at %v", lineObjectId.String(), connector.Clock().Now())
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	var lineRefs []string
+	for i := range lines {
+		frame := builder.BuildDatedTimetableVersionFrame(&lines[i])
+		if frame == nil {
+			continue
+		}
+		lineRefs = append(lineRefs, frame.LineRef)
+		delivery.DatedTimetableVersionFrames = append(delivery.DatedTimetableVersionFrames, frame)
+	}
+
+	logSIRIProductionTimetableDelivery(logStashEvent, delivery, lineRefs)
+
+	return delivery
+}
+
+func (connector *SIRIProductionTimetableRequestBroadcaster) newLogStashEvent() audit.LogStashEvent {
+	event := connector.partner.NewLogStashEvent()
+	event["connector"] = "ProductionTimetableRequestBroadcaster"
+	return event
+}
+
+func (factory *SIRIProductionTimetableRequestBroadcasterFactory) Validate(apiPartner *APIPartner) {
+	apiPartner.ValidatePresenceOfSetting(REMOTE_OBJECTID_KIND)
+	apiPartner.ValidatePresenceOfLocalCredentials()
+}
+
+func (factory *SIRIProductionTimetableRequestBroadcasterFactory) CreateConnector(partner *Partner) Connector {
+	return NewSIRIProductionTimetableRequestBroadcaster(partner)
+}
+
+func logXMLProductionTimetableRequest(logStashEvent audit.LogStashEvent, request *siri.XMLProductionTimetableRequest) {
+	logStashEvent["siriType"] = "ProductionTimetableResponse"
+	logStashEvent["messageIdentifier"] = request.MessageIdentifier()
+	logStashEvent["requestTimestamp"] = request.RequestTimestamp().String()
+	logStashEvent["requestedLines"] = strings.Join(request.Lines(), ",")
+	logStashEvent["startTime"] = request.StartTime().String()
+	logStashEvent["endTime"] = request.EndTime().String()
+	logStashEvent["requestXML"] = request.RawXML()
+}
+
+func logSIRIProductionTimetableDelivery(logStashEvent audit.LogStashEvent, delivery siri.SIRIProductionTimetableDelivery, lineRefs []string) {
+	logStashEvent["requestMessageRef"] = delivery.RequestMessageRef
+	logStashEvent["responseTimestamp"] = delivery.ResponseTimestamp.String()
+	logStashEvent["lineRefs"] = strings.Join(lineRefs, ",")
+	logStashEvent["status"] = strconv.FormatBool(delivery.Status)
+	if !delivery.Status {
+		logStashEvent["errorType"] = delivery.ErrorType
+		if delivery.ErrorType == "OtherError" {
+			logStashEvent["errorNumber"] = strconv.Itoa(delivery.ErrorNumber)
+		}
+		logStashEvent["errorText"] = delivery.ErrorText
+	}
+}
+
+func logSIRIProductionTimetableResponse(logStashEvent audit.LogStashEvent, response *siri.SIRIProductionTimetableResponse) {
+	logStashEvent["address"] = response.Address
+	logStashEvent["producerRef"] = response.ProducerRef
+	logStashEvent["responseMessageIdentifier"] = response.ResponseMessageIdentifier
+	xml, err := response.BuildXML()
+	if err != nil {
+		logStashEvent["responseXML"] = fmt.Sprintf("%v", err)
+		return
+	}
+	logStashEvent["responseXML"] = xml
+}
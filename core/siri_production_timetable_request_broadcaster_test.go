@@ -0,0 +1,158 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+func Test_SIRIProductionTimetableRequestBroadcaster_RequestLine(t *testing.T) {
+	referentials := NewMemoryReferentials()
+	referential := referentials.New("referential")
+	partner := referential.Partners().New("partner")
+	partner.SetSetting("local_url", "http://ara")
+	partner.SetSetting("remote_objectid_kind", "objectidKind")
+	partner.SetSetting("generators.response_message_identifier", "Ara:ResponseMessage::%{uuid}:LOC")
+	connector := NewSIRIProductionTimetableRequestBroadcaster(partner)
+	connector.Partner().SetUUIDGenerator(uuid.NewFakeUUIDGenerator())
+	connector.SetClock(clock.NewFakeClock())
+
+	stopArea := referential.Model().StopAreas().New()
+	stopArea.SetObjectID(model.NewObjectID("objectidKind", "stopArea1"))
+	stopArea.Name = "First stop"
+	stopArea.Save()
+
+	stopArea2 := referential.Model().StopAreas().New()
+	stopArea2.SetObjectID(model.NewObjectID("objectidKind", "stopArea2"))
+	stopArea2.Name = "Second stop"
+	stopArea2.Save()
+
+	line := referential.Model().Lines().New()
+	line.SetObjectID(model.NewObjectID("objectidKind", "NINOXE:Line:3:LOC"))
+	line.Save()
+
+	otherLine := referential.Model().Lines().New()
+	otherLine.SetObjectID(model.NewObjectID("objectidKind", "NINOXE:Line:4:LOC"))
+	otherLine.Save()
+
+	// In the requested validity period
+	vehicleJourney := referential.Model().VehicleJourneys().New()
+	vehicleJourney.SetObjectID(model.NewObjectID("objectidKind", "vehicleJourney"))
+	vehicleJourney.LineId = line.Id()
+	vehicleJourney.Save()
+
+	// After the requested validity period
+	vehicleJourney2 := referential.Model().VehicleJourneys().New()
+	vehicleJourney2.SetObjectID(model.NewObjectID("objectidKind", "vehicleJourney2"))
+	vehicleJourney2.LineId = line.Id()
+	vehicleJourney2.Save()
+
+	// On a Line which isn't requested
+	vehicleJourney3 := referential.Model().VehicleJourneys().New()
+	vehicleJourney3.SetObjectID(model.NewObjectID("objectidKind", "vehicleJourney3"))
+	vehicleJourney3.LineId = otherLine.Id()
+	vehicleJourney3.Save()
+
+	inPeriod := time.Date(2016, time.September, 7, 10, 0, 0, 0, time.UTC)
+	outOfPeriod := time.Date(2016, time.September, 8, 10, 0, 0, 0, time.UTC)
+
+	stopVisit2 := referential.Model().StopVisits().New()
+	stopVisit2.VehicleJourneyId = vehicleJourney.Id()
+	stopVisit2.StopAreaId = stopArea2.Id()
+	stopVisit2.PassageOrder = 2
+	stopVisit2.Schedules.SetArrivalTime("aimed", inPeriod.Add(10*time.Minute))
+	stopVisit2.Schedules.SetArrivalTime("expected", inPeriod.Add(15*time.Minute))
+	stopVisit2.Save()
+
+	stopVisit := referential.Model().StopVisits().New()
+	stopVisit.VehicleJourneyId = vehicleJourney.Id()
+	stopVisit.StopAreaId = stopArea.Id()
+	stopVisit.PassageOrder = 1
+	stopVisit.Schedules.SetDepartureTime("aimed", inPeriod)
+	stopVisit.Save()
+
+	stopVisit3 := referential.Model().StopVisits().New()
+	stopVisit3.VehicleJourneyId = vehicleJourney2.Id()
+	stopVisit3.StopAreaId = stopArea.Id()
+	stopVisit3.PassageOrder = 1
+	stopVisit3.Schedules.SetDepartureTime("aimed", outOfPeriod)
+	stopVisit3.Save()
+
+	stopVisit4 := referential.Model().StopVisits().New()
+	stopVisit4.VehicleJourneyId = vehicleJourney3.Id()
+	stopVisit4.StopAreaId = stopArea.Id()
+	stopVisit4.PassageOrder = 1
+	stopVisit4.Schedules.SetDepartureTime("aimed", inPeriod)
+	stopVisit4.Save()
+
+	file, err := os.Open("testdata/productiontimetable-request.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, err := siri.NewXMLGetProductionTimetableFromContent(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response := connector.RequestLine(request, &audit.BigQueryMessage{})
+
+	if response.Address != "http://ara" {
+		t.Errorf("Response has wrong adress:\n got: %v\n want: http://ara", response.Address)
+	}
+	if response.ResponseMessageIdentifier != "Ara:ResponseMessage::6ba7b814-9dad-11d1-0-00c04fd430c8:LOC" {
+		t.Errorf("Response has wrong ResponseMessageIdentifier:\n got: %v", response.ResponseMessageIdentifier)
+	}
+	if response.RequestMessageRef != "ProductionTimetable:Test:0" {
+		t.Errorf("Response has wrong RequestMessageRef:\n got: %v\n want: ProductionTimetable:Test:0", response.RequestMessageRef)
+	}
+	if !response.Status {
+		t.Errorf("Response should have a true Status")
+	}
+	if len(response.DatedTimetableVersionFrames) != 1 {
+		t.Fatalf("Response should have 1 DatedTimetableVersionFrame, got: %v", len(response.DatedTimetableVersionFrames))
+	}
+
+	frame := response.DatedTimetableVersionFrames[0]
+	if frame.LineRef != "NINOXE:Line:3:LOC" {
+		t.Errorf("Wrong LineRef:\n got: %v\n want: NINOXE:Line:3:LOC", frame.LineRef)
+	}
+	if len(frame.DatedVehicleJourneys) != 1 {
+		t.Fatalf("Frame should have 1 DatedVehicleJourney, got: %v", len(frame.DatedVehicleJourneys))
+	}
+
+	datedVehicleJourney := frame.DatedVehicleJourneys[0]
+	if datedVehicleJourney.DatedVehicleJourneyRef != "vehicleJourney" {
+		t.Errorf("Wrong DatedVehicleJourneyRef:\n got: %v\n want: vehicleJourney", datedVehicleJourney.DatedVehicleJourneyRef)
+	}
+	if len(datedVehicleJourney.DatedCalls) != 2 {
+		t.Fatalf("DatedVehicleJourney should have 2 DatedCalls, got: %v", len(datedVehicleJourney.DatedCalls))
+	}
+
+	firstCall := datedVehicleJourney.DatedCalls[0]
+	if firstCall.StopPointRef != "stopArea1" || firstCall.Order != 1 {
+		t.Errorf("Wrong first DatedCall:\n got: %v (%v)\n want: stopArea1 (1)", firstCall.StopPointRef, firstCall.Order)
+	}
+	if !firstCall.AimedDepartureTime.Equal(inPeriod) {
+		t.Errorf("Wrong AimedDepartureTime:\n got: %v\n want: %v", firstCall.AimedDepartureTime, inPeriod)
+	}
+
+	secondCall := datedVehicleJourney.DatedCalls[1]
+	if secondCall.StopPointRef != "stopArea2" || secondCall.StopPointName != "Second stop" {
+		t.Errorf("Wrong second DatedCall:\n got: %v (%v)\n want: stopArea2 (Second stop)", secondCall.StopPointRef, secondCall.StopPointName)
+	}
+	if !secondCall.AimedArrivalTime.Equal(inPeriod.Add(10 * time.Minute)) {
+		t.Errorf("AimedArrivalTime should use the aimed schedule:\n got: %v\n want: %v", secondCall.AimedArrivalTime, inPeriod.Add(10*time.Minute))
+	}
+}
@@ -0,0 +1,217 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+type SIRIProductionTimetableSubscriptionBroadcaster struct {
+	clock.ClockConsumer
+	uuid.UUIDConsumer
+
+	siriConnector
+
+	productionTimetableBroadcaster SIRIProductionTimetableBroadcaster
+	toBroadcast                    map[SubscriptionId][]model.ObjectID
+	reloadedSubscriptions          []*Subscription
+
+	mutex *sync.Mutex //protect the map
+}
+
+type SIRIProductionTimetableSubscriptionBroadcasterFactory struct{}
+
+func (factory *SIRIProductionTimetableSubscriptionBroadcasterFactory) CreateConnector(partner *Partner) Connector {
+	if _, ok := partner.Connector(SIRI_SUBSCRIPTION_REQUEST_DISPATCHER); !ok {
+		partner.CreateSubscriptionRequestDispatcher()
+	}
+	return newSIRIProductionTimetableSubscriptionBroadcaster(partner)
+}
+
+func (factory *SIRIProductionTimetableSubscriptionBroadcasterFactory) Validate(apiPartner *APIPartner) {
+	apiPartner.ValidatePresenceOfSetting(REMOTE_OBJECTID_KIND)
+	apiPartner.ValidatePresenceOfSetting(REMOTE_URL)
+	apiPartner.ValidatePresenceOfSetting(REMOTE_CREDENTIAL)
+	apiPartner.ValidatePresenceOfSetting(LOCAL_CREDENTIAL)
+}
+
+func newSIRIProductionTimetableSubscriptionBroadcaster(partner *Partner) *SIRIProductionTimetableSubscriptionBroadcaster {
+	connector := &SIRIProductionTimetableSubscriptionBroadcaster{}
+	connector.partner = partner
+	connector.mutex = &sync.Mutex{}
+	connector.toBroadcast = make(map[SubscriptionId][]model.ObjectID)
+
+	connector.productionTimetableBroadcaster = NewSIRIProductionTimetableBroadcaster(connector)
+	return connector
+}
+
+func (connector *SIRIProductionTimetableSubscriptionBroadcaster) Stop() {
+	connector.productionTimetableBroadcaster.Stop()
+}
+
+func (connector *SIRIProductionTimetableSubscriptionBroadcaster) Start() {
+	connector.productionTimetableBroadcaster.Start()
+}
+
+func (connector *SIRIProductionTimetableSubscriptionBroadcaster) HandleSubscriptionRequest(request *siri.XMLSubscriptionRequest, message *audit.BigQueryMessage) (resps []siri.SIRIResponseStatus) {
+	var lineIds, subIds []string
+
+	for _, pt := range request.XMLSubscriptionPTEntries() {
+		logStashEvent := connector.newLogStashEvent()
+		logSIRIProductionTimetableSubscriptionEntry(logStashEvent, pt)
+
+		rs := siri.SIRIResponseStatus{
+			RequestMessageRef: pt.MessageIdentifier(),
+			SubscriberRef:     pt.SubscriberRef(),
+			SubscriptionRef:   pt.SubscriptionIdentifier(),
+			ResponseTimestamp: connector.Clock().Now(),
+		}
+
+		lineIds = append(lineIds, pt.Lines()...)
+
+		lineObjectIds, unknownLineIds := connector.checkLines(pt)
+		if len(unknownLineIds) != 0 {
+			logger.Log.Debugf("ProductionTimetable subscription request Could not find line(s) with id : %v", strings.Join(unknownLineIds, ","))
+			rs.ErrorType = "InvalidDataReferencesError"
+			rs.ErrorText = fmt.Sprintf("Unknown Line(s) %v", strings.Join(unknownLineIds, ","))
+		} else {
+			rs.Status = true
+			rs.ValidUntil = pt.InitialTerminationTime()
+		}
+
+		resps = append(resps, rs)
+
+		logSIRIProductionTimetableSubscriptionResponseEntry(logStashEvent, &rs)
+		audit.CurrentLogStash().WriteEvent(logStashEvent)
+
+		if len(unknownLineIds) != 0 {
+			continue
+		}
+
+		subIds = append(subIds, pt.SubscriptionIdentifier())
+
+		sub, ok := connector.Partner().Subscriptions().FindByExternalId(pt.SubscriptionIdentifier())
+		if !ok {
+			sub = connector.Partner().Subscriptions().New("ProductionTimetableBroadcast")
+			sub.SetExternalId(pt.SubscriptionIdentifier())
+		}
+		sub.SetSubscriptionOption("MessageIdentifier", request.MessageIdentifier())
+		if !pt.StartTime().IsZero() {
+			sub.SetSubscriptionOption("StartTime", pt.StartTime().Format(time.RFC3339))
+		}
+		if !pt.EndTime().IsZero() {
+			sub.SetSubscriptionOption("EndTime", pt.EndTime().Format(time.RFC3339))
+		}
+
+		for i := range lineObjectIds {
+			r := sub.Resource(lineObjectIds[i])
+			if r == nil {
+				ref := model.Reference{
+					ObjectId: &lineObjectIds[i],
+					Type:     "Line",
+				}
+				r = sub.CreateAddNewResource(ref)
+				r.SubscribedAt = connector.Clock().Now()
+			}
+			r.SubscribedUntil = pt.InitialTerminationTime()
+			connector.addLine(sub.Id(), lineObjectIds[i])
+		}
+		sub.Save()
+	}
+	message.Type = "ProductionTimetableSubscriptionRequest"
+	message.SubscriptionIdentifiers = subIds
+	message.Lines = lineIds
+
+	return resps
+}
+
+// Without LineRef, the subscription concerns all the Lines
+func (connector *SIRIProductionTimetableSubscriptionBroadcaster) checkLines(pt *siri.XMLProductionTimetableSubscriptionRequestEntry) (lineObjectIds []model.ObjectID, lineIds []string) {
+	remoteObjectidKind := connector.partner.RemoteObjectIDKind(SIRI_PRODUCTION_TIMETABLE_SUBSCRIPTION_BROADCASTER)
+
+	if len(pt.Lines()) == 0 {
+		for _, line := range connector.Partner().Model().Lines().FindAll() {
+			lineObjectId, ok := line.ObjectID(remoteObjectidKind)
+			if ok {
+				lineObjectIds = append(lineObjectIds, lineObjectId)
+			}
+		}
+	}
+	for _, lineId := range pt.Lines() {
+		lineObjectId := model.NewObjectID(remoteObjectidKind, lineId)
+		if _, ok := connector.Partner().Model().Lines().FindByObjectId(lineObjectId); !ok {
+			lineIds = append(lineIds, lineId)
+			continue
+		}
+		lineObjectIds = append(lineObjectIds, lineObjectId)
+	}
+	return lineObjectIds, lineIds
+}
+
+func (connector *SIRIProductionTimetableSubscriptionBroadcaster) addLine(subId SubscriptionId, lineObjectId model.ObjectID) {
+	connector.mutex.Lock()
+	connector.toBroadcast[subId] = append(connector.toBroadcast[subId], lineObjectId)
+	connector.mutex.Unlock()
+}
+
+// Keep the subscriptions which are cancelled when the Referential is stopped
+func (connector *SIRIProductionTimetableSubscriptionBroadcaster) PrepareModelReload() {
+	connector.reloadedSubscriptions = connector.Partner().Subscriptions().FindSubscriptionsByKind("ProductionTimetableBroadcast")
+}
+
+// Restore the subscriptions and notify all their Lines with the new model
+func (connector *SIRIProductionTimetableSubscriptionBroadcaster) HandleModelReload() {
+	subscriptions := connector.reloadedSubscriptions
+	connector.reloadedSubscriptions = nil
+
+	for _, sub := range subscriptions {
+		sub.Save()
+		for _, r := range sub.ResourcesByObjectIDCopy() {
+			if r.SubscribedUntil.Before(connector.Clock().Now()) {
+				continue
+			}
+			connector.addLine(sub.Id(), *r.Reference.ObjectId)
+		}
+	}
+}
+
+func (connector *SIRIProductionTimetableSubscriptionBroadcaster) newLogStashEvent() audit.LogStashEvent {
+	event := connector.partner.NewLogStashEvent()
+	event["connector"] = "ProductionTimetableSubscriptionBroadcaster"
+	return event
+}
+
+func logSIRIProductionTimetableSubscriptionEntry(logStashEvent audit.LogStashEvent, ptEntry *siri.XMLProductionTimetableSubscriptionRequestEntry) {
+	logStashEvent["siriType"] = "ProductionTimetableSubscriptionEntry"
+	logStashEvent["lineRefs"] = strings.Join(ptEntry.Lines(), ",")
+	logStashEvent["messageIdentifier"] = ptEntry.MessageIdentifier()
+	logStashEvent["subscriberRef"] = ptEntry.SubscriberRef()
+	logStashEvent["subscriptionIdentifier"] = ptEntry.SubscriptionIdentifier()
+	logStashEvent["initialTerminationTime"] = ptEntry.InitialTerminationTime().String()
+	logStashEvent["requestTimestamp"] = ptEntry.RequestTimestamp().String()
+	logStashEvent["requestXML"] = ptEntry.RawXML()
+}
+
+func logSIRIProductionTimetableSubscriptionResponseEntry(logStashEvent audit.LogStashEvent, response *siri.SIRIResponseStatus) {
+	logStashEvent["requestMessageRef"] = response.RequestMessageRef
+	logStashEvent["subscriptionRef"] = response.SubscriptionRef
+	logStashEvent["responseTimestamp"] = response.ResponseTimestamp.String()
+	logStashEvent["validUntil"] = response.ValidUntil.String()
+	logStashEvent["status"] = strconv.FormatBool(response.Status)
+	if !response.Status {
+		logStashEvent["errorType"] = response.ErrorType
+		if response.ErrorType == "OtherError" {
+			logStashEvent["errorNumber"] = strconv.Itoa(response.ErrorNumber)
+		}
+		logStashEvent["errorText"] = response.ErrorText
+	}
+}
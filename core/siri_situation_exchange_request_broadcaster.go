@@ -0,0 +1,140 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+type SituationExchangeRequestBroadcaster interface {
+	Situations(*siri.XMLGetSituationExchange, *audit.BigQueryMessage) (*siri.SIRISituationExchangeResponse, error)
+}
+
+type SIRISituationExchangeRequestBroadcaster struct {
+	clock.ClockConsumer
+	uuid.UUIDConsumer
+	siriConnector
+}
+
+type SIRISituationExchangeRequestBroadcasterFactory struct{}
+
+func NewSIRISituationExchangeRequestBroadcaster(partner *Partner) *SIRISituationExchangeRequestBroadcaster {
+	siriSituationExchangeRequestBroadcaster := &SIRISituationExchangeRequestBroadcaster{}
+	siriSituationExchangeRequestBroadcaster.partner = partner
+	return siriSituationExchangeRequestBroadcaster
+}
+
+func (connector *SIRISituationExchangeRequestBroadcaster) Situations(request *siri.XMLGetSituationExchange, message *audit.BigQueryMessage) (*siri.SIRISituationExchangeResponse, error) {
+	tx := connector.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	logStashEvent := connector.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+
+	logXMLSituationExchangeRequest(logStashEvent, &request.XMLSituationExchangeRequest)
+	logStashEvent["requestorRef"] = request.RequestorRef()
+
+	response := &siri.SIRISituationExchangeResponse{
+		Address:                   connector.Partner().Address(),
+		ProducerRef:               connector.Partner().ProducerRef(),
+		ResponseMessageIdentifier: connector.Partner().IdentifierGenerator(RESPONSE_MESSAGE_IDENTIFIER).NewMessageIdentifier(),
+	}
+
+	response.SIRISituationExchangeDelivery = connector.getSituationExchangeDelivery(tx, logStashEvent, &request.XMLSituationExchangeRequest)
+
+	if !response.SIRISituationExchangeDelivery.Status {
+		message.Status = "Error"
+		message.ErrorDetails = response.SIRISituationExchangeDelivery.ErrorString()
+	}
+	message.RequestIdentifier = request.MessageIdentifier()
+	message.ResponseIdentifier = response.ResponseMessageIdentifier
+	message.Lines = request.LineRef()
+	message.StopAreas = request.StopPointRef()
+
+	logSIRISituationExchangeDelivery(logStashEvent, response.SIRISituationExchangeDelivery)
+	logSIRISituationExchangeResponse(logStashEvent, response)
+
+	return response, nil
+}
+
+func (connector *SIRISituationExchangeRequestBroadcaster) getSituationExchangeDelivery(tx *model.Transaction, logStashEvent audit.LogStashEvent, request *siri.XMLSituationExchangeRequest) siri.SIRISituationExchangeDelivery {
+	delivery := siri.SIRISituationExchangeDelivery{
+		RequestMessageRef: request.MessageIdentifier(),
+		Status:            true,
+		ResponseTimestamp: connector.Clock().Now(),
+	}
+
+	var situationNumbers []string
+
+	builder := NewBroadcastSituationExchangeBuilder(tx, connector.Partner(), SIRI_SITUATION_EXCHANGE_REQUEST_BROADCASTER)
+	builder.SetLineRef(request.LineRef())
+	builder.SetStopPointRef(request.StopPointRef())
+
+	for _, situation := range tx.Model().Situations().FindAll() {
+		siriSituation := builder.BuildSituationExchange(situation)
+		if siriSituation == nil {
+			continue
+		}
+		situationNumbers = append(situationNumbers, siriSituation.SituationNumber)
+		delivery.Situations = append(delivery.Situations, siriSituation)
+	}
+
+	logStashEvent["situationNumbers"] = strings.Join(situationNumbers, ", ")
+
+	return delivery
+}
+
+func (connector *SIRISituationExchangeRequestBroadcaster) newLogStashEvent() audit.LogStashEvent {
+	event := connector.partner.NewLogStashEvent()
+	event["connector"] = "SituationExchangeRequestBroadcaster"
+	return event
+}
+
+func (factory *SIRISituationExchangeRequestBroadcasterFactory) Validate(apiPartner *APIPartner) {
+	apiPartner.ValidatePresenceOfSetting(REMOTE_OBJECTID_KIND)
+	apiPartner.ValidatePresenceOfLocalCredentials()
+}
+
+func (factory *SIRISituationExchangeRequestBroadcasterFactory) CreateConnector(partner *Partner) Connector {
+	return NewSIRISituationExchangeRequestBroadcaster(partner)
+}
+
+func logXMLSituationExchangeRequest(logStashEvent audit.LogStashEvent, request *siri.XMLSituationExchangeRequest) {
+	logStashEvent["siriType"] = "SituationExchangeResponse"
+	logStashEvent["messageIdentifier"] = request.MessageIdentifier()
+	logStashEvent["requestTimestamp"] = request.RequestTimestamp().String()
+	logStashEvent["lineRefs"] = strings.Join(request.LineRef(), ", ")
+	logStashEvent["stopPointRefs"] = strings.Join(request.StopPointRef(), ", ")
+	logStashEvent["requestXML"] = request.RawXML()
+}
+
+func logSIRISituationExchangeDelivery(logStashEvent audit.LogStashEvent, delivery siri.SIRISituationExchangeDelivery) {
+	logStashEvent["requestMessageRef"] = delivery.RequestMessageRef
+	logStashEvent["responseTimestamp"] = delivery.ResponseTimestamp.String()
+	logStashEvent["status"] = strconv.FormatBool(delivery.Status)
+	if !delivery.Status {
+		logStashEvent["errorType"] = delivery.ErrorType
+		if delivery.ErrorType == "OtherError" {
+			logStashEvent["errorNumber"] = strconv.Itoa(delivery.ErrorNumber)
+		}
+		logStashEvent["errorText"] = delivery.ErrorText
+	}
+}
+
+func logSIRISituationExchangeResponse(logStashEvent audit.LogStashEvent, response *siri.SIRISituationExchangeResponse) {
+	logStashEvent["address"] = response.Address
+	logStashEvent["producerRef"] = response.ProducerRef
+	logStashEvent["responseMessageIdentifier"] = response.ResponseMessageIdentifier
+	xml, err := response.BuildXML()
+	if err != nil {
+		logStashEvent["responseXML"] = fmt.Sprintf("%v", err)
+		return
+	}
+	logStashEvent["responseXML"] = xml
+}
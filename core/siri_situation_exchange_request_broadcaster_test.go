@@ -0,0 +1,89 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+func Test_SIRISituationExchangeRequestBroadcaster_RequestSituation(t *testing.T) {
+	referentials := NewMemoryReferentials()
+	referential := referentials.New("referential")
+	partner := referential.Partners().New("partner")
+	partner.SetSetting("local_url", "http://ara")
+	partner.SetSetting("remote_objectid_kind", "objectidKind")
+	partner.SetSetting("generators.response_message_identifier", "Ara:ResponseMessage::%{uuid}:LOC")
+
+	connector := NewSIRISituationExchangeRequestBroadcaster(partner)
+	connector.Partner().SetUUIDGenerator(uuid.NewFakeUUIDGenerator())
+	connector.SetClock(clock.NewFakeClock())
+
+	line := referential.Model().Lines().New()
+	lineObjectId := model.NewObjectID("objectidKind", "NINOXE:Line:3:LOC")
+	line.SetObjectID(lineObjectId)
+	line.Save()
+
+	situation := referential.Model().Situations().New()
+	situation.SetObjectID(model.NewObjectID("objectidKind", "NINOXE:Situation:27_1"))
+	situation.Summary = "Travaux sur la ligne"
+	situation.Severity = "slight"
+	situation.ValidityPeriods = []*model.TimeRange{{EndTime: referential.Clock().Now().Add(5 * time.Minute)}}
+	lineReference := model.NewReference(lineObjectId)
+	lineReference.Type = "LineRef"
+	situation.Affects = append(situation.Affects, lineReference)
+	situation.Save()
+
+	expiredSituation := referential.Model().Situations().New()
+	expiredSituation.SetObjectID(model.NewObjectID("objectidKind", "NINOXE:Situation:28_1"))
+	expiredSituation.Summary = "Expired"
+	expiredSituation.ValidityPeriods = []*model.TimeRange{{EndTime: referential.Clock().Now().Add(-5 * time.Minute)}}
+	expiredSituation.Affects = append(expiredSituation.Affects, lineReference)
+	expiredSituation.Save()
+
+	file, err := os.Open("testdata/situationexchange-request-soap.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, err := siri.NewXMLGetSituationExchangeFromContent(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, _ := connector.Situations(request, &audit.BigQueryMessage{})
+
+	if response.Address != "http://ara" {
+		t.Errorf("Response has wrong adress:\n got: %v\n want: http://ara", response.Address)
+	}
+	if response.ResponseMessageIdentifier != "Ara:ResponseMessage::6ba7b814-9dad-11d1-0-00c04fd430c8:LOC" {
+		t.Errorf("Response has wrong ResponseMessageIdentifier:\n got: %v", response.ResponseMessageIdentifier)
+	}
+	if response.RequestMessageRef != "SituationExchange:Test:0" {
+		t.Errorf("Response has wrong RequestMessageRef:\n got: %v\n want: SituationExchange:Test:0", response.RequestMessageRef)
+	}
+	if len(response.Situations) != 1 {
+		t.Fatalf("Response should have 1 Situation, got: %v", len(response.Situations))
+	}
+
+	siriSituation := response.Situations[0]
+	if siriSituation.SituationNumber != "NINOXE:Situation:27_1" {
+		t.Errorf("Wrong SituationNumber: %v", siriSituation.SituationNumber)
+	}
+	if siriSituation.Version != 1 {
+		t.Errorf("Wrong Version, expected: 1, got: %v", siriSituation.Version)
+	}
+	if len(siriSituation.AffectedLines) != 1 || siriSituation.AffectedLines[0] != "NINOXE:Line:3:LOC" {
+		t.Errorf("Wrong AffectedLines: %v", siriSituation.AffectedLines)
+	}
+}
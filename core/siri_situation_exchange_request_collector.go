@@ -0,0 +1,169 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+type SituationExchangeRequestCollector interface {
+	RequestSituationUpdate(kind, requestedId string)
+}
+
+type SIRISituationExchangeRequestCollectorFactory struct{}
+
+type SIRISituationExchangeRequestCollector struct {
+	clock.ClockConsumer
+	uuid.UUIDConsumer
+
+	siriConnector
+
+	situationUpdateSubscriber SituationUpdateSubscriber
+}
+
+func NewSIRISituationExchangeRequestCollector(partner *Partner) *SIRISituationExchangeRequestCollector {
+	siriSituationExchangeRequestCollector := &SIRISituationExchangeRequestCollector{}
+	siriSituationExchangeRequestCollector.partner = partner
+	manager := partner.Referential().CollectManager()
+	siriSituationExchangeRequestCollector.situationUpdateSubscriber = manager.BroadcastSituationUpdateEvent
+
+	return siriSituationExchangeRequestCollector
+}
+
+func (connector *SIRISituationExchangeRequestCollector) RequestAllSituationsUpdate() {}
+
+func (connector *SIRISituationExchangeRequestCollector) RequestSituationUpdate(kind, requestedId string) {
+	logStashEvent := connector.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+
+	message := connector.newBQEvent()
+	defer audit.CurrentBigQuery(string(connector.Partner().Referential().Slug())).WriteEvent(message)
+
+	startTime := connector.Clock().Now()
+
+	siriSituationExchangeRequest := &siri.SIRIGetSituationExchangeRequest{
+		RequestorRef: connector.SIRIPartner().RequestorRef(),
+	}
+	siriSituationExchangeRequest.MessageIdentifier = connector.Partner().IdentifierGenerator(MESSAGE_IDENTIFIER).NewMessageIdentifier()
+	siriSituationExchangeRequest.RequestTimestamp = connector.Clock().Now()
+
+	// Check the request filter
+	switch kind {
+	case SITUATION_UPDATE_REQUEST_LINE:
+		siriSituationExchangeRequest.LineRef = []string{requestedId}
+		logStashEvent["lineRef"] = requestedId
+		message.Lines = []string{requestedId}
+	case SITUATION_UPDATE_REQUEST_STOP_AREA:
+		siriSituationExchangeRequest.StopPointRef = []string{requestedId}
+		logStashEvent["stopPointRef"] = requestedId
+		message.StopAreas = []string{requestedId}
+	}
+
+	logSIRISituationExchangeRequest(logStashEvent, message, siriSituationExchangeRequest)
+
+	xmlSituationExchangeResponse, err := connector.SIRIPartner().SOAPClient().SituationExchange(siriSituationExchangeRequest)
+	logStashEvent["responseTime"] = connector.Clock().Since(startTime).String()
+	message.ProcessingTime = connector.Clock().Since(startTime).Seconds()
+	if err != nil {
+		e := fmt.Sprintf("Error during GetSituationExchange: %v", err)
+		logStashEvent["status"] = "false"
+		logStashEvent["errorDescription"] = e
+
+		message.Status = "Error"
+		message.ErrorDetails = e
+		return
+	}
+
+	logXMLSituationExchangeResponse(logStashEvent, message, xmlSituationExchangeResponse)
+	situationUpdateEvents := []*model.SituationUpdateEvent{}
+	connector.setSituationUpdateEvents(&situationUpdateEvents, xmlSituationExchangeResponse)
+
+	connector.broadcastSituationUpdateEvent(situationUpdateEvents)
+}
+
+func (connector *SIRISituationExchangeRequestCollector) setSituationUpdateEvents(situationEvents *[]*model.SituationUpdateEvent, xmlResponse *siri.XMLSituationExchangeResponse) {
+	builder := NewSituationExchangeUpdateEventBuilder(connector.partner)
+	builder.SetSituationExchangeResponseUpdateEvents(situationEvents, xmlResponse)
+}
+
+func (connector *SIRISituationExchangeRequestCollector) SetSituationUpdateSubscriber(situationUpdateSubscriber SituationUpdateSubscriber) {
+	connector.situationUpdateSubscriber = situationUpdateSubscriber
+}
+
+func (connector *SIRISituationExchangeRequestCollector) broadcastSituationUpdateEvent(event []*model.SituationUpdateEvent) {
+	if connector.situationUpdateSubscriber != nil {
+		connector.situationUpdateSubscriber(event)
+	}
+}
+
+func (connector *SIRISituationExchangeRequestCollector) newBQEvent() *audit.BigQueryMessage {
+	return &audit.BigQueryMessage{
+		Type:      "SituationExchangeRequest",
+		Protocol:  "siri",
+		Direction: "sent",
+		Partner:   string(connector.partner.Slug()),
+		Status:    "OK",
+	}
+}
+
+func (connector *SIRISituationExchangeRequestCollector) newLogStashEvent() audit.LogStashEvent {
+	event := connector.partner.NewLogStashEvent()
+	event["connector"] = "SituationExchangeRequestCollector"
+	return event
+}
+
+func (factory *SIRISituationExchangeRequestCollectorFactory) Validate(apiPartner *APIPartner) {
+	apiPartner.ValidatePresenceOfSetting(REMOTE_OBJECTID_KIND)
+	apiPartner.ValidatePresenceOfSetting(REMOTE_URL)
+	apiPartner.ValidatePresenceOfSetting(REMOTE_CREDENTIAL)
+}
+
+func (factory *SIRISituationExchangeRequestCollectorFactory) CreateConnector(partner *Partner) Connector {
+	return NewSIRISituationExchangeRequestCollector(partner)
+}
+
+func logSIRISituationExchangeRequest(logStashEvent audit.LogStashEvent, message *audit.BigQueryMessage, request *siri.SIRIGetSituationExchangeRequest) {
+	logStashEvent["siriType"] = "SituationExchangeRequest"
+	logStashEvent["messageIdentifier"] = request.MessageIdentifier
+	logStashEvent["requestorRef"] = request.RequestorRef
+	logStashEvent["requestTimestamp"] = request.RequestTimestamp.String()
+	xml, err := request.BuildXML()
+	if err != nil {
+		logStashEvent["requestXML"] = fmt.Sprintf("%v", err)
+		return
+	}
+	logStashEvent["requestXML"] = xml
+
+	message.RequestIdentifier = request.MessageIdentifier
+	message.RequestRawMessage = xml
+	message.RequestSize = int64(len(xml))
+}
+
+func logXMLSituationExchangeResponse(logStashEvent audit.LogStashEvent, message *audit.BigQueryMessage, response *siri.XMLSituationExchangeResponse) {
+	message.ResponseIdentifier = response.ResponseMessageIdentifier()
+
+	logStashEvent["address"] = response.Address()
+	logStashEvent["producerRef"] = response.ProducerRef()
+	logStashEvent["requestMessageRef"] = response.RequestMessageRef()
+	logStashEvent["responseMessageIdentifier"] = response.ResponseMessageIdentifier()
+	logStashEvent["responseTimestamp"] = response.ResponseTimestamp().String()
+	logStashEvent["status"] = strconv.FormatBool(response.Status())
+	if !response.Status() {
+		message.Status = "Error"
+		logStashEvent["errorType"] = response.ErrorType()
+		if response.ErrorType() == "OtherError" {
+			logStashEvent["errorNumber"] = strconv.Itoa(response.ErrorNumber())
+		}
+		logStashEvent["errorText"] = response.ErrorText()
+		logStashEvent["errorDescription"] = response.ErrorDescription()
+		message.ErrorDetails = response.ErrorString()
+	}
+	logStashEvent["responseXML"] = response.RawXML()
+	message.ResponseRawMessage = response.RawXML()
+	message.ResponseSize = int64(len(message.ResponseRawMessage))
+}
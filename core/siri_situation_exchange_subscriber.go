@@ -0,0 +1,254 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/state"
+)
+
+type SIRISituationExchangeSubscriber interface {
+	state.Stopable
+	state.Startable
+}
+
+type SXSubscriber struct {
+	clock.ClockConsumer
+
+	connector *SIRISituationExchangeSubscriptionCollector
+}
+
+type SituationExchangeSubscriber struct {
+	SXSubscriber
+
+	stop chan struct{}
+}
+
+type FakeSituationExchangeSubscriber struct {
+	SXSubscriber
+}
+
+func NewFakeSituationExchangeSubscriber(connector *SIRISituationExchangeSubscriptionCollector) SIRISituationExchangeSubscriber {
+	subscriber := &FakeSituationExchangeSubscriber{}
+	subscriber.connector = connector
+	return subscriber
+}
+
+func (subscriber *FakeSituationExchangeSubscriber) Start() {
+	subscriber.prepareSIRISituationExchangeSubscriptionRequest()
+}
+
+func (subscriber *FakeSituationExchangeSubscriber) Stop() {}
+
+func NewSIRISituationExchangeSubscriber(connector *SIRISituationExchangeSubscriptionCollector) SIRISituationExchangeSubscriber {
+	subscriber := &SituationExchangeSubscriber{}
+	subscriber.connector = connector
+	return subscriber
+}
+
+func (subscriber *SituationExchangeSubscriber) Start() {
+	logger.Log.Debugf("Start SituationExchangeSubscriber")
+
+	subscriber.stop = make(chan struct{})
+	go subscriber.run()
+}
+
+func (subscriber *SituationExchangeSubscriber) run() {
+	c := subscriber.Clock().After(5 * time.Second)
+
+	for {
+		select {
+		case <-subscriber.stop:
+			return
+		case <-c:
+			logger.Log.Debugf("SIRISituationExchangeSubscriber visit")
+
+			subscriber.prepareSIRISituationExchangeSubscriptionRequest()
+
+			c = subscriber.Clock().After(5 * time.Second)
+		}
+	}
+}
+
+func (subscriber *SituationExchangeSubscriber) Stop() {
+	if subscriber.stop != nil {
+		close(subscriber.stop)
+	}
+}
+
+func (subscriber *SXSubscriber) prepareSIRISituationExchangeSubscriptionRequest() {
+	subscriptions := subscriber.connector.partner.Subscriptions().FindSubscriptionsByKind("SituationExchangeCollect")
+	if len(subscriptions) == 0 {
+		logger.Log.Debugf("SituationExchangeSubscriber visit without SituationExchangeCollect subscriptions")
+		return
+	}
+
+	// LineRef for Logstash
+	lineRefList := []string{}
+	stopPointRefList := []string{}
+
+	resourcesToRequest := make(map[string]*resourceToRequest)
+	for _, subscription := range subscriptions {
+		for _, resource := range subscription.ResourcesByObjectIDCopy() {
+			if resource.SubscribedAt.IsZero() && resource.RetryCount <= 10 {
+				messageIdentifier := subscriber.connector.Partner().IdentifierGenerator(MESSAGE_IDENTIFIER).NewMessageIdentifier()
+				logger.Log.Debugf("send request for subscription with id : %v", subscription.id)
+				resourcesToRequest[messageIdentifier] = &resourceToRequest{
+					subId:    subscription.id,
+					objectId: *(resource.Reference.ObjectId),
+					kind:     resource.Reference.Type,
+				}
+			}
+		}
+	}
+
+	if len(resourcesToRequest) == 0 {
+		return
+	}
+
+	logStashEvent := subscriber.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+
+	message := subscriber.newBQEvent()
+	defer audit.CurrentBigQuery(string(subscriber.connector.Partner().Referential().Slug())).WriteEvent(message)
+
+	sxRequest := &siri.SIRISituationExchangeSubscriptionRequest{
+		ConsumerAddress:   subscriber.connector.Partner().Address(),
+		MessageIdentifier: subscriber.connector.Partner().IdentifierGenerator(MESSAGE_IDENTIFIER).NewMessageIdentifier(),
+		RequestorRef:      subscriber.connector.SIRIPartner().RequestorRef(),
+		RequestTimestamp:  subscriber.Clock().Now(),
+	}
+
+	for messageIdentifier, requestedResource := range resourcesToRequest {
+		entry := &siri.SIRISituationExchangeSubscriptionRequestEntry{
+			SubscriberRef:          subscriber.connector.SIRIPartner().RequestorRef(),
+			SubscriptionIdentifier: string(requestedResource.subId),
+			InitialTerminationTime: subscriber.Clock().Now().Add(48 * time.Hour),
+		}
+		entry.MessageIdentifier = messageIdentifier
+		entry.RequestTimestamp = subscriber.Clock().Now()
+		switch requestedResource.kind {
+		case "Line":
+			entry.LineRef = []string{requestedResource.objectId.Value()}
+			lineRefList = append(lineRefList, requestedResource.objectId.Value())
+		case "StopArea":
+			entry.StopPointRef = []string{requestedResource.objectId.Value()}
+			stopPointRefList = append(stopPointRefList, requestedResource.objectId.Value())
+		}
+
+		sxRequest.Entries = append(sxRequest.Entries, entry)
+	}
+
+	logStashEvent["lineRefs"] = strings.Join(lineRefList, ", ")
+	logStashEvent["stopPointRefs"] = strings.Join(stopPointRefList, ", ")
+	logSIRISituationExchangeSubscriptionRequest(logStashEvent, sxRequest)
+
+	message.RequestIdentifier = sxRequest.MessageIdentifier
+	message.RequestRawMessage, _ = sxRequest.BuildXML()
+	message.RequestSize = int64(len(message.RequestRawMessage))
+	message.StopAreas = stopPointRefList
+	message.SubscriptionIdentifiers = lineRefList
+
+	startTime := subscriber.Clock().Now()
+	response, err := subscriber.connector.SIRIPartner().SOAPClient().SituationExchangeSubscription(sxRequest)
+	logStashEvent["responseTime"] = subscriber.Clock().Since(startTime).String()
+	message.ProcessingTime = subscriber.Clock().Since(startTime).Seconds()
+	if err != nil {
+		logger.Log.Debugf("Error while subscribing: %v", err)
+		e := fmt.Sprintf("Error during SituationExchangeSubscriptionRequest: %v", err)
+		logStashEvent["status"] = "false"
+		logStashEvent["errorDescription"] = e
+		subscriber.incrementRetryCountFromMap(resourcesToRequest)
+
+		message.Status = "Error"
+		message.ErrorDetails = e
+		return
+	}
+
+	logStashEvent["responseXML"] = response.RawXML()
+	message.ResponseRawMessage = response.RawXML()
+	message.ResponseSize = int64(len(message.ResponseRawMessage))
+
+	for _, responseStatus := range response.ResponseStatus() {
+		requestedResource, ok := resourcesToRequest[responseStatus.RequestMessageRef()]
+		if !ok {
+			logger.Log.Debugf("ResponseStatus RequestMessageRef unknown: %v", responseStatus.RequestMessageRef())
+			continue
+		}
+		delete(resourcesToRequest, responseStatus.RequestMessageRef()) // See #4691
+
+		subscription, ok := subscriber.connector.partner.Subscriptions().Find(requestedResource.subId)
+		if !ok { // Should never happen
+			logger.Log.Debugf("Response for unknown subscription %v", requestedResource.subId)
+			continue
+		}
+		resource := subscription.Resource(requestedResource.objectId)
+		if resource == nil { // Should never happen
+			logger.Log.Debugf("Response for unknown subscription resource %v", requestedResource.objectId.String())
+			continue
+		}
+
+		if !responseStatus.Status() {
+			logger.Log.Debugf("Subscription status false for line %v: %v %v ", requestedResource.objectId.Value(), responseStatus.ErrorType(), responseStatus.ErrorText())
+			resource.RetryCount++
+			message.Status = "Error"
+			continue
+		}
+		resource.SubscribedAt = subscriber.Clock().Now()
+		resource.RetryCount = 0
+	}
+	// Should not happen but see #4691
+	if len(resourcesToRequest) == 0 {
+		return
+	}
+	subscriber.incrementRetryCountFromMap(resourcesToRequest)
+}
+
+func (subscriber *SXSubscriber) incrementRetryCountFromMap(resourcesToRequest map[string]*resourceToRequest) {
+	for _, requestedResource := range resourcesToRequest {
+		subscription, ok := subscriber.connector.partner.Subscriptions().Find(requestedResource.subId)
+		if !ok { // Should never happen
+			continue
+		}
+		resource := subscription.Resource(requestedResource.objectId)
+		if resource == nil { // Should never happen
+			continue
+		}
+		resource.RetryCount++
+	}
+}
+
+func (subscriber *SXSubscriber) newBQEvent() *audit.BigQueryMessage {
+	return &audit.BigQueryMessage{
+		Type:      "SituationExchangeSubscriptionRequest",
+		Protocol:  "siri",
+		Direction: "sent",
+		Partner:   string(subscriber.connector.partner.Slug()),
+		Status:    "OK",
+	}
+}
+
+func (smb *SXSubscriber) newLogStashEvent() audit.LogStashEvent {
+	event := smb.connector.partner.NewLogStashEvent()
+	event["connector"] = "SituationExchangeSubscriptionCollector"
+	return event
+}
+
+func logSIRISituationExchangeSubscriptionRequest(logStashEvent audit.LogStashEvent, request *siri.SIRISituationExchangeSubscriptionRequest) {
+	logStashEvent["siriType"] = "SituationExchangeSubscriptionRequest"
+	logStashEvent["consumerAddress"] = request.ConsumerAddress
+	logStashEvent["messageIdentifier"] = request.MessageIdentifier
+	logStashEvent["requestorRef"] = request.RequestorRef
+	logStashEvent["requestTimestamp"] = request.RequestTimestamp.String()
+	xml, err := request.BuildXML()
+	if err != nil {
+		logStashEvent["requestXML"] = fmt.Sprintf("%v", err)
+		return
+	}
+	logStashEvent["requestXML"] = xml
+}
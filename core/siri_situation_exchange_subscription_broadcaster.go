@@ -0,0 +1,234 @@
+package core
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+type SIRISituationExchangeSubscriptionBroadcaster struct {
+	clock.ClockConsumer
+	uuid.UUIDConsumer
+
+	siriConnector
+
+	situationExchangeBroadcaster SIRISituationExchangeBroadcaster
+	toBroadcast                  map[SubscriptionId][]model.SituationId
+	mutex                        *sync.Mutex //protect the map
+}
+
+type SIRISituationExchangeSubscriptionBroadcasterFactory struct{}
+
+func (factory *SIRISituationExchangeSubscriptionBroadcasterFactory) CreateConnector(partner *Partner) Connector {
+	if _, ok := partner.Connector(SIRI_SUBSCRIPTION_REQUEST_DISPATCHER); !ok {
+		partner.CreateSubscriptionRequestDispatcher()
+	}
+	return newSIRISituationExchangeSubscriptionBroadcaster(partner)
+}
+
+func (factory *SIRISituationExchangeSubscriptionBroadcasterFactory) Validate(apiPartner *APIPartner) {
+	apiPartner.ValidatePresenceOfSetting(REMOTE_OBJECTID_KIND)
+	apiPartner.ValidatePresenceOfSetting(REMOTE_URL)
+	apiPartner.ValidatePresenceOfSetting(REMOTE_CREDENTIAL)
+	apiPartner.ValidatePresenceOfSetting(LOCAL_CREDENTIAL)
+}
+
+func newSIRISituationExchangeSubscriptionBroadcaster(partner *Partner) *SIRISituationExchangeSubscriptionBroadcaster {
+	connector := &SIRISituationExchangeSubscriptionBroadcaster{}
+	connector.partner = partner
+	connector.mutex = &sync.Mutex{}
+	connector.toBroadcast = make(map[SubscriptionId][]model.SituationId)
+
+	connector.situationExchangeBroadcaster = NewSIRISituationExchangeBroadcaster(connector)
+
+	return connector
+}
+
+func (connector *SIRISituationExchangeSubscriptionBroadcaster) Stop() {
+	if connector.situationExchangeBroadcaster != nil {
+		connector.situationExchangeBroadcaster.Stop()
+	}
+}
+
+func (connector *SIRISituationExchangeSubscriptionBroadcaster) Start() {
+	if connector.situationExchangeBroadcaster == nil {
+		connector.situationExchangeBroadcaster = NewSIRISituationExchangeBroadcaster(connector)
+	}
+	connector.situationExchangeBroadcaster.Start()
+}
+
+func (connector *SIRISituationExchangeSubscriptionBroadcaster) HandleGeneralMessageBroadcastEvent(event *model.GeneralMessageBroadcastEvent) {
+	connector.checkEvent(event.SituationId)
+}
+
+func (connector *SIRISituationExchangeSubscriptionBroadcaster) addSituation(subId SubscriptionId, sId model.SituationId) {
+	connector.mutex.Lock()
+	connector.toBroadcast[subId] = append(connector.toBroadcast[subId], sId)
+	connector.mutex.Unlock()
+}
+
+func (connector *SIRISituationExchangeSubscriptionBroadcaster) checkEvent(sId model.SituationId) {
+	tx := connector.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	situation, ok := tx.Model().Situations().Find(sId)
+	if !ok || situation.Origin == string(connector.partner.Slug()) {
+		return
+	}
+
+	obj := model.NewObjectID("SituationResource", "Situation")
+	subs := connector.partner.Subscriptions().FindSubscriptionsByKind("SituationExchangeBroadcast")
+
+	for _, sub := range subs {
+		resource := sub.Resource(obj)
+		if resource == nil || resource.SubscribedUntil.Before(connector.Clock().Now()) {
+			continue
+		}
+
+		lastState, ok := resource.LastState(string(situation.Id()))
+
+		if ok && !lastState.(*generalMessageLastChange).Haschanged(&situation) {
+			continue
+		}
+
+		if !ok {
+			gmlc := &generalMessageLastChange{}
+			gmlc.InitState(&situation, sub)
+			resource.SetLastState(string(situation.Id()), gmlc)
+		}
+		connector.addSituation(sub.Id(), sId)
+	}
+}
+
+func (connector *SIRISituationExchangeSubscriptionBroadcaster) HandleSubscriptionRequest(request *siri.XMLSubscriptionRequest, message *audit.BigQueryMessage) []siri.SIRIResponseStatus {
+	resps := []siri.SIRIResponseStatus{}
+
+	var subIds []string
+
+	for _, sx := range request.XMLSubscriptionSXEntries() {
+		logStashEvent := connector.newLogStashEvent()
+		logXMLSituationExchangeSubscriptionEntry(logStashEvent, sx)
+
+		rs := siri.SIRIResponseStatus{
+			RequestMessageRef: sx.MessageIdentifier(),
+			SubscriberRef:     sx.SubscriberRef(),
+			SubscriptionRef:   sx.SubscriptionIdentifier(),
+			Status:            true,
+			ResponseTimestamp: connector.Clock().Now(),
+			ValidUntil:        sx.InitialTerminationTime(),
+		}
+
+		subIds = append(subIds, sx.SubscriptionIdentifier())
+
+		sub, ok := connector.Partner().Subscriptions().FindByExternalId(sx.SubscriptionIdentifier())
+		if !ok {
+			sub = connector.Partner().Subscriptions().New("SituationExchangeBroadcast")
+			sub.SetExternalId(sx.SubscriptionIdentifier())
+		}
+
+		sub.SetSubscriptionOption("LineRef", strings.Join(sx.LineRef(), ","))
+		sub.SetSubscriptionOption("StopPointRef", strings.Join(sx.StopPointRef(), ","))
+		sub.SetSubscriptionOption("MessageIdentifier", sx.MessageIdentifier())
+
+		obj := model.NewObjectID("SituationResource", "Situation")
+		r := sub.Resource(obj)
+		if r == nil {
+			ref := model.Reference{
+				ObjectId: &obj,
+				Type:     "Situation",
+			}
+			r = sub.CreateAddNewResource(ref)
+			r.SubscribedAt = connector.Clock().Now()
+			r.SubscribedUntil = sx.InitialTerminationTime()
+		}
+
+		sub.Save()
+
+		connector.addSituations(sub, r)
+		resps = append(resps, rs)
+
+		logSIRISituationExchangeSubscriptionResponseEntry(logStashEvent, &rs)
+		audit.CurrentLogStash().WriteEvent(logStashEvent)
+	}
+
+	message.Type = "SituationExchangeSubscriptionRequest"
+	message.SubscriptionIdentifiers = subIds
+
+	return resps
+}
+
+func (connector *SIRISituationExchangeSubscriptionBroadcaster) addSituations(sub *Subscription, r *SubscribedResource) {
+	for _, situation := range connector.partner.Model().Situations().FindAll() {
+		gmlc := &generalMessageLastChange{}
+		gmlc.InitState(&situation, sub)
+		r.SetLastState(string(situation.Id()), gmlc)
+		connector.addSituation(sub.Id(), situation.Id())
+	}
+}
+
+func (connector *SIRISituationExchangeSubscriptionBroadcaster) newLogStashEvent() audit.LogStashEvent {
+	event := connector.partner.NewLogStashEvent()
+	event["connector"] = "SituationExchangeSubscriptionBroadcaster"
+	return event
+}
+
+func logXMLSituationExchangeSubscriptionEntry(logStashEvent audit.LogStashEvent, request *siri.XMLSituationExchangeSubscriptionRequestEntry) {
+	logStashEvent["siriType"] = "SituationExchangeSubscriptionEntry"
+	logStashEvent["messageIdentifier"] = request.MessageIdentifier()
+	logStashEvent["requestTimestamp"] = request.RequestTimestamp().String()
+	logStashEvent["stopPointRefs"] = strings.Join(request.StopPointRef(), ", ")
+	logStashEvent["lineRefs"] = strings.Join(request.LineRef(), ", ")
+	logStashEvent["subscriberRef"] = request.SubscriberRef()
+	logStashEvent["subscriptionIdentifier"] = request.SubscriptionIdentifier()
+	logStashEvent["initialTerminationTime"] = request.InitialTerminationTime().String()
+	logStashEvent["requestXML"] = request.RawXML()
+}
+
+func logSIRISituationExchangeSubscriptionResponseEntry(logStashEvent audit.LogStashEvent, sxEntry *siri.SIRIResponseStatus) {
+	logStashEvent["requestMessageRef"] = sxEntry.RequestMessageRef
+	logStashEvent["subscriptionRef"] = sxEntry.SubscriptionRef
+	logStashEvent["responseTimestamp"] = sxEntry.ResponseTimestamp.String()
+	logStashEvent["validUntil"] = sxEntry.ValidUntil.String()
+	logStashEvent["status"] = strconv.FormatBool(sxEntry.Status)
+	if !sxEntry.Status {
+		logStashEvent["errorType"] = sxEntry.ErrorType
+		if sxEntry.ErrorType == "OtherError" {
+			logStashEvent["errorNumber"] = strconv.Itoa(sxEntry.ErrorNumber)
+		}
+		logStashEvent["errorText"] = sxEntry.ErrorText
+	}
+}
+
+// Start Test
+
+type TestSIRISituationExchangeSubscriptionBroadcasterFactory struct{}
+
+type TestSituationExchangeSubscriptionBroadcaster struct {
+	uuid.UUIDConsumer
+
+	events []*model.GeneralMessageBroadcastEvent
+}
+
+func NewTestSituationExchangeSubscriptionBroadcaster() *TestSituationExchangeSubscriptionBroadcaster {
+	connector := &TestSituationExchangeSubscriptionBroadcaster{}
+	return connector
+}
+
+func (connector *TestSituationExchangeSubscriptionBroadcaster) HandleGeneralMessageBroadcastEvent(event *model.GeneralMessageBroadcastEvent) {
+	connector.events = append(connector.events, event)
+}
+
+func (factory *TestSIRISituationExchangeSubscriptionBroadcasterFactory) Validate(apiPartner *APIPartner) {
+} // Always valid
+
+func (factory *TestSIRISituationExchangeSubscriptionBroadcasterFactory) CreateConnector(partner *Partner) Connector {
+	return NewTestSituationExchangeSubscriptionBroadcaster()
+}
+
+// END OF TEST
@@ -0,0 +1,250 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/state"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+type SituationExchangeSubscriptionCollector interface {
+	state.Stopable
+	state.Startable
+
+	RequestAllSituationsUpdate()
+	RequestSituationUpdate(kind string, requestedId model.ObjectID)
+	HandleNotifySituationExchange(notify *siri.XMLNotifySituationExchange)
+}
+
+type SIRISituationExchangeSubscriptionCollector struct {
+	uuid.UUIDConsumer
+	clock.ClockConsumer
+
+	siriConnector
+
+	situationExchangeSubscriber SIRISituationExchangeSubscriber
+	situationUpdateSubscriber   SituationUpdateSubscriber
+}
+
+type SIRISituationExchangeSubscriptionCollectorFactory struct{}
+
+func (factory *SIRISituationExchangeSubscriptionCollectorFactory) CreateConnector(partner *Partner) Connector {
+	return NewSIRISituationExchangeSubscriptionCollector(partner)
+}
+
+func (factory *SIRISituationExchangeSubscriptionCollectorFactory) Validate(apiPartner *APIPartner) {
+	apiPartner.ValidatePresenceOfSetting(REMOTE_OBJECTID_KIND)
+	apiPartner.ValidatePresenceOfSetting(REMOTE_URL)
+	apiPartner.ValidatePresenceOfSetting(REMOTE_CREDENTIAL)
+	apiPartner.ValidatePresenceOfLocalCredentials()
+}
+
+func NewSIRISituationExchangeSubscriptionCollector(partner *Partner) *SIRISituationExchangeSubscriptionCollector {
+	connector := &SIRISituationExchangeSubscriptionCollector{}
+	connector.partner = partner
+	manager := partner.Referential().CollectManager()
+	connector.situationUpdateSubscriber = manager.BroadcastSituationUpdateEvent
+	connector.situationExchangeSubscriber = NewSIRISituationExchangeSubscriber(connector)
+
+	return connector
+}
+
+func (connector *SIRISituationExchangeSubscriptionCollector) Stop() {
+	connector.situationExchangeSubscriber.Stop()
+}
+
+func (connector *SIRISituationExchangeSubscriptionCollector) Start() {
+	connector.situationExchangeSubscriber.Start()
+}
+
+func (connector *SIRISituationExchangeSubscriptionCollector) RequestAllSituationsUpdate() {
+	obj := model.NewObjectID("situationExchangeCollect", "all")
+	connector.RequestSituationUpdate("all", obj)
+}
+
+func (connector *SIRISituationExchangeSubscriptionCollector) RequestSituationUpdate(kind string, requestedObjectId model.ObjectID) {
+	// Try to find a Subscription with the resource
+	subscriptions := connector.partner.Subscriptions().FindByResourceId(requestedObjectId.String(), "SituationExchangeCollect")
+	if len(subscriptions) > 0 {
+		for _, subscription := range subscriptions {
+			resource := subscription.Resource(requestedObjectId)
+			if resource == nil { // Should never happen
+				logger.Log.Debugf("Can't find resource in subscription after Subscriptions#FindByResourceId")
+				return
+			}
+			if !resource.SubscribedAt.IsZero() {
+				resource.SubscribedUntil = connector.Clock().Now().Add(2 * time.Minute)
+			}
+		}
+		return
+	}
+
+	// Else we find or create a subscription to add the resource
+	newSubscription := connector.partner.Subscriptions().FindOrCreateByKind("SituationExchangeCollect")
+	ref := model.Reference{
+		ObjectId: &requestedObjectId,
+	}
+	switch kind {
+	case SITUATION_UPDATE_REQUEST_LINE:
+		ref.Type = "Line"
+	case SITUATION_UPDATE_REQUEST_STOP_AREA:
+		ref.Type = "StopArea"
+	}
+
+	newSubscription.CreateAddNewResource(ref)
+}
+
+func (connector *SIRISituationExchangeSubscriptionCollector) HandleNotifySituationExchange(notify *siri.XMLNotifySituationExchange) {
+	logStashEvent := connector.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+	subscriptionErrors := make(map[string]string)
+	subToDelete := make(map[string]struct{})
+
+	logXMLSituationExchangeDelivery(logStashEvent, notify)
+
+	situationUpdateEvents := &[]*model.SituationUpdateEvent{}
+	builder := NewSituationExchangeUpdateEventBuilder(connector.partner)
+
+	for _, delivery := range notify.SituationExchangesDeliveries() {
+		subscriptionId := delivery.SubscriptionRef()
+		subscription, ok := connector.Partner().Subscriptions().Find(SubscriptionId(subscriptionId))
+		if !ok {
+			logger.Log.Printf("Partner %s sent a NotifySituationExchange to a non existant subscription of id: %s\n", connector.Partner().Slug(), subscriptionId)
+			subscriptionErrors[subscriptionId] = "Non existant subscription of id %s"
+			subToDelete[delivery.SubscriptionRef()] = struct{}{}
+			continue
+		}
+
+		if subscription.Kind() != "SituationExchangeCollect" {
+			logger.Log.Printf("Partner %s sent a NotifySituationExchange to a subscription with kind: %s\n", connector.Partner().Slug(), subscription.Kind())
+			subscriptionErrors[subscriptionId] = "Subscription of id %s is not a subscription of kind SituationExchangeCollect"
+			continue
+		}
+		connector.closeSituations(delivery)
+
+		builder.SetSituationExchangeDeliveryUpdateEvents(situationUpdateEvents, delivery, notify.ProducerRef())
+
+		if len(subscriptionErrors) != 0 {
+			logSubscriptionErrorsFromMap(logStashEvent, subscriptionErrors)
+		}
+		connector.broadcastSituationUpdateEvent(*situationUpdateEvents)
+	}
+
+	for subId := range subToDelete {
+		connector.cancelSubscription(subId)
+	}
+}
+
+func (connector *SIRISituationExchangeSubscriptionCollector) cancelSubscription(subId string) {
+	logStashEvent := connector.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+	message := connector.newBQEvent()
+	defer audit.CurrentBigQuery(string(connector.Partner().Referential().Slug())).WriteEvent(message)
+
+	request := &siri.SIRIDeleteSubscriptionRequest{
+		RequestTimestamp:  connector.Clock().Now(),
+		SubscriptionRef:   subId,
+		RequestorRef:      connector.partner.ProducerRef(),
+		MessageIdentifier: connector.Partner().IdentifierGenerator(MESSAGE_IDENTIFIER).NewMessageIdentifier(),
+	}
+
+	logSIRIDeleteSubscriptionRequest(logStashEvent, message, request, "SituationExchangeSubscriptionCollector")
+	startTime := connector.Clock().Now()
+	response, err := connector.SIRIPartner().SOAPClient().DeleteSubscription(request)
+
+	responseTime := connector.Clock().Since(startTime)
+	logStashEvent["responseTime"] = responseTime.String()
+	message.ProcessingTime = responseTime.Seconds()
+
+	if err != nil {
+		logger.Log.Debugf("Error while terminating subcription with id : %v error : %v", subId, err.Error())
+		e := fmt.Sprintf("Error during DeleteSubscription: %v", err)
+		logStashEvent["status"] = "false"
+		logStashEvent["response"] = e
+		message.Status = "Error"
+		message.ErrorDetails = e
+		return
+	}
+
+	logXMLDeleteSubscriptionResponse(logStashEvent, message, response)
+}
+
+// Delete the situations with a closed Progress
+func (connector *SIRISituationExchangeSubscriptionCollector) closeSituations(xmlResponse *siri.XMLSituationExchangeDelivery) {
+	tx := connector.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	var closed bool
+	for _, xmlSituation := range xmlResponse.XMLPtSituationElements() {
+		if xmlSituation.Progress() != "closed" {
+			continue
+		}
+		obj := model.NewObjectID(connector.partner.Setting(REMOTE_OBJECTID_KIND), xmlSituation.SituationNumber())
+		situation, ok := tx.Model().Situations().FindByObjectId(obj)
+		if ok {
+			logger.Log.Debugf("Deleting situation %v cause of closed progress", situation.Id())
+			tx.Model().Situations().Delete(&situation)
+			closed = true
+		}
+	}
+	if closed {
+		tx.Commit()
+	}
+}
+
+func (connector *SIRISituationExchangeSubscriptionCollector) SetSituationExchangeSubscriber(situationExchangeSubscriber SIRISituationExchangeSubscriber) {
+	connector.situationExchangeSubscriber = situationExchangeSubscriber
+}
+
+func (connector *SIRISituationExchangeSubscriptionCollector) SetSituationUpdateSubscriber(situationUpdateSubscriber SituationUpdateSubscriber) {
+	connector.situationUpdateSubscriber = situationUpdateSubscriber
+}
+
+func (connector *SIRISituationExchangeSubscriptionCollector) broadcastSituationUpdateEvent(event []*model.SituationUpdateEvent) {
+	if connector.situationUpdateSubscriber != nil {
+		connector.situationUpdateSubscriber(event)
+	}
+}
+
+func (connector *SIRISituationExchangeSubscriptionCollector) newLogStashEvent() audit.LogStashEvent {
+	event := connector.partner.NewLogStashEvent()
+	event["connector"] = "SituationExchangeSubscriptionCollector"
+	return event
+}
+
+func (connector *SIRISituationExchangeSubscriptionCollector) newBQEvent() *audit.BigQueryMessage {
+	return &audit.BigQueryMessage{
+		Protocol:  "siri",
+		Direction: "sent",
+		Partner:   string(connector.partner.Slug()),
+		Status:    "OK",
+	}
+}
+
+func logXMLSituationExchangeDelivery(logStashEvent audit.LogStashEvent, notify *siri.XMLNotifySituationExchange) {
+	logStashEvent["siriType"] = "CollectedNotifySituationExchange"
+	logStashEvent["address"] = notify.Address()
+	logStashEvent["producerRef"] = notify.ProducerRef()
+	logStashEvent["requestMessageRef"] = notify.RequestMessageRef()
+	logStashEvent["responseMessageIdentifier"] = notify.ResponseMessageIdentifier()
+	logStashEvent["responseTimestamp"] = notify.ResponseTimestamp().String()
+	logStashEvent["responseXML"] = notify.RawXML()
+
+	status := "true"
+	errorCount := 0
+	for _, delivery := range notify.SituationExchangesDeliveries() {
+		if !delivery.Status() {
+			status = "false"
+			errorCount++
+		}
+	}
+	logStashEvent["status"] = status
+	logStashEvent["errorCount"] = strconv.Itoa(errorCount)
+}
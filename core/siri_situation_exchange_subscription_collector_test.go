@@ -0,0 +1,113 @@
+package core
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+func Test_SIRISituationExchangeSubscriptionCollector(t *testing.T) {
+	request := &siri.XMLSubscriptionRequest{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength <= 0 {
+			t.Errorf("Request ContentLength should be zero")
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		var err error
+		request, err = siri.NewXMLSubscriptionRequestFromContent(body)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+	}))
+	defer ts.Close()
+
+	referentials := NewMemoryReferentials()
+	referential := referentials.New(ReferentialSlug("referential"))
+	referential.model = model.NewMemoryModel()
+	referentials.Save(referential)
+
+	partners := NewPartnerManager(referential)
+
+	partner := partners.New("slug")
+	partner.SetSettingsDefinition(map[string]string{
+		"local_url":            "http://example.com/test/siri",
+		"remote_url":           ts.URL,
+		"remote_objectid_kind": "test_kind",
+	})
+	partner.subscriptionManager = NewMemorySubscriptions(partner)
+	partners.Save(partner)
+
+	line := partners.Model().Lines().New()
+	lineObjectID := model.NewObjectID("test_kind", "line value")
+	line.SetObjectID(lineObjectID)
+	partners.Model().Lines().Save(&line)
+
+	connector := NewSIRISituationExchangeSubscriptionCollector(partner)
+	connector.SetSituationExchangeSubscriber(NewFakeSituationExchangeSubscriber(connector))
+
+	connector.RequestSituationUpdate(SITUATION_UPDATE_REQUEST_LINE, lineObjectID)
+	connector.Start()
+
+	if expected := "http://example.com/test/siri"; request.ConsumerAddress() != expected {
+		t.Errorf("Wrong ConsumerAddress:\n got: %v\nwant: %v", request.ConsumerAddress(), expected)
+	}
+
+	entries := request.XMLSubscriptionSXEntries()
+	if len(entries) != 1 {
+		t.Fatalf("Wrong XMLSubscriptionEntries:\n got: %v\nwant: 1", len(entries))
+	}
+	if lineRefs := entries[0].LineRef(); len(lineRefs) != 1 || lineRefs[0] != "line value" {
+		t.Errorf("Wrong LineRef:\n got: %v\nwant: [line value]", lineRefs)
+	}
+}
+
+func Test_SIRISituationExchangeSubscriptionCollector_HandleNotifySituationExchange(t *testing.T) {
+	referentials := NewMemoryReferentials()
+	referential := referentials.New(ReferentialSlug("referential"))
+	referential.model = model.NewMemoryModel()
+	referentials.Save(referential)
+	partners := NewPartnerManager(referential)
+
+	partner := partners.New("slug")
+	partner.SetSettingsDefinition(map[string]string{
+		"remote_objectid_kind": "test_kind",
+	})
+	subscriptions := NewMemorySubscriptions(partner)
+	subscriptions.SetUUIDGenerator(uuid.NewFakeUUIDGenerator())
+	partner.subscriptionManager = subscriptions
+	partners.Save(partner)
+
+	subscription := partner.Subscriptions().New("SituationExchangeCollect")
+	subscription.Save()
+
+	situation := referential.Model().Situations().New()
+	situation.SetObjectID(model.NewObjectID("test_kind", "NINOXE:Situation:27_1"))
+	situation.Save()
+
+	file, _ := os.Open("testdata/notify-situation-exchange.xml")
+	content, _ := ioutil.ReadAll(file)
+
+	connector := NewSIRISituationExchangeSubscriptionCollector(partner)
+	events := []*model.SituationUpdateEvent{}
+	connector.SetSituationUpdateSubscriber(func(e []*model.SituationUpdateEvent) {
+		events = append(events, e...)
+	})
+
+	notify, _ := siri.NewXMLNotifySituationExchangeFromContent(content)
+
+	connector.HandleNotifySituationExchange(notify)
+
+	if _, ok := referential.Model().Situations().Find(situation.Id()); ok {
+		t.Errorf("Closed situation should be deleted")
+	}
+	if len(events) != 0 {
+		t.Errorf("No SituationUpdateEvent should be broadcasted for a closed situation, got: %v", len(events))
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"bitbucket.org/enroute-mobi/ara/audit"
 	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
 	"bitbucket.org/enroute-mobi/ara/siri"
 )
 
@@ -15,12 +16,16 @@ type SIRIStopPointsDiscoveryRequestBroadcaster struct {
 	clock.ClockConsumer
 
 	siriConnector
+
+	connectorType string
 }
 
 type SIRIStopPointsDiscoveryRequestBroadcasterFactory struct{}
 
 func NewSIRIStopDiscoveryRequestBroadcaster(partner *Partner) *SIRIStopPointsDiscoveryRequestBroadcaster {
-	siriStopDiscoveryRequestBroadcaster := &SIRIStopPointsDiscoveryRequestBroadcaster{}
+	siriStopDiscoveryRequestBroadcaster := &SIRIStopPointsDiscoveryRequestBroadcaster{
+		connectorType: SIRI_STOP_POINTS_DISCOVERY_REQUEST_BROADCASTER,
+	}
 	siriStopDiscoveryRequestBroadcaster.partner = partner
 	return siriStopDiscoveryRequestBroadcaster
 }
@@ -34,6 +39,17 @@ func (connector *SIRIStopPointsDiscoveryRequestBroadcaster) StopAreas(request *s
 
 	logXMLStopPointDiscoveryRequest(logStashEvent, request)
 
+	response, annotedStopPointMap := connector.getStopPointsDiscoveryResponse(tx)
+
+	message.RequestIdentifier = request.MessageIdentifier()
+
+	logAnnotatedStopPoints(annotedStopPointMap, logStashEvent, message)
+	logSIRIStopPointDiscoveryResponse(logStashEvent, response)
+
+	return response, nil
+}
+
+func (connector *SIRIStopPointsDiscoveryRequestBroadcaster) getStopPointsDiscoveryResponse(tx *model.Transaction) (*siri.SIRIStopPointsDiscoveryResponse, map[string]struct{}) {
 	response := &siri.SIRIStopPointsDiscoveryResponse{
 		Status:            true,
 		ResponseTimestamp: connector.Clock().Now(),
@@ -41,7 +57,7 @@ func (connector *SIRIStopPointsDiscoveryRequestBroadcaster) StopAreas(request *s
 
 	annotedStopPointMap := make(map[string]struct{})
 
-	objectIDKind := connector.partner.RemoteObjectIDKind(SIRI_STOP_POINTS_DISCOVERY_REQUEST_BROADCASTER)
+	objectIDKind := connector.partner.RemoteObjectIDKind(connector.connectorType)
 	for _, stopArea := range tx.Model().StopAreas().FindAll() {
 		if stopArea.Name == "" || !stopArea.CollectedAlways {
 			continue
@@ -81,12 +97,7 @@ func (connector *SIRIStopPointsDiscoveryRequestBroadcaster) StopAreas(request *s
 
 	sort.Sort(siri.SIRIAnnotatedStopPointByStopPointRef(response.AnnotatedStopPoints))
 
-	message.RequestIdentifier = request.MessageIdentifier()
-
-	logAnnotatedStopPoints(annotedStopPointMap, logStashEvent, message)
-	logSIRIStopPointDiscoveryResponse(logStashEvent, response)
-
-	return response, nil
+	return response, annotedStopPointMap
 }
 
 func (connector *SIRIStopPointsDiscoveryRequestBroadcaster) ignoreStopWithoutLine() bool {
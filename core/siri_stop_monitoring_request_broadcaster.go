@@ -17,22 +17,37 @@ type StopMonitoringRequestBroadcaster interface {
 	RequestStopArea(*siri.XMLGetStopMonitoring, *audit.BigQueryMessage) *siri.SIRIStopMonitoringResponse
 }
 
+// Implemented by the SOAP and SIRI Lite requests
+type stopMonitoringRequest interface {
+	MessageIdentifier() string
+	MonitoringRef() string
+	LineRef() string
+	StopVisitTypes() string
+	MaximumStopVisits() int
+	PreviewInterval() time.Duration
+	StartTime() time.Time
+}
+
 type SIRIStopMonitoringRequestBroadcaster struct {
 	clock.ClockConsumer
 
 	siriConnector
+
+	connectorType string
 }
 
 type SIRIStopMonitoringRequestBroadcasterFactory struct{}
 
 func NewSIRIStopMonitoringRequestBroadcaster(partner *Partner) *SIRIStopMonitoringRequestBroadcaster {
-	siriStopMonitoringRequestBroadcaster := &SIRIStopMonitoringRequestBroadcaster{}
+	siriStopMonitoringRequestBroadcaster := &SIRIStopMonitoringRequestBroadcaster{
+		connectorType: SIRI_STOP_MONITORING_REQUEST_BROADCASTER,
+	}
 	siriStopMonitoringRequestBroadcaster.partner = partner
 	return siriStopMonitoringRequestBroadcaster
 }
 
-func (connector *SIRIStopMonitoringRequestBroadcaster) getStopMonitoringDelivery(tx *model.Transaction, logStashEvent audit.LogStashEvent, request *siri.XMLStopMonitoringRequest) siri.SIRIStopMonitoringDelivery {
-	objectidKind := connector.partner.RemoteObjectIDKind(SIRI_STOP_MONITORING_REQUEST_BROADCASTER)
+func (connector *SIRIStopMonitoringRequestBroadcaster) getStopMonitoringDelivery(tx *model.Transaction, logStashEvent audit.LogStashEvent, request stopMonitoringRequest) siri.SIRIStopMonitoringDelivery {
+	objectidKind := connector.partner.RemoteObjectIDKind(connector.connectorType)
 	objectid := model.NewObjectID(objectidKind, request.MonitoringRef())
 	stopArea, ok := tx.Model().StopAreas().FindByObjectId(objectid)
 	if !ok {
@@ -65,11 +80,26 @@ func (connector *SIRIStopMonitoringRequestBroadcaster) getStopMonitoringDelivery
 		delivery.ErrorText = fmt.Sprintf("Erreur [PRODUCER_UNAVAILABLE] : %v indisponible", strings.Join(stopArea.Origins.PartnersKO(), ", "))
 	}
 
+	var merger *Merger
+	if connector.Partner().MergeStopVisits() {
+		merger = NewMerger(connector.Partner().Referential(), tx)
+		merger.ExcludeOrigin(string(connector.Partner().Slug()))
+	}
+
 	// Prepare StopVisit Selectors
 	selectors := []model.StopVisitSelector{}
+	// With merging, the StopVisits of all the Lines of the requested Line group are selected
+	var lineIds map[model.LineId]struct{}
 	if request.LineRef() != "" {
 		lineSelectorObjectid := model.NewObjectID(objectidKind, request.LineRef())
-		selectors = append(selectors, model.StopVisitSelectorByLine(lineSelectorObjectid))
+		if line, ok := tx.Model().Lines().FindByObjectId(lineSelectorObjectid); merger != nil && ok {
+			lineIds = make(map[model.LineId]struct{})
+			for _, lineId := range merger.LineGroup(line.Id()) {
+				lineIds[lineId] = struct{}{}
+			}
+		} else {
+			selectors = append(selectors, model.StopVisitSelectorByLine(lineSelectorObjectid))
+		}
 	}
 	if request.PreviewInterval() != 0 {
 		duration := request.PreviewInterval()
@@ -85,15 +115,30 @@ func (connector *SIRIStopMonitoringRequestBroadcaster) getStopMonitoringDelivery
 	var stopVisitArray []string
 
 	// Initialize builder
-	stopMonitoringBuilder := NewBroadcastStopMonitoringBuilder(tx, connector.Partner(), SIRI_STOP_MONITORING_REQUEST_BROADCASTER)
+	stopMonitoringBuilder := NewBroadcastStopMonitoringBuilder(tx, connector.Partner(), connector.connectorType)
 	stopMonitoringBuilder.StopVisitTypes = request.StopVisitTypes()
 	stopMonitoringBuilder.MonitoringRef = request.MonitoringRef()
+	stopMonitoringBuilder.merger = merger
 
 	// Find Descendants
-	stopAreas := tx.Model().StopAreas().FindFamily(stopArea.Id())
+	var stopAreas []model.StopAreaId
+	if merger != nil {
+		stopAreas = merger.StopAreaFamily(stopArea.Id())
+	} else {
+		stopAreas = tx.Model().StopAreas().FindFamily(stopArea.Id())
+	}
+
+	stopVisits := tx.Model().StopVisits().FindFollowingByStopAreaIds(stopAreas)
+	var merged map[model.StopVisitId]model.VehicleJourneyId
+	if merger != nil {
+		merged = merger.MergeStopVisits(stopVisits)
+	}
 
 	// Fill StopVisits
-	for _, stopVisit := range tx.Model().StopVisits().FindFollowingByStopAreaIds(stopAreas) {
+	for _, stopVisit := range stopVisits {
+		if _, ok := merged[stopVisit.Id()]; ok {
+			continue
+		}
 		if stopVisit.Origin == string(connector.Partner().Slug()) {
 			continue
 		}
@@ -103,6 +148,12 @@ func (connector *SIRIStopMonitoringRequestBroadcaster) getStopMonitoringDelivery
 		if !selector(stopVisit) {
 			continue
 		}
+		if lineIds != nil {
+			vehicleJourney, _ := tx.Model().VehicleJourneys().Find(stopVisit.VehicleJourneyId)
+			if _, ok := lineIds[vehicleJourney.LineId]; !ok {
+				continue
+			}
+		}
 
 		monitoredStopVisit := stopMonitoringBuilder.BuildMonitoredStopVisit(stopVisit)
 		if monitoredStopVisit == nil {
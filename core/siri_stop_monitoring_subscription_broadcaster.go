@@ -107,8 +107,9 @@ func (connector *SIRIStopMonitoringSubscriptionBroadcaster) checkEvent(sv model.
 	}
 
 	vj, _ := tx.Model().VehicleJourneys().Find(sv.VehicleJourneyId)
+	merger := connector.merger(tx)
 
-	for _, stopAreaObjectId := range tx.Model().StopAreas().FindAscendantsWithObjectIdKind(sv.StopAreaId, connector.partner.RemoteObjectIDKind(SIRI_STOP_MONITORING_SUBSCRIPTION_BROADCASTER)) {
+	for _, stopAreaObjectId := range connector.stopAreaObjectIds(sv.StopAreaId, merger, tx) {
 		subs := connector.partner.Subscriptions().FindByResourceId(stopAreaObjectId.String(), "StopMonitoringBroadcast")
 
 		for _, sub := range subs {
@@ -118,7 +119,7 @@ func (connector *SIRIStopMonitoringSubscriptionBroadcaster) checkEvent(sv model.
 			}
 
 			// Handle LineRef filter
-			if lineRef, ok := connector.lineRef(sub, tx); ok && lineRef != vj.LineId {
+			if !connector.matchLineRef(sub, vj.LineId, merger, tx) {
 				continue
 			}
 
@@ -262,7 +263,15 @@ func (connector *SIRIStopMonitoringSubscriptionBroadcaster) addStopAreaStopVisit
 	tx := connector.Partner().Referential().NewTransaction()
 	defer tx.Close()
 
-	for _, saId := range tx.Model().StopAreas().FindFamily(sa.Id()) {
+	merger := connector.merger(tx)
+	var stopAreaIds []model.StopAreaId
+	if merger != nil {
+		stopAreaIds = merger.StopAreaFamily(sa.Id())
+	} else {
+		stopAreaIds = tx.Model().StopAreas().FindFamily(sa.Id())
+	}
+
+	for _, saId := range stopAreaIds {
 		for _, sv := range tx.Model().StopVisits().FindFollowingByStopAreaId(saId) {
 			if _, ok := res.LastState(string(sv.Id())); ok {
 				continue
@@ -270,7 +279,7 @@ func (connector *SIRIStopMonitoringSubscriptionBroadcaster) addStopAreaStopVisit
 
 			// Handle LineRef filter
 			vj, _ := tx.Model().VehicleJourneys().Find(sv.VehicleJourneyId)
-			if lineRef, ok := connector.lineRef(sub, tx); ok && lineRef != vj.LineId {
+			if !connector.matchLineRef(sub, vj.LineId, merger, tx) {
 				continue
 			}
 
@@ -282,6 +291,38 @@ func (connector *SIRIStopMonitoringSubscriptionBroadcaster) addStopAreaStopVisit
 	}
 }
 
+// Returns a Merger when the partner merges the StopVisits
+func (connector *SIRIStopMonitoringSubscriptionBroadcaster) merger(tx *model.Transaction) *Merger {
+	if !connector.Partner().MergeStopVisits() {
+		return nil
+	}
+	merger := NewMerger(connector.Partner().Referential(), tx)
+	merger.ExcludeOrigin(string(connector.Partner().Slug()))
+	return merger
+}
+
+// Returns the ObjectIDs of the StopAreas which can be subscribed to receive
+// the StopVisits of the StopArea: its ascendants and, with merging, the
+// ascendants of the StopAreas of its group
+func (connector *SIRIStopMonitoringSubscriptionBroadcaster) stopAreaObjectIds(stopAreaId model.StopAreaId, merger *Merger, tx *model.Transaction) (objectids []model.ObjectID) {
+	kind := connector.partner.RemoteObjectIDKind(SIRI_STOP_MONITORING_SUBSCRIPTION_BROADCASTER)
+	if merger == nil {
+		return tx.Model().StopAreas().FindAscendantsWithObjectIdKind(stopAreaId, kind)
+	}
+
+	known := make(map[model.ObjectID]struct{})
+	for _, id := range merger.StopAreaGroup(stopAreaId) {
+		for _, objectid := range tx.Model().StopAreas().FindAscendantsWithObjectIdKind(id, kind) {
+			if _, ok := known[objectid]; ok {
+				continue
+			}
+			known[objectid] = struct{}{}
+			objectids = append(objectids, objectid)
+		}
+	}
+	return
+}
+
 // WIP Need to do something about this method Refs #6338
 func (smsb *SIRIStopMonitoringSubscriptionBroadcaster) fillOptions(s *Subscription, r *SubscribedResource, request *siri.XMLSubscriptionRequest, sm *siri.XMLStopMonitoringSubscriptionRequestEntry) {
 	changeBeforeUpdates := request.ChangeBeforeUpdates()
@@ -296,6 +337,24 @@ func (smsb *SIRIStopMonitoringSubscriptionBroadcaster) fillOptions(s *Subscripti
 	s.SetSubscriptionOption("MessageIdentifier", request.MessageIdentifier())
 }
 
+// Returns true when the LineRef subscription option isn't defined or matches
+// the Line. With merging, all the Lines of the LineRef group match.
+func (connector *SIRIStopMonitoringSubscriptionBroadcaster) matchLineRef(sub *Subscription, lineId model.LineId, merger *Merger, tx *model.Transaction) bool {
+	lineRef, ok := connector.lineRef(sub, tx)
+	if !ok || lineRef == lineId {
+		return true
+	}
+	if merger == nil || lineRef == "" {
+		return false
+	}
+	for _, id := range merger.LineGroup(lineRef) {
+		if id == lineId {
+			return true
+		}
+	}
+	return false
+}
+
 // Returns the LineId of the line defined in the LineRef subscription option
 // If LineRef isn't defined or with an incorrect format, returns false
 func (connector *SIRIStopMonitoringSubscriptionBroadcaster) lineRef(sub *Subscription, tx *model.Transaction) (model.LineId, bool) {
@@ -73,6 +73,34 @@ func (connector *SIRISubscriptionRequestDispatcher) Dispatch(request *siri.XMLSu
 		return &response, nil
 	}
 
+	if len(request.XMLSubscriptionPTEntries()) > 0 {
+		ptbc, ok := connector.Partner().Connector(SIRI_PRODUCTION_TIMETABLE_SUBSCRIPTION_BROADCASTER)
+		if !ok {
+			return nil, fmt.Errorf("no ProductionTimetableSubscriptionBroadcaster Connector")
+		}
+
+		response.ResponseStatus = ptbc.(*SIRIProductionTimetableSubscriptionBroadcaster).HandleSubscriptionRequest(request, message)
+
+		logSIRISubscriptionResponse(logStashEvent, &response, "ProductionTimetableSubscriptionBroadcaster")
+		logStashEvent["siriType"] = "ProductionTimetableSubscriptionRequest"
+		audit.CurrentLogStash().WriteEvent(logStashEvent)
+		return &response, nil
+	}
+
+	if len(request.XMLSubscriptionSXEntries()) > 0 {
+		sxbc, ok := connector.Partner().Connector(SIRI_SITUATION_EXCHANGE_SUBSCRIPTION_BROADCASTER)
+		if !ok {
+			return nil, fmt.Errorf("no SituationExchangeSubscriptionBroadcaster Connector")
+		}
+
+		response.ResponseStatus = sxbc.(*SIRISituationExchangeSubscriptionBroadcaster).HandleSubscriptionRequest(request, message)
+
+		logSIRISubscriptionResponse(logStashEvent, &response, "SituationExchangeSubscriptionBroadcaster")
+		logStashEvent["siriType"] = "SituationExchangeSubscriptionRequest"
+		audit.CurrentLogStash().WriteEvent(logStashEvent)
+		return &response, nil
+	}
+
 	if len(request.XMLSubscriptionSMEntries()) > 0 {
 		smbc, ok := connector.Partner().Connector(SIRI_STOP_MONITORING_SUBSCRIPTION_BROADCASTER)
 		if !ok {
@@ -101,6 +129,20 @@ func (connector *SIRISubscriptionRequestDispatcher) Dispatch(request *siri.XMLSu
 		return &response, nil
 	}
 
+	if len(request.XMLSubscriptionVMEntries()) > 0 {
+		vmbc, ok := connector.Partner().Connector(SIRI_VEHICLE_MONITORING_SUBSCRIPTION_BROADCASTER)
+		if !ok {
+			return nil, fmt.Errorf("no VehicleMonitoringSubscriptionBroadcaster Connector")
+		}
+
+		response.ResponseStatus = vmbc.(*SIRIVehicleMonitoringSubscriptionBroadcaster).HandleSubscriptionRequest(request, message)
+
+		logSIRISubscriptionResponse(logStashEvent, &response, "VehicleMonitoringSubscriptionBroadcaster")
+		logStashEvent["siriType"] = "VehicleMonitoringSubscriptionRequest"
+		audit.CurrentLogStash().WriteEvent(logStashEvent)
+		return &response, nil
+	}
+
 	return nil, fmt.Errorf("subscription not supported")
 }
 
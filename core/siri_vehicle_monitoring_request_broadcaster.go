@@ -0,0 +1,163 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+)
+
+type SIRIVehicleMonitoringRequestBroadcaster struct {
+	clock.ClockConsumer
+
+	siriConnector
+}
+
+type SIRIVehicleMonitoringRequestBroadcasterFactory struct{}
+
+func NewSIRIVehicleMonitoringRequestBroadcaster(partner *Partner) *SIRIVehicleMonitoringRequestBroadcaster {
+	siriVehicleMonitoringRequestBroadcaster := &SIRIVehicleMonitoringRequestBroadcaster{}
+	siriVehicleMonitoringRequestBroadcaster.partner = partner
+	return siriVehicleMonitoringRequestBroadcaster
+}
+
+func (connector *SIRIVehicleMonitoringRequestBroadcaster) Vehicles(request *siri.XMLGetVehicleMonitoring, message *audit.BigQueryMessage) *siri.SIRIVehicleMonitoringResponse {
+	tx := connector.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	logStashEvent := connector.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+
+	logXMLVehicleMonitoringRequest(logStashEvent, &request.XMLVehicleMonitoringRequest)
+	logStashEvent["requestorRef"] = request.RequestorRef()
+
+	response := &siri.SIRIVehicleMonitoringResponse{
+		Address:                   connector.Partner().Address(),
+		ProducerRef:               connector.Partner().ProducerRef(),
+		ResponseMessageIdentifier: connector.Partner().IdentifierGenerator(RESPONSE_MESSAGE_IDENTIFIER).NewMessageIdentifier(),
+	}
+
+	var vehicleRefs []string
+	response.SIRIVehicleMonitoringDelivery, vehicleRefs = connector.getVehicleMonitoringDelivery(tx, &request.XMLVehicleMonitoringRequest)
+
+	if !response.SIRIVehicleMonitoringDelivery.Status {
+		message.Status = "Error"
+		message.ErrorDetails = response.SIRIVehicleMonitoringDelivery.ErrorString()
+	}
+	if request.LineRef() != "" {
+		message.Lines = []string{request.LineRef()}
+	}
+	message.Vehicles = vehicleRefs
+	message.RequestIdentifier = request.MessageIdentifier()
+	message.ResponseIdentifier = response.ResponseMessageIdentifier
+
+	logSIRIVehicleMonitoringDelivery(logStashEvent, response.SIRIVehicleMonitoringDelivery, vehicleRefs)
+	logSIRIVehicleMonitoringResponse(logStashEvent, response)
+
+	return response
+}
+
+func (connector *SIRIVehicleMonitoringRequestBroadcaster) getVehicleMonitoringDelivery(tx *model.Transaction, request *siri.XMLVehicleMonitoringRequest) (siri.SIRIVehicleMonitoringDelivery, []string) {
+	delivery := siri.SIRIVehicleMonitoringDelivery{
+		RequestMessageRef: request.MessageIdentifier(),
+		ResponseTimestamp: connector.Clock().Now(),
+	}
+
+	var vehicles []model.Vehicle
+
+	switch {
+	case request.VehicleMonitoringRef() != "":
+		objectid := model.NewObjectID(connector.Partner().VehicleRemoteObjectIDKind(SIRI_VEHICLE_MONITORING_REQUEST_BROADCASTER), request.VehicleMonitoringRef())
+		vehicle, ok := tx.Model().Vehicles().FindByObjectId(objectid)
+		if !ok {
+			delivery.ErrorType = "InvalidDataReferencesError"
+			delivery.ErrorText = fmt.Sprintf("Vehicle not found: '%s'", objectid.Value())
+			return delivery, nil
+		}
+		vehicles = []model.Vehicle{vehicle}
+	case request.LineRef() != "":
+		objectid := model.NewObjectID(connector.Partner().RemoteObjectIDKind(SIRI_VEHICLE_MONITORING_REQUEST_BROADCASTER), request.LineRef())
+		line, ok := tx.Model().Lines().FindByObjectId(objectid)
+		if !ok {
+			delivery.ErrorType = "InvalidDataReferencesError"
+			delivery.ErrorText = fmt.Sprintf("Line not found: '%s'", objectid.Value())
+			return delivery, nil
+		}
+		vehicles = tx.Model().Vehicles().FindByLineId(line.Id())
+	default:
+		vehicles = tx.Model().Vehicles().FindAll()
+	}
+
+	delivery.Status = true
+
+	var vehicleRefs []string
+	builder := NewBroadcastVehicleMonitoringBuilder(tx, connector.Partner(), SIRI_VEHICLE_MONITORING_REQUEST_BROADCASTER)
+	for _, vehicle := range vehicles {
+		if max := request.MaximumVehicles(); max > 0 && len(delivery.VehicleActivities) >= max {
+			break
+		}
+		activity := builder.BuildVehicleActivity(vehicle)
+		if activity == nil {
+			continue
+		}
+		delivery.VehicleActivities = append(delivery.VehicleActivities, activity)
+		vehicleRefs = append(vehicleRefs, activity.VehicleMonitoringRef)
+	}
+
+	return delivery, vehicleRefs
+}
+
+func (connector *SIRIVehicleMonitoringRequestBroadcaster) newLogStashEvent() audit.LogStashEvent {
+	event := connector.partner.NewLogStashEvent()
+	event["connector"] = "SIRIVehicleMonitoringRequestBroadcaster"
+	return event
+}
+
+func (factory *SIRIVehicleMonitoringRequestBroadcasterFactory) Validate(apiPartner *APIPartner) {
+	apiPartner.ValidatePresenceOfSetting(REMOTE_OBJECTID_KIND)
+	apiPartner.ValidatePresenceOfLocalCredentials()
+}
+
+func (factory *SIRIVehicleMonitoringRequestBroadcasterFactory) CreateConnector(partner *Partner) Connector {
+	return NewSIRIVehicleMonitoringRequestBroadcaster(partner)
+}
+
+func logXMLVehicleMonitoringRequest(logStashEvent audit.LogStashEvent, request *siri.XMLVehicleMonitoringRequest) {
+	logStashEvent["siriType"] = "VehicleMonitoringResponse"
+	logStashEvent["messageIdentifier"] = request.MessageIdentifier()
+	logStashEvent["vehicleMonitoringRef"] = request.VehicleMonitoringRef()
+	logStashEvent["lineRef"] = request.LineRef()
+	logStashEvent["maximumVehicles"] = strconv.Itoa(request.MaximumVehicles())
+	logStashEvent["requestTimestamp"] = request.RequestTimestamp().String()
+	logStashEvent["requestXML"] = request.RawXML()
+}
+
+func logSIRIVehicleMonitoringDelivery(logStashEvent audit.LogStashEvent, delivery siri.SIRIVehicleMonitoringDelivery, vehicleRefs []string) {
+	logStashEvent["requestMessageRef"] = delivery.RequestMessageRef
+	logStashEvent["responseTimestamp"] = delivery.ResponseTimestamp.String()
+	logStashEvent["vehicleRefs"] = strings.Join(vehicleRefs, ",")
+	logStashEvent["status"] = strconv.FormatBool(delivery.Status)
+	if !delivery.Status {
+		logStashEvent["errorType"] = delivery.ErrorType
+		if delivery.ErrorType == "OtherError" {
+			logStashEvent["errorNumber"] = strconv.Itoa(delivery.ErrorNumber)
+		}
+		logStashEvent["errorText"] = delivery.ErrorText
+	}
+}
+
+func logSIRIVehicleMonitoringResponse(logStashEvent audit.LogStashEvent, response *siri.SIRIVehicleMonitoringResponse) {
+	logStashEvent["address"] = response.Address
+	logStashEvent["producerRef"] = response.ProducerRef
+	logStashEvent["responseMessageIdentifier"] = response.ResponseMessageIdentifier
+	xml, err := response.BuildXML()
+	if err != nil {
+		logStashEvent["responseXML"] = fmt.Sprintf("%v", err)
+		return
+	}
+	logStashEvent["responseXML"] = xml
+}
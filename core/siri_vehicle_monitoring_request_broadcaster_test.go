@@ -0,0 +1,125 @@
+package core
+
+import (
+	"testing"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+func prepareVehicleMonitoringRequest(lineRef, vehicleRef string) *siri.XMLGetVehicleMonitoring {
+	content := []byte(`<ns7:GetVehicleMonitoring xmlns:ns2="http://www.siri.org.uk/siri" xmlns:ns7="http://wsdl.siri.org.uk">
+  <ServiceRequestInfo>
+    <ns2:RequestTimestamp>2016-09-07T09:11:25.174Z</ns2:RequestTimestamp>
+    <ns2:RequestorRef>test</ns2:RequestorRef>
+    <ns2:MessageIdentifier>VehicleMonitoring:Test:0</ns2:MessageIdentifier>
+  </ServiceRequestInfo>
+  <Request version="2.0:FR-IDF-2.4">
+    <ns2:MessageIdentifier>VehicleMonitoring:Test:0</ns2:MessageIdentifier>
+    <ns2:LineRef>` + lineRef + `</ns2:LineRef>
+    <ns2:VehicleMonitoringRef>` + vehicleRef + `</ns2:VehicleMonitoringRef>
+  </Request>
+</ns7:GetVehicleMonitoring>`)
+	request, _ := siri.NewXMLGetVehicleMonitoringFromContent(content)
+	return request
+}
+
+func Test_SIRIVehicleMonitoringRequestBroadcaster_Vehicles(t *testing.T) {
+	referentials := NewMemoryReferentials()
+	referential := referentials.New("referential")
+	partner := referential.Partners().New("partner")
+	partner.SetSetting("remote_objectid_kind", "objectidKind")
+	partner.SetSetting("generators.response_message_identifier", "Ara:ResponseMessage::%{uuid}:LOC")
+	connector := NewSIRIVehicleMonitoringRequestBroadcaster(partner)
+	connector.Partner().SetUUIDGenerator(uuid.NewFakeUUIDGenerator())
+	connector.SetClock(clock.NewFakeClock())
+
+	line := referential.Model().Lines().New()
+	line.SetObjectID(model.NewObjectID("objectidKind", "line"))
+	line.Name = "lineName"
+	line.Save()
+
+	line2 := referential.Model().Lines().New()
+	line2.SetObjectID(model.NewObjectID("objectidKind", "line2"))
+	line2.Save()
+
+	vehicleJourney := referential.Model().VehicleJourneys().New()
+	vehicleJourney.SetObjectID(model.NewObjectID("objectidKind", "vehicleJourney"))
+	vehicleJourney.LineId = line.Id()
+	vehicleJourney.Monitored = true
+	vehicleJourney.Save()
+
+	vehicle := referential.Model().Vehicles().New()
+	vehicle.SetObjectID(model.NewObjectID("objectidKind", "vehicle"))
+	vehicle.LineId = line.Id()
+	vehicle.VehicleJourneyId = vehicleJourney.Id()
+	vehicle.Longitude = 2.3
+	vehicle.Latitude = 48.8
+	vehicle.RecordedAtTime = connector.Clock().Now()
+	vehicle.Save()
+
+	vehicle2 := referential.Model().Vehicles().New()
+	vehicle2.SetObjectID(model.NewObjectID("objectidKind", "vehicle2"))
+	vehicle2.LineId = line2.Id()
+	vehicle2.VehicleJourneyId = vehicleJourney.Id()
+	vehicle2.Save()
+
+	message := &audit.BigQueryMessage{}
+	response := connector.Vehicles(prepareVehicleMonitoringRequest("line", ""), message)
+
+	if !response.Status {
+		t.Fatalf("Response should have a true status, got error: %v", response.ErrorString())
+	}
+	if expected := "Ara:ResponseMessage::6ba7b814-9dad-11d1-0-00c04fd430c8:LOC"; response.ResponseMessageIdentifier != expected {
+		t.Errorf("Wrong ResponseMessageIdentifier:\n got: %v\n want: %v", response.ResponseMessageIdentifier, expected)
+	}
+	if len(response.VehicleActivities) != 1 {
+		t.Fatalf("Response should have 1 VehicleActivity, got: %v", len(response.VehicleActivities))
+	}
+	activity := response.VehicleActivities[0]
+	if activity.VehicleMonitoringRef != "vehicle" {
+		t.Errorf("Wrong VehicleMonitoringRef:\n got: %v\n want: vehicle", activity.VehicleMonitoringRef)
+	}
+	if activity.LineRef != "line" || activity.PublishedLineName != "lineName" {
+		t.Errorf("Wrong Line in VehicleActivity: %v %v", activity.LineRef, activity.PublishedLineName)
+	}
+	if activity.DatedVehicleJourneyRef != "vehicleJourney" {
+		t.Errorf("Wrong DatedVehicleJourneyRef:\n got: %v\n want: vehicleJourney", activity.DatedVehicleJourneyRef)
+	}
+	if !activity.Monitored || activity.Longitude != 2.3 || activity.Latitude != 48.8 {
+		t.Errorf("Wrong VehicleActivity: %#v", activity)
+	}
+	if len(message.Vehicles) != 1 || message.Vehicles[0] != "vehicle" {
+		t.Errorf("Wrong BigQuery message Vehicles: %v", message.Vehicles)
+	}
+
+	response = connector.Vehicles(prepareVehicleMonitoringRequest("", "vehicle2"), &audit.BigQueryMessage{})
+	if len(response.VehicleActivities) != 1 || response.VehicleActivities[0].VehicleMonitoringRef != "vehicle2" {
+		t.Errorf("Response should have the requested vehicle, got: %v", response.VehicleActivities)
+	}
+}
+
+func Test_SIRIVehicleMonitoringRequestBroadcaster_UnknownLine(t *testing.T) {
+	referentials := NewMemoryReferentials()
+	referential := referentials.New("referential")
+	partner := referential.Partners().New("partner")
+	partner.SetSetting("remote_objectid_kind", "objectidKind")
+	connector := NewSIRIVehicleMonitoringRequestBroadcaster(partner)
+	connector.SetClock(clock.NewFakeClock())
+
+	message := &audit.BigQueryMessage{}
+	response := connector.Vehicles(prepareVehicleMonitoringRequest("unknown", ""), message)
+
+	if response.Status {
+		t.Errorf("Response should have a false status")
+	}
+	if expected := "InvalidDataReferencesError: Line not found: 'unknown'"; response.ErrorString() != expected {
+		t.Errorf("Wrong error:\n got: %v\n want: %v", response.ErrorString(), expected)
+	}
+	if message.Status != "Error" {
+		t.Errorf("BigQuery message should have an Error status, got: %v", message.Status)
+	}
+}
@@ -0,0 +1,203 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+type VehicleMonitoringRequestCollector interface {
+	RequestVehicleUpdate(request *VehicleUpdateRequest)
+}
+
+type SIRIVehicleMonitoringRequestCollector struct {
+	clock.ClockConsumer
+	uuid.UUIDConsumer
+
+	siriConnector
+
+	updateSubscriber UpdateSubscriber
+}
+
+type SIRIVehicleMonitoringRequestCollectorFactory struct{}
+
+func NewSIRIVehicleMonitoringRequestCollector(partner *Partner) *SIRIVehicleMonitoringRequestCollector {
+	connector := &SIRIVehicleMonitoringRequestCollector{}
+	connector.partner = partner
+	manager := partner.Referential().CollectManager()
+	connector.updateSubscriber = manager.BroadcastUpdateEvent
+
+	return connector
+}
+
+func (connector *SIRIVehicleMonitoringRequestCollector) RequestVehicleUpdate(request *VehicleUpdateRequest) {
+	tx := connector.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	line, ok := tx.Model().Lines().Find(request.LineId())
+	if !ok {
+		logger.Log.Debugf("VehicleUpdateRequest in VehicleMonitoringRequestCollector for unknown Line %v", request.LineId())
+		return
+	}
+
+	objectidKind := connector.partner.Setting(REMOTE_OBJECTID_KIND)
+	objectid, ok := line.ObjectID(objectidKind)
+	if !ok {
+		logger.Log.Debugf("Requested line %v doesn't have and objectId of kind %v", request.LineId(), objectidKind)
+		return
+	}
+
+	logStashEvent := connector.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+
+	message := connector.newBQEvent()
+	defer audit.CurrentBigQuery(string(connector.Partner().Referential().Slug())).WriteEvent(message)
+
+	startTime := connector.Clock().Now()
+
+	siriVehicleMonitoringRequest := siri.NewSIRIGetVehicleMonitoringRequest(
+		connector.Partner().IdentifierGenerator(MESSAGE_IDENTIFIER).NewMessageIdentifier(),
+		objectid.Value(),
+		connector.SIRIPartner().RequestorRef(),
+		connector.Clock().Now(),
+	)
+
+	logSIRIVehicleMonitoringRequest(logStashEvent, message, siriVehicleMonitoringRequest)
+
+	xmlVehicleMonitoringResponse, err := connector.SIRIPartner().SOAPClient().VehicleMonitoring(siriVehicleMonitoringRequest)
+	logStashEvent["responseTime"] = connector.Clock().Since(startTime).String()
+	message.ProcessingTime = connector.Clock().Since(startTime).Seconds()
+	if err != nil {
+		e := fmt.Sprintf("Error during VehicleMonitoring request: %v", err)
+		logStashEvent["status"] = "false"
+		logStashEvent["errorDescription"] = e
+		message.Status = "Error"
+		message.ErrorDetails = e
+		return
+	}
+
+	logXMLVehicleMonitoringResponse(logStashEvent, message, xmlVehicleMonitoringResponse)
+
+	builder := NewVehicleMonitoringUpdateEventBuilder(connector.partner, SIRI_VEHICLE_MONITORING_REQUEST_COLLECTOR)
+
+	for _, delivery := range xmlVehicleMonitoringResponse.VehicleMonitoringDeliveries() {
+		if !delivery.Status() {
+			continue
+		}
+		builder.SetUpdateEvents(delivery.XMLVehicleActivities())
+	}
+
+	updateEvents := builder.UpdateEvents()
+
+	logVehicleMonitoringRefs(logStashEvent, message, updateEvents.VehicleRefs)
+
+	connector.broadcastUpdateEvents(&updateEvents)
+}
+
+func (connector *SIRIVehicleMonitoringRequestCollector) broadcastUpdateEvents(events *VehicleMonitoringUpdateEvents) {
+	if connector.updateSubscriber == nil {
+		return
+	}
+	for _, e := range events.Lines {
+		connector.updateSubscriber(e)
+	}
+	for _, e := range events.VehicleJourneys {
+		connector.updateSubscriber(e)
+	}
+	for _, e := range events.Vehicles {
+		connector.updateSubscriber(e)
+	}
+}
+
+func (connector *SIRIVehicleMonitoringRequestCollector) SetUpdateSubscriber(updateSubscriber UpdateSubscriber) {
+	connector.updateSubscriber = updateSubscriber
+}
+
+func (connector *SIRIVehicleMonitoringRequestCollector) newBQEvent() *audit.BigQueryMessage {
+	return &audit.BigQueryMessage{
+		Type:      "VehicleMonitoringRequest",
+		Protocol:  "siri",
+		Direction: "sent",
+		Partner:   string(connector.partner.Slug()),
+		Status:    "OK",
+	}
+}
+
+func (connector *SIRIVehicleMonitoringRequestCollector) newLogStashEvent() audit.LogStashEvent {
+	event := connector.partner.NewLogStashEvent()
+	event["connector"] = "VehicleMonitoringRequestCollector"
+	return event
+}
+
+func (factory *SIRIVehicleMonitoringRequestCollectorFactory) Validate(apiPartner *APIPartner) {
+	apiPartner.ValidatePresenceOfSetting(REMOTE_OBJECTID_KIND)
+	apiPartner.ValidatePresenceOfSetting(REMOTE_URL)
+	apiPartner.ValidatePresenceOfSetting(REMOTE_CREDENTIAL)
+}
+
+func (factory *SIRIVehicleMonitoringRequestCollectorFactory) CreateConnector(partner *Partner) Connector {
+	return NewSIRIVehicleMonitoringRequestCollector(partner)
+}
+
+func logSIRIVehicleMonitoringRequest(logStashEvent audit.LogStashEvent, message *audit.BigQueryMessage, request *siri.SIRIGetVehicleMonitoringRequest) {
+	logStashEvent["siriType"] = "VehicleMonitoringRequest"
+	logStashEvent["messageIdentifier"] = request.MessageIdentifier
+	logStashEvent["lineRef"] = request.LineRef
+	logStashEvent["requestorRef"] = request.RequestorRef
+	logStashEvent["requestTimestamp"] = request.RequestTimestamp.String()
+	xml, err := request.BuildXML()
+	if err != nil {
+		logStashEvent["requestXML"] = fmt.Sprintf("%v", err)
+		return
+	}
+	logStashEvent["requestXML"] = xml
+
+	message.RequestIdentifier = request.MessageIdentifier
+	message.RequestRawMessage = xml
+	message.RequestSize = int64(len(xml))
+	message.Lines = []string{request.LineRef}
+}
+
+func logXMLVehicleMonitoringResponse(logStashEvent audit.LogStashEvent, message *audit.BigQueryMessage, response *siri.XMLVehicleMonitoringResponse) {
+	logStashEvent["address"] = response.Address()
+	logStashEvent["producerRef"] = response.ProducerRef()
+	logStashEvent["requestMessageRef"] = response.RequestMessageRef()
+	logStashEvent["responseMessageIdentifier"] = response.ResponseMessageIdentifier()
+	logStashEvent["responseTimestamp"] = response.ResponseTimestamp().String()
+	logStashEvent["responseXML"] = response.RawXML()
+	status := "true"
+	errorCount := 0
+	for _, delivery := range response.VehicleMonitoringDeliveries() {
+		if !delivery.Status() {
+			message.Status = "Error"
+			status = "false"
+			errorCount++
+		}
+	}
+	logStashEvent["status"] = status
+	logStashEvent["errorCount"] = strconv.Itoa(errorCount)
+
+	message.ResponseIdentifier = response.ResponseMessageIdentifier()
+	message.ResponseRawMessage = response.RawXML()
+	message.ResponseSize = int64(len(message.ResponseRawMessage))
+}
+
+func logVehicleMonitoringRefs(logStashEvent audit.LogStashEvent, message *audit.BigQueryMessage, refs map[string]struct{}) {
+	refSlice := make([]string, len(refs))
+	i := 0
+	for vehicleRef := range refs {
+		refSlice[i] = vehicleRef
+		i++
+	}
+	logStashEvent["vehicleRefs"] = strings.Join(refSlice, ", ")
+
+	if message != nil {
+		message.Vehicles = refSlice
+	}
+}
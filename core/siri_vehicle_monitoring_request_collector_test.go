@@ -0,0 +1,149 @@
+package core
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
+)
+
+func prepare_SIRIVehicleMonitoringRequestCollector(t *testing.T, settings map[string]string) []model.UpdateEvent {
+	audit.SetCurrentLogstash(audit.NewFakeLogStash())
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, err := os.Open("testdata/vehiclemonitoring-response-soap.xml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		io.Copy(w, file)
+	}))
+	defer ts.Close()
+
+	settings["remote_url"] = ts.URL
+	settings["remote_objectid_kind"] = "test"
+
+	partners := createTestPartnerManager()
+	partner := partners.New("slug")
+	partner.SetSettingsDefinition(settings)
+	partners.Save(partner)
+
+	line := partners.Model().Lines().New()
+	line.SetObjectID(model.NewObjectID("test", "RLA:Line:1:LOC"))
+	partners.Model().Lines().Save(&line)
+
+	connector := NewSIRIVehicleMonitoringRequestCollector(partner)
+	connector.SetClock(clock.NewFakeClock())
+	collectManager := NewTestCollectManager().(*TestCollectManager)
+	connector.SetUpdateSubscriber(collectManager.TestUpdateSubscriber)
+
+	connector.RequestVehicleUpdate(NewVehicleUpdateRequest(line.Id()))
+
+	return collectManager.UpdateEvents
+}
+
+func Test_SIRIVehicleMonitoringRequestCollectorFactory_Validate(t *testing.T) {
+	partner := &Partner{
+		slug:           "partner",
+		ConnectorTypes: []string{SIRI_VEHICLE_MONITORING_REQUEST_COLLECTOR},
+		connectors:     make(map[string]Connector),
+		manager:        NewPartnerManager(nil),
+	}
+	partner.PartnerSettings = NewPartnerSettings(partner)
+	apiPartner := partner.Definition()
+	apiPartner.Validate()
+	if apiPartner.Errors.Empty() {
+		t.Errorf("apiPartner should have errors when remote_url, remote_credential and remote_objectid_kind aren't set, got: %v", apiPartner.Errors)
+	}
+
+	apiPartner.Settings = map[string]string{
+		"remote_url":           "remote_url",
+		"remote_credential":    "remote_credential",
+		"remote_objectid_kind": "remote_objectid_kind",
+	}
+	apiPartner.Validate()
+	if !apiPartner.Errors.Empty() {
+		t.Errorf("apiPartner shouldn't have any error when remote_url, remote_credential and remote_objectid_kind are set, got: %v", apiPartner.Errors)
+	}
+}
+
+func Test_SIRIVehicleMonitoringRequestCollector_RequestVehicleUpdate(t *testing.T) {
+	events := prepare_SIRIVehicleMonitoringRequestCollector(t, map[string]string{
+		"collect.include_lines": "RLA:Line:1:LOC",
+	})
+
+	var vehicleEvents []*model.VehicleUpdateEvent
+	var vehicleJourneyEvents []*model.VehicleJourneyUpdateEvent
+	var lineEvents int
+	for _, event := range events {
+		switch e := event.(type) {
+		case *model.VehicleUpdateEvent:
+			vehicleEvents = append(vehicleEvents, e)
+		case *model.VehicleJourneyUpdateEvent:
+			vehicleJourneyEvents = append(vehicleJourneyEvents, e)
+		case *model.LineUpdateEvent:
+			lineEvents++
+		}
+	}
+
+	if lineEvents != 1 || len(vehicleJourneyEvents) != 1 || len(vehicleEvents) != 1 {
+		t.Fatalf("Wrong number of events: %v lines, %v vehicle journeys, %v vehicles", lineEvents, len(vehicleJourneyEvents), len(vehicleEvents))
+	}
+	if expected := model.NewObjectID("test", "RLA:Line:1:LOC"); vehicleJourneyEvents[0].LineObjectId != expected {
+		t.Errorf("Wrong VehicleJourney LineObjectId:\n got: %v\n want: %v", vehicleJourneyEvents[0].LineObjectId, expected)
+	}
+
+	vEvent := vehicleEvents[0]
+	if vEvent.Origin != "slug" {
+		t.Errorf("Wrong Vehicle Origin:\n got: %v\n want: slug", vEvent.Origin)
+	}
+	if expected := model.NewObjectID("test", "RLA:Vehicle:1:LOC"); vEvent.ObjectId != expected {
+		t.Errorf("Wrong Vehicle ObjectId:\n got: %v\n want: %v", vEvent.ObjectId, expected)
+	}
+	if expected := model.NewObjectID("test", "RLA:VehicleJourney:1:LOC"); vEvent.VehicleJourneyObjectId != expected {
+		t.Errorf("Wrong Vehicle VehicleJourneyObjectId:\n got: %v\n want: %v", vEvent.VehicleJourneyObjectId, expected)
+	}
+	if vEvent.Longitude != 2.3522 || vEvent.Latitude != 48.8566 || vEvent.Bearing != 123.5 {
+		t.Errorf("Wrong Vehicle position: %v %v %v", vEvent.Longitude, vEvent.Latitude, vEvent.Bearing)
+	}
+	if expected := time.Date(2017, time.January, 1, 11, 59, 30, 0, time.UTC); !vEvent.RecordedAt.Equal(expected) {
+		t.Errorf("Wrong Vehicle RecordedAt:\n got: %v\n want: %v", vEvent.RecordedAt, expected)
+	}
+}
+
+func Test_SIRIVehicleMonitoringRequestCollector_RequestVehicleUpdate_VehicleRemoteObjectIDKind(t *testing.T) {
+	events := prepare_SIRIVehicleMonitoringRequestCollector(t, map[string]string{
+		"collect.include_lines": "RLA:Line:1:LOC",
+		"siri-vehicle-monitoring-request-collector.vehicle_remote_objectid_kind": "vehicle",
+	})
+
+	for _, event := range events {
+		switch e := event.(type) {
+		case *model.VehicleUpdateEvent:
+			if expected := model.NewObjectID("vehicle", "RLA:Vehicle:1:LOC"); e.ObjectId != expected {
+				t.Errorf("Wrong Vehicle ObjectId:\n got: %v\n want: %v", e.ObjectId, expected)
+			}
+			if expected := model.NewObjectID("test", "RLA:VehicleJourney:1:LOC"); e.VehicleJourneyObjectId != expected {
+				t.Errorf("Wrong Vehicle VehicleJourneyObjectId:\n got: %v\n want: %v", e.VehicleJourneyObjectId, expected)
+			}
+			return
+		}
+	}
+	t.Errorf("No VehicleUpdateEvent")
+}
+
+func Test_SIRIVehicleMonitoringRequestCollector_RequestVehicleUpdate_ExcludedLine(t *testing.T) {
+	events := prepare_SIRIVehicleMonitoringRequestCollector(t, map[string]string{
+		"collect.include_lines": "RLA:Line:2:LOC",
+	})
+
+	if len(events) != 0 {
+		t.Errorf("No event should be broadcasted for a Line not in collect.include_lines, got: %v", events)
+	}
+}
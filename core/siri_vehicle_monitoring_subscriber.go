@@ -0,0 +1,253 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/state"
+)
+
+type SIRIVehicleMonitoringSubscriber interface {
+	state.Stopable
+	state.Startable
+}
+
+type VMSubscriber struct {
+	clock.ClockConsumer
+
+	connector *SIRIVehicleMonitoringSubscriptionCollector
+}
+
+type VehicleMonitoringSubscriber struct {
+	VMSubscriber
+
+	stop chan struct{}
+}
+
+type FakeVehicleMonitoringSubscriber struct {
+	VMSubscriber
+}
+
+type lineToRequest struct {
+	subId  SubscriptionId
+	lineId model.ObjectID
+}
+
+func NewFakeVehicleMonitoringSubscriber(connector *SIRIVehicleMonitoringSubscriptionCollector) SIRIVehicleMonitoringSubscriber {
+	subscriber := &FakeVehicleMonitoringSubscriber{}
+	subscriber.connector = connector
+	return subscriber
+}
+
+func (subscriber *FakeVehicleMonitoringSubscriber) Start() {
+	subscriber.prepareSIRIVehicleMonitoringSubscriptionRequest()
+}
+
+func (subscriber *FakeVehicleMonitoringSubscriber) Stop() {}
+
+func NewSIRIVehicleMonitoringSubscriber(connector *SIRIVehicleMonitoringSubscriptionCollector) SIRIVehicleMonitoringSubscriber {
+	subscriber := &VehicleMonitoringSubscriber{}
+	subscriber.connector = connector
+	return subscriber
+}
+
+func (subscriber *VehicleMonitoringSubscriber) Start() {
+	logger.Log.Debugf("Start VehicleMonitoringSubscriber")
+
+	subscriber.stop = make(chan struct{})
+	go subscriber.run()
+}
+
+func (subscriber *VehicleMonitoringSubscriber) run() {
+	c := subscriber.Clock().After(5 * time.Second)
+
+	for {
+		select {
+		case <-subscriber.stop:
+			return
+		case <-c:
+			logger.Log.Debugf("SIRIVehicleMonitoringSubscriber visit")
+
+			subscriber.prepareSIRIVehicleMonitoringSubscriptionRequest()
+
+			c = subscriber.Clock().After(5 * time.Second)
+		}
+	}
+}
+
+func (subscriber *VehicleMonitoringSubscriber) Stop() {
+	if subscriber.stop != nil {
+		close(subscriber.stop)
+	}
+}
+
+func (subscriber *VMSubscriber) prepareSIRIVehicleMonitoringSubscriptionRequest() {
+	subscriptions := subscriber.connector.partner.Subscriptions().FindSubscriptionsByKind("VehicleMonitoringCollect")
+	if len(subscriptions) == 0 {
+		logger.Log.Debugf("VehicleMonitoringSubscriber visit without VehicleMonitoringCollect subscriptions")
+		return
+	}
+
+	// LineRef for Logstash
+	lineRefList := []string{}
+
+	linesToRequest := make(map[string]*lineToRequest)
+	for _, subscription := range subscriptions {
+		for _, resource := range subscription.ResourcesByObjectIDCopy() {
+			if resource.SubscribedAt.IsZero() && resource.RetryCount <= 10 {
+				messageIdentifier := subscriber.connector.Partner().IdentifierGenerator(MESSAGE_IDENTIFIER).NewMessageIdentifier()
+				linesToRequest[messageIdentifier] = &lineToRequest{
+					subId:  subscription.id,
+					lineId: *(resource.Reference.ObjectId),
+				}
+			}
+		}
+	}
+
+	if len(linesToRequest) == 0 {
+		return
+	}
+
+	logStashEvent := subscriber.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+
+	message := subscriber.newBQEvent()
+	defer audit.CurrentBigQuery(string(subscriber.connector.Partner().Referential().Slug())).WriteEvent(message)
+
+	siriVehicleMonitoringSubscriptionRequest := &siri.SIRIVehicleMonitoringSubscriptionRequest{
+		ConsumerAddress:   subscriber.connector.Partner().Address(),
+		MessageIdentifier: subscriber.connector.Partner().IdentifierGenerator(MESSAGE_IDENTIFIER).NewMessageIdentifier(),
+		RequestorRef:      subscriber.connector.SIRIPartner().RequestorRef(),
+		RequestTimestamp:  subscriber.Clock().Now(),
+	}
+
+	var subIds []string
+	for messageIdentifier, requestedLine := range linesToRequest {
+		entry := &siri.SIRIVehicleMonitoringSubscriptionRequestEntry{
+			SubscriberRef:          subscriber.connector.SIRIPartner().RequestorRef(),
+			SubscriptionIdentifier: string(requestedLine.subId),
+			InitialTerminationTime: subscriber.Clock().Now().Add(48 * time.Hour),
+		}
+		entry.MessageIdentifier = messageIdentifier
+		entry.RequestTimestamp = subscriber.Clock().Now()
+		entry.LineRef = requestedLine.lineId.Value()
+
+		lineRefList = append(lineRefList, entry.LineRef)
+		subIds = append(subIds, entry.SubscriptionIdentifier)
+		siriVehicleMonitoringSubscriptionRequest.Entries = append(siriVehicleMonitoringSubscriptionRequest.Entries, entry)
+	}
+
+	message.RequestIdentifier = siriVehicleMonitoringSubscriptionRequest.MessageIdentifier
+	message.RequestRawMessage, _ = siriVehicleMonitoringSubscriptionRequest.BuildXML()
+	message.RequestSize = int64(len(message.RequestRawMessage))
+	message.Lines = lineRefList
+	message.SubscriptionIdentifiers = subIds
+
+	logStashEvent["lineRefs"] = strings.Join(lineRefList, ", ")
+	logSIRIVehicleMonitoringSubscriptionRequest(logStashEvent, siriVehicleMonitoringSubscriptionRequest)
+
+	startTime := subscriber.Clock().Now()
+	response, err := subscriber.connector.SIRIPartner().SOAPClient().VehicleMonitoringSubscription(siriVehicleMonitoringSubscriptionRequest)
+	logStashEvent["responseTime"] = subscriber.Clock().Since(startTime).String()
+	message.ProcessingTime = subscriber.Clock().Since(startTime).Seconds()
+	if err != nil {
+		logger.Log.Debugf("Error while subscribing: %v", err)
+		e := fmt.Sprintf("Error during VehicleMonitoringSubscriptionRequest: %v", err)
+		logStashEvent["status"] = "false"
+		logStashEvent["errorDescription"] = e
+		subscriber.incrementRetryCountFromMap(linesToRequest)
+
+		message.Status = "Error"
+		message.ErrorDetails = e
+		return
+	}
+
+	logStashEvent["responseXML"] = response.RawXML()
+	message.ResponseRawMessage = response.RawXML()
+	message.ResponseSize = int64(len(message.ResponseRawMessage))
+	message.ResponseIdentifier = response.ResponseMessageIdentifier()
+
+	for _, responseStatus := range response.ResponseStatus() {
+		requestedLine, ok := linesToRequest[responseStatus.RequestMessageRef()]
+		if !ok {
+			logger.Log.Debugf("ResponseStatus RequestMessageRef unknown: %v", responseStatus.RequestMessageRef())
+			continue
+		}
+		delete(linesToRequest, responseStatus.RequestMessageRef())
+
+		subscription, ok := subscriber.connector.partner.Subscriptions().Find(requestedLine.subId)
+		if !ok { // Should never happen
+			logger.Log.Debugf("Response for unknown subscription %v", requestedLine.subId)
+			continue
+		}
+		resource := subscription.Resource(requestedLine.lineId)
+		if resource == nil { // Should never happen
+			logger.Log.Debugf("Response for unknown subscription resource %v", requestedLine.lineId.String())
+			continue
+		}
+
+		if !responseStatus.Status() {
+			logger.Log.Debugf("Subscription status false for line %v: %v %v ", requestedLine.lineId.Value(), responseStatus.ErrorType(), responseStatus.ErrorText())
+			resource.RetryCount++
+			message.Status = "Error"
+			continue
+		}
+		resource.SubscribedAt = subscriber.Clock().Now()
+		resource.RetryCount = 0
+	}
+
+	if len(linesToRequest) == 0 {
+		return
+	}
+	subscriber.incrementRetryCountFromMap(linesToRequest)
+}
+
+func (subscriber *VMSubscriber) incrementRetryCountFromMap(linesToRequest map[string]*lineToRequest) {
+	for _, requestedLine := range linesToRequest {
+		subscription, ok := subscriber.connector.partner.Subscriptions().Find(requestedLine.subId)
+		if !ok { // Should never happen
+			continue
+		}
+		resource := subscription.Resource(requestedLine.lineId)
+		if resource == nil { // Should never happen
+			continue
+		}
+		resource.RetryCount++
+	}
+}
+
+func (subscriber *VMSubscriber) newBQEvent() *audit.BigQueryMessage {
+	return &audit.BigQueryMessage{
+		Type:      "VehicleMonitoringSubscriptionRequest",
+		Protocol:  "siri",
+		Direction: "sent",
+		Partner:   string(subscriber.connector.partner.Slug()),
+		Status:    "OK",
+	}
+}
+
+func (subscriber *VMSubscriber) newLogStashEvent() audit.LogStashEvent {
+	event := subscriber.connector.partner.NewLogStashEvent()
+	event["connector"] = "VehicleMonitoringSubscriptionCollector"
+	return event
+}
+
+func logSIRIVehicleMonitoringSubscriptionRequest(logStashEvent audit.LogStashEvent, request *siri.SIRIVehicleMonitoringSubscriptionRequest) {
+	logStashEvent["siriType"] = "VehicleMonitoringSubscriptionRequest"
+	logStashEvent["consumerAddress"] = request.ConsumerAddress
+	logStashEvent["messageIdentifier"] = request.MessageIdentifier
+	logStashEvent["requestorRef"] = request.RequestorRef
+	logStashEvent["requestTimestamp"] = request.RequestTimestamp.String()
+	xml, err := request.BuildXML()
+	if err != nil {
+		logStashEvent["requestXML"] = fmt.Sprintf("%v", err)
+		return
+	}
+	logStashEvent["requestXML"] = xml
+}
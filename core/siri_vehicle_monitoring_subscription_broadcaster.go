@@ -0,0 +1,231 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+type SIRIVehicleMonitoringSubscriptionBroadcaster struct {
+	clock.ClockConsumer
+	uuid.UUIDConsumer
+
+	siriConnector
+
+	vehicleMonitoringBroadcaster SIRIVehicleMonitoringBroadcaster
+	toBroadcast                  map[SubscriptionId][]model.VehicleId
+
+	mutex *sync.Mutex //protect the map
+}
+
+type SIRIVehicleMonitoringSubscriptionBroadcasterFactory struct{}
+
+func (factory *SIRIVehicleMonitoringSubscriptionBroadcasterFactory) CreateConnector(partner *Partner) Connector {
+	if _, ok := partner.Connector(SIRI_SUBSCRIPTION_REQUEST_DISPATCHER); !ok {
+		partner.CreateSubscriptionRequestDispatcher()
+	}
+	return newSIRIVehicleMonitoringSubscriptionBroadcaster(partner)
+}
+
+func (factory *SIRIVehicleMonitoringSubscriptionBroadcasterFactory) Validate(apiPartner *APIPartner) {
+	apiPartner.ValidatePresenceOfSetting(REMOTE_OBJECTID_KIND)
+	apiPartner.ValidatePresenceOfSetting(REMOTE_URL)
+	apiPartner.ValidatePresenceOfSetting(REMOTE_CREDENTIAL)
+	apiPartner.ValidatePresenceOfSetting(LOCAL_CREDENTIAL)
+}
+
+func newSIRIVehicleMonitoringSubscriptionBroadcaster(partner *Partner) *SIRIVehicleMonitoringSubscriptionBroadcaster {
+	siriVehicleMonitoringSubscriptionBroadcaster := &SIRIVehicleMonitoringSubscriptionBroadcaster{}
+	siriVehicleMonitoringSubscriptionBroadcaster.partner = partner
+	siriVehicleMonitoringSubscriptionBroadcaster.mutex = &sync.Mutex{}
+	siriVehicleMonitoringSubscriptionBroadcaster.toBroadcast = make(map[SubscriptionId][]model.VehicleId)
+
+	siriVehicleMonitoringSubscriptionBroadcaster.vehicleMonitoringBroadcaster = NewSIRIVehicleMonitoringBroadcaster(siriVehicleMonitoringSubscriptionBroadcaster)
+	return siriVehicleMonitoringSubscriptionBroadcaster
+}
+
+func (connector *SIRIVehicleMonitoringSubscriptionBroadcaster) HandleSubscriptionRequest(request *siri.XMLSubscriptionRequest, message *audit.BigQueryMessage) (resps []siri.SIRIResponseStatus) {
+	tx := connector.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	var lineIds, subIds []string
+
+	for _, vm := range request.XMLSubscriptionVMEntries() {
+		logStashEvent := connector.newLogStashEvent()
+		logSIRIVehicleMonitoringSubscriptionEntry(logStashEvent, vm)
+
+		rs := siri.SIRIResponseStatus{
+			RequestMessageRef: vm.MessageIdentifier(),
+			SubscriberRef:     vm.SubscriberRef(),
+			SubscriptionRef:   vm.SubscriptionIdentifier(),
+			ResponseTimestamp: connector.Clock().Now(),
+		}
+
+		lineIds = append(lineIds, vm.LineRef())
+
+		lineObjectId := model.NewObjectID(connector.Partner().RemoteObjectIDKind(SIRI_VEHICLE_MONITORING_SUBSCRIPTION_BROADCASTER), vm.LineRef())
+		line, ok := tx.Model().Lines().FindByObjectId(lineObjectId)
+		if !ok {
+			logger.Log.Debugf("VehicleMonitoring subscription request Could not find line with id : %v", vm.LineRef())
+			rs.ErrorType = "InvalidDataReferencesError"
+			rs.ErrorText = fmt.Sprintf("Unknown Line %v", vm.LineRef())
+		} else {
+			rs.Status = true
+			rs.ValidUntil = vm.InitialTerminationTime()
+		}
+
+		resps = append(resps, rs)
+
+		logSIRIVehicleMonitoringSubscriptionResponseEntry(logStashEvent, &rs)
+		audit.CurrentLogStash().WriteEvent(logStashEvent)
+
+		if !ok {
+			continue
+		}
+
+		subIds = append(subIds, vm.SubscriptionIdentifier())
+
+		sub, ok := connector.Partner().Subscriptions().FindByExternalId(vm.SubscriptionIdentifier())
+		if !ok {
+			sub = connector.Partner().Subscriptions().New("VehicleMonitoringBroadcast")
+			sub.SetExternalId(vm.SubscriptionIdentifier())
+			connector.fillOptions(sub, request)
+		}
+
+		ref := model.Reference{
+			ObjectId: &lineObjectId,
+			Type:     "Line",
+		}
+		r := sub.CreateAddNewResource(ref)
+		r.SubscribedAt = connector.Clock().Now()
+		r.SubscribedUntil = vm.InitialTerminationTime()
+		sub.Save()
+
+		// Send the current vehicles of the line with the first notification
+		for _, vehicle := range tx.Model().Vehicles().FindByLineId(line.Id()) {
+			connector.addVehicle(sub.Id(), vehicle.Id())
+		}
+	}
+	message.Type = "VehicleMonitoringSubscriptionRequest"
+	message.SubscriptionIdentifiers = subIds
+	message.Lines = lineIds
+
+	return resps
+}
+
+func (connector *SIRIVehicleMonitoringSubscriptionBroadcaster) Stop() {
+	connector.vehicleMonitoringBroadcaster.Stop()
+}
+
+func (connector *SIRIVehicleMonitoringSubscriptionBroadcaster) Start() {
+	connector.vehicleMonitoringBroadcaster.Start()
+}
+
+func (connector *SIRIVehicleMonitoringSubscriptionBroadcaster) fillOptions(s *Subscription, request *siri.XMLSubscriptionRequest) {
+	s.SetSubscriptionOption("MessageIdentifier", request.MessageIdentifier())
+}
+
+func (connector *SIRIVehicleMonitoringSubscriptionBroadcaster) HandleBroadcastEvent(event *model.StopMonitoringBroadcastEvent) {
+	if event.ModelType != "Vehicle" {
+		return
+	}
+
+	tx := connector.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	vehicle, ok := tx.Model().Vehicles().Find(model.VehicleId(event.ModelId))
+	if !ok {
+		return
+	}
+
+	line, ok := tx.Model().Lines().Find(vehicle.LineId)
+	if !ok {
+		return
+	}
+
+	lineObj, ok := line.ObjectID(connector.Partner().RemoteObjectIDKind(SIRI_VEHICLE_MONITORING_SUBSCRIPTION_BROADCASTER))
+	if !ok {
+		return
+	}
+
+	for _, sub := range connector.Partner().Subscriptions().FindByResourceId(lineObj.String(), "VehicleMonitoringBroadcast") {
+		r := sub.Resource(lineObj)
+		if r == nil || r.SubscribedUntil.Before(connector.Clock().Now()) {
+			continue
+		}
+		connector.addVehicle(sub.Id(), vehicle.Id())
+	}
+}
+
+func (connector *SIRIVehicleMonitoringSubscriptionBroadcaster) addVehicle(subId SubscriptionId, vehicleId model.VehicleId) {
+	connector.mutex.Lock()
+	connector.toBroadcast[subId] = append(connector.toBroadcast[subId], vehicleId)
+	connector.mutex.Unlock()
+}
+
+func (connector *SIRIVehicleMonitoringSubscriptionBroadcaster) newLogStashEvent() audit.LogStashEvent {
+	event := connector.partner.NewLogStashEvent()
+	event["connector"] = "VehicleMonitoringSubscriptionBroadcaster"
+	return event
+}
+
+func logSIRIVehicleMonitoringSubscriptionEntry(logStashEvent audit.LogStashEvent, vmEntry *siri.XMLVehicleMonitoringSubscriptionRequestEntry) {
+	logStashEvent["siriType"] = "VehicleMonitoringSubscriptionEntry"
+	logStashEvent["lineRef"] = vmEntry.LineRef()
+	logStashEvent["messageIdentifier"] = vmEntry.MessageIdentifier()
+	logStashEvent["subscriberRef"] = vmEntry.SubscriberRef()
+	logStashEvent["subscriptionIdentifier"] = vmEntry.SubscriptionIdentifier()
+	logStashEvent["initialTerminationTime"] = vmEntry.InitialTerminationTime().String()
+	logStashEvent["requestTimestamp"] = vmEntry.RequestTimestamp().String()
+	logStashEvent["requestXML"] = vmEntry.RawXML()
+}
+
+func logSIRIVehicleMonitoringSubscriptionResponseEntry(logStashEvent audit.LogStashEvent, response *siri.SIRIResponseStatus) {
+	logStashEvent["requestMessageRef"] = response.RequestMessageRef
+	logStashEvent["subscriptionRef"] = response.SubscriptionRef
+	logStashEvent["responseTimestamp"] = response.ResponseTimestamp.String()
+	logStashEvent["validUntil"] = response.ValidUntil.String()
+	logStashEvent["status"] = strconv.FormatBool(response.Status)
+	if !response.Status {
+		logStashEvent["errorType"] = response.ErrorType
+		if response.ErrorType == "OtherError" {
+			logStashEvent["errorNumber"] = strconv.Itoa(response.ErrorNumber)
+		}
+		logStashEvent["errorText"] = response.ErrorText
+	}
+}
+
+// START TEST
+
+type TestSIRIVMSubscriptionBroadcasterFactory struct{}
+
+type TestVMSubscriptionBroadcaster struct {
+	uuid.UUIDConsumer
+
+	events []*model.StopMonitoringBroadcastEvent
+}
+
+func NewTestVMSubscriptionBroadcaster() *TestVMSubscriptionBroadcaster {
+	connector := &TestVMSubscriptionBroadcaster{}
+	return connector
+}
+
+func (connector *TestVMSubscriptionBroadcaster) HandleBroadcastEvent(event *model.StopMonitoringBroadcastEvent) {
+	if event.ModelType != "Vehicle" {
+		return
+	}
+	connector.events = append(connector.events, event)
+}
+
+func (factory *TestSIRIVMSubscriptionBroadcasterFactory) Validate(apiPartner *APIPartner) {} // Always valid
+
+func (factory *TestSIRIVMSubscriptionBroadcasterFactory) CreateConnector(partner *Partner) Connector {
+	return NewTestVMSubscriptionBroadcaster()
+}
@@ -0,0 +1,245 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/state"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+type VehicleMonitoringSubscriptionCollector interface {
+	state.Stopable
+	state.Startable
+
+	RequestVehicleUpdate(request *VehicleUpdateRequest)
+	HandleNotifyVehicleMonitoring(delivery *siri.XMLNotifyVehicleMonitoring)
+}
+
+type SIRIVehicleMonitoringSubscriptionCollector struct {
+	clock.ClockConsumer
+	uuid.UUIDConsumer
+
+	siriConnector
+
+	vehicleMonitoringSubscriber SIRIVehicleMonitoringSubscriber
+	updateSubscriber            UpdateSubscriber
+}
+
+type SIRIVehicleMonitoringSubscriptionCollectorFactory struct{}
+
+func (factory *SIRIVehicleMonitoringSubscriptionCollectorFactory) CreateConnector(partner *Partner) Connector {
+	return NewSIRIVehicleMonitoringSubscriptionCollector(partner)
+}
+
+func (factory *SIRIVehicleMonitoringSubscriptionCollectorFactory) Validate(apiPartner *APIPartner) {
+	apiPartner.ValidatePresenceOfSetting(REMOTE_OBJECTID_KIND)
+	apiPartner.ValidatePresenceOfSetting(REMOTE_URL)
+	apiPartner.ValidatePresenceOfSetting(REMOTE_CREDENTIAL)
+	apiPartner.ValidatePresenceOfLocalCredentials()
+}
+
+func NewSIRIVehicleMonitoringSubscriptionCollector(partner *Partner) *SIRIVehicleMonitoringSubscriptionCollector {
+	connector := &SIRIVehicleMonitoringSubscriptionCollector{}
+	connector.partner = partner
+	manager := partner.Referential().CollectManager()
+	connector.updateSubscriber = manager.BroadcastUpdateEvent
+	connector.vehicleMonitoringSubscriber = NewSIRIVehicleMonitoringSubscriber(connector)
+
+	return connector
+}
+
+func (connector *SIRIVehicleMonitoringSubscriptionCollector) Stop() {
+	connector.vehicleMonitoringSubscriber.Stop()
+}
+
+func (connector *SIRIVehicleMonitoringSubscriptionCollector) Start() {
+	connector.vehicleMonitoringSubscriber.Start()
+}
+
+func (connector *SIRIVehicleMonitoringSubscriptionCollector) RequestVehicleUpdate(request *VehicleUpdateRequest) {
+	tx := connector.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	line, ok := tx.Model().Lines().Find(request.LineId())
+	if !ok {
+		logger.Log.Debugf("VehicleUpdateRequest in VehicleMonitoring SubscriptionCollector for unknown Line %v", request.LineId())
+		return
+	}
+
+	objectidKind := connector.Partner().Setting(REMOTE_OBJECTID_KIND)
+	lineObjectid, ok := line.ObjectID(objectidKind)
+	if !ok {
+		logger.Log.Debugf("Requested line %v doesn't have and objectId of kind %v", request.LineId(), objectidKind)
+		return
+	}
+
+	// Try to find a Subscription with the resource
+	subscriptions := connector.partner.Subscriptions().FindByResourceId(lineObjectid.String(), "VehicleMonitoringCollect")
+	if len(subscriptions) > 0 {
+		for _, subscription := range subscriptions {
+			resource := subscription.Resource(lineObjectid)
+			if resource == nil { // Should never happen
+				logger.Log.Debugf("Can't find resource in subscription after Subscriptions#FindByResourceId")
+				return
+			}
+			if !resource.SubscribedAt.IsZero() {
+				resource.SubscribedUntil = connector.Clock().Now().Add(2 * time.Minute)
+			}
+		}
+		return
+	}
+
+	// Else we find or create a subscription to add the resource
+	newSubscription := connector.partner.Subscriptions().FindOrCreateByKind("VehicleMonitoringCollect")
+	ref := model.Reference{
+		ObjectId: &lineObjectid,
+		Type:     "Line",
+	}
+
+	newSubscription.CreateAddNewResource(ref)
+}
+
+func (connector *SIRIVehicleMonitoringSubscriptionCollector) SetVehicleMonitoringSubscriber(vehicleMonitoringSubscriber SIRIVehicleMonitoringSubscriber) {
+	connector.vehicleMonitoringSubscriber = vehicleMonitoringSubscriber
+}
+
+func (connector *SIRIVehicleMonitoringSubscriptionCollector) SetUpdateSubscriber(updateSubscriber UpdateSubscriber) {
+	connector.updateSubscriber = updateSubscriber
+}
+
+func (connector *SIRIVehicleMonitoringSubscriptionCollector) HandleNotifyVehicleMonitoring(notify *siri.XMLNotifyVehicleMonitoring) {
+	logStashEvent := connector.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+	vehicleRefMap := make(map[string]struct{})
+	subscriptionErrors := make(map[string]string)
+	subToDelete := make(map[string]struct{})
+
+	logXMLNotifyVehicleMonitoring(logStashEvent, notify)
+
+	for _, delivery := range notify.VehicleMonitoringDeliveries() {
+		subscriptionId := delivery.SubscriptionRef()
+
+		subscription, ok := connector.Partner().Subscriptions().Find(SubscriptionId(subscriptionId))
+		if !ok {
+			logger.Log.Debugf("Partner %s sent a NotifyVehicleMonitoring to a non existant subscription of id: %s\n", connector.Partner().Slug(), subscriptionId)
+			subscriptionErrors[subscriptionId] = "Non existant subscription of id %s"
+			subToDelete[delivery.SubscriptionRef()] = struct{}{}
+			continue
+		}
+		if subscription.Kind() != "VehicleMonitoringCollect" {
+			logger.Log.Debugf("Partner %s sent a NotifyVehicleMonitoring to a subscription with kind: %s\n", connector.Partner().Slug(), subscription.Kind())
+			subscriptionErrors[subscriptionId] = "Subscription of id %s is not a subscription of kind VehicleMonitoringCollect"
+			continue
+		}
+
+		builder := NewVehicleMonitoringUpdateEventBuilder(connector.partner, SIRI_VEHICLE_MONITORING_SUBSCRIPTION_COLLECTOR)
+		builder.SetUpdateEvents(delivery.XMLVehicleActivities())
+		updateEvents := builder.UpdateEvents()
+
+		// Copy VehicleRefs for global log
+		for k := range updateEvents.VehicleRefs {
+			vehicleRefMap[k] = struct{}{}
+		}
+
+		connector.broadcastUpdateEvents(&updateEvents)
+	}
+
+	logVehicleMonitoringRefs(logStashEvent, nil, vehicleRefMap)
+	if len(subscriptionErrors) != 0 {
+		logSubscriptionErrorsFromMap(logStashEvent, subscriptionErrors)
+	}
+
+	for subId := range subToDelete {
+		connector.cancelSubscription(subId)
+	}
+}
+
+func (connector *SIRIVehicleMonitoringSubscriptionCollector) cancelSubscription(subId string) {
+	logStashEvent := connector.newLogStashEvent()
+	defer audit.CurrentLogStash().WriteEvent(logStashEvent)
+	message := connector.newBQEvent()
+	defer audit.CurrentBigQuery(string(connector.Partner().Referential().Slug())).WriteEvent(message)
+
+	request := &siri.SIRIDeleteSubscriptionRequest{
+		RequestTimestamp:  connector.Clock().Now(),
+		SubscriptionRef:   subId,
+		RequestorRef:      connector.partner.ProducerRef(),
+		MessageIdentifier: connector.Partner().IdentifierGenerator(MESSAGE_IDENTIFIER).NewMessageIdentifier(),
+	}
+	logSIRIDeleteSubscriptionRequest(logStashEvent, message, request, "VehicleMonitoringSubscriptionCollector")
+
+	startTime := connector.Clock().Now()
+	response, err := connector.SIRIPartner().SOAPClient().DeleteSubscription(request)
+
+	responseTime := connector.Clock().Since(startTime)
+	logStashEvent["responseTime"] = responseTime.String()
+	message.ProcessingTime = responseTime.Seconds()
+
+	if err != nil {
+		logger.Log.Debugf("Error while terminating subcription with id : %v error : %v", subId, err.Error())
+		e := fmt.Sprintf("Error during DeleteSubscription: %v", err)
+		logStashEvent["status"] = "false"
+		logStashEvent["errorDescription"] = e
+		message.Status = "Error"
+		message.ErrorDetails = e
+		return
+	}
+	logXMLDeleteSubscriptionResponse(logStashEvent, message, response)
+}
+
+func (connector *SIRIVehicleMonitoringSubscriptionCollector) broadcastUpdateEvents(events *VehicleMonitoringUpdateEvents) {
+	if connector.updateSubscriber == nil {
+		return
+	}
+	for _, e := range events.Lines {
+		connector.updateSubscriber(e)
+	}
+	for _, e := range events.VehicleJourneys {
+		connector.updateSubscriber(e)
+	}
+	for _, e := range events.Vehicles {
+		connector.updateSubscriber(e)
+	}
+}
+
+func (connector *SIRIVehicleMonitoringSubscriptionCollector) newLogStashEvent() audit.LogStashEvent {
+	event := connector.partner.NewLogStashEvent()
+	event["connector"] = "VehicleMonitoringSubscriptionCollector"
+	return event
+}
+
+func (connector *SIRIVehicleMonitoringSubscriptionCollector) newBQEvent() *audit.BigQueryMessage {
+	return &audit.BigQueryMessage{
+		Protocol:  "siri",
+		Direction: "sent",
+		Partner:   string(connector.partner.Slug()),
+		Status:    "OK",
+	}
+}
+
+func logXMLNotifyVehicleMonitoring(logStashEvent audit.LogStashEvent, notify *siri.XMLNotifyVehicleMonitoring) {
+	logStashEvent["siriType"] = "CollectedNotifyVehicleMonitoring"
+	logStashEvent["producerRef"] = notify.ProducerRef()
+	logStashEvent["requestMessageRef"] = notify.RequestMessageRef()
+	logStashEvent["responseMessageIdentifier"] = notify.ResponseMessageIdentifier()
+	logStashEvent["responseTimestamp"] = notify.ResponseTimestamp().String()
+	logStashEvent["responseXML"] = notify.RawXML()
+
+	status := "true"
+	errorCount := 0
+	for _, delivery := range notify.VehicleMonitoringDeliveries() {
+		if !delivery.Status() {
+			status = "false"
+			errorCount++
+		}
+	}
+	logStashEvent["status"] = status
+	logStashEvent["errorCount"] = strconv.Itoa(errorCount)
+}
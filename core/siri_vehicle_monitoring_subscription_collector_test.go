@@ -0,0 +1,56 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+func Test_SIRIVehicleMonitoringSubscriptionCollector_HandleNotifyVehicleMonitoring(t *testing.T) {
+	audit.SetCurrentLogstash(audit.NewFakeLogStash())
+
+	collectManager := NewTestCollectManager()
+	referential := &Referential{
+		collectManager: collectManager,
+		model:          model.NewMemoryModel(),
+	}
+
+	partners := NewPartnerManager(referential)
+	partner := partners.New("slug")
+	partner.SetSetting("remote_objectid_kind", "_internal")
+	partner.SetSetting("generators.subscription_identifier", "Subscription::%{id}::LOC")
+	partner.SetSetting("collect.include_lines", "RLA:Line:1:LOC")
+
+	connector := NewSIRIVehicleMonitoringSubscriptionCollector(partner)
+
+	file, err := os.Open("testdata/notify-vehicle-monitoring.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notify, err := siri.NewXMLNotifyVehicleMonitoringFromContent(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	partner.Subscriptions().SetUUIDGenerator(uuid.NewFakeUUIDGenerator())
+	subscription := connector.partner.Subscriptions().FindOrCreateByKind("VehicleMonitoringCollect")
+	subscription.Save()
+
+	connector.HandleNotifyVehicleMonitoring(notify)
+
+	// 1 Line 1 VehicleJourney 1 Vehicle, the second Line isn't collected
+	if len(collectManager.(*TestCollectManager).UpdateEvents) != 3 {
+		t.Errorf("Wrong number of events in collectManager, expected 3 got %v", len(collectManager.(*TestCollectManager).UpdateEvents))
+	}
+}
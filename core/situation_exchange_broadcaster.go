@@ -0,0 +1,207 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/state"
+)
+
+type SIRISituationExchangeBroadcaster interface {
+	state.Stopable
+	state.Startable
+}
+
+type SXBroadcaster struct {
+	clock.ClockConsumer
+
+	connector *SIRISituationExchangeSubscriptionBroadcaster
+}
+
+type SituationExchangeBroadcaster struct {
+	SXBroadcaster
+
+	stop chan struct{}
+}
+
+type FakeSituationExchangeBroadcaster struct {
+	SXBroadcaster
+
+	clock.ClockConsumer
+}
+
+func NewFakeSituationExchangeBroadcaster(connector *SIRISituationExchangeSubscriptionBroadcaster) SIRISituationExchangeBroadcaster {
+	broadcaster := &FakeSituationExchangeBroadcaster{}
+	broadcaster.connector = connector
+	return broadcaster
+}
+
+func (broadcaster *FakeSituationExchangeBroadcaster) Start() {
+	broadcaster.prepareSIRISituationExchangeNotify()
+}
+
+func (broadcaster *FakeSituationExchangeBroadcaster) Stop() {}
+
+func NewSIRISituationExchangeBroadcaster(connector *SIRISituationExchangeSubscriptionBroadcaster) SIRISituationExchangeBroadcaster {
+	broadcaster := &SituationExchangeBroadcaster{}
+	broadcaster.connector = connector
+
+	return broadcaster
+}
+
+func (sxb *SituationExchangeBroadcaster) Start() {
+	logger.Log.Debugf("Start SituationExchangeBroadcaster")
+
+	sxb.stop = make(chan struct{})
+	go sxb.run()
+}
+
+func (sxb *SituationExchangeBroadcaster) run() {
+	c := sxb.Clock().After(5 * time.Second)
+
+	for {
+		select {
+		case <-sxb.stop:
+			logger.Log.Debugf("situation exchange broadcaster routine stop")
+
+			return
+		case <-c:
+			logger.Log.Debugf("SIRISituationExchangeBroadcaster visit")
+
+			sxb.prepareSIRISituationExchangeNotify()
+
+			c = sxb.Clock().After(5 * time.Second)
+		}
+	}
+}
+
+func (sxb *SituationExchangeBroadcaster) Stop() {
+	if sxb.stop != nil {
+		close(sxb.stop)
+	}
+}
+
+func (sxb *SXBroadcaster) prepareSIRISituationExchangeNotify() {
+	sxb.connector.mutex.Lock()
+
+	events := sxb.connector.toBroadcast
+	sxb.connector.toBroadcast = make(map[SubscriptionId][]model.SituationId)
+
+	sxb.connector.mutex.Unlock()
+
+	tx := sxb.connector.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	for subId, situationIds := range events {
+		sub, ok := sxb.connector.Partner().Subscriptions().Find(subId)
+		if !ok {
+			continue
+		}
+
+		notify := siri.SIRINotifySituationExchange{
+			Address:                   sxb.connector.Partner().Address(),
+			ProducerRef:               sxb.connector.Partner().ProducerRef(),
+			ResponseMessageIdentifier: sxb.connector.Partner().IdentifierGenerator(RESPONSE_MESSAGE_IDENTIFIER).NewMessageIdentifier(),
+			SubscriberRef:             sxb.connector.SIRIPartner().SubscriberRef(),
+			SubscriptionIdentifier:    sub.ExternalId(),
+			RequestMessageRef:         sub.SubscriptionOption("MessageIdentifier"),
+			Status:                    true,
+			ResponseTimestamp:         sxb.Clock().Now(),
+		}
+
+		builder := NewBroadcastSituationExchangeBuilder(tx, sxb.connector.Partner(), SIRI_SITUATION_EXCHANGE_SUBSCRIPTION_BROADCASTER)
+		if sub.SubscriptionOption("LineRef") != "" {
+			builder.SetLineRef(strings.Split(sub.SubscriptionOption("LineRef"), ","))
+		}
+		if sub.SubscriptionOption("StopPointRef") != "" {
+			builder.SetStopPointRef(strings.Split(sub.SubscriptionOption("StopPointRef"), ","))
+		}
+
+		for _, situationId := range situationIds {
+			situation, ok := tx.Model().Situations().Find(situationId)
+			if !ok {
+				logger.Log.Debugf("Could not find situation : %v in situation exchange broadcaster", situationId)
+				continue
+			}
+
+			siriSituation := builder.BuildSituationExchange(situation)
+			if siriSituation == nil {
+				continue
+			}
+			notify.Situations = append(notify.Situations, siriSituation)
+		}
+		if len(notify.Situations) != 0 {
+			logStashEvent := sxb.newLogStashEvent()
+			message := sxb.newBQEvent()
+
+			logSIRISituationExchangeNotify(logStashEvent, message, &notify)
+			audit.CurrentLogStash().WriteEvent(logStashEvent)
+			t := sxb.Clock().Now()
+
+			err := sxb.connector.SIRIPartner().SOAPClient().NotifySituationExchange(&notify)
+			message.ProcessingTime = sxb.Clock().Since(t).Seconds()
+			if err != nil {
+				event := sxb.newLogStashEvent()
+				logSIRINotifyError(err.Error(), notify.ResponseMessageIdentifier, event)
+				audit.CurrentLogStash().WriteEvent(event)
+			}
+
+			audit.CurrentBigQuery(string(sxb.connector.Partner().Referential().Slug())).WriteEvent(message)
+		}
+	}
+}
+
+func (sxb *SXBroadcaster) newBQEvent() *audit.BigQueryMessage {
+	return &audit.BigQueryMessage{
+		Type:      "NotifySituationExchange",
+		Protocol:  "siri",
+		Direction: "sent",
+		Partner:   string(sxb.connector.partner.Slug()),
+		Status:    "OK",
+	}
+}
+
+func (sxb *SXBroadcaster) newLogStashEvent() audit.LogStashEvent {
+	event := sxb.connector.partner.NewLogStashEvent()
+	event["connector"] = "SituationExchangeSubscriptionBroadcaster"
+	return event
+}
+
+func logSIRISituationExchangeNotify(logStashEvent audit.LogStashEvent, message *audit.BigQueryMessage, response *siri.SIRINotifySituationExchange) {
+	message.RequestIdentifier = response.RequestMessageRef
+	message.ResponseIdentifier = response.ResponseMessageIdentifier
+	message.SubscriptionIdentifiers = []string{response.SubscriptionIdentifier}
+
+	logStashEvent["siriType"] = "NotifySituationExchange"
+	logStashEvent["producerRef"] = response.ProducerRef
+	logStashEvent["requestMessageRef"] = response.RequestMessageRef
+	logStashEvent["responseMessageIdentifier"] = response.ResponseMessageIdentifier
+	logStashEvent["responseTimestamp"] = response.ResponseTimestamp.String()
+	logStashEvent["subscriberRef"] = response.SubscriberRef
+	logStashEvent["subscriptionIdentifier"] = response.SubscriptionIdentifier
+	logStashEvent["status"] = strconv.FormatBool(response.Status)
+	if !response.Status {
+		message.Status = "Error"
+		logStashEvent["errorType"] = response.ErrorType
+		if response.ErrorType == "OtherError" {
+			logStashEvent["errorNumber"] = strconv.Itoa(response.ErrorNumber)
+		}
+		logStashEvent["errorText"] = response.ErrorText
+		message.ErrorDetails = response.ErrorString()
+	}
+	xml, err := response.BuildXML()
+	if err != nil {
+		logStashEvent["responseXML"] = fmt.Sprintf("%v", err)
+		return
+	}
+	logStashEvent["responseXML"] = xml
+	message.ResponseRawMessage = xml
+	message.ResponseSize = int64(len(xml))
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+type SituationExchangeUpdateEventBuilder struct {
+	clock.ClockConsumer
+	uuid.UUIDConsumer
+
+	partner            *Partner
+	remoteObjectidKind string
+}
+
+func NewSituationExchangeUpdateEventBuilder(partner *Partner) SituationExchangeUpdateEventBuilder {
+	return SituationExchangeUpdateEventBuilder{
+		partner:            partner,
+		remoteObjectidKind: partner.Setting(REMOTE_OBJECTID_KIND),
+	}
+}
+
+func (builder *SituationExchangeUpdateEventBuilder) SetSituationExchangeDeliveryUpdateEvents(events *[]*model.SituationUpdateEvent, xmlResponse *siri.XMLSituationExchangeDelivery, producerRef string) {
+	for _, xmlSituation := range xmlResponse.XMLPtSituationElements() {
+		builder.buildSituationExchangeUpdateEvent(events, xmlSituation, producerRef)
+	}
+}
+
+func (builder *SituationExchangeUpdateEventBuilder) SetSituationExchangeResponseUpdateEvents(events *[]*model.SituationUpdateEvent, xmlResponse *siri.XMLSituationExchangeResponse) {
+	for _, xmlSituation := range xmlResponse.XMLPtSituationElements() {
+		builder.buildSituationExchangeUpdateEvent(events, xmlSituation, xmlResponse.ProducerRef())
+	}
+}
+
+func (builder *SituationExchangeUpdateEventBuilder) buildSituationExchangeUpdateEvent(events *[]*model.SituationUpdateEvent, xmlSituation *siri.XMLPtSituationElement, producerRef string) {
+	if xmlSituation.SituationNumber() == "" || xmlSituation.Progress() == "closed" {
+		return
+	}
+
+	if xmlSituation.ParticipantRef() != "" {
+		producerRef = xmlSituation.ParticipantRef()
+	}
+
+	situationEvent := &model.SituationUpdateEvent{
+		Origin:            string(builder.partner.Slug()),
+		CreatedAt:         builder.Clock().Now(),
+		RecordedAt:        xmlSituation.CreationTime(),
+		SituationObjectID: model.NewObjectID(builder.remoteObjectidKind, xmlSituation.SituationNumber()),
+		Version:           xmlSituation.Version(),
+		ProducerRef:       producerRef,
+	}
+	situationEvent.SetId(model.SituationUpdateRequestId(builder.NewUUID()))
+
+	attributes := &situationEvent.SituationAttributes
+	attributes.Summary = xmlSituation.Summary()
+	attributes.Description = xmlSituation.Description()
+	attributes.Reason = xmlSituation.AlertCause()
+	attributes.Severity = xmlSituation.Severity()
+
+	for _, xmlPeriod := range xmlSituation.ValidityPeriods() {
+		attributes.ValidityPeriods = append(attributes.ValidityPeriods, builder.timeRange(xmlPeriod))
+	}
+	for _, xmlWindow := range xmlSituation.PublicationWindows() {
+		attributes.PublicationWindows = append(attributes.PublicationWindows, builder.timeRange(xmlWindow))
+	}
+
+	builder.setAffects(attributes, "NetworkRef", xmlSituation.AffectedNetworkRefs())
+	builder.setAffects(attributes, "LineRef", xmlSituation.AffectedLineRefs())
+	builder.setAffects(attributes, "StopPointRef", xmlSituation.AffectedStopPointRefs())
+	builder.setAffects(attributes, "DatedVehicleJourneyRef", xmlSituation.AffectedVehicleJourneyRefs())
+
+	builder.setGeneralMessageAttributes(attributes)
+
+	*events = append(*events, situationEvent)
+}
+
+func (builder *SituationExchangeUpdateEventBuilder) timeRange(xmlTimeRange *siri.XMLTimeRange) *model.TimeRange {
+	return &model.TimeRange{
+		StartTime: xmlTimeRange.StartTime(),
+		EndTime:   xmlTimeRange.EndTime(),
+	}
+}
+
+func (builder *SituationExchangeUpdateEventBuilder) setAffects(attributes *model.SituationAttributes, kind string, refs []string) {
+	for _, ref := range refs {
+		reference := model.NewReference(model.NewObjectID(builder.remoteObjectidKind, ref))
+		reference.Type = kind
+		attributes.Affects = append(attributes.Affects, reference)
+	}
+}
+
+// Fill the GeneralMessage attributes so the situation can be broadcasted to
+// GeneralMessage partners
+func (builder *SituationExchangeUpdateEventBuilder) setGeneralMessageAttributes(attributes *model.SituationAttributes) {
+	if attributes.Summary != "" {
+		attributes.Messages = append(attributes.Messages, &model.Message{
+			Content: attributes.Summary,
+			Type:    "shortMessage",
+		})
+	}
+	if attributes.Description != "" {
+		attributes.Messages = append(attributes.Messages, &model.Message{
+			Content: attributes.Description,
+			Type:    "longMessage",
+		})
+	}
+
+	for _, affect := range attributes.Affects {
+		if affect.Type != "LineRef" && affect.Type != "StopPointRef" {
+			continue
+		}
+		reference := model.NewReference(*affect.ObjectId)
+		reference.Type = affect.Type
+		attributes.References = append(attributes.References, reference)
+	}
+
+	for _, period := range attributes.ValidityPeriods {
+		if period.EndTime.After(attributes.ValidUntil) {
+			attributes.ValidUntil = period.EndTime
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+)
+
+func Test_SituationExchangeUpdateEventBuilder_BuildSituationExchangeUpdateEvent(t *testing.T) {
+	file, err := os.Open("../siri/testdata/situation_exchange_response-soap.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, _ := siri.NewXMLSituationExchangeResponseFromContent(content)
+
+	referentials := NewMemoryReferentials()
+	referential := referentials.New("slug")
+	partner := referential.Partners().New("slug")
+	partner.SetSetting("remote_objectid_kind", "remote_objectid_kind")
+	builder := NewSituationExchangeUpdateEventBuilder(partner)
+
+	events := &[]*model.SituationUpdateEvent{}
+
+	builder.SetSituationExchangeResponseUpdateEvents(events, response)
+
+	if len(*events) != 1 {
+		t.Fatalf("One event should have been created, got %v", len(*events))
+	}
+
+	event := (*events)[0]
+	if expected := model.NewObjectID("remote_objectid_kind", "NINOXE:Situation:27_1"); event.SituationObjectID != expected {
+		t.Errorf("Wrong SituationObjectID, expected: %v, got: %v", expected, event.SituationObjectID)
+	}
+	if event.Version != 2 {
+		t.Errorf("Wrong Version, expected: 2, got: %v", event.Version)
+	}
+	if event.ProducerRef != "NINOXE" {
+		t.Errorf("Wrong ProducerRef, expected: NINOXE, got: %v", event.ProducerRef)
+	}
+
+	attributes := event.SituationAttributes
+	if attributes.Summary != "Travaux sur la ligne" {
+		t.Errorf("Wrong Summary: %v", attributes.Summary)
+	}
+	if attributes.Reason != "roadworks" {
+		t.Errorf("Wrong Reason, expected: roadworks, got: %v", attributes.Reason)
+	}
+	if attributes.Severity != "slight" {
+		t.Errorf("Wrong Severity, expected: slight, got: %v", attributes.Severity)
+	}
+	if len(attributes.ValidityPeriods) != 1 || len(attributes.PublicationWindows) != 1 {
+		t.Fatalf("Wrong number of ValidityPeriods or PublicationWindows: %v %v", len(attributes.ValidityPeriods), len(attributes.PublicationWindows))
+	}
+	if !attributes.PublicationWindows[0].EndTime.IsZero() {
+		t.Errorf("PublicationWindow EndTime should be zero, got: %v", attributes.PublicationWindows[0].EndTime)
+	}
+
+	if len(attributes.Affects) != 5 {
+		t.Fatalf("Wrong number of Affects, expected: 5, got: %v", len(attributes.Affects))
+	}
+	expectedAffects := []struct{ kind, value string }{
+		{"NetworkRef", "NINOXE:Network:1:LOC"},
+		{"LineRef", "NINOXE:Line:3:LOC"},
+		{"StopPointRef", "NINOXE:StopPoint:SP:24:LOC"},
+		{"StopPointRef", "NINOXE:StopPoint:SP:12:LOC"},
+		{"DatedVehicleJourneyRef", "NINOXE:VehicleJourney:201"},
+	}
+	for i, expected := range expectedAffects {
+		affect := attributes.Affects[i]
+		if affect.Type != expected.kind || affect.ObjectId.Value() != expected.value {
+			t.Errorf("Wrong Affect %v, expected: %v %v, got: %v %v", i, expected.kind, expected.value, affect.Type, affect.ObjectId.Value())
+		}
+	}
+
+	// GeneralMessage attributes
+	if len(attributes.Messages) != 2 {
+		t.Fatalf("Wrong number of Messages, expected: 2, got: %v", len(attributes.Messages))
+	}
+	if attributes.Messages[0].Type != "shortMessage" || attributes.Messages[0].Content != attributes.Summary {
+		t.Errorf("Wrong first Message: %v", attributes.Messages[0])
+	}
+	if len(attributes.References) != 3 {
+		t.Errorf("Wrong number of References, expected: 3, got: %v", len(attributes.References))
+	}
+	if !attributes.ValidUntil.Equal(attributes.ValidityPeriods[0].EndTime) {
+		t.Errorf("Wrong ValidUntil, expected: %v, got: %v", attributes.ValidityPeriods[0].EndTime, attributes.ValidUntil)
+	}
+}
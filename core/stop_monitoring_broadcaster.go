@@ -141,6 +141,8 @@ func (smb *SMBroadcaster) prepareSIRIStopMonitoringNotify() {
 	tx := smb.connector.Partner().Referential().NewTransaction()
 	defer tx.Close()
 
+	merger := smb.connector.merger(tx)
+
 	for key, stopVisits := range events {
 		sub, ok := smb.connector.Partner().Subscriptions().Find(key)
 		if !ok {
@@ -150,6 +152,7 @@ func (smb *SMBroadcaster) prepareSIRIStopMonitoringNotify() {
 		// Initialize builder
 		stopMonitoringBuilder := NewBroadcastStopMonitoringBuilder(tx, smb.connector.Partner(), SIRI_STOP_MONITORING_SUBSCRIPTION_BROADCASTER)
 		stopMonitoringBuilder.StopVisitTypes = sub.SubscriptionOption("StopVisitTypes")
+		stopMonitoringBuilder.merger = merger
 
 		// maximumStopVisits, _ := strconv.Atoi(sub.SubscriptionOption("MaximumStopVisits"))
 		monitoredStopVisits := make(map[model.StopVisitId]struct{}) //Making sure not to send 2 times the same SV
@@ -170,13 +173,21 @@ func (smb *SMBroadcaster) prepareSIRIStopMonitoringNotify() {
 			}
 
 			// Find the StopVisit
-			stopVisit, ok := tx.Model().StopVisits().Find(stopVisitId)
+			modelStopVisit, ok := tx.Model().StopVisits().Find(stopVisitId)
 			if !ok {
 				continue
 			}
+			stopVisit := &modelStopVisit
+			// With merging, the StopVisit it is merged into is sent instead
+			if merger != nil {
+				stopVisit = merger.MergedStopVisit(stopVisit)
+				if _, ok := monitoredStopVisits[stopVisit.Id()]; ok {
+					continue
+				}
+			}
 
 			// Find the Resource
-			monitoringRef, resource, ok := smb.findResource(stopVisit.StopAreaId, sub, tx)
+			monitoringRef, resource, ok := smb.findResource(stopVisit.StopAreaId, sub, merger, tx)
 			if !ok {
 				continue
 			}
@@ -186,11 +197,12 @@ func (smb *SMBroadcaster) prepareSIRIStopMonitoringNotify() {
 
 			// Get the monitoredStopVisit
 			stopMonitoringBuilder.MonitoringRef = monitoringRef
-			if !smb.handledStopVisitAppend(stopVisit, delivery, stopMonitoringBuilder) {
+			if !smb.handledStopVisitAppend(*stopVisit, delivery, stopMonitoringBuilder) {
 				continue
 			}
 
 			monitoredStopVisits[stopVisitId] = struct{}{}
+			monitoredStopVisits[stopVisit.Id()] = struct{}{}
 
 			// See what to do about the MaximumStopVisits #10333
 			// // Refresh delivery
@@ -206,7 +218,7 @@ func (smb *SMBroadcaster) prepareSIRIStopMonitoringNotify() {
 			if !ok {
 				continue
 			}
-			lastState.UpdateState(&stopVisit)
+			lastState.UpdateState(stopVisit)
 		}
 
 		for _, delivery := range deliveries {
@@ -236,8 +248,8 @@ func (smb *SMBroadcaster) getDelivery(deliveries map[string]*siri.SIRINotifyStop
 	return
 }
 
-func (smb *SMBroadcaster) findResource(stopAreaId model.StopAreaId, sub *Subscription, tx *model.Transaction) (string, *SubscribedResource, bool) {
-	for _, objectid := range tx.Model().StopAreas().FindAscendantsWithObjectIdKind(stopAreaId, smb.connector.Partner().RemoteObjectIDKind(SIRI_STOP_MONITORING_SUBSCRIPTION_BROADCASTER)) {
+func (smb *SMBroadcaster) findResource(stopAreaId model.StopAreaId, sub *Subscription, merger *Merger, tx *model.Transaction) (string, *SubscribedResource, bool) {
+	for _, objectid := range smb.connector.stopAreaObjectIds(stopAreaId, merger, tx) {
 		resource := sub.Resource(objectid)
 		if resource != nil {
 			return objectid.Value(), resource, true
@@ -314,3 +314,68 @@ func Test_StopMonitoringBroadcaster_Receive_Notify(t *testing.T) {
 		t.Errorf("1 stopVisit should need to be broadcasted %v", len)
 	}
 }
+
+func Test_StopMonitoringBroadcaster_MergeStopVisits(t *testing.T) {
+	fakeClock := clock.NewFakeClock()
+	clock.SetDefaultClock(fakeClock)
+
+	response := []byte{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, _ = ioutil.ReadAll(r.Body)
+		w.Header().Add("Content-Type", "text/xml")
+	}))
+	defer ts.Close()
+
+	referential := NewMemoryReferentials().New("referential")
+	referential.SetClock(fakeClock)
+
+	partner := referential.Partners().New("subscriber")
+	partner.SetSetting("remote_objectid_kind", "internal")
+	partner.SetSetting("local_credential", "external")
+	partner.SetSetting("remote_url", ts.URL)
+	partner.SetSetting(BROADCAST_MERGE_STOP_VISITS, "true")
+	partner.ConnectorTypes = []string{SIRI_STOP_MONITORING_SUBSCRIPTION_BROADCASTER}
+	partner.RefreshConnectors()
+	referential.Partners().Save(partner)
+
+	c, _ := partner.Connector(SIRI_STOP_MONITORING_SUBSCRIPTION_BROADCASTER)
+	connector := c.(*SIRIStopMonitoringSubscriptionBroadcaster)
+	connector.SetClock(fakeClock)
+
+	aimedTime := fakeClock.Now().Add(10 * time.Minute)
+	high, low := mergerTest_PrepareModel(referential, aimedTime)
+
+	objectid := model.NewObjectID("internal", "highStopArea")
+	subscription := partner.Subscriptions().New("StopMonitoringBroadcast")
+	subscription.SetExternalId("externalId")
+	subscription.CreateAddNewResource(model.Reference{ObjectId: &objectid, Type: "StopArea"})
+	subscription.Save()
+
+	// The StopVisit of "low" is at a StopArea without "internal" ObjectID
+	connector.HandleStopMonitoringBroadcastEvent(&model.StopMonitoringBroadcastEvent{ModelId: string(low.Id()), ModelType: "StopVisit"})
+	if l := len(connector.toBroadcast[subscription.Id()]); l != 1 {
+		t.Fatalf("StopVisit of the StopArea group should be broadcasted, got %v StopVisits", l)
+	}
+	connector.HandleStopMonitoringBroadcastEvent(&model.StopMonitoringBroadcastEvent{ModelId: string(high.Id()), ModelType: "StopVisit"})
+
+	NewFakeStopMonitoringBroadcaster(connector).Start()
+
+	notify, err := siri.NewXMLNotifyStopMonitoringFromContent(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliveries := notify.StopMonitoringDeliveries()
+	if len(deliveries) != 1 {
+		t.Fatalf("Wrong number of deliveries:\n got: %v\n want: 1", len(deliveries))
+	}
+	stopVisits := deliveries[0].XMLMonitoredStopVisits()
+	if len(stopVisits) != 1 {
+		t.Fatalf("Merged StopVisits should be sent once, got %v", len(stopVisits))
+	}
+	if stopVisits[0].ItemIdentifier() != "highStopVisit" {
+		t.Errorf("StopVisit of the partner with the highest priority should be sent, got %v", stopVisits[0].ItemIdentifier())
+	}
+	if expected := aimedTime.Add(2 * time.Minute); !stopVisits[0].ExpectedDepartureTime().Equal(expected) {
+		t.Errorf("Wrong merged ExpectedDepartureTime:\n got: %v\n want: %v", stopVisits[0].ExpectedDepartureTime(), expected)
+	}
+}
@@ -97,6 +97,9 @@ func (builder *StopMonitoringUpdateEventBuilder) buildUpdateEvents(xmlStopVisitE
 			DestinationRef:  xmlStopVisitEvent.DestinationRef(),
 			DestinationName: xmlStopVisitEvent.DestinationName(),
 			Monitored:       xmlStopVisitEvent.Monitored(),
+			FlagsDefined:    true,
+			Cancellation:    xmlStopVisitEvent.Cancellation(),
+			ExtraJourney:    xmlStopVisitEvent.ExtraJourney(),
 
 			ObjectidKind: builder.remoteObjectidKind,
 			SiriXML:      xmlStopVisitEvent,
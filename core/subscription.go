@@ -2,6 +2,8 @@ package core
 
 import (
 	"encoding/json"
+	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -232,6 +234,9 @@ type MemorySubscriptions struct {
 	partner *Partner
 
 	byIdentifier map[SubscriptionId]*Subscription
+	lastSaved    string
+	// ServiceStartedAt of the remote partner when the subscriptions were saved
+	serviceStartedAt time.Time
 }
 
 func (manager *MemorySubscriptions) MarshalJSON() ([]byte, error) {
@@ -261,6 +266,9 @@ type Subscriptions interface {
 	CancelCollectSubscriptions()
 	FindByResourceId(id, kind string) []*Subscription
 	FindByExternalId(externalId string) (*Subscription, bool)
+	Load() error
+	SaveToDatabase() error
+	ServiceStartedAt() time.Time
 }
 
 func NewMemorySubscriptions(partner *Partner) *MemorySubscriptions {
@@ -450,3 +458,178 @@ func (manager *MemorySubscriptions) CancelCollectSubscriptions() {
 		}
 	}
 }
+
+type databaseSubscribedResource struct {
+	Reference       model.Reference
+	RetryCount      int `json:",omitempty"`
+	SubscribedAt    time.Time
+	SubscribedUntil time.Time
+	Options         map[string]string `json:",omitempty"`
+}
+
+func (manager *MemorySubscriptions) referentialId() string {
+	if manager.partner.manager == nil || manager.partner.manager.Referential() == nil {
+		return ""
+	}
+	return string(manager.partner.manager.Referential().Id())
+}
+
+// Loads the Partner subscriptions saved in Database
+func (manager *MemorySubscriptions) Load() error {
+	selectSubscriptions := []model.SelectSubscription{}
+	sqlQuery := fmt.Sprintf("select * from subscriptions where partner_id = '%s'", manager.partner.Id())
+	_, err := model.Database.Select(&selectSubscriptions, sqlQuery)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range selectSubscriptions {
+		if s.ServiceStartedAt != nil {
+			manager.serviceStartedAt = *s.ServiceStartedAt
+		}
+
+		subscription := &Subscription{
+			id:                  SubscriptionId(s.Id),
+			kind:                s.Kind.String,
+			externalId:          s.ExternalId.String,
+			resourcesByObjectID: make(map[string]*SubscribedResource),
+			subscriptionOptions: make(map[string]string),
+		}
+
+		if s.Options.Valid && len(s.Options.String) > 0 {
+			if err = json.Unmarshal([]byte(s.Options.String), &subscription.subscriptionOptions); err != nil {
+				return err
+			}
+		}
+
+		if s.Resources.Valid && len(s.Resources.String) > 0 {
+			resources := []databaseSubscribedResource{}
+			if err = json.Unmarshal([]byte(s.Resources.String), &resources); err != nil {
+				return err
+			}
+			for _, r := range resources {
+				if r.Reference.ObjectId == nil {
+					continue
+				}
+				resource := &SubscribedResource{
+					Reference:        r.Reference,
+					RetryCount:       r.RetryCount,
+					SubscribedAt:     r.SubscribedAt,
+					SubscribedUntil:  r.SubscribedUntil,
+					lastStates:       make(map[string]lastState),
+					resourcesOptions: make(map[string]string),
+				}
+				for key, value := range r.Options {
+					resource.resourcesOptions[key] = value
+				}
+				subscription.resourcesByObjectID[r.Reference.ObjectId.String()] = resource
+			}
+		}
+
+		manager.Save(subscription)
+	}
+
+	logger.Log.Debugf("Loaded %v subscriptions for partner %v", len(selectSubscriptions), manager.partner.Slug())
+	return nil
+}
+
+// Replaces the Partner subscriptions saved in Database. Nothing is done when
+// the subscriptions haven't changed since the last save.
+func (manager *MemorySubscriptions) SaveToDatabase() error {
+	serviceStartedAt := manager.partner.PartnerStatus.ServiceStartedAt
+	if serviceStartedAt.IsZero() {
+		serviceStartedAt = manager.serviceStartedAt
+	}
+
+	dbSubscriptions := []*model.DatabaseSubscription{}
+	for _, subscription := range manager.FindAll() {
+		dbSubscription, err := manager.newDbSubscription(subscription)
+		if err != nil {
+			return err
+		}
+		if !serviceStartedAt.IsZero() {
+			utc := serviceStartedAt.UTC()
+			dbSubscription.ServiceStartedAt = &utc
+		}
+		dbSubscriptions = append(dbSubscriptions, dbSubscription)
+	}
+	sort.Slice(dbSubscriptions, func(i, j int) bool { return dbSubscriptions[i].Id < dbSubscriptions[j].Id })
+
+	digest, err := json.Marshal(dbSubscriptions)
+	if err != nil {
+		return err
+	}
+	if string(digest) == manager.lastSaved {
+		return nil
+	}
+
+	tx, err := model.Database.Begin()
+	if err != nil {
+		return err
+	}
+
+	sqlQuery := fmt.Sprintf("delete from subscriptions where partner_id = '%s';", manager.partner.Id())
+	if _, err = tx.Exec(sqlQuery); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, dbSubscription := range dbSubscriptions {
+		if err = tx.Insert(dbSubscription); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	manager.lastSaved = string(digest)
+	manager.serviceStartedAt = serviceStartedAt
+	return nil
+}
+
+// Returns the ServiceStartedAt of the remote partner saved with the
+// subscriptions, zero if unknown
+func (manager *MemorySubscriptions) ServiceStartedAt() time.Time {
+	return manager.serviceStartedAt
+}
+
+func (manager *MemorySubscriptions) newDbSubscription(subscription *Subscription) (*model.DatabaseSubscription, error) {
+	resources := []databaseSubscribedResource{}
+	for _, resource := range subscription.ResourcesByObjectIDCopy() {
+		resources = append(resources, databaseSubscribedResource{
+			Reference:       resource.Reference,
+			RetryCount:      resource.RetryCount,
+			SubscribedAt:    resource.SubscribedAt,
+			SubscribedUntil: resource.SubscribedUntil,
+			Options:         resource.ResourcesOptions(),
+		})
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].Reference.ObjectId.String() < resources[j].Reference.ObjectId.String()
+	})
+
+	jsonResources, err := json.Marshal(resources)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.RLock()
+	options, err := json.Marshal(subscription.subscriptionOptions)
+	subscription.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DatabaseSubscription{
+		Id:            string(subscription.Id()),
+		ReferentialId: manager.referentialId(),
+		PartnerId:     string(manager.partner.Id()),
+		Kind:          subscription.Kind(),
+		ExternalId:    subscription.ExternalId(),
+		Resources:     string(jsonResources),
+		Options:       string(options),
+	}, nil
+}
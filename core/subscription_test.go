@@ -2,6 +2,7 @@ package core
 
 import (
 	"testing"
+	"time"
 
 	"bitbucket.org/enroute-mobi/ara/model"
 )
@@ -114,3 +115,76 @@ func Test_Subscription_byIdentifier(t *testing.T) {
 		t.Errorf("Should have found the subscription")
 	}
 }
+
+func Test_MemorySubscriptions_newDbSubscription(t *testing.T) {
+	subscriptions := NewMemorySubscriptions(NewPartner())
+
+	subscription := subscriptions.New("StopMonitoringBroadcast")
+	subscription.SetExternalId("externalId")
+	subscription.SetSubscriptionOption("MessageIdentifier", "MessageIdentifier")
+	resource := subscription.CreateAddNewResource(*model.NewReference(model.NewObjectID("test", "value")))
+	resource.ResourcesOptions()["StopVisitTypes"] = "all"
+
+	dbSubscription, err := subscriptions.newDbSubscription(subscription)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dbSubscription.Id != string(subscription.Id()) || dbSubscription.Kind != "StopMonitoringBroadcast" || dbSubscription.ExternalId != "externalId" {
+		t.Errorf("Wrong DatabaseSubscription: %v", dbSubscription)
+	}
+	if expected := `{"MessageIdentifier":"MessageIdentifier"}`; dbSubscription.Options != expected {
+		t.Errorf("Wrong DatabaseSubscription Options:\n got: %v\n want: %v", dbSubscription.Options, expected)
+	}
+	expected := `[{"Reference":{"ObjectId":{"test":"value"}},"SubscribedAt":"0001-01-01T00:00:00Z","SubscribedUntil":"1984-04-04T00:02:00Z","Options":{"StopVisitTypes":"all"}}]`
+	if dbSubscription.Resources != expected {
+		t.Errorf("Wrong DatabaseSubscription Resources:\n got: %v\n want: %v", dbSubscription.Resources, expected)
+	}
+}
+
+func Test_MemorySubscriptions_SaveToDatabase_Load(t *testing.T) {
+	model.InitTestDb(t)
+	defer model.CleanTestDb(t)
+
+	referentials := NewMemoryReferentials()
+	referential := referentials.New("slug")
+	referential.Save()
+	partner := referential.Partners().New("partner")
+	partner.Save()
+
+	subscription := partner.Subscriptions().New("StopMonitoringBroadcast")
+	subscription.SetExternalId("externalId")
+	subscription.SetSubscriptionOption("MessageIdentifier", "MessageIdentifier")
+	objectid := model.NewObjectID("test", "value")
+	resource := subscription.CreateAddNewResource(*model.NewReference(objectid))
+	resource.SubscribedUntil = referential.Clock().Now().Add(time.Hour)
+	resource.ResourcesOptions()["StopVisitTypes"] = "all"
+	partner.PartnerStatus.ServiceStartedAt = time.Date(2017, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := partner.Subscriptions().SaveToDatabase(); err != nil {
+		t.Fatal(err)
+	}
+
+	subscriptions := NewMemorySubscriptions(partner)
+	if err := subscriptions.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if !subscriptions.ServiceStartedAt().Equal(partner.PartnerStatus.ServiceStartedAt) {
+		t.Errorf("Wrong loaded ServiceStartedAt: %v", subscriptions.ServiceStartedAt())
+	}
+
+	loaded, ok := subscriptions.FindByExternalId("externalId")
+	if !ok {
+		t.Fatal("Subscription should be loaded from Database")
+	}
+	if loaded.Id() != subscription.Id() || loaded.Kind() != "StopMonitoringBroadcast" || loaded.SubscriptionOption("MessageIdentifier") != "MessageIdentifier" {
+		t.Errorf("Wrong loaded Subscription: %v", loaded)
+	}
+	loadedResource := loaded.Resource(objectid)
+	if loadedResource == nil {
+		t.Fatal("Subscription resource should be loaded from Database")
+	}
+	if !loadedResource.SubscribedUntil.Equal(resource.SubscribedUntil) || loadedResource.ResourcesOptions()["StopVisitTypes"] != "all" {
+		t.Errorf("Wrong loaded SubscribedResource: %v", loadedResource)
+	}
+}
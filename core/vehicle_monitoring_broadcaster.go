@@ -0,0 +1,232 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/audit"
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+	"bitbucket.org/enroute-mobi/ara/state"
+)
+
+type SIRIVehicleMonitoringBroadcaster interface {
+	state.Stopable
+	state.Startable
+}
+
+type VMBroadcaster struct {
+	clock.ClockConsumer
+
+	connector *SIRIVehicleMonitoringSubscriptionBroadcaster
+}
+
+type VehicleMonitoringBroadcaster struct {
+	VMBroadcaster
+
+	stop chan struct{}
+}
+
+type FakeVehicleMonitoringBroadcaster struct {
+	VMBroadcaster
+
+	clock.ClockConsumer
+}
+
+func NewFakeVehicleMonitoringBroadcaster(connector *SIRIVehicleMonitoringSubscriptionBroadcaster) SIRIVehicleMonitoringBroadcaster {
+	broadcaster := &FakeVehicleMonitoringBroadcaster{}
+	broadcaster.connector = connector
+	return broadcaster
+}
+
+func (broadcaster *FakeVehicleMonitoringBroadcaster) Start() {
+	broadcaster.prepareSIRIVehicleMonitoring()
+}
+
+func (broadcaster *FakeVehicleMonitoringBroadcaster) Stop() {}
+
+func NewSIRIVehicleMonitoringBroadcaster(connector *SIRIVehicleMonitoringSubscriptionBroadcaster) SIRIVehicleMonitoringBroadcaster {
+	broadcaster := &VehicleMonitoringBroadcaster{}
+	broadcaster.connector = connector
+
+	return broadcaster
+}
+
+func (vm *VehicleMonitoringBroadcaster) Start() {
+	logger.Log.Debugf("Start VehicleMonitoringBroadcaster")
+
+	vm.stop = make(chan struct{})
+	go vm.run()
+}
+
+func (vm *VehicleMonitoringBroadcaster) run() {
+	c := vm.Clock().After(5 * time.Second)
+
+	for {
+		select {
+		case <-vm.stop:
+			logger.Log.Debugf("vehicle monitoring broadcaster routine stop")
+
+			return
+		case <-c:
+			logger.Log.Debugf("SIRIVehicleMonitoringBroadcaster visit")
+
+			vm.prepareSIRIVehicleMonitoring()
+
+			c = vm.Clock().After(5 * time.Second)
+		}
+	}
+}
+
+func (vm *VehicleMonitoringBroadcaster) Stop() {
+	if vm.stop != nil {
+		close(vm.stop)
+	}
+}
+
+func (vm *VMBroadcaster) prepareSIRIVehicleMonitoring() {
+	vm.connector.mutex.Lock()
+
+	events := vm.connector.toBroadcast
+	vm.connector.toBroadcast = make(map[SubscriptionId][]model.VehicleId)
+
+	vm.connector.mutex.Unlock()
+
+	tx := vm.connector.Partner().Referential().NewTransaction()
+	defer tx.Close()
+
+	builder := NewBroadcastVehicleMonitoringBuilder(tx, vm.connector.Partner(), SIRI_VEHICLE_MONITORING_SUBSCRIPTION_BROADCASTER)
+
+	for subId, vehicleIds := range events {
+		sub, ok := vm.connector.Partner().Subscriptions().Find(subId)
+		if !ok {
+			logger.Log.Debugf("VM subscriptionBroadcast Could not find sub with id : %v", subId)
+			continue
+		}
+
+		processedVehicles := make(map[model.VehicleId]struct{}) //Making sure not to send 2 times the same Vehicle
+
+		delivery := &siri.SIRINotifyVehicleMonitoring{
+			Address:                   vm.connector.Partner().Address(),
+			ProducerRef:               vm.connector.Partner().ProducerRef(),
+			ResponseMessageIdentifier: vm.connector.Partner().IdentifierGenerator(RESPONSE_MESSAGE_IDENTIFIER).NewMessageIdentifier(),
+			SubscriberRef:             vm.connector.SIRIPartner().SubscriberRef(),
+			SubscriptionIdentifier:    sub.ExternalId(),
+			ResponseTimestamp:         vm.connector.Clock().Now(),
+			Status:                    true,
+			RequestMessageRef:         sub.SubscriptionOption("MessageIdentifier"),
+		}
+
+		for _, vehicleId := range vehicleIds {
+			if _, ok := processedVehicles[vehicleId]; ok {
+				continue
+			}
+			processedVehicles[vehicleId] = struct{}{}
+
+			vehicle, ok := tx.Model().Vehicles().Find(vehicleId)
+			if !ok {
+				continue
+			}
+
+			activity := builder.BuildVehicleActivity(vehicle)
+			if activity == nil {
+				continue
+			}
+			delivery.VehicleActivities = append(delivery.VehicleActivities, activity)
+		}
+
+		if len(delivery.VehicleActivities) == 0 {
+			continue
+		}
+
+		vm.sendDelivery(delivery)
+	}
+}
+
+func (vm *VMBroadcaster) sendDelivery(delivery *siri.SIRINotifyVehicleMonitoring) {
+	logStashEvent := vm.newLogStashEvent()
+	message := vm.newBQEvent()
+
+	logSIRIVehicleMonitoringNotify(logStashEvent, message, delivery)
+	audit.CurrentLogStash().WriteEvent(logStashEvent)
+
+	t := vm.Clock().Now()
+
+	err := vm.connector.SIRIPartner().SOAPClient().NotifyVehicleMonitoring(delivery)
+	message.ProcessingTime = vm.Clock().Since(t).Seconds()
+	if err != nil {
+		event := vm.newLogStashEvent()
+		logSIRINotifyError(err.Error(), delivery.ResponseMessageIdentifier, event)
+		audit.CurrentLogStash().WriteEvent(event)
+	}
+
+	audit.CurrentBigQuery(string(vm.connector.Partner().Referential().Slug())).WriteEvent(message)
+}
+
+func (vm *VMBroadcaster) newBQEvent() *audit.BigQueryMessage {
+	return &audit.BigQueryMessage{
+		Type:      "NotifyVehicleMonitoring",
+		Protocol:  "siri",
+		Direction: "sent",
+		Partner:   string(vm.connector.partner.Slug()),
+		Status:    "OK",
+	}
+}
+
+func (vm *VMBroadcaster) newLogStashEvent() audit.LogStashEvent {
+	event := vm.connector.partner.NewLogStashEvent()
+	event["connector"] = "VehicleMonitoringSubscriptionBroadcaster"
+	return event
+}
+
+func logSIRIVehicleMonitoringNotify(logStashEvent audit.LogStashEvent, message *audit.BigQueryMessage, notify *siri.SIRINotifyVehicleMonitoring) {
+	lineRefs := []string{}
+	vehicleRefs := []string{}
+	lines := make(map[string]struct{})
+	for _, activity := range notify.VehicleActivities {
+		vehicleRefs = append(vehicleRefs, activity.VehicleMonitoringRef)
+		if _, ok := lines[activity.LineRef]; !ok {
+			lines[activity.LineRef] = struct{}{}
+			lineRefs = append(lineRefs, activity.LineRef)
+		}
+	}
+
+	message.RequestIdentifier = notify.RequestMessageRef
+	message.ResponseIdentifier = notify.ResponseMessageIdentifier
+	message.Lines = lineRefs
+	message.Vehicles = vehicleRefs
+	message.SubscriptionIdentifiers = []string{notify.SubscriptionIdentifier}
+
+	logStashEvent["siriType"] = "NotifyVehicleMonitoring"
+	logStashEvent["producerRef"] = notify.ProducerRef
+	logStashEvent["requestMessageRef"] = notify.RequestMessageRef
+	logStashEvent["responseMessageIdentifier"] = notify.ResponseMessageIdentifier
+	logStashEvent["responseTimestamp"] = notify.ResponseTimestamp.String()
+	logStashEvent["subscriberRef"] = notify.SubscriberRef
+	logStashEvent["subscriptionIdentifier"] = notify.SubscriptionIdentifier
+	logStashEvent["lineRefs"] = strings.Join(lineRefs, ",")
+	logStashEvent["vehicleRefs"] = strings.Join(vehicleRefs, ",")
+	logStashEvent["status"] = strconv.FormatBool(notify.Status)
+
+	if !notify.Status {
+		message.Status = "Error"
+		logStashEvent["errorType"] = notify.ErrorType
+		if notify.ErrorType == "OtherError" {
+			logStashEvent["errorNumber"] = strconv.Itoa(notify.ErrorNumber)
+		}
+		logStashEvent["errorText"] = notify.ErrorText
+		message.ErrorDetails = notify.ErrorString()
+	}
+	xml, err := notify.BuildXML()
+	if err != nil {
+		logStashEvent["responseXML"] = fmt.Sprintf("%v", err)
+		return
+	}
+	logStashEvent["responseXML"] = xml
+	message.ResponseRawMessage = xml
+	message.ResponseSize = int64(len(xml))
+}
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+func Test_VehicleMonitoringBroadcaster_Create_Events(t *testing.T) {
+	clock.SetDefaultClock(clock.NewFakeClock())
+
+	referentials := NewMemoryReferentials()
+	referential := referentials.New("referential")
+	referential.model = model.NewMemoryModel()
+
+	referential.model.SetBroadcastSMChan(referential.broacasterManager.GetStopMonitoringBroadcastEventChan())
+	referential.broacasterManager.Start()
+	defer referential.broacasterManager.Stop()
+
+	partner := referential.Partners().New("partner")
+	partner.SetSetting("remote_objectid_kind", "internal")
+	partner.ConnectorTypes = []string{TEST_VEHICLE_MONITORING_SUBSCRIPTION_BROADCASTER}
+	partner.RefreshConnectors()
+	referential.Partners().Save(partner)
+
+	connector, _ := partner.Connector(TEST_VEHICLE_MONITORING_SUBSCRIPTION_BROADCASTER)
+
+	vehicle := referential.Model().Vehicles().New()
+
+	time.Sleep(5 * time.Millisecond) // Wait for the goRoutine to start ...
+	vehicle.Save()
+	vehicle.Save() // Unchanged Vehicle should not be broadcasted
+
+	time.Sleep(5 * time.Millisecond) // Wait for the Broadcaster and Connector to finish their work
+	if len(connector.(*TestVMSubscriptionBroadcaster).events) != 1 {
+		t.Error("1 event should have been generated got: ", len(connector.(*TestVMSubscriptionBroadcaster).events))
+	}
+}
+
+func Test_VehicleMonitoringBroadcaster_Receive_Notify(t *testing.T) {
+	fakeClock := clock.NewFakeClock()
+	clock.SetDefaultClock(fakeClock)
+	uuidGenerator := uuid.NewFakeUUIDGenerator()
+
+	// Create a test http server
+	response := []byte{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, _ = ioutil.ReadAll(r.Body)
+		w.Header().Add("Content-Type", "text/xml")
+	}))
+	defer ts.Close()
+
+	referentials := NewMemoryReferentials()
+	referential := referentials.New("referential")
+	referential.SetClock(fakeClock)
+	referential.broacasterManager.Start()
+	defer referential.broacasterManager.Stop()
+
+	partner := referential.Partners().New("partner")
+	partner.SetSetting("remote_objectid_kind", "internal")
+	partner.SetSetting("remote_credential", "external")
+	partner.SetSetting("local_credential", "local")
+	partner.SetSetting("remote_url", ts.URL)
+
+	partner.ConnectorTypes = []string{SIRI_VEHICLE_MONITORING_SUBSCRIPTION_BROADCASTER}
+	partner.RefreshConnectors()
+	referential.Partners().Save(partner)
+
+	c, _ := partner.Connector(SIRI_VEHICLE_MONITORING_SUBSCRIPTION_BROADCASTER)
+	connector := c.(*SIRIVehicleMonitoringSubscriptionBroadcaster)
+	connector.Partner().SetUUIDGenerator(uuidGenerator)
+	connector.SetClock(fakeClock)
+	connector.vehicleMonitoringBroadcaster = NewFakeVehicleMonitoringBroadcaster(connector)
+
+	line := referential.Model().Lines().New()
+	objectid := model.NewObjectID("internal", "line")
+	line.SetObjectID(objectid)
+	line.Save()
+
+	subscription := partner.Subscriptions().New("VehicleMonitoringBroadcast")
+	subscription.SetExternalId("externalId")
+	subscription.CreateAddNewResource(model.Reference{ObjectId: &objectid, Type: "Line"})
+	subscription.SetSubscriptionOption("MessageIdentifier", "MessageIdentifier")
+	subscription.Save()
+
+	vehicleJourney := referential.Model().VehicleJourneys().New()
+	vehicleJourney.LineId = line.Id()
+	vehicleJourney.SetObjectID(model.NewObjectID("internal", "vehicleJourney"))
+	vehicleJourney.Save()
+
+	vehicle := referential.Model().Vehicles().New()
+	vehicle.SetObjectID(model.NewObjectID("internal", "vehicle"))
+	vehicle.LineId = line.Id()
+	vehicle.VehicleJourneyId = vehicleJourney.Id()
+	vehicle.RecordedAtTime = fakeClock.Now()
+
+	time.Sleep(10 * time.Millisecond) // Wait for the goRoutine to start ...
+	vehicle.Save()
+
+	time.Sleep(10 * time.Millisecond) // Wait for the Broadcaster and Connector to finish their work
+
+	if l := len(connector.toBroadcast); l != 1 {
+		t.Fatalf("should have 1 subscription to broadcast got : %v", l)
+	}
+
+	connector.vehicleMonitoringBroadcaster.Start()
+
+	for _, expected := range []string{
+		"<sw:NotifyVehicleMonitoring",
+		"<siri:SubscriptionRef>externalId</siri:SubscriptionRef>",
+		"<siri:RequestMessageRef>MessageIdentifier</siri:RequestMessageRef>",
+		"<siri:VehicleMonitoringRef>vehicle</siri:VehicleMonitoringRef>",
+		"<siri:LineRef>line</siri:LineRef>",
+		"<siri:DatedVehicleJourneyRef>vehicleJourney</siri:DatedVehicleJourneyRef>",
+	} {
+		if !strings.Contains(string(response), expected) {
+			t.Errorf("Notification should contain %v, got:\n%v", expected, string(response))
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/siri"
+)
+
+type VehicleMonitoringUpdateEventBuilder struct {
+	partner                   *Partner
+	remoteObjectidKind        string
+	vehicleRemoteObjectidKind string
+
+	vehicleMonitoringUpdateEvents *VehicleMonitoringUpdateEvents
+}
+
+type VehicleMonitoringUpdateEvents struct {
+	Lines           map[string]*model.LineUpdateEvent
+	VehicleJourneys map[string]*model.VehicleJourneyUpdateEvent
+	Vehicles        map[string]*model.VehicleUpdateEvent
+	LineRefs        map[string]struct{}
+	VehicleRefs     map[string]struct{}
+}
+
+func NewVehicleMonitoringUpdateEventBuilder(partner *Partner, connectorName string) VehicleMonitoringUpdateEventBuilder {
+	return VehicleMonitoringUpdateEventBuilder{
+		partner:                       partner,
+		remoteObjectidKind:            partner.Setting(REMOTE_OBJECTID_KIND),
+		vehicleRemoteObjectidKind:     partner.VehicleRemoteObjectIDKind(connectorName),
+		vehicleMonitoringUpdateEvents: newVehicleMonitoringUpdateEvents(),
+	}
+}
+
+func newVehicleMonitoringUpdateEvents() *VehicleMonitoringUpdateEvents {
+	return &VehicleMonitoringUpdateEvents{
+		Lines:           make(map[string]*model.LineUpdateEvent),
+		VehicleJourneys: make(map[string]*model.VehicleJourneyUpdateEvent),
+		Vehicles:        make(map[string]*model.VehicleUpdateEvent),
+		LineRefs:        make(map[string]struct{}),
+		VehicleRefs:     make(map[string]struct{}),
+	}
+}
+
+func (builder *VehicleMonitoringUpdateEventBuilder) buildUpdateEvents(xmlVehicleActivity *siri.XMLVehicleActivity) {
+	// Vehicles are only collected on the Lines defined by collect.include_lines
+	if !builder.partner.CanCollectLine(xmlVehicleActivity.LineRef()) {
+		return
+	}
+
+	vehicleRef := xmlVehicleActivity.VehicleMonitoringRef()
+	if vehicleRef == "" {
+		vehicleRef = xmlVehicleActivity.VehicleRef()
+	}
+	if vehicleRef == "" || xmlVehicleActivity.DatedVehicleJourneyRef() == "" {
+		return
+	}
+
+	origin := string(builder.partner.Slug())
+
+	// Lines
+	lineObjectId := model.NewObjectID(builder.remoteObjectidKind, xmlVehicleActivity.LineRef())
+
+	_, ok := builder.vehicleMonitoringUpdateEvents.Lines[xmlVehicleActivity.LineRef()]
+	if !ok {
+		// CollectedAlways is false by default
+		lineEvent := &model.LineUpdateEvent{
+			Origin:   origin,
+			ObjectId: lineObjectId,
+			Name:     xmlVehicleActivity.PublishedLineName(),
+		}
+
+		builder.vehicleMonitoringUpdateEvents.Lines[xmlVehicleActivity.LineRef()] = lineEvent
+		builder.vehicleMonitoringUpdateEvents.LineRefs[xmlVehicleActivity.LineRef()] = struct{}{}
+	}
+
+	// VehicleJourneys
+	vjObjectId := model.NewObjectID(builder.remoteObjectidKind, xmlVehicleActivity.DatedVehicleJourneyRef())
+
+	_, ok = builder.vehicleMonitoringUpdateEvents.VehicleJourneys[xmlVehicleActivity.DatedVehicleJourneyRef()]
+	if !ok {
+		vjEvent := &model.VehicleJourneyUpdateEvent{
+			Origin:          origin,
+			ObjectId:        vjObjectId,
+			LineObjectId:    lineObjectId,
+			OriginRef:       xmlVehicleActivity.OriginRef(),
+			OriginName:      xmlVehicleActivity.OriginName(),
+			DestinationRef:  xmlVehicleActivity.DestinationRef(),
+			DestinationName: xmlVehicleActivity.DestinationName(),
+			Direction:       xmlVehicleActivity.DirectionName(),
+			Monitored:       xmlVehicleActivity.Monitored(),
+
+			ObjectidKind: builder.remoteObjectidKind,
+		}
+
+		builder.vehicleMonitoringUpdateEvents.VehicleJourneys[xmlVehicleActivity.DatedVehicleJourneyRef()] = vjEvent
+	}
+
+	// Vehicles
+	_, ok = builder.vehicleMonitoringUpdateEvents.Vehicles[vehicleRef]
+	if !ok {
+		vEvent := &model.VehicleUpdateEvent{
+			Origin:                 origin,
+			ObjectId:               model.NewObjectID(builder.vehicleRemoteObjectidKind, vehicleRef),
+			VehicleJourneyObjectId: vjObjectId,
+			Longitude:              xmlVehicleActivity.Longitude(),
+			Latitude:               xmlVehicleActivity.Latitude(),
+			Bearing:                xmlVehicleActivity.Bearing(),
+			RecordedAt:             xmlVehicleActivity.RecordedAtTime(),
+		}
+
+		builder.vehicleMonitoringUpdateEvents.Vehicles[vehicleRef] = vEvent
+		builder.vehicleMonitoringUpdateEvents.VehicleRefs[vehicleRef] = struct{}{}
+	}
+}
+
+func (builder *VehicleMonitoringUpdateEventBuilder) SetUpdateEvents(activities []*siri.XMLVehicleActivity) {
+	for _, xmlVehicleActivity := range activities {
+		builder.buildUpdateEvents(xmlVehicleActivity)
+	}
+}
+
+func (builder *VehicleMonitoringUpdateEventBuilder) UpdateEvents() VehicleMonitoringUpdateEvents {
+	return *builder.vehicleMonitoringUpdateEvents
+}
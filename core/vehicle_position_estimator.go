@@ -0,0 +1,280 @@
+package core
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/model"
+)
+
+const (
+	// Maximum distance (in meters) between a Vehicle and a StopArea to
+	// consider the Vehicle at stop
+	VEHICLE_AT_STOP_DISTANCE = 50.0
+
+	earthRadius = 6371000.0
+)
+
+// Estimates the progress of a VehicleJourney from the position of its
+// Vehicle.
+//
+// The Vehicle is projected onto the sequence of StopAreas of the
+// VehicleJourney (ordered by PassageOrder). The StopVisits before the
+// Vehicle are departed, the StopVisit where the Vehicle stands is arrived
+// and the expected times of the remaining StopVisits are computed from the
+// delay of the Vehicle against the aimed times.
+//
+// The expected times collected by another partner are kept, unless the
+// partner of the Vehicle positions has a higher collect.priority.
+type VehiclePositionEstimator struct {
+	referential *Referential
+	// Slug of the Partner which provides the Vehicle positions
+	origin string
+}
+
+type estimatedStop struct {
+	stopVisit              *model.StopVisit
+	objectId               model.ObjectID
+	stopAreaObjectId       model.ObjectID
+	vehicleJourneyObjectId model.ObjectID
+	// Position in meters relatively to the Vehicle
+	x, y float64
+}
+
+func NewVehiclePositionEstimator(referential *Referential, origin string) *VehiclePositionEstimator {
+	return &VehiclePositionEstimator{
+		referential: referential,
+		origin:      origin,
+	}
+}
+
+// Broadcasts the StopVisitUpdateEvents estimated from the Vehicle position
+func (estimator *VehiclePositionEstimator) Estimate(vehicleId model.VehicleId) {
+	for _, event := range estimator.UpdateEvents(vehicleId) {
+		estimator.referential.CollectManager().BroadcastUpdateEvent(event)
+	}
+}
+
+func (estimator *VehiclePositionEstimator) UpdateEvents(vehicleId model.VehicleId) (events []*model.StopVisitUpdateEvent) {
+	tx := estimator.referential.NewTransaction()
+	defer tx.Close()
+
+	vehicle, ok := tx.Model().Vehicles().Find(vehicleId)
+	if !ok || vehicle.RecordedAtTime.IsZero() || (vehicle.Latitude == 0 && vehicle.Longitude == 0) {
+		return
+	}
+	vehicleJourney, ok := tx.Model().VehicleJourneys().Find(vehicle.VehicleJourneyId)
+	if !ok {
+		return
+	}
+
+	stopVisits := tx.Model().StopVisits().FindByVehicleJourneyId(vehicleJourney.Id())
+	sort.Slice(stopVisits, func(i, j int) bool {
+		return stopVisits[i].PassageOrder < stopVisits[j].PassageOrder
+	})
+
+	var stops []estimatedStop
+	for i := range stopVisits {
+		stopArea, ok := tx.Model().StopAreas().Find(stopVisits[i].StopAreaId)
+		if !ok || (stopArea.Latitude == 0 && stopArea.Longitude == 0) {
+			continue
+		}
+		stop := estimatedStop{stopVisit: &stopVisits[i]}
+		if !estimator.setObjectIds(&stop, &vehicleJourney, &stopArea) {
+			continue
+		}
+		stop.x, stop.y = project(vehicle.Latitude, vehicle.Longitude, stopArea.Latitude, stopArea.Longitude)
+		stops = append(stops, stop)
+	}
+	if len(stops) < 2 {
+		return
+	}
+
+	// Find the closest segment between two consecutive stops
+	segment, fraction, distance := 0, 0.0, math.Inf(1)
+	for i := 0; i < len(stops)-1; i++ {
+		f, d := projectOnSegment(&stops[i], &stops[i+1])
+		if d < distance {
+			segment, fraction, distance = i, f, d
+		}
+	}
+
+	atStop := -1
+	switch {
+	case math.Hypot(stops[segment].x, stops[segment].y) <= VEHICLE_AT_STOP_DISTANCE:
+		atStop = segment
+	case math.Hypot(stops[segment+1].x, stops[segment+1].y) <= VEHICLE_AT_STOP_DISTANCE:
+		atStop = segment + 1
+	}
+
+	// Delay of the Vehicle against the aimed times
+	var aimedTime time.Time
+	if atStop != -1 {
+		aimedTime = aimedArrivalOrDeparture(stops[atStop].stopVisit)
+	} else {
+		departure := aimedDepartureOrArrival(stops[segment].stopVisit)
+		arrival := aimedArrivalOrDeparture(stops[segment+1].stopVisit)
+		if !departure.IsZero() && !arrival.IsZero() {
+			aimedTime = departure.Add(time.Duration(fraction * float64(arrival.Sub(departure))))
+		}
+	}
+	delay, hasDelay := vehicle.RecordedAtTime.Sub(aimedTime), !aimedTime.IsZero()
+
+	estimated := false
+	for i := range stops {
+		stopVisit := stops[i].stopVisit
+		event := estimator.newUpdateEvent(&stops[i], vehicleJourney.Monitored, vehicle.RecordedAtTime)
+		aimed := stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_AIMED)
+
+		switch {
+		case i == atStop:
+			event.VehicleAtStop = true
+			event.ArrivalStatus = model.STOP_VISIT_ARRIVAL_ARRIVED
+			if stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_ACTUAL).ArrivalTime().IsZero() {
+				event.Schedules.SetArrivalTime(model.STOP_VISIT_SCHEDULE_ACTUAL, vehicle.RecordedAtTime)
+				estimated = true
+			}
+			// A Vehicle at stop isn't expected to leave before the aimed departure time
+			if hasDelay && !aimed.DepartureTime().IsZero() {
+				event.Schedules.SetDepartureTime(model.STOP_VISIT_SCHEDULE_EXPECTED, aimed.DepartureTime().Add(maxDuration(delay, 0)))
+				estimated = true
+			}
+		case i < atStop || (atStop == -1 && i <= segment):
+			if stopVisit.DepartureStatus == model.STOP_VISIT_DEPARTURE_DEPARTED && !stopVisit.VehicleAtStop {
+				continue
+			}
+			event.ArrivalStatus = model.STOP_VISIT_ARRIVAL_ARRIVED
+			event.DepartureStatus = model.STOP_VISIT_DEPARTURE_DEPARTED
+		default:
+			// Without delay, the event wouldn't change the StopVisit
+			if !hasDelay || estimator.keepsExpectedTimes(stopVisit) {
+				continue
+			}
+			var arrivalTime, departureTime time.Time
+			if !aimed.ArrivalTime().IsZero() {
+				arrivalTime = aimed.ArrivalTime().Add(delay)
+			}
+			if !aimed.DepartureTime().IsZero() {
+				departureTime = aimed.DepartureTime().Add(delay)
+			}
+			event.Schedules.SetSchedule(model.STOP_VISIT_SCHEDULE_EXPECTED, departureTime, arrivalTime)
+			estimated = true
+		}
+
+		events = append(events, event)
+	}
+
+	// All the events update the same VehicleJourney
+	if estimated {
+		for _, event := range events {
+			event.Monitored = true
+		}
+	}
+
+	return
+}
+
+// Returns true when the expected times of the StopVisit have been collected
+// by another partner with the same or a higher collect.priority
+func (estimator *VehiclePositionEstimator) keepsExpectedTimes(stopVisit *model.StopVisit) bool {
+	if stopVisit.Origin == estimator.origin || stopVisit.Origin == "" {
+		return false
+	}
+	expected := stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_EXPECTED)
+	if expected.ArrivalTime().IsZero() && expected.DepartureTime().IsZero() {
+		return false
+	}
+	return estimator.collectPriority(stopVisit.Origin) >= estimator.collectPriority(estimator.origin)
+}
+
+func (estimator *VehiclePositionEstimator) collectPriority(origin string) int {
+	partner, ok := estimator.referential.Partners().FindBySlug(PartnerSlug(origin))
+	if !ok {
+		return 0
+	}
+	return partner.CollectPriority()
+}
+
+// Uses an ObjectID kind shared by the StopVisit, its StopArea and its
+// VehicleJourney
+func (estimator *VehiclePositionEstimator) setObjectIds(stop *estimatedStop, vehicleJourney *model.VehicleJourney, stopArea *model.StopArea) bool {
+	for kind, objectid := range stop.stopVisit.ObjectIDs() {
+		stopAreaObjectId, ok := stopArea.ObjectID(kind)
+		if !ok {
+			continue
+		}
+		vehicleJourneyObjectId, ok := vehicleJourney.ObjectID(kind)
+		if !ok {
+			continue
+		}
+		stop.objectId = objectid
+		stop.stopAreaObjectId = stopAreaObjectId
+		stop.vehicleJourneyObjectId = vehicleJourneyObjectId
+		return true
+	}
+	return false
+}
+
+// StopVisits have no Monitored status of their own: the events update the
+// Monitored status of the VehicleJourney. The events are monitored when a
+// time is estimated for one of the StopVisits, otherwise the Monitored status
+// of the VehicleJourney is kept
+func (estimator *VehiclePositionEstimator) newUpdateEvent(stop *estimatedStop, monitored bool, recordedAt time.Time) *model.StopVisitUpdateEvent {
+	stopVisit := stop.stopVisit
+
+	event := model.NewStopVisitUpdateEvent()
+	event.Origin = estimator.origin
+	event.ObjectId = stop.objectId
+	event.StopAreaObjectId = stop.stopAreaObjectId
+	event.VehicleJourneyObjectId = stop.vehicleJourneyObjectId
+	event.DataFrameRef = stopVisit.DataFrameRef
+	event.PassageOrder = stopVisit.PassageOrder
+	event.Monitored = monitored
+	event.VehicleAtStop = false
+	event.DepartureStatus = stopVisit.DepartureStatus
+	event.ArrivalStatus = stopVisit.ArrivalStatus
+	event.RecordedAt = recordedAt
+
+	return event
+}
+
+// Returns the fraction of the segment before the projection of the Vehicle
+// and the distance between the Vehicle and the segment
+func projectOnSegment(from, to *estimatedStop) (fraction, distance float64) {
+	dx, dy := to.x-from.x, to.y-from.y
+	if length := dx*dx + dy*dy; length != 0 {
+		fraction = math.Max(0, math.Min(1, -(from.x*dx+from.y*dy)/length))
+	}
+	return fraction, math.Hypot(from.x+fraction*dx, from.y+fraction*dy)
+}
+
+// Equirectangular projection of the point in meters around the origin
+func project(originLatitude, originLongitude, latitude, longitude float64) (x, y float64) {
+	x = (longitude - originLongitude) * math.Pi / 180 * math.Cos(originLatitude*math.Pi/180) * earthRadius
+	y = (latitude - originLatitude) * math.Pi / 180 * earthRadius
+	return
+}
+
+func aimedArrivalOrDeparture(stopVisit *model.StopVisit) time.Time {
+	aimed := stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_AIMED)
+	if !aimed.ArrivalTime().IsZero() {
+		return aimed.ArrivalTime()
+	}
+	return aimed.DepartureTime()
+}
+
+func aimedDepartureOrArrival(stopVisit *model.StopVisit) time.Time {
+	aimed := stopVisit.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_AIMED)
+	if !aimed.DepartureTime().IsZero() {
+		return aimed.DepartureTime()
+	}
+	return aimed.ArrivalTime()
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,234 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/model"
+)
+
+func Test_VehiclePositionEstimator_UpdateEvents(t *testing.T) {
+	aimedTime := time.Date(2017, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	var tests = []struct {
+		latitude          float64
+		recordedAt        time.Time
+		withoutAimedTimes bool
+		vehicleAtStop     []bool
+		monitored         []bool
+		departed          []bool
+		actualArrival     []time.Time
+		expectedArrival   []time.Time
+		expectedDeparture []time.Time
+	}{
+		{
+			// Between the first and the second stops, 3 minutes late
+			latitude:          48.005,
+			recordedAt:        aimedTime.Add(8 * time.Minute),
+			vehicleAtStop:     []bool{false, false, false},
+			monitored:         []bool{true, true, true},
+			departed:          []bool{true, false, false},
+			actualArrival:     []time.Time{{}, {}, {}},
+			expectedArrival:   []time.Time{{}, aimedTime.Add(13 * time.Minute), aimedTime.Add(23 * time.Minute)},
+			expectedDeparture: []time.Time{{}, aimedTime.Add(14 * time.Minute), {}},
+		},
+		{
+			// At the second stop, 2 minutes late
+			latitude:          48.0101,
+			recordedAt:        aimedTime.Add(12 * time.Minute),
+			vehicleAtStop:     []bool{false, true, false},
+			monitored:         []bool{true, true, true},
+			departed:          []bool{true, false, false},
+			actualArrival:     []time.Time{{}, aimedTime.Add(12 * time.Minute), {}},
+			expectedArrival:   []time.Time{{}, {}, aimedTime.Add(22 * time.Minute)},
+			expectedDeparture: []time.Time{{}, aimedTime.Add(13 * time.Minute), {}},
+		},
+		{
+			// At the second stop, without aimed times to compute the delay
+			latitude:          48.0101,
+			recordedAt:        aimedTime.Add(12 * time.Minute),
+			withoutAimedTimes: true,
+			vehicleAtStop:     []bool{false, true},
+			monitored:         []bool{true, true},
+			departed:          []bool{true, false},
+			actualArrival:     []time.Time{{}, aimedTime.Add(12 * time.Minute)},
+			expectedArrival:   []time.Time{{}, {}},
+			expectedDeparture: []time.Time{{}, {}},
+		},
+		{
+			// Between the first and the second stops, without aimed times
+			// nothing is estimated and the Monitored value is kept
+			latitude:          48.005,
+			recordedAt:        aimedTime.Add(8 * time.Minute),
+			withoutAimedTimes: true,
+			vehicleAtStop:     []bool{false},
+			monitored:         []bool{false},
+			departed:          []bool{true},
+			actualArrival:     []time.Time{{}},
+			expectedArrival:   []time.Time{{}},
+			expectedDeparture: []time.Time{{}},
+		},
+	}
+
+	for i, tt := range tests {
+		referential := NewMemoryReferentials().New("test")
+
+		vehicleJourney := referential.Model().VehicleJourneys().New()
+		vehicleJourney.SetObjectID(model.NewObjectID("kind", "vehicleJourney"))
+		vehicleJourney.Save()
+
+		for j := 0; j < 3; j++ {
+			stopArea := referential.Model().StopAreas().New()
+			stopArea.SetObjectID(model.NewObjectID("kind", "stopArea"+string(rune('1'+j))))
+			stopArea.Latitude = 48 + float64(j)*0.01
+			stopArea.Longitude = 2
+			stopArea.Save()
+
+			stopVisit := referential.Model().StopVisits().New()
+			stopVisit.SetObjectID(model.NewObjectID("kind", "stopVisit"+string(rune('1'+j))))
+			stopVisit.StopAreaId = stopArea.Id()
+			stopVisit.VehicleJourneyId = vehicleJourney.Id()
+			stopVisit.PassageOrder = j + 1
+			var departureTime, arrivalTime time.Time
+			if j != 0 {
+				arrivalTime = aimedTime.Add(time.Duration(j) * 10 * time.Minute)
+			}
+			if j != 2 {
+				departureTime = aimedTime.Add(time.Duration(j) * 11 * time.Minute)
+			}
+			if !tt.withoutAimedTimes {
+				stopVisit.Schedules.SetSchedule(model.STOP_VISIT_SCHEDULE_AIMED, departureTime, arrivalTime)
+			}
+			stopVisit.Save()
+		}
+
+		vehicle := referential.Model().Vehicles().New()
+		vehicle.VehicleJourneyId = vehicleJourney.Id()
+		vehicle.Latitude = tt.latitude
+		vehicle.Longitude = 2
+		vehicle.RecordedAtTime = tt.recordedAt
+		vehicle.Save()
+
+		events := NewVehiclePositionEstimator(referential, "partner").UpdateEvents(vehicle.Id())
+		if len(events) != len(tt.vehicleAtStop) {
+			t.Fatalf("Test %d: Wrong number of events:\n got: %v\n want: %v", i, len(events), len(tt.vehicleAtStop))
+		}
+
+		for j, event := range events {
+			if r := "stopVisit" + string(rune('1'+j)); event.ObjectId.Value() != r {
+				t.Errorf("Test %d: Wrong event ObjectId:\n got: %v\n want: %v", i, event.ObjectId.Value(), r)
+			}
+			if event.Origin != "partner" {
+				t.Errorf("Test %d: Wrong Origin for event %d:\n got: %v\n want: partner", i, j, event.Origin)
+			}
+			if event.Monitored != tt.monitored[j] {
+				t.Errorf("Test %d: Wrong Monitored for event %d:\n got: %v\n want: %v", i, j, event.Monitored, tt.monitored[j])
+			}
+			if event.VehicleAtStop != tt.vehicleAtStop[j] {
+				t.Errorf("Test %d: Wrong VehicleAtStop for event %d:\n got: %v\n want: %v", i, j, event.VehicleAtStop, tt.vehicleAtStop[j])
+			}
+			if departed := event.DepartureStatus == model.STOP_VISIT_DEPARTURE_DEPARTED; departed != tt.departed[j] {
+				t.Errorf("Test %d: Wrong DepartureStatus for event %d: %v", i, j, event.DepartureStatus)
+			}
+			if tt.vehicleAtStop[j] || tt.departed[j] {
+				if event.ArrivalStatus != model.STOP_VISIT_ARRIVAL_ARRIVED {
+					t.Errorf("Test %d: Wrong ArrivalStatus for event %d: %v", i, j, event.ArrivalStatus)
+				}
+			}
+
+			actual := event.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_ACTUAL)
+			if !actual.ArrivalTime().Equal(tt.actualArrival[j]) {
+				t.Errorf("Test %d: Wrong actual arrival time for event %d:\n got: %v\n want: %v", i, j, actual.ArrivalTime(), tt.actualArrival[j])
+			}
+			expected := event.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_EXPECTED)
+			if !expected.ArrivalTime().Equal(tt.expectedArrival[j]) {
+				t.Errorf("Test %d: Wrong expected arrival time for event %d:\n got: %v\n want: %v", i, j, expected.ArrivalTime(), tt.expectedArrival[j])
+			}
+			if !expected.DepartureTime().Equal(tt.expectedDeparture[j]) {
+				t.Errorf("Test %d: Wrong expected departure time for event %d:\n got: %v\n want: %v", i, j, expected.DepartureTime(), tt.expectedDeparture[j])
+			}
+		}
+	}
+}
+
+func Test_VehiclePositionEstimator_UpdateEvents_CollectedExpectedTimes(t *testing.T) {
+	aimedTime := time.Date(2017, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	var tests = []struct {
+		otherPriority string
+		estimated     bool
+	}{
+		{otherPriority: "1", estimated: true},
+		{otherPriority: "2", estimated: false},
+		{otherPriority: "3", estimated: false},
+	}
+
+	for i, tt := range tests {
+		referential := NewMemoryReferentials().New("test")
+
+		partner := referential.Partners().New("partner")
+		partner.SetSetting(COLLECT_PRIORITY, "2")
+		referential.Partners().Save(partner)
+		other := referential.Partners().New("other")
+		other.SetSetting(COLLECT_PRIORITY, tt.otherPriority)
+		referential.Partners().Save(other)
+
+		vehicleJourney := referential.Model().VehicleJourneys().New()
+		vehicleJourney.SetObjectID(model.NewObjectID("kind", "vehicleJourney"))
+		vehicleJourney.Save()
+
+		for j := 0; j < 2; j++ {
+			stopArea := referential.Model().StopAreas().New()
+			stopArea.SetObjectID(model.NewObjectID("kind", "stopArea"+string(rune('1'+j))))
+			stopArea.Latitude = 48 + float64(j)*0.01
+			stopArea.Longitude = 2
+			stopArea.Save()
+
+			stopVisit := referential.Model().StopVisits().New()
+			stopVisit.SetObjectID(model.NewObjectID("kind", "stopVisit"+string(rune('1'+j))))
+			stopVisit.StopAreaId = stopArea.Id()
+			stopVisit.VehicleJourneyId = vehicleJourney.Id()
+			stopVisit.PassageOrder = j + 1
+			stopVisit.Origin = "other"
+			stopVisit.Schedules.SetDepartureTime(model.STOP_VISIT_SCHEDULE_AIMED, aimedTime.Add(time.Duration(j)*10*time.Minute))
+			stopVisit.Schedules.SetDepartureTime(model.STOP_VISIT_SCHEDULE_EXPECTED, aimedTime.Add(time.Duration(j)*10*time.Minute))
+			stopVisit.Save()
+		}
+
+		// Between the two stops, 3 minutes late
+		vehicle := referential.Model().Vehicles().New()
+		vehicle.VehicleJourneyId = vehicleJourney.Id()
+		vehicle.Latitude = 48.005
+		vehicle.Longitude = 2
+		vehicle.RecordedAtTime = aimedTime.Add(8 * time.Minute)
+		vehicle.Save()
+
+		events := NewVehiclePositionEstimator(referential, "partner").UpdateEvents(vehicle.Id())
+		estimated := false
+		for _, event := range events {
+			if !event.Schedules.Schedule(model.STOP_VISIT_SCHEDULE_EXPECTED).DepartureTime().IsZero() {
+				estimated = true
+			}
+		}
+		if estimated != tt.estimated {
+			t.Errorf("Test %d: Wrong estimation of the expected times collected by a partner with collect.priority %v:\n got: %v\n want: %v", i, tt.otherPriority, estimated, tt.estimated)
+		}
+	}
+}
+
+func Test_VehiclePositionEstimator_Estimate(t *testing.T) {
+	referential := NewMemoryReferentials().New("test")
+	collectManager := NewTestCollectManager().(*TestCollectManager)
+	referential.collectManager = collectManager
+
+	vehicle := referential.Model().Vehicles().New()
+	vehicle.Latitude = 48
+	vehicle.Longitude = 2
+	vehicle.RecordedAtTime = time.Date(2017, time.January, 1, 12, 0, 0, 0, time.UTC)
+	vehicle.Save()
+
+	NewVehiclePositionEstimator(referential, "partner").Estimate(vehicle.Id())
+	if len(collectManager.UpdateEvents) != 0 {
+		t.Errorf("No event should be broadcasted for a Vehicle without VehicleJourney, got %v", collectManager.UpdateEvents)
+	}
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/clock"
+	"bitbucket.org/enroute-mobi/ara/model"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+type VehicleUpdateRequestId string
+
+type VehicleUpdateRequest struct {
+	id        VehicleUpdateRequestId
+	lineId    model.LineId
+	createdAt time.Time
+}
+
+func NewVehicleUpdateRequest(lineId model.LineId) *VehicleUpdateRequest {
+	return &VehicleUpdateRequest{
+		id:        VehicleUpdateRequestId(uuid.DefaultUUIDGenerator().NewUUID()),
+		lineId:    lineId,
+		createdAt: clock.DefaultClock().Now(),
+	}
+}
+
+func (vehicleUpdateRequest *VehicleUpdateRequest) Id() VehicleUpdateRequestId {
+	return vehicleUpdateRequest.id
+}
+
+func (vehicleUpdateRequest *VehicleUpdateRequest) LineId() model.LineId {
+	return vehicleUpdateRequest.lineId
+}
+
+func (vehicleUpdateRequest *VehicleUpdateRequest) CreatedAt() time.Time {
+	return vehicleUpdateRequest.createdAt
+}
@@ -0,0 +1,18 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE subscriptions (
+    id              text NOT NULL,
+    referential_id  uuid NOT NULL,
+    partner_id      uuid NOT NULL,
+    kind            text,
+    external_id     text,
+    resources       text,
+    options         text,
+    service_started_at timestamp
+);
+
+ALTER TABLE ONLY subscriptions ADD CONSTRAINT subscriptions_pkey PRIMARY KEY (partner_id, id);
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS subscriptions;
@@ -36,6 +36,7 @@ func InitDB(config config.DatabaseConfig) *gorp.DbMap {
 	database := &gorp.DbMap{Db: db, Dialect: gorp.PostgresDialect{}}
 	database.AddTableWithName(DatabaseReferential{}, "referentials")
 	database.AddTableWithName(DatabasePartner{}, "partners")
+	database.AddTableWithName(DatabaseSubscription{}, "subscriptions")
 
 	return database
 }
@@ -55,7 +56,7 @@ func InitTestDb(t *testing.T) {
 	// Initialize Database
 	Database = InitDB(config.Config.DB)
 
-	_, err = Database.Exec("TRUNCATE referentials, partners, lines, operators, stop_areas, stop_visits, vehicle_journeys;")
+	_, err = Database.Exec("TRUNCATE referentials, partners, subscriptions, lines, operators, stop_areas, stop_visits, vehicle_journeys;")
 	if err != nil {
 		t.Fatal(err)
 	}
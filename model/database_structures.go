@@ -1,6 +1,9 @@
 package model
 
-import "database/sql"
+import (
+	"database/sql"
+	"time"
+)
 
 type DatabaseReferential struct {
 	ReferentialId  string         `db:"referential_id"`
@@ -38,6 +41,29 @@ type SelectPartner struct {
 	ConnectorTypes sql.NullString `db:"connector_types"`
 }
 
+type DatabaseSubscription struct {
+	Id            string `db:"id"`
+	ReferentialId string `db:"referential_id"`
+	PartnerId     string `db:"partner_id"`
+	Kind          string `db:"kind"`
+	ExternalId    string `db:"external_id"`
+	Resources     string `db:"resources"`
+	Options       string `db:"options"`
+	// ServiceStartedAt of the remote partner when the subscription was saved
+	ServiceStartedAt *time.Time `db:"service_started_at"`
+}
+
+type SelectSubscription struct {
+	Id               string
+	ReferentialId    string `db:"referential_id"`
+	PartnerId        string `db:"partner_id"`
+	Kind             sql.NullString
+	ExternalId       sql.NullString `db:"external_id"`
+	Resources        sql.NullString
+	Options          sql.NullString
+	ServiceStartedAt *time.Time `db:"service_started_at"`
+}
+
 type DatabaseOperator struct {
 	Id              string `db:"id"`
 	ReferentialSlug string `db:"referential_slug"`
@@ -87,14 +113,15 @@ type SelectStopArea struct {
 }
 
 type DatabaseLine struct {
-	Id                     string `db:"id"`
-	ReferentialSlug        string `db:"referential_slug"`
-	ModelName              string `db:"model_name"`
-	Name                   string `db:"name"`
-	ObjectIDs              string `db:"object_ids"`
-	Attributes             string `db:"attributes"`
-	References             string `db:"siri_references"`
-	CollectGeneralMessages bool   `db:"collect_general_messages"`
+	Id                     string         `db:"id"`
+	ReferentialSlug        string         `db:"referential_slug"`
+	ReferentId             sql.NullString `db:"referent_id"`
+	ModelName              string         `db:"model_name"`
+	Name                   string         `db:"name"`
+	ObjectIDs              string         `db:"object_ids"`
+	Attributes             string         `db:"attributes"`
+	References             string         `db:"siri_references"`
+	CollectGeneralMessages bool           `db:"collect_general_messages"`
 }
 
 type SelectLine struct {
@@ -102,6 +129,7 @@ type SelectLine struct {
 	ReferentialSlug        string `db:"referential_slug"`
 	ModelName              string `db:"model_name"`
 	Name                   sql.NullString
+	ReferentId             sql.NullString `db:"referent_id"`
 	ObjectIDs              sql.NullString `db:"object_ids"`
 	Attributes             sql.NullString
 	References             sql.NullString `db:"siri_references"`
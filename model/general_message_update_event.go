@@ -22,6 +22,14 @@ type SituationAttributes struct {
 	LineSections []*References
 	Messages     []*Message
 	ValidUntil   time.Time
+
+	Summary            string
+	Description        string
+	Reason             string
+	Severity           string
+	ValidityPeriods    []*TimeRange
+	PublicationWindows []*TimeRange
+	Affects            []*Reference
 }
 
 func (event *SituationUpdateEvent) Id() SituationUpdateRequestId {
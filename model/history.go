@@ -0,0 +1,187 @@
+package model
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_HISTORY_MAX_ENTRIES           = 100000
+	DEFAULT_HISTORY_MAX_ENTRIES_BY_OBJECT = 100
+)
+
+// State of a StopVisit or a VehicleJourney after an update event
+type HistoryEntry struct {
+	Origin     string `json:",omitempty"`
+	RecordedAt time.Time
+
+	StopVisit      *StopVisit      `json:",omitempty"`
+	VehicleJourney *VehicleJourney `json:",omitempty"`
+}
+
+// Keeps the states of the model objects after each update event, ordered by
+// RecordedAt. The history is bounded: when maxEntries is reached, the oldest
+// entries are dropped, whatever the object.
+//
+// The History belongs to the Model and is lost when the Model is reloaded.
+type History struct {
+	mutex *sync.RWMutex
+
+	maxEntries         int
+	maxEntriesByObject int
+
+	entries map[ModelId][]*HistoryEntry
+	size    int
+	// Recording order, may contain entries already dropped because of
+	// maxEntriesByObject
+	order []*historyRecord
+}
+
+type historyRecord struct {
+	id    ModelId
+	entry *HistoryEntry
+}
+
+func NewHistory(maxEntries, maxEntriesByObject int) *History {
+	return &History{
+		mutex:              &sync.RWMutex{},
+		maxEntries:         maxEntries,
+		maxEntriesByObject: maxEntriesByObject,
+		entries:            make(map[ModelId][]*HistoryEntry),
+	}
+}
+
+// Changes the bounds of the History, the exceeding entries are dropped
+func (history *History) SetLimits(maxEntries, maxEntriesByObject int) {
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+
+	history.maxEntries = maxEntries
+	history.maxEntriesByObject = maxEntriesByObject
+
+	for id, entries := range history.entries {
+		if len(entries) > maxEntriesByObject {
+			history.size -= len(entries) - maxEntriesByObject
+			history.entries[id] = entries[len(entries)-maxEntriesByObject:]
+		}
+	}
+	history.compact()
+
+	for history.size > history.maxEntries {
+		history.dropOldest()
+	}
+}
+
+func (history *History) RecordStopVisit(stopVisit *StopVisit, origin string, recordedAt time.Time) {
+	history.record(ModelId(stopVisit.Id()), &HistoryEntry{
+		Origin:     origin,
+		RecordedAt: recordedAt,
+		StopVisit:  stopVisit.copy(),
+	})
+}
+
+func (history *History) RecordVehicleJourney(vehicleJourney *VehicleJourney, origin string, recordedAt time.Time) {
+	history.record(ModelId(vehicleJourney.Id()), &HistoryEntry{
+		Origin:         origin,
+		RecordedAt:     recordedAt,
+		VehicleJourney: vehicleJourney.copy(),
+	})
+}
+
+func (history *History) record(id ModelId, entry *HistoryEntry) {
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+
+	entries := history.entries[id]
+	if len(entries) >= history.maxEntriesByObject {
+		entries = entries[1:]
+		history.size--
+	}
+	history.entries[id] = append(entries, entry)
+	history.size++
+	history.order = append(history.order, &historyRecord{id: id, entry: entry})
+
+	for history.size > history.maxEntries {
+		history.dropOldest()
+	}
+	if len(history.order) > 2*history.maxEntries {
+		history.compact()
+	}
+}
+
+func (history *History) dropOldest() {
+	record := history.order[0]
+	history.order = history.order[1:]
+
+	entries := history.entries[record.id]
+	if len(entries) == 0 || entries[0] != record.entry {
+		return
+	}
+	history.size--
+	if len(entries) == 1 {
+		delete(history.entries, record.id)
+		return
+	}
+	history.entries[record.id] = entries[1:]
+}
+
+// Removes from the order the entries already dropped
+func (history *History) compact() {
+	kept := make([]*historyRecord, 0, history.size)
+	for _, record := range history.order {
+		for _, entry := range history.entries[record.id] {
+			if entry == record.entry {
+				kept = append(kept, record)
+				break
+			}
+		}
+	}
+	history.order = kept
+}
+
+func (history *History) StopVisit(id StopVisitId) []*HistoryEntry {
+	return history.find(ModelId(id))
+}
+
+func (history *History) VehicleJourney(id VehicleJourneyId) []*HistoryEntry {
+	return history.find(ModelId(id))
+}
+
+func (history *History) find(id ModelId) []*HistoryEntry {
+	history.mutex.RLock()
+	defer history.mutex.RUnlock()
+
+	entries := make([]*HistoryEntry, len(history.entries[id]))
+	copy(entries, history.entries[id])
+	return entries
+}
+
+// Returns the state of each StopVisit at the given time. The given current
+// StopVisits without history entry haven't changed since the Model was
+// loaded and are returned as they are. StopVisits recorded only after this
+// time are ignored.
+func (history *History) StopVisitsAt(t time.Time, current []StopVisit) (stopVisits []StopVisit) {
+	history.mutex.RLock()
+	defer history.mutex.RUnlock()
+
+	for i := range current {
+		if _, ok := history.entries[ModelId(current[i].Id())]; !ok {
+			stopVisits = append(stopVisits, *(current[i].copy()))
+		}
+	}
+
+	for _, entries := range history.entries {
+		var found *HistoryEntry
+		for _, entry := range entries {
+			if entry.RecordedAt.After(t) {
+				break
+			}
+			found = entry
+		}
+		if found == nil || found.StopVisit == nil {
+			continue
+		}
+		stopVisits = append(stopVisits, *(found.StopVisit.copy()))
+	}
+	return
+}
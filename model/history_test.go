@@ -0,0 +1,133 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/clock"
+)
+
+func Test_UpdateManager_History(t *testing.T) {
+	model := NewMemoryModel()
+	objectid := NewObjectID("kind", "value")
+	sa := model.StopAreas().New()
+	sa.SetObjectID(objectid)
+	sa.Save()
+
+	l := model.Lines().New()
+	l.SetObjectID(objectid)
+	l.Save()
+
+	fakeClock := clock.NewFakeClock()
+	manager := newUpdateManager(model)
+	manager.SetClock(fakeClock)
+
+	manager.Update(&VehicleJourneyUpdateEvent{
+		ObjectId:     objectid,
+		LineObjectId: objectid,
+		Origin:       "partner",
+	})
+	vj, _ := model.VehicleJourneys().FindByObjectId(objectid)
+	if entries := model.History().VehicleJourney(vj.Id()); len(entries) != 1 || entries[0].Origin != "partner" {
+		t.Errorf("VehicleJourney update should be recorded, got %v", entries)
+	}
+
+	event := &StopVisitUpdateEvent{
+		ObjectId:               objectid,
+		StopAreaObjectId:       objectid,
+		VehicleJourneyObjectId: objectid,
+		Origin:                 "partner",
+		ArrivalStatus:          STOP_VISIT_ARRIVAL_ONTIME,
+	}
+	manager.Update(event)
+	firstUpdate := fakeClock.Now()
+
+	fakeClock.Advance(time.Minute)
+	event.ArrivalStatus = STOP_VISIT_ARRIVAL_DELAYED
+	manager.Update(event)
+
+	sv, _ := model.StopVisits().FindByObjectId(objectid)
+	entries := model.History().StopVisit(sv.Id())
+	if len(entries) != 2 {
+		t.Fatalf("StopVisit should have 2 history entries, got %v", len(entries))
+	}
+	if !entries[0].RecordedAt.Equal(firstUpdate) || entries[0].StopVisit.ArrivalStatus != STOP_VISIT_ARRIVAL_ONTIME {
+		t.Errorf("Wrong first history entry: %v", entries[0])
+	}
+	if entries[1].StopVisit.ArrivalStatus != STOP_VISIT_ARRIVAL_DELAYED {
+		t.Errorf("Wrong second history entry: %v", entries[1])
+	}
+
+	if stopVisits := model.History().StopVisitsAt(firstUpdate.Add(-time.Second), model.StopVisits().FindAll()); len(stopVisits) != 0 {
+		t.Errorf("No StopVisit should exist before the first update, got %v", len(stopVisits))
+	}
+
+	// StopVisit unchanged since the Model was loaded
+	unchanged := model.StopVisits().New()
+	unchanged.Save()
+	stopVisits := model.History().StopVisitsAt(firstUpdate.Add(-time.Second), model.StopVisits().FindAll())
+	if len(stopVisits) != 1 || stopVisits[0].Id() != unchanged.Id() {
+		t.Errorf("StopVisit without history should be returned, got %v", stopVisits)
+	}
+	stopVisits = model.History().StopVisitsAt(firstUpdate.Add(30*time.Second), nil)
+	if len(stopVisits) != 1 || stopVisits[0].ArrivalStatus != STOP_VISIT_ARRIVAL_ONTIME {
+		t.Errorf("Wrong StopVisits after the first update: %v", stopVisits)
+	}
+	stopVisits = model.History().StopVisitsAt(fakeClock.Now(), nil)
+	if len(stopVisits) != 1 || stopVisits[0].ArrivalStatus != STOP_VISIT_ARRIVAL_DELAYED {
+		t.Errorf("Wrong StopVisits after the second update: %v", stopVisits)
+	}
+}
+
+func Test_History_Bounds(t *testing.T) {
+	history := NewHistory(3, 2)
+	model := NewMemoryModel()
+
+	stopVisit := model.StopVisits().New()
+	stopVisit.Save()
+	otherStopVisit := model.StopVisits().New()
+	otherStopVisit.Save()
+
+	recordedAt := time.Date(2017, time.January, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		history.RecordStopVisit(&stopVisit, "", recordedAt.Add(time.Duration(i)*time.Minute))
+	}
+	entries := history.StopVisit(stopVisit.Id())
+	if len(entries) != 2 || !entries[0].RecordedAt.Equal(recordedAt.Add(time.Minute)) {
+		t.Errorf("History should keep the 2 last entries by object, got %v", entries)
+	}
+
+	history.RecordStopVisit(&otherStopVisit, "", recordedAt.Add(3*time.Minute))
+	history.RecordStopVisit(&otherStopVisit, "", recordedAt.Add(4*time.Minute))
+	if entries := history.StopVisit(stopVisit.Id()); len(entries) != 1 || !entries[0].RecordedAt.Equal(recordedAt.Add(2*time.Minute)) {
+		t.Errorf("History should drop the oldest entries, got %v", entries)
+	}
+	if entries := history.StopVisit(otherStopVisit.Id()); len(entries) != 2 {
+		t.Errorf("History should keep the last entries, got %v", entries)
+	}
+}
+
+func Test_History_SetLimits(t *testing.T) {
+	history := NewHistory(10, 10)
+	model := NewMemoryModel()
+
+	stopVisit := model.StopVisits().New()
+	stopVisit.Save()
+	otherStopVisit := model.StopVisits().New()
+	otherStopVisit.Save()
+
+	recordedAt := time.Date(2017, time.January, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		history.RecordStopVisit(&stopVisit, "", recordedAt.Add(time.Duration(i)*time.Minute))
+	}
+	history.RecordStopVisit(&otherStopVisit, "", recordedAt.Add(3*time.Minute))
+
+	history.SetLimits(2, 2)
+
+	if entries := history.StopVisit(stopVisit.Id()); len(entries) != 1 || !entries[0].RecordedAt.Equal(recordedAt.Add(2*time.Minute)) {
+		t.Errorf("History should keep the last entries in the new bounds, got %v", entries)
+	}
+	if entries := history.StopVisit(otherStopVisit.Id()); len(entries) != 1 {
+		t.Errorf("History should keep the last entries in the new bounds, got %v", entries)
+	}
+}
@@ -16,7 +16,8 @@ type Line struct {
 	model  Model
 	origin string
 
-	id LineId
+	id         LineId
+	ReferentId LineId `json:",omitempty"`
 
 	CollectGeneralMessages bool
 	nextCollectAt          time.Time
@@ -161,6 +162,7 @@ type MemoryLines struct {
 	mutex        *sync.RWMutex
 	byIdentifier map[LineId]*Line
 	byObjectId   *ObjectIdIndex
+	byReferent   *Index
 }
 
 type Lines interface {
@@ -169,16 +171,20 @@ type Lines interface {
 	New() Line
 	Find(id LineId) (Line, bool)
 	FindByObjectId(objectid ObjectID) (Line, bool)
+	FindByReferentId(id LineId) []Line
 	FindAll() []Line
 	Save(line *Line) bool
 	Delete(line *Line) bool
 }
 
 func NewMemoryLines() *MemoryLines {
+	extractor := func(instance ModelInstance) ModelId { return ModelId((instance.(*Line)).ReferentId) }
+
 	return &MemoryLines{
 		mutex:        &sync.RWMutex{},
 		byIdentifier: make(map[LineId]*Line),
 		byObjectId:   NewObjectIdIndex(),
+		byReferent:   NewIndex(extractor),
 	}
 }
 
@@ -224,6 +230,18 @@ func (manager *MemoryLines) FindByObjectId(objectid ObjectID) (Line, bool) {
 	return Line{}, false
 }
 
+func (manager *MemoryLines) FindByReferentId(id LineId) (lines []Line) {
+	manager.mutex.RLock()
+
+	ids, _ := manager.byReferent.Find(ModelId(id))
+	for _, referringId := range ids {
+		lines = append(lines, *(manager.byIdentifier[LineId(referringId)].copy()))
+	}
+
+	manager.mutex.RUnlock()
+	return
+}
+
 func (manager *MemoryLines) FindAll() (lines []Line) {
 	manager.mutex.RLock()
 
@@ -246,6 +264,7 @@ func (manager *MemoryLines) Save(line *Line) bool {
 	line.model = manager.model
 	manager.byIdentifier[line.Id()] = line
 	manager.byObjectId.Index(line)
+	manager.byReferent.Index(line)
 
 	return true
 }
@@ -256,6 +275,7 @@ func (manager *MemoryLines) Delete(line *Line) bool {
 
 	delete(manager.byIdentifier, line.Id())
 	manager.byObjectId.Delete(ModelId(line.id))
+	manager.byReferent.Delete(ModelId(line.id))
 
 	return true
 }
@@ -274,6 +294,9 @@ func (manager *MemoryLines) Load(referentialSlug string) error {
 		if sl.Name.Valid {
 			line.Name = sl.Name.String
 		}
+		if sl.ReferentId.Valid {
+			line.ReferentId = LineId(sl.ReferentId.String)
+		}
 		if sl.CollectGeneralMessages.Valid {
 			line.CollectGeneralMessages = sl.CollectGeneralMessages.Bool
 		}
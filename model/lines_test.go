@@ -175,6 +175,31 @@ func Test_MemoryLines_FindAll(t *testing.T) {
 	}
 }
 
+func Test_MemoryLines_FindByReferentId(t *testing.T) {
+	lines := NewMemoryLines()
+	referent := lines.New()
+	lines.Save(&referent)
+
+	for i := 0; i < 2; i++ {
+		line := lines.New()
+		line.ReferentId = referent.Id()
+		lines.Save(&line)
+	}
+	otherLine := lines.New()
+	lines.Save(&otherLine)
+
+	if foundLines := lines.FindByReferentId(referent.Id()); len(foundLines) != 2 {
+		t.Errorf("FindByReferentId should return the 2 lines with the referent, got %v", len(foundLines))
+	}
+
+	otherLine.ReferentId = referent.Id()
+	lines.Save(&otherLine)
+	lines.Delete(&referent)
+	if foundLines := lines.FindByReferentId(referent.Id()); len(foundLines) != 3 {
+		t.Errorf("FindByReferentId should return the 3 lines with the referent, got %v", len(foundLines))
+	}
+}
+
 func Test_MemoryLines_Delete(t *testing.T) {
 	lines := NewMemoryLines()
 	existingLine := lines.New()
@@ -17,7 +17,7 @@ import (
 
 operator,Id,ModelName,Name,ObjectIDs
 stop_area,Id,ParentId,ReferentId,ModelName,Name,ObjectIDs,LineIds,Attributes,References,CollectedAlways,CollectChildren,CollectGeneralMessages
-line,Id,ModelName,Name,ObjectIDs,Attributes,References,CollectGeneralMessages
+line,Id,ModelName,Name,ObjectIDs,Attributes,References,CollectGeneralMessages[,ReferentId]
 vehicle_journey,Id,ModelName,Name,ObjectIDs,LineId,OriginName,DestinationName,Attributes,References
 stop_visit,Id,ModelName,ObjectIDs,StopAreaId,VehicleJourneyId,PassageOrder,Schedules,Attributes,References
 
@@ -78,8 +78,10 @@ func LoadFromCSVFile(filePath string, referentialSlug string, force bool) error
 	}
 	defer file.Close()
 
-	result := NewLoader(referentialSlug, force, true).Load(file)
+	return checkLoadResult(NewLoader(referentialSlug, force, true).Load(file))
+}
 
+func checkLoadResult(result Result) error {
 	if result.TotalInserts() == 0 {
 		if result.ErrorCount() == 0 {
 			return fmt.Errorf("loader error: empty file")
@@ -135,38 +137,39 @@ func (loader Loader) Load(reader io.Reader) Result {
 			continue
 		}
 
-		switch record[0] {
-		case OPERATOR:
-			err := loader.handleOperator(record)
-			if err != nil {
-				loader.err(i, err)
-			}
-		case STOP_AREA:
-			err := loader.handleStopArea(record)
-			if err != nil {
-				loader.err(i, err)
-			}
-		case LINE:
-			err := loader.handleLine(record)
-			if err != nil {
-				loader.err(i, err)
-			}
-		case VEHICLE_JOURNEY:
-			err := loader.handleVehicleJourney(record)
-			if err != nil {
-				loader.err(i, err)
-			}
-		case STOP_VISIT:
-			err := loader.handleStopVisit(record)
-			if err != nil {
-				loader.err(i, err)
-			}
-		default:
-			loader.err(i, fmt.Errorf("unknown record type %v", record[0]))
-			continue
+		err = loader.handleRecord(record)
+		if err != nil {
+			loader.err(i, err)
 		}
 	}
 
+	loader.insertAll()
+
+	logger.Log.Printf("Load operation done in %v", time.Since(startTime))
+	logger.Log.Printf(loader.result.PrintResult())
+
+	return loader.result
+}
+
+func (loader *Loader) handleRecord(record []string) error {
+	switch record[0] {
+	case OPERATOR:
+		return loader.handleOperator(record)
+	case STOP_AREA:
+		return loader.handleStopArea(record)
+	case LINE:
+		return loader.handleLine(record)
+	case VEHICLE_JOURNEY:
+		return loader.handleVehicleJourney(record)
+	case STOP_VISIT:
+		return loader.handleStopVisit(record)
+	default:
+		return fmt.Errorf("unknown record type %v", record[0])
+	}
+}
+
+// Inserts the pending records and counts the total inserts
+func (loader *Loader) insertAll() {
 	loader.insertOperators()
 	loader.insertStopAreas()
 	loader.insertLines()
@@ -174,11 +177,6 @@ func (loader Loader) Load(reader io.Reader) Result {
 	loader.insertStopVisits()
 
 	loader.result.setTotalInserts()
-
-	logger.Log.Printf("Load operation done in %v", time.Since(startTime))
-	logger.Log.Printf(loader.result.PrintResult())
-
-	return loader.result
 }
 
 func (loader *Loader) handleForce(klass, modelName string) error {
@@ -349,8 +347,8 @@ func (loader *Loader) insertStopAreas() {
 }
 
 func (loader *Loader) handleLine(record []string) error {
-	if len(record) != 8 {
-		return fmt.Errorf("wrong number of entries, expected 8 got %v", len(record))
+	if len(record) != 8 && len(record) != 9 {
+		return fmt.Errorf("wrong number of entries, expected 8 or 9 got %v", len(record))
 	}
 
 	var err error
@@ -373,7 +371,12 @@ func (loader *Loader) handleLine(record []string) error {
 		return err
 	}
 
-	values := fmt.Sprintf("($$%v$$,$$%v$$,$$%v$$,$$%v$$,$$%v$$,$$%v$$,$$%v$$,%v),",
+	referent := "null"
+	if len(record) == 9 && record[8] != "" {
+		referent = fmt.Sprintf("$$%v$$", record[8])
+	}
+
+	values := fmt.Sprintf("($$%v$$,$$%v$$,$$%v$$,$$%v$$,$$%v$$,$$%v$$,$$%v$$,%v,%v),",
 		loader.referentialSlug,
 		record[1],
 		record[2],
@@ -382,6 +385,7 @@ func (loader *Loader) handleLine(record []string) error {
 		record[5],
 		record[6],
 		collectGeneralMessages,
+		referent,
 	)
 	loader.lines = append(loader.lines, values...)
 	loader.bulkCounter[LINE]++
@@ -403,7 +407,7 @@ func (loader *Loader) insertLines() {
 		loader.bulkCounter[LINE] = 0
 	}()
 
-	query := fmt.Sprintf("INSERT INTO lines(referential_slug,id,model_name,name,object_ids,attributes,siri_references,collect_general_messages) VALUES %v;", string(loader.lines[:len(loader.lines)-1]))
+	query := fmt.Sprintf("INSERT INTO lines(referential_slug,id,model_name,name,object_ids,attributes,siri_references,collect_general_messages,referent_id) VALUES %v;", string(loader.lines[:len(loader.lines)-1]))
 	result, err := Database.Exec(query)
 	if err != nil {
 		loader.errInsert("lines", err)
@@ -563,6 +567,15 @@ func (loader *Loader) err(i int, e error) {
 	}
 }
 
+func (loader *Loader) errElement(element string, e error) {
+	if loader.printErrors {
+		logger.Log.Debugf("Error on %v: %v", element, e)
+		fmt.Printf("Error on %v: %v\n", element, e)
+	}
+	loader.result.Import[ERRORS]++
+	loader.result.Errors[fmt.Sprint("Error on ", element)] = append(loader.result.Errors[fmt.Sprint("Error on ", element)], e.Error())
+}
+
 func (loader *Loader) errInsert(m string, e error) {
 	if loader.printErrors {
 		logger.Log.Debugf("Error while inserting %v: %v", m, e)
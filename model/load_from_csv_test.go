@@ -58,6 +58,10 @@ func Test_LoadFromCSVFile(t *testing.T) {
 	if !ok {
 		t.Errorf("Can't find Line: %v", model.Lines().FindAll())
 	}
+	referringLine, ok := model.Lines().Find("f0eebc99-9c0b-4ef8-bb6d-6bb9bd380a12")
+	if !ok || referringLine.ReferentId != "f0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11" {
+		t.Errorf("Can't find Line with referent: %v", model.Lines().FindAll())
+	}
 	_, ok = model.VehicleJourneys().Find("01eebc99-9c0b-4ef8-bb6d-6bb9bd380a11")
 	if !ok {
 		t.Errorf("Can't find VehicleJourney: %v", model.VehicleJourneys().FindAll())
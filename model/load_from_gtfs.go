@@ -0,0 +1,520 @@
+package model
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+/* GTFS Structure
+
+agency.txt         -> operator
+stops.txt          -> stop_area (parent_station as parent)
+routes.txt         -> line
+trips.txt          -> vehicle_journey (only the trips running on the model date)
+stop_times.txt     -> stop_visit (stop_sequence as passage order, aimed schedules)
+
+The services running on the model date are defined by calendar.txt and calendar_dates.txt.
+Passing times are defined in the timezone of the first agency.
+*/
+
+const GTFS_FORMAT = "gtfs"
+
+type GtfsLoader struct {
+	uuid.UUIDConsumer
+
+	loader       *Loader
+	modelName    string
+	objectidKind string
+	location     *time.Location
+
+	recordHandler func([]string) error
+
+	files map[string]*zip.File
+
+	// GTFS id -> model id
+	operatorIds map[string]string
+	stopAreaIds map[string]string
+	lineIds     map[string]string
+
+	stops           []*gtfsStop
+	stopNames       map[string]string
+	routeOperators  map[string]string
+	services        map[string]struct{}
+	trips           map[string]*gtfsTrip
+	stopAreaLineIds map[string][]string
+}
+
+type gtfsStop struct {
+	id     string
+	name   string
+	parent string
+}
+
+type gtfsTrip struct {
+	id               string
+	vehicleJourneyId string
+	routeId          string
+	name             string
+	headsign         string
+
+	originSequence      int
+	originName          string
+	destinationSequence int
+	destinationName     string
+}
+
+// A GTFS file, the values are accessed with the column names
+type gtfsFile struct {
+	name    string
+	reader  *csv.Reader
+	columns map[string]int
+	record  []string
+}
+
+func LoadFromGtfsFile(filePath, referentialSlug, modelName, objectidKind string, force bool) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("loader error: error while opening file: %v", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("loader error: error while opening file: %v", err)
+	}
+
+	gtfsLoader, err := NewGtfsLoader(referentialSlug, modelName, objectidKind, force, true)
+	if err != nil {
+		return fmt.Errorf("loader error: %v", err)
+	}
+
+	return checkLoadResult(gtfsLoader.Load(file, info.Size()))
+}
+
+func NewGtfsLoader(referentialSlug, modelName, objectidKind string, force, printErrors bool) (*GtfsLoader, error) {
+	if _, err := time.Parse("2006-01-02", modelName); err != nil {
+		return nil, fmt.Errorf("invalid model name %v, expected a date (YYYY-MM-DD)", modelName)
+	}
+	if objectidKind == "" {
+		return nil, fmt.Errorf("an objectid kind is required")
+	}
+
+	loader := NewLoader(referentialSlug, force, printErrors)
+	return &GtfsLoader{
+		loader:          loader,
+		recordHandler:   loader.handleRecord,
+		modelName:       modelName,
+		objectidKind:    objectidKind,
+		location:        time.Local,
+		files:           make(map[string]*zip.File),
+		operatorIds:     make(map[string]string),
+		stopAreaIds:     make(map[string]string),
+		stopNames:       make(map[string]string),
+		lineIds:         make(map[string]string),
+		routeOperators:  make(map[string]string),
+		services:        make(map[string]struct{}),
+		trips:           make(map[string]*gtfsTrip),
+		stopAreaLineIds: make(map[string][]string),
+	}, nil
+}
+
+// Records are handled by the Loader while the files are read, with the
+// same batch inserts than the CSV import
+func (gtfsLoader *GtfsLoader) Load(reader io.ReaderAt, size int64) Result {
+	startTime := time.Now()
+	logger.Log.Printf("GTFS load operation started at %v", startTime)
+
+	err := gtfsLoader.load(reader, size)
+	if err != nil {
+		gtfsLoader.loader.errElement("archive", err)
+	}
+
+	gtfsLoader.loader.insertAll()
+
+	logger.Log.Printf("GTFS load operation done in %v", time.Since(startTime))
+	logger.Log.Printf(gtfsLoader.loader.result.PrintResult())
+
+	return gtfsLoader.loader.result
+}
+
+func (gtfsLoader *GtfsLoader) load(reader io.ReaderAt, size int64) error {
+	archive, err := zip.NewReader(reader, size)
+	if err != nil {
+		return err
+	}
+	for _, file := range archive.File {
+		gtfsLoader.files[file.Name] = file
+	}
+
+	steps := []struct {
+		name     string
+		required bool
+		handle   func(*gtfsFile) error
+	}{
+		{"agency.txt", true, gtfsLoader.handleAgency},
+		{"stops.txt", true, gtfsLoader.handleStop},
+		{"routes.txt", true, gtfsLoader.handleRoute},
+		{"calendar.txt", false, gtfsLoader.handleCalendar},
+		{"calendar_dates.txt", false, gtfsLoader.handleCalendarDate},
+		{"trips.txt", true, gtfsLoader.handleTrip},
+		{"stop_times.txt", true, gtfsLoader.handleStopTime},
+	}
+	for _, step := range steps {
+		err := gtfsLoader.readFile(step.name, step.required, step.handle)
+		if err != nil {
+			return err
+		}
+	}
+
+	// StopAreas and VehicleJourneys are completed by the StopTimes
+	for _, stop := range gtfsLoader.stops {
+		gtfsLoader.handleRecord(stop.id, gtfsLoader.stopAreaRecord(stop))
+	}
+
+	tripIds := make([]string, 0, len(gtfsLoader.trips))
+	for id := range gtfsLoader.trips {
+		tripIds = append(tripIds, id)
+	}
+	sort.Strings(tripIds)
+	for _, id := range tripIds {
+		gtfsLoader.handleRecord(id, gtfsLoader.vehicleJourneyRecord(gtfsLoader.trips[id]))
+	}
+
+	return nil
+}
+
+func (gtfsLoader *GtfsLoader) readFile(name string, required bool, handle func(*gtfsFile) error) error {
+	file, ok := gtfsLoader.files[name]
+	if !ok {
+		if required {
+			return fmt.Errorf("missing %v", name)
+		}
+		return nil
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	gtfsFile, err := newGtfsFile(name, reader)
+	if err != nil {
+		return err
+	}
+
+	var i int
+	for {
+		i++
+		err := gtfsFile.next()
+		if err == io.EOF {
+			return nil
+		}
+		// Invalid lines are ignored, other errors (like a corrupted
+		// archive) are returned at each read
+		var parseError *csv.ParseError
+		if err != nil && !errors.As(err, &parseError) {
+			return fmt.Errorf("%v: %v", name, err)
+		}
+		if err == nil {
+			err = handle(gtfsFile)
+		}
+		if err != nil {
+			gtfsLoader.loader.errElement(fmt.Sprintf("%v line %v", name, i+1), err)
+		}
+	}
+}
+
+func (gtfsLoader *GtfsLoader) handleRecord(element string, record []string) {
+	err := gtfsLoader.recordHandler(record)
+	if err != nil {
+		gtfsLoader.loader.errElement(element, err)
+	}
+}
+
+func (gtfsLoader *GtfsLoader) handleAgency(file *gtfsFile) error {
+	agencyId := file.value("agency_id")
+
+	// Passing times are defined in the timezone of the first agency
+	if len(gtfsLoader.operatorIds) == 0 {
+		if location, err := time.LoadLocation(file.value("agency_timezone")); err == nil {
+			gtfsLoader.location = location
+		}
+	}
+
+	id := gtfsLoader.NewUUID()
+	gtfsLoader.operatorIds[agencyId] = id
+	gtfsLoader.handleRecord(agencyId, []string{OPERATOR, id, gtfsLoader.modelName, file.value("agency_name"), gtfsLoader.objectids(agencyId)})
+	return nil
+}
+
+func (gtfsLoader *GtfsLoader) handleStop(file *gtfsFile) error {
+	stop := &gtfsStop{
+		id:     file.value("stop_id"),
+		name:   file.value("stop_name"),
+		parent: file.value("parent_station"),
+	}
+	if stop.id == "" {
+		return fmt.Errorf("missing stop_id")
+	}
+	gtfsLoader.stopAreaIds[stop.id] = gtfsLoader.NewUUID()
+	gtfsLoader.stopNames[stop.id] = stop.name
+	gtfsLoader.stops = append(gtfsLoader.stops, stop)
+	return nil
+}
+
+func (gtfsLoader *GtfsLoader) handleRoute(file *gtfsFile) error {
+	routeId := file.value("route_id")
+	if routeId == "" {
+		return fmt.Errorf("missing route_id")
+	}
+
+	name := file.value("route_long_name")
+	if name == "" {
+		name = file.value("route_short_name")
+	}
+
+	// agency_id is optional with a single agency
+	agencyId := file.value("agency_id")
+	if agencyId == "" && len(gtfsLoader.operatorIds) == 1 {
+		for id := range gtfsLoader.operatorIds {
+			agencyId = id
+		}
+	}
+	gtfsLoader.routeOperators[routeId] = agencyId
+
+	id := gtfsLoader.NewUUID()
+	gtfsLoader.lineIds[routeId] = id
+	gtfsLoader.handleRecord(routeId, []string{LINE, id, gtfsLoader.modelName, name, gtfsLoader.objectids(routeId), "{}", recordOperatorReferences(gtfsLoader.objectidKind, agencyId), ""})
+	return nil
+}
+
+var gtfsWeekdays = [7]string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+func (gtfsLoader *GtfsLoader) handleCalendar(file *gtfsFile) error {
+	date := gtfsLoader.gtfsModelDate()
+	if date < file.value("start_date") || date > file.value("end_date") {
+		return nil
+	}
+	modelDate, _ := time.Parse("2006-01-02", gtfsLoader.modelName)
+	if file.value(gtfsWeekdays[modelDate.Weekday()]) == "1" {
+		gtfsLoader.services[file.value("service_id")] = struct{}{}
+	}
+	return nil
+}
+
+func (gtfsLoader *GtfsLoader) handleCalendarDate(file *gtfsFile) error {
+	if file.value("date") != gtfsLoader.gtfsModelDate() {
+		return nil
+	}
+	switch file.value("exception_type") {
+	case "1":
+		gtfsLoader.services[file.value("service_id")] = struct{}{}
+	case "2":
+		delete(gtfsLoader.services, file.value("service_id"))
+	default:
+		return fmt.Errorf("invalid exception_type %v", file.value("exception_type"))
+	}
+	return nil
+}
+
+func (gtfsLoader *GtfsLoader) handleTrip(file *gtfsFile) error {
+	if _, ok := gtfsLoader.services[file.value("service_id")]; !ok {
+		return nil
+	}
+
+	trip := &gtfsTrip{
+		id:                  file.value("trip_id"),
+		vehicleJourneyId:    gtfsLoader.NewUUID(),
+		routeId:             file.value("route_id"),
+		name:                file.value("trip_short_name"),
+		headsign:            file.value("trip_headsign"),
+		originSequence:      -1,
+		destinationSequence: -1,
+	}
+	if _, ok := gtfsLoader.lineIds[trip.routeId]; !ok {
+		return fmt.Errorf("unknown route_id %v", trip.routeId)
+	}
+	gtfsLoader.trips[trip.id] = trip
+	return nil
+}
+
+func (gtfsLoader *GtfsLoader) handleStopTime(file *gtfsFile) error {
+	trip, ok := gtfsLoader.trips[file.value("trip_id")]
+	if !ok {
+		// Trip not running on the model date
+		return nil
+	}
+
+	stopAreaId, ok := gtfsLoader.stopAreaIds[file.value("stop_id")]
+	if !ok {
+		return fmt.Errorf("unknown stop_id %v", file.value("stop_id"))
+	}
+
+	sequence, err := strconv.Atoi(file.value("stop_sequence"))
+	if err != nil {
+		return fmt.Errorf("invalid stop_sequence %v", file.value("stop_sequence"))
+	}
+
+	schedule := &StopVisitSchedule{kind: STOP_VISIT_SCHEDULE_AIMED}
+	if value := file.value("arrival_time"); value != "" {
+		if schedule.arrivalTime, err = gtfsLoader.passingTime(value); err != nil {
+			return err
+		}
+	}
+	if value := file.value("departure_time"); value != "" {
+		if schedule.departureTime, err = gtfsLoader.passingTime(value); err != nil {
+			return err
+		}
+	}
+
+	lineId := gtfsLoader.lineIds[trip.routeId]
+	if !containsString(gtfsLoader.stopAreaLineIds[stopAreaId], lineId) {
+		gtfsLoader.stopAreaLineIds[stopAreaId] = append(gtfsLoader.stopAreaLineIds[stopAreaId], lineId)
+	}
+
+	stopName := gtfsLoader.stopNames[file.value("stop_id")]
+	if trip.originSequence == -1 || sequence < trip.originSequence {
+		trip.originSequence = sequence
+		trip.originName = stopName
+	}
+	if sequence > trip.destinationSequence {
+		trip.destinationSequence = sequence
+		trip.destinationName = stopName
+	}
+
+	attributes := map[string]string{}
+	if headsign := file.value("stop_headsign"); headsign != "" {
+		attributes["DestinationDisplay"] = headsign
+	}
+
+	gtfsLoader.handleRecord(trip.id, []string{
+		STOP_VISIT,
+		gtfsLoader.NewUUID(),
+		gtfsLoader.modelName,
+		gtfsLoader.objectids(fmt.Sprintf("%v-%v", trip.id, sequence)),
+		stopAreaId,
+		trip.vehicleJourneyId,
+		strconv.Itoa(sequence),
+		recordJSON([]*StopVisitSchedule{schedule}),
+		recordJSON(attributes),
+		"{}",
+	})
+	return nil
+}
+
+func (gtfsLoader *GtfsLoader) stopAreaRecord(stop *gtfsStop) []string {
+	lineIds := "[]"
+	if ids, ok := gtfsLoader.stopAreaLineIds[gtfsLoader.stopAreaIds[stop.id]]; ok {
+		lineIds = recordJSON(ids)
+	}
+	return []string{
+		STOP_AREA,
+		gtfsLoader.stopAreaIds[stop.id],
+		gtfsLoader.stopAreaIds[stop.parent],
+		"",
+		gtfsLoader.modelName,
+		stop.name,
+		gtfsLoader.objectids(stop.id),
+		lineIds,
+		"{}",
+		"{}",
+		"true",
+		"",
+		"",
+	}
+}
+
+func (gtfsLoader *GtfsLoader) vehicleJourneyRecord(trip *gtfsTrip) []string {
+	destinationName := trip.headsign
+	if destinationName == "" {
+		destinationName = trip.destinationName
+	}
+	return []string{
+		VEHICLE_JOURNEY,
+		trip.vehicleJourneyId,
+		gtfsLoader.modelName,
+		trip.name,
+		gtfsLoader.objectids(trip.id),
+		gtfsLoader.lineIds[trip.routeId],
+		trip.originName,
+		destinationName,
+		"{}",
+		recordOperatorReferences(gtfsLoader.objectidKind, gtfsLoader.routeOperators[trip.routeId]),
+	}
+}
+
+// GTFS times can be after 24:00:00 for the trips ending after midnight
+func (gtfsLoader *GtfsLoader) passingTime(value string) (time.Time, error) {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("invalid passing time %v", value)
+	}
+	var hms [3]int
+	for i := range parts {
+		v, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid passing time %v", value)
+		}
+		hms[i] = v
+	}
+	date, _ := time.Parse("2006-01-02", gtfsLoader.modelName)
+	return time.Date(date.Year(), date.Month(), date.Day(), hms[0], hms[1], hms[2], 0, gtfsLoader.location), nil
+}
+
+func (gtfsLoader *GtfsLoader) gtfsModelDate() string {
+	return strings.Replace(gtfsLoader.modelName, "-", "", -1)
+}
+
+func (gtfsLoader *GtfsLoader) objectids(value string) string {
+	return recordObjectIDs(gtfsLoader.objectidKind, value)
+}
+
+func newGtfsFile(name string, reader io.Reader) (*gtfsFile, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid %v header: %v", name, err)
+	}
+
+	file := &gtfsFile{
+		name:    name,
+		reader:  csvReader,
+		columns: make(map[string]int),
+	}
+	for i, column := range header {
+		if i == 0 {
+			column = strings.TrimPrefix(column, "\ufeff")
+		}
+		file.columns[strings.TrimSpace(column)] = i
+	}
+	return file, nil
+}
+
+func (file *gtfsFile) next() (err error) {
+	file.record, err = file.reader.Read()
+	return
+}
+
+func (file *gtfsFile) value(column string) string {
+	i, ok := file.columns[column]
+	if !ok || i >= len(file.record) {
+		return ""
+	}
+	return strings.TrimSpace(file.record[i])
+}
@@ -0,0 +1,175 @@
+package model
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+func gtfsTestArchive(t *testing.T) *bytes.Reader {
+	files, err := filepath.Glob("testdata/gtfs/*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buffer := new(bytes.Buffer)
+	writer := zip.NewWriter(buffer)
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := writer.Create(filepath.Base(file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(content)
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return bytes.NewReader(buffer.Bytes())
+}
+
+func Test_GtfsLoader_Records(t *testing.T) {
+	loader, err := NewGtfsLoader("referential", "2017-01-02", "internal", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader.SetUUIDGenerator(uuid.NewFakeUUIDGenerator())
+
+	records := make(map[string][][]string)
+	loader.recordHandler = func(record []string) error {
+		records[record[0]] = append(records[record[0]], record)
+		return nil
+	}
+
+	archive := gtfsTestArchive(t)
+	if err = loader.load(archive, archive.Size()); err != nil {
+		t.Fatal(err)
+	}
+	if loader.loader.result.ErrorCount() != 0 {
+		t.Fatalf("Load should not raise errors, got: %v", loader.loader.result.Errors)
+	}
+
+	expectedCounts := map[string]int{OPERATOR: 1, STOP_AREA: 3, LINE: 1, VEHICLE_JOURNEY: 2, STOP_VISIT: 4}
+	for kind, count := range expectedCounts {
+		if len(records[kind]) != count {
+			t.Fatalf("Wrong %v records count:\n got: %v\n want: %v", kind, len(records[kind]), count)
+		}
+	}
+
+	stopAreas := make(map[string][]string)
+	for _, record := range records[STOP_AREA] {
+		objectids := make(map[string]string)
+		json.Unmarshal([]byte(record[6]), &objectids)
+		stopAreas[objectids["internal"]] = record
+	}
+	if stopAreas["stop1"][2] != stopAreas["station1"][1] {
+		t.Errorf("stop1 should have station1 as parent, got: %v", stopAreas["stop1"][2])
+	}
+	lineId := records[LINE][0][1]
+	if stopAreas["stop2"][7] != `["`+lineId+`"]` {
+		t.Errorf("Wrong stop2 LineIds:\n got: %v\n want: [\"%v\"]", stopAreas["stop2"][7], lineId)
+	}
+	if records[LINE][0][3] != "Line 1" {
+		t.Errorf("Wrong Line name: %v", records[LINE][0][3])
+	}
+
+	trip1, trip2 := records[VEHICLE_JOURNEY][0], records[VEHICLE_JOURNEY][1]
+	if trip1[4] != `{"internal":"trip1"}` || trip2[4] != `{"internal":"trip2"}` {
+		t.Fatalf("Wrong VehicleJourneys: %v, %v", trip1[4], trip2[4])
+	}
+	if trip1[6] != "Stop 1" || trip1[7] != "Stop 2" {
+		t.Errorf("Wrong trip1 Origin and Destination names: %v, %v", trip1[6], trip1[7])
+	}
+	if trip2[7] != "Headsign" {
+		t.Errorf("trip2 DestinationName should be the trip_headsign, got: %v", trip2[7])
+	}
+	if expected := `{"OperatorRef":{"ObjectId":{"internal":"agency1"}}}`; trip1[9] != expected {
+		t.Errorf("Wrong VehicleJourney References:\n got: %v\n want: %v", trip1[9], expected)
+	}
+
+	// trip1 arrives at stop2 after midnight
+	stopVisit := records[STOP_VISIT][1]
+	if stopVisit[3] != `{"internal":"trip1-2"}` || stopVisit[5] != trip1[1] || stopVisit[6] != "2" {
+		t.Fatalf("Wrong StopVisit record: %v", stopVisit)
+	}
+	var schedules []StopVisitSchedule
+	if err = json.Unmarshal([]byte(stopVisit[7]), &schedules); err != nil {
+		t.Fatal(err)
+	}
+	location, _ := time.LoadLocation("Europe/Paris")
+	expectedArrival := time.Date(2017, time.January, 3, 0, 10, 0, 0, location)
+	if len(schedules) != 1 || !schedules[0].ArrivalTime().Equal(expectedArrival) {
+		t.Errorf("Wrong StopVisit Schedules:\n got: %v\n want: aimed arrival at %v", stopVisit[7], expectedArrival)
+	}
+
+	if records[STOP_VISIT][2][8] != `{"DestinationDisplay":"Terminus"}` {
+		t.Errorf("Wrong StopVisit Attributes: %v", records[STOP_VISIT][2][8])
+	}
+}
+
+func Test_GtfsLoader_Calendar(t *testing.T) {
+	// 2017-01-03 is a Tuesday, with the weekdays service removed
+	loader, err := NewGtfsLoader("referential", "2017-01-03", "internal", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var vehicleJourneys int
+	loader.recordHandler = func(record []string) error {
+		if record[0] == VEHICLE_JOURNEY {
+			vehicleJourneys++
+		}
+		return nil
+	}
+
+	archive := gtfsTestArchive(t)
+	if err = loader.load(archive, archive.Size()); err != nil {
+		t.Fatal(err)
+	}
+	if vehicleJourneys != 0 {
+		t.Errorf("No VehicleJourney should be loaded, got: %v", vehicleJourneys)
+	}
+}
+
+func Test_NewGtfsLoader_InvalidModelName(t *testing.T) {
+	if _, err := NewGtfsLoader("referential", "test", "internal", false, false); err == nil {
+		t.Errorf("NewGtfsLoader should return an error with an invalid model name")
+	}
+}
+
+func Test_GtfsLoader_CorruptedArchive(t *testing.T) {
+	loader, err := NewGtfsLoader("referential", "2017-01-02", "internal", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader.recordHandler = func(record []string) error { return nil }
+
+	content := "agency_id,agency_name,agency_url,agency_timezone\nagency1,Agency,http://example.com,Europe/Paris\n"
+	buffer := new(bytes.Buffer)
+	writer := zip.NewWriter(buffer)
+	w, err := writer.CreateHeader(&zip.FileHeader{Name: "agency.txt", Method: zip.Store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(content))
+	if err = writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Alter the stored content to raise a checksum error
+	data := bytes.Replace(buffer.Bytes(), []byte("Agency,"), []byte("Agencz,"), 1)
+	archive := bytes.NewReader(data)
+	if err = loader.load(archive, archive.Size()); err == nil {
+		t.Errorf("Load should return an error with a corrupted archive")
+	}
+}
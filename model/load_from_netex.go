@@ -0,0 +1,547 @@
+package model
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+/* NeTEx Structure
+
+Reads the NeTEx (French profile) elements, in any frame:
+
+Operator                  -> operator
+StopPlace and its Quays   -> stop_area (ParentSiteRef or enclosing StopPlace as parent, derivedFromObjectRef as referent)
+Line                      -> line (derivedFromObjectRef as referent)
+ServiceJourney            -> vehicle_journey
+TimetabledPassingTime     -> stop_visit (Order of the StopPointInJourneyPattern)
+
+ScheduledStopPoints are associated to Quays with the PassengerStopAssignments.
+The passing times are defined for the day given by the model name.
+*/
+
+const NETEX_FORMAT = "netex"
+
+type NeTExLoader struct {
+	uuid.UUIDConsumer
+
+	loader       *Loader
+	modelName    string
+	modelDate    time.Time
+	objectidKind string
+
+	operators                  []*netexOperator
+	stopPlaces                 []*netexStopPlace
+	scheduledStopPoints        map[string]*netexScheduledStopPoint
+	stopAssignments            map[string]string
+	lines                      []*netexLine
+	routes                     map[string]string
+	journeyPatterns            map[string]*netexJourneyPattern
+	destinationDisplays        map[string]string
+	serviceJourneys            []*netexServiceJourney
+	stopPointsInJourneyPattern map[string]*netexStopPointInJourneyPattern
+
+	// NeTEx id -> model id
+	operatorIds map[string]string
+	stopAreaIds map[string]string
+	lineIds     map[string]string
+}
+
+type netexRef struct {
+	Ref string `xml:"ref,attr"`
+}
+
+type netexOperator struct {
+	Id   string `xml:"id,attr"`
+	Name string `xml:"Name"`
+}
+
+type netexStopPlace struct {
+	Id                   string      `xml:"id,attr"`
+	DerivedFromObjectRef string      `xml:"derivedFromObjectRef,attr"`
+	Name                 string      `xml:"Name"`
+	ParentSiteRef        netexRef    `xml:"ParentSiteRef"`
+	Quays                []netexQuay `xml:"quays>Quay"`
+}
+
+type netexQuay struct {
+	Id                   string `xml:"id,attr"`
+	DerivedFromObjectRef string `xml:"derivedFromObjectRef,attr"`
+	Name                 string `xml:"Name"`
+}
+
+type netexScheduledStopPoint struct {
+	Id   string `xml:"id,attr"`
+	Name string `xml:"Name"`
+}
+
+type netexPassengerStopAssignment struct {
+	ScheduledStopPointRef netexRef `xml:"ScheduledStopPointRef"`
+	QuayRef               netexRef `xml:"QuayRef"`
+	StopPlaceRef          netexRef `xml:"StopPlaceRef"`
+}
+
+type netexLine struct {
+	Id                   string   `xml:"id,attr"`
+	DerivedFromObjectRef string   `xml:"derivedFromObjectRef,attr"`
+	Name                 string   `xml:"Name"`
+	PublicCode           string   `xml:"PublicCode"`
+	OperatorRef          netexRef `xml:"OperatorRef"`
+}
+
+type netexRoute struct {
+	Id      string   `xml:"id,attr"`
+	LineRef netexRef `xml:"LineRef"`
+}
+
+type netexJourneyPattern struct {
+	Id     string                            `xml:"id,attr"`
+	Route  netexRef                          `xml:"RouteRef"`
+	Points []*netexStopPointInJourneyPattern `xml:"pointsInSequence>StopPointInJourneyPattern"`
+}
+
+type netexStopPointInJourneyPattern struct {
+	Id                    string   `xml:"id,attr"`
+	Order                 string   `xml:"order,attr"`
+	ScheduledStopPointRef netexRef `xml:"ScheduledStopPointRef"`
+	DestinationDisplayRef netexRef `xml:"DestinationDisplayRef"`
+}
+
+type netexDestinationDisplay struct {
+	Id        string `xml:"id,attr"`
+	FrontText string `xml:"FrontText"`
+}
+
+type netexServiceJourney struct {
+	Id                       string                       `xml:"id,attr"`
+	Name                     string                       `xml:"Name"`
+	LineRef                  netexRef                     `xml:"LineRef"`
+	JourneyPatternRef        netexRef                     `xml:"JourneyPatternRef"`
+	ServiceJourneyPatternRef netexRef                     `xml:"ServiceJourneyPatternRef"`
+	OperatorRef              netexRef                     `xml:"OperatorRef"`
+	PassingTimes             []netexTimetabledPassingTime `xml:"passingTimes>TimetabledPassingTime"`
+}
+
+type netexTimetabledPassingTime struct {
+	StopPointInJourneyPatternRef netexRef `xml:"StopPointInJourneyPatternRef"`
+	ArrivalTime                  string   `xml:"ArrivalTime"`
+	ArrivalDayOffset             int      `xml:"ArrivalDayOffset"`
+	DepartureTime                string   `xml:"DepartureTime"`
+	DepartureDayOffset           int      `xml:"DepartureDayOffset"`
+}
+
+func LoadFromNeTExFile(filePath, referentialSlug, modelName, objectidKind string, force bool) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("loader error: error while opening file: %v", err)
+	}
+	defer file.Close()
+
+	netexLoader, err := NewNeTExLoader(referentialSlug, modelName, objectidKind, force, true)
+	if err != nil {
+		return fmt.Errorf("loader error: %v", err)
+	}
+
+	return checkLoadResult(netexLoader.Load(file))
+}
+
+func NewNeTExLoader(referentialSlug, modelName, objectidKind string, force, printErrors bool) (*NeTExLoader, error) {
+	modelDate, err := time.ParseInLocation("2006-01-02", modelName, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid model name %v, expected a date (YYYY-MM-DD)", modelName)
+	}
+	if objectidKind == "" {
+		return nil, fmt.Errorf("an objectid kind is required")
+	}
+
+	return &NeTExLoader{
+		loader:                     NewLoader(referentialSlug, force, printErrors),
+		modelName:                  modelName,
+		modelDate:                  modelDate,
+		objectidKind:               objectidKind,
+		scheduledStopPoints:        make(map[string]*netexScheduledStopPoint),
+		stopAssignments:            make(map[string]string),
+		routes:                     make(map[string]string),
+		journeyPatterns:            make(map[string]*netexJourneyPattern),
+		destinationDisplays:        make(map[string]string),
+		stopPointsInJourneyPattern: make(map[string]*netexStopPointInJourneyPattern),
+		operatorIds:                make(map[string]string),
+		stopAreaIds:                make(map[string]string),
+		lineIds:                    make(map[string]string),
+	}, nil
+}
+
+func (netexLoader *NeTExLoader) Load(reader io.Reader) Result {
+	startTime := time.Now()
+	logger.Log.Printf("NeTEx load operation started at %v", startTime)
+
+	err := netexLoader.parse(reader)
+	if err != nil {
+		netexLoader.loader.errElement("document", err)
+		netexLoader.loader.result.setTotalInserts()
+		return netexLoader.loader.result
+	}
+
+	for _, record := range netexLoader.Records() {
+		err := netexLoader.loader.handleRecord(record)
+		if err != nil {
+			netexLoader.loader.errElement(record[0], err)
+		}
+	}
+
+	netexLoader.loader.insertAll()
+
+	logger.Log.Printf("NeTEx load operation done in %v", time.Since(startTime))
+	logger.Log.Printf(netexLoader.loader.result.PrintResult())
+
+	return netexLoader.loader.result
+}
+
+func (netexLoader *NeTExLoader) parse(reader io.Reader) error {
+	decoder := xml.NewDecoder(reader)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "Operator":
+			operator := &netexOperator{}
+			err = decoder.DecodeElement(operator, &start)
+			netexLoader.operators = append(netexLoader.operators, operator)
+		case "StopPlace":
+			stopPlace := &netexStopPlace{}
+			err = decoder.DecodeElement(stopPlace, &start)
+			netexLoader.stopPlaces = append(netexLoader.stopPlaces, stopPlace)
+		case "ScheduledStopPoint":
+			stopPoint := &netexScheduledStopPoint{}
+			err = decoder.DecodeElement(stopPoint, &start)
+			netexLoader.scheduledStopPoints[stopPoint.Id] = stopPoint
+		case "PassengerStopAssignment":
+			assignment := &netexPassengerStopAssignment{}
+			err = decoder.DecodeElement(assignment, &start)
+			if assignment.QuayRef.Ref != "" {
+				netexLoader.stopAssignments[assignment.ScheduledStopPointRef.Ref] = assignment.QuayRef.Ref
+			} else {
+				netexLoader.stopAssignments[assignment.ScheduledStopPointRef.Ref] = assignment.StopPlaceRef.Ref
+			}
+		case "Line":
+			line := &netexLine{}
+			err = decoder.DecodeElement(line, &start)
+			netexLoader.lines = append(netexLoader.lines, line)
+		case "Route":
+			route := &netexRoute{}
+			err = decoder.DecodeElement(route, &start)
+			netexLoader.routes[route.Id] = route.LineRef.Ref
+		case "JourneyPattern", "ServiceJourneyPattern":
+			journeyPattern := &netexJourneyPattern{}
+			err = decoder.DecodeElement(journeyPattern, &start)
+			netexLoader.journeyPatterns[journeyPattern.Id] = journeyPattern
+			for _, point := range journeyPattern.Points {
+				netexLoader.stopPointsInJourneyPattern[point.Id] = point
+			}
+		case "DestinationDisplay":
+			destinationDisplay := &netexDestinationDisplay{}
+			err = decoder.DecodeElement(destinationDisplay, &start)
+			netexLoader.destinationDisplays[destinationDisplay.Id] = destinationDisplay.FrontText
+		case "ServiceJourney":
+			serviceJourney := &netexServiceJourney{}
+			err = decoder.DecodeElement(serviceJourney, &start)
+			netexLoader.serviceJourneys = append(netexLoader.serviceJourneys, serviceJourney)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid %v element: %v", start.Name.Local, err)
+		}
+	}
+}
+
+// Returns the records in the CSV loader format
+func (netexLoader *NeTExLoader) Records() (records [][]string) {
+	for _, operator := range netexLoader.operators {
+		id := netexLoader.NewUUID()
+		netexLoader.operatorIds[operator.Id] = id
+		records = append(records, []string{OPERATOR, id, netexLoader.modelName, operator.Name, netexLoader.objectids(operator.Id)})
+	}
+
+	for _, line := range netexLoader.lines {
+		netexLoader.lineIds[line.Id] = netexLoader.NewUUID()
+	}
+
+	stopAreas := netexLoader.stopAreaRecords()
+	vehicleJourneys, stopVisits, stopAreaLineIds := netexLoader.vehicleJourneyRecords()
+
+	for _, record := range stopAreas {
+		if lineIds, ok := stopAreaLineIds[record[1]]; ok {
+			record[7] = recordJSON(lineIds)
+		}
+		records = append(records, record)
+	}
+
+	for _, line := range netexLoader.lines {
+		name := line.Name
+		if name == "" {
+			name = line.PublicCode
+		}
+		records = append(records, []string{LINE, netexLoader.lineIds[line.Id], netexLoader.modelName, name, netexLoader.objectids(line.Id), "{}", netexLoader.operatorReferences(line.OperatorRef.Ref), "", netexLoader.lineIds[line.DerivedFromObjectRef]})
+	}
+
+	records = append(records, vehicleJourneys...)
+	records = append(records, stopVisits...)
+
+	return
+}
+
+func (netexLoader *NeTExLoader) stopAreaRecords() (records [][]string) {
+	for _, stopPlace := range netexLoader.stopPlaces {
+		netexLoader.stopAreaIds[stopPlace.Id] = netexLoader.NewUUID()
+		for _, quay := range stopPlace.Quays {
+			netexLoader.stopAreaIds[quay.Id] = netexLoader.NewUUID()
+		}
+	}
+
+	for _, stopPlace := range netexLoader.stopPlaces {
+		records = append(records, netexLoader.stopAreaRecord(stopPlace.Id, stopPlace.ParentSiteRef.Ref, stopPlace.DerivedFromObjectRef, stopPlace.Name))
+		for _, quay := range stopPlace.Quays {
+			records = append(records, netexLoader.stopAreaRecord(quay.Id, stopPlace.Id, quay.DerivedFromObjectRef, quay.Name))
+		}
+	}
+
+	// ScheduledStopPoints without PassengerStopAssignment are loaded as StopAreas
+	var unassigned []string
+	for id := range netexLoader.scheduledStopPoints {
+		if _, ok := netexLoader.stopAreaIds[netexLoader.stopAssignments[id]]; !ok {
+			unassigned = append(unassigned, id)
+		}
+	}
+	sort.Strings(unassigned)
+	for _, id := range unassigned {
+		netexLoader.stopAreaIds[id] = netexLoader.NewUUID()
+		records = append(records, netexLoader.stopAreaRecord(id, "", "", netexLoader.scheduledStopPoints[id].Name))
+	}
+
+	return
+}
+
+func (netexLoader *NeTExLoader) stopAreaRecord(netexId, parentRef, referentRef, name string) []string {
+	return []string{
+		STOP_AREA,
+		netexLoader.stopAreaIds[netexId],
+		netexLoader.stopAreaIds[parentRef],
+		netexLoader.stopAreaIds[referentRef],
+		netexLoader.modelName,
+		name,
+		netexLoader.objectids(netexId),
+		"[]",
+		"{}",
+		"{}",
+		"true",
+		"",
+		"",
+	}
+}
+
+func (netexLoader *NeTExLoader) vehicleJourneyRecords() (vehicleJourneys, stopVisits [][]string, stopAreaLineIds map[string][]string) {
+	stopAreaLineIds = make(map[string][]string)
+
+	for _, serviceJourney := range netexLoader.serviceJourneys {
+		journeyPattern := netexLoader.journeyPatterns[serviceJourney.JourneyPatternRef.Ref]
+		if journeyPattern == nil {
+			journeyPattern = netexLoader.journeyPatterns[serviceJourney.ServiceJourneyPatternRef.Ref]
+		}
+
+		lineRef := serviceJourney.LineRef.Ref
+		if lineRef == "" && journeyPattern != nil {
+			lineRef = netexLoader.routes[journeyPattern.Route.Ref]
+		}
+		lineId, ok := netexLoader.lineIds[lineRef]
+		if !ok {
+			netexLoader.loader.errElement(serviceJourney.Id, fmt.Errorf("unknown Line %v", lineRef))
+			continue
+		}
+
+		vehicleJourneyId := netexLoader.NewUUID()
+
+		var originName, destinationName, destinationDisplay string
+		for i, passingTime := range serviceJourney.PassingTimes {
+			point, ok := netexLoader.stopPointsInJourneyPattern[passingTime.StopPointInJourneyPatternRef.Ref]
+			if !ok {
+				netexLoader.loader.errElement(serviceJourney.Id, fmt.Errorf("unknown StopPointInJourneyPattern %v", passingTime.StopPointInJourneyPatternRef.Ref))
+				continue
+			}
+
+			stopPointRef := point.ScheduledStopPointRef.Ref
+			stopAreaRef := stopPointRef
+			if assignment, ok := netexLoader.stopAssignments[stopPointRef]; ok {
+				if _, ok := netexLoader.stopAreaIds[assignment]; ok {
+					stopAreaRef = assignment
+				}
+			}
+			stopAreaId, ok := netexLoader.stopAreaIds[stopAreaRef]
+			if !ok {
+				netexLoader.loader.errElement(serviceJourney.Id, fmt.Errorf("unknown ScheduledStopPoint %v", stopPointRef))
+				continue
+			}
+			if !containsString(stopAreaLineIds[stopAreaId], lineId) {
+				stopAreaLineIds[stopAreaId] = append(stopAreaLineIds[stopAreaId], lineId)
+			}
+
+			stopPointName := netexLoader.stopPointName(stopPointRef, stopAreaRef)
+			if originName == "" {
+				originName = stopPointName
+			}
+			destinationName = stopPointName
+			if text, ok := netexLoader.destinationDisplays[point.DestinationDisplayRef.Ref]; ok {
+				destinationDisplay = text
+			}
+
+			order, err := strconv.Atoi(point.Order)
+			if err != nil {
+				order = i + 1
+			}
+
+			schedules, err := netexLoader.schedules(passingTime)
+			if err != nil {
+				netexLoader.loader.errElement(serviceJourney.Id, err)
+				continue
+			}
+
+			attributes := map[string]string{}
+			if destinationDisplay != "" {
+				attributes["DestinationDisplay"] = destinationDisplay
+			}
+
+			stopVisits = append(stopVisits, []string{
+				STOP_VISIT,
+				netexLoader.NewUUID(),
+				netexLoader.modelName,
+				netexLoader.objectids(fmt.Sprintf("%v-%v", serviceJourney.Id, order)),
+				stopAreaId,
+				vehicleJourneyId,
+				strconv.Itoa(order),
+				schedules,
+				recordJSON(attributes),
+				"{}",
+			})
+		}
+
+		vehicleJourneys = append(vehicleJourneys, []string{
+			VEHICLE_JOURNEY,
+			vehicleJourneyId,
+			netexLoader.modelName,
+			serviceJourney.Name,
+			netexLoader.objectids(serviceJourney.Id),
+			lineId,
+			originName,
+			destinationName,
+			"{}",
+			netexLoader.operatorReferences(serviceJourney.OperatorRef.Ref),
+		})
+	}
+
+	return
+}
+
+func (netexLoader *NeTExLoader) stopPointName(stopPointRef, stopAreaRef string) string {
+	if stopPoint, ok := netexLoader.scheduledStopPoints[stopPointRef]; ok && stopPoint.Name != "" {
+		return stopPoint.Name
+	}
+	for _, stopPlace := range netexLoader.stopPlaces {
+		if stopPlace.Id == stopAreaRef {
+			return stopPlace.Name
+		}
+		for _, quay := range stopPlace.Quays {
+			if quay.Id == stopAreaRef {
+				return quay.Name
+			}
+		}
+	}
+	return ""
+}
+
+func (netexLoader *NeTExLoader) schedules(passingTime netexTimetabledPassingTime) (string, error) {
+	schedule := &StopVisitSchedule{kind: STOP_VISIT_SCHEDULE_AIMED}
+
+	var err error
+	if passingTime.ArrivalTime != "" {
+		schedule.arrivalTime, err = netexLoader.passingTime(passingTime.ArrivalTime, passingTime.ArrivalDayOffset)
+		if err != nil {
+			return "", err
+		}
+	}
+	if passingTime.DepartureTime != "" {
+		schedule.departureTime, err = netexLoader.passingTime(passingTime.DepartureTime, passingTime.DepartureDayOffset)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return recordJSON([]*StopVisitSchedule{schedule}), nil
+}
+
+// NeTEx passing times are times of day, with an optional day offset
+func (netexLoader *NeTExLoader) passingTime(value string, dayOffset int) (time.Time, error) {
+	t, err := time.Parse("15:04:05", strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid passing time %v", value)
+	}
+	date := netexLoader.modelDate.AddDate(0, 0, dayOffset)
+	return time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), t.Second(), 0, date.Location()), nil
+}
+
+func (netexLoader *NeTExLoader) operatorReferences(operatorRef string) string {
+	return recordOperatorReferences(netexLoader.objectidKind, operatorRef)
+}
+
+func (netexLoader *NeTExLoader) objectids(value string) string {
+	return recordObjectIDs(netexLoader.objectidKind, value)
+}
+
+// Helpers to build the records of the CSV loader format
+
+func recordOperatorReferences(objectidKind, operatorRef string) string {
+	if operatorRef == "" {
+		return "{}"
+	}
+	return recordJSON(map[string]Reference{
+		"OperatorRef": *NewReference(NewObjectID(objectidKind, operatorRef)),
+	})
+}
+
+func recordObjectIDs(objectidKind, value string) string {
+	return recordJSON(map[string]string{objectidKind: value})
+}
+
+func recordJSON(value interface{}) string {
+	b, err := json.Marshal(value)
+	if err != nil { // should not happen
+		return ""
+	}
+	return string(b)
+}
+
+func containsString(values []string, value string) bool {
+	for i := range values {
+		if values[i] == value {
+			return true
+		}
+	}
+	return false
+}
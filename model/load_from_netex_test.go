@@ -0,0 +1,109 @@
+package model
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/uuid"
+)
+
+func Test_NeTExLoader_Records(t *testing.T) {
+	file, err := os.Open("testdata/netex.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	loader, err := NewNeTExLoader("referential", "2017-01-01", "internal", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader.SetUUIDGenerator(uuid.NewFakeUUIDGenerator())
+
+	if err = loader.parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	records := make(map[string][][]string)
+	for _, record := range loader.Records() {
+		records[record[0]] = append(records[record[0]], record)
+	}
+
+	expectedCounts := map[string]int{OPERATOR: 1, STOP_AREA: 6, LINE: 2, VEHICLE_JOURNEY: 1, STOP_VISIT: 3}
+	for kind, count := range expectedCounts {
+		if len(records[kind]) != count {
+			t.Fatalf("Wrong %v records count:\n got: %v\n want: %v", kind, len(records[kind]), count)
+		}
+	}
+
+	stopAreas := make(map[string][]string)
+	for _, record := range records[STOP_AREA] {
+		objectids := make(map[string]string)
+		json.Unmarshal([]byte(record[6]), &objectids)
+		stopAreas[objectids["internal"]] = record
+	}
+
+	child := stopAreas["FR:StopPlace:Child:LOC"]
+	if child[2] != stopAreas["FR:StopPlace:Parent:LOC"][1] {
+		t.Errorf("Child StopArea should have Parent as parent, got: %v", child[2])
+	}
+	if child[3] != stopAreas["FR:StopPlace:Referent:LOC"][1] {
+		t.Errorf("Child StopArea should have Referent as referent, got: %v", child[3])
+	}
+
+	lineId := records[LINE][0][1]
+	quay := stopAreas["FR:Quay:1:LOC"]
+	if quay[2] != child[1] {
+		t.Errorf("Quay should have the StopPlace as parent, got: %v", quay[2])
+	}
+	if quay[7] != `["`+lineId+`"]` {
+		t.Errorf("Wrong Quay LineIds:\n got: %v\n want: [\"%v\"]", quay[7], lineId)
+	}
+	if _, ok := stopAreas["FR:ScheduledStopPoint:3:LOC"]; !ok {
+		t.Errorf("Unassigned ScheduledStopPoint should be loaded as a StopArea")
+	}
+
+	if expected := `{"OperatorRef":{"ObjectId":{"internal":"FR:Operator:1:LOC"}}}`; records[LINE][0][6] != expected {
+		t.Errorf("Wrong Line References:\n got: %v\n want: %v", records[LINE][0][6], expected)
+	}
+
+	if records[LINE][0][8] != "" || records[LINE][1][8] != lineId {
+		t.Errorf("Line 2 should have Line 1 as referent, got: %v", records[LINE][1][8])
+	}
+
+	vehicleJourney := records[VEHICLE_JOURNEY][0]
+	if vehicleJourney[5] != lineId {
+		t.Errorf("Wrong VehicleJourney LineId:\n got: %v\n want: %v", vehicleJourney[5], lineId)
+	}
+	if vehicleJourney[6] != "Quay 1" || vehicleJourney[7] != "Unassigned" {
+		t.Errorf("Wrong VehicleJourney Origin and Destination names: %v, %v", vehicleJourney[6], vehicleJourney[7])
+	}
+
+	stopVisit := records[STOP_VISIT][1]
+	if stopVisit[3] != `{"internal":"FR:ServiceJourney:1:LOC-2"}` {
+		t.Errorf("Wrong StopVisit ObjectIDs: %v", stopVisit[3])
+	}
+	if stopVisit[4] != stopAreas["FR:Quay:2:LOC"][1] || stopVisit[5] != vehicleJourney[1] || stopVisit[6] != "2" {
+		t.Errorf("Wrong StopVisit record: %v", stopVisit)
+	}
+	if records[STOP_VISIT][0][8] != `{"DestinationDisplay":"Terminus"}` {
+		t.Errorf("Wrong StopVisit Attributes: %v", records[STOP_VISIT][0][8])
+	}
+
+	var schedules []StopVisitSchedule
+	if err = json.Unmarshal([]byte(stopVisit[7]), &schedules); err != nil {
+		t.Fatal(err)
+	}
+	expectedDeparture := time.Date(2017, time.January, 2, 0, 6, 0, 0, time.Local)
+	if len(schedules) != 1 || schedules[0].Kind() != STOP_VISIT_SCHEDULE_AIMED || !schedules[0].DepartureTime().Equal(expectedDeparture) {
+		t.Errorf("Wrong StopVisit Schedules:\n got: %v\n want: aimed departure at %v", stopVisit[7], expectedDeparture)
+	}
+}
+
+func Test_NewNeTExLoader_InvalidModelName(t *testing.T) {
+	if _, err := NewNeTExLoader("referential", "test", "internal", false, false); err == nil {
+		t.Errorf("NewNeTExLoader should return an error with an invalid model name")
+	}
+}
@@ -23,6 +23,7 @@ type Model interface {
 	VehicleJourneys() VehicleJourneys
 	Operators() Operators
 	Vehicles() Vehicles
+	History() *History
 }
 
 type MemoryModel struct {
@@ -37,14 +38,19 @@ type MemoryModel struct {
 	situations      *MemorySituations
 	operators       *MemoryOperators
 
+	history *History
+
 	SMEventsChan chan StopMonitoringBroadcastEvent
 	GMEventsChan chan GeneralMessageBroadcastEvent
+	// Only used by the model streams
+	StreamEventsChan chan StopMonitoringBroadcastEvent
 }
 
 // Optionnal argument for tests
 func NewMemoryModel(referential ...string) *MemoryModel {
 	model := &MemoryModel{
-		date: NewDate(clock.DefaultClock().Now()),
+		date:    NewDate(clock.DefaultClock().Now()),
+		history: NewHistory(DEFAULT_HISTORY_MAX_ENTRIES, DEFAULT_HISTORY_MAX_ENTRIES_BY_OBJECT),
 	}
 
 	if len(referential) != 0 {
@@ -73,6 +79,7 @@ func NewMemoryModel(referential ...string) *MemoryModel {
 	vehicleJourneys := NewMemoryVehicleJourneys()
 	vehicleJourneys.model = model
 	model.vehicleJourneys = vehicleJourneys
+	model.vehicleJourneys.broadcastEvent = model.broadcastStreamEvent
 
 	operators := NewMemoryOperators()
 	operators.model = model
@@ -81,6 +88,7 @@ func NewMemoryModel(referential ...string) *MemoryModel {
 	vehicles := NewMemoryVehicles()
 	vehicles.model = model
 	model.vehicles = vehicles
+	model.vehicles.broadcastEvent = model.broadcastSMEvent
 
 	return model
 }
@@ -93,6 +101,10 @@ func (model *MemoryModel) SetBroadcastGMChan(broadcastGMEventChan chan GeneralMe
 	model.GMEventsChan = broadcastGMEventChan
 }
 
+func (model *MemoryModel) SetBroadcastStreamChan(broadcastStreamEventChan chan StopMonitoringBroadcastEvent) {
+	model.StreamEventsChan = broadcastStreamEventChan
+}
+
 func (model *MemoryModel) Referential() string {
 	return model.referential
 }
@@ -109,6 +121,17 @@ func (model *MemoryModel) broadcastSMEvent(event StopMonitoringBroadcastEvent) {
 	}
 }
 
+func (model *MemoryModel) broadcastStreamEvent(event StopMonitoringBroadcastEvent) {
+	if model.StreamEventsChan == nil {
+		return
+	}
+	select {
+	case model.StreamEventsChan <- event:
+	default:
+		logger.Log.Debugf("BrocasterManager model stream event queue is full")
+	}
+}
+
 func (model *MemoryModel) broadcastGMEvent(event GeneralMessageBroadcastEvent) {
 	select {
 	case model.GMEventsChan <- event:
@@ -167,6 +190,10 @@ func (model *MemoryModel) Vehicles() Vehicles {
 	return model.vehicles
 }
 
+func (model *MemoryModel) History() *History {
+	return model.history
+}
+
 func (model *MemoryModel) NewTransaction() *Transaction {
 	return NewTransaction(model)
 }
@@ -17,6 +17,11 @@ type Message struct {
 	NumberOfCharPerLine int    `json:",omitempty"`
 }
 
+type TimeRange struct {
+	StartTime time.Time
+	EndTime   time.Time
+}
+
 type Situation struct {
 	ObjectIDConsumer
 
@@ -35,6 +40,15 @@ type Situation struct {
 	Channel     string `json:",omitempty"`
 	ProducerRef string `json:",omitempty"`
 	Version     int    `json:",omitempty"`
+
+	// SituationExchange attributes
+	Summary            string `json:",omitempty"`
+	Description        string `json:",omitempty"`
+	Reason             string `json:",omitempty"`
+	Severity           string `json:",omitempty"`
+	ValidityPeriods    []*TimeRange
+	PublicationWindows []*TimeRange
+	Affects            []*Reference
 }
 
 func NewSituation(model Model) *Situation {
@@ -88,13 +102,16 @@ func (situation *Situation) UnmarshalJSON(data []byte) error {
 func (situation *Situation) MarshalJSON() ([]byte, error) {
 	type Alias Situation
 	aux := struct {
-		Id           SituationId
-		ObjectIDs    ObjectIDs     `json:",omitempty"`
-		RecordedAt   *time.Time    `json:",omitempty"`
-		ValidUntil   *time.Time    `json:",omitempty"`
-		Messages     []*Message    `json:",omitempty"`
-		References   []*Reference  `json:",omitempty"`
-		LineSections []*References `json:",omitempty"`
+		Id                 SituationId
+		ObjectIDs          ObjectIDs     `json:",omitempty"`
+		RecordedAt         *time.Time    `json:",omitempty"`
+		ValidUntil         *time.Time    `json:",omitempty"`
+		Messages           []*Message    `json:",omitempty"`
+		References         []*Reference  `json:",omitempty"`
+		LineSections       []*References `json:",omitempty"`
+		ValidityPeriods    []*TimeRange  `json:",omitempty"`
+		PublicationWindows []*TimeRange  `json:",omitempty"`
+		Affects            []*Reference  `json:",omitempty"`
 		*Alias
 	}{
 		Id:    situation.id,
@@ -113,6 +130,15 @@ func (situation *Situation) MarshalJSON() ([]byte, error) {
 	if len(situation.LineSections) != 0 {
 		aux.LineSections = situation.LineSections
 	}
+	if len(situation.ValidityPeriods) != 0 {
+		aux.ValidityPeriods = situation.ValidityPeriods
+	}
+	if len(situation.PublicationWindows) != 0 {
+		aux.PublicationWindows = situation.PublicationWindows
+	}
+	if len(situation.Affects) != 0 {
+		aux.Affects = situation.Affects
+	}
 	if !situation.RecordedAt.IsZero() {
 		aux.RecordedAt = &situation.RecordedAt
 	}
@@ -140,6 +166,7 @@ type Situations interface {
 	Find(id SituationId) (Situation, bool)
 	FindByObjectId(objectid ObjectID) (Situation, bool)
 	FindAll() []Situation
+	Size() int
 	Save(situation *Situation) bool
 	Delete(situation *Situation) bool
 }
@@ -181,6 +208,13 @@ func (manager *MemorySituations) FindAll() (situations []Situation) {
 	return
 }
 
+func (manager *MemorySituations) Size() (size int) {
+	manager.mutex.RLock()
+	size = len(manager.byIdentifier)
+	manager.mutex.RUnlock()
+	return
+}
+
 func (manager *MemorySituations) FindByObjectId(objectid ObjectID) (Situation, bool) {
 	manager.mutex.RLock()
 	defer manager.mutex.RUnlock()
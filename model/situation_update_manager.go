@@ -62,6 +62,14 @@ func (updater *SituationUpdater) Update() {
 		situation.Channel = event.SituationAttributes.Channel
 		situation.Format = event.SituationAttributes.Format
 
+		situation.Summary = event.SituationAttributes.Summary
+		situation.Description = event.SituationAttributes.Description
+		situation.Reason = event.SituationAttributes.Reason
+		situation.Severity = event.SituationAttributes.Severity
+		situation.ValidityPeriods = event.SituationAttributes.ValidityPeriods
+		situation.PublicationWindows = event.SituationAttributes.PublicationWindows
+		situation.Affects = event.SituationAttributes.Affects
+
 		situation.Save()
 	}
 }
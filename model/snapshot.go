@@ -0,0 +1,180 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Live state of a MemoryModel, used to warm start a referential
+type modelSnapshot struct {
+	Date Date
+
+	StopAreas       []json.RawMessage `json:",omitempty"`
+	Lines           []json.RawMessage `json:",omitempty"`
+	LineOrigins     map[LineId]string `json:",omitempty"`
+	VehicleJourneys []json.RawMessage `json:",omitempty"`
+	StopVisits      []json.RawMessage `json:",omitempty"`
+	Vehicles        []json.RawMessage `json:",omitempty"`
+	Situations      []json.RawMessage `json:",omitempty"`
+}
+
+func (model *MemoryModel) WriteSnapshot(writer io.Writer) error {
+	snapshot := &modelSnapshot{
+		Date:        model.date,
+		LineOrigins: make(map[LineId]string),
+	}
+
+	var err error
+	stopAreas := model.stopAreas.FindAll()
+	for i := range stopAreas {
+		if snapshot.StopAreas, err = appendSnapshot(snapshot.StopAreas, &stopAreas[i]); err != nil {
+			return err
+		}
+	}
+	lines := model.lines.FindAll()
+	for i := range lines {
+		if snapshot.Lines, err = appendSnapshot(snapshot.Lines, &lines[i]); err != nil {
+			return err
+		}
+		if lines[i].Origin() != "" {
+			snapshot.LineOrigins[lines[i].Id()] = lines[i].Origin()
+		}
+	}
+	vehicleJourneys := model.vehicleJourneys.FindAll()
+	for i := range vehicleJourneys {
+		if snapshot.VehicleJourneys, err = appendSnapshot(snapshot.VehicleJourneys, &vehicleJourneys[i]); err != nil {
+			return err
+		}
+	}
+	stopVisits := model.stopVisits.FindAll()
+	for i := range stopVisits {
+		if snapshot.StopVisits, err = appendSnapshot(snapshot.StopVisits, &stopVisits[i]); err != nil {
+			return err
+		}
+	}
+	vehicles := model.vehicles.FindAll()
+	for i := range vehicles {
+		if snapshot.Vehicles, err = appendSnapshot(snapshot.Vehicles, &vehicles[i]); err != nil {
+			return err
+		}
+	}
+	situations := model.situations.FindAll()
+	for i := range situations {
+		if snapshot.Situations, err = appendSnapshot(snapshot.Situations, &situations[i]); err != nil {
+			return err
+		}
+	}
+
+	return json.NewEncoder(writer).Encode(snapshot)
+}
+
+func appendSnapshot(objects []json.RawMessage, object json.Marshaler) ([]json.RawMessage, error) {
+	data, err := object.MarshalJSON()
+	if err != nil {
+		return objects, err
+	}
+	return append(objects, data), nil
+}
+
+// Restores the objects of the snapshot in the model. Returns false when the
+// snapshot has been taken for another model Date.
+func (model *MemoryModel) ReadSnapshot(reader io.Reader) (bool, error) {
+	snapshot := &modelSnapshot{}
+	if err := json.NewDecoder(reader).Decode(snapshot); err != nil {
+		return false, err
+	}
+	if snapshot.Date != model.date {
+		return false, nil
+	}
+
+	for _, data := range snapshot.StopAreas {
+		stopArea := NewStopArea(model)
+		aux := struct {
+			Id            StopAreaId
+			NextCollectAt time.Time
+			CollectedAt   time.Time
+		}{}
+		if err := unmarshalSnapshot(data, stopArea, &aux); err != nil {
+			return false, err
+		}
+		stopArea.id = aux.Id
+		stopArea.nextCollectAt = aux.NextCollectAt
+		stopArea.collectedAt = aux.CollectedAt
+		model.stopAreas.Save(stopArea)
+	}
+
+	for _, data := range snapshot.Lines {
+		line := NewLine(model)
+		aux := struct {
+			Id            LineId
+			NextCollectAt time.Time
+			CollectedAt   time.Time
+		}{}
+		if err := unmarshalSnapshot(data, line, &aux); err != nil {
+			return false, err
+		}
+		line.id = aux.Id
+		line.nextCollectAt = aux.NextCollectAt
+		line.collectedAt = aux.CollectedAt
+		line.origin = snapshot.LineOrigins[aux.Id]
+		model.lines.Save(line)
+	}
+
+	for _, data := range snapshot.VehicleJourneys {
+		vehicleJourney := NewVehicleJourney(model)
+		aux := struct{ Id VehicleJourneyId }{}
+		if err := unmarshalSnapshot(data, vehicleJourney, &aux); err != nil {
+			return false, err
+		}
+		vehicleJourney.id = aux.Id
+		model.vehicleJourneys.Save(vehicleJourney)
+	}
+
+	for _, data := range snapshot.StopVisits {
+		stopVisit := NewStopVisit(model)
+		aux := struct {
+			Id          StopVisitId
+			Collected   bool
+			CollectedAt time.Time
+		}{}
+		if err := unmarshalSnapshot(data, stopVisit, &aux); err != nil {
+			return false, err
+		}
+		stopVisit.id = aux.Id
+		stopVisit.collected = aux.Collected
+		stopVisit.collectedAt = aux.CollectedAt
+		model.stopVisits.Save(stopVisit)
+	}
+
+	for _, data := range snapshot.Vehicles {
+		vehicle := NewVehicle(model)
+		aux := struct{ Id VehicleId }{}
+		if err := unmarshalSnapshot(data, vehicle, &aux); err != nil {
+			return false, err
+		}
+		vehicle.id = aux.Id
+		model.vehicles.Save(vehicle)
+	}
+
+	for _, data := range snapshot.Situations {
+		situation := NewSituation(model)
+		aux := struct{ Id SituationId }{}
+		if err := unmarshalSnapshot(data, situation, &aux); err != nil {
+			return false, err
+		}
+		situation.id = aux.Id
+		model.situations.Save(situation)
+	}
+
+	return true, nil
+}
+
+// The objects UnmarshalJSON ignore their unexported attributes, read
+// separately in aux
+func unmarshalSnapshot(data json.RawMessage, object json.Unmarshaler, aux interface{}) error {
+	if err := object.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, aux)
+}
@@ -0,0 +1,134 @@
+package model
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func Test_MemoryModel_Snapshot(t *testing.T) {
+	collectedAt := time.Date(2017, time.January, 1, 12, 0, 0, 0, time.UTC)
+	model := NewMemoryModel()
+
+	stopArea := model.StopAreas().New()
+	stopArea.SetObjectID(NewObjectID("kind", "stopArea"))
+	stopArea.SetPartnerStatus("partner", true)
+	stopArea.Updated(collectedAt)
+	stopArea.Save()
+
+	line := model.Lines().New()
+	line.SetOrigin("partner")
+	line.Updated(collectedAt)
+	line.Save()
+
+	vehicleJourney := model.VehicleJourneys().New()
+	vehicleJourney.LineId = line.Id()
+	vehicleJourney.Save()
+
+	stopVisit := model.StopVisits().New()
+	stopVisit.StopAreaId = stopArea.Id()
+	stopVisit.VehicleJourneyId = vehicleJourney.Id()
+	stopVisit.ArrivalStatus = STOP_VISIT_ARRIVAL_DELAYED
+	stopVisit.Schedules.SetArrivalTime(STOP_VISIT_SCHEDULE_AIMED, collectedAt)
+	stopVisit.Schedules.SetArrivalTime(STOP_VISIT_SCHEDULE_EXPECTED, collectedAt.Add(5*time.Minute))
+	stopVisit.Collected(collectedAt)
+	stopVisit.Save()
+
+	vehicle := model.Vehicles().New()
+	vehicle.VehicleJourneyId = vehicleJourney.Id()
+	vehicle.Latitude = 48.8
+	vehicle.Save()
+
+	situation := model.Situations().New()
+	situation.Messages = []*Message{{Content: "message"}}
+	situation.Save()
+
+	var buffer bytes.Buffer
+	if err := model.WriteSnapshot(&buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	restoredModel := NewMemoryModel()
+	restored, err := restoredModel.ReadSnapshot(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !restored {
+		t.Fatal("Snapshot should be restored")
+	}
+
+	restoredStopArea, ok := restoredModel.StopAreas().FindByObjectId(NewObjectID("kind", "stopArea"))
+	if !ok {
+		t.Fatal("StopArea should be restored")
+	}
+	if restoredStopArea.Id() != stopArea.Id() {
+		t.Errorf("Wrong StopArea Id:\n got: %v\n want: %v", restoredStopArea.Id(), stopArea.Id())
+	}
+	if !restoredStopArea.CollectedAt().Equal(collectedAt) {
+		t.Errorf("Wrong StopArea CollectedAt:\n got: %v\n want: %v", restoredStopArea.CollectedAt(), collectedAt)
+	}
+	if status, _ := restoredStopArea.Origins.Origin("partner"); !status {
+		t.Errorf("StopArea Origins should be restored")
+	}
+
+	restoredLine, ok := restoredModel.Lines().Find(line.Id())
+	if !ok {
+		t.Fatal("Line should be restored")
+	}
+	if restoredLine.Origin() != "partner" || !restoredLine.CollectedAt().Equal(collectedAt) {
+		t.Errorf("Wrong Line Origin or CollectedAt: %v %v", restoredLine.Origin(), restoredLine.CollectedAt())
+	}
+
+	if _, ok := restoredModel.VehicleJourneys().Find(vehicleJourney.Id()); !ok {
+		t.Error("VehicleJourney should be restored")
+	}
+
+	restoredStopVisits := restoredModel.StopVisits().FindByVehicleJourneyId(vehicleJourney.Id())
+	if len(restoredStopVisits) != 1 {
+		t.Fatalf("StopVisit should be restored, got %v", len(restoredStopVisits))
+	}
+	restoredStopVisit := &restoredStopVisits[0]
+	if !restoredStopVisit.IsCollected() || !restoredStopVisit.CollectedAt().Equal(collectedAt) {
+		t.Errorf("StopVisit should be collected at %v", collectedAt)
+	}
+	if restoredStopVisit.ArrivalStatus != STOP_VISIT_ARRIVAL_DELAYED {
+		t.Errorf("Wrong StopVisit ArrivalStatus: %v", restoredStopVisit.ArrivalStatus)
+	}
+	if expected := collectedAt.Add(5 * time.Minute); !restoredStopVisit.Schedules.Schedule(STOP_VISIT_SCHEDULE_EXPECTED).ArrivalTime().Equal(expected) {
+		t.Errorf("Wrong StopVisit expected ArrivalTime:\n got: %v\n want: %v", restoredStopVisit.Schedules.Schedule(STOP_VISIT_SCHEDULE_EXPECTED).ArrivalTime(), expected)
+	}
+
+	restoredVehicle, ok := restoredModel.Vehicles().Find(vehicle.Id())
+	if !ok || restoredVehicle.Latitude != 48.8 {
+		t.Error("Vehicle should be restored")
+	}
+
+	restoredSituation, ok := restoredModel.Situations().Find(situation.Id())
+	if !ok || len(restoredSituation.Messages) != 1 || restoredSituation.Messages[0].Content != "message" {
+		t.Error("Situation should be restored")
+	}
+}
+
+func Test_MemoryModel_ReadSnapshot_OtherDate(t *testing.T) {
+	model := NewMemoryModel()
+	stopArea := model.StopAreas().New()
+	stopArea.Save()
+
+	var buffer bytes.Buffer
+	if err := model.WriteSnapshot(&buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	restoredModel := NewMemoryModel()
+	restoredModel.date = Date{Year: 2017, Month: time.January, Day: 1}
+	restored, err := restoredModel.ReadSnapshot(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored {
+		t.Error("Snapshot of another Date shouldn't be restored")
+	}
+	if size := restoredModel.StopAreas().Size(); size != 0 {
+		t.Errorf("Model shouldn't contain restored StopAreas, got %v", size)
+	}
+}
@@ -214,6 +214,7 @@ type MemoryStopAreas struct {
 	mutex        *sync.RWMutex
 	byIdentifier map[StopAreaId]*StopArea
 	byObjectId   *ObjectIdIndex
+	byReferent   *Index
 
 	broadcastEvent func(event StopMonitoringBroadcastEvent)
 }
@@ -225,8 +226,10 @@ type StopAreas interface {
 	Find(id StopAreaId) (StopArea, bool)
 	FindByObjectId(objectid ObjectID) (StopArea, bool)
 	FindByLineId(id LineId) []StopArea
+	FindByReferentId(id StopAreaId) []StopArea
 	FindByOrigin(origin string) []StopAreaId
 	FindAll() []StopArea
+	Size() int
 	FindFamily(id StopAreaId) []StopAreaId
 	FindAscendants(id StopAreaId) (stopAreas []StopArea)
 	FindAscendantsWithObjectIdKind(stopAreaId StopAreaId, kind string) (stopAreaIds []ObjectID)
@@ -235,10 +238,13 @@ type StopAreas interface {
 }
 
 func NewMemoryStopAreas() *MemoryStopAreas {
+	extractor := func(instance ModelInstance) ModelId { return ModelId((instance.(*StopArea)).ReferentId) }
+
 	return &MemoryStopAreas{
 		mutex:        &sync.RWMutex{},
 		byIdentifier: make(map[StopAreaId]*StopArea),
 		byObjectId:   NewObjectIdIndex(),
+		byReferent:   NewIndex(extractor),
 	}
 }
 
@@ -298,6 +304,18 @@ func (manager *MemoryStopAreas) FindByLineId(id LineId) (stopAreas []StopArea) {
 	return
 }
 
+func (manager *MemoryStopAreas) FindByReferentId(id StopAreaId) (stopAreas []StopArea) {
+	manager.mutex.RLock()
+
+	ids, _ := manager.byReferent.Find(ModelId(id))
+	for _, referringId := range ids {
+		stopAreas = append(stopAreas, *(manager.byIdentifier[StopAreaId(referringId)].copy()))
+	}
+
+	manager.mutex.RUnlock()
+	return
+}
+
 func (manager *MemoryStopAreas) FindByOrigin(origin string) (stopAreas []StopAreaId) {
 	manager.mutex.RLock()
 
@@ -322,6 +340,13 @@ func (manager *MemoryStopAreas) FindAll() (stopAreas []StopArea) {
 	return
 }
 
+func (manager *MemoryStopAreas) Size() (size int) {
+	manager.mutex.RLock()
+	size = len(manager.byIdentifier)
+	manager.mutex.RUnlock()
+	return
+}
+
 func (manager *MemoryStopAreas) Save(stopArea *StopArea) bool {
 	if stopArea.Id() == "" {
 		stopArea.id = StopAreaId(manager.NewUUID())
@@ -332,6 +357,7 @@ func (manager *MemoryStopAreas) Save(stopArea *StopArea) bool {
 	stopArea.model = manager.model
 	manager.byIdentifier[stopArea.Id()] = stopArea
 	manager.byObjectId.Index(stopArea)
+	manager.byReferent.Index(stopArea)
 
 	manager.mutex.Unlock()
 
@@ -353,6 +379,7 @@ func (manager *MemoryStopAreas) Delete(stopArea *StopArea) bool {
 
 	delete(manager.byIdentifier, stopArea.Id())
 	manager.byObjectId.Delete(ModelId(stopArea.id))
+	manager.byReferent.Delete(ModelId(stopArea.id))
 
 	return true
 }
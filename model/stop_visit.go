@@ -11,9 +11,10 @@ import (
 	"bitbucket.org/enroute-mobi/ara/uuid"
 )
 
-var SCHEDULE_ORDER_MAP = [3]StopVisitScheduleType{
+var SCHEDULE_ORDER_MAP = [4]StopVisitScheduleType{
 	STOP_VISIT_SCHEDULE_ACTUAL,
 	STOP_VISIT_SCHEDULE_EXPECTED,
+	STOP_VISIT_SCHEDULE_PREDICTED,
 	STOP_VISIT_SCHEDULE_AIMED,
 }
 
@@ -222,6 +223,24 @@ func (stopVisit *StopVisit) ReferenceDepartureTime() time.Time {
 	return time.Time{}
 }
 
+// Returns the expected arrival time, or the predicted one when no expected
+// arrival time has been collected
+func (stopVisit *StopVisit) ExpectedOrPredictedArrivalTime() time.Time {
+	if t := stopVisit.Schedules.Schedule(STOP_VISIT_SCHEDULE_EXPECTED).ArrivalTime(); !t.IsZero() {
+		return t
+	}
+	return stopVisit.Schedules.Schedule(STOP_VISIT_SCHEDULE_PREDICTED).ArrivalTime()
+}
+
+// Returns the expected departure time, or the predicted one when no expected
+// departure time has been collected
+func (stopVisit *StopVisit) ExpectedOrPredictedDepartureTime() time.Time {
+	if t := stopVisit.Schedules.Schedule(STOP_VISIT_SCHEDULE_EXPECTED).DepartureTime(); !t.IsZero() {
+		return t
+	}
+	return stopVisit.Schedules.Schedule(STOP_VISIT_SCHEDULE_PREDICTED).DepartureTime()
+}
+
 type ByTime []StopVisit
 
 func (a ByTime) Len() int           { return len(a) }
@@ -256,6 +275,7 @@ type StopVisits interface {
 	FindFollowingByStopAreaId(StopAreaId) []StopVisit
 	FindFollowingByStopAreaIds([]StopAreaId) []StopVisit
 	FindAll() []StopVisit
+	Size() int
 	FindAllAfter(time.Time) []StopVisit
 	Save(*StopVisit) bool
 	Delete(*StopVisit) bool
@@ -413,6 +433,13 @@ func (manager *MemoryStopVisits) FindAll() (stopVisits []StopVisit) {
 	return
 }
 
+func (manager *MemoryStopVisits) Size() (size int) {
+	manager.mutex.RLock()
+	size = len(manager.byIdentifier)
+	manager.mutex.RUnlock()
+	return
+}
+
 func (manager *MemoryStopVisits) FindAllAfter(t time.Time) (stopVisits []StopVisit) {
 	manager.mutex.RLock()
 
@@ -12,9 +12,11 @@ const (
 	STOP_VISIT_SCHEDULE_AIMED    StopVisitScheduleType = "aimed"
 	STOP_VISIT_SCHEDULE_EXPECTED StopVisitScheduleType = "expected"
 	STOP_VISIT_SCHEDULE_ACTUAL   StopVisitScheduleType = "actual"
+	// Computed by Ara from the delays observed on the previous StopVisits
+	STOP_VISIT_SCHEDULE_PREDICTED StopVisitScheduleType = "predicted"
 )
 
-var stopVisitScheduleTypes = [3]StopVisitScheduleType{STOP_VISIT_SCHEDULE_AIMED, STOP_VISIT_SCHEDULE_EXPECTED, STOP_VISIT_SCHEDULE_ACTUAL}
+var stopVisitScheduleTypes = [4]StopVisitScheduleType{STOP_VISIT_SCHEDULE_AIMED, STOP_VISIT_SCHEDULE_EXPECTED, STOP_VISIT_SCHEDULE_ACTUAL, STOP_VISIT_SCHEDULE_PREDICTED}
 
 type StopVisitSchedule struct {
 	kind          StopVisitScheduleType
@@ -197,6 +199,12 @@ func (schedules *StopVisitSchedules) SetSchedule(kind StopVisitScheduleType, dep
 	schedules.Unlock()
 }
 
+func (schedules *StopVisitSchedules) DeleteSchedule(kind StopVisitScheduleType) {
+	schedules.Lock()
+	delete(schedules.byType, kind)
+	schedules.Unlock()
+}
+
 func (schedules *StopVisitSchedules) Schedule(kind StopVisitScheduleType) *StopVisitSchedule {
 	schedules.RLock()
 	schedule, ok := schedules.byType[kind]
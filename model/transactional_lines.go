@@ -44,6 +44,22 @@ func (manager *TransactionalLines) FindByObjectId(objectid ObjectID) (Line, bool
 	return manager.model.Lines().FindByObjectId(objectid)
 }
 
+func (manager *TransactionalLines) FindByReferentId(id LineId) (lines []Line) {
+	for _, line := range manager.saved {
+		if line.ReferentId == id {
+			lines = append(lines, *line)
+		}
+	}
+
+	for _, modelLine := range manager.model.Lines().FindByReferentId(id) {
+		_, ok := manager.saved[modelLine.Id()]
+		if !ok {
+			lines = append(lines, modelLine)
+		}
+	}
+	return
+}
+
 func (manager *TransactionalLines) FindAll() []Line {
 	lines := []Line{}
 	for _, line := range manager.saved {
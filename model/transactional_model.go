@@ -64,6 +64,10 @@ func (model *TransactionalModel) Vehicles() Vehicles {
 	return model.vehicles
 }
 
+func (model *TransactionalModel) History() *History {
+	return model.parent.History()
+}
+
 func (model *TransactionalModel) NewTransaction() *Transaction {
 	return NewTransaction(model)
 }
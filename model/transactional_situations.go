@@ -59,6 +59,10 @@ func (manager *TransactionalSituations) FindAll() []Situation {
 	return situations
 }
 
+func (manager *TransactionalSituations) Size() int {
+	return len(manager.FindAll())
+}
+
 func (manager *TransactionalSituations) Save(situation *Situation) bool {
 	if situation.Id() == "" {
 		situation.id = SituationId(manager.NewUUID())
@@ -63,6 +63,23 @@ func (manager *TransactionalStopAreas) FindByLineId(id LineId) (stopAreas []Stop
 	return
 }
 
+func (manager *TransactionalStopAreas) FindByReferentId(id StopAreaId) (stopAreas []StopArea) {
+	for _, stopArea := range manager.saved {
+		if stopArea.ReferentId == id {
+			stopAreas = append(stopAreas, *(stopArea.copy()))
+		}
+	}
+
+	modelStopAreas := manager.model.StopAreas().FindByReferentId(id)
+	for i := range modelStopAreas {
+		_, ok := manager.saved[modelStopAreas[i].Id()]
+		if !ok {
+			stopAreas = append(stopAreas, *(modelStopAreas[i].copy()))
+		}
+	}
+	return
+}
+
 func (manager *TransactionalStopAreas) FindAll() []StopArea {
 	stopAreas := []StopArea{}
 	for _, savedStopArea := range manager.saved {
@@ -78,6 +95,10 @@ func (manager *TransactionalStopAreas) FindAll() []StopArea {
 	return stopAreas
 }
 
+func (manager *TransactionalStopAreas) Size() int {
+	return len(manager.FindAll())
+}
+
 func (manager *TransactionalStopAreas) FindByOrigin(origin string) (stopAreaIds []StopAreaId) {
 	for _, stopAreaId := range manager.model.StopAreas().FindByOrigin(origin) {
 		_, ok := manager.deleted[stopAreaId]
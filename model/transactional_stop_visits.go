@@ -165,6 +165,10 @@ func (manager *TransactionalStopVisits) FindAll() (stopVisits []StopVisit) {
 	return
 }
 
+func (manager *TransactionalStopVisits) Size() int {
+	return len(manager.FindAll())
+}
+
 func (manager *TransactionalStopVisits) Save(stopVisit *StopVisit) bool {
 	if stopVisit.Id() == "" {
 		stopVisit.id = StopVisitId(manager.NewUUID())
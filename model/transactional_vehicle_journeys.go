@@ -77,6 +77,10 @@ func (manager *TransactionalVehicleJourneys) FindAll() []VehicleJourney {
 	return vehicleJourneys
 }
 
+func (manager *TransactionalVehicleJourneys) Size() int {
+	return len(manager.FindAll())
+}
+
 func (manager *TransactionalVehicleJourneys) Save(vehicleJourney *VehicleJourney) bool {
 	if vehicleJourney.Id() == "" {
 		vehicleJourney.id = VehicleJourneyId(manager.NewUUID())
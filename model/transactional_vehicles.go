@@ -77,6 +77,10 @@ func (manager *TransactionalVehicles) FindAll() []Vehicle {
 	return vehicles
 }
 
+func (manager *TransactionalVehicles) Size() int {
+	return len(manager.FindAll())
+}
+
 func (manager *TransactionalVehicles) Save(vehicle *Vehicle) bool {
 	if vehicle.Id() == "" {
 		vehicle.id = VehicleId(manager.NewUUID())
@@ -12,6 +12,26 @@ const (
 	VEHICLE_EVENT
 )
 
+func (kind EventKind) String() string {
+	switch kind {
+	case STOP_AREA_EVENT:
+		return "StopArea"
+	case STATUS_EVENT:
+		return "Status"
+	case LINE_EVENT:
+		return "Line"
+	case VEHICLE_JOURNEY_EVENT:
+		return "VehicleJourney"
+	case STOP_VISIT_EVENT:
+		return "StopVisit"
+	case NOT_COLLECTED_EVENT:
+		return "NotCollected"
+	case VEHICLE_EVENT:
+		return "Vehicle"
+	}
+	return "Unknown"
+}
+
 type UpdateEvent interface {
 	EventKind() EventKind
 }
@@ -151,9 +151,14 @@ func (manager *UpdateManager) updateVehicleJourney(event *VehicleJourneyUpdateEv
 	}
 
 	vj.Monitored = event.Monitored
+	if event.FlagsDefined {
+		vj.Cancellation = event.Cancellation
+		vj.ExtraJourney = event.ExtraJourney
+	}
 
 	tx.Model().VehicleJourneys().Save(&vj)
 	tx.Commit()
+	tx.Model().History().RecordVehicleJourney(&vj, event.Origin, manager.Clock().Now())
 	tx.Close()
 }
 
@@ -228,6 +233,7 @@ func (manager *UpdateManager) updateStopVisit(event *StopVisitUpdateEvent) {
 
 	tx.Model().StopVisits().Save(&sv)
 	tx.Commit()
+	tx.Model().History().RecordStopVisit(&sv, event.Origin, manager.Clock().Now())
 	tx.Close()
 }
 
@@ -249,6 +255,9 @@ func (manager *UpdateManager) updateVehicle(event *VehicleUpdateEvent) {
 	vehicle.Latitude = event.Latitude
 	vehicle.Bearing = event.Bearing
 	vehicle.RecordedAtTime = manager.Clock().Now()
+	if !event.RecordedAt.IsZero() {
+		vehicle.RecordedAtTime = event.RecordedAt
+	}
 
 	if line != nil {
 		vehicle.LineId = line.Id()
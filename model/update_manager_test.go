@@ -1,6 +1,9 @@
 package model
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func Test_UpdateManager_UpdateStopVisit(t *testing.T) {
 	model := NewMemoryModel()
@@ -49,6 +52,87 @@ func Test_UpdateManager_UpdateStopVisit(t *testing.T) {
 	}
 }
 
+func Test_UpdateManager_UpdateVehicle(t *testing.T) {
+	model := NewMemoryModel()
+	objectid := NewObjectID("kind", "value")
+
+	l := model.Lines().New()
+	l.SetObjectID(objectid)
+	l.Save()
+
+	vj := model.VehicleJourneys().New()
+	vj.SetObjectID(objectid)
+	vj.LineId = l.Id()
+	vj.Save()
+
+	manager := newUpdateManager(model)
+
+	recordedAt := time.Date(2017, time.January, 1, 12, 0, 0, 0, time.UTC)
+	event := &VehicleUpdateEvent{
+		ObjectId:               objectid,
+		VehicleJourneyObjectId: objectid,
+		Longitude:              2.35,
+		Latitude:               48.85,
+		Bearing:                90,
+		RecordedAt:             recordedAt,
+	}
+
+	manager.Update(event)
+	vehicle, ok := model.Vehicles().FindByObjectId(objectid)
+	if !ok {
+		t.Fatalf("Vehicle should be created")
+	}
+	if vehicle.VehicleJourneyId != vj.Id() || vehicle.LineId != l.Id() {
+		t.Errorf("Vehicle should be linked to the VehicleJourney and its Line")
+	}
+	if !vehicle.RecordedAtTime.Equal(recordedAt) {
+		t.Errorf("Wrong Vehicle RecordedAtTime:\n got: %v\n want: %v", vehicle.RecordedAtTime, recordedAt)
+	}
+}
+
+func Test_UpdateManager_UpdateVehicleJourneyCancellation(t *testing.T) {
+	model := NewMemoryModel()
+	manager := newUpdateManager(model)
+
+	objectid := NewObjectID("kind", "value")
+	l := model.Lines().New()
+	l.SetObjectID(objectid)
+	l.Save()
+
+	event := &VehicleJourneyUpdateEvent{
+		ObjectId:     objectid,
+		LineObjectId: objectid,
+		FlagsDefined: true,
+		Cancellation: true,
+		ExtraJourney: true,
+	}
+	manager.Update(event)
+
+	vj, _ := model.VehicleJourneys().FindByObjectId(objectid)
+	if !vj.Cancellation || !vj.ExtraJourney {
+		t.Errorf("VehicleJourney should be a cancelled extra journey, got Cancellation: %v and ExtraJourney: %v", vj.Cancellation, vj.ExtraJourney)
+	}
+
+	// Like a vehicle position
+	manager.Update(&VehicleJourneyUpdateEvent{
+		ObjectId:     objectid,
+		LineObjectId: objectid,
+	})
+
+	vj, _ = model.VehicleJourneys().FindByObjectId(objectid)
+	if !vj.Cancellation || !vj.ExtraJourney {
+		t.Errorf("VehicleJourney flags should be kept when the event doesn't define them")
+	}
+
+	event.Cancellation = false
+	manager.Update(event)
+
+	vj, _ = model.VehicleJourneys().FindByObjectId(objectid)
+	if vj.Cancellation {
+		t.Errorf("VehicleJourney cancellation should be updated")
+	}
+}
+
 func Test_UpdateManager_UpdateStatus(t *testing.T) {
 	model := NewMemoryModel()
 	manager := newUpdateManager(model)
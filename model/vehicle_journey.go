@@ -3,6 +3,7 @@ package model
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"sync"
 
 	"bitbucket.org/enroute-mobi/ara/uuid"
@@ -23,7 +24,9 @@ type VehicleJourney struct {
 	OriginName      string `json:",omitempty"`
 	DestinationName string `json:",omitempty"`
 
-	Monitored bool
+	Monitored    bool
+	Cancellation bool `json:",omitempty"`
+	ExtraJourney bool `json:",omitempty"`
 
 	Attributes Attributes
 	References References
@@ -153,6 +156,8 @@ type MemoryVehicleJourneys struct {
 	byIdentifier map[VehicleJourneyId]*VehicleJourney
 	byObjectId   *ObjectIdIndex
 	byLine       *Index
+
+	broadcastEvent func(event StopMonitoringBroadcastEvent)
 }
 
 type VehicleJourneys interface {
@@ -163,6 +168,7 @@ type VehicleJourneys interface {
 	FindByObjectId(objectid ObjectID) (VehicleJourney, bool)
 	FindByLineId(id LineId) []VehicleJourney
 	FindAll() []VehicleJourney
+	Size() int
 	Save(vehicleJourney *VehicleJourney) bool
 	Delete(vehicleJourney *VehicleJourney) bool
 }
@@ -233,12 +239,23 @@ func (manager *MemoryVehicleJourneys) FindAll() (vehicleJourneys []VehicleJourne
 	return
 }
 
+func (manager *MemoryVehicleJourneys) Size() (size int) {
+	manager.mutex.RLock()
+	size = len(manager.byIdentifier)
+	manager.mutex.RUnlock()
+	return
+}
+
 func (manager *MemoryVehicleJourneys) Save(vehicleJourney *VehicleJourney) bool {
 	manager.mutex.Lock()
 	defer manager.mutex.Unlock()
 
+	changed := false
 	if vehicleJourney.Id() == "" {
 		vehicleJourney.id = VehicleJourneyId(manager.NewUUID())
+		changed = true
+	} else if vj, ok := manager.byIdentifier[vehicleJourney.Id()]; ok && vj != vehicleJourney {
+		changed = vehicleJourneyChanged(vj, vehicleJourney)
 	}
 
 	vehicleJourney.model = manager.model
@@ -246,9 +263,31 @@ func (manager *MemoryVehicleJourneys) Save(vehicleJourney *VehicleJourney) bool
 	manager.byObjectId.Index(vehicleJourney)
 	manager.byLine.Index(vehicleJourney)
 
+	if changed && manager.broadcastEvent != nil {
+		manager.broadcastEvent(StopMonitoringBroadcastEvent{
+			ModelId:   string(vehicleJourney.id),
+			ModelType: "VehicleJourney",
+		})
+	}
+
 	return true
 }
 
+// Equal can't be used since References have unexported fields
+func vehicleJourneyChanged(vj1, vj2 *VehicleJourney) bool {
+	return vj1.Origin != vj2.Origin ||
+		vj1.LineId != vj2.LineId ||
+		vj1.Name != vj2.Name ||
+		vj1.OriginName != vj2.OriginName ||
+		vj1.DestinationName != vj2.DestinationName ||
+		vj1.Monitored != vj2.Monitored ||
+		vj1.Cancellation != vj2.Cancellation ||
+		vj1.ExtraJourney != vj2.ExtraJourney ||
+		!reflect.DeepEqual(vj1.ObjectIDs(), vj2.ObjectIDs()) ||
+		!reflect.DeepEqual(vj1.Attributes, vj2.Attributes) ||
+		!reflect.DeepEqual(vj1.References.GetReferences(), vj2.References.GetReferences())
+}
+
 func (manager *MemoryVehicleJourneys) Delete(vehicleJourney *VehicleJourney) bool {
 	manager.mutex.Lock()
 	defer manager.mutex.Unlock()
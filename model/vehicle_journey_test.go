@@ -212,6 +212,28 @@ func Test_MemoryVehicleJourneys_Delete(t *testing.T) {
 	}
 }
 
+func Test_MemoryVehicleJourneys_Save_BroadcastEvent(t *testing.T) {
+	vehicleJourneys := NewMemoryVehicleJourneys()
+	var events []StopMonitoringBroadcastEvent
+	vehicleJourneys.broadcastEvent = func(event StopMonitoringBroadcastEvent) {
+		events = append(events, event)
+	}
+
+	vehicleJourney := vehicleJourneys.New()
+	vehicleJourneys.Save(&vehicleJourney)
+
+	unchanged, _ := vehicleJourneys.Find(vehicleJourney.Id())
+	vehicleJourneys.Save(&unchanged)
+
+	changed, _ := vehicleJourneys.Find(vehicleJourney.Id())
+	changed.Monitored = true
+	vehicleJourneys.Save(&changed)
+
+	if len(events) != 2 {
+		t.Errorf("Only new or changed VehicleJourneys should be broadcasted, got %v events", len(events))
+	}
+}
+
 func Test_MemoryVehicleJourneys_Load(t *testing.T) {
 	InitTestDb(t)
 	defer CleanTestDb(t)
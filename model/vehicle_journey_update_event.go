@@ -15,6 +15,11 @@ type VehicleJourneyUpdateEvent struct {
 	DestinationName string
 	Direction       string
 	Monitored       bool
+	// Cancellation and ExtraJourney are ignored when the collected data
+	// doesn't define them (like in vehicle positions)
+	FlagsDefined bool
+	Cancellation bool
+	ExtraJourney bool
 
 	ObjectidKind string
 	SiriXML      *siri.XMLMonitoredStopVisit
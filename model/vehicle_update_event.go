@@ -1,11 +1,16 @@
 package model
 
+import "time"
+
 type VehicleUpdateEvent struct {
+	Origin string
+
 	ObjectId               ObjectID
 	VehicleJourneyObjectId ObjectID
 	Longitude              float64
 	Latitude               float64
 	Bearing                float64
+	RecordedAt             time.Time
 }
 
 func NewVehicleUpdateEvent() *VehicleUpdateEvent {
@@ -106,6 +106,8 @@ type MemoryVehicles struct {
 	mutex        *sync.RWMutex
 	byIdentifier map[VehicleId]*Vehicle
 	byObjectId   *ObjectIdIndex
+
+	broadcastEvent func(event StopMonitoringBroadcastEvent)
 }
 
 type Vehicles interface {
@@ -116,6 +118,7 @@ type Vehicles interface {
 	FindByObjectId(objectid ObjectID) (Vehicle, bool)
 	FindByLineId(id LineId) []Vehicle
 	FindAll() []Vehicle
+	Size() int
 	Save(vehicle *Vehicle) bool
 	Delete(vehicle *Vehicle) bool
 }
@@ -187,18 +190,28 @@ func (manager *MemoryVehicles) FindAll() (vehicles []Vehicle) {
 	return
 }
 
+func (manager *MemoryVehicles) Size() (size int) {
+	manager.mutex.RLock()
+	size = len(manager.byIdentifier)
+	manager.mutex.RUnlock()
+	return
+}
+
 func (manager *MemoryVehicles) Save(vehicle *Vehicle) bool {
 	manager.mutex.Lock()
 
+	changed := false
 	if vehicle.id == "" {
 		vehicle.id = VehicleId(manager.NewUUID())
 		manager.sendBQMessage(vehicle)
+		changed = true
 	} else if v, ok := manager.byIdentifier[vehicle.Id()]; ok {
 		r, err := Equal(v, vehicle)
 		if err != nil {
 			logger.Log.Debugf("Error while comparing two vehicles: %v", err)
 		} else if !r.Equal {
 			manager.sendBQMessage(vehicle)
+			changed = true
 		}
 	}
 
@@ -208,6 +221,13 @@ func (manager *MemoryVehicles) Save(vehicle *Vehicle) bool {
 
 	manager.mutex.Unlock()
 
+	if changed && manager.broadcastEvent != nil {
+		manager.broadcastEvent(StopMonitoringBroadcastEvent{
+			ModelId:   string(vehicle.id),
+			ModelType: "Vehicle",
+		})
+	}
+
 	return true
 }
 
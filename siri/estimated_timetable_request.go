@@ -1,9 +1,11 @@
 package siri
 
 import (
+	"bytes"
 	"strings"
 	"time"
 
+	"bitbucket.org/enroute-mobi/ara/logger"
 	"github.com/jbowtie/gokogiri"
 	"github.com/jbowtie/gokogiri/xml"
 )
@@ -23,6 +25,19 @@ type XMLEstimatedTimetableRequest struct {
 	lines []string
 }
 
+type SIRIGetEstimatedTimetableRequest struct {
+	SIRIEstimatedTimetableRequest
+
+	RequestorRef string
+}
+
+type SIRIEstimatedTimetableRequest struct {
+	MessageIdentifier string
+	Lines             []string
+
+	RequestTimestamp time.Time
+}
+
 func NewXMLGetEstimatedTimetable(node xml.Node) *XMLGetEstimatedTimetable {
 	xmlGetEstimatedTimetable := &XMLGetEstimatedTimetable{}
 	xmlGetEstimatedTimetable.node = NewXMLNode(node)
@@ -68,3 +83,35 @@ func (request *XMLEstimatedTimetableRequest) StartTime() time.Time {
 	}
 	return request.startTime
 }
+
+func NewSIRIGetEstimatedTimetableRequest(
+	messageIdentifier string,
+	lines []string,
+	requestorRef string,
+	requestTimestamp time.Time) *SIRIGetEstimatedTimetableRequest {
+	request := &SIRIGetEstimatedTimetableRequest{
+		RequestorRef: requestorRef,
+	}
+	request.MessageIdentifier = messageIdentifier
+	request.Lines = lines
+	request.RequestTimestamp = requestTimestamp
+	return request
+}
+
+func (request *SIRIGetEstimatedTimetableRequest) BuildXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "get_estimated_timetable_request.template", request); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func (request *SIRIEstimatedTimetableRequest) BuildEstimatedTimetableRequestXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "estimated_timetable_request.template", request); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
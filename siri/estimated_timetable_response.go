@@ -38,6 +38,8 @@ type SIRIEstimatedJourneyVersionFrame struct {
 type SIRIEstimatedVehicleJourney struct {
 	LineRef                string
 	DatedVehicleJourneyRef string
+	ExtraJourney           bool
+	Cancellation           bool
 
 	Attributes map[string]string
 	References map[string]string
@@ -1,6 +1,7 @@
 package siri
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -275,3 +276,31 @@ func Test_SIRIEstimatedTimeTableResponse_BuildXML_EmptyCalls(t *testing.T) {
 		t.Errorf("Wrong XML for Request:\n got:\n%v\nwant:\n%v", xml, expectedXML)
 	}
 }
+
+func Test_SIRIEstimatedTimeTableResponse_BuildXML_CancellationAndExtraJourney(t *testing.T) {
+	vehicleJourney := &SIRIEstimatedVehicleJourney{
+		LineRef:                "line1",
+		DatedVehicleJourneyRef: "dvjref1",
+		ExtraJourney:           true,
+		Cancellation:           true,
+		Attributes:             map[string]string{},
+		References:             map[string]string{},
+	}
+
+	response := &SIRIEstimatedTimeTableResponse{}
+	response.Status = true
+	response.EstimatedJourneyVersionFrames = []*SIRIEstimatedJourneyVersionFrame{
+		{EstimatedVehicleJourneys: []*SIRIEstimatedVehicleJourney{vehicleJourney}},
+	}
+
+	xml, err := response.BuildXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<siri:DatedVehicleJourneyRef>dvjref1</siri:DatedVehicleJourneyRef>
+					<siri:ExtraJourney>true</siri:ExtraJourney>
+					<siri:Cancellation>true</siri:Cancellation>`
+	if !strings.Contains(xml, expected) {
+		t.Errorf("Wrong XML for cancelled extra journey:\n got:\n%v\nwant:\n%v", xml, expected)
+	}
+}
@@ -1,6 +1,11 @@
 package siri
 
-import "time"
+import (
+	"bytes"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/logger"
+)
 
 type XMLEstimatedTimetableSubscriptionRequestEntry struct {
 	XMLEstimatedTimetableRequest
@@ -10,6 +15,24 @@ type XMLEstimatedTimetableSubscriptionRequestEntry struct {
 	initialTerminationTime time.Time
 }
 
+type SIRIEstimatedTimetableSubscriptionRequest struct {
+	ConsumerAddress   string
+	MessageIdentifier string
+	RequestorRef      string
+	RequestTimestamp  time.Time
+
+	Entries []*SIRIEstimatedTimetableSubscriptionRequestEntry
+}
+
+type SIRIEstimatedTimetableSubscriptionRequestEntry struct {
+	SIRIEstimatedTimetableRequest
+
+	SubscriberRef          string
+	SubscriptionIdentifier string
+
+	InitialTerminationTime time.Time
+}
+
 func NewXMLEstimatedTimetableSubscriptionRequestEntry(node XMLNode) *XMLEstimatedTimetableSubscriptionRequestEntry {
 	xmlEstimatedTimetableSubscriptionRequest := &XMLEstimatedTimetableSubscriptionRequestEntry{}
 	xmlEstimatedTimetableSubscriptionRequest.node = node
@@ -36,3 +59,12 @@ func (request *XMLEstimatedTimetableSubscriptionRequestEntry) InitialTermination
 	}
 	return request.initialTerminationTime
 }
+
+func (request *SIRIEstimatedTimetableSubscriptionRequest) BuildXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "estimated_timetable_subscription_request.template", request); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
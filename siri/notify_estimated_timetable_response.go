@@ -6,8 +6,22 @@ import (
 	"time"
 
 	"bitbucket.org/enroute-mobi/ara/logger"
+	"github.com/jbowtie/gokogiri"
+	"github.com/jbowtie/gokogiri/xml"
 )
 
+type XMLNotifyEstimatedTimetable struct {
+	ResponseXMLStructure
+
+	deliveries []*XMLNotifyEstimatedTimetableDelivery
+}
+
+type XMLNotifyEstimatedTimetableDelivery struct {
+	SubscriptionDeliveryXMLStructure
+
+	estimatedVehicleJourneys []*XMLEstimatedVehicleJourney
+}
+
 type SIRINotifyEstimatedTimeTable struct {
 	Address                   string
 	RequestMessageRef         string
@@ -25,6 +39,46 @@ type SIRINotifyEstimatedTimeTable struct {
 	EstimatedJourneyVersionFrames []*SIRIEstimatedJourneyVersionFrame
 }
 
+func NewXMLNotifyEstimatedTimetable(node xml.Node) *XMLNotifyEstimatedTimetable {
+	xmlEstimatedTimetableResponse := &XMLNotifyEstimatedTimetable{}
+	xmlEstimatedTimetableResponse.node = NewXMLNode(node)
+	return xmlEstimatedTimetableResponse
+}
+
+func NewXMLNotifyEstimatedTimetableFromContent(content []byte) (*XMLNotifyEstimatedTimetable, error) {
+	doc, err := gokogiri.ParseXml(content)
+	if err != nil {
+		return nil, err
+	}
+	response := NewXMLNotifyEstimatedTimetable(doc.Root().XmlNode)
+	return response, nil
+}
+
+func NewXMLNotifyEstimatedTimetableDelivery(node XMLNode) *XMLNotifyEstimatedTimetableDelivery {
+	delivery := &XMLNotifyEstimatedTimetableDelivery{}
+	delivery.node = node
+	return delivery
+}
+
+func (notify *XMLNotifyEstimatedTimetable) EstimatedTimetableDeliveries() []*XMLNotifyEstimatedTimetableDelivery {
+	if notify.deliveries == nil {
+		deliveries := []*XMLNotifyEstimatedTimetableDelivery{}
+		nodes := notify.findNodes("EstimatedTimetableDelivery")
+		for _, node := range nodes {
+			deliveries = append(deliveries, NewXMLNotifyEstimatedTimetableDelivery(node))
+		}
+		notify.deliveries = deliveries
+	}
+	return notify.deliveries
+}
+
+func (delivery *XMLNotifyEstimatedTimetableDelivery) EstimatedVehicleJourneys() []*XMLEstimatedVehicleJourney {
+	if delivery.estimatedVehicleJourneys == nil {
+		delivery.estimatedVehicleJourneys = findEstimatedVehicleJourneys(&delivery.XMLStructure)
+	}
+	return delivery.estimatedVehicleJourneys
+}
+
 func (notify *SIRINotifyEstimatedTimeTable) ErrorString() string {
 	return fmt.Sprintf("%v: %v", notify.errorType(), notify.ErrorText)
 }
@@ -0,0 +1,46 @@
+package siri
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/logger"
+)
+
+type SIRINotifyProductionTimetable struct {
+	Address                   string
+	RequestMessageRef         string
+	ProducerRef               string
+	ResponseMessageIdentifier string
+	SubscriberRef             string
+	SubscriptionIdentifier    string
+
+	ResponseTimestamp time.Time
+	Status            bool
+	ErrorType         string
+	ErrorNumber       int
+	ErrorText         string
+
+	DatedTimetableVersionFrames []*SIRIDatedTimetableVersionFrame
+}
+
+func (notify *SIRINotifyProductionTimetable) ErrorString() string {
+	return fmt.Sprintf("%v: %v", notify.errorType(), notify.ErrorText)
+}
+
+func (notify *SIRINotifyProductionTimetable) errorType() string {
+	if notify.ErrorType == "OtherError" {
+		return fmt.Sprintf("%v %v", notify.ErrorType, notify.ErrorNumber)
+	}
+	return notify.ErrorType
+}
+
+func (notify *SIRINotifyProductionTimetable) BuildXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "production_timetable_notify.template", notify); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
@@ -0,0 +1,104 @@
+package siri
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"github.com/jbowtie/gokogiri"
+	"github.com/jbowtie/gokogiri/xml"
+)
+
+type XMLNotifySituationExchange struct {
+	ResponseXMLStructure
+
+	deliveries []*XMLSituationExchangeDelivery
+}
+
+type XMLSituationExchangeDelivery struct {
+	SubscriptionDeliveryXMLStructure
+
+	xmlPtSituationElements []*XMLPtSituationElement
+}
+
+type SIRINotifySituationExchange struct {
+	Address                   string
+	ProducerRef               string
+	RequestMessageRef         string
+	ResponseMessageIdentifier string
+	SubscriberRef             string
+	SubscriptionIdentifier    string
+
+	ResponseTimestamp time.Time
+
+	Status      bool
+	ErrorType   string
+	ErrorNumber int
+	ErrorText   string
+
+	Situations []*SIRIPtSituationElement
+}
+
+func NewXMLNotifySituationExchange(node xml.Node) *XMLNotifySituationExchange {
+	xmlSituationExchangeResponse := &XMLNotifySituationExchange{}
+	xmlSituationExchangeResponse.node = NewXMLNode(node)
+	return xmlSituationExchangeResponse
+}
+
+func NewXMLNotifySituationExchangeFromContent(content []byte) (*XMLNotifySituationExchange, error) {
+	doc, err := gokogiri.ParseXml(content)
+	if err != nil {
+		return nil, err
+	}
+	response := NewXMLNotifySituationExchange(doc.Root().XmlNode)
+	return response, nil
+}
+
+func NewXMLSituationExchangeDelivery(node XMLNode) *XMLSituationExchangeDelivery {
+	delivery := &XMLSituationExchangeDelivery{}
+	delivery.node = node
+	return delivery
+}
+
+func (notify *XMLNotifySituationExchange) SituationExchangesDeliveries() []*XMLSituationExchangeDelivery {
+	if notify.deliveries == nil {
+		deliveries := []*XMLSituationExchangeDelivery{}
+		nodes := notify.findNodes("SituationExchangeDelivery")
+		for _, node := range nodes {
+			deliveries = append(deliveries, NewXMLSituationExchangeDelivery(node))
+		}
+		notify.deliveries = deliveries
+	}
+	return notify.deliveries
+}
+
+func (delivery *XMLSituationExchangeDelivery) XMLPtSituationElements() []*XMLPtSituationElement {
+	if delivery.xmlPtSituationElements == nil {
+		nodes := delivery.findNodes("PtSituationElement")
+		for _, node := range nodes {
+			delivery.xmlPtSituationElements = append(delivery.xmlPtSituationElements, NewXMLPtSituationElement(node))
+		}
+	}
+	return delivery.xmlPtSituationElements
+}
+
+func (notify *SIRINotifySituationExchange) ErrorString() string {
+	return fmt.Sprintf("%v: %v", notify.errorType(), notify.ErrorText)
+}
+
+func (notify *SIRINotifySituationExchange) errorType() string {
+	if notify.ErrorType == "OtherError" {
+		return fmt.Sprintf("%v %v", notify.ErrorType, notify.ErrorNumber)
+	}
+	return notify.ErrorType
+}
+
+func (notify *SIRINotifySituationExchange) BuildXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "situation_exchange_notify.template", notify); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
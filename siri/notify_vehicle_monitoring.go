@@ -0,0 +1,105 @@
+package siri
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"github.com/jbowtie/gokogiri"
+	"github.com/jbowtie/gokogiri/xml"
+)
+
+type XMLNotifyVehicleMonitoring struct {
+	ResponseXMLStructure
+
+	deliveries []*XMLNotifyVehicleMonitoringDelivery
+}
+
+type XMLNotifyVehicleMonitoringDelivery struct {
+	SubscriptionDeliveryXMLStructure
+
+	vehicleActivities []*XMLVehicleActivity
+}
+
+type SIRINotifyVehicleMonitoring struct {
+	Address                   string
+	RequestMessageRef         string
+	ProducerRef               string
+	ResponseMessageIdentifier string
+	SubscriberRef             string
+	SubscriptionIdentifier    string
+
+	ResponseTimestamp time.Time
+	Status            bool
+	ErrorType         string
+	ErrorNumber       int
+	ErrorText         string
+
+	VehicleActivities []*SIRIVehicleActivity
+}
+
+func NewXMLNotifyVehicleMonitoring(node xml.Node) *XMLNotifyVehicleMonitoring {
+	xmlVehicleMonitoringResponse := &XMLNotifyVehicleMonitoring{}
+	xmlVehicleMonitoringResponse.node = NewXMLNode(node)
+	return xmlVehicleMonitoringResponse
+}
+
+func NewXMLNotifyVehicleMonitoringFromContent(content []byte) (*XMLNotifyVehicleMonitoring, error) {
+	doc, err := gokogiri.ParseXml(content)
+	if err != nil {
+		return nil, err
+	}
+	response := NewXMLNotifyVehicleMonitoring(doc.Root().XmlNode)
+	return response, nil
+}
+
+func NewXMLNotifyVehicleMonitoringDelivery(node XMLNode) *XMLNotifyVehicleMonitoringDelivery {
+	delivery := &XMLNotifyVehicleMonitoringDelivery{}
+	delivery.node = node
+	return delivery
+}
+
+func (notify *XMLNotifyVehicleMonitoring) VehicleMonitoringDeliveries() []*XMLNotifyVehicleMonitoringDelivery {
+	if notify.deliveries == nil {
+		deliveries := []*XMLNotifyVehicleMonitoringDelivery{}
+		nodes := notify.findNodes("VehicleMonitoringDelivery")
+		for _, node := range nodes {
+			deliveries = append(deliveries, NewXMLNotifyVehicleMonitoringDelivery(node))
+		}
+		notify.deliveries = deliveries
+	}
+	return notify.deliveries
+}
+
+func (delivery *XMLNotifyVehicleMonitoringDelivery) XMLVehicleActivities() []*XMLVehicleActivity {
+	if delivery.vehicleActivities == nil {
+		activities := []*XMLVehicleActivity{}
+		nodes := delivery.findNodes("VehicleActivity")
+		for _, node := range nodes {
+			activities = append(activities, NewXMLVehicleActivity(node))
+		}
+		delivery.vehicleActivities = activities
+	}
+	return delivery.vehicleActivities
+}
+
+func (notify *SIRINotifyVehicleMonitoring) ErrorString() string {
+	return fmt.Sprintf("%v: %v", notify.errorType(), notify.ErrorText)
+}
+
+func (notify *SIRINotifyVehicleMonitoring) errorType() string {
+	if notify.ErrorType == "OtherError" {
+		return fmt.Sprintf("%v %v", notify.ErrorType, notify.ErrorNumber)
+	}
+	return notify.ErrorType
+}
+
+func (notify *SIRINotifyVehicleMonitoring) BuildXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "vehicle_monitoring_notify.template", notify); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
@@ -0,0 +1,96 @@
+package siri
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func getXMLGetProductionTimetable(t *testing.T) *XMLGetProductionTimetable {
+	file, err := os.Open("testdata/production_timetable_request.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request, _ := NewXMLGetProductionTimetableFromContent(content)
+	return request
+}
+
+func Test_XMLGetProductionTimetable(t *testing.T) {
+	request := getXMLGetProductionTimetable(t)
+
+	if expected := "test"; request.RequestorRef() != expected {
+		t.Errorf("Wrong RequestorRef:\n got: %v\nwant: %v", request.RequestorRef(), expected)
+	}
+	if expected := "ProductionTimetable:Test:0"; request.MessageIdentifier() != expected {
+		t.Errorf("Wrong MessageIdentifier:\n got: %v\nwant: %v", request.MessageIdentifier(), expected)
+	}
+	if expected := time.Date(2016, time.September, 7, 6, 0, 0, 0, time.UTC); !request.StartTime().Equal(expected) {
+		t.Errorf("Wrong StartTime:\n got: %v\nwant: %v", request.StartTime(), expected)
+	}
+	if expected := time.Date(2016, time.September, 7, 22, 0, 0, 0, time.UTC); !request.EndTime().Equal(expected) {
+		t.Errorf("Wrong EndTime:\n got: %v\nwant: %v", request.EndTime(), expected)
+	}
+	if len(request.Lines()) != 2 {
+		t.Fatalf("GetProductionTimetable request has wrong number of lines: %v", request.Lines())
+	}
+	if expected := "NINOXE:Line:3:LOC"; request.Lines()[1] != expected {
+		t.Errorf("Wrong second line:\n got: %v\nwant: %v", request.Lines()[1], expected)
+	}
+}
+
+func Test_SIRIProductionTimetableResponse_BuildXML(t *testing.T) {
+	aimedTime := time.Date(2016, time.September, 7, 8, 0, 0, 0, time.UTC)
+	response := &SIRIProductionTimetableResponse{
+		Address:                   "http://ara",
+		ProducerRef:               "Ara",
+		ResponseMessageIdentifier: "Ara:ResponseMessage::1:LOC",
+	}
+	response.RequestMessageRef = "ProductionTimetable:Test:0"
+	response.ResponseTimestamp = aimedTime
+	response.Status = true
+	response.DatedTimetableVersionFrames = []*SIRIDatedTimetableVersionFrame{
+		{
+			LineRef:        "NINOXE:Line:3:LOC",
+			RecordedAtTime: aimedTime,
+			DatedVehicleJourneys: []*SIRIDatedVehicleJourney{
+				{
+					DatedVehicleJourneyRef: "NINOXE:VehicleJourney:201",
+					DatedCalls: []*SIRIDatedCall{
+						{
+							StopPointRef:       "NINOXE:StopPoint:SP:24:LOC",
+							Order:              1,
+							AimedDepartureTime: aimedTime,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	xml, err := response.BuildXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"<siri:LineRef>NINOXE:Line:3:LOC</siri:LineRef>",
+		"<siri:DatedVehicleJourneyCode>NINOXE:VehicleJourney:201</siri:DatedVehicleJourneyCode>",
+		"<siri:StopPointRef>NINOXE:StopPoint:SP:24:LOC</siri:StopPointRef>",
+		"<siri:AimedDepartureTime>2016-09-07T08:00:00.000Z</siri:AimedDepartureTime>",
+	} {
+		if !strings.Contains(xml, expected) {
+			t.Errorf("Response XML should contain %v:\n%v", expected, xml)
+		}
+	}
+	if strings.Contains(xml, "AimedArrivalTime") {
+		t.Errorf("Response XML should not contain an AimedArrivalTime:\n%v", xml)
+	}
+}
@@ -0,0 +1,97 @@
+package siri
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/logger"
+)
+
+type SIRIProductionTimetableResponse struct {
+	SIRIProductionTimetableDelivery
+
+	Address                   string
+	ProducerRef               string
+	ResponseMessageIdentifier string
+}
+
+type SIRIProductionTimetableDelivery struct {
+	RequestMessageRef string
+
+	ResponseTimestamp time.Time
+
+	Status      bool
+	ErrorType   string
+	ErrorNumber int
+	ErrorText   string
+
+	DatedTimetableVersionFrames []*SIRIDatedTimetableVersionFrame
+}
+
+type SIRIDatedTimetableVersionFrame struct {
+	LineRef        string
+	RecordedAtTime time.Time
+
+	Attributes map[string]string
+
+	DatedVehicleJourneys []*SIRIDatedVehicleJourney
+}
+
+type SIRIDatedVehicleJourney struct {
+	DatedVehicleJourneyRef string
+
+	Attributes map[string]string
+	References map[string]string
+
+	DatedCalls []*SIRIDatedCall
+}
+
+type SIRIDatedCall struct {
+	StopPointRef       string
+	StopPointName      string
+	DestinationDisplay string
+
+	Order int
+
+	AimedArrivalTime   time.Time
+	AimedDepartureTime time.Time
+}
+
+func (response *SIRIProductionTimetableResponse) BuildXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "production_timetable_response.template", response); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func (delivery *SIRIProductionTimetableDelivery) ErrorString() string {
+	return fmt.Sprintf("%v: %v", delivery.errorType(), delivery.ErrorText)
+}
+
+func (delivery *SIRIProductionTimetableDelivery) errorType() string {
+	if delivery.ErrorType == "OtherError" {
+		return fmt.Sprintf("%v %v", delivery.ErrorType, delivery.ErrorNumber)
+	}
+	return delivery.ErrorType
+}
+
+func (delivery *SIRIProductionTimetableDelivery) BuildProductionTimetableDeliveryXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "production_timetable_delivery.template", delivery); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func (frame *SIRIDatedTimetableVersionFrame) BuildDatedTimetableVersionFrameXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "dated_timetable_version_frame.template", frame); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
@@ -0,0 +1,69 @@
+package siri
+
+import (
+	"time"
+)
+
+type StopPointsDelivery struct {
+	Version               string
+	ResponseTimestamp     time.Time
+	Status                bool
+	AnnotatedStopPointRef []*AnnotatedStopPointRef
+}
+
+type AnnotatedStopPointRef struct {
+	StopPointRef string
+	StopName     string
+	Lines        []string `json:",omitempty"`
+	Monitored    bool
+	TimingPoint  bool
+}
+
+type LinesDelivery struct {
+	Version           string
+	ResponseTimestamp time.Time
+	Status            bool
+	AnnotatedLineRef  []*AnnotatedLineRef
+}
+
+type AnnotatedLineRef struct {
+	LineRef   string
+	LineName  string
+	Monitored bool
+}
+
+func NewSiriLiteStopPointsDelivery(response *SIRIStopPointsDiscoveryResponse) *StopPointsDelivery {
+	delivery := &StopPointsDelivery{
+		Version:               "2.0:FR-IDF-2.4",
+		ResponseTimestamp:     response.ResponseTimestamp,
+		Status:                response.Status,
+		AnnotatedStopPointRef: []*AnnotatedStopPointRef{},
+	}
+	for _, stopPoint := range response.AnnotatedStopPoints {
+		delivery.AnnotatedStopPointRef = append(delivery.AnnotatedStopPointRef, &AnnotatedStopPointRef{
+			StopPointRef: stopPoint.StopPointRef,
+			StopName:     stopPoint.StopName,
+			Lines:        stopPoint.Lines,
+			Monitored:    stopPoint.Monitored,
+			TimingPoint:  stopPoint.TimingPoint,
+		})
+	}
+	return delivery
+}
+
+func NewSiriLiteLinesDelivery(response *SIRILinesDiscoveryResponse) *LinesDelivery {
+	delivery := &LinesDelivery{
+		Version:           "2.0:FR-IDF-2.4",
+		ResponseTimestamp: response.ResponseTimestamp,
+		Status:            response.Status,
+		AnnotatedLineRef:  []*AnnotatedLineRef{},
+	}
+	for _, line := range response.AnnotatedLines {
+		delivery.AnnotatedLineRef = append(delivery.AnnotatedLineRef, &AnnotatedLineRef{
+			LineRef:   line.LineRef,
+			LineName:  line.LineName,
+			Monitored: line.Monitored,
+		})
+	}
+	return delivery
+}
@@ -0,0 +1,103 @@
+package siri
+
+import (
+	"time"
+)
+
+type EstimatedTimetableDelivery struct {
+	Version                      string
+	ResponseTimestamp            time.Time `json:",omitempty"`
+	RequestMessageRef            string    `json:",omitempty"`
+	Status                       bool
+	ErrorCondition               *ErrorCondition `json:",omitempty"`
+	EstimatedJourneyVersionFrame []*EstimatedJourneyVersionFrame
+}
+
+type EstimatedJourneyVersionFrame struct {
+	RecordedAtTime          time.Time
+	EstimatedVehicleJourney []*EstimatedVehicleJourney
+}
+
+type EstimatedVehicleJourney struct {
+	LineRef                string
+	DirectionRef           string `json:",omitempty"`
+	OperatorRef            string `json:",omitempty"`
+	DatedVehicleJourneyRef string
+	OriginRef              string `json:",omitempty"`
+	DestinationRef         string `json:",omitempty"`
+	EstimatedCalls         *EstimatedCalls
+}
+
+type EstimatedCalls struct {
+	EstimatedCall []*EstimatedCall
+}
+
+type EstimatedCall struct {
+	StopPointRef          string
+	Order                 int
+	StopPointName         string `json:",omitempty"`
+	VehicleAtStop         bool
+	DestinationDisplay    string     `json:",omitempty"`
+	AimedArrivalTime      *time.Time `json:",omitempty"`
+	ExpectedArrivalTime   *time.Time `json:",omitempty"`
+	ArrivalStatus         string     `json:",omitempty"`
+	AimedDepartureTime    *time.Time `json:",omitempty"`
+	ExpectedDepartureTime *time.Time `json:",omitempty"`
+	DepartureStatus       string     `json:",omitempty"`
+}
+
+func NewSiriLiteEstimatedTimetableDelivery(delivery *SIRIEstimatedTimetableDelivery) *EstimatedTimetableDelivery {
+	siriLiteDelivery := &EstimatedTimetableDelivery{
+		Version:                      "2.0:FR-IDF-2.4",
+		ResponseTimestamp:            delivery.ResponseTimestamp,
+		RequestMessageRef:            delivery.RequestMessageRef,
+		Status:                       delivery.Status,
+		EstimatedJourneyVersionFrame: []*EstimatedJourneyVersionFrame{},
+	}
+	if !delivery.Status {
+		siriLiteDelivery.ErrorCondition = NewErrorCondition(delivery.ErrorType, delivery.ErrorNumber, delivery.ErrorText)
+		// Like in SOAP, the frames are only returned with an OtherError
+		if delivery.ErrorType != "OtherError" {
+			return siriLiteDelivery
+		}
+	}
+
+	for _, frame := range delivery.EstimatedJourneyVersionFrames {
+		siriLiteFrame := &EstimatedJourneyVersionFrame{
+			RecordedAtTime: frame.RecordedAtTime,
+		}
+		for _, vehicleJourney := range frame.EstimatedVehicleJourneys {
+			siriLiteFrame.EstimatedVehicleJourney = append(siriLiteFrame.EstimatedVehicleJourney, newSiriLiteEstimatedVehicleJourney(vehicleJourney))
+		}
+		siriLiteDelivery.EstimatedJourneyVersionFrame = append(siriLiteDelivery.EstimatedJourneyVersionFrame, siriLiteFrame)
+	}
+	return siriLiteDelivery
+}
+
+func newSiriLiteEstimatedVehicleJourney(vehicleJourney *SIRIEstimatedVehicleJourney) *EstimatedVehicleJourney {
+	siriLiteVehicleJourney := &EstimatedVehicleJourney{
+		LineRef:                vehicleJourney.LineRef,
+		DirectionRef:           vehicleJourney.Attributes["DirectionRef"],
+		OperatorRef:            vehicleJourney.References["OperatorRef"],
+		DatedVehicleJourneyRef: vehicleJourney.DatedVehicleJourneyRef,
+		OriginRef:              vehicleJourney.References["OriginRef"],
+		DestinationRef:         vehicleJourney.References["DestinationRef"],
+		EstimatedCalls:         &EstimatedCalls{},
+	}
+	for _, call := range vehicleJourney.EstimatedCalls {
+		siriLiteVehicleJourney.EstimatedCalls.EstimatedCall = append(siriLiteVehicleJourney.EstimatedCalls.EstimatedCall, &EstimatedCall{
+			StopPointRef:          call.StopPointRef,
+			Order:                 call.Order,
+			StopPointName:         call.StopPointName,
+			VehicleAtStop:         call.VehicleAtStop,
+			DestinationDisplay:    call.DestinationDisplay,
+			AimedArrivalTime:      siriLiteTime(call.AimedArrivalTime),
+			ExpectedArrivalTime:   siriLiteTime(call.ExpectedArrivalTime),
+			ArrivalStatus:         call.ArrivalStatus,
+			AimedDepartureTime:    siriLiteTime(call.AimedDepartureTime),
+			ExpectedDepartureTime: siriLiteTime(call.ExpectedDepartureTime),
+			DepartureStatus:       call.DepartureStatus,
+		})
+	}
+	return siriLiteVehicleJourney
+}
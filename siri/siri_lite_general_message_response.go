@@ -0,0 +1,115 @@
+package siri
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type GeneralMessageDelivery struct {
+	Version           string
+	ResponseTimestamp time.Time `json:",omitempty"`
+	RequestMessageRef string    `json:",omitempty"`
+	Status            bool
+	ErrorCondition    *ErrorCondition `json:",omitempty"`
+	GeneralMessage    []*GeneralMessage
+}
+
+type GeneralMessage struct {
+	FormatRef             string `json:"formatRef,omitempty"`
+	RecordedAtTime        time.Time
+	ItemIdentifier        string
+	InfoMessageIdentifier string
+	InfoMessageVersion    int
+	InfoChannelRef        string
+	ValidUntilTime        *time.Time `json:",omitempty"`
+	Content               *GeneralMessageContent
+}
+
+// References are grouped by kind (LineRef, StopPointRef, ...)
+type GeneralMessageContent struct {
+	References  map[string][]string `json:"-"`
+	LineSection []*LineSection      `json:",omitempty"`
+	Message     []*Message          `json:",omitempty"`
+}
+
+type LineSection struct {
+	FirstStop string `json:",omitempty"`
+	LastStop  string `json:",omitempty"`
+	LineRef   string `json:",omitempty"`
+}
+
+type Message struct {
+	MessageType         string `json:",omitempty"`
+	MessageText         string `json:",omitempty"`
+	NumberOfLines       int    `json:",omitempty"`
+	NumberOfCharPerLine int    `json:",omitempty"`
+}
+
+// The references are flattened in the Content, like in the SOAP responses
+func (content *GeneralMessageContent) MarshalJSON() ([]byte, error) {
+	aux := make(map[string]interface{})
+	for kind, ids := range content.References {
+		aux[kind] = ids
+	}
+	if len(content.LineSection) != 0 {
+		aux["LineSection"] = content.LineSection
+	}
+	if len(content.Message) != 0 {
+		aux["Message"] = content.Message
+	}
+	return json.Marshal(aux)
+}
+
+func NewSiriLiteGeneralMessageDelivery(delivery *SIRIGeneralMessageDelivery) *GeneralMessageDelivery {
+	siriLiteDelivery := &GeneralMessageDelivery{
+		Version:           "2.0:FR-IDF-2.4",
+		ResponseTimestamp: delivery.ResponseTimestamp,
+		RequestMessageRef: delivery.RequestMessageRef,
+		Status:            delivery.Status,
+		GeneralMessage:    []*GeneralMessage{},
+	}
+	if !delivery.Status {
+		siriLiteDelivery.ErrorCondition = NewErrorCondition(delivery.ErrorType, delivery.ErrorNumber, delivery.ErrorText)
+		return siriLiteDelivery
+	}
+
+	for _, generalMessage := range delivery.GeneralMessages {
+		siriLiteDelivery.GeneralMessage = append(siriLiteDelivery.GeneralMessage, newSiriLiteGeneralMessage(generalMessage))
+	}
+	return siriLiteDelivery
+}
+
+func newSiriLiteGeneralMessage(generalMessage *SIRIGeneralMessage) *GeneralMessage {
+	content := &GeneralMessageContent{
+		References: make(map[string][]string),
+	}
+	for _, reference := range generalMessage.References {
+		content.References[reference.Kind] = append(content.References[reference.Kind], reference.Id)
+	}
+	for _, lineSection := range generalMessage.LineSections {
+		content.LineSection = append(content.LineSection, &LineSection{
+			FirstStop: lineSection.FirstStop,
+			LastStop:  lineSection.LastStop,
+			LineRef:   lineSection.LineRef,
+		})
+	}
+	for _, message := range generalMessage.Messages {
+		content.Message = append(content.Message, &Message{
+			MessageType:         message.Type,
+			MessageText:         message.Content,
+			NumberOfLines:       message.NumberOfLines,
+			NumberOfCharPerLine: message.NumberOfCharPerLine,
+		})
+	}
+
+	return &GeneralMessage{
+		FormatRef:             generalMessage.FormatRef,
+		RecordedAtTime:        generalMessage.RecordedAtTime,
+		ItemIdentifier:        generalMessage.ItemIdentifier,
+		InfoMessageIdentifier: generalMessage.InfoMessageIdentifier,
+		InfoMessageVersion:    generalMessage.InfoMessageVersion,
+		InfoChannelRef:        generalMessage.InfoChannelRef,
+		ValidUntilTime:        siriLiteTime(generalMessage.ValidUntilTime),
+		Content:               content,
+	}
+}
@@ -0,0 +1,98 @@
+package siri
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SIRILiteRequest reads the SIRI Lite query string filters with the same
+// accessors than the SOAP requests
+type SIRILiteRequest struct {
+	url     string
+	filters url.Values
+}
+
+func NewSIRILiteRequest(url string, filters url.Values) *SIRILiteRequest {
+	return &SIRILiteRequest{
+		url:     url,
+		filters: filters,
+	}
+}
+
+func (request *SIRILiteRequest) Url() string {
+	return request.url
+}
+
+func (request *SIRILiteRequest) MessageIdentifier() string {
+	return request.filters.Get("MessageIdentifier")
+}
+
+func (request *SIRILiteRequest) RequestorRef() string {
+	return request.filters.Get("RequestorRef")
+}
+
+func (request *SIRILiteRequest) MonitoringRef() string {
+	return request.filters.Get("MonitoringRef")
+}
+
+func (request *SIRILiteRequest) LineRef() string {
+	return request.filters.Get("LineRef")
+}
+
+// Lines accepts several LineRef parameters or a comma separated list
+func (request *SIRILiteRequest) Lines() (lines []string) {
+	for _, value := range request.filters["LineRef"] {
+		for _, lineRef := range strings.Split(value, ",") {
+			if lineRef = strings.TrimSpace(lineRef); lineRef != "" {
+				lines = append(lines, lineRef)
+			}
+		}
+	}
+	return
+}
+
+func (request *SIRILiteRequest) InfoChannelRef() []string {
+	return request.filters["InfoChannelRef"]
+}
+
+func (request *SIRILiteRequest) StopVisitTypes() string {
+	return request.filters.Get("StopVisitTypes")
+}
+
+func (request *SIRILiteRequest) MaximumStopVisits() int {
+	i, _ := strconv.Atoi(request.filters.Get("MaximumStopVisits"))
+	return i
+}
+
+func (request *SIRILiteRequest) PreviewInterval() time.Duration {
+	return parseISO8601Duration(request.filters.Get("PreviewInterval"))
+}
+
+func (request *SIRILiteRequest) StartTime() time.Time {
+	t, err := time.Parse("2006-01-02T15:04:05Z07:00", strings.TrimSpace(request.filters.Get("StartTime")))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// GeneralMessage filters accept several LineRef and StopPointRef
+type SIRILiteGeneralMessageRequest struct {
+	SIRILiteRequest
+}
+
+func NewSIRILiteGeneralMessageRequest(url string, filters url.Values) *SIRILiteGeneralMessageRequest {
+	return &SIRILiteGeneralMessageRequest{
+		SIRILiteRequest: *NewSIRILiteRequest(url, filters),
+	}
+}
+
+func (request *SIRILiteGeneralMessageRequest) LineRef() []string {
+	return request.filters["LineRef"]
+}
+
+func (request *SIRILiteGeneralMessageRequest) StopPointRef() []string {
+	return request.filters["StopPointRef"]
+}
@@ -0,0 +1,42 @@
+package siri
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_SIRILiteRequest(t *testing.T) {
+	filters, err := url.ParseQuery("MessageIdentifier=Test:0&MonitoringRef=NINOXE:StopPoint:SP:24:LOC&LineRef=NINOXE:Line:1:LOC,NINOXE:Line:2:LOC&LineRef=NINOXE:Line:3:LOC&PreviewInterval=PT1H30M&MaximumStopVisits=5&StartTime=2017-01-01T12:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := NewSIRILiteRequest("/test/siri/v2.0/stop-monitoring.json", filters)
+
+	if request.MessageIdentifier() != "Test:0" {
+		t.Errorf("Wrong MessageIdentifier:\n got: %v\n want: Test:0", request.MessageIdentifier())
+	}
+	if request.MonitoringRef() != "NINOXE:StopPoint:SP:24:LOC" {
+		t.Errorf("Wrong MonitoringRef:\n got: %v\n want: NINOXE:StopPoint:SP:24:LOC", request.MonitoringRef())
+	}
+	expectedLines := []string{"NINOXE:Line:1:LOC", "NINOXE:Line:2:LOC", "NINOXE:Line:3:LOC"}
+	if !reflect.DeepEqual(request.Lines(), expectedLines) {
+		t.Errorf("Wrong Lines:\n got: %v\n want: %v", request.Lines(), expectedLines)
+	}
+	if request.PreviewInterval() != 90*time.Minute {
+		t.Errorf("Wrong PreviewInterval:\n got: %v\n want: 1h30m0s", request.PreviewInterval())
+	}
+	if request.MaximumStopVisits() != 5 {
+		t.Errorf("Wrong MaximumStopVisits:\n got: %v\n want: 5", request.MaximumStopVisits())
+	}
+	expectedTime := time.Date(2017, time.January, 1, 12, 0, 0, 0, time.UTC)
+	if !request.StartTime().Equal(expectedTime) {
+		t.Errorf("Wrong StartTime:\n got: %v\n want: %v", request.StartTime(), expectedTime)
+	}
+
+	empty := NewSIRILiteRequest("", url.Values{})
+	if empty.PreviewInterval() != 0 || empty.MaximumStopVisits() != 0 || !empty.StartTime().IsZero() || len(empty.Lines()) != 0 {
+		t.Errorf("Empty request should have no filters")
+	}
+}
@@ -11,17 +11,20 @@ type SiriLiteResponse struct {
 }
 
 type SiriLiteResponseSubstructure struct {
-	ServiceDelivery *ServiceDelivery
+	ServiceDelivery    *ServiceDelivery    `json:",omitempty"`
+	StopPointsDelivery *StopPointsDelivery `json:",omitempty"`
+	LinesDelivery      *LinesDelivery      `json:",omitempty"`
 }
 
 type ServiceDelivery struct {
-	ResponseTimestamp         time.Time                  `json:",omitempty"`
-	ProducerRef               string                     `json:",omitempty"`
-	ResponseMessageIdentifier string                     `json:",omitempty"`
-	RequestMessageRef         string                     `json:",omitempty"`
-	VehicleMonitoringDelivery *VehicleMonitoringDelivery `json:",omitempty"`
-	// StopMonitoringDelivery *StopMonitoringDelivery `json:",omitempty"`
-	// ...
+	ResponseTimestamp          time.Time                   `json:",omitempty"`
+	ProducerRef                string                      `json:",omitempty"`
+	ResponseMessageIdentifier  string                      `json:",omitempty"`
+	RequestMessageRef          string                      `json:",omitempty"`
+	VehicleMonitoringDelivery  *VehicleMonitoringDelivery  `json:",omitempty"`
+	StopMonitoringDelivery     *StopMonitoringDelivery     `json:",omitempty"`
+	EstimatedTimetableDelivery *EstimatedTimetableDelivery `json:",omitempty"`
+	GeneralMessageDelivery     *GeneralMessageDelivery     `json:",omitempty"`
 }
 
 type ErrorCondition struct {
@@ -30,7 +33,15 @@ type ErrorCondition struct {
 	ErrorText   string
 }
 
-func (ec *ErrorCondition) MarshalJson() ([]byte, error) {
+func NewErrorCondition(errorType string, errorNumber int, errorText string) *ErrorCondition {
+	return &ErrorCondition{
+		ErrorType:   errorType,
+		ErrorNumber: errorNumber,
+		ErrorText:   errorText,
+	}
+}
+
+func (ec *ErrorCondition) MarshalJSON() ([]byte, error) {
 	aux := make(map[string]map[string]string)
 	aux[ec.ErrorType] = make(map[string]string)
 	if ec.ErrorType == "OtherError" {
@@ -46,3 +57,18 @@ func NewSiriLiteResponse() *SiriLiteResponse {
 		Siri: &SiriLiteResponseSubstructure{ServiceDelivery: &ServiceDelivery{}},
 	}
 }
+
+// Discovery deliveries aren't included in a ServiceDelivery
+func NewSiriLiteDiscoveryResponse() *SiriLiteResponse {
+	return &SiriLiteResponse{
+		Siri: &SiriLiteResponseSubstructure{},
+	}
+}
+
+// Optional timestamps are omitted from the JSON when they're not defined
+func siriLiteTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,135 @@
+package siri
+
+import (
+	"time"
+)
+
+type StopMonitoringDelivery struct {
+	Version            string
+	ResponseTimestamp  time.Time `json:",omitempty"`
+	RequestMessageRef  string    `json:",omitempty"`
+	MonitoringRef      string    `json:",omitempty"`
+	Status             bool
+	ErrorCondition     *ErrorCondition `json:",omitempty"`
+	MonitoredStopVisit []*MonitoredStopVisit
+}
+
+type MonitoredStopVisit struct {
+	RecordedAtTime          *time.Time `json:",omitempty"`
+	ItemIdentifier          string
+	MonitoringRef           string
+	MonitoredVehicleJourney *StopVisitVehicleJourney
+}
+
+type StopVisitVehicleJourney struct {
+	LineRef                 string                   `json:",omitempty"`
+	DirectionRef            string                   `json:",omitempty"`
+	FramedVehicleJourneyRef *FramedVehicleJourneyRef `json:",omitempty"`
+	JourneyPatternRef       string                   `json:",omitempty"`
+	VehicleMode             string                   `json:",omitempty"`
+	PublishedLineName       string                   `json:",omitempty"`
+	RouteRef                string                   `json:",omitempty"`
+	DirectionName           string                   `json:",omitempty"`
+	OperatorRef             string                   `json:",omitempty"`
+	OriginRef               string                   `json:",omitempty"`
+	OriginName              string                   `json:",omitempty"`
+	DestinationRef          string                   `json:",omitempty"`
+	DestinationName         string                   `json:",omitempty"`
+	VehicleJourneyName      string                   `json:",omitempty"`
+	Monitored               bool
+	MonitoredCall           *MonitoredCall
+}
+
+type MonitoredCall struct {
+	StopPointRef          string `json:",omitempty"`
+	Order                 int    `json:",omitempty"`
+	StopPointName         string `json:",omitempty"`
+	VehicleAtStop         bool
+	DestinationDisplay    string     `json:",omitempty"`
+	AimedArrivalTime      *time.Time `json:",omitempty"`
+	ActualArrivalTime     *time.Time `json:",omitempty"`
+	ExpectedArrivalTime   *time.Time `json:",omitempty"`
+	ArrivalStatus         string     `json:",omitempty"`
+	ArrivalPlatformName   string     `json:",omitempty"`
+	AimedDepartureTime    *time.Time `json:",omitempty"`
+	ActualDepartureTime   *time.Time `json:",omitempty"`
+	ExpectedDepartureTime *time.Time `json:",omitempty"`
+	DepartureStatus       string     `json:",omitempty"`
+	DeparturePlatformName string     `json:",omitempty"`
+}
+
+func NewSiriLiteStopMonitoringDelivery(delivery *SIRIStopMonitoringDelivery) *StopMonitoringDelivery {
+	siriLiteDelivery := &StopMonitoringDelivery{
+		Version:            "2.0:FR-IDF-2.4",
+		ResponseTimestamp:  delivery.ResponseTimestamp,
+		RequestMessageRef:  delivery.RequestMessageRef,
+		MonitoringRef:      delivery.MonitoringRef,
+		Status:             delivery.Status,
+		MonitoredStopVisit: []*MonitoredStopVisit{},
+	}
+	if !delivery.Status {
+		siriLiteDelivery.ErrorCondition = NewErrorCondition(delivery.ErrorType, delivery.ErrorNumber, delivery.ErrorText)
+		// Like in SOAP, the StopVisits are only returned with an OtherError
+		if delivery.ErrorType != "OtherError" {
+			return siriLiteDelivery
+		}
+	}
+
+	for _, stopVisit := range delivery.MonitoredStopVisits {
+		siriLiteDelivery.MonitoredStopVisit = append(siriLiteDelivery.MonitoredStopVisit, NewSiriLiteMonitoredStopVisit(stopVisit))
+	}
+	return siriLiteDelivery
+}
+
+func NewSiriLiteMonitoredStopVisit(stopVisit *SIRIMonitoredStopVisit) *MonitoredStopVisit {
+	vehicleJourneyAttributes := stopVisit.Attributes["VehicleJourneyAttributes"]
+	stopVisitAttributes := stopVisit.Attributes["StopVisitAttributes"]
+	vehicleJourneyReferences := stopVisit.References["VehicleJourney"]
+
+	vehicleJourney := &StopVisitVehicleJourney{
+		LineRef:            stopVisit.LineRef,
+		DirectionRef:       vehicleJourneyAttributes["DirectionRef"],
+		JourneyPatternRef:  vehicleJourneyReferences["JourneyPatternRef"],
+		VehicleMode:        vehicleJourneyAttributes["VehicleMode"],
+		PublishedLineName:  stopVisit.PublishedLineName,
+		RouteRef:           vehicleJourneyReferences["RouteRef"],
+		DirectionName:      vehicleJourneyAttributes["DirectionName"],
+		OperatorRef:        stopVisit.References["StopVisitReferences"]["OperatorRef"],
+		OriginRef:          vehicleJourneyReferences["OriginRef"],
+		OriginName:         stopVisit.OriginName,
+		DestinationRef:     vehicleJourneyReferences["DestinationRef"],
+		DestinationName:    stopVisit.DestinationName,
+		VehicleJourneyName: stopVisit.VehicleJourneyName,
+		Monitored:          stopVisit.Monitored,
+		MonitoredCall: &MonitoredCall{
+			StopPointRef:          stopVisit.StopPointRef,
+			Order:                 stopVisit.Order,
+			StopPointName:         stopVisit.StopPointName,
+			VehicleAtStop:         stopVisit.VehicleAtStop,
+			DestinationDisplay:    stopVisitAttributes["DestinationDisplay"],
+			AimedArrivalTime:      siriLiteTime(stopVisit.AimedArrivalTime),
+			ActualArrivalTime:     siriLiteTime(stopVisit.ActualArrivalTime),
+			ExpectedArrivalTime:   siriLiteTime(stopVisit.ExpectedArrivalTime),
+			ArrivalStatus:         stopVisit.ArrivalStatus,
+			ArrivalPlatformName:   stopVisitAttributes["ArrivalPlatformName"],
+			AimedDepartureTime:    siriLiteTime(stopVisit.AimedDepartureTime),
+			ActualDepartureTime:   siriLiteTime(stopVisit.ActualDepartureTime),
+			ExpectedDepartureTime: siriLiteTime(stopVisit.ExpectedDepartureTime),
+			DepartureStatus:       stopVisit.DepartureStatus,
+			DeparturePlatformName: stopVisitAttributes["DeparturePlatformName"],
+		},
+	}
+	if stopVisit.DatedVehicleJourneyRef != "" || stopVisit.DataFrameRef != "" {
+		vehicleJourney.FramedVehicleJourneyRef = &FramedVehicleJourneyRef{
+			DataFrameRef:           stopVisit.DataFrameRef,
+			DatedVehicleJourneyRef: stopVisit.DatedVehicleJourneyRef,
+		}
+	}
+
+	return &MonitoredStopVisit{
+		RecordedAtTime:          siriLiteTime(stopVisit.RecordedAt),
+		ItemIdentifier:          stopVisit.ItemIdentifier,
+		MonitoringRef:           stopVisit.MonitoringRef,
+		MonitoredVehicleJourney: vehicleJourney,
+	}
+}
@@ -0,0 +1,112 @@
+package siri
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"github.com/jbowtie/gokogiri"
+	"github.com/jbowtie/gokogiri/xml"
+)
+
+type XMLGetSituationExchange struct {
+	XMLSituationExchangeRequest
+
+	requestorRef string
+}
+
+type XMLSituationExchangeRequest struct {
+	LightRequestXMLStructure
+
+	// Filters
+	lineRef      []string
+	stopPointRef []string
+}
+
+type SIRIGetSituationExchangeRequest struct {
+	SIRISituationExchangeRequest
+
+	RequestorRef string
+}
+
+type SIRISituationExchangeRequest struct {
+	MessageIdentifier string
+
+	RequestTimestamp time.Time
+
+	LineRef      []string
+	StopPointRef []string
+}
+
+func NewXMLGetSituationExchange(node xml.Node) *XMLGetSituationExchange {
+	xmlSituationExchangeRequest := &XMLGetSituationExchange{}
+	xmlSituationExchangeRequest.node = NewXMLNode(node)
+	return xmlSituationExchangeRequest
+}
+
+func NewXMLGetSituationExchangeFromContent(content []byte) (*XMLGetSituationExchange, error) {
+	doc, err := gokogiri.ParseXml(content)
+	if err != nil {
+		return nil, err
+	}
+	request := NewXMLGetSituationExchange(doc.Root().XmlNode)
+	return request, nil
+}
+
+func (request *XMLGetSituationExchange) RequestorRef() string {
+	if request.requestorRef == "" {
+		request.requestorRef = request.findStringChildContent("RequestorRef")
+	}
+	return request.requestorRef
+}
+
+func (request *XMLSituationExchangeRequest) LineRef() []string {
+	if len(request.lineRef) == 0 {
+		nodes := request.findNodes("LineRef")
+		for _, lineRef := range nodes {
+			request.lineRef = append(request.lineRef, strings.TrimSpace(lineRef.NativeNode().Content()))
+		}
+	}
+	return request.lineRef
+}
+
+func (request *XMLSituationExchangeRequest) StopPointRef() []string {
+	if len(request.stopPointRef) == 0 {
+		nodes := request.findNodes("StopPointRef")
+		for _, stopPointRef := range nodes {
+			request.stopPointRef = append(request.stopPointRef, strings.TrimSpace(stopPointRef.NativeNode().Content()))
+		}
+	}
+	return request.stopPointRef
+}
+
+func NewSIRIGetSituationExchangeRequest(
+	messageIdentifier,
+	requestorRef string,
+	requestTimestamp time.Time) *SIRIGetSituationExchangeRequest {
+	request := &SIRIGetSituationExchangeRequest{
+		RequestorRef: requestorRef,
+	}
+	request.MessageIdentifier = messageIdentifier
+	request.RequestTimestamp = requestTimestamp
+	return request
+}
+
+func (request *SIRIGetSituationExchangeRequest) BuildXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "get_situation_exchange_request.template", request); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func (request *SIRISituationExchangeRequest) BuildSituationExchangeRequestXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "situation_exchange_request.template", request); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
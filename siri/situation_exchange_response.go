@@ -0,0 +1,97 @@
+package siri
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/logger"
+)
+
+type SIRISituationExchangeResponse struct {
+	SIRISituationExchangeDelivery
+
+	Address                   string
+	ProducerRef               string
+	ResponseMessageIdentifier string
+}
+
+type SIRISituationExchangeDelivery struct {
+	RequestMessageRef string
+
+	ResponseTimestamp time.Time
+
+	Status      bool
+	ErrorType   string
+	ErrorNumber int
+	ErrorText   string
+
+	Situations []*SIRIPtSituationElement
+}
+
+type SIRIPtSituationElement struct {
+	CreationTime    time.Time
+	SituationNumber string
+	Version         int
+	ParticipantRef  string
+
+	ValidityPeriods    []*SIRITimeRange
+	PublicationWindows []*SIRITimeRange
+
+	AlertCause  string
+	Severity    string
+	Summary     string
+	Description string
+
+	AffectedNetworks        []string
+	AffectedLines           []string
+	AffectedStopPoints      []string
+	AffectedVehicleJourneys []string
+}
+
+type SIRITimeRange struct {
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+func (response *SIRISituationExchangeResponse) BuildXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "situation_exchange_response.template", response); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func (delivery *SIRISituationExchangeDelivery) ErrorString() string {
+	return fmt.Sprintf("%v: %v", delivery.errorType(), delivery.ErrorText)
+}
+
+func (delivery *SIRISituationExchangeDelivery) errorType() string {
+	if delivery.ErrorType == "OtherError" {
+		return fmt.Sprintf("%v %v", delivery.ErrorType, delivery.ErrorNumber)
+	}
+	return delivery.ErrorType
+}
+
+func (delivery *SIRISituationExchangeDelivery) BuildSituationExchangeDeliveryXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "situation_exchange_delivery.template", delivery); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func (situation *SIRIPtSituationElement) HasAffects() bool {
+	return len(situation.AffectedNetworks) != 0 || len(situation.AffectedLines) != 0 || len(situation.AffectedStopPoints) != 0 || len(situation.AffectedVehicleJourneys) != 0
+}
+
+func (situation *SIRIPtSituationElement) BuildPtSituationElementXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "pt_situation_element.template", situation); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
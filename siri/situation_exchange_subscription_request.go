@@ -0,0 +1,70 @@
+package siri
+
+import (
+	"bytes"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/logger"
+)
+
+type XMLSituationExchangeSubscriptionRequestEntry struct {
+	XMLSituationExchangeRequest
+
+	subscriberRef          string
+	subscriptionIdentifier string
+	initialTerminationTime time.Time
+}
+
+type SIRISituationExchangeSubscriptionRequest struct {
+	ConsumerAddress   string
+	MessageIdentifier string
+	RequestorRef      string
+	RequestTimestamp  time.Time
+
+	Entries []*SIRISituationExchangeSubscriptionRequestEntry
+}
+
+type SIRISituationExchangeSubscriptionRequestEntry struct {
+	SIRISituationExchangeRequest
+
+	SubscriberRef          string
+	SubscriptionIdentifier string
+
+	InitialTerminationTime time.Time
+}
+
+func NewXMLSituationExchangeSubscriptionRequestEntry(node XMLNode) *XMLSituationExchangeSubscriptionRequestEntry {
+	xmlSituationExchangeSubscriptionRequestEntry := &XMLSituationExchangeSubscriptionRequestEntry{}
+	xmlSituationExchangeSubscriptionRequestEntry.node = node
+	return xmlSituationExchangeSubscriptionRequestEntry
+}
+
+func (request *XMLSituationExchangeSubscriptionRequestEntry) SubscriberRef() string {
+	if request.subscriberRef == "" {
+		request.subscriberRef = request.findStringChildContent("SubscriberRef")
+	}
+	return request.subscriberRef
+}
+
+func (request *XMLSituationExchangeSubscriptionRequestEntry) SubscriptionIdentifier() string {
+	if request.subscriptionIdentifier == "" {
+		request.subscriptionIdentifier = request.findStringChildContent("SubscriptionIdentifier")
+	}
+	return request.subscriptionIdentifier
+}
+
+func (request *XMLSituationExchangeSubscriptionRequestEntry) InitialTerminationTime() time.Time {
+	if request.initialTerminationTime.IsZero() {
+		request.initialTerminationTime = request.findTimeChildContent("InitialTerminationTime")
+	}
+	return request.initialTerminationTime
+}
+
+func (request *SIRISituationExchangeSubscriptionRequest) BuildXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "situation_exchange_subscription_request.template", request); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"bitbucket.org/enroute-mobi/ara/clock"
 	"bitbucket.org/enroute-mobi/ara/version"
 	"github.com/jbowtie/gokogiri/xml"
 	"golang.org/x/text/encoding/charmap"
@@ -31,9 +32,22 @@ type Request interface {
 }
 
 type SOAPClient struct {
+	clock.ClockConsumer
 	SOAPClientUrls
 
-	httpClient *http.Client
+	httpClient     *http.Client
+	metrics        *SOAPClientMetrics
+	authentication SOAPClientAuthentication
+	retry          SOAPClientRetry
+	circuitBreaker *CircuitBreaker
+}
+
+// Retry of the idempotent requests, with an exponential backoff. No retry
+// is made when the total duration would exceed MaxDuration (if defined).
+type SOAPClientRetry struct {
+	Attempts    int
+	Backoff     time.Duration
+	MaxDuration time.Duration
 }
 
 type SOAPClientUrls struct {
@@ -79,9 +93,36 @@ func NewSOAPClient(urls SOAPClientUrls) *SOAPClient {
 	return &SOAPClient{
 		SOAPClientUrls: urls,
 		httpClient:     httpClient,
+		metrics:        NewSOAPClientMetrics(),
 	}
 }
 
+func (client *SOAPClient) Metrics() *SOAPClientMetrics {
+	return client.metrics
+}
+
+// Shares the given metrics between several SOAPClients
+func (client *SOAPClient) SetMetrics(metrics *SOAPClientMetrics) {
+	client.metrics = metrics
+}
+
+func (client *SOAPClient) Retry() SOAPClientRetry {
+	return client.retry
+}
+
+func (client *SOAPClient) SetRetry(retry SOAPClientRetry) {
+	client.retry = retry
+}
+
+func (client *SOAPClient) CircuitBreaker() *CircuitBreaker {
+	return client.circuitBreaker
+}
+
+// Shares the given CircuitBreaker between several SOAPClients
+func (client *SOAPClient) SetCircuitBreaker(circuitBreaker *CircuitBreaker) {
+	client.circuitBreaker = circuitBreaker
+}
+
 func (client *SOAPClient) responseFromFormat(body io.Reader, contentType string) io.Reader {
 	r, _ := regexp.Compile("^text/xml;charset=([ -~]+)")
 	s := r.FindStringSubmatch(contentType)
@@ -95,11 +136,56 @@ func (client *SOAPClient) responseFromFormat(body io.Reader, contentType string)
 }
 
 func (client *SOAPClient) prepareAndSendRequest(args soapClientArguments) (xml.Node, error) {
+	if client.circuitBreaker != nil {
+		if err := client.circuitBreaker.Allow(); err != nil {
+			client.metrics.Observe(requestName(args.request), 0, err)
+			return nil, err
+		}
+	}
+
+	attempts := 1
+	if args.requestType == DEFAULT || args.requestType == CHECK_STATUS {
+		attempts = client.retry.Attempts
+	}
+	backoff := client.retry.Backoff
+	retryStartTime := client.Clock().Now()
+
+	for attempt := 1; ; attempt++ {
+		startTime := time.Now()
+		node, retryable, err := client.sendRequest(args)
+		client.metrics.Observe(requestName(args.request), time.Since(startTime), err)
+
+		if err == nil || !retryable || attempt >= attempts || client.retryTooLong(retryStartTime, backoff) {
+			if client.circuitBreaker != nil {
+				if err != nil && retryable {
+					client.circuitBreaker.Failure()
+				} else {
+					client.circuitBreaker.Success()
+				}
+			}
+			return node, err
+		}
+
+		<-client.Clock().After(backoff)
+		backoff *= 2
+	}
+}
+
+func (client *SOAPClient) retryTooLong(startTime time.Time, backoff time.Duration) bool {
+	if client.retry.MaxDuration <= 0 {
+		return false
+	}
+	return client.Clock().Since(startTime)+backoff > client.retry.MaxDuration
+}
+
+// Returns retryable when the request failed because of a network error or a
+// server error
+func (client *SOAPClient) sendRequest(args soapClientArguments) (_ xml.Node, retryable bool, err error) {
 	// Wrap the request XML
 	soapEnvelope := NewSOAPEnvelopeBuffer()
 	xml, err := args.request.BuildXML()
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	soapEnvelope.WriteXML(xml)
@@ -113,7 +199,7 @@ func (client *SOAPClient) prepareAndSendRequest(args soapClientArguments) (xml.N
 
 	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, client.getURL(args.requestType), soapEnvelope)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	if args.acceptGzip {
 		httpRequest.Header.Set("Accept-Encoding", "gzip, deflate")
@@ -121,11 +207,12 @@ func (client *SOAPClient) prepareAndSendRequest(args soapClientArguments) (xml.N
 	httpRequest.Header.Set("Content-Type", "text/xml; charset=utf-8")
 	httpRequest.Header.Set("User-Agent", version.ApplicationName())
 	httpRequest.ContentLength = soapEnvelope.Length()
+	client.authentication.setHeaders(httpRequest)
 
 	// Send http request
 	response, err := client.httpClient.Do(httpRequest)
 	if err != nil {
-		return nil, err
+		return nil, true, err
 	}
 	defer func() {
 		io.Copy(ioutil.Discard, response.Body)
@@ -134,16 +221,16 @@ func (client *SOAPClient) prepareAndSendRequest(args soapClientArguments) (xml.N
 
 	// Do nothing if request is a notification
 	if args.requestType == NOTIFICATION {
-		return nil, nil
+		return nil, false, nil
 	}
 
 	// Check response status
 	if response.StatusCode != http.StatusOK {
-		return nil, NewSiriError(strings.Join([]string{"SIRI CRITICAL: HTTP status ", strconv.Itoa(response.StatusCode)}, ""))
+		return nil, response.StatusCode >= http.StatusInternalServerError, NewSiriError(strings.Join([]string{"SIRI CRITICAL: HTTP status ", strconv.Itoa(response.StatusCode)}, ""))
 	}
 
 	if !strings.Contains(response.Header.Get("Content-Type"), "text/xml") {
-		return nil, NewSiriError(fmt.Sprintf("SIRI CRITICAL: HTTP Content-Type %v", response.Header.Get("Content-Type")))
+		return nil, false, NewSiriError(fmt.Sprintf("SIRI CRITICAL: HTTP Content-Type %v", response.Header.Get("Content-Type")))
 	}
 
 	// Check if response is gzip
@@ -151,7 +238,7 @@ func (client *SOAPClient) prepareAndSendRequest(args soapClientArguments) (xml.N
 	if args.acceptGzip && response.Header.Get("Content-Encoding") == "gzip" {
 		gzipReader, err := gzip.NewReader(response.Body)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 		defer gzipReader.Close()
 		responseReader = gzipReader
@@ -162,9 +249,10 @@ func (client *SOAPClient) prepareAndSendRequest(args soapClientArguments) (xml.N
 	// Create SOAPEnvelope and check body type
 	envelope, err := NewSOAPEnvelope(responseReader)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
-	return envelope.BodyOrError(args.expectedResponse)
+	node, err := envelope.BodyOrError(args.expectedResponse)
+	return node, false, err
 }
 
 func (client *SOAPClient) getURL(requestType requestType) string {
@@ -249,6 +337,48 @@ func (client *SOAPClient) SituationMonitoring(request *SIRIGetGeneralMessageRequ
 	return generalMessage, nil
 }
 
+func (client *SOAPClient) SituationExchange(request *SIRIGetSituationExchangeRequest) (*XMLSituationExchangeResponse, error) {
+	node, err := client.prepareAndSendRequest(soapClientArguments{
+		request:          request,
+		expectedResponse: "GetSituationExchangeResponse",
+		acceptGzip:       true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	situationExchange := NewXMLSituationExchangeResponse(node)
+	return situationExchange, nil
+}
+
+func (client *SOAPClient) VehicleMonitoring(request *SIRIGetVehicleMonitoringRequest) (*XMLVehicleMonitoringResponse, error) {
+	node, err := client.prepareAndSendRequest(soapClientArguments{
+		request:          request,
+		expectedResponse: "GetVehicleMonitoringResponse",
+		acceptGzip:       true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	vehicleMonitoring := NewXMLVehicleMonitoringResponse(node)
+	return vehicleMonitoring, nil
+}
+
+func (client *SOAPClient) EstimatedTimetable(request *SIRIGetEstimatedTimetableRequest) (*XMLEstimatedTimetableResponse, error) {
+	node, err := client.prepareAndSendRequest(soapClientArguments{
+		request:          request,
+		expectedResponse: "GetEstimatedTimetableResponse",
+		acceptGzip:       true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	estimatedTimetable := NewXMLEstimatedTimetableResponse(node)
+	return estimatedTimetable, nil
+}
+
 func (client *SOAPClient) StopMonitoringSubscription(request *SIRIStopMonitoringSubscriptionRequest) (*XMLSubscriptionResponse, error) {
 	node, err := client.prepareAndSendRequest(soapClientArguments{
 		request:          request,
@@ -277,6 +407,48 @@ func (client *SOAPClient) GeneralMessageSubscription(request *SIRIGeneralMessage
 	return response, nil
 }
 
+func (client *SOAPClient) VehicleMonitoringSubscription(request *SIRIVehicleMonitoringSubscriptionRequest) (*XMLSubscriptionResponse, error) {
+	node, err := client.prepareAndSendRequest(soapClientArguments{
+		request:          request,
+		requestType:      SUBSCRIPTION,
+		expectedResponse: "SubscribeResponse",
+		acceptGzip:       true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	response := NewXMLSubscriptionResponse(node)
+	return response, nil
+}
+
+func (client *SOAPClient) EstimatedTimetableSubscription(request *SIRIEstimatedTimetableSubscriptionRequest) (*XMLSubscriptionResponse, error) {
+	node, err := client.prepareAndSendRequest(soapClientArguments{
+		request:          request,
+		requestType:      SUBSCRIPTION,
+		expectedResponse: "SubscribeResponse",
+		acceptGzip:       true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	response := NewXMLSubscriptionResponse(node)
+	return response, nil
+}
+
+func (client *SOAPClient) SituationExchangeSubscription(request *SIRISituationExchangeSubscriptionRequest) (*XMLSubscriptionResponse, error) {
+	node, err := client.prepareAndSendRequest(soapClientArguments{
+		request:          request,
+		requestType:      SUBSCRIPTION,
+		expectedResponse: "SubscribeResponse",
+		acceptGzip:       true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	response := NewXMLSubscriptionResponse(node)
+	return response, nil
+}
+
 func (client *SOAPClient) DeleteSubscription(request *SIRIDeleteSubscriptionRequest) (*XMLDeleteSubscriptionResponse, error) {
 	node, err := client.prepareAndSendRequest(soapClientArguments{
 		request:          request,
@@ -324,3 +496,36 @@ func (client *SOAPClient) NotifyEstimatedTimeTable(request *SIRINotifyEstimatedT
 	}
 	return nil
 }
+
+func (client *SOAPClient) NotifyProductionTimetable(request *SIRINotifyProductionTimetable) error {
+	_, err := client.prepareAndSendRequest(soapClientArguments{
+		request:     request,
+		requestType: NOTIFICATION,
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (client *SOAPClient) NotifyVehicleMonitoring(request *SIRINotifyVehicleMonitoring) error {
+	_, err := client.prepareAndSendRequest(soapClientArguments{
+		request:     request,
+		requestType: NOTIFICATION,
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (client *SOAPClient) NotifySituationExchange(request *SIRINotifySituationExchange) error {
+	_, err := client.prepareAndSendRequest(soapClientArguments{
+		request:     request,
+		requestType: NOTIFICATION,
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
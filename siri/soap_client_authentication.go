@@ -0,0 +1,55 @@
+package siri
+
+import (
+	"crypto/tls"
+	"net/http"
+)
+
+const (
+	AUTHENTICATION_BASIC              = "basic"
+	AUTHENTICATION_BEARER             = "bearer"
+	AUTHENTICATION_CLIENT_CERTIFICATE = "client_certificate"
+)
+
+// Authentication sent by the SOAPClient with each request
+type SOAPClientAuthentication struct {
+	Mode string
+
+	Username string
+	Password string
+
+	Token string
+
+	CertificateFile string
+	KeyFile         string
+}
+
+func (client *SOAPClient) Authentication() SOAPClientAuthentication {
+	return client.authentication
+}
+
+// The authentication is changed only when the client certificate can be
+// loaded, so a failed authentication is retried with the next SOAPClient
+func (client *SOAPClient) SetAuthentication(authentication SOAPClientAuthentication) error {
+	if authentication.Mode == AUTHENTICATION_CLIENT_CERTIFICATE {
+		certificate, err := tls.LoadX509KeyPair(authentication.CertificateFile, authentication.KeyFile)
+		if err != nil {
+			return err
+		}
+		if transport, ok := client.httpClient.Transport.(*http.Transport); ok {
+			transport.TLSClientConfig = &tls.Config{Certificates: []tls.Certificate{certificate}}
+		}
+	}
+
+	client.authentication = authentication
+	return nil
+}
+
+func (authentication *SOAPClientAuthentication) setHeaders(request *http.Request) {
+	switch authentication.Mode {
+	case AUTHENTICATION_BASIC:
+		request.SetBasicAuth(authentication.Username, authentication.Password)
+	case AUTHENTICATION_BEARER:
+		request.Header.Set("Authorization", "Bearer "+authentication.Token)
+	}
+}
@@ -0,0 +1,87 @@
+package siri
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/clock"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Stops the requests to a partner during coolOff after threshold
+// consecutive failures. After the coolOff, a single request is sent to check
+// the partner again.
+type CircuitBreaker struct {
+	clock.ClockConsumer
+
+	mutex *sync.Mutex
+
+	threshold int
+	coolOff   time.Duration
+
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+type CircuitBreakerState struct {
+	Open      bool
+	Failures  int
+	OpenUntil *time.Time `json:",omitempty"`
+}
+
+func NewCircuitBreaker(threshold int, coolOff time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		mutex:     &sync.Mutex{},
+		threshold: threshold,
+		coolOff:   coolOff,
+	}
+}
+
+func (breaker *CircuitBreaker) Allow() error {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	if breaker.failures < breaker.threshold {
+		return nil
+	}
+	if breaker.probing || breaker.Clock().Now().Before(breaker.openUntil) {
+		return ErrCircuitOpen
+	}
+	breaker.probing = true
+	return nil
+}
+
+func (breaker *CircuitBreaker) Success() {
+	breaker.mutex.Lock()
+	breaker.failures = 0
+	breaker.probing = false
+	breaker.mutex.Unlock()
+}
+
+func (breaker *CircuitBreaker) Failure() {
+	breaker.mutex.Lock()
+	breaker.failures++
+	breaker.probing = false
+	if breaker.failures >= breaker.threshold {
+		breaker.openUntil = breaker.Clock().Now().Add(breaker.coolOff)
+	}
+	breaker.mutex.Unlock()
+}
+
+func (breaker *CircuitBreaker) State() CircuitBreakerState {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	state := CircuitBreakerState{
+		Open:     breaker.failures >= breaker.threshold,
+		Failures: breaker.failures,
+	}
+	if state.Open {
+		openUntil := breaker.openUntil
+		state.OpenUntil = &openUntil
+	}
+	return state
+}
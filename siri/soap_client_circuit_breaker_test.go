@@ -0,0 +1,44 @@
+package siri
+
+import (
+	"testing"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/clock"
+)
+
+func Test_CircuitBreaker(t *testing.T) {
+	fakeClock := clock.NewFakeClock()
+	breaker := NewCircuitBreaker(2, time.Minute)
+	breaker.SetClock(fakeClock)
+
+	breaker.Failure()
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("CircuitBreaker should be closed after a single failure, got %v", err)
+	}
+	breaker.Failure()
+	if err := breaker.Allow(); err != ErrCircuitOpen {
+		t.Fatalf("CircuitBreaker should be open after two failures, got %v", err)
+	}
+
+	state := breaker.State()
+	if !state.Open || state.Failures != 2 || !state.OpenUntil.Equal(fakeClock.Now().Add(time.Minute)) {
+		t.Errorf("Wrong CircuitBreaker state: %#v", state)
+	}
+
+	fakeClock.Advance(time.Minute)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("CircuitBreaker should allow a request after the cool off, got %v", err)
+	}
+	if err := breaker.Allow(); err != ErrCircuitOpen {
+		t.Errorf("CircuitBreaker should allow a single request after the cool off, got %v", err)
+	}
+
+	breaker.Success()
+	if err := breaker.Allow(); err != nil {
+		t.Errorf("CircuitBreaker should be closed after a success, got %v", err)
+	}
+	if state := breaker.State(); state.Open || state.Failures != 0 {
+		t.Errorf("Wrong CircuitBreaker state: %#v", state)
+	}
+}
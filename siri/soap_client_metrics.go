@@ -0,0 +1,62 @@
+package siri
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SOAPClientMetrics counts the requests sent by one or several SOAPClients
+type SOAPClientMetrics struct {
+	mutex    *sync.RWMutex
+	requests map[string]*SOAPRequestMetrics
+}
+
+type SOAPRequestMetrics struct {
+	Request  string
+	Count    uint64
+	Errors   uint64
+	Duration time.Duration
+}
+
+func NewSOAPClientMetrics() *SOAPClientMetrics {
+	return &SOAPClientMetrics{
+		mutex:    &sync.RWMutex{},
+		requests: make(map[string]*SOAPRequestMetrics),
+	}
+}
+
+func (metrics *SOAPClientMetrics) Observe(request string, duration time.Duration, err error) {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+
+	requestMetrics, ok := metrics.requests[request]
+	if !ok {
+		requestMetrics = &SOAPRequestMetrics{Request: request}
+		metrics.requests[request] = requestMetrics
+	}
+	requestMetrics.Count++
+	requestMetrics.Duration += duration
+	if err != nil {
+		requestMetrics.Errors++
+	}
+}
+
+// Returns a copy of the metrics of each request, sorted by request name
+func (metrics *SOAPClientMetrics) Requests() (requests []SOAPRequestMetrics) {
+	metrics.mutex.RLock()
+	for _, requestMetrics := range metrics.requests {
+		requests = append(requests, *requestMetrics)
+	}
+	metrics.mutex.RUnlock()
+
+	sort.Slice(requests, func(i, j int) bool { return requests[i].Request < requests[j].Request })
+	return
+}
+
+// Returns the request name used in metrics, like GetStopMonitoringRequest or NotifyStopMonitoring
+func requestName(request Request) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", request), "*siri.SIRI")
+}
@@ -9,6 +9,8 @@ import (
 	"os"
 	"testing"
 	"time"
+
+	"bitbucket.org/enroute-mobi/ara/clock"
 )
 
 func testSOAPFile(name string) (*os.File, error) {
@@ -146,3 +148,183 @@ func Test_SOAPClient_StopMonitoring(t *testing.T) {
 		t.Errorf("Wrong ResponseTimestamp in response:\n got: %v\n want: %v", response.ResponseTimestamp(), expected)
 	}
 }
+
+func Test_SOAPClient_Metrics(t *testing.T) {
+	ts := createHTTPServer(t, "checkstatus-response")
+	defer ts.Close()
+
+	client := NewSOAPClient(SOAPClientUrls{Url: ts.URL})
+	request := &SIRICheckStatusRequest{
+		RequestorRef:      "Ara",
+		RequestTimestamp:  time.Now(),
+		MessageIdentifier: "Ara:Message::6ba7b814-9dad-11d1-32-00c04fd430c8:LOC",
+	}
+	if _, err := client.CheckStatus(request); err != nil {
+		t.Fatal(err)
+	}
+	// The CheckStatusResponse isn't the expected response
+	client.StopMonitoring(&SIRIGetStopMonitoringRequest{RequestorRef: "Ara"})
+
+	requests := client.Metrics().Requests()
+	if len(requests) != 2 {
+		t.Fatalf("Metrics should have 2 requests, got: %v", requests)
+	}
+	if requests[0].Request != "CheckStatusRequest" || requests[0].Count != 1 || requests[0].Errors != 0 {
+		t.Errorf("Wrong CheckStatusRequest metrics: %v", requests[0])
+	}
+	if requests[1].Request != "GetStopMonitoringRequest" || requests[1].Count != 1 || requests[1].Errors != 1 {
+		t.Errorf("Wrong GetStopMonitoringRequest metrics: %v", requests[1])
+	}
+}
+
+func Test_SOAPClient_Authentication(t *testing.T) {
+	var authorization string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+
+		file, err := testSOAPFile("checkstatus-response")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		io.Copy(w, file)
+	}))
+	defer ts.Close()
+
+	var conditions = []struct {
+		authentication SOAPClientAuthentication
+		expected       string
+	}{
+		{SOAPClientAuthentication{}, ""},
+		{SOAPClientAuthentication{Mode: AUTHENTICATION_BASIC, Username: "user", Password: "secret"}, "Basic dXNlcjpzZWNyZXQ="},
+		{SOAPClientAuthentication{Mode: AUTHENTICATION_BEARER, Token: "token"}, "Bearer token"},
+	}
+
+	for _, condition := range conditions {
+		client := NewSOAPClient(SOAPClientUrls{Url: ts.URL})
+		if err := client.SetAuthentication(condition.authentication); err != nil {
+			t.Fatal(err)
+		}
+		request := &SIRICheckStatusRequest{RequestorRef: "Ara", RequestTimestamp: time.Now()}
+		if _, err := client.CheckStatus(request); err != nil {
+			t.Fatal(err)
+		}
+		if authorization != condition.expected {
+			t.Errorf("Wrong Authorization header with %v:\n got: %v\n want: %v", condition.authentication.Mode, authorization, condition.expected)
+		}
+	}
+}
+
+func Test_SOAPClient_SetAuthentication_MissingCertificate(t *testing.T) {
+	client := NewSOAPClient(SOAPClientUrls{Url: "http://localhost"})
+	authentication := SOAPClientAuthentication{
+		Mode:            AUTHENTICATION_CLIENT_CERTIFICATE,
+		CertificateFile: "testdata/missing.crt",
+		KeyFile:         "testdata/missing.key",
+	}
+	if err := client.SetAuthentication(authentication); err == nil {
+		t.Error("SetAuthentication should return an error when the certificate can't be loaded")
+	}
+	if client.Authentication() == authentication {
+		t.Error("SOAPClient shouldn't keep an authentication which can't be loaded")
+	}
+}
+
+func createFailingHTTPServer(t *testing.T, failures int, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if *requests <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		file, err := testSOAPFile("checkstatus-response")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		io.Copy(w, file)
+	}))
+}
+
+func Test_SOAPClient_Retry(t *testing.T) {
+	var requests int
+	ts := createFailingHTTPServer(t, 2, &requests)
+	defer ts.Close()
+
+	client := NewSOAPClient(SOAPClientUrls{Url: ts.URL})
+	client.SetRetry(SOAPClientRetry{Attempts: 3, Backoff: time.Millisecond})
+
+	request := &SIRICheckStatusRequest{RequestorRef: "Ara", RequestTimestamp: time.Now()}
+	if _, err := client.CheckStatus(request); err != nil {
+		t.Fatalf("CheckStatus should succeed after retries, got %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("Wrong number of requests:\n got: %v\n want: 3", requests)
+	}
+}
+
+func Test_SOAPClient_Retry_MaxDuration(t *testing.T) {
+	var requests int
+	ts := createFailingHTTPServer(t, 5, &requests)
+	defer ts.Close()
+
+	fakeClock := clock.NewFakeClock()
+	client := NewSOAPClient(SOAPClientUrls{Url: ts.URL})
+	client.SetClock(fakeClock)
+	client.SetRetry(SOAPClientRetry{Attempts: 5, Backoff: time.Second, MaxDuration: 2500 * time.Millisecond})
+
+	done := make(chan error)
+	go func() {
+		request := &SIRICheckStatusRequest{RequestorRef: "Ara", RequestTimestamp: time.Now()}
+		_, err := client.CheckStatus(request)
+		done <- err
+	}()
+
+	// Only the first backoff (1s) fits in the maximum duration
+	fakeClock.BlockUntil(1)
+	fakeClock.Advance(time.Second)
+
+	if err := <-done; err == nil {
+		t.Fatal("CheckStatus should fail when the retry duration is exceeded")
+	}
+	if requests != 2 {
+		t.Errorf("Wrong number of requests:\n got: %v\n want: 2", requests)
+	}
+}
+
+func Test_SOAPClient_Retry_NotIdempotent(t *testing.T) {
+	var requests int
+	ts := createFailingHTTPServer(t, 2, &requests)
+	defer ts.Close()
+
+	client := NewSOAPClient(SOAPClientUrls{Url: ts.URL})
+	client.SetRetry(SOAPClientRetry{Attempts: 3, Backoff: time.Millisecond})
+
+	client.DeleteSubscription(&SIRIDeleteSubscriptionRequest{RequestorRef: "Ara"})
+	if requests != 1 {
+		t.Errorf("Subscription requests shouldn't be retried, got %v requests", requests)
+	}
+}
+
+func Test_SOAPClient_CircuitBreaker(t *testing.T) {
+	var requests int
+	ts := createFailingHTTPServer(t, 10, &requests)
+	defer ts.Close()
+
+	client := NewSOAPClient(SOAPClientUrls{Url: ts.URL})
+	client.SetCircuitBreaker(NewCircuitBreaker(2, time.Minute))
+
+	request := &SIRICheckStatusRequest{RequestorRef: "Ara", RequestTimestamp: time.Now()}
+	for i := 0; i < 2; i++ {
+		if _, err := client.CheckStatus(request); err == nil || err == ErrCircuitOpen {
+			t.Fatalf("Request %d should fail with the server error, got %v", i, err)
+		}
+	}
+	if _, err := client.CheckStatus(request); err != ErrCircuitOpen {
+		t.Errorf("Request should be stopped by the circuit breaker, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Wrong number of requests:\n got: %v\n want: 2", requests)
+	}
+}
@@ -16,6 +16,9 @@ type XMLSubscriptionRequest struct {
 	smEntries  []*XMLStopMonitoringSubscriptionRequestEntry
 	gmEntries  []*XMLGeneralMessageSubscriptionRequestEntry
 	ettEntries []*XMLEstimatedTimetableSubscriptionRequestEntry
+	ptEntries  []*XMLProductionTimetableSubscriptionRequestEntry
+	vmEntries  []*XMLVehicleMonitoringSubscriptionRequestEntry
+	sxEntries  []*XMLSituationExchangeSubscriptionRequestEntry
 }
 
 func NewXMLSubscriptionRequestFromContent(content []byte) (*XMLSubscriptionRequest, error) {
@@ -55,6 +58,17 @@ func (request *XMLSubscriptionRequest) XMLSubscriptionETTEntries() []*XMLEstimat
 	return request.ettEntries
 }
 
+func (request *XMLSubscriptionRequest) XMLSubscriptionVMEntries() []*XMLVehicleMonitoringSubscriptionRequestEntry {
+	if len(request.vmEntries) != 0 {
+		return request.vmEntries
+	}
+	nodes := request.findNodes("VehicleMonitoringSubscriptionRequest")
+	for _, vm := range nodes {
+		request.vmEntries = append(request.vmEntries, NewXMLVehicleMonitoringSubscriptionRequestEntry(vm))
+	}
+	return request.vmEntries
+}
+
 func (request *XMLSubscriptionRequest) XMLSubscriptionGMEntries() []*XMLGeneralMessageSubscriptionRequestEntry {
 	if len(request.gmEntries) != 0 {
 		return request.gmEntries
@@ -66,6 +80,28 @@ func (request *XMLSubscriptionRequest) XMLSubscriptionGMEntries() []*XMLGeneralM
 	return request.gmEntries
 }
 
+func (request *XMLSubscriptionRequest) XMLSubscriptionSXEntries() []*XMLSituationExchangeSubscriptionRequestEntry {
+	if len(request.sxEntries) != 0 {
+		return request.sxEntries
+	}
+	nodes := request.findNodes("SituationExchangeSubscriptionRequest")
+	for _, situationExchange := range nodes {
+		request.sxEntries = append(request.sxEntries, NewXMLSituationExchangeSubscriptionRequestEntry(situationExchange))
+	}
+	return request.sxEntries
+}
+
+func (request *XMLSubscriptionRequest) XMLSubscriptionPTEntries() []*XMLProductionTimetableSubscriptionRequestEntry {
+	if len(request.ptEntries) != 0 {
+		return request.ptEntries
+	}
+	nodes := request.findNodes("ProductionTimetableSubscriptionRequest")
+	for _, pt := range nodes {
+		request.ptEntries = append(request.ptEntries, NewXMLProductionTimetableSubscriptionRequestEntry(pt))
+	}
+	return request.ptEntries
+}
+
 func (request *XMLSubscriptionRequest) ConsumerAddress() string {
 	if request.consumerAddress == "" {
 		request.consumerAddress = request.findStringChildContent("ConsumerAddress")
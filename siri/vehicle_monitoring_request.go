@@ -0,0 +1,112 @@
+package siri
+
+import (
+	"bytes"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/logger"
+	"github.com/jbowtie/gokogiri"
+	"github.com/jbowtie/gokogiri/xml"
+)
+
+type XMLGetVehicleMonitoring struct {
+	XMLVehicleMonitoringRequest
+
+	requestorRef string
+}
+
+type XMLVehicleMonitoringRequest struct {
+	LightRequestXMLStructure
+
+	vehicleMonitoringRef string
+	lineRef              string
+	maximumVehicles      int
+}
+
+type SIRIGetVehicleMonitoringRequest struct {
+	SIRIVehicleMonitoringRequest
+
+	RequestorRef string
+}
+
+type SIRIVehicleMonitoringRequest struct {
+	MessageIdentifier string
+	LineRef           string
+
+	RequestTimestamp time.Time
+}
+
+func NewXMLGetVehicleMonitoring(node xml.Node) *XMLGetVehicleMonitoring {
+	xmlGetVehicleMonitoring := &XMLGetVehicleMonitoring{}
+	xmlGetVehicleMonitoring.node = NewXMLNode(node)
+	return xmlGetVehicleMonitoring
+}
+
+func NewXMLGetVehicleMonitoringFromContent(content []byte) (*XMLGetVehicleMonitoring, error) {
+	doc, err := gokogiri.ParseXml(content)
+	if err != nil {
+		return nil, err
+	}
+	request := NewXMLGetVehicleMonitoring(doc.Root().XmlNode)
+	return request, nil
+}
+
+func (request *XMLGetVehicleMonitoring) RequestorRef() string {
+	if request.requestorRef == "" {
+		request.requestorRef = request.findStringChildContent("RequestorRef")
+	}
+	return request.requestorRef
+}
+
+func (request *XMLVehicleMonitoringRequest) VehicleMonitoringRef() string {
+	if request.vehicleMonitoringRef == "" {
+		request.vehicleMonitoringRef = request.findStringChildContent("VehicleMonitoringRef")
+	}
+	return request.vehicleMonitoringRef
+}
+
+func (request *XMLVehicleMonitoringRequest) LineRef() string {
+	if request.lineRef == "" {
+		request.lineRef = request.findStringChildContent("LineRef")
+	}
+	return request.lineRef
+}
+
+func (request *XMLVehicleMonitoringRequest) MaximumVehicles() int {
+	if request.maximumVehicles == 0 {
+		request.maximumVehicles = request.findIntChildContent("MaximumVehicles")
+	}
+	return request.maximumVehicles
+}
+
+func NewSIRIGetVehicleMonitoringRequest(
+	messageIdentifier,
+	lineRef,
+	requestorRef string,
+	requestTimestamp time.Time) *SIRIGetVehicleMonitoringRequest {
+	request := &SIRIGetVehicleMonitoringRequest{
+		RequestorRef: requestorRef,
+	}
+	request.MessageIdentifier = messageIdentifier
+	request.LineRef = lineRef
+	request.RequestTimestamp = requestTimestamp
+	return request
+}
+
+func (request *SIRIGetVehicleMonitoringRequest) BuildXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "get_vehicle_monitoring_request.template", request); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func (request *SIRIVehicleMonitoringRequest) BuildVehicleMonitoringRequestXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "vehicle_monitoring_request.template", request); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
@@ -0,0 +1,135 @@
+package siri
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func getXMLGetVehicleMonitoring(t *testing.T) *XMLGetVehicleMonitoring {
+	file, err := os.Open("testdata/vehicle_monitoring_request.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request, _ := NewXMLGetVehicleMonitoringFromContent(content)
+	return request
+}
+
+func Test_XMLGetVehicleMonitoring_RequestorRef(t *testing.T) {
+	request := getXMLGetVehicleMonitoring(t)
+	if expected := "test"; request.RequestorRef() != expected {
+		t.Errorf("Wrong RequestorRef:\n got: %v\nwant: %v", request.RequestorRef(), expected)
+	}
+}
+
+func Test_XMLGetVehicleMonitoring_RequestTimestamp(t *testing.T) {
+	request := getXMLGetVehicleMonitoring(t)
+	if expected := time.Date(2016, time.September, 7, 9, 11, 25, 174000000, time.UTC); request.RequestTimestamp() != expected {
+		t.Errorf("Wrong RequestTimestamp:\n got: %v\nwant: %v", request.RequestTimestamp(), expected)
+	}
+}
+
+func Test_XMLGetVehicleMonitoring_MessageIdentifier(t *testing.T) {
+	request := getXMLGetVehicleMonitoring(t)
+	if expected := "VehicleMonitoring:Test:0"; request.MessageIdentifier() != expected {
+		t.Errorf("Wrong MessageIdentifier:\n got: %v\nwant: %v", request.MessageIdentifier(), expected)
+	}
+}
+
+func Test_XMLGetVehicleMonitoring_LineRef(t *testing.T) {
+	request := getXMLGetVehicleMonitoring(t)
+	if expected := "NINOXE:Line:2:LOC"; request.LineRef() != expected {
+		t.Errorf("Wrong LineRef:\n got: %v\nwant: %v", request.LineRef(), expected)
+	}
+	if request.VehicleMonitoringRef() != "" {
+		t.Errorf("Wrong VehicleMonitoringRef:\n got: %v\nwant: \"\"", request.VehicleMonitoringRef())
+	}
+}
+
+func Test_XMLGetVehicleMonitoring_MaximumVehicles(t *testing.T) {
+	request := getXMLGetVehicleMonitoring(t)
+	if expected := 5; request.MaximumVehicles() != expected {
+		t.Errorf("Wrong MaximumVehicles:\n got: %v\nwant: %v", request.MaximumVehicles(), expected)
+	}
+}
+
+func Test_SIRIVehicleMonitoringResponse_BuildXML(t *testing.T) {
+	timestamp := time.Date(2016, time.September, 7, 9, 11, 25, 0, time.UTC)
+	response := &SIRIVehicleMonitoringResponse{
+		Address:                   "http://ara",
+		ProducerRef:               "Ara",
+		ResponseMessageIdentifier: "response",
+		SIRIVehicleMonitoringDelivery: SIRIVehicleMonitoringDelivery{
+			RequestMessageRef: "request",
+			ResponseTimestamp: timestamp,
+			Status:            true,
+			VehicleActivities: []*SIRIVehicleActivity{
+				{
+					RecordedAtTime:         timestamp,
+					ValidUntilTime:         timestamp,
+					VehicleMonitoringRef:   "vehicle",
+					LineRef:                "line",
+					DataFrameRef:           "2016-09-07",
+					DatedVehicleJourneyRef: "vehicleJourney",
+					PublishedLineName:      "Line 1",
+					Monitored:              true,
+					Bearing:                90,
+					Longitude:              2.3,
+					Latitude:               48.8,
+				},
+			},
+		},
+	}
+
+	expected := `<sw:GetVehicleMonitoringResponse xmlns:sw="http://wsdl.siri.org.uk" xmlns:siri="http://www.siri.org.uk/siri">
+	<ServiceDeliveryInfo>
+		<siri:ResponseTimestamp>2016-09-07T09:11:25.000Z</siri:ResponseTimestamp>
+		<siri:ProducerRef>Ara</siri:ProducerRef>
+		<siri:Address>http://ara</siri:Address>
+		<siri:ResponseMessageIdentifier>response</siri:ResponseMessageIdentifier>
+		<siri:RequestMessageRef>request</siri:RequestMessageRef>
+	</ServiceDeliveryInfo>
+	<Answer>
+		<siri:VehicleMonitoringDelivery version="2.0:FR-IDF-2.4">
+			<siri:ResponseTimestamp>2016-09-07T09:11:25.000Z</siri:ResponseTimestamp>
+			<siri:RequestMessageRef>request</siri:RequestMessageRef>
+			<siri:Status>true</siri:Status>
+			<siri:VehicleActivity>
+				<siri:RecordedAtTime>2016-09-07T09:11:25.000Z</siri:RecordedAtTime>
+				<siri:ValidUntilTime>2016-09-07T09:11:25.000Z</siri:ValidUntilTime>
+				<siri:VehicleMonitoringRef>vehicle</siri:VehicleMonitoringRef>
+				<siri:MonitoredVehicleJourney>
+					<siri:LineRef>line</siri:LineRef>
+					<siri:FramedVehicleJourneyRef>
+						<siri:DataFrameRef>2016-09-07</siri:DataFrameRef>
+						<siri:DatedVehicleJourneyRef>vehicleJourney</siri:DatedVehicleJourneyRef>
+					</siri:FramedVehicleJourneyRef>
+					<siri:PublishedLineName>Line 1</siri:PublishedLineName>
+					<siri:Monitored>true</siri:Monitored>
+					<siri:VehicleLocation>
+						<siri:Longitude>2.3</siri:Longitude>
+						<siri:Latitude>48.8</siri:Latitude>
+					</siri:VehicleLocation>
+					<siri:Bearing>90</siri:Bearing>
+				</siri:MonitoredVehicleJourney>
+			</siri:VehicleActivity>
+		</siri:VehicleMonitoringDelivery>
+	</Answer>
+	<AnswerExtension/>
+</sw:GetVehicleMonitoringResponse>`
+
+	xml, err := response.BuildXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if xml != expected {
+		t.Errorf("Wrong XML for VehicleMonitoring response:\n got:\n%v\nwant:\n%v", xml, expected)
+	}
+}
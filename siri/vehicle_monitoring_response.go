@@ -0,0 +1,90 @@
+package siri
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/logger"
+)
+
+type SIRIVehicleMonitoringResponse struct {
+	SIRIVehicleMonitoringDelivery
+
+	Address                   string
+	ProducerRef               string
+	ResponseMessageIdentifier string
+}
+
+type SIRIVehicleMonitoringDelivery struct {
+	RequestMessageRef string
+
+	ResponseTimestamp time.Time
+
+	Status      bool
+	ErrorType   string
+	ErrorNumber int
+	ErrorText   string
+
+	VehicleActivities []*SIRIVehicleActivity
+}
+
+type SIRIVehicleActivity struct {
+	RecordedAtTime       time.Time
+	ValidUntilTime       time.Time
+	VehicleMonitoringRef string
+
+	LineRef                string
+	DataFrameRef           string
+	DatedVehicleJourneyRef string
+	PublishedLineName      string
+	DirectionName          string
+	OriginRef              string
+	OriginName             string
+	DestinationRef         string
+	DestinationName        string
+
+	Monitored bool
+
+	Bearing   float64
+	Longitude float64
+	Latitude  float64
+}
+
+func (response *SIRIVehicleMonitoringResponse) BuildXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "vehicle_monitoring_response.template", response); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func (delivery *SIRIVehicleMonitoringDelivery) ErrorString() string {
+	return fmt.Sprintf("%v: %v", delivery.errorType(), delivery.ErrorText)
+}
+
+func (delivery *SIRIVehicleMonitoringDelivery) errorType() string {
+	if delivery.ErrorType == "OtherError" {
+		return fmt.Sprintf("%v %v", delivery.ErrorType, delivery.ErrorNumber)
+	}
+	return delivery.ErrorType
+}
+
+func (delivery *SIRIVehicleMonitoringDelivery) BuildVehicleMonitoringDeliveryXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "vehicle_monitoring_delivery.template", delivery); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func (activity *SIRIVehicleActivity) BuildVehicleActivityXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "vehicle_activity.template", activity); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
@@ -0,0 +1,70 @@
+package siri
+
+import (
+	"bytes"
+	"time"
+
+	"bitbucket.org/enroute-mobi/ara/logger"
+)
+
+type XMLVehicleMonitoringSubscriptionRequestEntry struct {
+	XMLVehicleMonitoringRequest
+
+	subscriberRef          string
+	subscriptionRef        string
+	initialTerminationTime time.Time
+}
+
+type SIRIVehicleMonitoringSubscriptionRequest struct {
+	ConsumerAddress   string
+	MessageIdentifier string
+	RequestorRef      string
+	RequestTimestamp  time.Time
+
+	Entries []*SIRIVehicleMonitoringSubscriptionRequestEntry
+}
+
+type SIRIVehicleMonitoringSubscriptionRequestEntry struct {
+	SIRIVehicleMonitoringRequest
+
+	SubscriberRef          string
+	SubscriptionIdentifier string
+
+	InitialTerminationTime time.Time
+}
+
+func NewXMLVehicleMonitoringSubscriptionRequestEntry(node XMLNode) *XMLVehicleMonitoringSubscriptionRequestEntry {
+	xmlVehicleMonitoringSubscriptionRequest := &XMLVehicleMonitoringSubscriptionRequestEntry{}
+	xmlVehicleMonitoringSubscriptionRequest.node = node
+	return xmlVehicleMonitoringSubscriptionRequest
+}
+
+func (request *XMLVehicleMonitoringSubscriptionRequestEntry) SubscriberRef() string {
+	if request.subscriberRef == "" {
+		request.subscriberRef = request.findStringChildContent("SubscriberRef")
+	}
+	return request.subscriberRef
+}
+
+func (request *XMLVehicleMonitoringSubscriptionRequestEntry) SubscriptionIdentifier() string {
+	if request.subscriptionRef == "" {
+		request.subscriptionRef = request.findStringChildContent("SubscriptionIdentifier")
+	}
+	return request.subscriptionRef
+}
+
+func (request *XMLVehicleMonitoringSubscriptionRequestEntry) InitialTerminationTime() time.Time {
+	if request.initialTerminationTime.IsZero() {
+		request.initialTerminationTime = request.findTimeChildContent("InitialTerminationTime")
+	}
+	return request.initialTerminationTime
+}
+
+func (request *SIRIVehicleMonitoringSubscriptionRequest) BuildXML() (string, error) {
+	var buffer bytes.Buffer
+	if err := templates.ExecuteTemplate(&buffer, "vehicle_monitoring_subscription_request.template", request); err != nil {
+		logger.Log.Debugf("Error while executing template: %v", err)
+		return "", err
+	}
+	return buffer.String(), nil
+}
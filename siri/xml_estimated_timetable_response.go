@@ -0,0 +1,329 @@
+package siri
+
+import (
+	"time"
+
+	"github.com/jbowtie/gokogiri"
+	"github.com/jbowtie/gokogiri/xml"
+)
+
+type XMLEstimatedTimetableResponse struct {
+	ResponseXMLStructure
+
+	deliveries []*XMLEstimatedTimetableDelivery
+}
+
+type XMLEstimatedTimetableDelivery struct {
+	DeliveryXMLStructure
+
+	estimatedVehicleJourneys []*XMLEstimatedVehicleJourney
+}
+
+type XMLEstimatedVehicleJourney struct {
+	XMLStructure
+
+	lineRef                string
+	directionRef           string
+	datedVehicleJourneyRef string
+	publishedLineName      string
+	originRef              string
+	originName             string
+	destinationRef         string
+	destinationName        string
+	operatorRef            string
+	dataFrameRef           string
+	monitored              Bool
+	cancellation           Bool
+	extraJourney           Bool
+
+	recordedCalls  []*XMLCall
+	estimatedCalls []*XMLCall
+}
+
+type XMLCall struct {
+	XMLStructure
+
+	stopPointRef       string
+	stopPointName      string
+	destinationDisplay string
+	order              int
+	cancellation       Bool
+
+	aimedArrivalTime      time.Time
+	expectedArrivalTime   time.Time
+	actualArrivalTime     time.Time
+	arrivalStatus         string
+	aimedDepartureTime    time.Time
+	expectedDepartureTime time.Time
+	actualDepartureTime   time.Time
+	departureStatus       string
+}
+
+func NewXMLEstimatedTimetableResponse(node xml.Node) *XMLEstimatedTimetableResponse {
+	xmlEstimatedTimetableResponse := &XMLEstimatedTimetableResponse{}
+	xmlEstimatedTimetableResponse.node = NewXMLNode(node)
+	return xmlEstimatedTimetableResponse
+}
+
+func NewXMLEstimatedTimetableResponseFromContent(content []byte) (*XMLEstimatedTimetableResponse, error) {
+	doc, err := gokogiri.ParseXml(content)
+	if err != nil {
+		return nil, err
+	}
+	response := NewXMLEstimatedTimetableResponse(doc.Root().XmlNode)
+	return response, nil
+}
+
+func NewXMLEstimatedTimetableDelivery(node XMLNode) *XMLEstimatedTimetableDelivery {
+	delivery := &XMLEstimatedTimetableDelivery{}
+	delivery.node = node
+	return delivery
+}
+
+func NewXMLEstimatedVehicleJourney(node XMLNode) *XMLEstimatedVehicleJourney {
+	vehicleJourney := &XMLEstimatedVehicleJourney{}
+	vehicleJourney.node = node
+	return vehicleJourney
+}
+
+func NewXMLCall(node XMLNode) *XMLCall {
+	call := &XMLCall{}
+	call.node = node
+	return call
+}
+
+func (response *XMLEstimatedTimetableResponse) EstimatedTimetableDeliveries() []*XMLEstimatedTimetableDelivery {
+	if response.deliveries == nil {
+		deliveries := []*XMLEstimatedTimetableDelivery{}
+		nodes := response.findNodes("EstimatedTimetableDelivery")
+		for _, node := range nodes {
+			deliveries = append(deliveries, NewXMLEstimatedTimetableDelivery(node))
+		}
+		response.deliveries = deliveries
+	}
+	return response.deliveries
+}
+
+func (delivery *XMLEstimatedTimetableDelivery) EstimatedVehicleJourneys() []*XMLEstimatedVehicleJourney {
+	if delivery.estimatedVehicleJourneys == nil {
+		delivery.estimatedVehicleJourneys = findEstimatedVehicleJourneys(&delivery.XMLStructure)
+	}
+	return delivery.estimatedVehicleJourneys
+}
+
+func findEstimatedVehicleJourneys(xmlStruct *XMLStructure) []*XMLEstimatedVehicleJourney {
+	vehicleJourneys := []*XMLEstimatedVehicleJourney{}
+	nodes := xmlStruct.findNodes("EstimatedVehicleJourney")
+	for _, node := range nodes {
+		vehicleJourneys = append(vehicleJourneys, NewXMLEstimatedVehicleJourney(node))
+	}
+	return vehicleJourneys
+}
+
+func (vj *XMLEstimatedVehicleJourney) RecordedCalls() []*XMLCall {
+	if vj.recordedCalls == nil {
+		calls := []*XMLCall{}
+		nodes := vj.findNodes("RecordedCall")
+		for _, node := range nodes {
+			calls = append(calls, NewXMLCall(node))
+		}
+		vj.recordedCalls = calls
+	}
+	return vj.recordedCalls
+}
+
+func (vj *XMLEstimatedVehicleJourney) EstimatedCalls() []*XMLCall {
+	if vj.estimatedCalls == nil {
+		calls := []*XMLCall{}
+		nodes := vj.findNodes("EstimatedCall")
+		for _, node := range nodes {
+			calls = append(calls, NewXMLCall(node))
+		}
+		vj.estimatedCalls = calls
+	}
+	return vj.estimatedCalls
+}
+
+func (vj *XMLEstimatedVehicleJourney) LineRef() string {
+	if vj.lineRef == "" {
+		vj.lineRef = vj.findStringChildContent("LineRef")
+	}
+	return vj.lineRef
+}
+
+func (vj *XMLEstimatedVehicleJourney) DirectionRef() string {
+	if vj.directionRef == "" {
+		vj.directionRef = vj.findStringChildContent("DirectionRef")
+	}
+	return vj.directionRef
+}
+
+// DatedVehicleJourneyRef can be defined directly or in a FramedVehicleJourneyRef
+func (vj *XMLEstimatedVehicleJourney) DatedVehicleJourneyRef() string {
+	if vj.datedVehicleJourneyRef == "" {
+		vj.datedVehicleJourneyRef = vj.findStringChildContent("DatedVehicleJourneyRef")
+	}
+	return vj.datedVehicleJourneyRef
+}
+
+func (vj *XMLEstimatedVehicleJourney) DataFrameRef() string {
+	if vj.dataFrameRef == "" {
+		vj.dataFrameRef = vj.findStringChildContent("DataFrameRef")
+	}
+	return vj.dataFrameRef
+}
+
+func (vj *XMLEstimatedVehicleJourney) PublishedLineName() string {
+	if vj.publishedLineName == "" {
+		vj.publishedLineName = vj.findStringChildContent("PublishedLineName")
+	}
+	return vj.publishedLineName
+}
+
+func (vj *XMLEstimatedVehicleJourney) OriginRef() string {
+	if vj.originRef == "" {
+		vj.originRef = vj.findStringChildContent("OriginRef")
+	}
+	return vj.originRef
+}
+
+func (vj *XMLEstimatedVehicleJourney) OriginName() string {
+	if vj.originName == "" {
+		vj.originName = vj.findStringChildContent("OriginName")
+	}
+	return vj.originName
+}
+
+func (vj *XMLEstimatedVehicleJourney) DestinationRef() string {
+	if vj.destinationRef == "" {
+		vj.destinationRef = vj.findStringChildContent("DestinationRef")
+	}
+	return vj.destinationRef
+}
+
+func (vj *XMLEstimatedVehicleJourney) DestinationName() string {
+	if vj.destinationName == "" {
+		vj.destinationName = vj.findStringChildContent("DestinationName")
+	}
+	return vj.destinationName
+}
+
+func (vj *XMLEstimatedVehicleJourney) OperatorRef() string {
+	if vj.operatorRef == "" {
+		vj.operatorRef = vj.findStringChildContent("OperatorRef")
+	}
+	return vj.operatorRef
+}
+
+func (vj *XMLEstimatedVehicleJourney) Monitored() bool {
+	if !vj.monitored.Defined {
+		vj.monitored.Parse(vj.findStringChildContent("Monitored"))
+	}
+	return vj.monitored.Value
+}
+
+// Cancellation is also defined in the Calls, only the direct child is used
+func (vj *XMLEstimatedVehicleJourney) Cancellation() bool {
+	if !vj.cancellation.Defined {
+		vj.cancellation.Parse(vj.findDirectChildContent("Cancellation"))
+	}
+	return vj.cancellation.Value
+}
+
+func (vj *XMLEstimatedVehicleJourney) ExtraJourney() bool {
+	if !vj.extraJourney.Defined {
+		vj.extraJourney.Parse(vj.findDirectChildContent("ExtraJourney"))
+	}
+	return vj.extraJourney.Value
+}
+
+func (call *XMLCall) StopPointRef() string {
+	if call.stopPointRef == "" {
+		call.stopPointRef = call.findStringChildContent("StopPointRef")
+	}
+	return call.stopPointRef
+}
+
+func (call *XMLCall) StopPointName() string {
+	if call.stopPointName == "" {
+		call.stopPointName = call.findStringChildContent("StopPointName")
+	}
+	return call.stopPointName
+}
+
+func (call *XMLCall) DestinationDisplay() string {
+	if call.destinationDisplay == "" {
+		call.destinationDisplay = call.findStringChildContent("DestinationDisplay")
+	}
+	return call.destinationDisplay
+}
+
+func (call *XMLCall) Order() int {
+	if call.order == 0 {
+		call.order = call.findIntChildContent("Order")
+	}
+	return call.order
+}
+
+func (call *XMLCall) Cancellation() bool {
+	if !call.cancellation.Defined {
+		call.cancellation.Parse(call.findStringChildContent("Cancellation"))
+	}
+	return call.cancellation.Value
+}
+
+func (call *XMLCall) AimedArrivalTime() time.Time {
+	if call.aimedArrivalTime.IsZero() {
+		call.aimedArrivalTime = call.findTimeChildContent("AimedArrivalTime")
+	}
+	return call.aimedArrivalTime
+}
+
+func (call *XMLCall) ExpectedArrivalTime() time.Time {
+	if call.expectedArrivalTime.IsZero() {
+		call.expectedArrivalTime = call.findTimeChildContent("ExpectedArrivalTime")
+	}
+	return call.expectedArrivalTime
+}
+
+func (call *XMLCall) ActualArrivalTime() time.Time {
+	if call.actualArrivalTime.IsZero() {
+		call.actualArrivalTime = call.findTimeChildContent("ActualArrivalTime")
+	}
+	return call.actualArrivalTime
+}
+
+func (call *XMLCall) ArrivalStatus() string {
+	if call.arrivalStatus == "" {
+		call.arrivalStatus = call.findStringChildContent("ArrivalStatus")
+	}
+	return call.arrivalStatus
+}
+
+func (call *XMLCall) AimedDepartureTime() time.Time {
+	if call.aimedDepartureTime.IsZero() {
+		call.aimedDepartureTime = call.findTimeChildContent("AimedDepartureTime")
+	}
+	return call.aimedDepartureTime
+}
+
+func (call *XMLCall) ExpectedDepartureTime() time.Time {
+	if call.expectedDepartureTime.IsZero() {
+		call.expectedDepartureTime = call.findTimeChildContent("ExpectedDepartureTime")
+	}
+	return call.expectedDepartureTime
+}
+
+func (call *XMLCall) ActualDepartureTime() time.Time {
+	if call.actualDepartureTime.IsZero() {
+		call.actualDepartureTime = call.findTimeChildContent("ActualDepartureTime")
+	}
+	return call.actualDepartureTime
+}
+
+func (call *XMLCall) DepartureStatus() string {
+	if call.departureStatus == "" {
+		call.departureStatus = call.findStringChildContent("DepartureStatus")
+	}
+	return call.departureStatus
+}
@@ -0,0 +1,105 @@
+package siri
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func getXMLEstimatedTimetableResponse(t *testing.T) *XMLEstimatedTimetableResponse {
+	file, err := os.Open("testdata/estimated_timetable_response-soap.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, _ := NewXMLEstimatedTimetableResponseFromContent(content)
+	return response
+}
+
+func Test_XMLEstimatedTimetableResponse_RequestMessageRef(t *testing.T) {
+	response := getXMLEstimatedTimetableResponse(t)
+	if expected := "EstimatedTimetable:Test:0"; response.RequestMessageRef() != expected {
+		t.Errorf("Wrong RequestMessageRef:\n got: %v\nwant: %v", response.RequestMessageRef(), expected)
+	}
+}
+
+func Test_XMLEstimatedTimetableResponse_EstimatedVehicleJourneys(t *testing.T) {
+	response := getXMLEstimatedTimetableResponse(t)
+
+	if len(response.EstimatedTimetableDeliveries()) != 1 {
+		t.Fatalf("Wrong number of EstimatedTimetableDeliveries:\n got: %v\nwant: 1", len(response.EstimatedTimetableDeliveries()))
+	}
+	delivery := response.EstimatedTimetableDeliveries()[0]
+	if !delivery.Status() {
+		t.Errorf("Wrong delivery Status:\n got: %v\nwant: true", delivery.Status())
+	}
+	if len(delivery.EstimatedVehicleJourneys()) != 2 {
+		t.Fatalf("Wrong number of EstimatedVehicleJourneys:\n got: %v\nwant: 2", len(delivery.EstimatedVehicleJourneys()))
+	}
+
+	vj := delivery.EstimatedVehicleJourneys()[0]
+	if expected := "RLA:Line:1:LOC"; vj.LineRef() != expected {
+		t.Errorf("Wrong LineRef:\n got: %v\nwant: %v", vj.LineRef(), expected)
+	}
+	if expected := "RLA:VehicleJourney:1:LOC"; vj.DatedVehicleJourneyRef() != expected {
+		t.Errorf("Wrong DatedVehicleJourneyRef:\n got: %v\nwant: %v", vj.DatedVehicleJourneyRef(), expected)
+	}
+	if expected := "RLA:DataFrame::2017-01-01:LOC"; vj.DataFrameRef() != expected {
+		t.Errorf("Wrong DataFrameRef:\n got: %v\nwant: %v", vj.DataFrameRef(), expected)
+	}
+	if expected := "RLA:StopPoint:q:3:LOC"; vj.DestinationRef() != expected {
+		t.Errorf("Wrong DestinationRef:\n got: %v\nwant: %v", vj.DestinationRef(), expected)
+	}
+	if !vj.Monitored() {
+		t.Errorf("Wrong Monitored:\n got: false\nwant: true")
+	}
+	if vj.Cancellation() {
+		t.Errorf("VehicleJourney shouldn't be cancelled by a cancelled Call")
+	}
+	if !vj.ExtraJourney() {
+		t.Errorf("Wrong ExtraJourney:\n got: false\nwant: true")
+	}
+	if len(vj.RecordedCalls()) != 1 {
+		t.Fatalf("Wrong number of RecordedCalls:\n got: %v\nwant: 1", len(vj.RecordedCalls()))
+	}
+	if len(vj.EstimatedCalls()) != 2 {
+		t.Fatalf("Wrong number of EstimatedCalls:\n got: %v\nwant: 2", len(vj.EstimatedCalls()))
+	}
+
+	recordedCall := vj.RecordedCalls()[0]
+	if expected := time.Date(2017, time.January, 1, 11, 52, 0, 0, time.UTC); !recordedCall.ActualDepartureTime().Equal(expected) {
+		t.Errorf("Wrong ActualDepartureTime:\n got: %v\nwant: %v", recordedCall.ActualDepartureTime(), expected)
+	}
+
+	cancelledCall := vj.EstimatedCalls()[0]
+	if !cancelledCall.Cancellation() {
+		t.Errorf("Call should be cancelled")
+	}
+
+	call := vj.EstimatedCalls()[1]
+	if expected := "RLA:StopPoint:q:3:LOC"; call.StopPointRef() != expected {
+		t.Errorf("Wrong StopPointRef:\n got: %v\nwant: %v", call.StopPointRef(), expected)
+	}
+	if call.Order() != 3 {
+		t.Errorf("Wrong Order:\n got: %v\nwant: 3", call.Order())
+	}
+	if expected := time.Date(2017, time.January, 1, 12, 12, 0, 0, time.UTC); !call.ExpectedArrivalTime().Equal(expected) {
+		t.Errorf("Wrong ExpectedArrivalTime:\n got: %v\nwant: %v", call.ExpectedArrivalTime(), expected)
+	}
+	if expected := "delayed"; call.ArrivalStatus() != expected {
+		t.Errorf("Wrong ArrivalStatus:\n got: %v\nwant: %v", call.ArrivalStatus(), expected)
+	}
+
+	if !delivery.EstimatedVehicleJourneys()[1].Cancellation() {
+		t.Errorf("Second VehicleJourney should be cancelled")
+	}
+	if delivery.EstimatedVehicleJourneys()[1].ExtraJourney() {
+		t.Errorf("Second VehicleJourney shouldn't be an extra journey")
+	}
+}
@@ -0,0 +1,237 @@
+package siri
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jbowtie/gokogiri"
+	"github.com/jbowtie/gokogiri/xml"
+)
+
+const (
+	affectedNetworkRefXPath        = "./*[local-name()='Affects']/*[local-name()='Networks']/*[local-name()='AffectedNetwork']/*[local-name()='NetworkRef']"
+	affectedLineRefXPath           = "./*[local-name()='Affects']/*[local-name()='Networks']/*[local-name()='AffectedNetwork']/*[local-name()='AffectedLine']/*[local-name()='LineRef']"
+	affectedStopPointRefXPath      = "./*[local-name()='Affects']/*[local-name()='StopPoints']/*[local-name()='AffectedStopPoint']/*[local-name()='StopPointRef']"
+	affectedVehicleJourneyRefXPath = "./*[local-name()='Affects']/*[local-name()='VehicleJourneys']/*[local-name()='AffectedVehicleJourney']//*[local-name()='DatedVehicleJourneyRef' or local-name()='VehicleJourneyRef']"
+)
+
+type XMLSituationExchangeResponse struct {
+	ResponseXMLStructureWithStatus
+
+	xmlPtSituationElements []*XMLPtSituationElement
+}
+
+type XMLPtSituationElement struct {
+	XMLStructure
+
+	situationNumber string
+	participantRef  string
+	summary         string
+	description     string
+	alertCause      string
+	severity        string
+	progress        string
+
+	version int
+
+	creationTime time.Time
+
+	validityPeriods    []*XMLTimeRange
+	publicationWindows []*XMLTimeRange
+
+	affectedNetworkRefs        []string
+	affectedLineRefs           []string
+	affectedStopPointRefs      []string
+	affectedVehicleJourneyRefs []string
+}
+
+type XMLTimeRange struct {
+	XMLStructure
+
+	startTime time.Time
+	endTime   time.Time
+}
+
+func NewXMLSituationExchangeResponseFromContent(content []byte) (*XMLSituationExchangeResponse, error) {
+	doc, err := gokogiri.ParseXml(content)
+	if err != nil {
+		return nil, err
+	}
+	response := NewXMLSituationExchangeResponse(doc.Root().XmlNode)
+	return response, nil
+}
+
+func NewXMLSituationExchangeResponse(node xml.Node) *XMLSituationExchangeResponse {
+	xmlSituationExchangeResponse := &XMLSituationExchangeResponse{}
+	xmlSituationExchangeResponse.node = NewXMLNode(node)
+	return xmlSituationExchangeResponse
+}
+
+func NewXMLPtSituationElement(node XMLNode) *XMLPtSituationElement {
+	situation := &XMLPtSituationElement{}
+	situation.node = node
+	return situation
+}
+
+func NewXMLTimeRange(node XMLNode) *XMLTimeRange {
+	timeRange := &XMLTimeRange{}
+	timeRange.node = node
+	return timeRange
+}
+
+func (response *XMLSituationExchangeResponse) ErrorString() string {
+	return fmt.Sprintf("%v: %v", response.errorType(), response.ErrorText())
+}
+
+func (response *XMLSituationExchangeResponse) errorType() string {
+	if response.ErrorType() == "OtherError" {
+		return fmt.Sprintf("%v %v", response.ErrorType(), response.ErrorNumber())
+	}
+	return response.ErrorType()
+}
+
+func (response *XMLSituationExchangeResponse) XMLPtSituationElements() []*XMLPtSituationElement {
+	if len(response.xmlPtSituationElements) == 0 {
+		nodes := response.findNodes("PtSituationElement")
+		for _, node := range nodes {
+			response.xmlPtSituationElements = append(response.xmlPtSituationElements, NewXMLPtSituationElement(node))
+		}
+	}
+	return response.xmlPtSituationElements
+}
+
+func (situation *XMLPtSituationElement) CreationTime() time.Time {
+	if situation.creationTime.IsZero() {
+		situation.creationTime = situation.findTimeChildContent("CreationTime")
+	}
+	return situation.creationTime
+}
+
+func (situation *XMLPtSituationElement) SituationNumber() string {
+	if situation.situationNumber == "" {
+		situation.situationNumber = situation.findStringChildContent("SituationNumber")
+	}
+	return situation.situationNumber
+}
+
+func (situation *XMLPtSituationElement) Version() int {
+	if situation.version == 0 {
+		situation.version = situation.findIntChildContent("Version")
+		if situation.version == 0 {
+			situation.version = 1
+		}
+	}
+	return situation.version
+}
+
+func (situation *XMLPtSituationElement) ParticipantRef() string {
+	if situation.participantRef == "" {
+		situation.participantRef = situation.findStringChildContent("ParticipantRef")
+	}
+	return situation.participantRef
+}
+
+func (situation *XMLPtSituationElement) Summary() string {
+	if situation.summary == "" {
+		situation.summary = situation.findStringChildContent("Summary")
+	}
+	return situation.summary
+}
+
+func (situation *XMLPtSituationElement) Description() string {
+	if situation.description == "" {
+		situation.description = situation.findStringChildContent("Description")
+	}
+	return situation.description
+}
+
+func (situation *XMLPtSituationElement) AlertCause() string {
+	if situation.alertCause == "" {
+		situation.alertCause = situation.findStringChildContent("AlertCause")
+	}
+	return situation.alertCause
+}
+
+func (situation *XMLPtSituationElement) Severity() string {
+	if situation.severity == "" {
+		situation.severity = situation.findStringChildContent("Severity")
+	}
+	return situation.severity
+}
+
+func (situation *XMLPtSituationElement) Progress() string {
+	if situation.progress == "" {
+		situation.progress = situation.findStringChildContent("Progress")
+	}
+	return situation.progress
+}
+
+func (situation *XMLPtSituationElement) ValidityPeriods() []*XMLTimeRange {
+	if len(situation.validityPeriods) == 0 {
+		nodes := situation.findDirectChildrenNodes("ValidityPeriod")
+		for _, node := range nodes {
+			situation.validityPeriods = append(situation.validityPeriods, NewXMLTimeRange(node))
+		}
+	}
+	return situation.validityPeriods
+}
+
+func (situation *XMLPtSituationElement) PublicationWindows() []*XMLTimeRange {
+	if len(situation.publicationWindows) == 0 {
+		nodes := situation.findDirectChildrenNodes("PublicationWindow")
+		for _, node := range nodes {
+			situation.publicationWindows = append(situation.publicationWindows, NewXMLTimeRange(node))
+		}
+	}
+	return situation.publicationWindows
+}
+
+func (situation *XMLPtSituationElement) AffectedNetworkRefs() []string {
+	if len(situation.affectedNetworkRefs) == 0 {
+		situation.affectedNetworkRefs = situation.refs(affectedNetworkRefXPath)
+	}
+	return situation.affectedNetworkRefs
+}
+
+func (situation *XMLPtSituationElement) AffectedLineRefs() []string {
+	if len(situation.affectedLineRefs) == 0 {
+		situation.affectedLineRefs = situation.refs(affectedLineRefXPath)
+	}
+	return situation.affectedLineRefs
+}
+
+func (situation *XMLPtSituationElement) AffectedStopPointRefs() []string {
+	if len(situation.affectedStopPointRefs) == 0 {
+		situation.affectedStopPointRefs = situation.refs(affectedStopPointRefXPath)
+	}
+	return situation.affectedStopPointRefs
+}
+
+func (situation *XMLPtSituationElement) AffectedVehicleJourneyRefs() []string {
+	if len(situation.affectedVehicleJourneyRefs) == 0 {
+		situation.affectedVehicleJourneyRefs = situation.refs(affectedVehicleJourneyRefXPath)
+	}
+	return situation.affectedVehicleJourneyRefs
+}
+
+func (situation *XMLPtSituationElement) refs(xpath string) (refs []string) {
+	for _, node := range situation.nodes(xpath) {
+		refs = append(refs, strings.TrimSpace(node.NativeNode().Content()))
+	}
+	return
+}
+
+func (timeRange *XMLTimeRange) StartTime() time.Time {
+	if timeRange.startTime.IsZero() {
+		timeRange.startTime = timeRange.findTimeChildContent("StartTime")
+	}
+	return timeRange.startTime
+}
+
+func (timeRange *XMLTimeRange) EndTime() time.Time {
+	if timeRange.endTime.IsZero() {
+		timeRange.endTime = timeRange.findTimeChildContent("EndTime")
+	}
+	return timeRange.endTime
+}
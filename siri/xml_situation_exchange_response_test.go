@@ -0,0 +1,135 @@
+package siri
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func getXMLSituationExchangeResponse(t *testing.T) *XMLSituationExchangeResponse {
+	file, err := os.Open("testdata/situation_exchange_response-soap.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, _ := NewXMLSituationExchangeResponseFromContent(content)
+	return response
+}
+
+func Test_XMLSituationExchangeResponse_XMLPtSituationElement(t *testing.T) {
+	response := getXMLSituationExchangeResponse(t)
+
+	if expected := "SituationExchange:Test:0"; response.RequestMessageRef() != expected {
+		t.Errorf("Wrong RequestMessageRef:\n got: %v\nwant: %v", response.RequestMessageRef(), expected)
+	}
+	if len(response.XMLPtSituationElements()) != 1 {
+		t.Fatalf("Wrong number of PtSituationElements:\n got: %v\nwant: 1", len(response.XMLPtSituationElements()))
+	}
+	situation := response.XMLPtSituationElements()[0]
+
+	if expected := "NINOXE:Situation:27_1"; situation.SituationNumber() != expected {
+		t.Errorf("Wrong SituationNumber:\n got: %v\nwant: %v", situation.SituationNumber(), expected)
+	}
+	if expected := 2; situation.Version() != expected {
+		t.Errorf("Wrong Version:\n got: %v\nwant: %v", situation.Version(), expected)
+	}
+	if expected := "NINOXE"; situation.ParticipantRef() != expected {
+		t.Errorf("Wrong ParticipantRef:\n got: %v\nwant: %v", situation.ParticipantRef(), expected)
+	}
+	if expected := time.Date(2017, time.March, 29, 3, 30, 6, 0, situation.CreationTime().Location()); !situation.CreationTime().Equal(expected) {
+		t.Errorf("Wrong CreationTime:\n got: %v\nwant: %v", situation.CreationTime(), expected)
+	}
+	if expected := "roadworks"; situation.AlertCause() != expected {
+		t.Errorf("Wrong AlertCause:\n got: %v\nwant: %v", situation.AlertCause(), expected)
+	}
+	if expected := "slight"; situation.Severity() != expected {
+		t.Errorf("Wrong Severity:\n got: %v\nwant: %v", situation.Severity(), expected)
+	}
+	if expected := "open"; situation.Progress() != expected {
+		t.Errorf("Wrong Progress:\n got: %v\nwant: %v", situation.Progress(), expected)
+	}
+	if expected := "Travaux sur la ligne"; situation.Summary() != expected {
+		t.Errorf("Wrong Summary:\n got: %v\nwant: %v", situation.Summary(), expected)
+	}
+	if expected := "Les arrêts Jean Jaures et Pont de Sevres ne sont pas desservis"; situation.Description() != expected {
+		t.Errorf("Wrong Description:\n got: %v\nwant: %v", situation.Description(), expected)
+	}
+
+	if len(situation.ValidityPeriods()) != 1 {
+		t.Fatalf("Wrong number of ValidityPeriods:\n got: %v\nwant: 1", len(situation.ValidityPeriods()))
+	}
+	period := situation.ValidityPeriods()[0]
+	if expected := time.Date(2017, time.March, 29, 20, 30, 6, 0, period.EndTime().Location()); !period.EndTime().Equal(expected) {
+		t.Errorf("Wrong ValidityPeriod EndTime:\n got: %v\nwant: %v", period.EndTime(), expected)
+	}
+	if len(situation.PublicationWindows()) != 1 || !situation.PublicationWindows()[0].EndTime().IsZero() {
+		t.Errorf("Wrong PublicationWindows: %v", situation.PublicationWindows())
+	}
+
+	if expected := []string{"NINOXE:Network:1:LOC"}; !reflect.DeepEqual(situation.AffectedNetworkRefs(), expected) {
+		t.Errorf("Wrong AffectedNetworkRefs:\n got: %v\nwant: %v", situation.AffectedNetworkRefs(), expected)
+	}
+	if expected := []string{"NINOXE:Line:3:LOC"}; !reflect.DeepEqual(situation.AffectedLineRefs(), expected) {
+		t.Errorf("Wrong AffectedLineRefs:\n got: %v\nwant: %v", situation.AffectedLineRefs(), expected)
+	}
+	if expected := []string{"NINOXE:StopPoint:SP:24:LOC", "NINOXE:StopPoint:SP:12:LOC"}; !reflect.DeepEqual(situation.AffectedStopPointRefs(), expected) {
+		t.Errorf("Wrong AffectedStopPointRefs:\n got: %v\nwant: %v", situation.AffectedStopPointRefs(), expected)
+	}
+	if expected := []string{"NINOXE:VehicleJourney:201"}; !reflect.DeepEqual(situation.AffectedVehicleJourneyRefs(), expected) {
+		t.Errorf("Wrong AffectedVehicleJourneyRefs:\n got: %v\nwant: %v", situation.AffectedVehicleJourneyRefs(), expected)
+	}
+}
+
+func Test_SIRISituationExchangeResponse_BuildXML(t *testing.T) {
+	testTime := time.Date(2017, time.March, 29, 3, 30, 6, 0, time.UTC)
+	response := &SIRISituationExchangeResponse{
+		Address:                   "http://ara",
+		ProducerRef:               "Ara",
+		ResponseMessageIdentifier: "response",
+	}
+	response.RequestMessageRef = "SituationExchange:Test:0"
+	response.ResponseTimestamp = testTime
+	response.Status = true
+	response.Situations = []*SIRIPtSituationElement{
+		{
+			CreationTime:       testTime,
+			SituationNumber:    "Situation:1",
+			Version:            1,
+			ValidityPeriods:    []*SIRITimeRange{{StartTime: testTime, EndTime: testTime.Add(time.Hour)}},
+			AlertCause:         "roadworks",
+			Summary:            "Summary",
+			AffectedLines:      []string{"Line:1"},
+			AffectedStopPoints: []string{"StopPoint:1"},
+		},
+	}
+
+	xml, err := response.BuildXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := NewXMLSituationExchangeResponseFromContent([]byte(xml))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(parsed.XMLPtSituationElements()) != 1 {
+		t.Fatalf("Wrong number of PtSituationElements in:\n%v", xml)
+	}
+	situation := parsed.XMLPtSituationElements()[0]
+	if situation.SituationNumber() != "Situation:1" || situation.Summary() != "Summary" || situation.AlertCause() != "roadworks" {
+		t.Errorf("Wrong PtSituationElement in:\n%v", xml)
+	}
+	if !situation.ValidityPeriods()[0].EndTime().Equal(testTime.Add(time.Hour)) {
+		t.Errorf("Wrong ValidityPeriod EndTime in:\n%v", xml)
+	}
+	if !reflect.DeepEqual(situation.AffectedLineRefs(), []string{"Line:1"}) || !reflect.DeepEqual(situation.AffectedStopPointRefs(), []string{"StopPoint:1"}) {
+		t.Errorf("Wrong Affects in:\n%v", xml)
+	}
+}
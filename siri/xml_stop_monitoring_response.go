@@ -79,6 +79,8 @@ type XMLMonitoredStopVisit struct {
 	journeyNote                 string
 	journeyPatternName          string
 	monitored                   Bool
+	cancellation                Bool
+	extraJourney                Bool
 	monitoringError             string
 	occupancy                   string
 	originAimedDepartureTime    string
@@ -492,6 +494,20 @@ func (visit *XMLMonitoredStopVisit) Monitored() bool {
 	return visit.monitored.Value
 }
 
+func (visit *XMLMonitoredStopVisit) Cancellation() bool {
+	if !visit.cancellation.Defined {
+		visit.cancellation.Parse(visit.findStringChildContent("Cancellation"))
+	}
+	return visit.cancellation.Value
+}
+
+func (visit *XMLMonitoredStopVisit) ExtraJourney() bool {
+	if !visit.extraJourney.Defined {
+		visit.extraJourney.Parse(visit.findStringChildContent("ExtraJourney"))
+	}
+	return visit.extraJourney.Value
+}
+
 func (visit *XMLMonitoredStopVisit) MonitoringError() string {
 	if visit.monitoringError == "" {
 		visit.monitoringError = visit.findStringChildContent("MonitoringError")
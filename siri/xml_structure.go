@@ -208,6 +208,14 @@ func (xmlStruct *XMLStructure) findStringChildContent(localName string) string {
 	return strings.TrimSpace(node.Content())
 }
 
+func (xmlStruct *XMLStructure) findDirectChildContent(localName string) string {
+	nodes := xmlStruct.findDirectChildrenNodes(localName)
+	if len(nodes) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(nodes[0].NativeNode().Content())
+}
+
 func (xmlStruct *XMLStructure) containSelfClosing(localName string) bool {
 	node := xmlStruct.findNode(localName)
 	return node != nil
@@ -231,8 +239,12 @@ func (xmlStruct *XMLStructure) findDurationChildContent(localName string) time.D
 	if node == nil {
 		return 0
 	}
+	return parseISO8601Duration(node.Content())
+}
+
+func parseISO8601Duration(value string) time.Duration {
 	durationRegex := regexp.MustCompile(`P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?`)
-	matches := durationRegex.FindStringSubmatch(strings.TrimSpace(node.Content()))
+	matches := durationRegex.FindStringSubmatch(strings.TrimSpace(value))
 
 	if len(matches) == 0 {
 		return 0
@@ -282,6 +294,18 @@ func (xmlStruct *XMLStructure) findIntChildContent(localName string) int {
 	return s
 }
 
+func (xmlStruct *XMLStructure) findFloatChildContent(localName string) float64 {
+	node := xmlStruct.findNode(localName)
+	if node == nil {
+		return 0
+	}
+	s, err := strconv.ParseFloat(strings.TrimSpace(node.Content()), 64)
+	if err != nil {
+		return 0
+	}
+	return s
+}
+
 func (xmlStruct *XMLStructure) RawXML() string {
 	return xmlStruct.node.NativeNode().String()
 }
@@ -0,0 +1,213 @@
+package siri
+
+import (
+	"time"
+
+	"github.com/jbowtie/gokogiri"
+	"github.com/jbowtie/gokogiri/xml"
+)
+
+type XMLVehicleMonitoringResponse struct {
+	ResponseXMLStructure
+
+	deliveries []*XMLVehicleMonitoringDelivery
+}
+
+type XMLVehicleMonitoringDelivery struct {
+	DeliveryXMLStructure
+
+	vehicleActivities []*XMLVehicleActivity
+}
+
+type XMLVehicleActivity struct {
+	XMLStructure
+
+	recordedAtTime         time.Time
+	validUntilTime         time.Time
+	vehicleMonitoringRef   string
+	vehicleRef             string
+	lineRef                string
+	dataFrameRef           string
+	datedVehicleJourneyRef string
+	publishedLineName      string
+	directionName          string
+	originRef              string
+	originName             string
+	destinationRef         string
+	destinationName        string
+	monitored              Bool
+
+	bearing   float64
+	longitude float64
+	latitude  float64
+}
+
+func NewXMLVehicleMonitoringResponse(node xml.Node) *XMLVehicleMonitoringResponse {
+	xmlVehicleMonitoringResponse := &XMLVehicleMonitoringResponse{}
+	xmlVehicleMonitoringResponse.node = NewXMLNode(node)
+	return xmlVehicleMonitoringResponse
+}
+
+func NewXMLVehicleMonitoringResponseFromContent(content []byte) (*XMLVehicleMonitoringResponse, error) {
+	doc, err := gokogiri.ParseXml(content)
+	if err != nil {
+		return nil, err
+	}
+	response := NewXMLVehicleMonitoringResponse(doc.Root().XmlNode)
+	return response, nil
+}
+
+func NewXMLVehicleMonitoringDelivery(node XMLNode) *XMLVehicleMonitoringDelivery {
+	delivery := &XMLVehicleMonitoringDelivery{}
+	delivery.node = node
+	return delivery
+}
+
+func NewXMLVehicleActivity(node XMLNode) *XMLVehicleActivity {
+	activity := &XMLVehicleActivity{}
+	activity.node = node
+	return activity
+}
+
+func (response *XMLVehicleMonitoringResponse) VehicleMonitoringDeliveries() []*XMLVehicleMonitoringDelivery {
+	if response.deliveries == nil {
+		deliveries := []*XMLVehicleMonitoringDelivery{}
+		nodes := response.findNodes("VehicleMonitoringDelivery")
+		for _, node := range nodes {
+			deliveries = append(deliveries, NewXMLVehicleMonitoringDelivery(node))
+		}
+		response.deliveries = deliveries
+	}
+	return response.deliveries
+}
+
+func (delivery *XMLVehicleMonitoringDelivery) XMLVehicleActivities() []*XMLVehicleActivity {
+	if delivery.vehicleActivities == nil {
+		activities := []*XMLVehicleActivity{}
+		nodes := delivery.findNodes("VehicleActivity")
+		for _, node := range nodes {
+			activities = append(activities, NewXMLVehicleActivity(node))
+		}
+		delivery.vehicleActivities = activities
+	}
+	return delivery.vehicleActivities
+}
+
+func (activity *XMLVehicleActivity) RecordedAtTime() time.Time {
+	if activity.recordedAtTime.IsZero() {
+		activity.recordedAtTime = activity.findTimeChildContent("RecordedAtTime")
+	}
+	return activity.recordedAtTime
+}
+
+func (activity *XMLVehicleActivity) ValidUntilTime() time.Time {
+	if activity.validUntilTime.IsZero() {
+		activity.validUntilTime = activity.findTimeChildContent("ValidUntilTime")
+	}
+	return activity.validUntilTime
+}
+
+func (activity *XMLVehicleActivity) VehicleMonitoringRef() string {
+	if activity.vehicleMonitoringRef == "" {
+		activity.vehicleMonitoringRef = activity.findStringChildContent("VehicleMonitoringRef")
+	}
+	return activity.vehicleMonitoringRef
+}
+
+func (activity *XMLVehicleActivity) VehicleRef() string {
+	if activity.vehicleRef == "" {
+		activity.vehicleRef = activity.findStringChildContent("VehicleRef")
+	}
+	return activity.vehicleRef
+}
+
+func (activity *XMLVehicleActivity) LineRef() string {
+	if activity.lineRef == "" {
+		activity.lineRef = activity.findStringChildContent("LineRef")
+	}
+	return activity.lineRef
+}
+
+func (activity *XMLVehicleActivity) DataFrameRef() string {
+	if activity.dataFrameRef == "" {
+		activity.dataFrameRef = activity.findStringChildContent("DataFrameRef")
+	}
+	return activity.dataFrameRef
+}
+
+func (activity *XMLVehicleActivity) DatedVehicleJourneyRef() string {
+	if activity.datedVehicleJourneyRef == "" {
+		activity.datedVehicleJourneyRef = activity.findStringChildContent("DatedVehicleJourneyRef")
+	}
+	return activity.datedVehicleJourneyRef
+}
+
+func (activity *XMLVehicleActivity) PublishedLineName() string {
+	if activity.publishedLineName == "" {
+		activity.publishedLineName = activity.findStringChildContent("PublishedLineName")
+	}
+	return activity.publishedLineName
+}
+
+func (activity *XMLVehicleActivity) DirectionName() string {
+	if activity.directionName == "" {
+		activity.directionName = activity.findStringChildContent("DirectionName")
+	}
+	return activity.directionName
+}
+
+func (activity *XMLVehicleActivity) OriginRef() string {
+	if activity.originRef == "" {
+		activity.originRef = activity.findStringChildContent("OriginRef")
+	}
+	return activity.originRef
+}
+
+func (activity *XMLVehicleActivity) OriginName() string {
+	if activity.originName == "" {
+		activity.originName = activity.findStringChildContent("OriginName")
+	}
+	return activity.originName
+}
+
+func (activity *XMLVehicleActivity) DestinationRef() string {
+	if activity.destinationRef == "" {
+		activity.destinationRef = activity.findStringChildContent("DestinationRef")
+	}
+	return activity.destinationRef
+}
+
+func (activity *XMLVehicleActivity) DestinationName() string {
+	if activity.destinationName == "" {
+		activity.destinationName = activity.findStringChildContent("DestinationName")
+	}
+	return activity.destinationName
+}
+
+func (activity *XMLVehicleActivity) Monitored() bool {
+	if !activity.monitored.Defined {
+		activity.monitored.Parse(activity.findStringChildContent("Monitored"))
+	}
+	return activity.monitored.Value
+}
+
+func (activity *XMLVehicleActivity) Bearing() float64 {
+	if activity.bearing == 0 {
+		activity.bearing = activity.findFloatChildContent("Bearing")
+	}
+	return activity.bearing
+}
+
+func (activity *XMLVehicleActivity) Longitude() float64 {
+	if activity.longitude == 0 {
+		activity.longitude = activity.findFloatChildContent("Longitude")
+	}
+	return activity.longitude
+}
+
+func (activity *XMLVehicleActivity) Latitude() float64 {
+	if activity.latitude == 0 {
+		activity.latitude = activity.findFloatChildContent("Latitude")
+	}
+	return activity.latitude
+}
@@ -0,0 +1,103 @@
+package siri
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func getXMLVehicleMonitoringResponse(t *testing.T) *XMLVehicleMonitoringResponse {
+	file, err := os.Open("testdata/vehicle_monitoring_response-soap.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, _ := NewXMLVehicleMonitoringResponseFromContent(content)
+	return response
+}
+
+func Test_XMLVehicleMonitoringResponse_RequestMessageRef(t *testing.T) {
+	response := getXMLVehicleMonitoringResponse(t)
+	if expected := "VehicleMonitoring:Test:0"; response.RequestMessageRef() != expected {
+		t.Errorf("Wrong RequestMessageRef:\n got: %v\nwant: %v", response.RequestMessageRef(), expected)
+	}
+}
+
+func Test_XMLVehicleMonitoringResponse_XMLVehicleActivity(t *testing.T) {
+	response := getXMLVehicleMonitoringResponse(t)
+
+	if len(response.VehicleMonitoringDeliveries()) != 1 {
+		t.Fatalf("Wrong number of VehicleMonitoringDeliveries:\n got: %v\nwant: 1", len(response.VehicleMonitoringDeliveries()))
+	}
+	delivery := response.VehicleMonitoringDeliveries()[0]
+	if !delivery.Status() {
+		t.Errorf("Wrong delivery Status:\n got: %v\nwant: true", delivery.Status())
+	}
+	if len(delivery.XMLVehicleActivities()) != 1 {
+		t.Fatalf("Wrong number of VehicleActivities:\n got: %v\nwant: 1", len(delivery.XMLVehicleActivities()))
+	}
+
+	activity := delivery.XMLVehicleActivities()[0]
+	if expected := time.Date(2017, time.January, 1, 11, 59, 30, 0, time.UTC); !activity.RecordedAtTime().Equal(expected) {
+		t.Errorf("Wrong RecordedAtTime:\n got: %v\nwant: %v", activity.RecordedAtTime(), expected)
+	}
+	if expected := "RLA:Vehicle:1:LOC"; activity.VehicleMonitoringRef() != expected {
+		t.Errorf("Wrong VehicleMonitoringRef:\n got: %v\nwant: %v", activity.VehicleMonitoringRef(), expected)
+	}
+	if expected := "RLA:Line:1:LOC"; activity.LineRef() != expected {
+		t.Errorf("Wrong LineRef:\n got: %v\nwant: %v", activity.LineRef(), expected)
+	}
+	if expected := "RLA:VehicleJourney:1:LOC"; activity.DatedVehicleJourneyRef() != expected {
+		t.Errorf("Wrong DatedVehicleJourneyRef:\n got: %v\nwant: %v", activity.DatedVehicleJourneyRef(), expected)
+	}
+	if expected := "Ligne 1"; activity.PublishedLineName() != expected {
+		t.Errorf("Wrong PublishedLineName:\n got: %v\nwant: %v", activity.PublishedLineName(), expected)
+	}
+	if expected := 2.3522; activity.Longitude() != expected {
+		t.Errorf("Wrong Longitude:\n got: %v\nwant: %v", activity.Longitude(), expected)
+	}
+	if expected := 48.8566; activity.Latitude() != expected {
+		t.Errorf("Wrong Latitude:\n got: %v\nwant: %v", activity.Latitude(), expected)
+	}
+	if expected := 123.5; activity.Bearing() != expected {
+		t.Errorf("Wrong Bearing:\n got: %v\nwant: %v", activity.Bearing(), expected)
+	}
+}
+
+func Test_SIRIGetVehicleMonitoringRequest_BuildXML(t *testing.T) {
+	expectedXML := `<sw:GetVehicleMonitoring xmlns:sw="http://wsdl.siri.org.uk" xmlns:siri="http://www.siri.org.uk/siri">
+	<ServiceRequestInfo>
+		<siri:RequestTimestamp>2016-09-21T20:14:46.000Z</siri:RequestTimestamp>
+		<siri:RequestorRef>test</siri:RequestorRef>
+		<siri:MessageIdentifier>test</siri:MessageIdentifier>
+	</ServiceRequestInfo>
+	<Request version="2.0:FR-IDF-2.4">
+		<siri:RequestTimestamp>2016-09-21T20:14:46.000Z</siri:RequestTimestamp>
+		<siri:MessageIdentifier>test</siri:MessageIdentifier>
+		<siri:LineRef>line</siri:LineRef>
+	</Request>
+	<RequestExtension />
+</sw:GetVehicleMonitoring>`
+
+	date := time.Date(2016, time.September, 21, 20, 14, 46, 0, time.UTC)
+	request := NewSIRIGetVehicleMonitoringRequest("test", "line", "test", date)
+	xml, err := request.BuildXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedXML != xml {
+		t.Errorf("Wrong XML for Request:\n got:\n%v\nwant:\n%v", xml, expectedXML)
+	}
+
+	// Ensure the request is valid XML
+	if _, err := NewXMLNodeFromContent(bytes.NewBufferString(xml).Bytes()); err != nil {
+		t.Error(err)
+	}
+}